@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package budget tracks how long ap commands take against the time budgets declared in .ap/ci.yaml.
+package budget
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// WarnThreshold is the fraction of its budget after which a run is reported as close to the budget.
+const WarnThreshold = 0.8
+
+// Config is the budgets section of .ap/ci.yaml.
+type Config struct {
+	// Limits maps an ap command (e.g. "test", "e2e" or "alpha sandbox") to its time budget, e.g. "15m".
+	Limits map[string]string `json:"limits,omitempty"`
+
+	// Enforce makes a command fail when it exceeds its budget, rather than only warning.
+	Enforce bool `json:"enforce,omitempty"`
+}
+
+// Validate checks that every limit is a positive duration.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	commands := make([]string, 0, len(c.Limits))
+	for command := range c.Limits {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	for _, command := range commands {
+		d, err := time.ParseDuration(c.Limits[command])
+		if err != nil {
+			return fmt.Errorf("invalid budget for %q: %w", command, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid budget for %q: must be positive", command)
+		}
+	}
+	return nil
+}
+
+// Limit returns the budget of command, if it has one.
+func (c *Config) Limit(command string) (time.Duration, bool) {
+	if c == nil {
+		return 0, false
+	}
+	s, ok := c.Limits[command]
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// Track records a completed run of command in the history at historyPath and checks it against its budget.
+// Commands without a budget are not tracked. A run taking more than WarnThreshold of its budget is warned about;
+// a run exceeding its budget is an error if budgets are enforced, and a warning otherwise.
+func (c *Config) Track(historyPath string, command string, start time.Time, elapsed time.Duration) error {
+	limit, ok := c.Limit(command)
+	if !ok {
+		return nil
+	}
+
+	run := Run{
+		Time:       start.UTC(),
+		Command:    command,
+		DurationMs: elapsed.Milliseconds(),
+		BudgetMs:   limit.Milliseconds(),
+	}
+	if err := AppendHistory(historyPath, run); err != nil {
+		// The history only feeds the report, so a failure to write it must not fail the command.
+		klog.Warningf("failed to record duration of %q: %v", command, err)
+	}
+
+	msg := fmt.Sprintf("ap %s took %s, %.0f%% of its %s budget", command, elapsed.Round(time.Second), 100*run.Fraction(), limit)
+	switch {
+	case elapsed > limit && c.Enforce:
+		return fmt.Errorf("%s", msg)
+	case elapsed > limit:
+		klog.Warningf("%s (budget exceeded)", msg)
+	case run.Fraction() > WarnThreshold:
+		klog.Warningf("%s", msg)
+	}
+	return nil
+}
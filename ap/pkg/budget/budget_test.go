@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		limits  map[string]string
+		wantErr string
+	}{
+		{limits: map[string]string{"test": "15m", "e2e": "1h30m"}},
+		{limits: map[string]string{"test": "15"}, wantErr: `invalid budget for "test"`},
+		{limits: map[string]string{"e2e": "-5m"}, wantErr: `invalid budget for "e2e": must be positive`},
+	}
+	for _, tt := range tests {
+		err := (&Config{Limits: tt.limits}).Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%v) failed: %v", tt.limits, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate(%v) = %v, want error containing %q", tt.limits, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTrack(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	historyPath := filepath.Join(t.TempDir(), "budgets", "history.jsonl")
+	cfg := &Config{Limits: map[string]string{"test": "10m"}}
+
+	if err := cfg.Track(historyPath, "test", start, 9*time.Minute); err != nil {
+		t.Fatalf("Track within budget failed: %v", err)
+	}
+	if err := cfg.Track(historyPath, "test", start, 11*time.Minute); err != nil {
+		t.Fatalf("Track over an unenforced budget failed: %v", err)
+	}
+	if err := cfg.Track(historyPath, "lint", start, time.Hour); err != nil {
+		t.Fatalf("Track without a budget failed: %v", err)
+	}
+
+	cfg.Enforce = true
+	err := cfg.Track(historyPath, "test", start, 12*time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "ap test took 12m0s, 120% of its 10m0s budget") {
+		t.Errorf("Track over an enforced budget = %v, want budget exceeded error", err)
+	}
+
+	runs, err := ReadHistory(historyPath)
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	var got []time.Duration
+	for _, run := range runs {
+		if run.Command != "test" || run.BudgetMs != (10*time.Minute).Milliseconds() || !run.Time.Equal(start) {
+			t.Errorf("unexpected run %+v", run)
+		}
+		got = append(got, run.Duration())
+	}
+	want := []time.Duration{9 * time.Minute, 11 * time.Minute, 12 * time.Minute}
+	if len(got) != len(want) {
+		t.Fatalf("recorded durations %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("recorded durations %v, want %v", got, want)
+		}
+	}
+}
+
+func TestReadHistoryMissing(t *testing.T) {
+	runs, err := ReadHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil || runs != nil {
+		t.Errorf("ReadHistory of a missing file = %v, %v; want no runs", runs, err)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	budget := (10 * time.Minute).Milliseconds()
+	var runs []Run
+	for _, minutes := range []int64{2, 5, 6, 8, 9, 12} {
+		runs = append(runs, Run{Command: "test", DurationMs: minutes * 60000, BudgetMs: budget})
+	}
+	runs = append(runs, Run{Command: "alpha sandbox", DurationMs: 30000, BudgetMs: 60000})
+	cfg := &Config{Limits: map[string]string{"test": "10m", "e2e": "45m"}}
+
+	var out bytes.Buffer
+	if err := PrintReport(&out, cfg, runs, 5); err != nil {
+		t.Fatalf("PrintReport failed: %v", err)
+	}
+
+	want := `COMMAND        BUDGET  RUNS  LAST          MAX           TREND
+alpha sandbox  -       1     30s (50%)     30s (50%)     ▄
+e2e            45m0s   0     -             -             -
+test           10m0s   6     12m0s (120%)  12m0s (120%)  ▄▅▆▇█
+`
+	if got := out.String(); got != want {
+		t.Errorf("PrintReport output:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Run is a completed run of an ap command with a budget.
+type Run struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	DurationMs int64     `json:"durationMs"`
+	// BudgetMs is the budget of the command at the time it ran.
+	BudgetMs int64 `json:"budgetMs"`
+}
+
+// Duration returns how long the run took.
+func (r Run) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
+
+// Fraction returns the fraction of its budget the run took.
+func (r Run) Fraction() float64 {
+	if r.BudgetMs <= 0 {
+		return 0
+	}
+	return float64(r.DurationMs) / float64(r.BudgetMs)
+}
+
+// sparkBlocks are the bars of a trend line, from empty to full.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Trend returns a sparkline of the durations of runs, each relative to its budget:
+// a full bar is a run that took its whole budget (or more).
+func Trend(runs []Run) string {
+	var sb strings.Builder
+	for _, run := range runs {
+		i := int(run.Fraction() * float64(len(sparkBlocks)-1))
+		i = max(0, min(i, len(sparkBlocks)-1))
+		sb.WriteRune(sparkBlocks[i])
+	}
+	return sb.String()
+}
+
+// PrintReport writes a table of the budgeted commands, with the last of their runs and the trend of the
+// most recent ones. Commands that have a budget in cfg but have not run yet are listed too.
+func PrintReport(w io.Writer, cfg *Config, runs []Run, window int) error {
+	byCommand := make(map[string][]Run)
+	for _, run := range runs {
+		byCommand[run.Command] = append(byCommand[run.Command], run)
+	}
+	if cfg != nil {
+		for command := range cfg.Limits {
+			if _, ok := byCommand[command]; !ok {
+				byCommand[command] = nil
+			}
+		}
+	}
+	commands := make([]string, 0, len(byCommand))
+	for command := range byCommand {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMMAND\tBUDGET\tRUNS\tLAST\tMAX\tTREND")
+	for _, command := range commands {
+		commandRuns := byCommand[command]
+		if len(commandRuns) > window {
+			commandRuns = commandRuns[len(commandRuns)-window:]
+		}
+
+		budget := "-"
+		if limit, ok := cfg.Limit(command); ok {
+			budget = limit.String()
+		}
+		if len(commandRuns) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t0\t-\t-\t-\n", command, budget)
+			continue
+		}
+
+		last := commandRuns[len(commandRuns)-1]
+		var longest Run
+		for _, run := range commandRuns {
+			if run.Fraction() >= longest.Fraction() {
+				longest = run
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", command, budget, len(byCommand[command]),
+			formatRun(last), formatRun(longest), Trend(commandRuns))
+	}
+	return tw.Flush()
+}
+
+// formatRun formats the duration of a run and the fraction of its budget it took, e.g. "12m30s (83%)".
+func formatRun(run Run) string {
+	return fmt.Sprintf("%s (%.0f%%)", run.Duration().Round(time.Second), 100*run.Fraction())
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context is what workflow expressions can refer to: the matrix values, the github and runner contexts,
+// and whether an earlier step of the job failed.
+type Context struct {
+	Matrix map[string]string
+	GitHub map[string]string
+	Runner map[string]string
+	Failed bool
+}
+
+// Expand replaces the ${{ }} expressions in s with their values.
+func (c *Context) Expand(s string) (string, error) {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${{")
+		if start < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		end := strings.Index(s[start:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated expression in %q", s)
+		}
+		v, err := c.Eval(s[start+3 : start+end])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s[:start])
+		sb.WriteString(v.String())
+		s = s[start+end+2:]
+	}
+}
+
+// If evaluates the if condition of a step. Without a status function, steps only run if no earlier step failed.
+func (c *Context) If(condition string) (bool, error) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return !c.Failed, nil
+	}
+	if strings.HasPrefix(condition, "${{") && strings.HasSuffix(condition, "}}") {
+		condition = strings.TrimSuffix(strings.TrimPrefix(condition, "${{"), "}}")
+	}
+	p := &parser{ctx: c, tokens: tokenize(condition)}
+	v, err := p.parse()
+	if err != nil {
+		return false, fmt.Errorf("error in condition %q: %w", condition, err)
+	}
+	if !p.statusChecked && c.Failed {
+		return false, nil
+	}
+	return v.truthy(), nil
+}
+
+// Eval evaluates an expression, the part between ${{ and }}.
+func (c *Context) Eval(expr string) (Value, error) {
+	p := &parser{ctx: c, tokens: tokenize(expr)}
+	v, err := p.parse()
+	if err != nil {
+		return Value{}, fmt.Errorf("error in expression %q: %w", strings.TrimSpace(expr), err)
+	}
+	return v, nil
+}
+
+// Value is the value of an expression: a string, or a boolean if IsBool is set.
+type Value struct {
+	Str    string
+	Bool   bool
+	IsBool bool
+}
+
+func (v Value) String() string {
+	if v.IsBool {
+		return fmt.Sprint(v.Bool)
+	}
+	return v.Str
+}
+
+func (v Value) truthy() bool {
+	if v.IsBool {
+		return v.Bool
+	}
+	return v.Str != ""
+}
+
+func boolValue(b bool) Value {
+	return Value{Bool: b, IsBool: true}
+}
+
+// tokenize splits an expression into operators, string literals (with their quotes), and words.
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'':
+			// Quotes are escaped by doubling them.
+			j := i + 1
+			for j < len(s) {
+				if s[j] == '\'' {
+					if j+1 < len(s) && s[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, s[i:min(j+1, len(s))])
+			i = j + 1
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "||"),
+			strings.HasPrefix(s[i:], "=="), strings.HasPrefix(s[i:], "!="):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.ContainsRune("!()", rune(c)):
+			tokens = append(tokens, s[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n'!()&|=", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				// A lone & | or =, which is not an operator.
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+// parser evaluates the subset of the GitHub Actions expression syntax used by generated workflows:
+// literals, context properties, ! == != && || and parentheses, and the status functions.
+type parser struct {
+	ctx    *Context
+	tokens []string
+	pos    int
+
+	// statusChecked is set if the expression calls a status function, which replaces the implicit success().
+	statusChecked bool
+}
+
+func (p *parser) parse() (Value, error) {
+	v, err := p.or()
+	if err != nil {
+		return Value{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return Value{}, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return v, nil
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// or and and return the deciding operand, as in GitHub Actions, so that a && 'x' || 'y' selects a string.
+func (p *parser) or() (Value, error) {
+	v, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.next()
+		var rhs Value
+		rhs, err = p.and()
+		if !v.truthy() {
+			v = rhs
+		}
+	}
+	return v, err
+}
+
+func (p *parser) and() (Value, error) {
+	v, err := p.comparison()
+	for err == nil && p.peek() == "&&" {
+		p.next()
+		var rhs Value
+		rhs, err = p.comparison()
+		if v.truthy() {
+			v = rhs
+		}
+	}
+	return v, err
+}
+
+func (p *parser) comparison() (Value, error) {
+	v, err := p.unary()
+	if err != nil {
+		return Value{}, err
+	}
+	if op := p.peek(); op == "==" || op == "!=" {
+		p.next()
+		rhs, err := p.unary()
+		if err != nil {
+			return Value{}, err
+		}
+		// String comparisons are case insensitive.
+		equal := strings.EqualFold(v.String(), rhs.String())
+		return boolValue(equal == (op == "==")), nil
+	}
+	return v, nil
+}
+
+func (p *parser) unary() (Value, error) {
+	if p.peek() == "!" {
+		p.next()
+		v, err := p.unary()
+		return boolValue(!v.truthy()), err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Value, error) {
+	t := p.next()
+	switch {
+	case t == "":
+		return Value{}, fmt.Errorf("unexpected end of expression")
+	case t == "(":
+		v, err := p.or()
+		if err != nil {
+			return Value{}, err
+		}
+		if p.next() != ")" {
+			return Value{}, fmt.Errorf("missing )")
+		}
+		return v, nil
+	case strings.HasPrefix(t, "'"):
+		if len(t) < 2 || !strings.HasSuffix(t, "'") {
+			return Value{}, fmt.Errorf("unterminated string %s", t)
+		}
+		return Value{Str: strings.ReplaceAll(t[1:len(t)-1], "''", "'")}, nil
+	case t == "true" || t == "false":
+		return boolValue(t == "true"), nil
+	case p.peek() == "(":
+		p.next()
+		if p.next() != ")" {
+			return Value{}, fmt.Errorf("function %s with arguments is not supported", t)
+		}
+		return p.call(t)
+	}
+	return p.property(t)
+}
+
+// call evaluates a status function.
+func (p *parser) call(name string) (Value, error) {
+	p.statusChecked = true
+	switch name {
+	case "success":
+		return boolValue(!p.ctx.Failed), nil
+	case "failure":
+		return boolValue(p.ctx.Failed), nil
+	case "always":
+		return boolValue(true), nil
+	case "cancelled":
+		return boolValue(false), nil
+	}
+	return Value{}, fmt.Errorf("function %s is not supported", name)
+}
+
+// property evaluates a context property such as matrix.go.
+func (p *parser) property(t string) (Value, error) {
+	context, name, ok := strings.Cut(t, ".")
+	var values map[string]string
+	switch context {
+	case "matrix":
+		values = p.ctx.Matrix
+	case "github":
+		values = p.ctx.GitHub
+	case "runner":
+		values = p.ctx.Runner
+	default:
+		return Value{}, fmt.Errorf("%q is not supported", t)
+	}
+	if !ok {
+		return Value{}, fmt.Errorf("%q is not supported", t)
+	}
+	// Missing properties are empty, as in GitHub Actions.
+	return Value{Str: values[name]}, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import "testing"
+
+func TestExpand(t *testing.T) {
+	ctx := &Context{
+		Matrix: map[string]string{"go": "tip", "os": "ubuntu-latest"},
+		GitHub: map[string]string{"ref_name": "v1.2.3"},
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"no expressions", "no expressions"},
+		{"${{ matrix.os }}", "ubuntu-latest"},
+		{"tag ${{ github.ref_name }}!", "tag v1.2.3!"},
+		{"${{ matrix.go == 'tip' && 'stable' || matrix.go }}", "stable"},
+		{"${{ matrix.go != 'tip' && 'stable' || matrix.go }}", "tip"},
+		{"${{ matrix.missing }}", ""},
+		{"${{ 'it''s' }}", "it's"},
+		{"${{ matrix.go == 'TIP' }}", "true"},
+		{"${{ !(matrix.go == 'tip') }}", "false"},
+	}
+	for _, tt := range tests {
+		got, err := ctx.Expand(tt.in)
+		if err != nil {
+			t.Errorf("Expand(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"${{ env.FOO }}", "${{ hashFiles('go.sum') }}", "${{ matrix.go", "${{ (matrix.go }}"} {
+		if _, err := ctx.Expand(in); err == nil {
+			t.Errorf("Expand(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestIf(t *testing.T) {
+	tests := []struct {
+		condition string
+		failed    bool
+		want      bool
+	}{
+		{"", false, true},
+		{"", true, false},
+		{"matrix.go == '1.25'", false, true},
+		{"${{ matrix.go == '1.25' }}", false, true},
+		{"matrix.go == '1.25'", true, false},
+		{"matrix.go != '1.25'", false, false},
+		{"failure()", false, false},
+		{"failure()", true, true},
+		{"always()", true, true},
+		{"success() && matrix.go == '1.25'", false, true},
+	}
+	for _, tt := range tests {
+		ctx := &Context{Matrix: map[string]string{"go": "1.25"}, Failed: tt.failed}
+		got, err := ctx.If(tt.condition)
+		if err != nil {
+			t.Errorf("If(%q) error = %v", tt.condition, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("If(%q) with failed=%v = %v, want %v", tt.condition, tt.failed, got, tt.want)
+		}
+	}
+}
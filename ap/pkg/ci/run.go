@@ -0,0 +1,354 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"k8s.io/klog/v2"
+)
+
+// Workspace is where the checkout is mounted in job containers, as GITHUB_WORKSPACE.
+const Workspace = "/workspace"
+
+// Options configures how jobs are run.
+type Options struct {
+	// Engine is the container engine, docker by default.
+	Engine string
+	// Image replaces the image of every job.
+	Image string
+	// KeepWorkspace keeps the checkouts of all jobs; those of failed jobs are always kept,
+	// as their test results would be uploaded in CI.
+	KeepWorkspace bool
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Result is the outcome of a job.
+type Result struct {
+	Name     string
+	Duration time.Duration
+	// Skipped is why the job did not run, if it did not.
+	Skipped string
+	Err     error
+	// Workspace is the checkout the job ran in, if it was kept.
+	Workspace string
+}
+
+// Run runs each of the plans in a fresh copy of the working tree at repoRoot, one after the other.
+func Run(ctx context.Context, repoRoot string, plans []*Plan, opt Options) []Result {
+	if opt.Engine == "" {
+		opt.Engine = "docker"
+	}
+	if opt.Stdout == nil {
+		opt.Stdout = os.Stdout
+	}
+	if opt.Stderr == nil {
+		opt.Stderr = os.Stderr
+	}
+
+	var results []Result
+	for _, plan := range plans {
+		result := Result{Name: plan.Name, Skipped: plan.Skip}
+		if plan.Skip == "" {
+			ok, err := tools.Require(ctx, tools.Requirement{Tool: opt.Engine, Task: "run CI job " + plan.Name})
+			switch {
+			case err != nil:
+				result.Err = err
+			case !ok:
+				result.Skipped = opt.Engine + " is not installed"
+			default:
+				start := time.Now()
+				result.Workspace, result.Err = runJob(ctx, repoRoot, plan, opt)
+				result.Duration = time.Since(start)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// PrintResults prints a table of the results.
+func PrintResults(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tRESULT\tDURATION\tWORKSPACE")
+	for _, r := range results {
+		status := "passed"
+		switch {
+		case r.Skipped != "":
+			status = "skipped: " + r.Skipped
+		case r.Err != nil:
+			status = "failed"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, status, r.Duration.Round(time.Second), r.Workspace)
+	}
+	return tw.Flush()
+}
+
+// runJob runs the steps of plan in a container, returning the checkout if it is kept.
+func runJob(ctx context.Context, repoRoot string, plan *Plan, opt Options) (string, error) {
+	workspace, err := os.MkdirTemp("", "ap-ci-")
+	if err != nil {
+		return "", err
+	}
+	keep := opt.KeepWorkspace
+	defer func() {
+		if !keep {
+			os.RemoveAll(workspace)
+		}
+	}()
+
+	fmt.Fprintf(opt.Stderr, "==> %s\n", plan.Name)
+	if err := checkout(ctx, repoRoot, workspace); err != nil {
+		return "", fmt.Errorf("failed to check out %s: %w", repoRoot, err)
+	}
+
+	image := plan.Image
+	if opt.Image != "" {
+		image = opt.Image
+	}
+	container, err := startContainer(ctx, opt.Engine, image, workspace)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := exec.Command(opt.Engine, "rm", "--force", container).Run(); err != nil {
+			klog.Warningf("Failed to remove container %s: %v", container, err)
+		}
+	}()
+
+	err = runSteps(ctx, opt, container, plan)
+	if err != nil {
+		keep = true
+	}
+	if !keep {
+		return "", err
+	}
+	return workspace, err
+}
+
+// runSteps runs the steps of plan in container, stopping at the first failure apart from steps that run on failure.
+func runSteps(ctx context.Context, opt Options, container string, plan *Plan) error {
+	var firstErr error
+	for i, step := range plan.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+		run, err := plan.ctx.If(step.If)
+		if err != nil {
+			return err
+		}
+		if !run {
+			continue
+		}
+		switch {
+		case isAction(step.Uses, "actions/checkout"):
+			// The workspace is already a copy of the working tree.
+			continue
+		case step.Uses != "":
+			fmt.Fprintf(opt.Stderr, "--- %s: skipping %s, as actions are not run locally\n", name, step.Uses)
+			continue
+		}
+
+		fmt.Fprintf(opt.Stderr, "--- %s\n", name)
+		if err := runStep(ctx, opt, container, plan, step); err != nil {
+			err = fmt.Errorf("step %q failed: %w", name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			plan.ctx.Failed = true
+		}
+	}
+	return firstErr
+}
+
+// runStep runs the script of a step with bash, as GitHub Actions does, with the job and step env.
+func runStep(ctx context.Context, opt Options, container string, plan *Plan, step Step) error {
+	script, err := plan.ctx.Expand(step.Run)
+	if err != nil {
+		return err
+	}
+	dir, err := plan.ctx.Expand(step.WorkingDirectory)
+	if err != nil {
+		return err
+	}
+	env := maps.Clone(plan.Env)
+	for k, v := range step.Env {
+		if env[k], err = plan.ctx.Expand(v); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"exec", "--workdir", path.Join(Workspace, dir)}
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		args = append(args, "--env", k+"="+env[k])
+	}
+	args = append(args, container, "bash", "--noprofile", "--norc", "-eo", "pipefail", "-c", stepPrelude+script)
+	cmd := exec.CommandContext(ctx, opt.Engine, args...)
+	cmd.Stdout = opt.Stdout
+	cmd.Stderr = opt.Stderr
+	return cmd.Run()
+}
+
+// stepPrelude applies the PATH entries and variables that earlier steps added with GITHUB_PATH and GITHUB_ENV.
+const stepPrelude = `while IFS= read -r p; do [ -n "$p" ] && PATH="$p:$PATH"; done < "$GITHUB_PATH"
+set -a; . "$GITHUB_ENV"; set +a
+`
+
+// containerEnv is the environment of every step, as set by GitHub Actions runners.
+var containerEnv = map[string]string{
+	"CI":               "true",
+	"GITHUB_ACTIONS":   "true",
+	"GITHUB_WORKSPACE": Workspace,
+	"GITHUB_PATH":      "/tmp/github_path",
+	"GITHUB_ENV":       "/tmp/github_env",
+	"RUNNER_OS":        "Linux",
+	"HOME":             "/tmp",
+	// The workspace is owned by the user running ap, which git would otherwise refuse to use.
+	"GIT_CONFIG_COUNT":   "1",
+	"GIT_CONFIG_KEY_0":   "safe.directory",
+	"GIT_CONFIG_VALUE_0": "*",
+}
+
+// startContainer starts a container that the steps are run in, with the workspace mounted.
+// It runs as the current user, so that the workspace can be removed afterwards.
+func startContainer(ctx context.Context, engine, image, workspace string) (string, error) {
+	args := []string{"run", "--detach", "--rm", "--volume", workspace + ":" + Workspace, "--workdir", Workspace}
+	if uid := os.Getuid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	for _, k := range slices.Sorted(maps.Keys(containerEnv)) {
+		args = append(args, "--env", k+"="+containerEnv[k])
+	}
+	args = append(args, image, "sh", "-c", `touch "$GITHUB_PATH" "$GITHUB_ENV" && exec sleep infinity`)
+
+	klog.V(2).Infof("Running %s %s", engine, strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, engine, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to start a %s container: %w: %s", image, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitHubContext returns the github context of jobs run from the working tree at repoRoot,
+// as if its current branch had been pushed.
+func GitHubContext(ctx context.Context, repoRoot string) (map[string]string, error) {
+	sha, err := git(ctx, repoRoot, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := git(ctx, repoRoot, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"event_name": "push",
+		"ref_name":   strings.TrimSpace(branch),
+		"sha":        strings.TrimSpace(sha),
+		"workspace":  Workspace,
+	}, nil
+}
+
+// checkout copies the files of the working tree at repoRoot that are not ignored into dir,
+// and commits them to a new repository, like the fresh checkout of a CI job, including uncommitted changes.
+func checkout(ctx context.Context, repoRoot, dir string) error {
+	out, err := git(ctx, repoRoot, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(out, "\x00") {
+		if name == "" {
+			continue
+		}
+		if err := copyFile(filepath.Join(repoRoot, filepath.FromSlash(name)), filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "--all"},
+		{"-c", "user.name=ap", "-c", "user.email=ap@localhost", "commit", "--quiet", "--no-verify", "--allow-empty", "--message", "ap ci run"},
+	} {
+		if _, err := git(ctx, dir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies a regular file or symlink, creating its directory. Deleted files and directories
+// (submodules) are skipped.
+func copyFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	case info.Mode().IsRegular():
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return os.Chmod(dst, info.Mode().Perm())
+	}
+	return nil
+}
+
+// git runs git in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
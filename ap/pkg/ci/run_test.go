@@ -0,0 +1,57 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/gittest"
+)
+
+func TestCheckout(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	repo.WriteFile(".gitignore", ".build/\n")
+	repo.WriteFile("committed.txt", "committed\n")
+	repo.WriteFile("deleted.txt", "deleted\n")
+	repo.Git("add", ".")
+	repo.Git("commit", "-q", "-m", "initial")
+
+	repo.WriteFile("committed.txt", "modified\n")
+	repo.WriteFile("dir/untracked.txt", "untracked\n")
+	repo.WriteFile(".build/ignored.txt", "ignored\n")
+	if err := os.Remove(filepath.Join(repo.Dir, "deleted.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := checkout(t.Context(), repo.Dir, dir); err != nil {
+		t.Fatalf("checkout() error = %v", err)
+	}
+	for name, want := range map[string]string{"committed.txt": "modified\n", "dir/untracked.txt": "untracked\n"} {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"deleted.txt", ".build"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was checked out, want it to be left out", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		t.Errorf("checkout is not a git repository: %v", err)
+	}
+}
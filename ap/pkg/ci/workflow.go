@@ -0,0 +1,223 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ci runs the jobs of the generated GitHub Actions workflows locally, in containers,
+// so that CI failures can be reproduced without pushing commits.
+package ci
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultWorkflow is the workflow run by default, relative to the repository root.
+const DefaultWorkflow = ".github/workflows/ci-presubmits.yaml"
+
+// DefaultImage is the image of jobs that do not set up Go, as for the generated Prow and Cloud Build jobs.
+const DefaultImage = "golang"
+
+// Workflow is the part of a GitHub Actions workflow that is run locally.
+type Workflow struct {
+	Name string         `json:"name,omitempty"`
+	Jobs map[string]Job `json:"jobs,omitempty"`
+}
+
+// Job is a job of a workflow.
+type Job struct {
+	RunsOn   string            `json:"runs-on,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	Strategy *Strategy         `json:"strategy,omitempty"`
+	Steps    []Step            `json:"steps,omitempty"`
+}
+
+// Strategy is the matrix a job runs in.
+type Strategy struct {
+	Matrix map[string][]string `json:"matrix,omitempty"`
+}
+
+// Step is a step of a job, which either uses an action or runs a script.
+type Step struct {
+	Name             string            `json:"name,omitempty"`
+	If               string            `json:"if,omitempty"`
+	Uses             string            `json:"uses,omitempty"`
+	With             map[string]string `json:"with,omitempty"`
+	Run              string            `json:"run,omitempty"`
+	WorkingDirectory string            `json:"working-directory,omitempty"`
+	Env              map[string]string `json:"env,omitempty"`
+}
+
+// LoadWorkflow parses the workflow file at path.
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var w Workflow
+	if err := yaml.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return &w, nil
+}
+
+// JobNames returns the names of the jobs of the workflow, sorted.
+func (w *Workflow) JobNames() []string {
+	return slices.Sorted(maps.Keys(w.Jobs))
+}
+
+// Plan is a job, for one combination of its matrix, as it runs locally.
+type Plan struct {
+	// Name is the job name, followed by its matrix values if it has any.
+	Name  string
+	Image string
+	Env   map[string]string
+	Steps []Step
+
+	// Skip is why the job cannot run locally, e.g. because it runs on macOS.
+	Skip string
+
+	ctx *Context
+}
+
+// PlanJobs returns the plans of the named jobs, or of all of them if names is empty, for each of their matrix combinations.
+// github are the values of the github context.
+func (w *Workflow) PlanJobs(repoRoot string, names []string, github map[string]string) ([]*Plan, error) {
+	if len(names) == 0 {
+		names = w.JobNames()
+	}
+	var plans []*Plan
+	for _, name := range names {
+		job, ok := w.Jobs[name]
+		if !ok {
+			return nil, fmt.Errorf("job %q not found; the jobs are %s", name, strings.Join(w.JobNames(), ", "))
+		}
+		for _, matrix := range job.matrixCombinations() {
+			plan, err := job.plan(repoRoot, name, &Context{Matrix: matrix, GitHub: github, Runner: map[string]string{"os": "Linux"}})
+			if err != nil {
+				return nil, fmt.Errorf("job %s: %w", name, err)
+			}
+			plans = append(plans, plan)
+		}
+	}
+	return plans, nil
+}
+
+// matrixCombinations returns every combination of the values of the matrix, with keys in sorted order.
+func (j *Job) matrixCombinations() []map[string]string {
+	combinations := []map[string]string{{}}
+	if j.Strategy == nil {
+		return combinations
+	}
+	for _, key := range slices.Sorted(maps.Keys(j.Strategy.Matrix)) {
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, value := range j.Strategy.Matrix[key] {
+				m := maps.Clone(c)
+				m[key] = value
+				next = append(next, m)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func (j *Job) plan(repoRoot, name string, ctx *Context) (*Plan, error) {
+	plan := &Plan{Name: name, Image: DefaultImage, Env: map[string]string{}, ctx: ctx}
+	if len(ctx.Matrix) > 0 {
+		var values []string
+		for _, key := range slices.Sorted(maps.Keys(ctx.Matrix)) {
+			values = append(values, key+"="+ctx.Matrix[key])
+		}
+		plan.Name += " (" + strings.Join(values, ", ") + ")"
+	}
+
+	runsOn, err := ctx.Expand(j.RunsOn)
+	if err != nil {
+		return nil, err
+	}
+	if runsOn != "" && !strings.HasPrefix(runsOn, "ubuntu-") {
+		plan.Skip = fmt.Sprintf("runs on %s, and only Linux runners can be run locally", runsOn)
+	}
+	for k, v := range j.Env {
+		if plan.Env[k], err = ctx.Expand(v); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, step := range j.Steps {
+		if !isAction(step.Uses, "actions/setup-go") {
+			plan.Steps = append(plan.Steps, step)
+			continue
+		}
+		// Setup steps only depend on the matrix, so they select the image up front.
+		if ok, err := ctx.If(step.If); err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		image, err := goImage(repoRoot, ctx, step)
+		if err != nil {
+			return nil, err
+		}
+		plan.Image = image
+	}
+	return plan, nil
+}
+
+// goImage returns the golang image with the Go version installed by a setup-go step.
+func goImage(repoRoot string, ctx *Context, step Step) (string, error) {
+	if file := step.With["go-version-file"]; file != "" {
+		data, err := os.ReadFile(filepath.Join(repoRoot, file))
+		if err != nil {
+			return "", err
+		}
+		mod, err := modfile.ParseLax(file, data, nil)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case mod.Toolchain != nil:
+			return "golang:" + strings.TrimPrefix(mod.Toolchain.Name, "go"), nil
+		case mod.Go != nil:
+			return "golang:" + mod.Go.Version, nil
+		}
+		return DefaultImage, nil
+	}
+
+	version, err := ctx.Expand(step.With["go-version"])
+	if err != nil {
+		return "", err
+	}
+	switch version {
+	case "", "stable":
+		return DefaultImage, nil
+	case "oldstable":
+		return "", fmt.Errorf("go-version %s is not supported", version)
+	}
+	return "golang:" + version, nil
+}
+
+// isAction returns true if uses refers to the action, at any version.
+func isAction(uses, action string) bool {
+	name, _, _ := strings.Cut(uses, "@")
+	return name == action
+}
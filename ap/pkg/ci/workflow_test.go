@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testWorkflow = `
+name: CI Presubmits
+jobs:
+  ap-test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      fail-fast: false
+      matrix:
+        os: ['ubuntu-latest', 'macos-latest']
+        go: ['go.mod', '1.24']
+    steps:
+      - name: Checkout code
+        uses: actions/checkout@v4
+
+      - name: Setup Go
+        if: matrix.go == 'go.mod'
+        uses: actions/setup-go@v5
+        with:
+          go-version-file: 'go.mod'
+
+      - name: Setup Go
+        if: matrix.go != 'go.mod'
+        uses: actions/setup-go@v5
+        with:
+          go-version: ${{ matrix.go }}
+
+      - name: Run ap-test
+        run: ./dev/ci/presubmits/ap-test
+  shellcheck:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Run shellcheck
+        run: ./dev/ci/presubmits/shellcheck
+`
+
+func TestPlanJobs(t *testing.T) {
+	repoRoot := t.TempDir()
+	writeFile(t, filepath.Join(repoRoot, "go.mod"), "module example.com/m\n\ngo 1.25.0\n")
+	path := filepath.Join(repoRoot, "ci-presubmits.yaml")
+	writeFile(t, path, testWorkflow)
+
+	w, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("LoadWorkflow() error = %v", err)
+	}
+	if got, want := w.JobNames(), []string{"ap-test", "shellcheck"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("JobNames() = %v, want %v", got, want)
+	}
+
+	plans, err := w.PlanJobs(repoRoot, nil, nil)
+	if err != nil {
+		t.Fatalf("PlanJobs() error = %v", err)
+	}
+	type summary struct {
+		Name, Image string
+		Skipped     bool
+		Steps       int
+	}
+	var got []summary
+	for _, p := range plans {
+		got = append(got, summary{p.Name, p.Image, p.Skip != "", len(p.Steps)})
+	}
+	want := []summary{
+		{"ap-test (go=go.mod, os=ubuntu-latest)", "golang:1.25.0", false, 2},
+		{"ap-test (go=go.mod, os=macos-latest)", "golang:1.25.0", true, 2},
+		{"ap-test (go=1.24, os=ubuntu-latest)", "golang:1.24", false, 2},
+		{"ap-test (go=1.24, os=macos-latest)", "golang:1.24", true, 2},
+		{"shellcheck", DefaultImage, false, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanJobs() =\n%v\nwant\n%v", got, want)
+	}
+
+	plans, err = w.PlanJobs(repoRoot, []string{"shellcheck"}, nil)
+	if err != nil || len(plans) != 1 || plans[0].Name != "shellcheck" {
+		t.Errorf("PlanJobs(shellcheck) = %v, %v, want the shellcheck job", plans, err)
+	}
+	if _, err := w.PlanJobs(repoRoot, []string{"missing"}, nil); err == nil {
+		t.Errorf("PlanJobs(missing) succeeded, want an error")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
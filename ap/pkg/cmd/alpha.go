@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"context"
+	"os"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox"
 	"github.com/spf13/cobra"
@@ -38,6 +39,9 @@ func BuildAlphaCommand(rootOpt *RootOptions) *cobra.Command {
 	}
 
 	cmd.AddCommand(BuildSandboxCommand(&opt))
+	cmd.AddCommand(BuildJanitorCommand(&opt))
+	cmd.AddCommand(BuildRegeneratePRCommand(&opt))
+	cmd.AddCommand(BuildBudgetsCommand(&opt))
 
 	return cmd
 }
@@ -61,6 +65,8 @@ func BuildSandboxCommand(alphaOpt *AlphaOptions) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(BuildSandboxLogsCommand(&opt))
+
 	return cmd
 }
 
@@ -71,3 +77,35 @@ func RunSandbox(ctx context.Context, opt SandboxOptions, args []string) error {
 	}
 	return sandbox.Run(ctx, opt.RepoRoot, args)
 }
+
+// SandboxLogsOptions holds the configuration for the "sandbox logs" command.
+type SandboxLogsOptions struct {
+	*SandboxOptions
+
+	Audit bool
+}
+
+// BuildSandboxLogsCommand constructs the cobra command for "sandbox logs".
+func BuildSandboxLogsCommand(sandboxOpt *SandboxOptions) *cobra.Command {
+	opt := SandboxLogsOptions{
+		SandboxOptions: sandboxOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print the logs of the sandbox pod",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunSandboxLogs(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opt.Audit, "audit", opt.Audit, "Print the audit log of the commands run in the sandbox")
+
+	return cmd
+}
+
+// RunSandboxLogs executes the business logic for the "sandbox logs" command.
+func RunSandboxLogs(ctx context.Context, opt SandboxLogsOptions) error {
+	return sandbox.Logs(ctx, sandbox.LogsOptions{Audit: opt.Audit, Out: os.Stdout})
+}
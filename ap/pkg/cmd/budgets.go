@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/budget"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	"github.com/spf13/cobra"
+)
+
+// BudgetsOptions holds the configuration for the "budgets" command.
+type BudgetsOptions struct {
+	*AlphaOptions
+
+	// Window is the number of recent runs of each command shown in the trend.
+	Window int
+}
+
+// BuildBudgetsCommand constructs the cobra command for "budgets".
+func BuildBudgetsCommand(alphaOpt *AlphaOptions) *cobra.Command {
+	opt := BudgetsOptions{
+		AlphaOptions: alphaOpt,
+		Window:       20,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "budgets",
+		Short: "Report how long commands take against their time budgets in .ap/ci.yaml",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunBudgets(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().IntVar(&opt.Window, "window", opt.Window, "Number of recent runs of each command to show in the trend")
+
+	return cmd
+}
+
+// RunBudgets executes the business logic for the "budgets" command.
+func RunBudgets(ctx context.Context, opt BudgetsOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	cfg, err := generate.LoadCIConfig(opt.RepoRoot)
+	if err != nil {
+		return err
+	}
+	runs, err := budget.ReadHistory(budget.HistoryPath(opt.RepoRoot))
+	if err != nil {
+		return err
+	}
+	return budget.PrintReport(os.Stdout, cfg.Budgets, runs, opt.Window)
+}
+
+// trackBudget checks a completed run of cmd against its budget in .ap/ci.yaml.
+// Dry runs are not tracked, as they do not do the work the budget is for.
+func trackBudget(opt *RootOptions, cmd *cobra.Command, start time.Time) error {
+	if opt.RepoRoot == "" || opt.DryRun {
+		return nil
+	}
+	elapsed := time.Since(start)
+
+	cfg, err := generate.LoadCIConfig(opt.RepoRoot)
+	if err != nil {
+		return err
+	}
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	return cfg.Budgets.Track(budget.HistoryPath(opt.RepoRoot), command, start, elapsed)
+}
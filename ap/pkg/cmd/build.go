@@ -8,7 +8,7 @@
 //
 // Unless required by applicable law or agreed to in writing, software
 // distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either expressGoogle LLC or its affiliates. All rights reserved.
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
@@ -26,6 +26,11 @@ import (
 // BuildOptions holds the configuration for the "build" command.
 type BuildOptions struct {
 	*RootOptions
+
+	// Push pushes the built images to $IMAGE_PREFIX.
+	Push bool
+	// VerifyReproducible builds each image twice instead, checking that both builds have the same digest.
+	VerifyReproducible bool
 }
 
 // BuildBuildCommand constructs the cobra command for "build".
@@ -43,6 +48,9 @@ func BuildBuildCommand(rootOpt *RootOptions) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&opt.Push, "push", opt.Push, "Push images to $IMAGE_PREFIX, recording their digests in .build/images/digests.json")
+	cmd.Flags().BoolVar(&opt.VerifyReproducible, "verify-reproducible", opt.VerifyReproducible, "Instead of building, build each image twice from scratch, reproducibly and without pushing, and fail if the digests differ")
+
 	return cmd
 }
 
@@ -51,8 +59,23 @@ func RunBuild(ctx context.Context, opt BuildOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
-	for _, apRoot := range opt.APRoots {
-		if err := images.Build(ctx, apRoot, false); err != nil {
+	if opt.VerifyReproducible {
+		if opt.Push {
+			return fmt.Errorf("--verify-reproducible does not push; it cannot be combined with --push")
+		}
+		return opt.forEachAPRoot(func(apRoot string) error {
+			return images.VerifyReproducible(ctx, apRoot)
+		})
+	}
+	report := opt.dryRunReport()
+	err := opt.forEachAPRoot(func(apRoot string) error {
+		// A dry run still builds, but does not push.
+		push := opt.Push
+		if report != nil && push {
+			push = false
+			report.Addf("push images built in %s", apRoot)
+		}
+		if _, err := images.Build(ctx, apRoot, push); err != nil {
 			return err
 		}
 
@@ -61,9 +84,14 @@ func RunBuild(ctx context.Context, opt BuildOptions) error {
 		if err != nil {
 			return fmt.Errorf("failed to discover build tasks in %s: %w", apRoot, err)
 		}
-		if err := tasks.Run(ctx, apRoot, buildTasks); err != nil {
-			return err
+		if report != nil {
+			// Scripts cannot be previewed, so we tell them to skip any side effects themselves.
+			tasks.AddEnv(buildTasks, tasks.DryRunEnv+"=true")
 		}
+		return tasks.Run(ctx, apRoot, buildTasks)
+	})
+	if err != nil {
+		return err
 	}
-	return nil
+	return opt.finishDryRun(report)
 }
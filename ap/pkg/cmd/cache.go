@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/largefiles"
+	"github.com/spf13/cobra"
+)
+
+// CacheOptions holds the configuration for the "cache" command.
+type CacheOptions struct {
+	*RootOptions
+}
+
+// BuildCacheCommand constructs the cobra command for "cache".
+func BuildCacheCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := CacheOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and prune the cache of formatting and build results, which has a namespace per repository",
+	}
+
+	cmd.AddCommand(BuildCacheStatsCommand(&opt))
+	cmd.AddCommand(BuildCachePruneCommand(&opt))
+
+	return cmd
+}
+
+// CacheStatsOptions holds the configuration for the "cache stats" command.
+type CacheStatsOptions struct {
+	*CacheOptions
+}
+
+// BuildCacheStatsCommand constructs the cobra command for "cache stats".
+func BuildCacheStatsCommand(cacheOpt *CacheOptions) *cobra.Command {
+	opt := CacheStatsOptions{
+		CacheOptions: cacheOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show the size and last use of the cache of each repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunCacheStats(cmd.Context(), opt)
+		},
+	}
+
+	return cmd
+}
+
+// RunCacheStats executes the business logic for the "cache stats" command.
+func RunCacheStats(ctx context.Context, opt CacheStatsOptions) error {
+	dir, err := cache.Dir()
+	if err != nil {
+		return err
+	}
+	namespaces, err := cache.Namespaces()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tSIZE\tLAST USED")
+	var total int64
+	for _, ns := range namespaces {
+		root := ns.Root
+		if root == "" {
+			root = "(unknown) " + ns.Dir
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", root, largefiles.FormatSize(ns.Size), ns.LastUsed.Format(time.DateTime))
+		total += ns.Size
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d repositories, %s in %s\n", len(namespaces), largefiles.FormatSize(total), dir)
+	return nil
+}
+
+// CachePruneOptions holds the configuration for the "cache prune" command.
+type CachePruneOptions struct {
+	*CacheOptions
+
+	// MaxAge evicts the caches of repositories not used for longer.
+	MaxAge time.Duration
+
+	// MaxSize evicts the least recently used caches until the cache is no larger, e.g. "512MB".
+	MaxSize string
+}
+
+// BuildCachePruneCommand constructs the cobra command for "cache prune".
+func BuildCachePruneCommand(cacheOpt *CacheOptions) *cobra.Command {
+	opt := CachePruneOptions{
+		CacheOptions: cacheOpt,
+		MaxAge:       cache.DefaultPruneOptions.MaxAge,
+		MaxSize:      largefiles.FormatSize(cache.DefaultPruneOptions.MaxSize),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict the caches of deleted, unused and least recently used repositories",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunCachePrune(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opt.MaxAge, "max-age", opt.MaxAge, "Evict the caches of repositories not used for longer than this (0 for no limit)")
+	cmd.Flags().StringVar(&opt.MaxSize, "max-size", opt.MaxSize, "Evict the least recently used caches until the cache is no larger than this (0 for no limit)")
+
+	return cmd
+}
+
+// RunCachePrune executes the business logic for the "cache prune" command.
+func RunCachePrune(ctx context.Context, opt CachePruneOptions) error {
+	maxSize, err := largefiles.ParseSize(opt.MaxSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-size: %w", err)
+	}
+	report := opt.dryRunReport()
+	evicted, err := cache.Prune(cache.PruneOptions{MaxAge: opt.MaxAge, MaxSize: maxSize, DryRun: report != nil})
+	if err != nil {
+		return err
+	}
+
+	var freed int64
+	for _, ns := range evicted {
+		root := ns.Root
+		if root == "" {
+			root = ns.Dir
+		}
+		if report != nil {
+			report.Addf("evict the cache of %s (%s)", root, largefiles.FormatSize(ns.Size))
+		} else {
+			fmt.Printf("Evicted the cache of %s (%s)\n", root, largefiles.FormatSize(ns.Size))
+		}
+		freed += ns.Size
+	}
+	if report == nil {
+		fmt.Printf("Freed %s\n", largefiles.FormatSize(freed))
+	}
+	return opt.finishDryRun(report)
+}
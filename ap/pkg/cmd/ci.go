@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/ci"
+	"github.com/spf13/cobra"
+)
+
+// CIOptions holds the configuration for the "ci" command.
+type CIOptions struct {
+	*RootOptions
+}
+
+// BuildCICommand constructs the cobra command for "ci".
+func BuildCICommand(rootOpt *RootOptions) *cobra.Command {
+	opt := CIOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Run the generated CI jobs locally",
+	}
+
+	cmd.AddCommand(BuildCIRunCommand(&opt))
+
+	return cmd
+}
+
+// CIRunOptions holds the configuration for the "ci run" command.
+type CIRunOptions struct {
+	*CIOptions
+
+	// Workflow is the workflow file to run jobs from, relative to the repository root.
+	Workflow string
+	// Image replaces the container image of every job.
+	Image string
+	// Engine is the container engine.
+	Engine string
+	// KeepWorkspace keeps the checkouts of jobs that pass, as well as those of jobs that fail.
+	KeepWorkspace bool
+	// List prints the jobs instead of running them.
+	List bool
+}
+
+// BuildCIRunCommand constructs the cobra command for "ci run".
+func BuildCIRunCommand(ciOpt *CIOptions) *cobra.Command {
+	opt := CIRunOptions{
+		CIOptions: ciOpt,
+		Workflow:  ci.DefaultWorkflow,
+		Engine:    "docker",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "run [job...]",
+		Short: "Run jobs of the generated GitHub Actions workflow in containers, as CI would",
+		Long: `Run jobs of the generated GitHub Actions workflow locally, each in a container with a fresh copy of the
+working tree (including uncommitted changes), running the same steps with the same env.
+All the jobs run if none are named, in each combination of their matrix that runs on Linux.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunCIRun(cmd.Context(), opt, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Workflow, "workflow", opt.Workflow, "Workflow file to run jobs from, relative to the repository root")
+	cmd.Flags().StringVar(&opt.Image, "image", opt.Image, "Container image to run every job in, instead of the one matching its Go version")
+	cmd.Flags().StringVar(&opt.Engine, "engine", opt.Engine, "Container engine to run jobs with, e.g. podman")
+	cmd.Flags().BoolVar(&opt.KeepWorkspace, "keep-workspace", opt.KeepWorkspace, "Keep the checkouts of jobs that pass (those of failed jobs are always kept)")
+	cmd.Flags().BoolVar(&opt.List, "list", opt.List, "List the jobs and the images they run in, without running them")
+
+	return cmd
+}
+
+// RunCIRun executes the business logic for the "ci run" command.
+func RunCIRun(ctx context.Context, opt CIRunOptions, jobs []string) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	workflow, err := ci.LoadWorkflow(filepath.Join(opt.RepoRoot, opt.Workflow))
+	if err != nil {
+		return fmt.Errorf("failed to load the workflow (run ap generate to create it): %w", err)
+	}
+	github, err := ci.GitHubContext(ctx, opt.RepoRoot)
+	if err != nil {
+		return err
+	}
+	plans, err := workflow.PlanJobs(opt.RepoRoot, jobs, github)
+	if err != nil {
+		return err
+	}
+
+	if opt.List {
+		for _, plan := range plans {
+			if plan.Skip != "" {
+				fmt.Printf("%s\tskipped: %s\n", plan.Name, plan.Skip)
+				continue
+			}
+			fmt.Printf("%s\t%s\n", plan.Name, plan.Image)
+		}
+		return nil
+	}
+
+	results := ci.Run(ctx, opt.RepoRoot, plans, ci.Options{
+		Engine:        opt.Engine,
+		Image:         opt.Image,
+		KeepWorkspace: opt.KeepWorkspace,
+	})
+	fmt.Fprintln(os.Stderr)
+	if err := ci.PrintResults(os.Stderr, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", r.Name, r.Err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d jobs failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/configcheck"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// checksConfigAnnotation marks commands that check the config files themselves,
+// so that they are not also checked before the command runs.
+const checksConfigAnnotation = "ap.checks-config"
+
+// ConfigOptions holds the configuration for the "config" command.
+type ConfigOptions struct {
+	*RootOptions
+}
+
+// BuildConfigCommand constructs the cobra command for "config".
+func BuildConfigCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := ConfigOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with the .ap config files",
+	}
+
+	cmd.AddCommand(BuildConfigValidateCommand(&opt))
+	cmd.AddCommand(BuildConfigSchemaCommand(&opt))
+	cmd.AddCommand(BuildConfigShowCommand(&opt))
+
+	return cmd
+}
+
+// ConfigValidateOptions holds the configuration for the "config validate" command.
+type ConfigValidateOptions struct {
+	*ConfigOptions
+
+	// Output is the format of the findings: text, github, sarif or junit.
+	Output string
+}
+
+// BuildConfigValidateCommand constructs the cobra command for "config validate".
+func BuildConfigValidateCommand(configOpt *ConfigOptions) *cobra.Command {
+	opt := ConfigValidateOptions{
+		ConfigOptions: configOpt,
+		Output:        string(findings.FormatText),
+	}
+
+	cmd := &cobra.Command{
+		Use:         "validate",
+		Short:       "Report unknown keys and type errors in the .ap config files of the repository",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{checksConfigAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunConfigValidate(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Output, "output", opt.Output, "Output format of the findings: text, github, sarif or junit")
+
+	return cmd
+}
+
+// RunConfigValidate executes the business logic for the "config validate" command.
+func RunConfigValidate(_ context.Context, opt ConfigValidateOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+	format, err := findings.ParseFormat(opt.Output)
+	if err != nil {
+		return err
+	}
+
+	found, err := configcheck.Validate(opt.RepoRoot)
+	if err != nil {
+		return err
+	}
+	findings.Relativize(found, opt.RepoRoot)
+	if err := findings.Write(os.Stdout, format, "ap config validate", found); err != nil {
+		return err
+	}
+	if findings.HasErrors(found) {
+		return fmt.Errorf("invalid config files found")
+	}
+	return nil
+}
+
+// ConfigSchemaOptions holds the configuration for the "config schema" command.
+type ConfigSchemaOptions struct {
+	*ConfigOptions
+}
+
+// BuildConfigSchemaCommand constructs the cobra command for "config schema".
+func BuildConfigSchemaCommand(configOpt *ConfigOptions) *cobra.Command {
+	opt := ConfigSchemaOptions{
+		ConfigOptions: configOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:       "schema <file>",
+		Short:     "Print the JSON schema of a .ap config file, e.g. ci.yaml",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: configcheck.Files(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunConfigSchema(cmd.Context(), opt, args[0])
+		},
+	}
+
+	return cmd
+}
+
+// RunConfigSchema executes the business logic for the "config schema" command.
+func RunConfigSchema(_ context.Context, _ ConfigSchemaOptions, name string) error {
+	schema, err := configcheck.Schema(name)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(schema)
+	return err
+}
+
+// ConfigShowOptions holds the configuration for the "config show" command.
+type ConfigShowOptions struct {
+	*ConfigOptions
+
+	// Effective fills in the defaults of the settings that are not configured.
+	Effective bool
+}
+
+// BuildConfigShowCommand constructs the cobra command for "config show".
+func BuildConfigShowCommand(configOpt *ConfigOptions) *cobra.Command {
+	opt := ConfigShowOptions{
+		ConfigOptions: configOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the merged .ap/go.yaml of each ap root, including the settings inherited from enclosing roots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunConfigShow(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opt.Effective, "effective", opt.Effective, "Fill in the defaults of the settings that are not configured")
+
+	return cmd
+}
+
+// RunConfigShow executes the business logic for the "config show" command.
+func RunConfigShow(_ context.Context, opt ConfigShowOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	for i, apRoot := range opt.APRoots {
+		merged, layers, err := config.LoadMerged(apRoot)
+		if err != nil {
+			return err
+		}
+		var shown any = merged
+		if opt.Effective {
+			cfg, err := config.Load(apRoot)
+			if err != nil {
+				return err
+			}
+			shown = cfg.Effective()
+		}
+		data, err := yaml.Marshal(shown)
+		if err != nil {
+			return err
+		}
+
+		sources := "defaults only"
+		if len(layers) > 0 {
+			var rel []string
+			for _, layer := range layers {
+				rel = append(rel, relPath(opt.RepoRoot, layer))
+			}
+			sources = "from " + strings.Join(rel, ", ")
+		}
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Printf("# %s (%s)\n%s", relPath(opt.RepoRoot, apRoot), sources, data)
+	}
+	return nil
+}
+
+// relPath returns path relative to root, or path itself if it is not under root.
+func relPath(root string, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// checkConfigFiles warns about the problems in the config files of the ap roots before cmd runs,
+// as they are otherwise ignored when the files are loaded.
+func checkConfigFiles(opt *RootOptions, cmd *cobra.Command) {
+	if cmd.Annotations[checksConfigAnnotation] != "" {
+		return
+	}
+	for _, apRoot := range opt.APRoots {
+		found, err := configcheck.CheckAPRoot(apRoot)
+		if err != nil {
+			klog.Warningf("Failed to check the config files in %s: %v", apRoot, err)
+			continue
+		}
+		findings.Relativize(found, opt.RepoRoot)
+		for _, f := range found {
+			klog.Warning(f.String())
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/k8s"
 	"github.com/spf13/cobra"
@@ -27,6 +28,30 @@ import (
 // DeployOptions holds the configuration for the "deploy" command.
 type DeployOptions struct {
 	*RootOptions
+
+	// Profile selects a profile from .ap/deploy.yaml (e.g. staging).
+	Profile string
+
+	// Target overrides the kubeconfig, context and namespace from .ap/deploy.yaml.
+	Target k8s.Target
+
+	// Wait waits for Deployments, StatefulSets and DaemonSets to become ready.
+	Wait bool
+
+	// Prune deletes previously deployed resources that are no longer in any manifest.
+	Prune bool
+
+	// CreateNamespace creates the target namespace if it does not exist.
+	CreateNamespace bool
+
+	// FailOnConflicts fails on conflicts with fields owned by other field managers, instead of taking them over.
+	FailOnConflicts bool
+
+	// Diff prints the changes to the cluster before applying them.
+	Diff bool
+
+	// Canary is the percentage of traffic to send to canary copies of the Deployments, instead of updating them.
+	Canary int
 }
 
 // BuildDeployCommand constructs the cobra command for "deploy".
@@ -44,6 +69,17 @@ func BuildDeployCommand(rootOpt *RootOptions) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&opt.Profile, "profile", opt.Profile, "The profile from .ap/deploy.yaml to deploy (e.g. staging)")
+	cmd.Flags().StringVar(&opt.Target.Kubeconfig, "kubeconfig", opt.Target.Kubeconfig, "Path to the kubeconfig file to deploy with")
+	cmd.Flags().StringVar(&opt.Target.Context, "context", opt.Target.Context, "The kubeconfig context to deploy to")
+	cmd.Flags().StringVarP(&opt.Target.Namespace, "namespace", "n", opt.Target.Namespace, "The namespace for resources that do not specify one")
+	cmd.Flags().BoolVar(&opt.Wait, "wait", opt.Wait, "Wait for Deployments, StatefulSets and DaemonSets to become ready")
+	cmd.Flags().BoolVar(&opt.Prune, "prune", opt.Prune, "Delete previously deployed resources that are no longer in any manifest")
+	cmd.Flags().BoolVar(&opt.CreateNamespace, "create-namespace", opt.CreateNamespace, "Create the target namespace if it does not exist")
+	cmd.Flags().BoolVar(&opt.FailOnConflicts, "fail-on-conflicts", opt.FailOnConflicts, "Fail on conflicts with fields owned by other field managers, instead of taking the fields over")
+	cmd.Flags().BoolVar(&opt.Diff, "diff", opt.Diff, "Print the changes a server-side dry run reports for every manifest, and a summary of them, before applying any")
+	cmd.Flags().IntVar(&opt.Canary, "canary", opt.Canary, "Deploy canary copies of the Deployments behind a Service for this percentage of their traffic, instead of updating them")
+
 	return cmd
 }
 
@@ -52,19 +88,75 @@ func RunDeploy(ctx context.Context, opt DeployOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
+	if opt.Canary < 0 || opt.Canary >= 100 {
+		return fmt.Errorf("--canary must be a percentage between 1 and 99, got %d", opt.Canary)
+	}
+	if opt.Diff && opt.Canary != 0 {
+		return fmt.Errorf("--diff cannot be combined with --canary")
+	}
+
+	report := opt.dryRunReport()
+	err := opt.forEachAPRoot(func(apRoot string) error {
+		return deployAPRoot(ctx, opt, apRoot, report)
+	})
+	if err != nil {
+		return err
+	}
+	return opt.finishDryRun(report)
+}
+
+// deployAPRoot builds and deploys the ap root at apRoot, with the image settings of the selected profile.
+func deployAPRoot(ctx context.Context, opt DeployOptions, apRoot string, report *dryrun.Report) error {
+	cfg, err := k8s.LoadDeployConfig(apRoot)
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Profile(opt.Profile)
+	if err != nil {
+		return fmt.Errorf("deploy failed for %s: %w", apRoot, err)
+	}
+	restore, err := setEnv(profile.Env())
+	if err != nil {
+		return err
+	}
+	defer restore()
 
 	if os.Getenv("IMAGE_PREFIX") == "" {
-		return fmt.Errorf("IMAGE_PREFIX is not set; it is required for deploy")
+		return fmt.Errorf("IMAGE_PREFIX is not set; it is required for deploy (set it, or imagePrefix in a profile in .ap/deploy.yaml)")
 	}
 
-	for _, apRoot := range opt.APRoots {
-		// Deploy typically also builds
-		if err := images.Build(ctx, apRoot, true); err != nil {
-			return fmt.Errorf("build failed during deploy for %s: %w", apRoot, err)
+	// Deploy typically also builds; a dry run builds without pushing, so images are referenced by tag.
+	digests, err := images.Build(ctx, apRoot, report == nil)
+	if err != nil {
+		return fmt.Errorf("build failed during deploy for %s: %w", apRoot, err)
+	}
+	if err := k8s.Deploy(ctx, apRoot, k8s.DeployOptions{Digests: digests, Profile: opt.Profile, Target: opt.Target, WaitForRollouts: opt.Wait, Prune: opt.Prune, CreateNamespace: opt.CreateNamespace, FailOnConflicts: opt.FailOnConflicts, Diff: opt.Diff, Canary: opt.Canary, DryRun: report}); err != nil {
+		return fmt.Errorf("deploy failed for %s: %w", apRoot, err)
+	}
+	return nil
+}
+
+// setEnv sets the environment variables in vars, and returns a function restoring their previous values.
+func setEnv(vars map[string]string) (func(), error) {
+	var restores []func()
+	restore := func() {
+		for _, fn := range restores {
+			fn()
 		}
-		if err := k8s.Deploy(ctx, apRoot); err != nil {
-			return fmt.Errorf("deploy failed for %s: %w", apRoot, err)
+	}
+	for name, value := range vars {
+		previous, ok := os.LookupEnv(name)
+		if err := os.Setenv(name, value); err != nil {
+			restore()
+			return nil, err
 		}
+		restores = append(restores, func() {
+			if ok {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
 	}
-	return nil
+	return restore, nil
 }
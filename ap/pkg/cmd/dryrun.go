@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+)
+
+// dryRunReport returns a report for collecting the changes of a dry run, or nil if this is not a dry run.
+func (o *RootOptions) dryRunReport() *dryrun.Report {
+	if !o.DryRun {
+		return nil
+	}
+	return &dryrun.Report{}
+}
+
+// finishDryRun prints the report of a dry run (if any), and returns dryrun.ErrChanges
+// if changes would have been made and FailOnChanges is set.
+func (o *RootOptions) finishDryRun(report *dryrun.Report) error {
+	if report == nil {
+		return nil
+	}
+	report.Print(os.Stdout)
+	if o.FailOnChanges && len(report.Changes()) != 0 {
+		return dryrun.ErrChanges
+	}
+	return nil
+}
+
+// previewFileChanges runs fn on a copy of dir and records the files it would change in report,
+// relative to the repository root.
+func (o *RootOptions) previewFileChanges(ctx context.Context, report *dryrun.Report, dir string, fn func(ctx context.Context, dir string) error) error {
+	changes, err := dryrun.PreviewInCopy(ctx, dir, fn)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(o.RepoRoot, dir)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		report.Addf("%s %s", change.Kind, filepath.Join(rel, change.Path))
+	}
+	return nil
+}
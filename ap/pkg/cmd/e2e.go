@@ -16,15 +16,17 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 
-	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/e2e"
 	"github.com/spf13/cobra"
 )
 
 // E2eOptions holds the configuration for the "e2e" command.
 type E2eOptions struct {
 	*RootOptions
+
+	// KeepCluster leaves the cluster configured in .ap/e2e.yaml running after the tests.
+	KeepCluster bool
 }
 
 // BuildE2eCommand constructs the cobra command for "e2e".
@@ -42,6 +44,8 @@ func BuildE2eCommand(rootOpt *RootOptions) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&opt.KeepCluster, "keep-cluster", opt.KeepCluster, "Keep the e2e cluster running after the tests, e.g. for debugging")
+
 	return cmd
 }
 
@@ -50,20 +54,7 @@ func RunE2e(ctx context.Context, opt E2eOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
-	for _, apRoot := range opt.APRoots {
-		// Run test-e2e* scripts
-		e2eTasks, err := tasks.FindTaskScripts(apRoot, tasks.WithPrefix("test-e2e"))
-		if err != nil {
-			return fmt.Errorf("failed to discover e2e tasks in %s: %w", apRoot, err)
-		}
-
-		if len(e2eTasks) == 0 {
-			continue
-		}
-
-		if err := tasks.Run(ctx, apRoot, e2eTasks); err != nil {
-			return err
-		}
-	}
-	return nil
+	return opt.forEachAPRoot(func(apRoot string) error {
+		return e2e.Run(ctx, apRoot, e2e.Options{KeepCluster: opt.KeepCluster})
+	})
 }
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/download"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/execprofile"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// applyExecutionProfile selects the execution profile, from --execution-profile or the environment, and applies
+// its defaults: those of the flags that were not given, and the environment of ap and the commands it runs.
+func applyExecutionProfile(opt *RootOptions, cmd *cobra.Command) error {
+	name := opt.ExecutionProfile
+	if name == "" {
+		name = execprofile.Detect(os.Getenv)
+	}
+	var configured map[string]execprofile.Profile
+	if opt.RepoRoot != "" {
+		cfg, err := generate.LoadAPConfig(opt.RepoRoot)
+		if err != nil {
+			return err
+		}
+		configured = cfg.Profiles
+	}
+	p, err := execprofile.Resolve(name, configured)
+	if err != nil {
+		return err
+	}
+	opt.ExecutionProfile = name
+	opt.Profile = p
+	klog.V(2).Infof("Using the %s execution profile", name)
+
+	if p.Verbosity != nil && !cmd.Flags().Changed("v") {
+		if err := cmd.Flags().Set("v", strconv.Itoa(*p.Verbosity)); err != nil {
+			return fmt.Errorf("failed to set the verbosity of execution profile %q: %w", name, err)
+		}
+	}
+	env := map[string]string{execprofile.Env: name}
+	if p.CacheOff() {
+		env[cache.DisableEnv] = "off"
+	}
+	if p.Offline {
+		env[download.OfflineEnv] = "true"
+		env["GOPROXY"] = "off"
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
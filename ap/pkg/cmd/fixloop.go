@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/fixloop"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/format"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/spf13/cobra"
+)
+
+// FixLoopOptions holds the configuration for the "fixloop" command.
+type FixLoopOptions struct {
+	*RootOptions
+
+	// MaxIterations bounds the number of fix and verify rounds.
+	MaxIterations int
+	// JSON prints the summary as JSON.
+	JSON bool
+}
+
+// InitDefaults sets the default values for the "fixloop" command.
+func (o *FixLoopOptions) InitDefaults() {
+	o.MaxIterations = fixloop.DefaultMaxIterations
+}
+
+// BuildFixLoopCommand constructs the cobra command for "fixloop".
+func BuildFixLoopCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := FixLoopOptions{
+		RootOptions: rootOpt,
+	}
+	opt.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "fixloop",
+		Short: "Run the automatic fixers and lint until the tree is green, and summarize what is left",
+		Long: `Runs ap generate and ap format, then the checks of ap lint, repeating until no errors remain,
+the fixers stop changing files, or --max-iterations is reached.
+The summary lists the files that were fixed and the findings that still need attention, with hints.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunFixLoop(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().IntVar(&opt.MaxIterations, "max-iterations", opt.MaxIterations, "Maximum number of fix and verify rounds")
+	cmd.Flags().BoolVar(&opt.JSON, "json", opt.JSON, "Print the summary as JSON")
+
+	return cmd
+}
+
+// RunFixLoop executes the business logic for the "fixloop" command.
+func RunFixLoop(ctx context.Context, opt FixLoopOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+	if opt.MaxIterations < 1 {
+		return fmt.Errorf("--max-iterations must be at least 1, got %d", opt.MaxIterations)
+	}
+
+	var summary *fixloop.Summary
+	run := func(ctx context.Context, repoRoot string) error {
+		// In a dry run, repoRoot is a copy of the repository, so the ap roots are moved along with it.
+		var apRoots []string
+		for _, apRoot := range opt.APRoots {
+			rel, err := filepath.Rel(opt.RepoRoot, apRoot)
+			if err != nil {
+				return err
+			}
+			apRoots = append(apRoots, filepath.Join(repoRoot, rel))
+		}
+
+		var err error
+		summary, err = fixloop.Run(ctx, fixloop.Options{
+			RepoRoot:      repoRoot,
+			MaxIterations: opt.MaxIterations,
+			Fixers: []fixloop.Fixer{
+				{Name: "generate", Run: func(ctx context.Context) error { return generate.Run(ctx, repoRoot) }},
+				{Name: "format", Run: func(ctx context.Context) error {
+					for _, apRoot := range apRoots {
+						if err := format.Run(ctx, apRoot); err != nil {
+							return err
+						}
+					}
+					return nil
+				}},
+			},
+			Verify: func(ctx context.Context) ([]findings.Finding, error) {
+				return lintFindings(ctx, repoRoot, apRoots, nil)
+			},
+		})
+		return err
+	}
+
+	report := opt.dryRunReport()
+	if report != nil {
+		if err := opt.previewFileChanges(ctx, report, opt.RepoRoot, run); err != nil {
+			return err
+		}
+	} else if err := run(ctx, opt.RepoRoot); err != nil {
+		return err
+	}
+
+	if opt.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summary); err != nil {
+			return err
+		}
+	} else {
+		summary.Print(os.Stdout)
+	}
+	if err := opt.finishDryRun(report); err != nil {
+		return err
+	}
+	if !summary.Green {
+		return fmt.Errorf("errors remain after %d iteration(s) of ap fixloop", summary.Iterations)
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"context"
+	"os"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/format"
 	"github.com/spf13/cobra"
@@ -24,6 +25,12 @@ import (
 // FormatOptions holds the configuration for the "format" command.
 type FormatOptions struct {
 	*RootOptions
+
+	// Changed limits formatting to the files staged in git.
+	Changed bool
+
+	// Check reports the changes formatting would make, as unified diffs, and fails if there are any.
+	Check bool
 }
 
 // BuildFormatCommand constructs the cobra command for "format".
@@ -38,10 +45,16 @@ func BuildFormatCommand(rootOpt *RootOptions) *cobra.Command {
 		Short:   "Run formatting tasks",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !cmd.Flags().Changed("check") {
+				opt.Check = opt.Profile.Verify()
+			}
 			return RunFormat(cmd.Context(), opt)
 		},
 	}
 
+	cmd.Flags().BoolVar(&opt.Changed, "changed", opt.Changed, "Only format the files staged in git")
+	cmd.Flags().BoolVar(&opt.Check, "check", opt.Check, "Do not change any files; print the file header changes formatting would make as diffs, and fail if there are any (default: true in execution profiles in verify mode, e.g. ci)")
+
 	return cmd
 }
 
@@ -50,10 +63,42 @@ func RunFormat(ctx context.Context, opt FormatOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
-	for _, apRoot := range opt.APRoots {
-		if err := format.Run(ctx, apRoot); err != nil {
+	var changed []string
+	if opt.Changed {
+		files, err := opt.changedFiles(ctx)
+		if err != nil {
 			return err
 		}
+		changed = files
+	}
+
+	if opt.Check {
+		return opt.forEachAPRoot(func(apRoot string) error {
+			files := filesUnder(changed, apRoot)
+			if opt.Changed && len(files) == 0 {
+				return nil
+			}
+			return format.CheckFiles(ctx, apRoot, files, os.Stdout)
+		})
+	}
+
+	report := opt.dryRunReport()
+	err := opt.forEachAPRoot(func(apRoot string) error {
+		// Relative paths, so that a dry run formats the same files in its copy of the ap root.
+		files := filesUnder(changed, apRoot)
+		if opt.Changed && len(files) == 0 {
+			return nil
+		}
+		run := func(ctx context.Context, dir string) error {
+			return format.RunFiles(ctx, dir, files)
+		}
+		if report != nil {
+			return opt.previewFileChanges(ctx, report, apRoot, run)
+		}
+		return run(ctx, apRoot)
+	})
+	if err != nil {
+		return err
 	}
-	return nil
+	return opt.finishDryRun(report)
 }
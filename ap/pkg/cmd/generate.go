@@ -16,10 +16,13 @@ package cmd
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/format"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/refdocs"
 	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
 )
 
 // GenerateOptions holds the configuration for the "generate" command.
@@ -50,8 +53,45 @@ func RunGenerate(ctx context.Context, opt GenerateOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
-	if err := generate.Run(ctx, opt.RepoRoot); err != nil {
+	if report := opt.dryRunReport(); report != nil {
+		// Generate into a copy of the repository, so that we can report what changed.
+		if err := opt.previewFileChanges(ctx, report, opt.RepoRoot, runGenerate); err != nil {
+			return err
+		}
+		return opt.finishDryRun(report)
+	}
+	return runGenerate(ctx, opt.RepoRoot)
+}
+
+func runGenerate(ctx context.Context, repoRoot string) error {
+	if err := generate.Run(ctx, repoRoot); err != nil {
+		return err
+	}
+	if err := generateReferenceDocs(ctx, repoRoot); err != nil {
+		return err
+	}
+	return format.Run(ctx, repoRoot)
+}
+
+// generateReferenceDocs writes the reference documentation of ap, if .ap/generate.yaml of the repository enables it.
+func generateReferenceDocs(ctx context.Context, repoRoot string) error {
+	cfg, err := generate.LoadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	if cfg.ReferenceDocs == nil || !cfg.ReferenceDocs.Enabled {
+		return nil
+	}
+	output := cfg.ReferenceDocs.Output
+	if output == "" {
+		output = refdocs.DefaultOutput
+	}
+	klog.Infof("Generating reference docs in %s", output)
+
+	// A new command tree documents the defaults of the flags, rather than the values this invocation was given.
+	pages, err := refdocs.Generate(ctx, BuildRootCommand(), repoRoot)
+	if err != nil {
 		return err
 	}
-	return format.Run(ctx, opt.RepoRoot)
+	return refdocs.Write(filepath.Join(repoRoot, output), pages)
 }
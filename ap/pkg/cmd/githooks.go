@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/githooks"
+	"github.com/spf13/cobra"
+)
+
+// GitHooksOptions holds the configuration for the "githooks" command.
+type GitHooksOptions struct {
+	*RootOptions
+}
+
+// BuildGitHooksCommand constructs the cobra command for "githooks".
+func BuildGitHooksCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := GitHooksOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "githooks",
+		Short: "Manage the git hooks that run ap before commits",
+	}
+
+	cmd.AddCommand(BuildGitHooksInstallCommand(&opt))
+	cmd.AddCommand(BuildGitHooksUninstallCommand(&opt))
+
+	return cmd
+}
+
+// GitHooksInstallOptions holds the configuration for the "githooks install" command.
+type GitHooksInstallOptions struct {
+	*GitHooksOptions
+
+	// Force replaces a pre-commit hook that was not installed by ap.
+	Force bool
+}
+
+// BuildGitHooksInstallCommand constructs the cobra command for "githooks install".
+func BuildGitHooksInstallCommand(hooksOpt *GitHooksOptions) *cobra.Command {
+	opt := GitHooksInstallOptions{
+		GitHooksOptions: hooksOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install a pre-commit hook running ap format --changed and ap lint --changed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunGitHooksInstall(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opt.Force, "force", opt.Force, "Replace an existing pre-commit hook that was not installed by ap")
+
+	return cmd
+}
+
+// RunGitHooksInstall executes the business logic for the "githooks install" command.
+func RunGitHooksInstall(ctx context.Context, opt GitHooksInstallOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+	report := opt.dryRunReport()
+	path, err := githooks.Install(ctx, opt.RepoRoot, opt.Force, report)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		fmt.Printf("Installed %s\n", path)
+	}
+	return opt.finishDryRun(report)
+}
+
+// GitHooksUninstallOptions holds the configuration for the "githooks uninstall" command.
+type GitHooksUninstallOptions struct {
+	*GitHooksOptions
+}
+
+// BuildGitHooksUninstallCommand constructs the cobra command for "githooks uninstall".
+func BuildGitHooksUninstallCommand(hooksOpt *GitHooksOptions) *cobra.Command {
+	opt := GitHooksUninstallOptions{
+		GitHooksOptions: hooksOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the pre-commit hook installed by ap githooks install",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunGitHooksUninstall(cmd.Context(), opt)
+		},
+	}
+
+	return cmd
+}
+
+// RunGitHooksUninstall executes the business logic for the "githooks uninstall" command.
+func RunGitHooksUninstall(ctx context.Context, opt GitHooksUninstallOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+	report := opt.dryRunReport()
+	path, err := githooks.Uninstall(ctx, opt.RepoRoot, report)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		if path == "" {
+			fmt.Println("No pre-commit hook is installed")
+		} else {
+			fmt.Printf("Removed %s\n", path)
+		}
+	}
+	return opt.finishDryRun(report)
+}
+
+// changedFiles returns the files staged in git, which --changed limits commands to.
+func (o *RootOptions) changedFiles(ctx context.Context) ([]string, error) {
+	return githooks.StagedFiles(ctx, o.RepoRoot)
+}
+
+// filesUnder returns the paths of the files under root, relative to root.
+func filesUnder(files []string, root string) []string {
+	var under []string
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		under = append(under, rel)
+	}
+	return under
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/e2e"
+	"github.com/spf13/cobra"
+)
+
+// JanitorOptions holds the configuration for the "janitor" command.
+type JanitorOptions struct {
+	*AlphaOptions
+
+	Project string
+	TTL     time.Duration
+	DryRun  bool
+}
+
+// InitDefaults sets the default values for the "janitor" command.
+func (o *JanitorOptions) InitDefaults() {
+	o.TTL = e2e.DefaultMaxLifetime
+	o.DryRun = true
+}
+
+// BuildJanitorCommand constructs the cobra command for "janitor".
+func BuildJanitorCommand(alphaOpt *AlphaOptions) *cobra.Command {
+	opt := JanitorOptions{
+		AlphaOptions: alphaOpt,
+	}
+	opt.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "janitor",
+		Short: "Delete e2e clusters that were leaked by ap e2e",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunJanitor(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Project, "project", opt.Project, "The GCP project to scan for e2e clusters")
+	cmd.MarkFlagRequired("project")
+	cmd.Flags().DurationVar(&opt.TTL, "ttl", opt.TTL, "Maximum age of e2e clusters without an expiry label")
+	cmd.Flags().BoolVar(&opt.DryRun, "dry-run", opt.DryRun, "If true, only report the clusters that would be deleted")
+
+	return cmd
+}
+
+// RunJanitor executes the business logic for the "janitor" command.
+func RunJanitor(ctx context.Context, opt JanitorOptions) error {
+	return e2e.Janitor(ctx, e2e.JanitorOptions{
+		Project: opt.Project,
+		TTL:     opt.TTL,
+		DryRun:  opt.DryRun,
+		Out:     os.Stdout,
+	})
+}
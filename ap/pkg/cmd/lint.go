@@ -16,34 +16,56 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/configcheck"
 	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/k8s"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/prlinter"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/shell"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/textstyle"
 	"github.com/spf13/cobra"
 )
 
 // LintOptions holds the configuration for the "lint" command.
 type LintOptions struct {
 	*RootOptions
+
+	// Output is the format of the findings: text, github, sarif or junit.
+	Output string
+	// Changed limits linting to the files staged in git.
+	Changed bool
 }
 
 // BuildLintCommand constructs the cobra command for "lint".
 func BuildLintCommand(rootOpt *RootOptions) *cobra.Command {
 	opt := LintOptions{
 		RootOptions: rootOpt,
+		Output:      string(findings.FormatText),
 	}
 
 	cmd := &cobra.Command{
-		Use:   "lint",
-		Short: "Run linting tasks (vet, govulncheck, prlinter)",
-		Args:  cobra.NoArgs,
+		Use:         "lint",
+		Short:       "Run linting tasks (vet, govulncheck, prlinter, kubelint, shell scripts, config keys, text encoding)",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{checksConfigAnnotation: "true"},
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return RunLint(cmd.Context(), opt)
 		},
 	}
 
+	cmd.Flags().StringVar(&opt.Output, "output", opt.Output, "Output format of the findings: text, github, sarif or junit")
+	cmd.Flags().BoolVar(&opt.Changed, "changed", opt.Changed, "Only lint the files staged in git")
+
+	cmd.AddCommand(BuildLintK8sCommand(rootOpt))
 	cmd.AddCommand(BuildUnusedCommand())
 	cmd.AddCommand(BuildTestContextCommand())
+	cmd.AddCommand(BuildCobraCmdCommand())
+	cmd.AddCommand(BuildConcurrencyCommand())
+	cmd.AddCommand(BuildLargeCopyCommand())
 
 	return cmd
 }
@@ -53,13 +75,112 @@ func RunLint(ctx context.Context, opt LintOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
-	if err := prlinter.Lint(ctx, opt.RepoRoot); err != nil {
+	format, err := findings.ParseFormat(opt.Output)
+	if err != nil {
 		return err
 	}
-	for _, apRoot := range opt.APRoots {
-		if err := golang.Lint(ctx, apRoot); err != nil {
+	var changed []string
+	if opt.Changed {
+		files, err := opt.changedFiles(ctx)
+		if err != nil {
 			return err
 		}
+		if len(files) == 0 {
+			fmt.Fprintln(os.Stderr, "No staged files to lint")
+			return nil
+		}
+		changed = files
+	}
+
+	prFindings, err := prlinter.Lint(ctx, opt.RepoRoot, changed)
+	if err != nil {
+		return err
+	}
+
+	all, err := lintFindings(ctx, opt.RepoRoot, opt.APRoots, changed)
+	if err != nil {
+		return err
+	}
+	all = append(prFindings, all...)
+	all, err = prlinter.EnforceOnChangedLines(ctx, opt.RepoRoot, all)
+	if err != nil {
+		return err
+	}
+
+	// Text goes to stderr like the output of the other tasks; the other formats are reports for tools to consume.
+	out := os.Stdout
+	if format == findings.FormatText {
+		out = os.Stderr
+	}
+	if err := findings.Write(out, format, "ap lint", all); err != nil {
+		return err
+	}
+	if findings.HasErrors(all) {
+		return fmt.Errorf("lint failures found")
 	}
 	return nil
 }
+
+// lintFindings runs the linters that report findings over repoRoot and its ap roots.
+// If files (absolute paths) is not empty, only the findings in those files are returned, and the
+// ap roots without any of them are not linted. The paths of the findings are relative to repoRoot.
+func lintFindings(ctx context.Context, repoRoot string, apRoots []string, files []string) ([]findings.Finding, error) {
+	var all []findings.Finding
+	for _, apRoot := range apRoots {
+		var rootFiles []string
+		if len(files) > 0 {
+			for _, rel := range filesUnder(files, apRoot) {
+				rootFiles = append(rootFiles, filepath.Join(apRoot, rel))
+			}
+			if len(rootFiles) == 0 {
+				continue
+			}
+		}
+
+		found, err := golang.LintFiles(ctx, apRoot, rootFiles)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+
+		found, err = k8s.Lint(apRoot)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+
+		found, err = shell.Lint(ctx, apRoot)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+
+		found, err = textstyle.CheckEncoding(ctx, apRoot)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+	}
+
+	found, err := configcheck.Lint(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, found...)
+	findings.Relativize(all, repoRoot)
+
+	if len(files) == 0 {
+		return all, nil
+	}
+	changed := map[string]bool{}
+	for _, rel := range filesUnder(files, repoRoot) {
+		changed[filepath.ToSlash(rel)] = true
+	}
+	var inFiles []findings.Finding
+	for _, f := range all {
+		if changed[filepath.ToSlash(f.Path)] {
+			inFiles = append(inFiles, f)
+		}
+	}
+	return inFiles, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/k8s"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/spf13/cobra"
+)
+
+// LintK8sOptions holds the configuration for the "lint k8s" command.
+type LintK8sOptions struct {
+	*RootOptions
+
+	// Output is the format of the findings: text, github, sarif or junit.
+	Output string
+	// Fix applies the fixes of the enabled rules that have one before linting.
+	Fix bool
+}
+
+// BuildLintK8sCommand constructs the cobra command for "lint k8s".
+func BuildLintK8sCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := LintK8sOptions{
+		RootOptions: rootOpt,
+		Output:      string(findings.FormatText),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "k8s",
+		Short: "Run only kubelint, over the manifests under k8s directories",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunLintK8s(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Output, "output", opt.Output, "Output format of the findings: text, github, sarif or junit")
+	cmd.Flags().BoolVar(&opt.Fix, "fix", opt.Fix, "Fix the findings that can be fixed mechanically, editing the manifests in place, before linting")
+
+	return cmd
+}
+
+// RunLintK8s executes the business logic for the "lint k8s" command.
+func RunLintK8s(_ context.Context, opt LintK8sOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+	format, err := findings.ParseFormat(opt.Output)
+	if err != nil {
+		return err
+	}
+
+	var all []findings.Finding
+	for _, apRoot := range opt.APRoots {
+		if opt.Fix {
+			changed, err := k8s.Fix(apRoot)
+			if err != nil {
+				return err
+			}
+			for _, path := range changed {
+				fmt.Fprintf(os.Stderr, "Fixed %s\n", path)
+			}
+		}
+		found, err := k8s.Lint(apRoot)
+		if err != nil {
+			return err
+		}
+		all = append(all, found...)
+	}
+	findings.Relativize(all, opt.RepoRoot)
+
+	// Text goes to stderr like the output of the other tasks; the other formats are reports for tools to consume.
+	out := os.Stdout
+	if format == findings.FormatText {
+		out = os.Stderr
+	}
+	if err := findings.Write(out, format, "kubelint", all); err != nil {
+		return err
+	}
+	if findings.HasErrors(all) {
+		return fmt.Errorf("lint failures found")
+	}
+	return nil
+}
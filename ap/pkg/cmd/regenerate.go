@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/format"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/regenerate"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/spf13/cobra"
+)
+
+// RegeneratePROptions holds the configuration for the "regenerate-pr" command.
+type RegeneratePROptions struct {
+	*AlphaOptions
+
+	// Repository is the GitHub repository to open the pull request in, as owner/name.
+	Repository  string
+	Base        string
+	Branch      string
+	Remote      string
+	GitHubToken string
+}
+
+// InitDefaults sets the default values for the "regenerate-pr" command.
+func (o *RegeneratePROptions) InitDefaults() {
+	o.Base = regenerate.DefaultBase
+	o.Branch = regenerate.DefaultBranch
+	o.Remote = regenerate.DefaultRemote
+}
+
+// BuildRegeneratePRCommand constructs the cobra command for "regenerate-pr".
+func BuildRegeneratePRCommand(alphaOpt *AlphaOptions) *cobra.Command {
+	opt := RegeneratePROptions{
+		AlphaOptions: alphaOpt,
+	}
+	opt.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "regenerate-pr",
+		Short: "Run generate and open a pull request if generated files have drifted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunRegeneratePR(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Repository, "repo", opt.Repository, "The GitHub repository to open the pull request in, as owner/name (default from GITHUB_REPOSITORY env var)")
+	cmd.Flags().StringVar(&opt.Base, "base", opt.Base, "The branch the pull request merges into")
+	cmd.Flags().StringVar(&opt.Branch, "branch", opt.Branch, "The branch to push the regenerated files to; it is force-pushed")
+	cmd.Flags().StringVar(&opt.Remote, "remote", opt.Remote, "The git remote to push to")
+	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
+
+	return cmd
+}
+
+// RunRegeneratePR executes the business logic for the "regenerate-pr" command.
+func RunRegeneratePR(ctx context.Context, opt RegeneratePROptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+	if opt.Repository == "" {
+		// Set by GitHub Actions.
+		opt.Repository = os.Getenv("GITHUB_REPOSITORY")
+	}
+	owner, repo, ok := strings.Cut(opt.Repository, "/")
+	if !ok || owner == "" || repo == "" {
+		return fmt.Errorf("--repo must be owner/name (or set GITHUB_REPOSITORY), got %q", opt.Repository)
+	}
+
+	report := opt.dryRunReport()
+	var pulls regenerate.PullRequests
+	if report == nil {
+		client, err := githubclient.New(ctx, opt.GitHubToken)
+		if err != nil {
+			return err
+		}
+		pulls = client.PullRequests
+	}
+
+	err := regenerate.Run(ctx, regenerate.Options{
+		RepoRoot: opt.RepoRoot,
+		Owner:    owner,
+		Repo:     repo,
+		Base:     opt.Base,
+		Branch:   opt.Branch,
+		Remote:   opt.Remote,
+		DryRun:   report,
+		Out:      os.Stdout,
+	}, runGenerateSteps, pulls)
+	if err != nil {
+		return err
+	}
+	return opt.finishDryRun(report)
+}
+
+// runGenerateSteps runs generate like runGenerate, calling afterStep after each generator and the formatter.
+func runGenerateSteps(ctx context.Context, repoRoot string, afterStep func(ctx context.Context, name string) error) error {
+	if err := generate.RunWithOptions(ctx, repoRoot, generate.Options{AfterStep: afterStep}); err != nil {
+		return err
+	}
+	if err := format.Run(ctx, repoRoot); err != nil {
+		return err
+	}
+	return afterStep(ctx, "format")
+}
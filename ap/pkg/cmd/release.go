@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/release"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/spf13/cobra"
+)
+
+// ReleaseOptions holds the configuration for the "release" command.
+type ReleaseOptions struct {
+	*RootOptions
+
+	Remote string
+	// Build builds and pushes the images with the release tag.
+	Build bool
+	// Bump overrides the bump computed from the commits: major, minor or patch.
+	Bump string
+
+	// GitHubRelease creates a GitHub release with the changelog as its notes.
+	GitHubRelease bool
+	// Repository is the GitHub repository to create the release in, as owner/name.
+	Repository  string
+	GitHubToken string
+}
+
+// InitDefaults sets the default values for the "release" command.
+func (o *ReleaseOptions) InitDefaults() {
+	o.Remote = release.DefaultRemote
+	o.Build = true
+}
+
+// BuildReleaseCommand constructs the cobra command for "release".
+func BuildReleaseCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := ReleaseOptions{
+		RootOptions: rootOpt,
+	}
+	opt.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Tag the next semver release from the conventional commits, and build and push its images",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunRelease(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Remote, "remote", opt.Remote, "The git remote to push the tag to")
+	cmd.Flags().BoolVar(&opt.Build, "build", opt.Build, "Build and push the images with the release tag; disable if pushing the tag triggers a release workflow that does")
+	cmd.Flags().StringVar(&opt.Bump, "bump", opt.Bump, "Override the version bump computed from the commits: major, minor or patch")
+	cmd.Flags().BoolVar(&opt.GitHubRelease, "github-release", opt.GitHubRelease, "Create a GitHub release with the changelog as its notes")
+	cmd.Flags().StringVar(&opt.Repository, "repo", opt.Repository, "The GitHub repository to create the release in, as owner/name (default from GITHUB_REPOSITORY env var)")
+	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
+
+	return cmd
+}
+
+// RunRelease executes the business logic for the "release" command.
+func RunRelease(ctx context.Context, opt ReleaseOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	releaseOpt := release.Options{
+		RepoRoot: opt.RepoRoot,
+		Remote:   opt.Remote,
+		DryRun:   opt.dryRunReport(),
+		Out:      os.Stdout,
+	}
+	if opt.Bump != "" {
+		bump, err := release.ParseBump(opt.Bump)
+		if err != nil {
+			return err
+		}
+		releaseOpt.Bump = &bump
+	}
+
+	var releases release.Releases
+	if opt.GitHubRelease {
+		if opt.Repository == "" {
+			// Set by GitHub Actions; read when running rather than as the flag default, so that help and docs are the same everywhere.
+			opt.Repository = os.Getenv("GITHUB_REPOSITORY")
+		}
+		owner, repo, ok := strings.Cut(opt.Repository, "/")
+		if !ok || owner == "" || repo == "" {
+			return fmt.Errorf("--repo must be owner/name (or set GITHUB_REPOSITORY), got %q", opt.Repository)
+		}
+		releaseOpt.Owner, releaseOpt.Repo = owner, repo
+		if releaseOpt.DryRun == nil {
+			client, err := githubclient.New(ctx, opt.GitHubToken)
+			if err != nil {
+				return err
+			}
+			releases = client.Repositories
+		}
+	}
+
+	var build release.Builder
+	if opt.Build {
+		build = func(ctx context.Context, tag string) error {
+			// The images are pushed with the tag of the release (and their digests recorded, as by ap build --push).
+			if err := os.Setenv("IMAGE_TAG", tag); err != nil {
+				return err
+			}
+			return RunBuild(ctx, BuildOptions{RootOptions: opt.RootOptions, Push: true})
+		}
+	}
+	if err := release.Run(ctx, releaseOpt, build, releases); err != nil {
+		return err
+	}
+	return opt.finishDryRun(releaseOpt.DryRun)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// RollbackOptions holds the configuration for the "rollback" command.
+type RollbackOptions struct {
+	*RootOptions
+
+	// Profile selects a profile from .ap/deploy.yaml (e.g. staging).
+	Profile string
+
+	// Target overrides the kubeconfig, context and namespace from .ap/deploy.yaml.
+	Target k8s.Target
+
+	// Wait waits for the re-applied Deployments, StatefulSets and DaemonSets to become ready.
+	Wait bool
+}
+
+// BuildRollbackCommand constructs the cobra command for "rollback".
+func BuildRollbackCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := RollbackOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Undo the last deploy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunRollback(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Profile, "profile", opt.Profile, "The profile from .ap/deploy.yaml to roll back (e.g. staging)")
+	cmd.Flags().StringVar(&opt.Target.Kubeconfig, "kubeconfig", opt.Target.Kubeconfig, "Path to the kubeconfig file to use")
+	cmd.Flags().StringVar(&opt.Target.Context, "context", opt.Target.Context, "The kubeconfig context to roll back")
+	cmd.Flags().StringVarP(&opt.Target.Namespace, "namespace", "n", opt.Target.Namespace, "The namespace that was deployed to")
+	cmd.Flags().BoolVar(&opt.Wait, "wait", opt.Wait, "Wait for Deployments, StatefulSets and DaemonSets to become ready")
+
+	return cmd
+}
+
+// RunRollback executes the business logic for the "rollback" command.
+func RunRollback(ctx context.Context, opt RollbackOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	report := opt.dryRunReport()
+	err := opt.forEachAPRoot(func(apRoot string) error {
+		if err := k8s.Rollback(ctx, apRoot, k8s.RollbackOptions{Profile: opt.Profile, Target: opt.Target, WaitForRollouts: opt.Wait, DryRun: report}); err != nil {
+			return fmt.Errorf("rollback failed for %s: %w", apRoot, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return opt.finishDryRun(report)
+}
@@ -20,8 +20,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/execprofile"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/repo"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 )
@@ -30,18 +35,48 @@ import (
 type RootOptions struct {
 	RepoRoot string
 	APRoot   string
-	APRoots  []string
+	// APRoots are the ap roots commands run in: all of them, or those selected with --root.
+	APRoots []string
+
+	// Roots selects the ap roots to run in, by path; all of them are used if it is empty.
+	Roots []string
+	// AllRoots runs in all the ap roots, which is the default; it cannot be combined with Roots.
+	AllRoots bool
+
+	// DryRun previews the changes that mutating commands would make, without making them.
+	DryRun bool
+	// FailOnChanges makes a dry run fail if it finds changes that would be made.
+	FailOnChanges bool
+
+	// IgnoreMissingTools skips the tasks whose external tools (e.g. docker or kubectl) are not installed, instead of failing.
+	IgnoreMissingTools bool
+
+	// ExecutionProfile names the execution profile (e.g. local, ci or agent); it is detected from the environment if empty.
+	ExecutionProfile string
+	// Profile is the resolved execution profile, whose settings are the defaults of the flags that were not given.
+	Profile execprofile.Profile
 }
 
 // BuildRootCommand constructs the root cobra command.
 func BuildRootCommand() *cobra.Command {
-	var opt RootOptions
+	opt := RootOptions{
+		FailOnChanges: true,
+	}
 
+	var start time.Time
 	cmd := &cobra.Command{
 		Use:   "ap",
 		Short: "ap is a tool for managing gke-labs projects",
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			cmd.SilenceUsage = true
+			start = time.Now()
+			if opt.DryRun {
+				fmt.Fprintln(os.Stderr, dryrun.Banner)
+			}
+			cmd.SetContext(tools.NewContext(cmd.Context(), opt.IgnoreMissingTools))
+			if err := checkTools(cmd, opt.IgnoreMissingTools); err != nil {
+				return err
+			}
 			repoRoot, apRoot, err := findRoots()
 			if err == nil {
 				opt.RepoRoot = repoRoot
@@ -54,26 +89,51 @@ func BuildRootCommand() *cobra.Command {
 					}
 					opt.APRoots = apRoots
 				}
+				checkConfigFiles(&opt, cmd)
 			}
-			return nil
+			if err := applyExecutionProfile(&opt, cmd); err != nil {
+				return err
+			}
+			return selectRoots(&opt)
+		},
+		// Only commands that succeed are checked against their budgets; failures end early.
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			reportSkippedTools(cmd)
+			return trackBudget(&opt, cmd, start)
 		},
 	}
 
 	fs := cmd.PersistentFlags()
+	fs.BoolVar(&opt.DryRun, "dry-run", opt.DryRun, "Show the changes that would be made, without making them")
+	fs.BoolVar(&opt.FailOnChanges, "fail-on-changes", opt.FailOnChanges, "With --dry-run, exit non-zero if changes would be made")
+	fs.StringArrayVar(&opt.Roots, "root", opt.Roots, "Only run in the ap root at this path (relative to the current directory or the repository root); may be repeated")
+	fs.BoolVar(&opt.AllRoots, "all-roots", opt.AllRoots, "Run in all the ap roots of the repository (the default)")
+	fs.BoolVar(&opt.IgnoreMissingTools, "ignore-missing-tools", opt.IgnoreMissingTools, "Skip the tasks whose external tools (e.g. docker or kubectl) are not installed, instead of failing")
+	fs.StringVar(&opt.ExecutionProfile, "execution-profile", opt.ExecutionProfile, "The execution profile, whose settings in .ap/ap.yaml are the defaults of the flags: local, ci, agent or a configured one (default: detected from the environment)")
 	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
 	klog.InitFlags(klogFlags)
 	fs.AddGoFlagSet(klogFlags)
 
 	cmd.AddCommand(BuildTestCommand(&opt))
 	cmd.AddCommand(BuildE2eCommand(&opt))
+	cmd.AddCommand(BuildWarmCommand(&opt))
 	cmd.AddCommand(BuildLintCommand(&opt))
 	cmd.AddCommand(BuildBuildCommand(&opt))
+	cmd.AddCommand(BuildCacheCommand(&opt))
 	cmd.AddCommand(BuildDeployCommand(&opt))
+	cmd.AddCommand(BuildUndeployCommand(&opt))
+	cmd.AddCommand(BuildRollbackCommand(&opt))
+	cmd.AddCommand(BuildReleaseCommand(&opt))
 	cmd.AddCommand(BuildGenerateCommand(&opt))
 	cmd.AddCommand(BuildFormatCommand(&opt))
+	cmd.AddCommand(BuildFixLoopCommand(&opt))
+	cmd.AddCommand(BuildGitHooksCommand(&opt))
+	cmd.AddCommand(BuildCICommand(&opt))
+	cmd.AddCommand(BuildConfigCommand(&opt))
 	cmd.AddCommand(BuildVersionBumpCommand(&opt))
 	cmd.AddCommand(BuildAlphaCommand(&opt))
 	cmd.AddCommand(BuildServeCommand(&opt))
+	cmd.AddCommand(BuildUICommand(&opt))
 	cmd.AddCommand(BuildVersionCommand(&opt))
 
 	return cmd
@@ -81,50 +141,83 @@ func BuildRootCommand() *cobra.Command {
 
 // findRoots attempts to find the root of the git repository and the closest ap root
 func findRoots() (string, string, error) {
-	repoRoot := os.Getenv("REPO_ROOT")
 	apRoot := os.Getenv("AP_ROOT")
 
-	if repoRoot != "" && apRoot != "" {
-		return repoRoot, apRoot, nil
-	}
-
 	startDir, err := os.Getwd()
 	if err != nil {
 		return "", "", err
 	}
 
-	dir := startDir
-	for {
-		if apRoot == "" {
-			if _, err := os.Stat(filepath.Join(dir, ".ap")); err == nil {
-				apRoot = dir
-			}
+	repoRoot, err := repo.FindRoot(startDir)
+	if err != nil {
+		// Outside of a git repository, AP_ROOT stands in for the repository root.
+		if apRoot != "" {
+			return apRoot, apRoot, nil
 		}
-		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
-			if repoRoot == "" {
-				repoRoot = dir
-			}
-			if apRoot == "" {
-				apRoot = dir
-			}
-			return repoRoot, apRoot, nil
+		return "", "", err
+	}
+
+	if apRoot == "" {
+		apRoot = findAPRoot(startDir, repoRoot)
+	}
+	return repoRoot, apRoot, nil
+}
+
+// findAPRoot returns the closest ancestor of startDir (up to repoRoot) containing a .ap directory,
+// or repoRoot if there is none.
+func findAPRoot(startDir string, repoRoot string) string {
+	for dir := startDir; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".ap")); err == nil {
+			return dir
 		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
+		if dir == repoRoot || filepath.Dir(dir) == dir {
+			return repoRoot
 		}
-		dir = parent
 	}
+}
 
-	if repoRoot == "" {
-		repoRoot = os.Getenv("AP_ROOT")
+// selectRoots limits opt.APRoots to the ap roots selected with --root.
+func selectRoots(opt *RootOptions) error {
+	if len(opt.Roots) == 0 {
+		return nil
 	}
-
-	if repoRoot != "" {
-		return repoRoot, apRoot, nil
+	if opt.AllRoots {
+		return fmt.Errorf("--root and --all-roots cannot be used together")
+	}
+	if opt.RepoRoot == "" {
+		return fmt.Errorf("--root must be used inside a git repository (or set REPO_ROOT or AP_ROOT)")
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	roots, err := config.SelectAPRoots(opt.RepoRoot, workDir, opt.APRoots, opt.Roots)
+	if err != nil {
+		return err
 	}
+	opt.APRoots = roots
+	if len(roots) == 1 {
+		opt.APRoot = roots[0]
+	}
+	return nil
+}
 
-	return "", "", fmt.Errorf("could not find git repository root (starting at %s)", startDir)
+// forEachAPRoot calls fn with each of the ap roots the command runs in, stopping at the first error.
+// When there are several, each is announced on stderr and errors name the root they came from.
+func (o *RootOptions) forEachAPRoot(fn func(apRoot string) error) error {
+	for _, apRoot := range o.APRoots {
+		name := config.RootName(o.RepoRoot, apRoot)
+		if len(o.APRoots) > 1 {
+			fmt.Fprintf(os.Stderr, "==> ap root %s\n", name)
+		}
+		if err := fn(apRoot); err != nil {
+			if len(o.APRoots) > 1 {
+				return fmt.Errorf("[%s] %w", name, err)
+			}
+			return err
+		}
+	}
+	return nil
 }
 
 func requireRepoRoot(opt *RootOptions) error {
@@ -28,12 +28,19 @@ type ServeOptions struct {
 	*RootOptions
 	ServeRoot string
 	Port      int
+
+	// AuditLog is the file recording the commands run by the server; empty disables the audit log.
+	AuditLog string
+
+	// Commands are the ap commands the server reports it can run.
+	Commands []string
 }
 
 // BuildServeCommand constructs the cobra command for "serve".
 func BuildServeCommand(rootOpt *RootOptions) *cobra.Command {
 	opt := ServeOptions{
 		RootOptions: rootOpt,
+		AuditLog:    sandbox.DefaultAuditLogPath,
 	}
 
 	cmd := &cobra.Command{
@@ -51,16 +58,27 @@ func BuildServeCommand(rootOpt *RootOptions) *cobra.Command {
 				}
 				opt.ServeRoot = cwd
 			}
+			for _, c := range cmd.Root().Commands() {
+				if c.IsAvailableCommand() {
+					opt.Commands = append(opt.Commands, c.Name())
+				}
+			}
 			return RunServe(cmd.Context(), opt)
 		}}
 
 	cmd.Flags().StringVar(&opt.ServeRoot, "root", "", "Root directory for the sandbox server (defaults to repo root)")
 	cmd.Flags().IntVar(&opt.Port, "port", 50051, "Port to listen on")
+	cmd.Flags().StringVar(&opt.AuditLog, "audit-log", opt.AuditLog, "File recording every command run by the server (empty to disable)")
 
 	return cmd
 }
 
 // RunServe executes the business logic for the "serve" command.
 func RunServe(ctx context.Context, opt ServeOptions) error {
-	return sandbox.Serve(ctx, opt.ServeRoot, opt.Port)
+	return sandbox.Serve(ctx, sandbox.ServeOptions{
+		Root:         opt.ServeRoot,
+		Port:         opt.Port,
+		AuditLogPath: opt.AuditLog,
+		Commands:     opt.Commands,
+	})
 }
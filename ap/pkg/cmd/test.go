@@ -16,16 +16,28 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/languages"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/mutate"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 // TestOptions holds the configuration for the "test" command.
 type TestOptions struct {
 	*RootOptions
+
+	// CheckWrites fails the run if the tests add, modify or delete files in the repository,
+	// outside of .build directories.
+	CheckWrites bool
+
+	// Mutate lists go package patterns to run mutation testing on, instead of running the tests.
+	Mutate []string
 }
 
 // BuildTestCommand constructs the cobra command for "test".
@@ -43,6 +55,9 @@ func BuildTestCommand(rootOpt *RootOptions) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&opt.CheckWrites, "check-writes", opt.CheckWrites, "Fail if the tests change files in the working tree (they should write to t.TempDir())")
+	cmd.Flags().StringSliceVar(&opt.Mutate, "mutate", opt.Mutate, "Run mutation testing on these go packages (e.g. ./pkg/cache/...) instead of the tests, and report the surviving mutants")
+
 	return cmd
 }
 
@@ -52,19 +67,75 @@ func RunTest(ctx context.Context, opt TestOptions) error {
 		return err
 	}
 
-	for _, apRoot := range opt.APRoots {
-		if err := golang.Test(ctx, apRoot); err != nil {
-			return err
-		}
+	if len(opt.Mutate) > 0 {
+		return runMutate(ctx, opt)
+	}
 
-		// Run test-* scripts (excluding test-e2e*)
-		testTasks, err := tasks.FindTaskScripts(apRoot, tasks.WithPrefix("test-"), tasks.WithExcludePrefix("test-e2e"))
-		if err != nil {
-			return fmt.Errorf("failed to discover test tasks in %s: %w", apRoot, err)
-		}
-		if err := tasks.Run(ctx, apRoot, testTasks); err != nil {
-			return err
+	return opt.forEachAPRoot(func(apRoot string) error {
+		run := func() error { return testAPRoot(ctx, apRoot) }
+		if opt.CheckWrites {
+			return checkWrites(opt.RepoRoot, apRoot, run)
 		}
+		return run()
+	})
+}
+
+// runMutate runs mutation testing on the packages matching opt.Mutate, resolved in the current directory.
+func runMutate(ctx context.Context, opt TestOptions) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	pkgs, err := mutate.Run(ctx, mutate.Options{Dir: dir, Patterns: opt.Mutate})
+	mutate.WriteReport(os.Stdout, dir, pkgs)
+	return err
+}
+
+// testAPRoot runs the go tests, the tests of the other languages enabled in .ap/languages.yaml
+// and the test-* task scripts of the ap root at apRoot.
+func testAPRoot(ctx context.Context, apRoot string) error {
+	if err := golang.Test(ctx, apRoot); err != nil {
+		return err
+	}
+
+	languageTasks, err := languages.TestTasks(apRoot)
+	if err != nil {
+		return err
+	}
+	if err := tasks.Run(ctx, apRoot, languageTasks); err != nil {
+		return err
+	}
+
+	// Run test-* scripts (excluding test-e2e*)
+	testTasks, err := tasks.FindTaskScripts(apRoot, tasks.WithPrefix("test-"), tasks.WithExcludePrefix("test-e2e"))
+	if err != nil {
+		return fmt.Errorf("failed to discover test tasks in %s: %w", apRoot, err)
+	}
+	return tasks.Run(ctx, apRoot, testTasks)
+}
+
+// checkWrites runs the tests of apRoot with run, and fails if they changed any file under repoRoot.
+// Such tests corrupt later (cached) runs and make the generated files check fail, so they are reported
+// even if the tests themselves failed.
+func checkWrites(repoRoot string, apRoot string, run func() error) error {
+	before, err := worktree.Take(repoRoot)
+	if err != nil {
+		return err
+	}
+	testErr := run()
+	after, err := worktree.Take(repoRoot)
+	if err != nil {
+		return errors.Join(testErr, err)
+	}
+
+	changes := worktree.Changes(before, after)
+	if len(changes) == 0 {
+		return testErr
+	}
+	fmt.Fprintf(os.Stderr, "Tests in %s changed files in the working tree:\n", apRoot)
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", change.Kind, change.Path)
 	}
-	return nil
+	fmt.Fprintln(os.Stderr, "<hint_for_agent>Tests should write to t.TempDir() (or a copy of their testdata), not to the source tree; restore the files with git checkout.</hint_for_agent>")
+	return errors.Join(testErr, fmt.Errorf("tests in %s changed %d files in the working tree", apRoot, len(changes)))
 }
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+// requiredTools are the external tools needed by each command, by command path, for the report before it runs.
+// Tools that depend on the configuration (e.g. of container tasks or the e2e cluster) are only checked when used.
+var requiredTools = map[string][]tools.Requirement{
+	"ap build": {
+		{Tool: "docker", Task: "build images from a Dockerfile"},
+	},
+	"ap deploy": {
+		{Tool: "docker", Task: "build images from a Dockerfile"},
+	},
+	"ap lint": {
+		{Tool: "git", Task: "lint the changes of the pull request"},
+		{Tool: "shellcheck", Task: "lint shell scripts with shellcheck", Optional: true},
+	},
+	"ap release": {
+		{Tool: "git", Task: "tag the release", Essential: true},
+	},
+	"ap githooks install": {
+		{Tool: "git", Task: "install git hooks", Essential: true},
+	},
+	"ap githooks uninstall": {
+		{Tool: "git", Task: "uninstall git hooks", Essential: true},
+	},
+	"ap alpha janitor": {
+		{Tool: "gcloud", Task: "delete stale e2e clusters", Essential: true},
+	},
+	"ap alpha regenerate-pr": {
+		{Tool: "git", Task: "commit regenerated files", Essential: true},
+	},
+}
+
+// checkTools reports the missing tools of cmd on stderr, with the tasks that will be skipped or fail because of them.
+// Commands fail upfront if an essential tool is missing, rather than part of the way through.
+func checkTools(cmd *cobra.Command, ignoreMissing bool) error {
+	missing := tools.Missing(requiredTools[cmd.CommandPath()])
+	if len(missing) == 0 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Some tools used by %s are not installed:\n", cmd.CommandPath())
+	if err := tools.WriteReport(os.Stderr, missing, ignoreMissing); err != nil {
+		return err
+	}
+	var essential []string
+	for _, req := range missing {
+		if req.Essential {
+			essential = append(essential, req.Tool)
+		}
+	}
+	if len(essential) > 0 {
+		return fmt.Errorf("%s requires %s, which is not installed", cmd.CommandPath(), strings.Join(essential, ", "))
+	}
+	return nil
+}
+
+// reportSkippedTools warns on stderr about the tasks that were skipped because their tools are missing.
+func reportSkippedTools(cmd *cobra.Command) {
+	if summary := tools.Summary(tools.Skipped(cmd.Context())); summary != "" {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", summary)
+	}
+}
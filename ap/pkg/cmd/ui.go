@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// UIOptions holds the configuration for the "ui" command.
+type UIOptions struct {
+	*RootOptions
+}
+
+// BuildUICommand constructs the cobra command for "ui".
+func BuildUICommand(rootOpt *RootOptions) *cobra.Command {
+	opt := UIOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Browse the results of the last ap test run and re-run failures",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunUI(cmd.Context(), opt)
+		},
+	}
+
+	return cmd
+}
+
+// RunUI executes the business logic for the "ui" command.
+func RunUI(ctx context.Context, opt UIOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	var results []*golang.TestResult
+	for _, apRoot := range opt.APRoots {
+		rootResults, err := golang.LoadResults(apRoot)
+		if err != nil {
+			return err
+		}
+		results = append(results, rootResults...)
+	}
+
+	return ui.NewBrowser(results, os.Stdin, os.Stdout, golang.Rerun).Run(ctx)
+}
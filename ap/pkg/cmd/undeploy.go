@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// UndeployOptions holds the configuration for the "undeploy" command.
+type UndeployOptions struct {
+	*RootOptions
+
+	// Profile selects a profile from .ap/deploy.yaml (e.g. staging).
+	Profile string
+
+	// Target overrides the kubeconfig, context and namespace from .ap/deploy.yaml.
+	Target k8s.Target
+}
+
+// BuildUndeployCommand constructs the cobra command for "undeploy".
+func BuildUndeployCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := UndeployOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "undeploy",
+		Short: "Delete the resources applied by deploy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunUndeploy(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Profile, "profile", opt.Profile, "The profile from .ap/deploy.yaml to undeploy (e.g. staging)")
+	cmd.Flags().StringVar(&opt.Target.Kubeconfig, "kubeconfig", opt.Target.Kubeconfig, "Path to the kubeconfig file to use")
+	cmd.Flags().StringVar(&opt.Target.Context, "context", opt.Target.Context, "The kubeconfig context to delete from")
+	cmd.Flags().StringVarP(&opt.Target.Namespace, "namespace", "n", opt.Target.Namespace, "The namespace that was deployed to")
+
+	return cmd
+}
+
+// RunUndeploy executes the business logic for the "undeploy" command.
+func RunUndeploy(ctx context.Context, opt UndeployOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	report := opt.dryRunReport()
+	err := opt.forEachAPRoot(func(apRoot string) error {
+		if err := k8s.Undeploy(ctx, apRoot, k8s.UndeployOptions{Profile: opt.Profile, Target: opt.Target, DryRun: report}); err != nil {
+			return fmt.Errorf("undeploy failed for %s: %w", apRoot, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return opt.finishDryRun(report)
+}
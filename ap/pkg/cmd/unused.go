@@ -30,12 +30,13 @@ func BuildUnusedCommand() *cobra.Command {
 		Short:              "Run the unused analyzer",
 		Hidden:             true,
 		DisableFlagParsing: true,
-		Run: func(_ *cobra.Command, args []string) {
+		RunE: func(_ *cobra.Command, args []string) error {
 			// multichecker.Main expects the first argument to be the program name,
 			// and subsequent arguments to be flags and packages.
 			// Since this is a subcommand, we need to shift the arguments.
 			os.Args = append([]string{os.Args[0]}, args...)
 			multichecker.Main(unused.Analyzer)
+			return nil
 		},
 	}
 
@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"context"
+	"os"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/version"
 	"github.com/spf13/cobra"
@@ -24,6 +25,9 @@ import (
 // VersionOptions holds the configuration for the "version" command.
 type VersionOptions struct {
 	*RootOptions
+
+	// JSON prints the build metadata as JSON.
+	JSON bool
 }
 
 // BuildVersionCommand constructs the cobra command for "version".
@@ -41,10 +45,12 @@ func BuildVersionCommand(rootOpt *RootOptions) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&opt.JSON, "json", opt.JSON, "Print the build metadata as JSON")
+
 	return cmd
 }
 
 // RunVersion executes the business logic for the "version" command.
-func RunVersion(ctx context.Context, opt VersionOptions) error {
-	return version.Run(ctx, opt.RepoRoot)
+func RunVersion(_ context.Context, opt VersionOptions) error {
+	return version.Print(os.Stdout, version.Get(), opt.JSON)
 }
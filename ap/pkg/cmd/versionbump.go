@@ -15,15 +15,30 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
+	"os"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/versionbump"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/google/go-github/v81/github"
 	"github.com/spf13/cobra"
 )
 
 // VersionBumpOptions holds the configuration for the "versionbump" command.
 type VersionBumpOptions struct {
 	*RootOptions
+
+	// Actions bumps the actions used by the GitHub Actions workflows to their latest release.
+	Actions bool
+	// PinActions pins the actions to the commits of their releases.
+	PinActions  bool
+	GitHubToken string
+}
+
+// InitDefaults sets the default values for the "versionbump" command.
+func (o *VersionBumpOptions) InitDefaults() {
+	o.Actions = true
 }
 
 // BuildVersionBumpCommand constructs the cobra command for "versionbump".
@@ -31,16 +46,21 @@ func BuildVersionBumpCommand(rootOpt *RootOptions) *cobra.Command {
 	opt := VersionBumpOptions{
 		RootOptions: rootOpt,
 	}
+	opt.InitDefaults()
 
 	cmd := &cobra.Command{
 		Use:   "versionbump",
-		Short: "Bump project versions (e.g. Go)",
+		Short: "Bump project versions (e.g. Go and GitHub Actions)",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return RunVersionBump(cmd.Context(), opt)
 		},
 	}
 
+	cmd.Flags().BoolVar(&opt.Actions, "actions", opt.Actions, "Bump the actions used by the GitHub Actions workflows to their latest release")
+	cmd.Flags().BoolVar(&opt.PinActions, "pin-actions", opt.PinActions, "Pin the actions to the commits of their releases, with the tag in a comment")
+	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token used to look up action releases (default from GITHUB_TOKEN env var; unauthenticated if unset)")
+
 	return cmd
 }
 
@@ -49,10 +69,40 @@ func RunVersionBump(ctx context.Context, opt VersionBumpOptions) error {
 	if err := requireRepoRoot(opt.RootOptions); err != nil {
 		return err
 	}
-	for _, apRoot := range opt.APRoots {
-		if err := versionbump.Run(ctx, apRoot); err != nil {
+	report := opt.dryRunReport()
+	err := opt.forEachAPRoot(func(apRoot string) error {
+		if report != nil {
+			return opt.previewFileChanges(ctx, report, apRoot, versionbump.Run)
+		}
+		return versionbump.Run(ctx, apRoot)
+	})
+	if err != nil {
+		return err
+	}
+
+	if opt.Actions {
+		actionOpt := versionbump.ActionOptions{Pin: opt.PinActions}
+		if token := cmp.Or(opt.GitHubToken, os.Getenv("GITHUB_TOKEN")); token != "" {
+			client, err := githubclient.New(ctx, token)
+			if err != nil {
+				return err
+			}
+			actionOpt.Repositories = client.Repositories
+		} else {
+			// Release lookups are allowed without a token, at a lower rate limit.
+			actionOpt.Repositories = github.NewClient(nil).Repositories
+		}
+		bumpActions := func(ctx context.Context, repoRoot string) error {
+			return versionbump.BumpActions(ctx, repoRoot, actionOpt)
+		}
+		if report != nil {
+			err = opt.previewFileChanges(ctx, report, opt.RepoRoot, bumpActions)
+		} else {
+			err = bumpActions(ctx, opt.RepoRoot)
+		}
+		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return opt.finishDryRun(report)
 }
@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+	"github.com/spf13/cobra"
+)
+
+// WarmOptions holds the configuration for the "warm" command.
+type WarmOptions struct {
+	*RootOptions
+
+	// Parallelism is the number of modules built concurrently.
+	Parallelism int
+}
+
+// BuildWarmCommand constructs the cobra command for "warm".
+func BuildWarmCommand(rootOpt *RootOptions) *cobra.Command {
+	opt := WarmOptions{
+		RootOptions: rootOpt,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Pre-build packages and test binaries to warm the Go build cache",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return RunWarm(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().IntVar(&opt.Parallelism, "parallelism", opt.Parallelism, "Number of modules to build concurrently (default: the parallelism of the execution profile, or the number of CPUs)")
+
+	return cmd
+}
+
+// RunWarm executes the business logic for the "warm" command.
+func RunWarm(ctx context.Context, opt WarmOptions) error {
+	if err := requireRepoRoot(opt.RootOptions); err != nil {
+		return err
+	}
+
+	if opt.Parallelism == 0 {
+		opt.Parallelism = opt.Profile.Parallelism
+	}
+	return opt.forEachAPRoot(func(apRoot string) error {
+		return golang.Warm(ctx, apRoot, golang.WarmOptions{Parallelism: opt.Parallelism})
+	})
+}
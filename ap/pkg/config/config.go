@@ -15,15 +15,23 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/dupcode"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/largecopy"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/largefiles"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/repo"
 	"sigs.k8s.io/yaml"
 )
 
 type Config struct {
 	Gofmt       *GofmtConfig       `json:"gofmt"`
+	Goimports   *GoimportsConfig   `json:"goimports"`
 	Govet       *GovetConfig       `json:"govet"`
 	Govulncheck *GovulncheckConfig `json:"govulncheck"`
 	Skip        []string           `json:"skip"`
@@ -34,6 +42,15 @@ type GofmtConfig struct {
 	Enabled *bool `json:"enabled"`
 }
 
+// GoimportsConfig configures the goimports pass of ap format, which adds missing imports, removes unused ones
+// and groups them into standard library, external and module-local imports.
+type GoimportsConfig struct {
+	Enabled *bool `json:"enabled"`
+	// LocalPrefix is the comma-separated import path prefixes of the module-local group;
+	// it defaults to the module path of the go.mod of each file.
+	LocalPrefix string `json:"localPrefix"`
+}
+
 type GovetConfig struct {
 	Enabled *bool `json:"enabled"`
 }
@@ -46,10 +63,25 @@ type LintConfig struct {
 	Unused           *UnusedConfig           `json:"unused"`
 	TestContext      *TestContextConfig      `json:"testcontext"`
 	UnusedParameters *UnusedParametersConfig `json:"unusedparameters"`
+	DupCode          *DupCodeConfig          `json:"dupcode"`
+	CobraCmd         *CobraCmdConfig         `json:"cobracmd"`
+	MajorVersions    *MajorVersionsConfig    `json:"majorversions"`
+	LargeFiles       *LargeFilesConfig       `json:"largefiles"`
+	Concurrency      *ConcurrencyConfig      `json:"concurrency"`
+	LargeCopy        *LargeCopyConfig        `json:"largecopy"`
+	// ChangedLinesOnly lists the rules (e.g. "mutexcopy") whose errors only fail ap lint on the lines a pull request
+	// adds or modifies; elsewhere they are reported as warnings. It is read from the go.yaml of the repository root.
+	ChangedLinesOnly []string `json:"changedLinesOnly"`
 }
 
 type UnusedConfig struct {
 	Enabled *bool `json:"enabled"`
+	// Exported is the mode of the check for exported functions and types that nothing in their module uses:
+	// "ignore" (default), "warn" or "error". Unlike the rest of unused, it loads every package of the module at once.
+	Exported string `json:"exported"`
+	// SuppressComment is a comment that suppresses the findings on its line or of the declaration it documents,
+	// in addition to //nolint:unused, e.g. "//keep:unused".
+	SuppressComment string `json:"suppressComment"`
 }
 
 type TestContextConfig struct {
@@ -60,25 +92,230 @@ type UnusedParametersConfig struct {
 	Mode string `json:"mode"`
 }
 
-// Load loads the configuration from .ap/go.yaml in the repository root.
-func Load(repoRoot string) (*Config, error) {
-	configFile := filepath.Join(repoRoot, ".ap/go.yaml")
+// CobraCmdConfig configures the check of cobra command conventions; mode is "ignore", "warn" (default) or "error".
+type CobraCmdConfig struct {
+	Mode string `json:"mode"`
+}
+
+// ConcurrencyConfig configures the concurrency bug heuristics; the mode of each is "ignore", "warn" (default) or "error".
+type ConcurrencyConfig struct {
+	// MutexCopy reports values holding a sync.Mutex (or another sync type) that are copied.
+	MutexCopy string `json:"mutexcopy"`
+	// WaitGroupAdd reports sync.WaitGroup.Add called inside the goroutine it counts.
+	WaitGroupAdd string `json:"waitgroupadd"`
+	// HandlerMapWrite reports HTTP and webhook handlers that write to a shared map without holding a lock.
+	HandlerMapWrite string `json:"handlermapwrite"`
+	// TimeTick reports time.Tick outside the main function.
+	TimeTick string `json:"timetick"`
+}
+
+// LargeCopyConfig configures the check for large structs passed by value and methods with large value receivers;
+// mode is "ignore" (default), "warn" or "error".
+type LargeCopyConfig struct {
+	Mode string `json:"mode"`
+	// MaxSize is the size in bytes over which a struct passed by value is reported (defaults to 128).
+	MaxSize int64 `json:"maxSize"`
+}
+
+// MajorVersionsConfig configures the check that libraries are required at a single major version across the modules
+// of an ap root; mode is "ignore", "warn" or "error" (default).
+type MajorVersionsConfig struct {
+	Mode string `json:"mode"`
+	// Libraries are the module paths, without their major version suffix, of the libraries checked;
+	// they default to DefaultSingleMajorLibraries.
+	Libraries []string `json:"libraries"`
+}
+
+// DefaultSingleMajorLibraries are the libraries that must be required at a single major version, if not configured.
+// Mixing major versions of these libraries makes their types incompatible across modules.
+var DefaultSingleMajorLibraries = []string{
+	"github.com/google/go-github",
+	"gopkg.in/yaml",
+	"k8s.io/klog",
+}
+
+// LargeFilesConfig configures the check of the files added by a pull request for binaries and large files;
+// mode is "ignore", "warn" or "error" (default). It is read from the go.yaml of the repository root.
+type LargeFilesConfig struct {
+	Mode string `json:"mode"`
+	// MaxSize is the size over which files are flagged, e.g. "512KB" (defaults to 1MB).
+	MaxSize string `json:"maxSize"`
+	// Allow lists gitignore-style patterns of the files that are never flagged, e.g. "**/testdata/**/*.png".
+	Allow []string `json:"allow"`
+}
+
+// DupCodeConfig configures the advisory duplicate code check.
+type DupCodeConfig struct {
+	Enabled           *bool `json:"enabled"`
+	MinTokens         int   `json:"minTokens"`
+	IgnoreIdentifiers bool  `json:"ignoreIdentifiers"`
+}
 
+// Load loads the configuration of the ap root at root.
+// The .ap/go.yaml of root is layered over those of the enclosing ap roots in the same repository:
+// mappings are merged key by key, and any other value (including a list) replaces the inherited one.
+func Load(root string) (*Config, error) {
+	merged, layers, err := LoadMerged(root)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
 	var config Config
-	if _, err := os.Stat(configFile); err == nil {
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error merging %s: %w", strings.Join(layers, ", "), err)
+	}
+	return &config, nil
+}
+
+// LoadMerged returns the settings configured for the ap root at root, merged as Load does,
+// without the defaults of the settings that are not configured, and the files they came from.
+func LoadMerged(root string) (map[string]any, []string, error) {
+	layers, err := Layers(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := map[string]any{}
+	for _, configFile := range layers {
 		data, err := os.ReadFile(configFile)
 		if err != nil {
-			return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+			return nil, nil, fmt.Errorf("error reading %s: %w", configFile, err)
+		}
+		// Parse each layer on its own first, so that errors name the file they are in.
+		if err := yaml.Unmarshal(data, &Config{}); err != nil {
+			return nil, nil, fmt.Errorf("error parsing %s: %w", configFile, err)
 		}
+		var layer map[string]any
+		if err := yaml.Unmarshal(data, &layer); err != nil {
+			return nil, nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+		}
+		mergeInto(merged, layer)
+	}
+	return merged, layers, nil
+}
 
-		if err := yaml.Unmarshal(data, &config); err != nil {
-			return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+// Layers returns the .ap/go.yaml files that apply to the ap root at root, from the repository root down to root.
+// Outside of a git repository, only the file of root itself applies.
+func Layers(root string) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	top := root
+	if repoRoot, err := repo.FindRoot(root); err == nil {
+		if rel, err := filepath.Rel(repoRoot, root); err == nil && !strings.HasPrefix(rel, "..") {
+			top = repoRoot
 		}
-	} else if !os.IsNotExist(err) {
-		return nil, fmt.Errorf("error checking %s: %w", configFile, err)
 	}
 
-	return &config, nil
+	var layers []string
+	for dir := root; ; dir = filepath.Dir(dir) {
+		configFile := filepath.Join(dir, ".ap", "go.yaml")
+		if _, err := os.Stat(configFile); err == nil {
+			layers = append(layers, configFile)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error checking %s: %w", configFile, err)
+		}
+		if dir == top || filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	slices.Reverse(layers)
+	return layers, nil
+}
+
+// mergeInto merges src into dst; nested mappings are merged, other values in src replace those in dst.
+func mergeInto(dst, src map[string]any) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]any)
+		dstMap, ok2 := dst[k].(map[string]any)
+		if ok && ok2 {
+			mergeInto(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// Effective returns a copy of the config with the defaults filled in, so that every setting is explicit.
+func (c *Config) Effective() *Config {
+	minTokens, ignoreIdentifiers := dupcode.DefaultMinTokens, false
+	if c.Lint != nil && c.Lint.DupCode != nil {
+		if c.Lint.DupCode.MinTokens > 0 {
+			minTokens = c.Lint.DupCode.MinTokens
+		}
+		ignoreIdentifiers = c.Lint.DupCode.IgnoreIdentifiers
+	}
+
+	skip := slices.Clone(c.Skip)
+	if skip == nil {
+		skip = []string{}
+	}
+
+	largeFiles := &LargeFilesConfig{
+		Mode:    mode(c.IsLargeFilesEnabled(), c.IsLargeFilesError()),
+		MaxSize: largefiles.FormatSize(largefiles.DefaultMaxSize),
+		Allow:   c.LargeFilesAllow(),
+	}
+	if c.Lint != nil && c.Lint.LargeFiles != nil && c.Lint.LargeFiles.MaxSize != "" {
+		largeFiles.MaxSize = c.Lint.LargeFiles.MaxSize
+	}
+	modes := c.ConcurrencyModes()
+	concurrency := &ConcurrencyConfig{
+		MutexCopy:       modes["mutexcopy"],
+		WaitGroupAdd:    modes["waitgroupadd"],
+		HandlerMapWrite: modes["handlermapwrite"],
+		TimeTick:        modes["timetick"],
+	}
+	return &Config{
+		Gofmt:       &GofmtConfig{Enabled: ptr(c.IsGofmtEnabled())},
+		Goimports:   &GoimportsConfig{Enabled: ptr(c.IsGoimportsEnabled()), LocalPrefix: c.GoimportsLocalPrefix()},
+		Govet:       &GovetConfig{Enabled: ptr(c.IsGovetEnabled())},
+		Govulncheck: &GovulncheckConfig{Enabled: ptr(c.IsGovulncheckEnabled())},
+		Skip:        skip,
+		Lint: &LintConfig{
+			Unused:           &UnusedConfig{Enabled: ptr(c.IsUnusedEnabled()), Exported: mode(c.IsUnusedExportedEnabled(), c.IsUnusedExportedError()), SuppressComment: c.UnusedSuppressComment()},
+			TestContext:      &TestContextConfig{Mode: mode(c.IsTestContextEnabled(), c.IsTestContextError())},
+			UnusedParameters: &UnusedParametersConfig{Mode: unusedParametersMode(c)},
+			DupCode:          &DupCodeConfig{Enabled: ptr(c.IsDupCodeEnabled()), MinTokens: minTokens, IgnoreIdentifiers: ignoreIdentifiers},
+			CobraCmd:         &CobraCmdConfig{Mode: mode(c.IsCobraCmdEnabled(), c.IsCobraCmdError())},
+			MajorVersions:    &MajorVersionsConfig{Mode: mode(c.IsMajorVersionsEnabled(), c.IsMajorVersionsError()), Libraries: c.SingleMajorLibraries()},
+			LargeFiles:       largeFiles,
+			Concurrency:      concurrency,
+			LargeCopy:        &LargeCopyConfig{Mode: mode(c.IsLargeCopyEnabled(), c.IsLargeCopyError()), MaxSize: c.LargeCopyMaxSize()},
+			ChangedLinesOnly: c.ChangedLinesOnlyRules(),
+		},
+	}
+}
+
+// mode returns the mode of a check that is ignored, or reported as a warning or an error.
+func mode(enabled bool, isError bool) string {
+	switch {
+	case !enabled:
+		return "ignore"
+	case isError:
+		return "error"
+	}
+	return "warn"
+}
+
+// unusedParametersMode returns the mode of the unused parameters check; any mode other than "skip" enables it.
+func unusedParametersMode(c *Config) string {
+	if !c.IsUnusedParametersEnabled() {
+		return "skip"
+	}
+	if c.Lint.UnusedParameters.Mode == "" {
+		return "check"
+	}
+	return c.Lint.UnusedParameters.Mode
+}
+
+func ptr[T any](v T) *T {
+	return &v
 }
 
 // IsGofmtEnabled returns true if gofmt is enabled in the config (defaulting to true).
@@ -89,6 +326,22 @@ func (c *Config) IsGofmtEnabled() bool {
 	return true
 }
 
+// IsGoimportsEnabled returns true if goimports is enabled in the config (defaulting to false).
+func (c *Config) IsGoimportsEnabled() bool {
+	if c.Goimports != nil && c.Goimports.Enabled != nil {
+		return *c.Goimports.Enabled
+	}
+	return false
+}
+
+// GoimportsLocalPrefix returns the configured local prefix of goimports, or "" to use the module path.
+func (c *Config) GoimportsLocalPrefix() string {
+	if c.Goimports != nil {
+		return c.Goimports.LocalPrefix
+	}
+	return ""
+}
+
 // IsGovetEnabled returns true if govet is enabled in the config (defaulting to true).
 func (c *Config) IsGovetEnabled() bool {
 	if c.Govet != nil && c.Govet.Enabled != nil {
@@ -113,6 +366,32 @@ func (c *Config) IsUnusedEnabled() bool {
 	return true
 }
 
+// UnusedSuppressComment returns the configured comment suppressing unused findings, or "" if there is none.
+func (c *Config) UnusedSuppressComment() string {
+	if c.Lint != nil && c.Lint.Unused != nil {
+		return c.Lint.Unused.SuppressComment
+	}
+	return ""
+}
+
+// IsUnusedExportedEnabled returns true if the module-wide check for unused exported functions and types is enabled,
+// which also requires unused detection to be enabled. Default is false.
+func (c *Config) IsUnusedExportedEnabled() bool {
+	if c.IsUnusedEnabled() && c.Lint != nil && c.Lint.Unused != nil {
+		return c.Lint.Unused.Exported != "" && c.Lint.Unused.Exported != "ignore"
+	}
+	return false
+}
+
+// IsUnusedExportedError returns true if unused exported functions and types should be reported as an error.
+// Default is false (warning).
+func (c *Config) IsUnusedExportedError() bool {
+	if c.Lint != nil && c.Lint.Unused != nil {
+		return c.Lint.Unused.Exported == "error"
+	}
+	return false
+}
+
 // IsUnusedParametersEnabled returns true if unused parameter detection is enabled.
 // Default is false.
 func (c *Config) IsUnusedParametersEnabled() bool {
@@ -138,3 +417,143 @@ func (c *Config) IsTestContextError() bool {
 	}
 	return false
 }
+
+// IsDupCodeEnabled returns true if duplicate code detection is enabled in the config (defaulting to true).
+func (c *Config) IsDupCodeEnabled() bool {
+	if c.Lint != nil && c.Lint.DupCode != nil && c.Lint.DupCode.Enabled != nil {
+		return *c.Lint.DupCode.Enabled
+	}
+	return true
+}
+
+// IsCobraCmdEnabled returns true if the cobra command check is enabled in the config (defaulting to true).
+func (c *Config) IsCobraCmdEnabled() bool {
+	if c.Lint != nil && c.Lint.CobraCmd != nil {
+		return c.Lint.CobraCmd.Mode != "ignore"
+	}
+	return true
+}
+
+// IsCobraCmdError returns true if cobra command convention violations should be reported as an error.
+// Default is false (warning).
+func (c *Config) IsCobraCmdError() bool {
+	if c.Lint != nil && c.Lint.CobraCmd != nil {
+		return c.Lint.CobraCmd.Mode == "error"
+	}
+	return false
+}
+
+// ConcurrencyModes returns the mode of each concurrency check ("ignore", "warn" or "error"), keyed by analyzer name.
+func (c *Config) ConcurrencyModes() map[string]string {
+	var cc ConcurrencyConfig
+	if c.Lint != nil && c.Lint.Concurrency != nil {
+		cc = *c.Lint.Concurrency
+	}
+	modes := map[string]string{
+		"mutexcopy":       cc.MutexCopy,
+		"waitgroupadd":    cc.WaitGroupAdd,
+		"handlermapwrite": cc.HandlerMapWrite,
+		"timetick":        cc.TimeTick,
+	}
+	for name, mode := range modes {
+		if mode == "" {
+			modes[name] = "warn"
+		}
+	}
+	return modes
+}
+
+// IsLargeCopyEnabled returns true if the check for large structs passed by value is enabled. Default is false.
+func (c *Config) IsLargeCopyEnabled() bool {
+	if c.Lint != nil && c.Lint.LargeCopy != nil {
+		return c.Lint.LargeCopy.Mode != "" && c.Lint.LargeCopy.Mode != "ignore"
+	}
+	return false
+}
+
+// IsLargeCopyError returns true if large structs passed by value should be reported as an error.
+// Default is false (warning).
+func (c *Config) IsLargeCopyError() bool {
+	if c.Lint != nil && c.Lint.LargeCopy != nil {
+		return c.Lint.LargeCopy.Mode == "error"
+	}
+	return false
+}
+
+// LargeCopyMaxSize returns the size in bytes over which a struct passed by value is reported.
+func (c *Config) LargeCopyMaxSize() int64 {
+	if c.Lint != nil && c.Lint.LargeCopy != nil && c.Lint.LargeCopy.MaxSize > 0 {
+		return c.Lint.LargeCopy.MaxSize
+	}
+	return largecopy.DefaultMaxSize
+}
+
+// ChangedLinesOnlyRules returns the rules whose errors only fail the lint on the lines changed by a pull request.
+func (c *Config) ChangedLinesOnlyRules() []string {
+	if c.Lint != nil && c.Lint.ChangedLinesOnly != nil {
+		return c.Lint.ChangedLinesOnly
+	}
+	return []string{}
+}
+
+// IsMajorVersionsEnabled returns true if the single major version check is enabled in the config (defaulting to true).
+func (c *Config) IsMajorVersionsEnabled() bool {
+	if c.Lint != nil && c.Lint.MajorVersions != nil {
+		return c.Lint.MajorVersions.Mode != "ignore"
+	}
+	return true
+}
+
+// IsMajorVersionsError returns true if libraries required at several major versions should be reported as an error.
+// Default is true.
+func (c *Config) IsMajorVersionsError() bool {
+	if c.Lint != nil && c.Lint.MajorVersions != nil {
+		return c.Lint.MajorVersions.Mode != "warn"
+	}
+	return true
+}
+
+// IsLargeFilesEnabled returns true if the check for binary and large files is enabled in the config (defaulting to true).
+func (c *Config) IsLargeFilesEnabled() bool {
+	if c.Lint != nil && c.Lint.LargeFiles != nil {
+		return c.Lint.LargeFiles.Mode != "ignore"
+	}
+	return true
+}
+
+// IsLargeFilesError returns true if binary and large files should be reported as an error.
+// Default is true.
+func (c *Config) IsLargeFilesError() bool {
+	if c.Lint != nil && c.Lint.LargeFiles != nil {
+		return c.Lint.LargeFiles.Mode != "warn"
+	}
+	return true
+}
+
+// LargeFilesMaxSize returns the size in bytes over which files are flagged.
+func (c *Config) LargeFilesMaxSize() (int64, error) {
+	if c.Lint != nil && c.Lint.LargeFiles != nil && c.Lint.LargeFiles.MaxSize != "" {
+		size, err := largefiles.ParseSize(c.Lint.LargeFiles.MaxSize)
+		if err != nil {
+			return 0, fmt.Errorf("lint.largefiles.maxSize: %w", err)
+		}
+		return size, nil
+	}
+	return largefiles.DefaultMaxSize, nil
+}
+
+// LargeFilesAllow returns the patterns of the files that are never flagged as binary or large.
+func (c *Config) LargeFilesAllow() []string {
+	if c.Lint != nil && c.Lint.LargeFiles != nil && c.Lint.LargeFiles.Allow != nil {
+		return slices.Clone(c.Lint.LargeFiles.Allow)
+	}
+	return []string{}
+}
+
+// SingleMajorLibraries returns the libraries that must be required at a single major version.
+func (c *Config) SingleMajorLibraries() []string {
+	if c.Lint != nil && c.Lint.MajorVersions != nil && c.Lint.MajorVersions.Libraries != nil {
+		return slices.Clone(c.Lint.MajorVersions.Libraries)
+	}
+	return slices.Clone(DefaultSingleMajorLibraries)
+}
@@ -15,8 +15,10 @@
 package config
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -87,3 +89,123 @@ func TestLoadDefault(t *testing.T) {
 		t.Errorf("expected default govulncheck enabled to be true")
 	}
 }
+
+func TestLoadLayered(t *testing.T) {
+	repoRoot := t.TempDir()
+	sub := filepath.Join(repoRoot, "tools", "sub")
+	files := map[string]string{
+		".ap/go.yaml":           "gofmt:\n  enabled: false\nskip:\n- vendor/\nlint:\n  dupcode:\n    minTokens: 150\n    ignoreIdentifiers: true\n",
+		"tools/sub/.ap/go.yaml": "skip:\n- gen/\nlint:\n  dupcode:\n    minTokens: 200\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(repoRoot, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	layers, err := Layers(sub)
+	if err != nil {
+		t.Fatalf("Layers failed: %v", err)
+	}
+	wantLayers := []string{filepath.Join(repoRoot, ".ap", "go.yaml"), filepath.Join(sub, ".ap", "go.yaml")}
+	if !reflect.DeepEqual(layers, wantLayers) {
+		t.Errorf("Layers() = %v, want %v", layers, wantLayers)
+	}
+
+	cfg, err := Load(sub)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// Settings are inherited from the repository root, mappings are merged, and lists are replaced.
+	if cfg.IsGofmtEnabled() {
+		t.Errorf("expected gofmt enabled to be inherited as false")
+	}
+	if !reflect.DeepEqual(cfg.Skip, []string{"gen/"}) {
+		t.Errorf("unexpected skip list: %v", cfg.Skip)
+	}
+	if got := cfg.Lint.DupCode; got.MinTokens != 200 || !got.IgnoreIdentifiers {
+		t.Errorf("unexpected dupcode config: %+v", got)
+	}
+
+	// The repository root does not inherit from its nested ap roots.
+	cfg, err = Load(repoRoot)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Lint.DupCode.MinTokens != 150 {
+		t.Errorf("unexpected dupcode config at the root: %+v", cfg.Lint.DupCode)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	cfg := &Config{Lint: &LintConfig{CobraCmd: &CobraCmdConfig{Mode: "error"}}}
+	got := cfg.Effective()
+
+	if !*got.Gofmt.Enabled || !*got.Lint.DupCode.Enabled || got.Lint.DupCode.MinTokens != 100 {
+		t.Errorf("Effective() did not fill in the defaults: %+v", got)
+	}
+	if got.Lint.CobraCmd.Mode != "error" || got.Lint.TestContext.Mode != "warn" || got.Lint.UnusedParameters.Mode != "skip" {
+		t.Errorf("Effective() modes = %s, %s, %s; want error, warn, skip", got.Lint.CobraCmd.Mode, got.Lint.TestContext.Mode, got.Lint.UnusedParameters.Mode)
+	}
+	if got.Skip == nil {
+		t.Errorf("Effective() skip is nil, want empty")
+	}
+	if lf := got.Lint.LargeFiles; lf.Mode != "error" || lf.MaxSize != "1MB" || lf.Allow == nil {
+		t.Errorf("Effective() largefiles = %+v, want mode error, maxSize 1MB and an empty allow list", lf)
+	}
+	if got.Lint.Unused.Exported != "ignore" {
+		t.Errorf("Effective() unused exported mode = %s, want ignore", got.Lint.Unused.Exported)
+	}
+	if lc := got.Lint.LargeCopy; lc.Mode != "ignore" || lc.MaxSize != 128 {
+		t.Errorf("Effective() largecopy = %+v, want mode ignore and maxSize 128", lc)
+	}
+}
+
+func TestUnusedExported(t *testing.T) {
+	tests := []struct {
+		name        string
+		unused      *UnusedConfig
+		wantEnabled bool
+		wantError   bool
+	}{
+		{name: "default"},
+		{name: "warn", unused: &UnusedConfig{Exported: "warn"}, wantEnabled: true},
+		{name: "error", unused: &UnusedConfig{Exported: "error"}, wantEnabled: true, wantError: true},
+		{name: "ignore", unused: &UnusedConfig{Exported: "ignore"}},
+		{name: "unused disabled", unused: &UnusedConfig{Enabled: ptr(false), Exported: "error"}, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Lint: &LintConfig{Unused: tt.unused}}
+			if got := cfg.IsUnusedExportedEnabled(); got != tt.wantEnabled {
+				t.Errorf("IsUnusedExportedEnabled() = %v, want %v", got, tt.wantEnabled)
+			}
+			if got := cfg.IsUnusedExportedError(); got != tt.wantError {
+				t.Errorf("IsUnusedExportedError() = %v, want %v", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestConcurrencyModes(t *testing.T) {
+	cfg := &Config{Lint: &LintConfig{Concurrency: &ConcurrencyConfig{MutexCopy: "error", TimeTick: "ignore"}}}
+	want := map[string]string{
+		"mutexcopy":       "error",
+		"waitgroupadd":    "warn",
+		"handlermapwrite": "warn",
+		"timetick":        "ignore",
+	}
+	if got := cfg.ConcurrencyModes(); !maps.Equal(got, want) {
+		t.Errorf("ConcurrencyModes() = %v, want %v", got, want)
+	}
+	if got := (&Config{}).ConcurrencyModes()["mutexcopy"]; got != "warn" {
+		t.Errorf("default mutexcopy mode = %s, want warn", got)
+	}
+}
@@ -15,11 +15,17 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/repo"
 )
 
 // FindAllAPRoots finds all directories containing a .ap directory within the given repoRoot.
+// Nested repositories (e.g. submodules) are skipped, as their ap roots belong to them.
 func FindAllAPRoots(repoRoot string) ([]string, error) {
 	var roots []string
 	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
@@ -33,6 +39,9 @@ func FindAllAPRoots(repoRoot string) ([]string, error) {
 			if info.Name() == "vendor" || info.Name() == "node_modules" {
 				return filepath.SkipDir
 			}
+			if path != repoRoot && repo.IsNestedRepo(path) {
+				return filepath.SkipDir
+			}
 			if _, err := os.Stat(filepath.Join(path, ".ap")); err == nil {
 				roots = append(roots, path)
 			}
@@ -44,3 +53,49 @@ func FindAllAPRoots(repoRoot string) ([]string, error) {
 	}
 	return roots, nil
 }
+
+// SelectAPRoots returns the ap roots among apRoots named by paths, in the order of apRoots.
+// A relative path is resolved against workDir if it exists there, and against repoRoot otherwise,
+// so that "ap test --root github-admin" works from the repository root and from within it.
+func SelectAPRoots(repoRoot, workDir string, apRoots []string, paths []string) ([]string, error) {
+	selected := map[string]bool{}
+	for _, path := range paths {
+		root := path
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(workDir, path)
+			if _, err := os.Stat(root); err != nil {
+				root = filepath.Join(repoRoot, path)
+			}
+		}
+		root = filepath.Clean(root)
+		if !slices.Contains(apRoots, root) {
+			return nil, fmt.Errorf("%s is not an ap root; the ap roots are %s", path, strings.Join(RootNames(repoRoot, apRoots), ", "))
+		}
+		selected[root] = true
+	}
+
+	var roots []string
+	for _, root := range apRoots {
+		if selected[root] {
+			roots = append(roots, root)
+		}
+	}
+	return roots, nil
+}
+
+// RootName returns the path of the ap root at root relative to repoRoot, or "." for the repository root itself.
+func RootName(repoRoot, root string) string {
+	if rel, err := filepath.Rel(repoRoot, root); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return root
+}
+
+// RootNames returns the RootName of each of roots.
+func RootNames(repoRoot string, roots []string) []string {
+	var names []string
+	for _, root := range roots {
+		names = append(names, RootName(repoRoot, root))
+	}
+	return names
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindAllAPRoots(t *testing.T) {
+	repoRoot := t.TempDir()
+	for _, dir := range []string{
+		".git",
+		".ap",
+		"tools/foo/.ap",
+		"vendor/example.com/bar/.ap",
+		"third_party/lib/.ap",
+	} {
+		if err := os.MkdirAll(filepath.Join(repoRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// third_party/lib is a submodule, so its ap root belongs to another repository.
+	if err := os.WriteFile(filepath.Join(repoRoot, "third_party", "lib", ".git"), []byte("gitdir: ../../.git/modules/lib\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindAllAPRoots(repoRoot)
+	if err != nil {
+		t.Fatalf("FindAllAPRoots failed: %v", err)
+	}
+	want := []string{repoRoot, filepath.Join(repoRoot, "tools", "foo")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllAPRoots() = %v, want %v", got, want)
+	}
+}
+
+func TestSelectAPRoots(t *testing.T) {
+	repoRoot := t.TempDir()
+	apRoots := []string{repoRoot, filepath.Join(repoRoot, "github-admin"), filepath.Join(repoRoot, "tools", "foo")}
+	for _, root := range apRoots {
+		if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		workDir string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "relative to the repository root",
+			workDir: repoRoot,
+			paths:   []string{"tools/foo", "github-admin/"},
+			want:    apRoots[1:],
+		},
+		{
+			name:    "relative to the working directory",
+			workDir: filepath.Join(repoRoot, "tools"),
+			paths:   []string{"foo"},
+			want:    apRoots[2:],
+		},
+		{
+			name:    "falls back to the repository root",
+			workDir: filepath.Join(repoRoot, "tools"),
+			paths:   []string{"github-admin", ".."},
+			want:    apRoots[:2],
+		},
+		{
+			name:    "absolute",
+			workDir: repoRoot,
+			paths:   []string{apRoots[1]},
+			want:    apRoots[1:2],
+		},
+		{
+			name:    "not an ap root",
+			workDir: repoRoot,
+			paths:   []string{"tools"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectAPRoots(repoRoot, tt.workDir, apRoots, tt.paths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectAPRoots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectAPRoots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := RootNames(repoRoot, apRoots); !reflect.DeepEqual(got, []string{".", "github-admin", "tools/foo"}) {
+		t.Errorf("RootNames() = %v", got)
+	}
+}
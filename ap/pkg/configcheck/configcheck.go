@@ -0,0 +1,481 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configcheck checks the .ap config files, and the examples of them in the docs, against the Go types
+// the files are loaded into: unknown keys are otherwise silently ignored, and type errors are reported
+// without a line number. It also derives JSON schemas for the files from the same types.
+package configcheck
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/e2e"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/k8s"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/languages"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/shell"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/fileheaders"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/textstyle"
+	kubelintconfig "github.com/gke-labs/gke-labs-infra/kubelint/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is the rule name of the findings.
+const Rule = "configkeys"
+
+// schemas maps the name of each config file in .ap to the type it is loaded into.
+var schemas = map[string]reflect.Type{
+	"ap.yaml":     reflect.TypeFor[generate.APConfig](),
+	"ci.yaml":     reflect.TypeFor[generate.CIConfig](),
+	"deploy.yaml": reflect.TypeFor[k8s.DeployConfig](),
+	"e2e.yaml":    reflect.TypeFor[e2e.Config](),
+	// The older name of headers.yaml, still read if headers.yaml does not exist.
+	"file-headers.yaml": reflect.TypeFor[fileheaders.Config](),
+	"format.yaml":       reflect.TypeFor[textstyle.Config](),
+	"generate.yaml":     reflect.TypeFor[generate.Config](),
+	"go.yaml":           reflect.TypeFor[config.Config](),
+	"headers.yaml":      reflect.TypeFor[fileheaders.Config](),
+	"images.yaml":       reflect.TypeFor[images.Config](),
+	"kubelint.yaml":     reflect.TypeFor[kubelintconfig.Config](),
+	"languages.yaml":    reflect.TypeFor[languages.Config](),
+	"mocks.yaml":        reflect.TypeFor[generate.MocksConfig](),
+	"quarantine.yaml":   reflect.TypeFor[golang.QuarantineConfig](),
+	"shell.yaml":        reflect.TypeFor[shell.Config](),
+	"tasks.yaml":        reflect.TypeFor[tasks.Config](),
+}
+
+// skipDirs are not searched for config files and docs.
+// renamed maps the older names of config files, which are still read, to their current names.
+var renamed = map[string]string{
+	"file-headers.yaml": "headers.yaml",
+}
+
+var skipDirs = map[string]bool{".git": true, ".build": true, "node_modules": true, "vendor": true, "third_party": true}
+
+// Lint checks every YAML file in a .ap directory under root (including those in testdata) and
+// .codestyle/file-headers.yaml files, and the YAML blocks
+// of Markdown files under root that document a .ap config file.
+// A key that no field accepts is an error; a YAML file in .ap that ap does not read is a warning.
+func Lint(root string) ([]findings.Finding, error) {
+	return walk(root, true)
+}
+
+// Validate checks the config files under root like Lint, but not the docs.
+func Validate(root string) ([]findings.Finding, error) {
+	return walk(root, false)
+}
+
+// CheckAPRoot checks the config files in the .ap directory of a single ap root.
+// It is cheap enough to run before every command.
+func CheckAPRoot(apRoot string) ([]findings.Finding, error) {
+	entries, err := os.ReadDir(filepath.Join(apRoot, ".ap"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var found []findings.Finding
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		f, err := checkConfigFile(filepath.Join(apRoot, ".ap", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, f...)
+	}
+	return found, nil
+}
+
+func walk(root string, docs bool) ([]findings.Finding, error) {
+	var found []findings.Finding
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case filepath.Base(filepath.Dir(path)) == ".codestyle" && d.Name() == "file-headers.yaml":
+			// The oldest location of the file headers config, still read if there is none in .ap.
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			found = append(found, Check(path, 0, data, schemas[d.Name()])...)
+		case filepath.Base(filepath.Dir(path)) == ".ap":
+			f, err := checkConfigFile(path)
+			if err != nil {
+				return err
+			}
+			found = append(found, f...)
+		case docs && filepath.Ext(path) == ".md":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			found = append(found, checkMarkdown(path, data)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check config files in %s: %w", root, err)
+	}
+	return found, nil
+}
+
+// checkConfigFile checks a file in a .ap directory; files other than YAML are ignored.
+func checkConfigFile(path string) ([]findings.Finding, error) {
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := schemas[name]
+	if !ok {
+		message := fmt.Sprintf("%s is not a config file read by ap (known files: %s)", name, strings.Join(knownFiles(), ", "))
+		if suggestion := closest(name, knownFiles()); suggestion != "" {
+			message = fmt.Sprintf("%s is not a config file read by ap; did you mean %s?", name, suggestion)
+		}
+		return []findings.Finding{{
+			Path:     path,
+			Rule:     Rule,
+			Message:  message,
+			Severity: findings.SeverityWarning,
+		}}, nil
+	}
+	return Check(path, 0, data, t), nil
+}
+
+// Files returns the names of the config files in .ap that ap reads, sorted.
+func Files() []string {
+	return knownFiles()
+}
+
+// knownFiles returns the names of the config files in .ap, sorted.
+func knownFiles() []string {
+	var names []string
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	// configFileRef matches a mention of a config file in Markdown, e.g. "Example `.ap/ci.yaml`:".
+	configFileRef = regexp.MustCompile("`\\.ap/([A-Za-z0-9_-]+\\.yaml)`")
+	// configFileHeading matches a heading naming a config file, e.g. "### ci.yaml".
+	configFileHeading = regexp.MustCompile(`^#+\s+([A-Za-z0-9_-]+\.yaml)\s*$`)
+)
+
+// checkMarkdown checks the YAML blocks of a Markdown file that document a config file.
+// A block documents the config file named by the heading of its section, or mentioned last before it in the section.
+func checkMarkdown(path string, data []byte) []findings.Finding {
+	var found []findings.Finding
+
+	current := ""
+	var block []string
+	blockStart := 0
+	inBlock, inYAML := false, false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		if inBlock {
+			if !strings.HasPrefix(trimmed, "```") {
+				block = append(block, text)
+				continue
+			}
+			inBlock = false
+			if t, ok := schemas[current]; ok && inYAML {
+				found = append(found, Check(path, blockStart, []byte(strings.Join(block, "\n")), t)...)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			lang := strings.TrimPrefix(trimmed, "```")
+			inBlock, inYAML = true, lang == "yaml" || lang == "yml"
+			block, blockStart = nil, line
+		case strings.HasPrefix(trimmed, "#"):
+			current = ""
+			if m := configFileHeading.FindStringSubmatch(trimmed); m != nil {
+				current = m[1]
+			}
+		default:
+			if m := configFileRef.FindAllStringSubmatch(text, -1); m != nil {
+				current = m[len(m)-1][1]
+			}
+		}
+	}
+	return found
+}
+
+// Check returns a finding for each key of the YAML document data that no field of t accepts,
+// and for each value whose kind does not match the type of its field.
+// Lines are reported relative to lineOffset, the line of path that the document starts after.
+func Check(path string, lineOffset int, data []byte, t reflect.Type) []findings.Finding {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []findings.Finding{{
+			Path:     path,
+			Line:     lineOffset + 1,
+			Rule:     Rule,
+			Message:  fmt.Sprintf("cannot parse YAML: %v", err),
+			Severity: findings.SeverityError,
+		}}
+	}
+
+	c := checker{path: path, lineOffset: lineOffset}
+	c.check(&doc, t, "")
+	return c.found
+}
+
+type checker struct {
+	path       string
+	lineOffset int
+	found      []findings.Finding
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// check checks node against t; keyPath is the dotted path of node in the document.
+func (c *checker) check(node *yaml.Node, t reflect.Type, keyPath string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types that unmarshal themselves, and untyped values, accept anything.
+	if reflect.PointerTo(t).Implements(unmarshalerType) || t.Kind() == reflect.Interface {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			c.check(child, t, keyPath)
+		}
+		return
+	case yaml.AliasNode:
+		return
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			c.typeError(node, keyPath, "a list")
+			return
+		}
+		for _, child := range node.Content {
+			c.check(child, t.Elem(), keyPath+"[]")
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			c.typeError(node, keyPath, "a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c.check(node.Content[i+1], t.Elem(), join(keyPath, node.Content[i].Value))
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			c.typeError(node, keyPath, "a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fieldFor(t, key.Value)
+			if !ok {
+				message := fmt.Sprintf("unknown key %q: no field of %s accepts it", join(keyPath, key.Value), t)
+				if suggestion := closest(key.Value, fieldNames(t)); suggestion != "" {
+					message += fmt.Sprintf("; did you mean %q?", suggestion)
+				}
+				c.add(key, message)
+				continue
+			}
+			c.check(value, field.Type, join(keyPath, key.Value))
+		}
+	case reflect.Bool:
+		// The config files are loaded as YAML 1.1, in which yes, no, on and off are booleans too.
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!bool" && !yaml11Bools[strings.ToLower(node.Value)]) {
+			c.typeError(node, keyPath, "a boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			c.typeError(node, keyPath, "an integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			c.typeError(node, keyPath, "a number")
+		}
+	case reflect.String:
+		// Any scalar is converted to a string when the file is loaded.
+		if node.Kind != yaml.ScalarNode {
+			c.typeError(node, keyPath, "a string")
+		}
+	}
+}
+
+var yaml11Bools = map[string]bool{"yes": true, "no": true, "on": true, "off": true, "y": true, "n": true}
+
+func (c *checker) add(node *yaml.Node, message string) {
+	c.found = append(c.found, findings.Finding{
+		Path:     c.path,
+		Line:     c.lineOffset + node.Line,
+		Column:   node.Column,
+		Rule:     Rule,
+		Message:  message,
+		Severity: findings.SeverityError,
+	})
+}
+
+func (c *checker) typeError(node *yaml.Node, keyPath string, want string) {
+	c.add(node, fmt.Sprintf("%q must be %s, not %s", keyPath, want, describe(node)))
+}
+
+// describe returns the kind of value node holds, for error messages.
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.MappingNode:
+		return "a mapping"
+	}
+	switch node.Tag {
+	case "!!bool":
+		return "the boolean " + node.Value
+	case "!!int", "!!float":
+		return "the number " + node.Value
+	}
+	return fmt.Sprintf("the string %q", node.Value)
+}
+
+// fieldFor returns the field of struct t that the JSON key unmarshals into.
+// Like encoding/json, it prefers an exact match of the name but accepts any case.
+func fieldFor(t reflect.Type, key string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for _, field := range reflect.VisibleFields(t) {
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if name == key {
+			return field, true
+		}
+		if folded == nil && strings.EqualFold(name, key) {
+			folded = &field
+		}
+	}
+	if folded != nil {
+		return *folded, true
+	}
+	return reflect.StructField{}, false
+}
+
+// fieldNames returns the JSON names of the fields of struct t.
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for _, field := range reflect.VisibleFields(t) {
+		if name, ok := jsonName(field); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonName returns the key that field is decoded from, or false if the field is not decoded.
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || (field.Anonymous && field.Tag.Get("json") == "") {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// closest returns the candidate most similar to s, if one is close enough to be a likely typo.
+func closest(s string, candidates []string) string {
+	best, bestDistance := "", len(s)/3+1
+	for _, candidate := range candidates {
+		if d := levenshtein(strings.ToLower(s), strings.ToLower(candidate)); d <= bestDistance && (best == "" || d < bestDistance) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func join(keyPath string, key string) string {
+	if keyPath == "" {
+		return key
+	}
+	return keyPath + "." + key
+}
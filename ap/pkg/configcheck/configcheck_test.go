@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configcheck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".ap/headers.yaml": "license: apache-2.0\nskip:\n- vendor/\nignore:\n- third_party/\n",
+		".ap/go.yaml":      "lint:\n  dupcode:\n    minTokens: 150\n    minToken: 150\n",
+		".ap/images.yaml":  "images:\n- name: server\n  buildArgs:\n    ANY_KEY: value\n  tag: v1\n",
+		// Keys are matched case-insensitively, as they are when the config is loaded.
+		"sub/.ap/tasks.yaml":               "Defaults:\n  timeout: 10m\n",
+		"sub/.ap/image.yaml":               "images: []\n",
+		"sub/.codestyle/file-headers.yaml": "license: apache-2.0\nignore: [vendor/]\n",
+		"pkg/testdata/.ap/ci.yaml":         "matrix:\n  go: [stable]\n  arch: [arm64]\n",
+		".build/.ap/go.yaml":               "unknown: true\n",
+		"README.md": "# Docs\n\n" +
+			"### ci.yaml\n\n```yaml\nbackends: [prow]\nbakends: [prow]\n```\n\n" +
+			"## Deploying\n\nExample `.ap/deploy.yaml`:\n```yaml\nnamespace: prod\nnamspace: prod\n```\n\n" +
+			"## Other\n\nNot a config file:\n```yaml\napiVersion: v1\nkind: Pod\n```\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Lint(root)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	var got []string
+	for _, f := range found {
+		f.Path, _ = filepath.Rel(root, f.Path)
+		got = append(got, f.String())
+	}
+
+	want := []string{
+		`.ap/go.yaml:4:5: unknown key "lint.dupcode.minToken": no field of config.DupCodeConfig accepts it; did you mean "minTokens"? [configkeys]`,
+		`.ap/headers.yaml:4:1: unknown key "ignore": no field of fileheaders.Config accepts it [configkeys]`,
+		`.ap/images.yaml:5:3: unknown key "images[].tag": no field of images.ImageConfig accepts it [configkeys]`,
+		`README.md:7:1: unknown key "bakends": no field of generate.CIConfig accepts it; did you mean "backends"? [configkeys]`,
+		`README.md:15:1: unknown key "namspace": no field of k8s.DeployConfig accepts it; did you mean "namespace"? [configkeys]`,
+		`pkg/testdata/.ap/ci.yaml:3:3: unknown key "matrix.arch": no field of generate.CIMatrix accepts it [configkeys]`,
+		`sub/.ap/image.yaml: warning: image.yaml is not a config file read by ap; did you mean images.yaml? [configkeys]`,
+		`sub/.codestyle/file-headers.yaml:2:1: unknown key "ignore": no field of fileheaders.Config accepts it [configkeys]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lint() found:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckInvalidYAML(t *testing.T) {
+	found := Check("README.md", 10, []byte("key: [unclosed\n"), schemas["ap.yaml"])
+	if len(found) != 1 || found[0].Line != 11 || !strings.Contains(found[0].Message, "cannot parse YAML") {
+		t.Errorf("Check() = %+v, want a parse error on line 11", found)
+	}
+}
+
+func TestCheckTypes(t *testing.T) {
+	data := "gofmt:\n  enabled: yes\ngovet:\n  enabled: \"false\"\nskip: vendor/\nlint:\n  dupcode:\n    minTokens: many\n    ignoreIdentifiers: true\n  unused: [true]\n  testcontext:\n    mode:\n      level: error\n"
+	var got []string
+	for _, f := range Check("go.yaml", 0, []byte(data), schemas["go.yaml"]) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		`go.yaml:4:12: "govet.enabled" must be a boolean, not the string "false" [configkeys]`,
+		`go.yaml:5:7: "skip" must be a list, not the string "vendor/" [configkeys]`,
+		`go.yaml:8:16: "lint.dupcode.minTokens" must be an integer, not the string "many" [configkeys]`,
+		`go.yaml:10:11: "lint.unused" must be a mapping, not a list [configkeys]`,
+		`go.yaml:13:7: "lint.testcontext.mode" must be a string, not a mapping [configkeys]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Check() found:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckAPRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".ap", "ap.yaml"), []byte("verison: v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".ap", "README.md"), []byte("```yaml\nnot: checked\n```\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := CheckAPRoot(root)
+	if err != nil {
+		t.Fatalf("CheckAPRoot failed: %v", err)
+	}
+	if len(found) != 1 || !strings.Contains(found[0].Message, `did you mean "version"?`) {
+		t.Errorf("CheckAPRoot() = %+v, want the misspelt version key", found)
+	}
+
+	if found, err := CheckAPRoot(t.TempDir()); err != nil || len(found) != 0 {
+		t.Errorf("CheckAPRoot() without .ap = %+v, %v; want nothing", found, err)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Comments are the doc comments of Go types, keyed by "<package path>.<type>", and of their fields,
+// keyed by "<package path>.<type>.<field>", each joined into a single line.
+type Comments map[string]string
+
+// LoadComments reads the doc comments of the types of the config files from their Go source,
+// which is found with go list in dir, a checkout of the module defining them.
+func LoadComments(ctx context.Context, dir string) (Comments, error) {
+	pkgs := map[string]bool{}
+	for _, t := range schemas {
+		collectPackages(t, pkgs, map[reflect.Type]bool{})
+	}
+	args := []string{"list", "-f", "{{.ImportPath}}={{.Dir}}"}
+	for pkg := range pkgs {
+		args = append(args, pkg)
+	}
+	slices.Sort(args[3:])
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the source of the config types: %w", err)
+	}
+
+	comments := Comments{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		pkg, pkgDir, _ := strings.Cut(line, "=")
+		if err := comments.load(pkg, pkgDir); err != nil {
+			return nil, err
+		}
+	}
+	return comments, nil
+}
+
+// collectPackages adds the packages defining t and the types it refers to to pkgs.
+func collectPackages(t reflect.Type, pkgs map[string]bool, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	if t.PkgPath() != "" {
+		pkgs[t.PkgPath()] = true
+	}
+	for _, field := range reflect.VisibleFields(t) {
+		collectPackages(field.Type, pkgs, seen)
+	}
+}
+
+// load adds the comments of the types declared in the Go files of package pkg, in dir.
+func (c Comments) load(pkg, dir string) error {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", file, err)
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				doc := spec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				key := pkg + "." + spec.Name.Name
+				c.add(key, doc)
+
+				st, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					doc := field.Doc
+					if doc == nil {
+						doc = field.Comment
+					}
+					for _, name := range field.Names {
+						c.add(key+"."+name.Name, doc)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c Comments) add(key string, doc *ast.CommentGroup) {
+	if doc != nil {
+		c[key] = strings.Join(strings.Fields(doc.Text()), " ")
+	}
+}
+
+var (
+	// defaultPhrase matches the default stated in a comment, e.g. "(defaults to 4h)" or ", defaults to true".
+	defaultPhrase = regexp.MustCompile(`\bdefaults? to ([^)]+?)(?:\)|$|\. )`)
+	// defaultMarker matches a value marked as the default, e.g. `"kind" (default)`.
+	defaultMarker = regexp.MustCompile(`(\S+) \(default\)`)
+)
+
+// defaultOf returns the default stated in the description of a field, or "".
+func defaultOf(description string) string {
+	if m := defaultPhrase.FindStringSubmatch(description); m != nil {
+		return strings.TrimSuffix(m[1], ".")
+	}
+	if m := defaultMarker.FindStringSubmatch(description); m != nil {
+		return strings.Trim(m[1], ",")
+	}
+	return ""
+}
+
+// Reference returns the reference documentation of the config file name in Markdown: a table of the fields of
+// each struct type it is made of, with their types, defaults and descriptions from comments.
+func Reference(name string, comments Comments) ([]byte, error) {
+	t, ok := schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a config file read by ap (known files: %s)", name, strings.Join(knownFiles(), ", "))
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	r := &reference{comments: comments, sections: map[reflect.Type]string{}}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# .ap/%s\n\n", name)
+	if doc := comments[typeKey(t)]; doc != "" {
+		fmt.Fprintf(&b, "%s\n\n", doc)
+	}
+	if current, ok := renamed[name]; ok {
+		fmt.Fprintf(&b, "This is the older name of [%s](%s.md), which is read if it does not exist.\n\n", current, strings.TrimSuffix(current, ".yaml"))
+	}
+	if t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(unmarshalerType) {
+		fmt.Fprintf(&b, "The file is a %s.\n", r.typeName(t))
+		return b.Bytes(), nil
+	}
+	r.sections[t] = ""
+	r.writeFields(&b, t)
+	for i := 0; i < len(r.queue); i++ {
+		t := r.queue[i]
+		fmt.Fprintf(&b, "\n## %s\n\n", r.sections[t])
+		if doc := comments[typeKey(t)]; doc != "" {
+			fmt.Fprintf(&b, "%s\n\n", doc)
+		}
+		r.writeFields(&b, t)
+	}
+	return b.Bytes(), nil
+}
+
+// reference renders the struct types of a config file, each in its own section.
+type reference struct {
+	comments Comments
+	// sections are the headings of the struct types, which are rendered in the order they are queued.
+	sections map[reflect.Type]string
+	queue    []reflect.Type
+}
+
+// writeFields writes the table of the fields of struct t.
+func (r *reference) writeFields(b *bytes.Buffer, t reflect.Type) {
+	b.WriteString("| Field | Type | Default | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, field := range reflect.VisibleFields(t) {
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		// Promoted fields are documented in the struct declaring them.
+		owner := t
+		if len(field.Index) > 1 {
+			owner = t.FieldByIndex(field.Index[:len(field.Index)-1]).Type
+			for owner.Kind() == reflect.Pointer {
+				owner = owner.Elem()
+			}
+		}
+		description := r.comments[typeKey(owner)+"."+field.Name]
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", name, r.typeName(field.Type), cell(defaultOf(description)), cell(description))
+	}
+}
+
+// typeName returns the name of type t in the reference, linking to the section of struct types.
+func (r *reference) typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) || t.Kind() == reflect.Interface {
+		return "any"
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "list of " + r.typeName(t.Elem())
+	case reflect.Map:
+		return "map of " + r.typeName(t.Elem())
+	case reflect.Struct:
+		heading, ok := r.sections[t]
+		if !ok {
+			heading = t.Name()
+			if heading == "" {
+				heading = fmt.Sprintf("Object %d", len(r.queue)+1)
+			}
+			r.sections[t] = heading
+			r.queue = append(r.queue, t)
+		}
+		if heading == "" {
+			// The top-level type, e.g. of a recursive config.
+			return "object"
+		}
+		return fmt.Sprintf("[%s](#%s)", heading, anchor(heading))
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	}
+	return "any"
+}
+
+func typeKey(t reflect.Type) string {
+	return t.PkgPath() + "." + t.Name()
+}
+
+// anchor returns the anchor GitHub gives to a heading.
+func anchor(heading string) string {
+	return strings.ReplaceAll(strings.ToLower(heading), " ", "-")
+}
+
+// cell escapes text for a cell of a Markdown table.
+func cell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
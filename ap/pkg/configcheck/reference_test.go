@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configcheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReference(t *testing.T) {
+	comments, err := LoadComments(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("LoadComments failed: %v", err)
+	}
+	got, err := Reference("generate.yaml", comments)
+	if err != nil {
+		t.Fatalf("Reference(generate.yaml) failed: %v", err)
+	}
+	for _, want := range []string{
+		"# .ap/generate.yaml\n\nConfig is the contents of .ap/generate.yaml.\n",
+		"| `controllerGen` | [ControllerGenConfig](#controllergenconfig) |  |  |\n",
+		"\n## ControllerGenConfig\n\nControllerGenConfig configures the built-in controller-gen generator.\n",
+		"| `crdOutput` | string | config/crd | CRDOutput is the directory for the generated CRD YAML, relative to the ap root (defaults to config/crd). |\n",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("Reference(generate.yaml) does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if _, err := Reference("image.yaml", comments); err == nil {
+		t.Errorf("Reference(image.yaml) succeeded, want an error")
+	}
+}
+
+func TestDefaultOf(t *testing.T) {
+	tests := map[string]string{
+		"Version is the version to run (defaults to v1.2.3).":                     "v1.2.3",
+		`MaxLifetime is how long a cluster may live (e.g. "2h", defaults to 4h).`: "4h",
+		`Provider is "kind" (default), "k3d" or "gke".`:                           `"kind"`,
+		"Enabled turns the check on or off.":                                      "",
+		"Cache is on unless disabled, and defaults to true. Really.":              "true",
+	}
+	for description, want := range tests {
+		if got := defaultOf(description); got != want {
+			t.Errorf("defaultOf(%q) = %q, want %q", description, got, want)
+		}
+	}
+}
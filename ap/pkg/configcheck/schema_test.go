@@ -0,0 +1,45 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configcheck
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/goldentest"
+)
+
+// The schemas are checked in, so that editors can validate the config files.
+func TestSchemas(t *testing.T) {
+	for _, name := range Files() {
+		t.Run(name, func(t *testing.T) {
+			got, err := Schema(name)
+			if err != nil {
+				t.Fatalf("Schema(%s) failed: %v", name, err)
+			}
+			if !json.Valid(got) {
+				t.Fatalf("Schema(%s) is not valid JSON", name)
+			}
+			goldentest.CompareFile(t, filepath.Join("..", "..", "docs", "schemas", SchemaFile(name)), got)
+		})
+	}
+}
+
+func TestSchemaUnknownFile(t *testing.T) {
+	if _, err := Schema("image.yaml"); err == nil {
+		t.Errorf("Schema(image.yaml) succeeded, want an error")
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package download fetches external resources (release indexes, tool archives) for ap.
+//
+// Resources are fetched through the proxy configured in the environment (HTTPS_PROXY etc.,
+// or AP_DOWNLOAD_PROXY), transient failures are retried with exponential backoff,
+// and resources with a known sha256 are verified before they are returned.
+// A local mirror directory (AP_DOWNLOAD_MIRROR) can serve resources without the network.
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MirrorEnv names the environment variable holding the mirror directory.
+	MirrorEnv = "AP_DOWNLOAD_MIRROR"
+	// OfflineEnv names the environment variable that, when "true", forbids network access.
+	OfflineEnv = "AP_OFFLINE"
+	// ProxyEnv names the environment variable holding a proxy URL that overrides HTTPS_PROXY and friends.
+	ProxyEnv = "AP_DOWNLOAD_PROXY"
+)
+
+// Resource is an external resource.
+type Resource struct {
+	URL string
+	// SHA256 is the expected hex-encoded sha256 of the content.
+	// Resources without a checksum (e.g. a release index) are treated as mutable:
+	// they are fetched from the network first, and only read from the mirror when the network fails.
+	SHA256 string
+}
+
+// Client fetches resources.
+type Client struct {
+	// MirrorDir, if set, holds copies of resources at MirrorPath.
+	// Resources fetched from the network are also written there, so that a machine with network access can populate it.
+	MirrorDir string
+	// Offline fails instead of fetching from the network.
+	Offline bool
+	// HTTPClient is used to fetch resources from the network.
+	HTTPClient *http.Client
+	// MaxAttempts is the number of times a fetch is attempted before giving up.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles on every retry.
+	BaseDelay time.Duration
+}
+
+// NewFromEnv returns a Client configured from the environment.
+func NewFromEnv() (*Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if s := os.Getenv(ProxyEnv); s != "" {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", ProxyEnv, s, err)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+
+	return &Client{
+		MirrorDir:   os.Getenv(MirrorEnv),
+		Offline:     os.Getenv(OfflineEnv) == "true",
+		HTTPClient:  &http.Client{Transport: transport, Timeout: 5 * time.Minute},
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+	}, nil
+}
+
+// Get returns the content of r, verified against r.SHA256 if set.
+func (c *Client) Get(ctx context.Context, r Resource) ([]byte, error) {
+	mirrorPath := ""
+	if c.MirrorDir != "" {
+		p, err := MirrorPath(c.MirrorDir, r.URL)
+		if err != nil {
+			return nil, err
+		}
+		mirrorPath = p
+	}
+
+	// Resources with a checksum are immutable, so a mirrored copy is as good as a fresh one.
+	if mirrorPath != "" && (r.SHA256 != "" || c.Offline) {
+		data, err := os.ReadFile(mirrorPath)
+		if err == nil {
+			if err := verify(r, data); err != nil {
+				return nil, fmt.Errorf("mirrored copy %s: %w", mirrorPath, err)
+			}
+			klog.V(2).Infof("using mirrored copy %s of %s", mirrorPath, r.URL)
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading %s: %w", mirrorPath, err)
+		}
+	}
+	if c.Offline {
+		return nil, fmt.Errorf("%s is not available offline (%s=true); add it to the mirror in %s=%q", r.URL, OfflineEnv, MirrorEnv, c.MirrorDir)
+	}
+
+	data, err := c.fetch(ctx, r.URL)
+	if err != nil {
+		if mirrorPath != "" && r.SHA256 == "" {
+			if mirrored, readErr := os.ReadFile(mirrorPath); readErr == nil {
+				klog.Warningf("error fetching %s, using mirrored copy %s: %v", r.URL, mirrorPath, err)
+				return mirrored, nil
+			}
+		}
+		return nil, err
+	}
+	if err := verify(r, data); err != nil {
+		return nil, err
+	}
+
+	if mirrorPath != "" {
+		if err := writeFile(mirrorPath, data); err != nil {
+			klog.Warningf("error adding %s to the mirror: %v", r.URL, err)
+		}
+	}
+	return data, nil
+}
+
+// Download writes the content of r to dest, verified against r.SHA256 if set.
+// dest is only replaced once the content has been verified.
+func (c *Client) Download(ctx context.Context, r Resource, dest string) error {
+	data, err := c.Get(ctx, r)
+	if err != nil {
+		return err
+	}
+	return writeFile(dest, data)
+}
+
+// MirrorPath returns where the resource at rawURL is kept in the mirror in dir: <dir>/<host>/<path>,
+// with the escaped query, if any, appended after a "@".
+func MirrorPath(dir string, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q: no host", rawURL)
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	if p == "" || strings.HasSuffix(u.Path, "/") {
+		p += "/index"
+	}
+	if u.RawQuery != "" {
+		p += "@" + url.QueryEscape(u.RawQuery)
+	}
+	clean := filepath.Join(dir, u.Host, filepath.FromSlash(p))
+	if rel, err := filepath.Rel(dir, clean); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid URL %q: path escapes the mirror", rawURL)
+	}
+	return clean, nil
+}
+
+// fetch fetches rawURL from the network, retrying transient failures with exponential backoff.
+func (c *Client) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	attempts := max(c.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		data, retryable, err := c.fetchOnce(ctx, rawURL)
+		if err == nil {
+			return data, nil
+		}
+		if !retryable || attempt >= attempts {
+			return nil, err
+		}
+		delay := c.BaseDelay << (attempt - 1)
+		klog.Warningf("retrying in %v: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// fetchOnce fetches rawURL, and reports whether a failure is worth retrying.
+func (c *Client) fetchOnce(ctx context.Context, rawURL string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		retryable := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retryable, fmt.Errorf("unexpected status code %d fetching %s: %s", resp.StatusCode, rawURL, string(body))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	return data, false, nil
+}
+
+// verify checks data against the checksum of r, if any.
+func verify(r Resource, data []byte) error {
+	if r.SHA256 == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, r.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: got sha256 %s, want %s", r.URL, got, r.SHA256)
+	}
+	return nil
+}
+
+// writeFile writes data to path through a temporary file, so that path never holds partial content.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := io.Copy(f, bytes.NewReader(data)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestGetRetriesAndVerifies(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	c := &Client{MaxAttempts: 3}
+	got, err := c.Get(t.Context(), Resource{URL: server.URL + "/tool.tar.gz", SHA256: sha("content")})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(got) != "content" || requests != 2 {
+		t.Errorf("Get() = %q after %d requests, want %q after 2", got, requests, "content")
+	}
+
+	requests = 0
+	_, err = c.Get(t.Context(), Resource{URL: server.URL + "/tool.tar.gz", SHA256: sha("other")})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	c := &Client{MaxAttempts: 3}
+	if _, err := c.Get(t.Context(), Resource{URL: server.URL + "/missing"}); err == nil {
+		t.Errorf("expected an error")
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestMirror(t *testing.T) {
+	content := "v1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	mirror := t.TempDir()
+	online := &Client{MirrorDir: mirror}
+	pinned := Resource{URL: server.URL + "/tool.tar.gz", SHA256: sha("v1")}
+	index := Resource{URL: server.URL + "/dl/?mode=json"}
+
+	// Fetching online populates the mirror.
+	for _, r := range []Resource{pinned, index} {
+		if _, err := online.Get(t.Context(), r); err != nil {
+			t.Fatalf("Get(%s) failed: %v", r.URL, err)
+		}
+	}
+	indexPath, err := MirrorPath(mirror, index.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(mirror, strings.TrimPrefix(server.URL, "http://"), "dl", "index@mode%3Djson"); indexPath != want {
+		t.Errorf("MirrorPath() = %q, want %q", indexPath, want)
+	}
+	if _, err := os.Stat(indexPath); err != nil {
+		t.Errorf("index was not mirrored: %v", err)
+	}
+
+	// Mutable resources are fetched again while online; pinned ones come from the mirror.
+	content = "v2"
+	if got, _ := online.Get(t.Context(), index); string(got) != "v2" {
+		t.Errorf("Get(index) = %q, want the fresh %q", got, "v2")
+	}
+	if got, _ := online.Get(t.Context(), pinned); string(got) != "v1" {
+		t.Errorf("Get(pinned) = %q, want the mirrored %q", got, "v1")
+	}
+
+	// Offline, everything comes from the mirror, and anything else fails.
+	server.Close()
+	offline := &Client{MirrorDir: mirror, Offline: true}
+	if got, err := offline.Get(t.Context(), index); err != nil || string(got) != "v2" {
+		t.Errorf("offline Get(index) = %q, %v; want %q", got, err, "v2")
+	}
+	if _, err := offline.Get(t.Context(), Resource{URL: server.URL + "/other"}); err == nil {
+		t.Errorf("expected an error for a resource that is not mirrored")
+	}
+
+	// A tampered mirror is detected.
+	pinnedPath, _ := MirrorPath(mirror, pinned.URL)
+	if err := os.WriteFile(pinnedPath, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := offline.Get(t.Context(), pinned); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("binary"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "bin", "tool")
+	c := &Client{}
+	if err := c.Download(t.Context(), Resource{URL: server.URL + "/tool", SHA256: sha("other")}, dest); err == nil {
+		t.Errorf("expected a checksum mismatch")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("unverified content was written to %s", dest)
+	}
+	if err := c.Download(t.Context(), Resource{URL: server.URL + "/tool", SHA256: sha("binary")}, dest); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "binary" {
+		t.Errorf("downloaded %q, want %q", got, "binary")
+	}
+}
+
+func TestMirrorPathRejectsEscapes(t *testing.T) {
+	for _, u := range []string{"https://example.com/../../etc/passwd", "https://../x", "/no/host"} {
+		if got, err := MirrorPath(t.TempDir(), u); err == nil {
+			t.Errorf("MirrorPath(%q) = %q, want an error", u, got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dryrun supports previewing the changes that mutating commands would make, without making them.
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrChanges is returned by a dry run that found changes that would be made.
+var ErrChanges = errors.New("dry run: changes would be made")
+
+// Banner is printed at the start of a dry run.
+const Banner = "*** DRY RUN: no changes will be made ***"
+
+// Report collects the changes that a dry run would make.
+// A nil *Report means that the command is not a dry run.
+type Report struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+// Addf records a change that would be made.
+func (r *Report) Addf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, fmt.Sprintf(format, args...))
+}
+
+// Changes returns the changes recorded so far.
+func (r *Report) Changes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.changes)
+}
+
+// Print writes a summary of the changes to w.
+func (r *Report) Print(w io.Writer) {
+	changes := r.Changes()
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "DRY RUN: no changes would be made")
+		return
+	}
+	fmt.Fprintf(w, "DRY RUN: %d change(s) would be made:\n", len(changes))
+	for _, change := range changes {
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+}
+
+// FileChange is a difference between a directory tree and its copy.
+type FileChange struct {
+	// Path is relative to the root of the tree.
+	Path string
+	// Kind is "added", "modified" or "deleted".
+	Kind string
+}
+
+func (c FileChange) String() string {
+	return c.Kind + ": " + c.Path
+}
+
+// skipDirs are not copied, and not compared, by PreviewInCopy.
+var skipDirs = map[string]bool{".git": true, ".build": true}
+
+// PreviewInCopy copies the tree at root to a temporary directory, runs fn on the copy,
+// and returns the files that fn changed. The tree at root is not modified.
+// The .git directory is linked rather than copied, so git commands still work in the copy.
+func PreviewInCopy(ctx context.Context, root string, fn func(ctx context.Context, dir string) error) ([]FileChange, error) {
+	tmpDir, err := os.MkdirTemp("", "ap-dry-run-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Keep the directory name, as some defaults are derived from it.
+	dir := filepath.Join(tmpDir, filepath.Base(root))
+	if err := copyTree(root, dir); err != nil {
+		return nil, fmt.Errorf("failed to copy %s for dry run: %w", root, err)
+	}
+	if _, err := os.Lstat(filepath.Join(root, ".git")); err == nil {
+		if err := os.Symlink(filepath.Join(root, ".git"), filepath.Join(dir, ".git")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := fn(ctx, dir); err != nil {
+		return nil, err
+	}
+	return diffTrees(root, dir)
+}
+
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() && skipDirs[d.Name()] && rel != "." {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, data, info.Mode().Perm()); err != nil {
+				return err
+			}
+			// Modes are compared, so they must not be changed by the umask.
+			return os.Chmod(target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+// diffTrees returns the regular files that differ between the trees at a and b, sorted by path.
+func diffTrees(a, b string) ([]FileChange, error) {
+	filesA, err := listFiles(a)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := listFiles(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for rel, infoA := range filesA {
+		infoB, ok := filesB[rel]
+		if !ok {
+			changes = append(changes, FileChange{Path: rel, Kind: "deleted"})
+			continue
+		}
+		if infoA.Mode().Perm() != infoB.Mode().Perm() {
+			changes = append(changes, FileChange{Path: rel, Kind: "modified"})
+			continue
+		}
+		same, err := sameContent(filepath.Join(a, rel), filepath.Join(b, rel), infoA, infoB)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			changes = append(changes, FileChange{Path: rel, Kind: "modified"})
+		}
+	}
+	for rel := range filesB {
+		if _, ok := filesA[rel]; !ok {
+			changes = append(changes, FileChange{Path: rel, Kind: "added"})
+		}
+	}
+	slices.SortFunc(changes, func(x, y FileChange) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return changes, nil
+}
+
+func listFiles(root string) (map[string]fs.FileInfo, error) {
+	files := make(map[string]fs.FileInfo)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && skipDirs[d.Name()] && path != root {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = info
+		return nil
+	})
+	return files, err
+}
+
+func sameContent(a, b string, infoA, infoB fs.FileInfo) (bool, error) {
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dryrun
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewInCopy(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"keep.txt":         "unchanged\n",
+		"modify.go":        "package a\n",
+		"sub/delete.txt":   "bye\n",
+		".build/cache.txt": "ignored\n",
+	} {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := PreviewInCopy(t.Context(), root, func(_ context.Context, dir string) error {
+		if filepath.Base(dir) != filepath.Base(root) {
+			t.Errorf("copy is named %q, want %q", filepath.Base(dir), filepath.Base(root))
+		}
+		if err := os.WriteFile(filepath.Join(dir, "modify.go"), []byte("package b\n"), 0644); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(dir, "sub", "delete.txt")); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("hi\n"), 0644); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(dir, ".build"), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, ".build", "out.txt"), []byte("ignored\n"), 0644)
+	})
+	if err != nil {
+		t.Fatalf("PreviewInCopy failed: %v", err)
+	}
+
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	want := "modified: modify.go, added: new.txt, deleted: sub/delete.txt"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("got changes %q, want %q", strings.Join(got, ", "), want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "modify.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "package a\n" {
+		t.Errorf("original tree was modified: %q", data)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var r Report
+	r.Addf("apply %s", "deployment.apps/server")
+	r.Addf("delete %s", "configmap/old")
+
+	var sb strings.Builder
+	r.Print(&sb)
+	want := "DRY RUN: 2 change(s) would be made:\n  apply deployment.apps/server\n  delete configmap/old\n"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ProviderKind creates clusters with kind (https://kind.sigs.k8s.io).
+	ProviderKind = "kind"
+	// ProviderK3d creates clusters with k3d (https://k3d.io).
+	ProviderK3d = "k3d"
+	// ProviderGKE creates short-lived GKE Autopilot clusters with gcloud.
+	ProviderGKE = "gke"
+)
+
+const (
+	// LabelE2E marks GKE clusters created by the e2e harness.
+	LabelE2E = "ap-e2e"
+	// LabelExpiresAt is the unix time after which a GKE cluster created by the e2e harness may be deleted by a janitor,
+	// in case the harness itself failed to delete it.
+	LabelExpiresAt = "ap-expires-at"
+
+	// DefaultMaxLifetime is the lifetime of GKE clusters when the config does not set maxLifetime.
+	DefaultMaxLifetime = 4 * time.Hour
+)
+
+// Cluster is a cluster managed by the e2e harness.
+type Cluster struct {
+	Name string
+
+	// Kubeconfig is the path of the kubeconfig for the cluster; the ambient kubeconfig is not modified.
+	Kubeconfig string
+
+	provider string
+	config   *ClusterConfig
+	root     string
+
+	// expiresAt is the value of the LabelExpiresAt label on GKE clusters.
+	expiresAt time.Time
+
+	// created is true if Up created the cluster, rather than reusing an existing one.
+	created bool
+}
+
+// NewCluster returns the cluster configured for root.
+func NewCluster(root string, config *ClusterConfig) *Cluster {
+	provider := config.Provider
+	if provider == "" {
+		provider = ProviderKind
+	}
+	name := config.Name
+	if name == "" {
+		name = "ap-e2e-" + strings.ToLower(filepath.Base(root))
+		if provider == ProviderGKE {
+			// GKE clusters are ephemeral, so concurrent runs (e.g. in CI) must not collide.
+			name = gkeClusterName(name)
+		}
+	}
+	c := &Cluster{
+		Name:       name,
+		Kubeconfig: filepath.Join(root, ".build", "e2e", name+".kubeconfig"),
+		provider:   provider,
+		config:     config,
+		root:       root,
+	}
+	if provider == ProviderGKE {
+		c.expiresAt = time.Now().Add(config.maxLifetime())
+	}
+	return c
+}
+
+var invalidGKENameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// gkeClusterName returns a unique, valid GKE cluster name (at most 40 characters) starting with prefix.
+func gkeClusterName(prefix string) string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	suffix := "-" + hex.EncodeToString(b)
+
+	name := invalidGKENameChars.ReplaceAllString(prefix, "-")
+	if maxLen := 40 - len(suffix); len(name) > maxLen {
+		name = name[:maxLen]
+	}
+	return strings.TrimRight(name, "-") + suffix
+}
+
+// Up creates the cluster, or reuses it if it already exists, and writes its kubeconfig.
+func (c *Cluster) Up(ctx context.Context) error {
+	if _, err := tools.Require(ctx, tools.Requirement{Tool: c.createArgs()[0], Task: "create the " + c.provider + " cluster for e2e tests", Essential: true}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Kubeconfig), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for kubeconfig: %w", err)
+	}
+
+	exists, err := c.exists(ctx)
+	if err != nil {
+		return err
+	}
+	if exists {
+		klog.Infof("Reusing existing %s cluster %s", c.provider, c.Name)
+		return c.run(ctx, c.kubeconfigArgs())
+	}
+
+	klog.Infof("Creating %s cluster %s", c.provider, c.Name)
+	if err := c.run(ctx, c.createArgs()); err != nil {
+		if c.provider == ProviderGKE {
+			// A failed or interrupted create can still leave a (billed) cluster behind.
+			if downErr := c.Down(context.WithoutCancel(ctx)); downErr != nil {
+				klog.Warningf("failed to clean up cluster %s after failed create: %v", c.Name, downErr)
+			}
+		}
+		return fmt.Errorf("failed to create cluster %s: %w", c.Name, err)
+	}
+	c.created = true
+	if c.provider == ProviderK3d || c.provider == ProviderGKE {
+		// k3d and gcloud do not write the kubeconfig on create without touching the default kubeconfig.
+		return c.run(ctx, c.kubeconfigArgs())
+	}
+	return nil
+}
+
+// LoadImages loads the locally built images into the cluster nodes.
+// This is not supported for GKE clusters, which must pull images from a registry.
+func (c *Cluster) LoadImages(ctx context.Context, local []images.LocalImage) error {
+	if c.provider == ProviderGKE {
+		return fmt.Errorf("cannot load local images into GKE cluster %s; push them to a registry instead", c.Name)
+	}
+	for _, img := range local {
+		klog.Infof("Loading image %s into cluster %s", img.Ref, c.Name)
+		if err := c.run(ctx, c.loadArgs(img)); err != nil {
+			return fmt.Errorf("failed to load image %s into cluster %s: %w", img.Ref, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// Down deletes the cluster and its kubeconfig.
+func (c *Cluster) Down(ctx context.Context) error {
+	klog.Infof("Deleting %s cluster %s", c.provider, c.Name)
+	if err := c.run(ctx, c.deleteArgs()); err != nil {
+		return fmt.Errorf("failed to delete cluster %s: %w", c.Name, err)
+	}
+	if err := os.Remove(c.Kubeconfig); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Teardown deletes the cluster if the e2e harness owns it: if Up created it, or if it is a GKE cluster with the
+// LabelE2E label. Other clusters, such as one made by hand with the configured name, are kept.
+func (c *Cluster) Teardown(ctx context.Context) error {
+	owned, err := c.owned(ctx)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		klog.Infof("Keeping %s cluster %s, which was not created by the e2e harness", c.provider, c.Name)
+		return nil
+	}
+	return c.Down(ctx)
+}
+
+func (c *Cluster) owned(ctx context.Context) (bool, error) {
+	if c.created {
+		return true, nil
+	}
+	if c.provider != ProviderGKE {
+		return false, nil
+	}
+	cmd := exec.CommandContext(ctx, "gcloud", "container", "clusters", "describe", c.Name,
+		"--project", c.config.Project, "--region", c.config.Region, "--format", "value(resourceLabels."+LabelE2E+")")
+	out, err := runner.Output(ctx, cmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the labels of cluster %s: %w", c.Name, err)
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+func (c *Cluster) exists(ctx context.Context) (bool, error) {
+	var args []string
+	switch c.provider {
+	case ProviderK3d:
+		args = []string{"k3d", "cluster", "list", "--no-headers", "-o", "name"}
+	case ProviderGKE:
+		args = []string{"gcloud", "container", "clusters", "list", "--project", c.config.Project,
+			"--filter", "name=" + c.Name, "--format", "value(name)"}
+	default:
+		args = []string{"kind", "get", "clusters"}
+	}
+	out, err := runner.Output(ctx, exec.CommandContext(ctx, args[0], args[1:]...))
+	if err != nil {
+		return false, fmt.Errorf("failed to list %s clusters: %w", c.provider, err)
+	}
+	return slices.Contains(strings.Fields(string(out)), c.Name), nil
+}
+
+func (c *Cluster) createArgs() []string {
+	switch c.provider {
+	case ProviderK3d:
+		args := []string{"k3d", "cluster", "create", c.Name, "--wait",
+			"--kubeconfig-update-default=false", "--kubeconfig-switch-context=false"}
+		if c.config.Config != "" {
+			args = append(args, "--config", filepath.Join(c.root, c.config.Config))
+		}
+		if c.config.NodeImage != "" {
+			args = append(args, "--image", c.config.NodeImage)
+		}
+		return args
+	case ProviderGKE:
+		labels := LabelE2E + "=true," + LabelExpiresAt + "=" + strconv.FormatInt(c.expiresAt.Unix(), 10)
+		return []string{"gcloud", "container", "clusters", "create-auto", c.Name,
+			"--project", c.config.Project, "--region", c.config.Region, "--labels", labels, "--quiet"}
+	default:
+		args := []string{"kind", "create", "cluster", "--name", c.Name, "--kubeconfig", c.Kubeconfig, "--wait", "5m"}
+		if c.config.Config != "" {
+			args = append(args, "--config", filepath.Join(c.root, c.config.Config))
+		}
+		if c.config.NodeImage != "" {
+			args = append(args, "--image", c.config.NodeImage)
+		}
+		return args
+	}
+}
+
+func (c *Cluster) kubeconfigArgs() []string {
+	switch c.provider {
+	case ProviderK3d:
+		return []string{"k3d", "kubeconfig", "write", c.Name, "--output", c.Kubeconfig}
+	case ProviderGKE:
+		// gcloud writes to the file named by KUBECONFIG, see run.
+		return []string{"gcloud", "container", "clusters", "get-credentials", c.Name,
+			"--project", c.config.Project, "--region", c.config.Region}
+	default:
+		return []string{"kind", "export", "kubeconfig", "--name", c.Name, "--kubeconfig", c.Kubeconfig}
+	}
+}
+
+func (c *Cluster) loadArgs(img images.LocalImage) []string {
+	switch c.provider {
+	case ProviderK3d:
+		source := img.Ref
+		if img.Archive != "" {
+			source = img.Archive
+		}
+		return []string{"k3d", "image", "import", source, "--cluster", c.Name}
+	default:
+		if img.Archive != "" {
+			return []string{"kind", "load", "image-archive", img.Archive, "--name", c.Name}
+		}
+		return []string{"kind", "load", "docker-image", img.Ref, "--name", c.Name}
+	}
+}
+
+func (c *Cluster) deleteArgs() []string {
+	switch c.provider {
+	case ProviderK3d:
+		return []string{"k3d", "cluster", "delete", c.Name}
+	case ProviderGKE:
+		return []string{"gcloud", "container", "clusters", "delete", c.Name,
+			"--project", c.config.Project, "--region", c.config.Region, "--quiet"}
+	default:
+		return []string{"kind", "delete", "cluster", "--name", c.Name}
+	}
+}
+
+func (c *Cluster) run(ctx context.Context, args []string) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = c.root
+	if c.provider == ProviderGKE {
+		cmd.Env = append(os.Environ(), "KUBECONFIG="+c.Kubeconfig)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return runner.Run(ctx, cmd)
+}
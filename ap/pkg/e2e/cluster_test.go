@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+)
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(root, ".ap", "e2e.yaml")
+
+	if err := os.WriteFile(configFile, []byte("cluster:\n  provider: k3d\n  loadImages: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Cluster == nil || cfg.Cluster.Provider != ProviderK3d || cfg.Cluster.ShouldLoadImages() {
+		t.Errorf("unexpected config %+v", cfg.Cluster)
+	}
+
+	if err := os.WriteFile(configFile, []byte("cluster:\n  provider: minikube\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(root); err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+
+	if err := os.WriteFile(configFile, []byte("cluster:\n  provider: gke\n  region: us-central1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(root); err == nil {
+		t.Errorf("expected an error for a gke cluster without a project")
+	}
+
+	if err := os.WriteFile(configFile, []byte("cluster:\n  provider: gke\n  project: my-project\n  region: us-central1\n  maxLifetime: 2h\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Cluster.ShouldLoadImages() || cfg.Cluster.maxLifetime() != 2*time.Hour {
+		t.Errorf("unexpected config %+v", cfg.Cluster)
+	}
+
+	cfg, err = LoadConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Cluster != nil {
+		t.Errorf("expected no cluster without a config file")
+	}
+}
+
+func TestClusterArgs(t *testing.T) {
+	koImage := images.LocalImage{Name: "server", Ref: "server:latest", Archive: "/src/app/.build/images/server.tar"}
+	dockerImage := images.LocalImage{Name: "web", Ref: "web:latest"}
+
+	kind := NewCluster("/src/app", &ClusterConfig{Config: "dev/kind.yaml"})
+	k3d := NewCluster("/src/app", &ClusterConfig{Provider: ProviderK3d, Name: "e2e", NodeImage: "rancher/k3s:v1.30.0-k3s1"})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"kind create", kind.createArgs(), "kind create cluster --name ap-e2e-app --kubeconfig /src/app/.build/e2e/ap-e2e-app.kubeconfig --wait 5m --config /src/app/dev/kind.yaml"},
+		{"kind kubeconfig", kind.kubeconfigArgs(), "kind export kubeconfig --name ap-e2e-app --kubeconfig /src/app/.build/e2e/ap-e2e-app.kubeconfig"},
+		{"kind load archive", kind.loadArgs(koImage), "kind load image-archive /src/app/.build/images/server.tar --name ap-e2e-app"},
+		{"kind load docker image", kind.loadArgs(dockerImage), "kind load docker-image web:latest --name ap-e2e-app"},
+		{"kind delete", kind.deleteArgs(), "kind delete cluster --name ap-e2e-app"},
+		{"k3d create", k3d.createArgs(), "k3d cluster create e2e --wait --kubeconfig-update-default=false --kubeconfig-switch-context=false --image rancher/k3s:v1.30.0-k3s1"},
+		{"k3d kubeconfig", k3d.kubeconfigArgs(), "k3d kubeconfig write e2e --output /src/app/.build/e2e/e2e.kubeconfig"},
+		{"k3d load archive", k3d.loadArgs(koImage), "k3d image import /src/app/.build/images/server.tar --cluster e2e"},
+		{"k3d load docker image", k3d.loadArgs(dockerImage), "k3d image import web:latest --cluster e2e"},
+		{"k3d delete", k3d.deleteArgs(), "k3d cluster delete e2e"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.args, " "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGKEClusterArgs(t *testing.T) {
+	gke := NewCluster("/src/app", &ClusterConfig{Provider: ProviderGKE, Name: "e2e", Project: "my-project", Region: "us-central1"})
+	gke.expiresAt = time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"gke create", gke.createArgs(), "gcloud container clusters create-auto e2e --project my-project --region us-central1 --labels ap-e2e=true,ap-expires-at=1700000000 --quiet"},
+		{"gke kubeconfig", gke.kubeconfigArgs(), "gcloud container clusters get-credentials e2e --project my-project --region us-central1"},
+		{"gke delete", gke.deleteArgs(), "gcloud container clusters delete e2e --project my-project --region us-central1 --quiet"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.args, " "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	generated := NewCluster("/src/My_App", &ClusterConfig{Provider: ProviderGKE, Project: "my-project", Region: "us-central1"})
+	if !strings.HasPrefix(generated.Name, "ap-e2e-my-app-") || len(generated.Name) > 40 {
+		t.Errorf("unexpected generated cluster name %q", generated.Name)
+	}
+	if other := NewCluster("/src/My_App", generated.config); other.Name == generated.Name {
+		t.Errorf("expected unique cluster names, got %q twice", other.Name)
+	}
+}
+
+func TestTeardown(t *testing.T) {
+	kindList := runner.Invocation{Args: []string{"kind", "get", "clusters"}, Stdout: "other\n"}
+	kindExisting := runner.Invocation{Args: []string{"kind", "get", "clusters"}, Stdout: "e2e\n"}
+	kindCreate := runner.Invocation{Args: []string{"kind", "create", "cluster", "--name", "e2e", "--kubeconfig", "*", "--wait", "5m"}}
+	kindKubeconfig := runner.Invocation{Args: []string{"kind", "export", "kubeconfig", "--name", "e2e", "--kubeconfig", "*"}}
+	kindDelete := runner.Invocation{Args: []string{"kind", "delete", "cluster", "--name", "e2e"}}
+	gkeDescribe := func(label string) runner.Invocation {
+		return runner.Invocation{Args: []string{"gcloud", "container", "clusters", "describe", "e2e", "--project", "my-project",
+			"--region", "us-central1", "--format", "value(resourceLabels.ap-e2e)"}, Stdout: label + "\n"}
+	}
+	gkeList := runner.Invocation{Args: []string{"gcloud", "container", "clusters", "list", "--project", "my-project",
+		"--filter", "name=e2e", "--format", "value(name)"}, Stdout: "e2e\n"}
+	gkeKubeconfig := runner.Invocation{Args: []string{"gcloud", "container", "clusters", "get-credentials", "e2e", "--project", "my-project", "--region", "us-central1"}}
+	gkeDelete := runner.Invocation{Args: []string{"gcloud", "container", "clusters", "delete", "e2e", "--project", "my-project", "--region", "us-central1", "--quiet"}}
+
+	kind := &ClusterConfig{Name: "e2e"}
+	gke := &ClusterConfig{Provider: ProviderGKE, Name: "e2e", Project: "my-project", Region: "us-central1"}
+	tests := []struct {
+		name        string
+		config      *ClusterConfig
+		invocations []runner.Invocation
+		wantDeleted bool
+	}{
+		{name: "created", config: kind, invocations: []runner.Invocation{kindList, kindCreate, kindDelete}, wantDeleted: true},
+		{name: "made by hand", config: kind, invocations: []runner.Invocation{kindExisting, kindKubeconfig}},
+		{name: "labelled gke cluster", config: gke, invocations: []runner.Invocation{gkeList, gkeKubeconfig, gkeDescribe("true"), gkeDelete}, wantDeleted: true},
+		{name: "unlabelled gke cluster", config: gke, invocations: []runner.Invocation{gkeList, gkeKubeconfig, gkeDescribe("")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayer := runner.NewReplayer(tt.invocations...)
+			ctx := runner.NewContext(context.Background(), replayer)
+			cluster := NewCluster(t.TempDir(), tt.config)
+			if err := cluster.Up(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if err := cluster.Teardown(ctx); err != nil {
+				t.Fatal(err)
+			}
+			lines := replayer.CommandLines()
+			deleted := slices.ContainsFunc(lines, func(line string) bool { return strings.Contains(line, " delete") })
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v; ran %q", deleted, tt.wantDeleted, lines)
+			}
+		})
+	}
+}
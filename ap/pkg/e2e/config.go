@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the contents of .ap/e2e.yaml.
+type Config struct {
+	// Cluster configures a local cluster that is created for the e2e tasks.
+	// If not set, the e2e tasks run against whatever cluster the environment points at.
+	Cluster *ClusterConfig `json:"cluster,omitempty"`
+}
+
+// ClusterConfig configures the cluster managed by the e2e harness.
+type ClusterConfig struct {
+	// Provider is "kind" (default), "k3d" or "gke".
+	Provider string `json:"provider,omitempty"`
+
+	// Name is the cluster name (defaults to ap-e2e-<ap root directory name>, with a random suffix for gke).
+	Name string `json:"name,omitempty"`
+
+	// Config is the kind or k3d config file, relative to the ap root.
+	Config string `json:"config,omitempty"`
+
+	// NodeImage overrides the node image, e.g. to pick the Kubernetes version.
+	NodeImage string `json:"nodeImage,omitempty"`
+
+	// LoadImages builds the images locally and loads them into the cluster (defaults to true, except for gke).
+	LoadImages *bool `json:"loadImages,omitempty"`
+
+	// Keep leaves the cluster running after the e2e tasks, so it is reused by the next runs.
+	// Runs only delete the clusters they create, so a kept kind or k3d cluster must be deleted by hand.
+	Keep bool `json:"keep,omitempty"`
+
+	// Project is the GCP project for gke clusters.
+	Project string `json:"project,omitempty"`
+
+	// Region is the GCP region for gke clusters.
+	Region string `json:"region,omitempty"`
+
+	// MaxLifetime is how long a gke cluster may live (e.g. "2h", defaults to 4h).
+	// It is recorded in a label on the cluster, so that janitors can sweep clusters that were not deleted.
+	MaxLifetime string `json:"maxLifetime,omitempty"`
+}
+
+// LoadConfig loads .ap/e2e.yaml from root, returning an empty config if it does not exist.
+func LoadConfig(root string) (*Config, error) {
+	configFile := filepath.Join(root, ".ap", "e2e.yaml")
+
+	var config Config
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	if config.Cluster != nil {
+		if err := config.Cluster.validate(); err != nil {
+			return nil, fmt.Errorf("error in %s: %w", configFile, err)
+		}
+	}
+	return &config, nil
+}
+
+func (c *ClusterConfig) validate() error {
+	switch c.Provider {
+	case "", ProviderKind, ProviderK3d:
+		return nil
+	case ProviderGKE:
+		if c.Project == "" || c.Region == "" {
+			return fmt.Errorf("project and region are required for the gke cluster provider")
+		}
+		if c.LoadImages != nil && *c.LoadImages {
+			return fmt.Errorf("loadImages is not supported for the gke cluster provider")
+		}
+		if c.MaxLifetime != "" {
+			if d, err := time.ParseDuration(c.MaxLifetime); err != nil || d <= 0 {
+				return fmt.Errorf("invalid maxLifetime %q", c.MaxLifetime)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown cluster provider %q", c.Provider)
+	}
+}
+
+// ShouldLoadImages returns true if images should be loaded into the cluster (defaulting to true, except for gke).
+func (c *ClusterConfig) ShouldLoadImages() bool {
+	if c.LoadImages != nil {
+		return *c.LoadImages
+	}
+	return c.Provider != ProviderGKE
+}
+
+func (c *ClusterConfig) maxLifetime() time.Duration {
+	if d, err := time.ParseDuration(c.MaxLifetime); err == nil && d > 0 {
+		return d
+	}
+	return DefaultMaxLifetime
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package e2e runs the e2e task scripts, optionally against a local cluster that it manages.
+package e2e
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"k8s.io/klog/v2"
+)
+
+// Options configures Run.
+type Options struct {
+	// KeepCluster leaves the cluster running after the tasks, even if the config does not set keep.
+	KeepCluster bool
+}
+
+// Run runs the dev/tasks/test-e2e* scripts under root.
+// If .ap/e2e.yaml configures a cluster, it is created first (with the locally built images loaded into it),
+// the scripts run with KUBECONFIG pointing at it, and it is deleted afterwards if this run created it.
+func Run(ctx context.Context, root string, opt Options) (err error) {
+	e2eTasks, err := tasks.FindTaskScripts(root, tasks.WithPrefix("test-e2e"))
+	if err != nil {
+		return fmt.Errorf("failed to discover e2e tasks in %s: %w", root, err)
+	}
+	if len(e2eTasks) == 0 {
+		return nil
+	}
+
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return err
+	}
+	if cfg.Cluster == nil {
+		return tasks.Run(ctx, root, e2eTasks)
+	}
+
+	cluster := NewCluster(root, cfg.Cluster)
+	if err := cluster.Up(ctx); err != nil {
+		return err
+	}
+	if cfg.Cluster.Keep || opt.KeepCluster {
+		defer klog.Infof("Keeping cluster %s; KUBECONFIG=%s", cluster.Name, cluster.Kubeconfig)
+	} else {
+		defer func() {
+			// Tear down even if the context was cancelled, so we don't leak clusters.
+			if downErr := cluster.Teardown(context.WithoutCancel(ctx)); downErr != nil {
+				if err == nil {
+					err = downErr
+				} else {
+					klog.Warningf("%v", downErr)
+				}
+			}
+		}()
+	}
+
+	if cfg.Cluster.ShouldLoadImages() {
+		if _, err := images.Build(ctx, root, false); err != nil {
+			return fmt.Errorf("build failed for %s: %w", root, err)
+		}
+		local, err := images.LocalImages(root)
+		if err != nil {
+			return err
+		}
+		if err := cluster.LoadImages(ctx, local); err != nil {
+			return err
+		}
+	}
+
+	tasks.AddEnv(e2eTasks, "KUBECONFIG="+cluster.Kubeconfig, "AP_E2E_CLUSTER="+cluster.Name)
+	return tasks.Run(ctx, root, e2eTasks)
+}
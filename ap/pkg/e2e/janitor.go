@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// JanitorOptions configures Janitor.
+type JanitorOptions struct {
+	// Project is the GCP project to scan for leaked e2e clusters.
+	Project string
+
+	// TTL is the maximum age of e2e clusters that do not have an expiry label.
+	TTL time.Duration
+
+	// DryRun reports the clusters that would be deleted without deleting them.
+	DryRun bool
+
+	// Out receives the report.
+	Out io.Writer
+}
+
+// LeakedCluster is a GKE cluster created by the e2e harness.
+type LeakedCluster struct {
+	Name      string
+	Location  string
+	CreatedAt time.Time
+	// ExpiresAt is when the cluster may be deleted, from the LabelExpiresAt label or the TTL.
+	ExpiresAt time.Time
+}
+
+// gkeCluster is the subset of the gcloud container clusters list output that we use.
+type gkeCluster struct {
+	Name           string            `json:"name"`
+	Location       string            `json:"location"`
+	CreateTime     string            `json:"createTime"`
+	ResourceLabels map[string]string `json:"resourceLabels"`
+}
+
+// Janitor deletes the GKE clusters created by the e2e harness that have outlived their expiry,
+// which can happen if ap e2e was killed before it could clean up.
+func Janitor(ctx context.Context, opt JanitorOptions) error {
+	if opt.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+
+	out, err := exec.CommandContext(ctx, "gcloud", "container", "clusters", "list", "--project", opt.Project,
+		"--filter", "resourceLabels."+LabelE2E+"=true", "--format", "json").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list clusters in project %s: %w", opt.Project, err)
+	}
+	clusters, err := parseLeakedClusters(out, opt.TTL)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(opt.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLUSTER\tLOCATION\tAGE\tEXPIRES\tACTION")
+	var expired []LeakedCluster
+	for _, c := range clusters {
+		action := "keep"
+		if !now.Before(c.ExpiresAt) {
+			expired = append(expired, c)
+			action = "delete"
+			if opt.DryRun {
+				action = "delete (dry-run)"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Name, c.Location, now.Sub(c.CreatedAt).Truncate(time.Minute),
+			c.ExpiresAt.UTC().Format(time.RFC3339), action)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if opt.DryRun {
+		return nil
+	}
+
+	// Keep going past failures, so one stuck cluster does not leak the rest.
+	var errs []error
+	for _, c := range expired {
+		klog.Infof("Deleting leaked cluster %s in %s", c.Name, c.Location)
+		cmd := exec.CommandContext(ctx, "gcloud", "container", "clusters", "delete", c.Name,
+			"--project", opt.Project, "--location", c.Location, "--quiet", "--async")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete cluster %s: %w: %s", c.Name, err, out))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to delete %d of %d expired clusters: %v", len(errs), len(expired), errs)
+	}
+	return nil
+}
+
+// parseLeakedClusters parses the JSON output of gcloud container clusters list.
+// Clusters without a valid LabelExpiresAt label expire ttl after they were created.
+func parseLeakedClusters(data []byte, ttl time.Duration) ([]LeakedCluster, error) {
+	var list []gkeCluster
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster list: %w", err)
+	}
+
+	var clusters []LeakedCluster
+	for _, c := range list {
+		if c.ResourceLabels[LabelE2E] != "true" {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, c.CreateTime)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s has invalid createTime %q: %w", c.Name, c.CreateTime, err)
+		}
+		expiresAt := createdAt.Add(ttl)
+		if v, err := strconv.ParseInt(c.ResourceLabels[LabelExpiresAt], 10, 64); err == nil {
+			expiresAt = time.Unix(v, 0)
+		}
+		clusters = append(clusters, LeakedCluster{
+			Name:      c.Name,
+			Location:  c.Location,
+			CreatedAt: createdAt,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return clusters, nil
+}
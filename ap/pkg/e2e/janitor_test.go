@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLeakedClusters(t *testing.T) {
+	data := []byte(`[
+  {"name": "ap-e2e-app-1a2b3c", "location": "us-central1", "createTime": "2026-01-01T10:00:00+00:00",
+   "resourceLabels": {"ap-e2e": "true", "ap-expires-at": "1767268800"}},
+  {"name": "ap-e2e-app-4d5e6f", "location": "us-east1", "createTime": "2026-01-01T10:00:00+00:00",
+   "resourceLabels": {"ap-e2e": "true"}},
+  {"name": "prod", "location": "us-central1", "createTime": "2025-01-01T10:00:00+00:00"}
+]`)
+
+	clusters, err := parseLeakedClusters(data, 6*time.Hour)
+	if err != nil {
+		t.Fatalf("parseLeakedClusters failed: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters, want 2: %+v", len(clusters), clusters)
+	}
+	if got, want := clusters[0].ExpiresAt, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expiry from label: got %v, want %v", got, want)
+	}
+	if got, want := clusters[1].ExpiresAt, time.Date(2026, 1, 1, 16, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expiry from ttl: got %v, want %v", got, want)
+	}
+	if clusters[1].Location != "us-east1" {
+		t.Errorf("unexpected location %q", clusters[1].Location)
+	}
+
+	if _, err := parseLeakedClusters([]byte(`[{"name": "x", "createTime": "yesterday", "resourceLabels": {"ap-e2e": "true"}}]`), time.Hour); err == nil {
+		t.Errorf("expected an error for an invalid createTime")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package execprofile selects the execution profile of ap: the defaults for running it on a developer's machine,
+// in CI or for a sandboxed agent, so that one config tree serves all three without flags on every command.
+package execprofile
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The built-in profiles.
+const (
+	Local = "local"
+	CI    = "ci"
+	Agent = "agent"
+)
+
+// Env names the environment variable that selects the profile, like --execution-profile.
+// ap sets it for the commands it runs, so that they use the same profile.
+const Env = "AP_EXECUTION_PROFILE"
+
+// The modes of a profile.
+const (
+	// ModeFix makes ap format change the files it would format.
+	ModeFix = "fix"
+	// ModeVerify makes ap format only report the changes it would make, as --check does.
+	ModeVerify = "verify"
+)
+
+// Profile is a named set of defaults for running ap. Flags given on the command line take precedence.
+type Profile struct {
+	// Verbosity is the log verbosity, as set by -v.
+	Verbosity *int `json:"verbosity,omitempty"`
+	// Cache is false to turn off the ap cache (~/.cache/ap/codestyle), so that every check runs over all its inputs.
+	Cache *bool `json:"cache,omitempty"`
+	// Parallelism is the number of modules ap warm builds concurrently (default: number of CPUs).
+	Parallelism int `json:"parallelism,omitempty"`
+	// Mode is "fix" to make ap format change files, or "verify" to only report the changes it would make.
+	Mode string `json:"mode,omitempty"`
+	// Offline forbids network access: downloads are served from the mirror only (AP_OFFLINE) and Go does not
+	// fetch modules (GOPROXY=off).
+	Offline bool `json:"offline,omitempty"`
+}
+
+// builtin are the defaults of the built-in profiles, which the profiles configured in .ap/ap.yaml are applied over.
+var builtin = map[string]Profile{
+	Local: {Mode: ModeFix},
+	CI:    {Mode: ModeVerify},
+	Agent: {Mode: ModeFix},
+}
+
+// agentEnvs are environment variables set by coding agents in the commands they run.
+var agentEnvs = []string{"CLAUDECODE", "GEMINI_CLI", "CODEX_SANDBOX", "CURSOR_AGENT"}
+
+// Detect returns the name of the profile for the environment: the one named by Env, ci in CI systems,
+// agent in the commands run by coding agents, and local otherwise.
+func Detect(getenv func(string) string) string {
+	if name := getenv(Env); name != "" {
+		return name
+	}
+	if ci := getenv("CI"); (ci != "" && ci != "false") || getenv("GITHUB_ACTIONS") == "true" {
+		return CI
+	}
+	for _, env := range agentEnvs {
+		if getenv(env) != "" {
+			return Agent
+		}
+	}
+	return Local
+}
+
+// Resolve returns the profile with the given name: the built-in profile of that name, if any, with the fields set
+// in configured (the profiles of .ap/ap.yaml) applied over it.
+func Resolve(name string, configured map[string]Profile) (Profile, error) {
+	p, isBuiltin := builtin[name]
+	c, isConfigured := configured[name]
+	if !isBuiltin && !isConfigured {
+		return Profile{}, fmt.Errorf("unknown execution profile %q (known: %s)", name, strings.Join(Names(configured), ", "))
+	}
+	if c.Verbosity != nil {
+		p.Verbosity = c.Verbosity
+	}
+	if c.Cache != nil {
+		p.Cache = c.Cache
+	}
+	if c.Parallelism != 0 {
+		p.Parallelism = c.Parallelism
+	}
+	if c.Mode != "" {
+		p.Mode = c.Mode
+	}
+	if c.Offline {
+		p.Offline = true
+	}
+
+	if p.Mode != "" && p.Mode != ModeFix && p.Mode != ModeVerify {
+		return Profile{}, fmt.Errorf("execution profile %q: invalid mode %q (must be %q or %q)", name, p.Mode, ModeFix, ModeVerify)
+	}
+	if p.Parallelism < 0 {
+		return Profile{}, fmt.Errorf("execution profile %q: parallelism must not be negative", name)
+	}
+	return p, nil
+}
+
+// Names returns the names of the built-in and configured profiles, sorted.
+func Names(configured map[string]Profile) []string {
+	var names []string
+	for name := range builtin {
+		names = append(names, name)
+	}
+	for name := range configured {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Verify returns whether the profile only verifies, rather than fixes.
+func (p Profile) Verify() bool {
+	return p.Mode == ModeVerify
+}
+
+// CacheOff returns whether the profile turns off the ap cache.
+func (p Profile) CacheOff() bool {
+	return p.Cache != nil && !*p.Cache
+}
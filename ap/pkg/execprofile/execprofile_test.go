@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execprofile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "local", env: nil, want: Local},
+		{name: "ci", env: map[string]string{"CI": "true"}, want: CI},
+		{name: "ci false", env: map[string]string{"CI": "false"}, want: Local},
+		{name: "github actions", env: map[string]string{"GITHUB_ACTIONS": "true"}, want: CI},
+		{name: "agent", env: map[string]string{"CLAUDECODE": "1"}, want: Agent},
+		{name: "ci over agent", env: map[string]string{"CI": "1", "GEMINI_CLI": "1"}, want: CI},
+		{name: "explicit", env: map[string]string{Env: "nightly", "CI": "true"}, want: "nightly"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Detect(func(key string) string { return tc.env[key] })
+			if got != tc.want {
+				t.Errorf("Detect() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	two, off := 2, false
+	configured := map[string]Profile{
+		CI:        {Verbosity: &two, Cache: &off},
+		"offline": {Offline: true, Parallelism: 4},
+		"broken":  {Mode: "sometimes"},
+	}
+
+	tests := []struct {
+		name    string
+		want    Profile
+		wantErr string
+	}{
+		{name: Local, want: Profile{Mode: ModeFix}},
+		{name: CI, want: Profile{Verbosity: &two, Cache: &off, Mode: ModeVerify}},
+		{name: "offline", want: Profile{Offline: true, Parallelism: 4}},
+		{name: "broken", wantErr: `invalid mode "sometimes"`},
+		{name: "unknown", wantErr: "known: agent, broken, ci, local, offline"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Resolve(tc.name, configured)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Resolve() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	if p, _ := Resolve(CI, configured); !p.Verify() || !p.CacheOff() {
+		t.Errorf("ci profile: Verify() = %v, CacheOff() = %v, want both true", p.Verify(), p.CacheOff())
+	}
+}
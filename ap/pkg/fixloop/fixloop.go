@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fixloop runs the automatic fixers and the verification in a loop,
+// so that a single command takes a tree as close to green as tooling can.
+package fixloop
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/worktree"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"k8s.io/klog/v2"
+)
+
+// DefaultMaxIterations is the number of fix and verify rounds run by default.
+const DefaultMaxIterations = 3
+
+// Fixer rewrites files in place, for example by running ap generate or ap format.
+type Fixer struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Verifier checks the tree and returns the problems that remain.
+type Verifier func(ctx context.Context) ([]findings.Finding, error)
+
+// Options configures Run.
+type Options struct {
+	// RepoRoot is the root of the tree the fixers change.
+	RepoRoot string
+	// MaxIterations bounds the number of fix and verify rounds.
+	MaxIterations int
+
+	Fixers []Fixer
+	Verify Verifier
+}
+
+// Fix is a file changed by a fixer.
+type Fix struct {
+	Iteration int    `json:"iteration"`
+	Fixer     string `json:"fixer"`
+	Path      string `json:"path"`
+	// Kind is one of added, modified or deleted.
+	Kind string `json:"kind"`
+}
+
+// Problem is a finding the fixers could not resolve.
+type Problem struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Hint suggests how to resolve the problem by hand.
+	Hint string `json:"hint,omitempty"`
+}
+
+// Summary is the outcome of Run.
+type Summary struct {
+	Iterations int `json:"iterations"`
+	// Green is true if the last verification found no errors.
+	Green     bool      `json:"green"`
+	Fixed     []Fix     `json:"fixed"`
+	Remaining []Problem `json:"remaining"`
+}
+
+// Run alternates between running the fixers and verifying the tree.
+// It stops once verification passes, once a round of fixers changes nothing,
+// or after opt.MaxIterations rounds.
+func Run(ctx context.Context, opt Options) (*Summary, error) {
+	maxIterations := opt.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxIterations
+	}
+
+	summary := &Summary{Fixed: []Fix{}, Remaining: []Problem{}}
+	var remaining []findings.Finding
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		summary.Iterations = iteration
+
+		changed := false
+		for _, fixer := range opt.Fixers {
+			klog.Infof("Running %s (iteration %d)", fixer.Name, iteration)
+			fixes, err := runFixer(ctx, opt.RepoRoot, fixer)
+			if err != nil {
+				return nil, err
+			}
+			for _, fix := range fixes {
+				fix.Iteration = iteration
+				summary.Fixed = append(summary.Fixed, fix)
+			}
+			changed = changed || len(fixes) > 0
+		}
+
+		found, err := opt.Verify(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("verification failed: %w", err)
+		}
+		remaining = found
+		if !findings.HasErrors(found) {
+			summary.Green = true
+			break
+		}
+		if !changed {
+			// Another round would run the same fixers on the same files.
+			break
+		}
+	}
+
+	for _, f := range remaining {
+		summary.Remaining = append(summary.Remaining, Problem{
+			Path:     f.Path,
+			Line:     f.Line,
+			Column:   f.Column,
+			Rule:     f.Rule,
+			Severity: string(f.Severity),
+			Message:  f.Message,
+			Hint:     Hint(f.Rule),
+		})
+	}
+	return summary, nil
+}
+
+// runFixer runs fixer and returns the files it changed under root.
+func runFixer(ctx context.Context, root string, fixer Fixer) ([]Fix, error) {
+	before, err := worktree.Take(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := fixer.Run(ctx); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", fixer.Name, err)
+	}
+	after, err := worktree.Take(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixes []Fix
+	for _, change := range worktree.Changes(before, after) {
+		fixes = append(fixes, Fix{Fixer: fixer.Name, Path: change.Path, Kind: change.Kind})
+	}
+	return fixes, nil
+}
+
+// hints maps rules to advice for resolving their findings, which no fixer handles.
+var hints = map[string]string{
+	"unused":        "Remove the unused parameter, method or field, or use it.",
+	"testcontext":   "Use t.Context() instead of context.Background() or context.TODO() in tests; ap lint testcontext -fix ./... rewrites most of them.",
+	"cobracmd":      "Use RunE, pass cmd.Context() down, and mark required flags with MarkFlagRequired.",
+	"dupcode":       "Extract the duplicated code into a shared function, or raise the threshold in .ap/go.yaml.",
+	"configkeys":    "Fix the key name; see the configuration reference in the ap README.",
+	"shellstrict":   "Add set -o errexit, set -o nounset and set -o pipefail after the shebang.",
+	"shellquote":    "Quote the expansion (\"$VAR\"), or assign it to an array if it should be split.",
+	"majorversions": "Require the same major version of the library in every go.mod, and update the imports to match.",
+}
+
+// Hint returns advice for resolving a finding of rule.
+func Hint(rule string) string {
+	if hint, ok := hints[rule]; ok {
+		return hint
+	}
+	if rule == "" {
+		return "Fix by hand, then run ap fixloop again."
+	}
+	return fmt.Sprintf("No fixer handles %s findings; fix by hand, then run ap fixloop again.", rule)
+}
+
+// Print writes a human-readable summary to w.
+func (s *Summary) Print(w io.Writer) {
+	if len(s.Fixed) == 0 {
+		fmt.Fprintln(w, "Auto-fixed: nothing")
+	} else {
+		fmt.Fprintf(w, "Auto-fixed %d file(s):\n", len(s.Fixed))
+		for _, fix := range s.Fixed {
+			fmt.Fprintf(w, "  %s %s (%s)\n", fix.Kind, fix.Path, fix.Fixer)
+		}
+	}
+
+	if len(s.Remaining) == 0 {
+		fmt.Fprintf(w, "All checks pass after %d iteration(s).\n", s.Iterations)
+		return
+	}
+	fmt.Fprintf(w, "Needs attention (%d):\n", len(s.Remaining))
+	for _, p := range s.Remaining {
+		f := findings.Finding{Path: p.Path, Line: p.Line, Column: p.Column, Rule: p.Rule, Message: p.Message, Severity: findings.Severity(p.Severity)}
+		fmt.Fprintf(w, "  %s\n", f)
+		fmt.Fprintf(w, "    <hint_for_agent>%s</hint_for_agent>\n", p.Hint)
+	}
+	if s.Green {
+		fmt.Fprintf(w, "No errors remain after %d iteration(s); the findings above are warnings.\n", s.Iterations)
+	} else {
+		fmt.Fprintf(w, "Errors remain after %d iteration(s).\n", s.Iterations)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixloop
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestRunFixesUntilGreen(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("unformatted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := Options{
+		RepoRoot: root,
+		Fixers: []Fixer{
+			{Name: "format", Run: func(context.Context) error {
+				return os.WriteFile(path, []byte("formatted"), 0644)
+			}},
+		},
+		Verify: func(context.Context) ([]findings.Finding, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if string(data) != "formatted" {
+				return []findings.Finding{{Path: "main.go", Rule: "gofmt", Message: "not formatted", Severity: findings.SeverityError}}, nil
+			}
+			return []findings.Finding{{Path: "main.go", Line: 3, Rule: "testcontext", Message: "use t.Context()", Severity: findings.SeverityWarning}}, nil
+		},
+	}
+	summary, err := Run(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := &Summary{
+		Iterations: 1,
+		Green:      true,
+		Fixed:      []Fix{{Iteration: 1, Fixer: "format", Path: "main.go", Kind: "modified"}},
+		Remaining: []Problem{{
+			Path: "main.go", Line: 3, Rule: "testcontext", Severity: "warning", Message: "use t.Context()",
+			Hint: hints["testcontext"],
+		}},
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("Run() = %+v, want %+v", summary, want)
+	}
+}
+
+func TestRunStopsWhenFixersChangeNothing(t *testing.T) {
+	verifications := 0
+	opt := Options{
+		RepoRoot:      t.TempDir(),
+		MaxIterations: 5,
+		Fixers:        []Fixer{{Name: "generate", Run: func(context.Context) error { return nil }}},
+		Verify: func(context.Context) ([]findings.Finding, error) {
+			verifications++
+			return []findings.Finding{{Path: "a.go", Line: 1, Rule: "unused", Message: "unused parameter x", Severity: findings.SeverityError}}, nil
+		},
+	}
+	summary, err := Run(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.Green || summary.Iterations != 1 || verifications != 1 {
+		t.Errorf("Run() = %+v after %d verifications, want to stop red after one iteration", summary, verifications)
+	}
+
+	var out bytes.Buffer
+	summary.Print(&out)
+	for _, want := range []string{
+		"Auto-fixed: nothing",
+		"a.go:1: unused parameter x [unused]",
+		"<hint_for_agent>" + hints["unused"] + "</hint_for_agent>",
+		"Errors remain after 1 iteration(s).",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Print() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunBoundedByMaxIterations(t *testing.T) {
+	root := t.TempDir()
+	n := 0
+	opt := Options{
+		RepoRoot:      root,
+		MaxIterations: 2,
+		Fixers: []Fixer{{Name: "generate", Run: func(context.Context) error {
+			// Each run adds a file, so the fixer never converges.
+			n++
+			return os.WriteFile(filepath.Join(root, strings.Repeat("x", n)), nil, 0644)
+		}}},
+		Verify: func(context.Context) ([]findings.Finding, error) {
+			return []findings.Finding{{Path: "a.go", Message: "broken", Severity: findings.SeverityError}}, nil
+		},
+	}
+	summary, err := Run(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.Iterations != 2 || len(summary.Fixed) != 2 || summary.Fixed[1].Kind != "added" {
+		t.Errorf("Run() = %+v, want two iterations that each added a file", summary)
+	}
+}
+
+func TestHint(t *testing.T) {
+	if got := Hint("printf"); !strings.Contains(got, "No fixer handles printf findings") {
+		t.Errorf("Hint(printf) = %q", got)
+	}
+}
@@ -16,24 +16,45 @@ package format
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/languages"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/fileheaders"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/gostyle"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/textstyle"
 	"k8s.io/klog/v2"
 )
 
+// Run formats all the files under root.
 func Run(ctx context.Context, root string) error {
-	// 1. Run codestyle (headers, gofmt, etc)
-	if err := runCodestyle(ctx, root); err != nil {
+	return RunFiles(ctx, root, nil)
+}
+
+// RunFiles formats files, which are relative to root or absolute; if files is empty, all the files under root
+// are formatted. The legacy format scripts cannot be limited to some files, so they always format the whole tree.
+func RunFiles(ctx context.Context, root string, files []string) error {
+	// 1. Run codestyle (headers, gofmt, YAML and Markdown)
+	if err := runCodestyle(ctx, root, files); err != nil {
+		return err
+	}
+
+	// 2. Run the formatters of the other languages enabled in .ap/languages.yaml (e.g. ruff, prettier)
+	languageTasks, err := languages.FormatTasks(root, files, false)
+	if err != nil {
+		return err
+	}
+	if err := tasks.Run(ctx, root, languageTasks); err != nil {
 		return err
 	}
 
-	// 2. Run legacy format scripts
+	// 3. Run legacy format scripts
 	if err := runLegacyScripts(ctx, root); err != nil {
 		return err
 	}
@@ -41,14 +62,51 @@ func Run(ctx context.Context, root string) error {
 	return nil
 }
 
-func runCodestyle(ctx context.Context, root string) error {
+// CheckFiles reports the changes formatting files would make, as RunFiles does, without making them.
+// Only file headers and the files of the other languages enabled in .ap/languages.yaml are checked so far.
+// The changes are written to w (as unified diffs, for file headers), and an error is returned if there are any.
+func CheckFiles(ctx context.Context, root string, files []string, w io.Writer) error {
+	problems, err := fileheaders.Check(ctx, root, files)
+	if err != nil {
+		return fmt.Errorf("fileheaders failed: %w", err)
+	}
+	for _, p := range problems {
+		fmt.Fprint(w, p.Diff)
+	}
+
+	var errs []error
+	if len(problems) > 0 {
+		var paths []string
+		for _, p := range problems {
+			paths = append(paths, "  "+p.String())
+		}
+		errs = append(errs, fmt.Errorf("%d files need formatting; run ap format to fix them:\n%s", len(problems), strings.Join(paths, "\n")))
+	}
+
+	languageTasks, err := languages.FormatTasks(root, files, true)
+	if err != nil {
+		return err
+	}
+	languages.SetOutput(languageTasks, w)
+	for _, task := range languageTasks {
+		if err := task.Run(ctx, root); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runCodestyle(ctx context.Context, root string, files []string) error {
 	klog.Info("Running codestyle...")
-	if err := fileheaders.Run(ctx, root, nil); err != nil {
+	if err := fileheaders.Run(ctx, root, files); err != nil {
 		return fmt.Errorf("fileheaders failed: %w", err)
 	}
-	if err := gostyle.Run(ctx, root, nil); err != nil {
+	if err := gostyle.Run(ctx, root, files); err != nil {
 		return fmt.Errorf("gostyle failed: %w", err)
 	}
+	if err := textstyle.Run(ctx, root, files); err != nil {
+		return fmt.Errorf("textstyle failed: %w", err)
+	}
 
 	return nil
 }
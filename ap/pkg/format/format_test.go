@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/goldentest"
+)
+
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		".ap/headers.yaml": "license: apache-2.0\ncopyrightHolder: Google LLC\nskip:\n- \"**/*.yaml\"\n",
+		".ap/go.yaml":      "gofmt:\n  enabled: true\n",
+		".ap/format.yaml":  "yaml:\n  enabled: true\nmarkdown:\n  enabled: true\n",
+		"main.go":          "package main\nfunc main() {\nprintln(\"hello\")\n}\n",
+		"hack/run.sh":      "#!/bin/bash\necho hello\n",
+		"k8s/app.yaml":     "apiVersion: v1\nkind: ConfigMap\ndata:\n    key: value\n",
+		"README.md":        "#  Example\n| Key | Value |\n|-|-|\n| key | value |\n",
+	}
+	for relPath, content := range files {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Run(context.Background(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// The header includes the current year, so normalize it to keep the golden files stable.
+	year := strconv.Itoa(time.Now().Year())
+	goldentest.CompareDir(t, filepath.Join("testdata", "basic"), root,
+		goldentest.WithReplacement("Copyright "+year+" ", "Copyright YEAR "))
+}
+
+func TestRunFiles(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+
+	unformatted := "package main\nfunc main() {\nprintln(\"hello\")\n}\n"
+	files := map[string]string{
+		".ap/go.yaml": "gofmt:\n  enabled: true\n",
+		"changed.go":  unformatted,
+		"other.go":    unformatted,
+	}
+	for relPath, content := range files {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RunFiles(t.Context(), root, []string{"changed.go"}); err != nil {
+		t.Fatalf("RunFiles failed: %v", err)
+	}
+
+	for name, formatted := range map[string]bool{"changed.go": true, "other.go": false} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data) != unformatted; got != formatted {
+			t.Errorf("%s formatted = %v, want %v", name, got, formatted)
+		}
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".ap/headers.yaml": "license: apache-2.0\ncopyrightHolder: Google LLC\nskip:\n- \"**/*.yaml\"\n",
+		"main.go":          "package main\n",
+	}
+	for relPath, content := range files {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out strings.Builder
+	err := CheckFiles(t.Context(), root, nil, &out)
+	if err == nil || !strings.Contains(err.Error(), "main.go: missing header") {
+		t.Errorf("CheckFiles() error = %v, want main.go to be reported", err)
+	}
+	if !strings.Contains(out.String(), "+++ b/main.go\n") {
+		t.Errorf("CheckFiles() output =\n%s\nwant a diff of main.go", out.String())
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "main.go")); err != nil || string(data) != files["main.go"] {
+		t.Errorf("CheckFiles() changed main.go")
+	}
+
+	if err := Run(t.Context(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := CheckFiles(t.Context(), root, nil, &out); err != nil {
+		t.Errorf("CheckFiles() after Run error = %v", err)
+	}
+}
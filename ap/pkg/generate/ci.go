@@ -0,0 +1,555 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/budget"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// GoVersionFromGoMod is the Go version in .ap/ci.yaml that uses the version in the module's go.mod.
+	GoVersionFromGoMod = "go.mod"
+	// GoVersionTip is the Go version in .ap/ci.yaml that builds Go at tip with gotip.
+	GoVersionTip = "tip"
+
+	defaultRunner = "ubuntu-latest"
+
+	// The actions used by the generated GitHub Actions workflow.
+	actionCheckout       = "actions/checkout"
+	actionSetupGo        = "actions/setup-go"
+	actionCache          = "actions/cache"
+	actionUploadArtifact = "actions/upload-artifact"
+
+	// The CI backends that ap generate can write configuration for.
+	BackendGitHubActions = "github-actions"
+	BackendProw          = "prow"
+	BackendCloudBuild    = "cloudbuild"
+)
+
+// defaultActions are the versions of the actions used by the generated GitHub Actions workflow,
+// unless overridden in .ap/ci.yaml.
+var defaultActions = map[string]string{
+	actionCheckout:       "v4",
+	actionSetupGo:        "v5",
+	actionCache:          "v4",
+	actionUploadArtifact: "v4",
+}
+
+// DefaultActions returns the versions of the actions used by the generated GitHub Actions workflow,
+// keyed by action, unless overridden by the actions of .ap/ci.yaml.
+func DefaultActions() map[string]string {
+	return maps.Clone(defaultActions)
+}
+
+// yamlLicenseHeader starts the generated YAML files.
+const yamlLicenseHeader = `# Copyright 2026 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+`
+
+// ciBackend generates the configuration of a CI system, running the jobs found in the ap roots.
+type ciBackend struct {
+	name     string
+	generate func(repoRoot string, cfg *CIConfig, jobs *ciJobs) error
+}
+
+var ciBackends = []ciBackend{
+	{BackendGitHubActions, runGithubActionsGenerator},
+	{BackendProw, runProwGenerator},
+	{BackendCloudBuild, runCloudBuildGenerator},
+}
+
+// ciGenerators returns a generator for each CI backend configured in .ap/ci.yaml.
+func ciGenerators(repoRoot string, apRoots []string) ([]generator, error) {
+	cfg, err := LoadCIConfig(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var generators []generator
+	for _, backend := range ciBackends {
+		if !slices.Contains(cfg.backends(), backend.name) {
+			continue
+		}
+		generators = append(generators, generator{backend.name, func(context.Context) error {
+			jobs, err := findCIJobs(repoRoot, apRoots)
+			if err != nil {
+				return err
+			}
+			return backend.generate(repoRoot, cfg, jobs)
+		}})
+	}
+	return generators, nil
+}
+
+// ciJob is a script found in an ap root, run by a CI job.
+type ciJob struct {
+	// Name is the script name, suffixed with the path of the ap root unless it is the repository root.
+	Name string
+	// Script is the path of the script, relative to the repository root.
+	Script string
+	// APRoot is the path of the ap root, relative to the repository root.
+	APRoot string
+	// GoMod is the path of the ap root's go.mod, relative to the repository root, or "" if it has none.
+	GoMod string
+}
+
+// ciJobs are the scripts run by the generated CI configuration.
+type ciJobs struct {
+	// Presubmits are the scripts in dev/ci/presubmits, run on pull requests.
+	Presubmits []ciJob
+	// Postsubmits are the scripts in dev/ci/postsubmits, run on pushes to main.
+	Postsubmits []ciJob
+	// Releases are the release-* tasks in dev/tasks, run when a release is tagged.
+	Releases []ciJob
+	// Periodics are the scripts in dev/ci/periodics, run daily by the GitHub Actions backend.
+	Periodics []ciJob
+}
+
+// findCIJobs returns the scripts run in CI from each ap root.
+// They are discovered when the CI configuration is generated, after the presubmit generators have run.
+func findCIJobs(repoRoot string, apRoots []string) (*ciJobs, error) {
+	var jobs ciJobs
+	var err error
+	if jobs.Presubmits, err = findScripts(repoRoot, apRoots, filepath.Join("dev", "ci", "presubmits"), ""); err != nil {
+		return nil, err
+	}
+	if jobs.Postsubmits, err = findScripts(repoRoot, apRoots, filepath.Join("dev", "ci", "postsubmits"), ""); err != nil {
+		return nil, err
+	}
+	if jobs.Releases, err = findScripts(repoRoot, apRoots, filepath.Join("dev", "tasks"), "release-"); err != nil {
+		return nil, err
+	}
+	if jobs.Periodics, err = findScripts(repoRoot, apRoots, filepath.Join("dev", "ci", "periodics"), ""); err != nil {
+		return nil, err
+	}
+	return &jobs, nil
+}
+
+// findScripts returns the scripts starting with prefix in the directory relDir of each ap root.
+func findScripts(repoRoot string, apRoots []string, relDir string, prefix string) ([]ciJob, error) {
+	var jobs []ciJob
+	for _, apRoot := range apRoots {
+		suffix := getSuffix(repoRoot, apRoot)
+		dir := filepath.Join(apRoot, relDir)
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+
+		relAPRoot, err := filepath.Rel(repoRoot, apRoot)
+		if err != nil {
+			return nil, err
+		}
+		goMod := ""
+		if _, err := os.Stat(filepath.Join(apRoot, "go.mod")); err == nil {
+			goMod = filepath.Join(relAPRoot, "go.mod")
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+				continue
+			}
+			scriptName := entry.Name()
+
+			jobName := scriptName
+			if suffix != "" && !strings.HasSuffix(jobName, suffix) {
+				jobName = jobName + suffix
+			}
+			jobs = append(jobs, ciJob{
+				Name:   jobName,
+				Script: filepath.Join(relAPRoot, relDir, scriptName),
+				APRoot: relAPRoot,
+				GoMod:  goMod,
+			})
+		}
+	}
+	return jobs, nil
+}
+
+// CIConfig is the contents of .ap/ci.yaml, which configures the generated GitHub Actions workflow.
+type CIConfig struct {
+	// Backends are the CI systems to generate configuration for (defaults to github-actions).
+	Backends []string `json:"backends,omitempty"`
+
+	// Image is the container image that Prow and Cloud Build jobs run in
+	// (defaults to the golang image of the ap root's Go version).
+	Image string `json:"image,omitempty"`
+
+	// Matrix and the other GitHub Actions settings below are ignored by the other backends.
+	Matrix *CIMatrix `json:"matrix,omitempty"`
+
+	// Cache restores and saves the Go build and module caches with actions/cache (defaults to true).
+	Cache *bool `json:"cache,omitempty"`
+
+	// Setup are steps shared by every job, run after Go is set up and before the presubmit script.
+	Setup []CIStep `json:"setup,omitempty"`
+
+	// Budgets are the time budgets of ap commands, checked by ap itself whenever they run.
+	Budgets *budget.Config `json:"budgets,omitempty"`
+
+	// Actions overrides the versions of the actions used by the generated GitHub Actions workflow,
+	// keyed by action, e.g. actions/checkout: v5. A version can pin a commit, with the tag in a comment
+	// as written by ap versionbump --pin-actions, e.g. "08c6903cd8c0fde910a37f88322edcfb5dd907a8 # v5.0.0".
+	Actions map[string]string `json:"actions,omitempty"`
+}
+
+// CIMatrix lists the configurations every presubmit job runs in.
+type CIMatrix struct {
+	// Go are the Go versions: GoVersionFromGoMod (the default), GoVersionTip,
+	// or any version accepted by actions/setup-go, e.g. "stable" or "1.25".
+	Go []string `json:"go,omitempty"`
+
+	// OS are the GitHub Actions runners (defaults to ubuntu-latest).
+	OS []string `json:"os,omitempty"`
+}
+
+// CIStep is a GitHub Actions step, which either uses an action or runs a script.
+type CIStep struct {
+	Name string            `json:"name,omitempty"`
+	Uses string            `json:"uses,omitempty"`
+	With map[string]string `json:"with,omitempty"`
+	Run  string            `json:"run,omitempty"`
+}
+
+// LoadCIConfig loads .ap/ci.yaml from repoRoot, returning an empty config if it does not exist.
+func LoadCIConfig(repoRoot string) (*CIConfig, error) {
+	configFile := filepath.Join(repoRoot, ".ap", "ci.yaml")
+
+	var config CIConfig
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("error in %s: %w", configFile, err)
+	}
+	return &config, nil
+}
+
+func (c *CIConfig) validate() error {
+	if err := c.Budgets.Validate(); err != nil {
+		return err
+	}
+	for _, backend := range c.Backends {
+		if !slices.ContainsFunc(ciBackends, func(b ciBackend) bool { return b.name == backend }) {
+			return fmt.Errorf("unknown backend %q (supported: %s, %s, %s)", backend, BackendGitHubActions, BackendProw, BackendCloudBuild)
+		}
+	}
+	for action, version := range c.Actions {
+		if _, ok := defaultActions[action]; !ok {
+			return fmt.Errorf("actions sets the version of %s, which is not used by the generated workflow", action)
+		}
+		if strings.TrimSpace(version) == "" {
+			return fmt.Errorf("actions sets an empty version for %s", action)
+		}
+	}
+	for i, step := range c.Setup {
+		if (step.Uses == "") == (step.Run == "") {
+			return fmt.Errorf("setup step %d must set exactly one of uses and run", i+1)
+		}
+		if step.Run != "" && len(step.With) > 0 {
+			return fmt.Errorf("setup step %d sets with, which requires uses", i+1)
+		}
+	}
+	return nil
+}
+
+// backends returns the CI backends to generate configuration for.
+func (c *CIConfig) backends() []string {
+	if len(c.Backends) > 0 {
+		return c.Backends
+	}
+	return []string{BackendGitHubActions}
+}
+
+// image returns the container image to run the job in.
+func (c *CIConfig) image(repoRoot string, job ciJob) (string, error) {
+	if c.Image != "" {
+		return c.Image, nil
+	}
+	if job.GoMod == "" {
+		return "golang", nil
+	}
+	version, err := goVersion(filepath.Join(repoRoot, job.GoMod))
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		return "golang", nil
+	}
+	return "golang:" + version, nil
+}
+
+// goVersion returns the Go version required by the go.mod at path: its toolchain, or else its go directive.
+func goVersion(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var version string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "toolchain":
+			return strings.TrimPrefix(fields[1], "go"), nil
+		case "go":
+			version = fields[1]
+		}
+	}
+	return version, nil
+}
+
+// goVersions returns the Go versions to test with.
+func (c *CIConfig) goVersions() []string {
+	if c.Matrix != nil && len(c.Matrix.Go) > 0 {
+		return c.Matrix.Go
+	}
+	return []string{GoVersionFromGoMod}
+}
+
+// runners returns the runners to test on.
+func (c *CIConfig) runners() []string {
+	if c.Matrix != nil && len(c.Matrix.OS) > 0 {
+		return c.Matrix.OS
+	}
+	return []string{defaultRunner}
+}
+
+// uses returns the reference to action in a uses entry of the generated workflow, e.g. actions/checkout@v4.
+func (c *CIConfig) uses(action string) string {
+	if version, ok := c.Actions[action]; ok {
+		return action + "@" + strings.TrimSpace(version)
+	}
+	return action + "@" + defaultActions[action]
+}
+
+// isCacheEnabled returns true if jobs should cache the Go build and module caches.
+func (c *CIConfig) isCacheEnabled() bool {
+	if c.Cache != nil {
+		return *c.Cache
+	}
+	return true
+}
+
+// matrixDimensions returns whether the jobs have an os and a go matrix dimension.
+func (c *CIConfig) matrixDimensions(usesGo bool) (os bool, goVersion bool) {
+	return len(c.runners()) > 1, usesGo && len(c.goVersions()) > 1
+}
+
+// writeJobHeader writes the start of a job, setting env for all its steps, up to and including the checkout step.
+// Only the dimensions with more than one value become part of the matrix.
+func (c *CIConfig) writeJobHeader(sb *strings.Builder, jobName string, usesGo bool, env map[string]string) {
+	osMatrix, goMatrix := c.matrixDimensions(usesGo)
+
+	fmt.Fprintf(sb, "  %s:\n", jobName)
+	if osMatrix {
+		sb.WriteString("    runs-on: ${{ matrix.os }}\n")
+	} else {
+		fmt.Fprintf(sb, "    runs-on: %s\n", c.runners()[0])
+	}
+	if len(env) > 0 {
+		sb.WriteString("    env:\n")
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			fmt.Fprintf(sb, "      %s: %s\n", name, env[name])
+		}
+	}
+	if osMatrix || goMatrix {
+		sb.WriteString("    strategy:\n      fail-fast: false\n      matrix:\n")
+		if osMatrix {
+			fmt.Fprintf(sb, "        os: %s\n", flowList(c.runners()))
+		}
+		if goMatrix {
+			fmt.Fprintf(sb, "        go: %s\n", flowList(c.goVersions()))
+		}
+	}
+	fmt.Fprintf(sb, `    steps:
+      - name: Checkout code
+        uses: %s
+`, c.uses(actionCheckout))
+}
+
+// writeGoSetup writes the steps installing the configured Go versions.
+// With several versions, each step only runs for the matrix entries it applies to.
+func (c *CIConfig) writeGoSetup(sb *strings.Builder, relGoMod string) {
+	versions := c.goVersions()
+	matrix := len(versions) > 1
+	condition := func(expr string) string {
+		if !matrix {
+			return ""
+		}
+		return "        if: " + expr + "\n"
+	}
+
+	// actions/setup-go has its own cache, keyed only on go.sum; it is replaced by writeCacheStep.
+	setupGoCache := ""
+	if c.isCacheEnabled() {
+		setupGoCache = "\n          cache: false"
+	}
+
+	fromGoMod := slices.Contains(versions, GoVersionFromGoMod)
+	tip := slices.Contains(versions, GoVersionTip)
+	if fromGoMod {
+		fmt.Fprintf(sb, `
+      - name: Setup Go
+%s        uses: %s
+        with:
+          go-version-file: '%s'%s
+`, condition("matrix.go == '"+GoVersionFromGoMod+"'"), c.uses(actionSetupGo), relGoMod, setupGoCache)
+	}
+
+	if !fromGoMod || matrix {
+		// gotip is built with the latest stable release.
+		goVersion := "${{ matrix.go }}"
+		switch {
+		case !matrix && tip:
+			goVersion = "'stable'"
+		case !matrix:
+			goVersion = quote(versions[0])
+		case tip:
+			goVersion = "${{ matrix.go == '" + GoVersionTip + "' && 'stable' || matrix.go }}"
+		}
+		ifOther := ""
+		if fromGoMod {
+			ifOther = condition("matrix.go != '" + GoVersionFromGoMod + "'")
+		}
+		fmt.Fprintf(sb, `
+      - name: Setup Go
+%s        uses: %s
+        with:
+          go-version: %s%s
+`, ifOther, c.uses(actionSetupGo), goVersion, setupGoCache)
+	}
+
+	if tip {
+		fmt.Fprintf(sb, `
+      - name: Setup Go tip
+%s        run: |
+          go install golang.org/dl/gotip@latest
+          gotip download
+          echo "$(gotip env GOROOT)/bin" >> "$GITHUB_PATH"
+`, condition("matrix.go == '"+GoVersionTip+"'"))
+	}
+}
+
+// writeCacheStep writes the step restoring and saving the Go build and module caches, keyed on go.sum.
+// Jobs build different packages, so each job (and Go version) has its own cache, falling back to the others.
+func (c *CIConfig) writeCacheStep(sb *strings.Builder, jobName string, relGoSum string) {
+	if !c.isCacheEnabled() {
+		return
+	}
+	_, goMatrix := c.matrixDimensions(true)
+	prefix := "${{ runner.os }}-go-"
+	if goMatrix {
+		prefix += "${{ matrix.go }}-"
+	}
+
+	fmt.Fprintf(sb, `
+      - name: Cache Go build and modules
+        uses: %s
+        with:
+          path: |
+            ~/.cache/go-build
+            ~/Library/Caches/go-build
+            ~/go/pkg/mod
+          key: %s%s-${{ hashFiles('%s') }}
+          restore-keys: |
+            %s%s-
+            %s
+`, c.uses(actionCache), prefix, jobName, relGoSum, prefix, jobName, prefix)
+}
+
+// writeUploadTestResultsStep writes the step uploading the test results of a failed job as an artifact.
+func (c *CIConfig) writeUploadTestResultsStep(sb *strings.Builder, jobName string, relResultsDir string, usesGo bool) {
+	// Artifact names must be unique within a workflow run.
+	name := "test-results-" + jobName
+	osMatrix, goMatrix := c.matrixDimensions(usesGo)
+	if osMatrix {
+		name += "-${{ matrix.os }}"
+	}
+	if goMatrix {
+		name += "-${{ matrix.go }}"
+	}
+
+	fmt.Fprintf(sb, `
+      - name: Upload test results
+        if: failure()
+        uses: %s
+        with:
+          name: %s
+          path: %s
+          if-no-files-found: ignore
+`, c.uses(actionUploadArtifact), name, relResultsDir)
+}
+
+// writeSetupSteps writes the shared setup steps from .ap/ci.yaml.
+func (c *CIConfig) writeSetupSteps(sb *strings.Builder) error {
+	for _, step := range c.Setup {
+		data, err := yaml.Marshal([]CIStep{step})
+		if err != nil {
+			return fmt.Errorf("failed to marshal setup step: %w", err)
+		}
+		sb.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			sb.WriteString("      " + line + "\n")
+		}
+	}
+	return nil
+}
+
+// flowList formats values as a YAML flow sequence of quoted strings.
+func flowList(values []string) string {
+	var quoted []string
+	for _, v := range values {
+		quoted = append(quoted, quote(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// quote returns s as a single-quoted YAML string, so versions like 1.20 are not read as numbers.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// runCloudBuildGenerator writes a Cloud Build configuration (cloudbuild.yaml) running the presubmit jobs
+// as parallel steps.
+func runCloudBuildGenerator(repoRoot string, ciConfig *CIConfig, jobs *ciJobs) error {
+	outputFile := filepath.Join(repoRoot, "cloudbuild.yaml")
+
+	klog.Infof("Generating %s", outputFile)
+
+	var sb strings.Builder
+	sb.WriteString(yamlLicenseHeader + `
+steps:
+`)
+	for _, job := range jobs.Presubmits {
+		image, err := ciConfig.image(repoRoot, job)
+		if err != nil {
+			return err
+		}
+		// waitFor '-' starts the step right away, rather than after the previous one.
+		fmt.Fprintf(&sb, `  - id: %s
+    name: %s
+    entrypoint: ./%s
+    waitFor: ['-']
+`, job.Name, quote(image), job.Script)
+	}
+	// The default of 10 minutes is too short to run all presubmits.
+	sb.WriteString(`
+timeout: 3600s
+`)
+
+	if err := writeFileIfChanged(outputFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	return nil
+}
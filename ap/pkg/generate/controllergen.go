@@ -0,0 +1,271 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultControllerGenVersion is the version of controller-gen used when .ap/generate.yaml does not pin one.
+const DefaultControllerGenVersion = "v0.18.0"
+
+// Config is the contents of .ap/generate.yaml.
+type Config struct {
+	ControllerGen *ControllerGenConfig `json:"controllerGen,omitempty"`
+
+	// ReferenceDocs configures the reference documentation of ap, generated from its commands and config types.
+	// It is only read from the repository root, and is meant for the repository of ap itself.
+	ReferenceDocs *ReferenceDocsConfig `json:"referenceDocs,omitempty"`
+}
+
+// ReferenceDocsConfig configures the generation of the reference documentation of ap.
+type ReferenceDocsConfig struct {
+	// Enabled turns the generator on (defaults to false).
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Output is the directory of the documentation, relative to the repository root (defaults to docs/reference).
+	Output string `json:"output,omitempty"`
+}
+
+// ControllerGenConfig configures the built-in controller-gen generator.
+type ControllerGenConfig struct {
+	// Enabled turns the generator on or off (defaults to true when markers are found).
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Version is the controller-gen version to run (defaults to DefaultControllerGenVersion).
+	Version string `json:"version,omitempty"`
+
+	// HeaderFile is the boilerplate header for the generated DeepCopy code, relative to the ap root.
+	HeaderFile string `json:"headerFile,omitempty"`
+
+	// CRDOutput is the directory for the generated CRD YAML, relative to the ap root (defaults to config/crd).
+	CRDOutput string `json:"crdOutput,omitempty"`
+}
+
+// LoadConfig loads .ap/generate.yaml from apRoot, returning an empty config if it does not exist.
+func LoadConfig(apRoot string) (*Config, error) {
+	configFile := filepath.Join(apRoot, ".ap", "generate.yaml")
+
+	var config Config
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+	return &config, nil
+}
+
+// controllerGenPackages are the packages of an ap root that need controller-gen.
+type controllerGenPackages struct {
+	// Object are the packages with DeepCopy markers.
+	Object []string
+	// CRD are the packages defining API types with a group name.
+	CRD []string
+}
+
+// runControllerGenGenerator runs controller-gen for the packages under apRoot that have deepcopy-gen
+// or kubebuilder markers, generating DeepCopy methods and CRD YAML.
+// It is skipped if the ap root has its own generate-crds script.
+func runControllerGenGenerator(ctx context.Context, apRoot string) error {
+	cfg, err := LoadConfig(apRoot)
+	if err != nil {
+		return err
+	}
+	genCfg := cfg.ControllerGen
+	if genCfg == nil {
+		genCfg = &ControllerGenConfig{}
+	}
+	if genCfg.Enabled != nil && !*genCfg.Enabled {
+		return nil
+	}
+
+	scripts, err := tasks.FindTaskScripts(apRoot, tasks.WithPrefix("generate-crds"))
+	if err != nil {
+		return fmt.Errorf("failed to discover generate tasks in %s: %w", apRoot, err)
+	}
+	if len(scripts) > 0 {
+		return nil
+	}
+
+	pkgs, err := findControllerGenPackages(apRoot)
+	if err != nil {
+		return err
+	}
+	if len(pkgs.Object) == 0 && len(pkgs.CRD) == 0 {
+		return nil
+	}
+
+	args := controllerGenArgs(genCfg, pkgs)
+	klog.Infof("Running controller-gen in %s", apRoot)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = apRoot
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("controller-gen failed in %s: %w", apRoot, err)
+	}
+	return nil
+}
+
+// controllerGenArgs returns the arguments to go for running controller-gen on pkgs.
+func controllerGenArgs(cfg *ControllerGenConfig, pkgs *controllerGenPackages) []string {
+	version := cfg.Version
+	if version == "" {
+		version = DefaultControllerGenVersion
+	}
+	crdOutput := cfg.CRDOutput
+	if crdOutput == "" {
+		crdOutput = filepath.Join("config", "crd")
+	}
+
+	args := []string{"run", "sigs.k8s.io/controller-tools/cmd/controller-gen@" + version}
+	if len(pkgs.Object) > 0 {
+		object := "object"
+		if cfg.HeaderFile != "" {
+			object += fmt.Sprintf(":headerFile=%q", cfg.HeaderFile)
+		}
+		args = append(args, object)
+		for _, pkg := range pkgs.Object {
+			args = append(args, "paths=./"+filepath.ToSlash(pkg))
+		}
+	}
+	if len(pkgs.CRD) > 0 {
+		args = append(args, "crd")
+		for _, pkg := range pkgs.CRD {
+			args = append(args, "paths=./"+filepath.ToSlash(pkg))
+		}
+		args = append(args, "output:crd:artifacts:config="+filepath.ToSlash(crdOutput))
+	}
+	return args
+}
+
+// findControllerGenPackages returns the directories under apRoot (relative to it) with controller-gen markers.
+func findControllerGenPackages(apRoot string) (*controllerGenPackages, error) {
+	objectDirs := make(map[string]bool)
+	crdDirs := make(map[string]bool)
+
+	err := walkGoFiles(apRoot, func(path string) error {
+		if strings.HasPrefix(filepath.Base(path), "zz_generated") {
+			return nil
+		}
+		object, crd, err := scanMarkers(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(apRoot, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if object {
+			objectDirs[rel] = true
+		}
+		if crd {
+			crdDirs[rel] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s for controller-gen markers: %w", apRoot, err)
+	}
+
+	pkgs := &controllerGenPackages{}
+	for dir := range objectDirs {
+		pkgs.Object = append(pkgs.Object, dir)
+	}
+	for dir := range crdDirs {
+		pkgs.CRD = append(pkgs.CRD, dir)
+	}
+	slices.Sort(pkgs.Object)
+	slices.Sort(pkgs.CRD)
+	return pkgs, nil
+}
+
+// walkGoFiles calls fn for the non-test Go files under apRoot, in path order.
+// Nested ap roots and Go modules are skipped; they are generated on their own.
+func walkGoFiles(apRoot string, fn func(path string) error) error {
+	return filepath.WalkDir(apRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == apRoot {
+				return nil
+			}
+			switch d.Name() {
+			case ".git", ".build", "vendor", "node_modules", "testdata":
+				return filepath.SkipDir
+			}
+			for _, marker := range []string{".ap", "go.mod"} {
+				if _, err := os.Stat(filepath.Join(path, marker)); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// scanMarkers reports whether the Go file has markers for DeepCopy generation and for CRD generation.
+// CRDs need both a +kubebuilder:object:root type and a +groupName, which may be in different files of the package,
+// so any +groupName or +kubebuilder:resource marker is taken to mean the package defines CRDs.
+func scanMarkers(path string) (object bool, crd bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		marker, ok := strings.CutPrefix(line, "// +")
+		if !ok {
+			marker, ok = strings.CutPrefix(line, "//+")
+		}
+		if !ok || strings.HasSuffix(marker, "=false") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(marker, "k8s:deepcopy-gen"), strings.HasPrefix(marker, "kubebuilder:object:"):
+			object = true
+		case strings.HasPrefix(marker, "groupName="), strings.HasPrefix(marker, "kubebuilder:resource"):
+			crd = true
+		}
+	}
+	return object, crd, scanner.Err()
+}
@@ -24,52 +24,75 @@ import (
 	"strings"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/execprofile"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
 )
 
+// Options configures RunWithOptions.
+type Options struct {
+	// AfterStep, if set, is called after each generator has run, with the generator's name
+	// (e.g. "controller-gen"), so that callers can attribute changes to the generator that made them.
+	AfterStep func(ctx context.Context, name string) error
+}
+
 func Run(ctx context.Context, repoRoot string) error {
+	return RunWithOptions(ctx, repoRoot, Options{})
+}
+
+// generator is a named generation step.
+type generator struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// RunWithOptions runs the generators of every ap root under repoRoot, then the built-in generators of the repository.
+func RunWithOptions(ctx context.Context, repoRoot string, opt Options) error {
 	apRoots, err := config.FindAllAPRoots(repoRoot)
 	if err != nil {
 		return err
 	}
 
+	var generators []generator
 	for _, apRoot := range apRoots {
-		klog.Infof("Generating for AP root: %s", apRoot)
-
-		// 1. Run legacy scripts
-		if err := runLegacyScripts(ctx, apRoot); err != nil {
-			return err
-		}
+		generators = append(generators,
+			// 1. Run legacy scripts
+			generator{"scripts", func(ctx context.Context) error {
+				klog.Infof("Generating for AP root: %s", apRoot)
+				return runLegacyScripts(ctx, apRoot)
+			}},
+			generator{"controller-gen", func(ctx context.Context) error { return runControllerGenGenerator(ctx, apRoot) }},
+			generator{"mockgen", func(ctx context.Context) error { return runMockGenerator(ctx, apRoot) }},
+		)
 	}
 
 	// 2. Run built-in generators (only in repoRoot)
-	if err := runGenerateVerifierGenerator(ctx, repoRoot); err != nil {
-		return err
-	}
-
-	if err := runApTestGenerator(ctx, repoRoot); err != nil {
-		return err
-	}
-
-	if err := runApLintGenerator(ctx, repoRoot); err != nil {
-		return err
-	}
-
-	if err := runApBuildGenerator(ctx, repoRoot, apRoots); err != nil {
-		return err
-	}
-
-	if err := runApE2eGenerator(ctx, repoRoot, apRoots); err != nil {
+	generators = append(generators,
+		generator{"ap-verify-generate", func(ctx context.Context) error { return runGenerateVerifierGenerator(ctx, repoRoot) }},
+		generator{"ap-test", func(ctx context.Context) error { return runApTestGenerator(ctx, repoRoot) }},
+		generator{"ap-lint", func(ctx context.Context) error { return runApLintGenerator(ctx, repoRoot) }},
+		generator{"ap-build", func(ctx context.Context) error { return runApBuildGenerator(ctx, repoRoot, apRoots) }},
+		generator{"ap-e2e", func(ctx context.Context) error { return runApE2eGenerator(ctx, repoRoot, apRoots) }},
+		generator{"ap-build-reproducible", func(ctx context.Context) error { return runApBuildReproducibleGenerator(ctx, repoRoot, apRoots) }},
+	)
+	ciGenerators, err := ciGenerators(repoRoot, apRoots)
+	if err != nil {
 		return err
 	}
+	generators = append(generators, ciGenerators...)
 
-	if err := runGithubActionsGenerator(ctx, repoRoot, apRoots); err != nil {
-		return err
+	for _, g := range generators {
+		if err := g.run(ctx); err != nil {
+			return err
+		}
+		if opt.AfterStep != nil {
+			if err := opt.AfterStep(ctx, g.name); err != nil {
+				return err
+			}
+		}
 	}
-
 	return nil
 }
 
@@ -260,7 +283,10 @@ cd "${REPO_ROOT}"
 
 # Run linting
 %s lint
-`, apCmd)
+
+# Check file headers, without modifying the checkout
+%s format --check
+`, apCmd, apCmd)
 	if err := writeFileIfChanged(targetFile, []byte(content), 0755); err != nil {
 		return fmt.Errorf("failed to write %s: %w", targetFile, err)
 	}
@@ -415,14 +441,49 @@ cd "${REPO_ROOT}"
 	return nil
 }
 
-func runGithubActionsGenerator(_ context.Context, repoRoot string, apRoots []string) error {
-	workflowsDir := filepath.Join(repoRoot, ".github", "workflows")
-	outputFile := filepath.Join(workflowsDir, "ci-presubmits.yaml")
+// runApBuildReproducibleGenerator writes the periodic job checking that the images of the ap roots that build them
+// reproducibly (reproducible in .ap/images.yaml) still do, or removes it if there are none.
+func runApBuildReproducibleGenerator(_ context.Context, repoRoot string, apRoots []string) error {
+	reproducible := false
+	for _, apRoot := range apRoots {
+		cfg, err := images.LoadConfig(apRoot)
+		if err != nil {
+			return err
+		}
+		ok, err := images.HasImages(apRoot)
+		if err == nil && ok && cfg.Reproducible {
+			reproducible = true
+			break
+		}
+	}
 
-	klog.Infof("Generating %s", outputFile)
+	periodicsDir := filepath.Join(repoRoot, "dev", "ci", "periodics")
+	targetFile := filepath.Join(periodicsDir, "ap-build-reproducible")
 
-	var sb strings.Builder
-	sb.WriteString(`# Copyright 2026 Google LLC
+	if !reproducible {
+		if _, err := os.Stat(targetFile); err == nil {
+			klog.Infof("Removing %s as no images are built reproducibly", targetFile)
+			if err := os.Remove(targetFile); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", targetFile, err)
+			}
+		}
+		return nil
+	}
+
+	klog.Infof("Generating %s", targetFile)
+
+	if err := os.MkdirAll(periodicsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create periodics dir: %w", err)
+	}
+
+	apCmd, err := GetApCommand(repoRoot, repoRoot)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf(`#!/bin/bash
+
+# Copyright 2026 Google LLC
 #
 # Licensed under the Apache License, Version 2.0 (the "License");
 # you may not use this file except in compliance with the License.
@@ -436,6 +497,38 @@ func runGithubActionsGenerator(_ context.Context, repoRoot string, apRoots []str
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+set -o errexit
+set -o nounset
+set -o pipefail
+
+REPO_ROOT="$(git rev-parse --show-toplevel)"
+cd "${REPO_ROOT}"
+
+# The default docker builder cannot export an image without loading it.
+if command -v docker >/dev/null; then
+  docker buildx create --use --name ap-reproducible >/dev/null 2>&1 || docker buildx use ap-reproducible
+fi
+
+# Build each image twice and compare the digests
+%s build --verify-reproducible
+`, apCmd)
+	if err := writeFileIfChanged(targetFile, []byte(content), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", targetFile, err)
+	}
+
+	return nil
+}
+
+// runGithubActionsGenerator writes the GitHub Actions workflows running the presubmit jobs,
+// and the postsubmit, periodic and release jobs if there are any.
+func runGithubActionsGenerator(repoRoot string, ciConfig *CIConfig, jobs *ciJobs) error {
+	workflowsDir := filepath.Join(repoRoot, ".github", "workflows")
+	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create workflows dir: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(yamlLicenseHeader + `
 name: CI Presubmits
 
 on:
@@ -447,93 +540,200 @@ on:
 
 jobs:
 `)
+	if err := ciConfig.writeJobs(&sb, jobs.Presubmits); err != nil {
+		return err
+	}
+	if err := writeWorkflow(filepath.Join(workflowsDir, "ci-presubmits.yaml"), sb.String()); err != nil {
+		return err
+	}
 
-	for _, apRoot := range apRoots {
-		suffix := getSuffix(repoRoot, apRoot)
-		presubmitsDir := filepath.Join(apRoot, "dev", "ci", "presubmits")
-		entries, err := os.ReadDir(presubmitsDir)
-		if os.IsNotExist(err) {
-			continue
+	postsubmitsFile := filepath.Join(workflowsDir, "ci-postsubmits.yaml")
+	if len(jobs.Postsubmits) == 0 {
+		if err := removeWorkflow(postsubmitsFile, "no postsubmits found"); err != nil {
+			return err
 		}
-		if err != nil {
-			return fmt.Errorf("failed to read presubmits dir %s: %w", presubmitsDir, err)
+	} else {
+		sb.Reset()
+		sb.WriteString(yamlLicenseHeader + `
+name: CI Postsubmits
+
+on:
+  push:
+    branches:
+      - main
+
+jobs:
+`)
+		if err := ciConfig.writeJobs(&sb, jobs.Postsubmits); err != nil {
+			return err
+		}
+		if err := writeWorkflow(postsubmitsFile, sb.String()); err != nil {
+			return err
 		}
+	}
 
-		goModExists := false
-		if _, err := os.Stat(filepath.Join(apRoot, "go.mod")); err == nil {
-			goModExists = true
+	periodicsFile := filepath.Join(workflowsDir, "ci-periodics.yaml")
+	if len(jobs.Periodics) == 0 {
+		if err := removeWorkflow(periodicsFile, "no periodics found"); err != nil {
+			return err
 		}
+	} else {
+		sb.Reset()
+		sb.WriteString(yamlLicenseHeader + `
+name: CI Periodics
 
-		relPresubmitsDir, err := filepath.Rel(repoRoot, presubmitsDir)
-		if err != nil {
+on:
+  schedule:
+    - cron: '0 6 * * *'
+  workflow_dispatch:
+
+jobs:
+`)
+		if err := ciConfig.writeJobs(&sb, jobs.Periodics); err != nil {
 			return err
 		}
+		if err := writeWorkflow(periodicsFile, sb.String()); err != nil {
+			return err
+		}
+	}
 
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-			scriptName := entry.Name()
+	releaseFile := filepath.Join(workflowsDir, "release.yaml")
+	if len(jobs.Releases) == 0 {
+		return removeWorkflow(releaseFile, "no release tasks found")
+	}
+	sb.Reset()
+	sb.WriteString(yamlLicenseHeader + `
+name: Release
 
-			jobName := scriptName
-			if suffix != "" && !strings.HasSuffix(jobName, suffix) {
-				jobName = jobName + suffix
-			}
+on:
+  push:
+    tags:
+      - 'v*'
 
-			sb.WriteString(fmt.Sprintf(`  %s:
-    runs-on: ubuntu-latest
-    steps:
-      - name: Checkout code
-        uses: actions/checkout@v4
-`, jobName))
-
-			if goModExists {
-				relGoMod, _ := filepath.Rel(repoRoot, filepath.Join(apRoot, "go.mod"))
-				sb.WriteString(fmt.Sprintf(`
-      - name: Setup Go
-        uses: actions/setup-go@v5
-        with:
-          go-version-file: '%s'
-`, relGoMod))
-			}
+jobs:
+`)
+	if err := ciConfig.writeReleaseJob(&sb, repoRoot, jobs.Releases); err != nil {
+		return err
+	}
+	return writeWorkflow(releaseFile, sb.String())
+}
 
-			sb.WriteString(fmt.Sprintf(`
-      - name: Run %s
-        run: ./%s/%s
+// writeJobs writes a job for each script, running in the configured matrix.
+func (c *CIConfig) writeJobs(sb *strings.Builder, jobs []ciJob) error {
+	for _, job := range jobs {
+		usesGo := job.GoMod != ""
+		c.writeJobHeader(sb, job.Name, usesGo, nil)
+
+		if usesGo {
+			c.writeGoSetup(sb, job.GoMod)
+			c.writeCacheStep(sb, job.Name, filepath.Join(filepath.Dir(job.GoMod), "go.sum"))
+		}
 
-`, jobName, relPresubmitsDir, scriptName))
+		if err := c.writeSetupSteps(sb); err != nil {
+			return err
 		}
+
+		sb.WriteString(fmt.Sprintf(`
+      - name: Run %s
+        run: ./%s
+`, job.Name, job.Script))
+
+		c.writeUploadTestResultsStep(sb, job.Name, filepath.Join(job.APRoot, ".build", "test-results"), usesGo)
+		sb.WriteString("\n")
 	}
+	return nil
+}
 
-	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create workflows dir: %w", err)
+// writeReleaseJob writes the job that pushes the images with the released tag, then runs the release tasks.
+// A release is built once, with the Go version of the repository root's go.mod, so the matrix does not apply.
+func (c *CIConfig) writeReleaseJob(sb *strings.Builder, repoRoot string, releases []ciJob) error {
+	release := &CIConfig{Cache: c.Cache, Setup: c.Setup}
+
+	_, err := os.Stat(filepath.Join(repoRoot, "go.mod"))
+	usesGo := err == nil
+	release.writeJobHeader(sb, "release", usesGo, map[string]string{"IMAGE_TAG": "${{ github.ref_name }}"})
+	if usesGo {
+		release.writeGoSetup(sb, "go.mod")
+		release.writeCacheStep(sb, "release", "go.sum")
+	}
+	if err := release.writeSetupSteps(sb); err != nil {
+		return err
 	}
 
-	if err := writeFileIfChanged(outputFile, []byte(sb.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	apCmd, err := GetApCommand(repoRoot, repoRoot)
+	if err != nil {
+		return err
 	}
+	fmt.Fprintf(sb, `
+      - name: Build and push images
+        run: %s build --push
+`, apCmd)
 
+	// Task scripts run in their ap root, as they do when run by ap.
+	for _, job := range releases {
+		fmt.Fprintf(sb, "\n      - name: Run %s\n", job.Name)
+		if job.APRoot != "." {
+			fmt.Fprintf(sb, "        working-directory: %s\n", job.APRoot)
+		}
+		fmt.Fprintf(sb, "        run: ./%s\n", filepath.Join("dev", "tasks", filepath.Base(job.Script)))
+	}
 	return nil
 }
 
-func GetApCommand(repoRoot, apRoot string) (string, error) {
-	configPath := filepath.Join(apRoot, ".ap", "ap.yaml")
-	defaultCmd := "go run github.com/gke-labs/gke-labs-infra/ap@latest"
+// writeWorkflow writes a generated workflow file.
+func writeWorkflow(path string, content string) error {
+	klog.Infof("Generating %s", path)
+	if err := writeFileIfChanged(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return defaultCmd, nil
+// removeWorkflow removes a generated workflow file that is no longer needed, if it exists.
+func removeWorkflow(path string, reason string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
 	}
+	klog.Infof("Removing %s as %s", path, reason)
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// APConfig is the contents of .ap/ap.yaml.
+type APConfig struct {
+	// Version is "!self" to make the generated scripts run ap from this repository; otherwise they run the latest ap.
+	Version string `json:"version"`
+	// Profiles configures the execution profiles by name: the built-in local, ci and agent profiles, whose
+	// settings are applied over their defaults, and any others. They are read from the repository root only.
+	Profiles map[string]execprofile.Profile `json:"profiles,omitempty"`
+}
 
+// LoadAPConfig loads the .ap/ap.yaml of root, returning an empty config if it does not exist.
+func LoadAPConfig(root string) (*APConfig, error) {
+	configPath := filepath.Join(root, ".ap", "ap.yaml")
 	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return &APConfig{}, nil
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to read %s: %w", configPath, err)
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
 	}
 
-	var config struct {
-		Version string `json:"version"`
-	}
+	var config APConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return "", fmt.Errorf("failed to parse %s: %w", configPath, err)
+		return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	return &config, nil
+}
+
+func GetApCommand(repoRoot, apRoot string) (string, error) {
+	defaultCmd := "go run github.com/gke-labs/gke-labs-infra/ap@latest"
+
+	config, err := LoadAPConfig(apRoot)
+	if err != nil {
+		return "", err
 	}
 
 	if config.Version == "!self" {
@@ -0,0 +1,466 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/goldentest"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		perm := os.FileMode(0644)
+		if filepath.Base(filepath.Dir(p)) == "tasks" {
+			perm = 0755
+		}
+		if err := os.WriteFile(p, []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "minimal",
+			files: map[string]string{
+				".ap/ap.yaml": "",
+			},
+		},
+		{
+			name: "self",
+			files: map[string]string{
+				".ap/ap.yaml":           "version: \"!self\"\n",
+				"images/foo/Dockerfile": "FROM scratch\n",
+				"go.mod":                "module example.com/foo\n",
+			},
+		},
+		{
+			name: "multiple roots",
+			files: map[string]string{
+				".ap/ap.yaml":                      "",
+				"go.mod":                           "module example.com/foo\n",
+				"sub/.ap/ap.yaml":                  "",
+				"sub/dev/tasks/test-e2e":           "#!/bin/bash\n",
+				"sub/dev/ci/presubmits/sub-verify": "#!/bin/bash\n",
+			},
+		},
+		{
+			name: "ci matrix",
+			files: map[string]string{
+				".ap/ap.yaml": "",
+				".ap/ci.yaml": `matrix:
+  go: [go.mod, stable, tip]
+  os: [ubuntu-latest, macos-latest]
+setup:
+- name: Install protoc
+  uses: arduino/setup-protoc@v3
+  with:
+    version: "25.x"
+- name: Check tools
+  run: |
+    protoc --version
+    go version
+`,
+				"go.mod":                             "module example.com/foo\n",
+				"tools/.ap/ap.yaml":                  "",
+				"tools/dev/ci/presubmits/tools-lint": "#!/bin/bash\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			if err := Run(context.Background(), root); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			goldenDir := filepath.Join("testdata", filepath.Base(t.Name()))
+			goldentest.CompareDir(t, filepath.Join(goldenDir, "presubmits"), filepath.Join(root, "dev", "ci", "presubmits"))
+			workflow, err := os.ReadFile(filepath.Join(root, ".github", "workflows", "ci-presubmits.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			goldentest.CompareFile(t, filepath.Join(goldenDir, "ci-presubmits.yaml"), workflow)
+		})
+	}
+}
+
+func TestGetApCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		apRoot  string
+		version string
+		want    string
+	}{
+		{
+			name: "no config",
+			want: "go run github.com/gke-labs/gke-labs-infra/ap@latest",
+		},
+		{
+			name:    "self at repo root",
+			version: "!self",
+			want:    "go run ./ap",
+		},
+		{
+			name:    "self in subdirectory",
+			apRoot:  "sub",
+			version: "!self",
+			want:    "go run ../ap",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			apRoot := filepath.Join(repoRoot, tt.apRoot)
+			if tt.version != "" {
+				writeFiles(t, apRoot, map[string]string{
+					".ap/ap.yaml": "version: \"" + tt.version + "\"\n",
+				})
+			}
+
+			got, err := GetApCommand(repoRoot, apRoot)
+			if err != nil {
+				t.Fatalf("GetApCommand failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetApCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControllerGen(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                          "module example.com/foo\n",
+		"api/v1/groupversion_info.go":     "// +kubebuilder:object:generate=true\n// +groupName=foo.example.com\npackage v1\n",
+		"api/v1/types.go":                 "package v1\n\n// +kubebuilder:object:root=true\ntype Foo struct{}\n",
+		"api/v1/zz_generated.deepcopy.go": "package v1\n\n// +kubebuilder:object:root=true\n",
+		"pkg/internal/types.go":           "package internal\n\n// +k8s:deepcopy-gen=true\ntype Bar struct{}\n",
+		"pkg/other/types.go":              "package other\n\n// +k8s:deepcopy-gen=false\ntype Baz struct{}\n",
+		"sub/go.mod":                      "module example.com/sub\n",
+		"sub/api/types.go":                "package api\n\n// +kubebuilder:object:root=true\n",
+	})
+
+	pkgs, err := findControllerGenPackages(root)
+	if err != nil {
+		t.Fatalf("findControllerGenPackages failed: %v", err)
+	}
+	if got, want := strings.Join(pkgs.Object, ","), "api/v1,pkg/internal"; got != want {
+		t.Errorf("object packages: got %q, want %q", got, want)
+	}
+	if got, want := strings.Join(pkgs.CRD, ","), "api/v1"; got != want {
+		t.Errorf("crd packages: got %q, want %q", got, want)
+	}
+
+	args := controllerGenArgs(&ControllerGenConfig{HeaderFile: "hack/boilerplate.go.txt"}, pkgs)
+	want := `run sigs.k8s.io/controller-tools/cmd/controller-gen@` + DefaultControllerGenVersion +
+		` object:headerFile="hack/boilerplate.go.txt" paths=./api/v1 paths=./pkg/internal crd paths=./api/v1 output:crd:artifacts:config=config/crd`
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("controllerGenArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestMockgen(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/foo\n",
+		"pkg/store/store.go": `//go:generate mockgen -source=$GOFILE -destination=mocks/store.go -package=mocks
+//go:generate go run go.uber.org/mock/mockgen@v0.4.0 -destination "mock dir/cache.go" example.com/foo/pkg/store Cache
+//go:generate stringer -type=Kind
+
+package store
+`,
+	})
+
+	var directives []mockgenDirective
+	if err := walkGoFiles(root, func(path string) error {
+		found, err := findMockgenDirectives(path)
+		directives = append(directives, found...)
+		return err
+	}); err != nil {
+		t.Fatalf("failed to find directives: %v", err)
+	}
+	if len(directives) != 2 {
+		t.Fatalf("got %d directives, want 2: %+v", len(directives), directives)
+	}
+	if d := directives[0]; d.Tool != mockgenPackage || d.Package != "store" || d.Line != 1 ||
+		strings.Join(d.Args, " ") != "-source=$GOFILE -destination=mocks/store.go -package=mocks" {
+		t.Errorf("unexpected directive %+v", d)
+	}
+	if d := directives[1]; d.Tool != mockgenPackage+"@v0.4.0" ||
+		strings.Join(d.Args, "|") != "-destination|mock dir/cache.go|example.com/foo/pkg/store|Cache" {
+		t.Errorf("unexpected directive %+v", d)
+	}
+
+	writeFiles(t, root, map[string]string{
+		".ap/mocks.yaml": "mocks:\n- source: pkg/store/store.go\n  destination: pkg/store/mocks/store.go\n" +
+			"- importPath: example.com/foo/pkg/store\n  interfaces: [Store, Cache]\n  destination: pkg/store/mocks/pkg.go\n  package: mocks\n",
+	})
+	cfg, err := LoadMocksConfig(root)
+	if err != nil {
+		t.Fatalf("LoadMocksConfig failed: %v", err)
+	}
+	if got, want := strings.Join(mockgenArgs(cfg.Mocks[0]), " "), "-destination=pkg/store/mocks/store.go -source=pkg/store/store.go"; got != want {
+		t.Errorf("mockgenArgs() = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(mockgenArgs(cfg.Mocks[1]), " "), "-destination=pkg/store/mocks/pkg.go -package=mocks example.com/foo/pkg/store Store,Cache"; got != want {
+		t.Errorf("mockgenArgs() = %q, want %q", got, want)
+	}
+
+	writeFiles(t, root, map[string]string{
+		".ap/mocks.yaml": "mocks:\n- destination: mocks.go\n",
+	})
+	if _, err := LoadMocksConfig(root); err == nil {
+		t.Errorf("expected an error for a mock without a source or importPath")
+	}
+}
+
+func TestWriteGoSetup(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     []string
+		notWant  []string
+	}{
+		{
+			versions: nil,
+			want:     []string{"go-version-file: 'go.mod'"},
+			notWant:  []string{"if:", "go-version:"},
+		},
+		{
+			versions: []string{"1.25"},
+			want:     []string{"go-version: '1.25'"},
+			notWant:  []string{"if:", "go-version-file"},
+		},
+		{
+			versions: []string{"tip"},
+			want:     []string{"go-version: 'stable'", "gotip download"},
+			notWant:  []string{"if:"},
+		},
+		{
+			versions: []string{"go.mod", "stable"},
+			want:     []string{"if: matrix.go == 'go.mod'", "if: matrix.go != 'go.mod'", "go-version: ${{ matrix.go }}"},
+			notWant:  []string{"gotip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.versions, ","), func(t *testing.T) {
+			cfg := &CIConfig{Matrix: &CIMatrix{Go: tt.versions}}
+			var sb strings.Builder
+			cfg.writeGoSetup(&sb, "go.mod")
+			for _, want := range tt.want {
+				if !strings.Contains(sb.String(), want) {
+					t.Errorf("writeGoSetup() is missing %q:\n%s", want, sb.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(sb.String(), notWant) {
+					t.Errorf("writeGoSetup() unexpectedly contains %q:\n%s", notWant, sb.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLoadCIConfigInvalidStep(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ci.yaml": "setup:\n- name: both\n  uses: actions/foo@v1\n  run: echo\n",
+	})
+	if _, err := LoadCIConfig(root); err == nil || !strings.Contains(err.Error(), "exactly one of uses and run") {
+		t.Errorf("LoadCIConfig() error = %v, want invalid step", err)
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	cache := false
+	cfg := &CIConfig{Cache: &cache}
+	var sb strings.Builder
+	cfg.writeGoSetup(&sb, "go.mod")
+	cfg.writeCacheStep(&sb, "ap-test", "go.sum")
+	if strings.Contains(sb.String(), "cache") {
+		t.Errorf("with cache: false, the generated steps still configure caching:\n%s", sb.String())
+	}
+}
+
+func TestCIBackends(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ap.yaml":                        "",
+		".ap/ci.yaml":                        "backends: [prow, cloudbuild]\n",
+		"go.mod":                             "module example.com/foo\n\ngo 1.25.0\n",
+		"tools/.ap/ap.yaml":                  "",
+		"tools/dev/ci/presubmits/tools-lint": "#!/bin/bash\n",
+	})
+
+	if err := Run(context.Background(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, ".github", "workflows", "ci-presubmits.yaml")); !os.IsNotExist(err) {
+		t.Errorf("GitHub Actions workflow was generated, but github-actions is not a configured backend")
+	}
+	for _, name := range []string{".prow.yaml", "cloudbuild.yaml"} {
+		got, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		goldentest.CompareFile(t, filepath.Join("testdata", "ci_backends", strings.TrimPrefix(name, ".")), got)
+	}
+}
+
+func TestPostsubmitsAndReleases(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ap.yaml":                          "version: \"!self\"\n",
+		".ap/ci.yaml":                          "setup:\n- name: Log in to the registry\n  run: ./dev/login\n",
+		"go.mod":                               "module example.com/foo\n",
+		"dev/ci/postsubmits/publish-docs":      "#!/bin/bash\n",
+		"dev/tasks/release-notes":              "#!/bin/bash\n",
+		"dev/tasks/test-unit":                  "#!/bin/bash\n",
+		"charts/.ap/ap.yaml":                   "",
+		"charts/dev/tasks/release-helm-charts": "#!/bin/bash\n",
+	})
+
+	if err := Run(context.Background(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	workflowsDir := filepath.Join(root, ".github", "workflows")
+	for _, name := range []string{"ci-postsubmits.yaml", "release.yaml"} {
+		got, err := os.ReadFile(filepath.Join(workflowsDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		goldentest.CompareFile(t, filepath.Join("testdata", "postsubmits_and_releases", name), got)
+	}
+
+	// The workflows are removed along with the last of their scripts.
+	for _, script := range []string{"dev/ci/postsubmits/publish-docs", "dev/tasks/release-notes", "charts/dev/tasks/release-helm-charts"} {
+		if err := os.Remove(filepath.Join(root, script)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Run(context.Background(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, name := range []string{"ci-postsubmits.yaml", "release.yaml"} {
+		if _, err := os.Stat(filepath.Join(workflowsDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed after its scripts were", name)
+		}
+	}
+}
+
+func TestPeriodics(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ap.yaml":           "version: \"!self\"\n",
+		".ap/images.yaml":       "reproducible: true\n",
+		"go.mod":                "module example.com/foo\n",
+		"images/web/Dockerfile": "FROM scratch\n",
+		"dev/ci/periodics/fuzz": "#!/bin/bash\n",
+	})
+
+	if err := Run(context.Background(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	script, err := os.ReadFile(filepath.Join(root, "dev", "ci", "periodics", "ap-build-reproducible"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(script), "go run ./ap build --verify-reproducible\n") {
+		t.Errorf("ap-build-reproducible does not run ap build --verify-reproducible:\n%s", script)
+	}
+	workflowFile := filepath.Join(root, ".github", "workflows", "ci-periodics.yaml")
+	got, err := os.ReadFile(workflowFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goldentest.CompareFile(t, filepath.Join("testdata", "periodics", "ci-periodics.yaml"), got)
+
+	// The reproducibility check and the workflow are removed when they are no longer needed.
+	for _, file := range []string{".ap/images.yaml", "dev/ci/periodics/fuzz"} {
+		if err := os.Remove(filepath.Join(root, file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Run(context.Background(), root); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := os.Stat(workflowFile); !os.IsNotExist(err) {
+		t.Errorf("ci-periodics.yaml was not removed after its scripts were")
+	}
+}
+
+func TestLoadCIConfigUnknownBackend(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ci.yaml": "backends: [jenkins]\n",
+	})
+	if _, err := LoadCIConfig(root); err == nil || !strings.Contains(err.Error(), `unknown backend "jenkins"`) {
+		t.Errorf("LoadCIConfig() error = %v, want unknown backend", err)
+	}
+}
+
+func TestActionVersions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ci.yaml": "actions:\n  actions/checkout: \"08c6903cd8c0fde910a37f88322edcfb5dd907a8 # v5.0.0\"\n  actions/setup-go: v6\n",
+	})
+	cfg, err := LoadCIConfig(root)
+	if err != nil {
+		t.Fatalf("LoadCIConfig() error = %v", err)
+	}
+	var sb strings.Builder
+	cfg.writeGoSetup(&sb, "go.mod")
+	if got := sb.String(); !strings.Contains(got, "uses: actions/setup-go@v6\n") {
+		t.Errorf("writeGoSetup() does not use the configured version of actions/setup-go:\n%s", got)
+	}
+	if got, want := cfg.uses("actions/checkout"), "actions/checkout@08c6903cd8c0fde910a37f88322edcfb5dd907a8 # v5.0.0"; got != want {
+		t.Errorf("uses(actions/checkout) = %q, want %q", got, want)
+	}
+	if got, want := cfg.uses("actions/cache"), "actions/cache@v4"; got != want {
+		t.Errorf("uses(actions/cache) = %q, want %q", got, want)
+	}
+}
+
+func TestLoadCIConfigUnknownAction(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/ci.yaml": "actions:\n  actions/foo: v1\n",
+	})
+	if _, err := LoadCIConfig(root); err == nil || !strings.Contains(err.Error(), "not used by the generated workflow") {
+		t.Errorf("LoadCIConfig() error = %v, want unknown action", err)
+	}
+}
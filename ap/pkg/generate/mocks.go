@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultMockgenVersion is the version of go.uber.org/mock/mockgen used when .ap/mocks.yaml does not pin one.
+const DefaultMockgenVersion = "v0.5.2"
+
+const mockgenPackage = "go.uber.org/mock/mockgen"
+
+// MocksConfig is the contents of .ap/mocks.yaml.
+type MocksConfig struct {
+	// Version is the mockgen version to run (defaults to DefaultMockgenVersion).
+	Version string `json:"version,omitempty"`
+
+	// Mocks are generated in addition to those from //go:generate mockgen directives.
+	Mocks []MockConfig `json:"mocks,omitempty"`
+}
+
+// MockConfig configures one mockgen invocation.
+// Either Source (source mode) or ImportPath and Interfaces (package mode) must be set.
+type MockConfig struct {
+	// Source is the Go file to mock the interfaces of, relative to the ap root.
+	Source string `json:"source,omitempty"`
+
+	// ImportPath is the package to mock the Interfaces of.
+	ImportPath string   `json:"importPath,omitempty"`
+	Interfaces []string `json:"interfaces,omitempty"`
+
+	// Destination is the generated file, relative to the ap root.
+	Destination string `json:"destination"`
+
+	// Package is the package name of the generated file (defaults to mock_ and the source package name).
+	Package string `json:"package,omitempty"`
+}
+
+// LoadMocksConfig loads .ap/mocks.yaml from apRoot, returning an empty config if it does not exist.
+func LoadMocksConfig(apRoot string) (*MocksConfig, error) {
+	configFile := filepath.Join(apRoot, ".ap", "mocks.yaml")
+
+	var config MocksConfig
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+	for i, mock := range config.Mocks {
+		if mock.Destination == "" {
+			return nil, fmt.Errorf("error in %s: mocks[%d] has no destination", configFile, i)
+		}
+		if (mock.Source == "") == (mock.ImportPath == "") {
+			return nil, fmt.Errorf("error in %s: mocks[%d] must set exactly one of source or importPath", configFile, i)
+		}
+		if mock.ImportPath != "" && len(mock.Interfaces) == 0 {
+			return nil, fmt.Errorf("error in %s: mocks[%d] has an importPath but no interfaces", configFile, i)
+		}
+	}
+	return &config, nil
+}
+
+// mockgenDirective is a //go:generate directive that runs mockgen.
+type mockgenDirective struct {
+	// Path is the Go file containing the directive.
+	Path    string
+	Line    int
+	Package string
+	// Tool is the mockgen package to run, with a version if the directive pins one.
+	Tool string
+	Args []string
+}
+
+// runMockGenerator regenerates the mocks under apRoot, from //go:generate mockgen directives
+// and from .ap/mocks.yaml. mockgen is run with go run, so it does not need to be installed.
+func runMockGenerator(ctx context.Context, apRoot string) error {
+	cfg, err := LoadMocksConfig(apRoot)
+	if err != nil {
+		return err
+	}
+	version := cfg.Version
+	if version == "" {
+		version = DefaultMockgenVersion
+	}
+
+	var directives []mockgenDirective
+	if err := walkGoFiles(apRoot, func(path string) error {
+		found, err := findMockgenDirectives(path)
+		directives = append(directives, found...)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to scan %s for mockgen directives: %w", apRoot, err)
+	}
+
+	for _, d := range directives {
+		rel, _ := filepath.Rel(apRoot, d.Path)
+		klog.Infof("Running mockgen for %s:%d", rel, d.Line)
+		env := map[string]string{
+			"GOFILE":    filepath.Base(d.Path),
+			"GOLINE":    strconv.Itoa(d.Line),
+			"GOPACKAGE": d.Package,
+			"DOLLAR":    "$",
+		}
+		var args []string
+		for _, arg := range d.Args {
+			args = append(args, os.Expand(arg, func(key string) string {
+				if v, ok := env[key]; ok {
+					return v
+				}
+				return os.Getenv(key)
+			}))
+		}
+		tool := d.Tool
+		if !strings.Contains(tool, "@") {
+			tool += "@" + version
+		}
+		if err := runMockgen(ctx, filepath.Dir(d.Path), env, tool, args); err != nil {
+			return fmt.Errorf("mockgen failed for %s:%d: %w", rel, d.Line, err)
+		}
+	}
+
+	for _, mock := range cfg.Mocks {
+		klog.Infof("Running mockgen for %s", mock.Destination)
+		if err := runMockgen(ctx, apRoot, nil, mockgenPackage+"@"+version, mockgenArgs(mock)); err != nil {
+			return fmt.Errorf("mockgen failed for %s: %w", mock.Destination, err)
+		}
+	}
+	return nil
+}
+
+func runMockgen(ctx context.Context, dir string, env map[string]string, tool string, args []string) error {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"run", tool}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// mockgenArgs returns the mockgen arguments for a mock configured in .ap/mocks.yaml.
+func mockgenArgs(mock MockConfig) []string {
+	args := []string{"-destination=" + mock.Destination}
+	if mock.Package != "" {
+		args = append(args, "-package="+mock.Package)
+	}
+	if mock.Source != "" {
+		return append(args, "-source="+mock.Source)
+	}
+	return append(args, mock.ImportPath, strings.Join(mock.Interfaces, ","))
+}
+
+// findMockgenDirectives returns the //go:generate directives in the Go file that run mockgen,
+// either directly or with go run.
+func findMockgenDirectives(path string) ([]mockgenDirective, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var directives []mockgenDirective
+	pkg := ""
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if name, ok := strings.CutPrefix(text, "package "); ok && pkg == "" {
+			pkg = strings.TrimSpace(name)
+			continue
+		}
+		command, ok := strings.CutPrefix(text, "//go:generate ")
+		if !ok {
+			continue
+		}
+		words, err := splitDirective(command)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		tool, args, ok := mockgenCommand(words)
+		if !ok {
+			continue
+		}
+		directives = append(directives, mockgenDirective{Path: path, Line: line, Tool: tool, Args: args})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// The package clause usually follows the directives.
+	for i := range directives {
+		directives[i].Package = pkg
+	}
+	return directives, nil
+}
+
+// mockgenCommand returns the mockgen package and arguments if words runs mockgen.
+func mockgenCommand(words []string) (string, []string, bool) {
+	if len(words) > 0 && words[0] == "mockgen" {
+		return mockgenPackage, words[1:], true
+	}
+	if len(words) > 2 && words[0] == "go" && words[1] == "run" {
+		tool, _, _ := strings.Cut(words[2], "@")
+		if tool == mockgenPackage || tool == "github.com/golang/mock/mockgen" {
+			return words[2], words[3:], true
+		}
+	}
+	return "", nil, false
+}
+
+// splitDirective splits a //go:generate command into words, as go generate does:
+// words are separated by spaces, and double-quoted strings are a single word.
+func splitDirective(command string) ([]string, error) {
+	var words []string
+	for {
+		command = strings.TrimLeft(command, " \t")
+		if command == "" {
+			return words, nil
+		}
+		if command[0] == '"' {
+			end := 1
+			for ; end < len(command); end++ {
+				if command[end] == '\\' {
+					end++
+				} else if command[end] == '"' {
+					break
+				}
+			}
+			if end >= len(command) {
+				return nil, fmt.Errorf("unterminated quoted string in go:generate directive")
+			}
+			word, err := strconv.Unquote(command[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, word)
+			command = command[end+1:]
+			continue
+		}
+		end := strings.IndexAny(command, " \t")
+		if end < 0 {
+			end = len(command)
+		}
+		words = append(words, command[:end])
+		command = command[end:]
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package githooks installs the git hooks that run ap before commits, and finds the files they check.
+package githooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+)
+
+// Marker identifies hooks installed by ap, which can be replaced or removed without losing anything.
+const Marker = "# Installed by ap githooks install; reinstall to update."
+
+// PreCommitHook formats and lints the files staged for a commit.
+// If formatting changes any files, the commit is stopped so that the changes can be reviewed and staged.
+const PreCommitHook = `#!/bin/sh
+` + Marker + `
+set -o errexit
+set -o nounset
+
+AP="${AP:-ap}"
+if ! command -v "${AP}" > /dev/null; then
+  echo "pre-commit: ${AP} not found; install it, or set AP to its path" >&2
+  exit 1
+fi
+
+before="$(git diff)"
+"${AP}" format --changed
+if [ "$(git diff)" != "${before}" ]; then
+  echo "pre-commit: ap format changed files; review the changes, stage them and commit again" >&2
+  exit 1
+fi
+"${AP}" lint --changed
+`
+
+// HooksDir returns the directory git runs the hooks of the repository at repoRoot from,
+// which honors core.hooksPath.
+func HooksDir(ctx context.Context, repoRoot string) (string, error) {
+	out, err := git(ctx, repoRoot, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoRoot, dir)
+	}
+	return dir, nil
+}
+
+// Install writes the pre-commit hook of the repository at repoRoot, and returns its path.
+// A pre-commit hook that was not installed by ap is only replaced if force is set.
+// If report is not nil, the change is recorded in it instead of being made.
+func Install(ctx context.Context, repoRoot string, force bool, report *dryrun.Report) (string, error) {
+	dir, err := HooksDir(ctx, repoRoot)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "pre-commit")
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return "", err
+	case string(existing) == PreCommitHook:
+		return path, nil
+	case !bytes.Contains(existing, []byte(Marker)) && !force:
+		return "", fmt.Errorf("%s already exists and was not installed by ap; use --force to replace it", path)
+	}
+
+	if report != nil {
+		report.Addf("write %s", path)
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(PreCommitHook), 0755); err != nil {
+		return "", err
+	}
+	// WriteFile does not change the mode of an existing file.
+	if err := os.Chmod(path, 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Uninstall removes the pre-commit hook of the repository at repoRoot, if ap installed it,
+// and returns its path, or "" if there was nothing to remove.
+// If report is not nil, the change is recorded in it instead of being made.
+func Uninstall(ctx context.Context, repoRoot string, report *dryrun.Report) (string, error) {
+	dir, err := HooksDir(ctx, repoRoot)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "pre-commit")
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Contains(existing, []byte(Marker)) {
+		return "", fmt.Errorf("%s was not installed by ap; remove it by hand", path)
+	}
+	if report != nil {
+		report.Addf("delete %s", path)
+		return path, nil
+	}
+	return path, os.Remove(path)
+}
+
+// StagedFiles returns the absolute paths of the files staged in the repository at repoRoot
+// that have been added, copied, modified or renamed; deleted files have nothing to check.
+func StagedFiles(ctx context.Context, repoRoot string) ([]string, error) {
+	out, err := git(ctx, repoRoot, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(repoRoot, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// git runs git in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githooks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/gittest"
+)
+
+func TestInstall(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	repoRoot := repo.Dir
+	hook := filepath.Join(repoRoot, ".git", "hooks", "pre-commit")
+
+	// A dry run only reports the hook.
+	var report dryrun.Report
+	if _, err := Install(t.Context(), repoRoot, false, &report); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if got := report.Changes(); len(got) != 1 || !strings.HasPrefix(got[0], "write ") {
+		t.Errorf("dry run changes = %v, want the hook to be written", got)
+	}
+	if _, err := os.Stat(hook); !os.IsNotExist(err) {
+		t.Fatalf("dry run wrote %s", hook)
+	}
+
+	path, err := Install(t.Context(), repoRoot, false, nil)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if path != hook {
+		t.Errorf("Install() = %s, want %s", path, hook)
+	}
+	info, err := os.Stat(hook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("%s is not executable: %v", hook, info.Mode())
+	}
+
+	// Reinstalling replaces the hook, as ap installed it.
+	if _, err := Install(t.Context(), repoRoot, false, nil); err != nil {
+		t.Fatalf("reinstalling failed: %v", err)
+	}
+
+	// core.hooksPath is honored.
+	repo.Git("config", "core.hooksPath", ".githooks")
+	if path, err := Install(t.Context(), repoRoot, false, nil); err != nil || path != filepath.Join(repoRoot, ".githooks", "pre-commit") {
+		t.Errorf("Install() with core.hooksPath = %s, %v", path, err)
+	}
+}
+
+func TestInstallKeepsOtherHooks(t *testing.T) {
+	repoRoot := gittest.NewRepo(t).Dir
+	hook := filepath.Join(repoRoot, ".git", "hooks", "pre-commit")
+	if err := os.MkdirAll(filepath.Dir(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nmake check\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Install(t.Context(), repoRoot, false, nil); err == nil {
+		t.Errorf("Install() replaced a hook that ap did not install")
+	}
+	if _, err := Uninstall(t.Context(), repoRoot, nil); err == nil {
+		t.Errorf("Uninstall() removed a hook that ap did not install")
+	}
+
+	if _, err := Install(t.Context(), repoRoot, true, nil); err != nil {
+		t.Fatalf("Install() with force error = %v", err)
+	}
+	path, err := Uninstall(t.Context(), repoRoot, nil)
+	if err != nil || path != hook {
+		t.Fatalf("Uninstall() = %s, %v; want %s", path, err, hook)
+	}
+	if _, err := os.Stat(hook); !os.IsNotExist(err) {
+		t.Errorf("%s was not removed", hook)
+	}
+}
+
+func TestStagedFiles(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	for _, name := range []string{"a.go", "b.go", "dir/c.md", "unstaged.go"} {
+		repo.WriteFile(name, name)
+	}
+	repo.Git("add", "a.go", "b.go")
+	repo.Git("commit", "-q", "-m", "initial")
+	repo.Git("rm", "-q", "b.go")
+	repo.Git("add", "dir/c.md")
+	repo.WriteFile("a.go", "changed")
+	repo.Git("add", "a.go")
+
+	got, err := StagedFiles(t.Context(), repo.Dir)
+	if err != nil {
+		t.Fatalf("StagedFiles() error = %v", err)
+	}
+	// The deleted file has nothing to check.
+	want := []string{filepath.Join(repo.Dir, "a.go"), filepath.Join(repo.Dir, "dir", "c.md")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StagedFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gittest creates git repositories for tests, with git configured only by the test.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Repo is a git repository in a temporary directory.
+type Repo struct {
+	t *testing.T
+	// Dir is the root of the work tree.
+	Dir string
+}
+
+// NewRepo runs git init, with initArgs (e.g. "-b", "main"), in a temporary directory.
+// For the rest of the test, git ignores the user's global config and the environment
+// variables that would point it at another repository.
+func NewRepo(t *testing.T, initArgs ...string) *Repo {
+	t.Helper()
+	for _, name := range []string{"GIT_WORK_TREE", "GIT_DIR", "GIT_INDEX_FILE"} {
+		// t.Setenv restores the variable after the test; git rejects it being set but empty.
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	r := &Repo{t: t, Dir: t.TempDir()}
+	r.Git(append([]string{"init", "-q"}, initArgs...)...)
+	return r
+}
+
+// Git runs git with args in the repository, as a test user, and returns its output without surrounding whitespace.
+// The test fails if git does.
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = r.Dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// WriteFile writes content to the file at the slash-separated path in the repository, creating its directories.
+func (r *Repo) WriteFile(path, content string) {
+	r.t.Helper()
+	p := filepath.Join(r.Dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
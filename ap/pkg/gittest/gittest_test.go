@@ -0,0 +1,36 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gittest
+
+import "testing"
+
+func TestNewRepo(t *testing.T) {
+	// A repository of the environment is not used.
+	t.Setenv("GIT_DIR", t.TempDir())
+
+	repo := NewRepo(t, "-b", "main")
+	repo.WriteFile("dir/a.txt", "a\n")
+	repo.Git("add", ".")
+	repo.Git("commit", "-q", "-m", "initial")
+	if got := repo.Git("rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Errorf("branch = %q, want main", got)
+	}
+	if got := repo.Git("ls-files"); got != "dir/a.txt" {
+		t.Errorf("ls-files = %q, want dir/a.txt", got)
+	}
+	if got := repo.Git("log", "--format=%an"); got != "test" {
+		t.Errorf("author = %q, want test", got)
+	}
+}
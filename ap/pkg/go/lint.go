@@ -15,22 +15,56 @@
 package golang
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/dupcode"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/unused"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
 	"k8s.io/klog/v2"
 )
 
-// Lint runs go vet and govulncheck in discovered modules.
-func Lint(ctx context.Context, root string) error {
+// Lint runs go vet, govulncheck and the ap analyzers in discovered modules, checks that libraries
+// are required at a single major version across them, and reports expired entries of .ap/quarantine.yaml,
+// returning their findings.
+// Checks configured as warnings report warning findings; a check that cannot run is an error.
+func Lint(ctx context.Context, root string) ([]findings.Finding, error) {
+	return LintFiles(ctx, root, nil)
+}
+
+// LintFiles is Lint limited to the absolute paths in files, if there are any: the analyzers only run over the
+// packages of the Go files among them, govulncheck and the major version check only run if a go.mod or go.sum
+// is among them, and the duplicate code check (which compares the whole tree) does not run.
+// Findings may still be reported in the other files of the packages checked.
+func LintFiles(ctx context.Context, root string, files []string) ([]findings.Finding, error) {
 	cfg, err := config.Load(root)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	all, err := CheckQuarantine(root, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if cfg.IsDupCodeEnabled() && len(files) == 0 {
+		// Duplicate code is found across modules, so this runs once for the whole root.
+		found, err := findDuplicates(root, cfg)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
 	}
 
 	// Find all go.mod files
@@ -39,85 +73,373 @@ func Lint(ctx context.Context, root string) error {
 		return info.Name() == "go.mod"
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	if cfg.IsMajorVersionsEnabled() && (len(files) == 0 || slices.ContainsFunc(files, isModFile)) {
+		// Stdout is reserved for the lint report.
+		found, err := checkMajorVersions(root, goMods, cfg.SingleMajorLibraries(), severity(cfg.IsMajorVersionsError()), os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+	}
+
+	analyses := newAnalysisCache(root)
+	defer analyses.save()
+
 	for _, goMod := range goMods {
 		dir := filepath.Dir(goMod)
 
 		hasGo, err := hasGoFiles(dir)
 		if err != nil {
-			return fmt.Errorf("failed to check for Go files in %s: %w", dir, err)
+			return nil, fmt.Errorf("failed to check for Go files in %s: %w", dir, err)
 		}
 		if !hasGo {
 			klog.Infof("Skipping %s as it contains no Go files", dir)
 			continue
 		}
 
-		if cfg.IsGovetEnabled() {
+		pkgs, modChanged := []string{"./..."}, true
+		if len(files) > 0 {
+			pkgs, modChanged = changedPackages(dir, goMods, files)
+			if len(pkgs) == 0 && !modChanged {
+				continue
+			}
+		}
+
+		if cfg.IsGovetEnabled() && len(pkgs) > 0 {
 			klog.Infof("Running go vet in %s", dir)
-			vetCmd := exec.CommandContext(ctx, "go", "vet", "./...")
-			vetCmd.Dir = dir
-			vetCmd.Stdout = os.Stdout
-			vetCmd.Stderr = os.Stderr
-			if err := vetCmd.Run(); err != nil {
-				return fmt.Errorf("go vet failed in %s: %w", dir, err)
+			args := append([]string{"vet", "-json"}, pkgs...)
+			found, err := analyses.run("go vet", append([]string{goVersion(ctx, dir), dir}, args...), func() ([]findings.Finding, error) {
+				return runAnalysis(ctx, dir, findings.SeverityError, "go", args...)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("go vet failed in %s: %w", dir, err)
 			}
+			all = append(all, found...)
 		}
 
-		if cfg.IsGovulncheckEnabled() {
+		if cfg.IsGovulncheckEnabled() && modChanged {
 			klog.Infof("Running govulncheck in %s", dir)
 			vulnCmd := exec.CommandContext(ctx, "go", "run", "golang.org/x/vuln/cmd/govulncheck@latest", "./...")
 			vulnCmd.Dir = dir
-			vulnCmd.Stdout = os.Stdout
+			// Stdout is reserved for the lint report.
+			vulnCmd.Stdout = os.Stderr
 			vulnCmd.Stderr = os.Stderr
 			if err := vulnCmd.Run(); err != nil {
-				return fmt.Errorf("govulncheck failed in %s: %w", dir, err)
+				return nil, fmt.Errorf("govulncheck failed in %s: %w", dir, err)
 			}
 		}
 
+		if len(pkgs) == 0 {
+			continue
+		}
+
 		if cfg.IsUnusedEnabled() {
 			klog.Infof("Running unused check in %s", dir)
-			apPath, err := os.Executable()
-			if err != nil {
-				return fmt.Errorf("could not find ap executable: %w", err)
-			}
-			args := []string{"lint", "unused"}
+			args := []string{"unused"}
 			if cfg.IsUnusedParametersEnabled() {
 				args = append(args, "-unused.check-parameters=true")
 			} else {
 				args = append(args, "-unused.check-parameters=false")
 			}
-			args = append(args, "./...")
-			unusedCmd := exec.CommandContext(ctx, apPath, args...)
-			unusedCmd.Dir = dir
-			unusedCmd.Stdout = os.Stdout
-			unusedCmd.Stderr = os.Stderr
-			if err := unusedCmd.Run(); err != nil {
-				return fmt.Errorf("unused check failed in %s: %w", dir, err)
+			if comment := cfg.UnusedSuppressComment(); comment != "" {
+				args = append(args, "-unused.suppress-comment="+comment)
+			}
+			found, err := analyses.runAP(ctx, dir, findings.SeverityError, pkgs, args...)
+			if err != nil {
+				return nil, fmt.Errorf("unused check failed in %s: %w", dir, err)
+			}
+			all = append(all, found...)
+		}
+
+		if cfg.IsUnusedExportedEnabled() {
+			// A change to any package can leave the exported identifiers of another unused, so the whole module is checked.
+			klog.Infof("Running unused exported check in %s", dir)
+			sev := severity(cfg.IsUnusedExportedError())
+			found, err := analyses.run("unused exported", []string{apVersion(), dir, string(sev)}, func() ([]findings.Finding, error) {
+				return unused.Exported(ctx, dir, sev)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("unused exported check failed in %s: %w", dir, err)
 			}
+			all = append(all, found...)
 		}
 
 		if cfg.IsTestContextEnabled() {
 			klog.Infof("Running testcontext check in %s", dir)
-			apPath, err := os.Executable()
+			found, err := analyses.runAP(ctx, dir, severity(cfg.IsTestContextError()), pkgs, "testcontext")
 			if err != nil {
-				return fmt.Errorf("could not find ap executable: %w", err)
-			}
-			args := []string{"lint", "testcontext", "./..."}
-			testcontextCmd := exec.CommandContext(ctx, apPath, args...)
-			testcontextCmd.Dir = dir
-			testcontextCmd.Stdout = os.Stdout
-			testcontextCmd.Stderr = os.Stderr
-			if err := testcontextCmd.Run(); err != nil {
 				if cfg.IsTestContextError() {
-					return fmt.Errorf("testcontext check failed in %s: %w", dir, err)
+					return nil, fmt.Errorf("testcontext check failed in %s: %w", dir, err)
 				}
 				klog.Warningf("testcontext check failed in %s: %v", dir, err)
 			}
+			all = append(all, found...)
 		}
+
+		if cfg.IsCobraCmdEnabled() {
+			klog.Infof("Running cobracmd check in %s", dir)
+			found, err := analyses.runAP(ctx, dir, severity(cfg.IsCobraCmdError()), pkgs, "cobracmd")
+			if err != nil {
+				if cfg.IsCobraCmdError() {
+					return nil, fmt.Errorf("cobracmd check failed in %s: %w", dir, err)
+				}
+				klog.Warningf("cobracmd check failed in %s: %v", dir, err)
+			}
+			all = append(all, found...)
+		}
+
+		if cfg.IsLargeCopyEnabled() {
+			klog.Infof("Running largecopy check in %s", dir)
+			args := []string{"largecopy", fmt.Sprintf("-largecopy.max-size=%d", cfg.LargeCopyMaxSize())}
+			found, err := analyses.runAP(ctx, dir, severity(cfg.IsLargeCopyError()), pkgs, args...)
+			if err != nil {
+				if cfg.IsLargeCopyError() {
+					return nil, fmt.Errorf("largecopy check failed in %s: %w", dir, err)
+				}
+				klog.Warningf("largecopy check failed in %s: %v", dir, err)
+			}
+			all = append(all, found...)
+		}
+
+		// The concurrency analyzers run in one pass per severity, each selecting its analyzers with their flags.
+		modes := cfg.ConcurrencyModes()
+		for _, isError := range []bool{true, false} {
+			args := []string{"concurrency"}
+			for _, name := range slices.Sorted(maps.Keys(modes)) {
+				if mode := modes[name]; mode != "ignore" && (mode == "error") == isError {
+					args = append(args, "-"+name)
+				}
+			}
+			if len(args) == 1 {
+				continue
+			}
+			klog.Infof("Running concurrency checks %s in %s", strings.Join(args[1:], " "), dir)
+			found, err := analyses.runAP(ctx, dir, severity(isError), pkgs, args...)
+			if err != nil {
+				if isError {
+					return nil, fmt.Errorf("concurrency check failed in %s: %w", dir, err)
+				}
+				klog.Warningf("concurrency check failed in %s: %v", dir, err)
+			}
+			all = append(all, found...)
+		}
+	}
+	return all, nil
+}
+
+// severity returns the severity of the findings of a check configured as an error or a warning.
+func severity(isError bool) findings.Severity {
+	if isError {
+		return findings.SeverityError
 	}
-	return nil
+	return findings.SeverityWarning
+}
+
+// runAPAnalyzer runs the hidden "ap lint <analyzer>" command over the packages pkgs in dir.
+func runAPAnalyzer(ctx context.Context, dir string, sev findings.Severity, pkgs []string, args ...string) ([]findings.Finding, error) {
+	apPath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("could not find ap executable: %w", err)
+	}
+	args = append([]string{"lint"}, args...)
+	args = append(args, "-json")
+	args = append(args, pkgs...)
+	return runAnalysis(ctx, dir, sev, apPath, args...)
+}
+
+// runAnalysis runs an analysis driver (go vet or a multichecker) in dir with -json, returning its diagnostics.
+func runAnalysis(ctx context.Context, dir string, sev findings.Severity, name string, args ...string) ([]findings.Finding, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+
+	found, other, err := parseAnalysisJSON(out.Bytes(), sev)
+	if err == nil && runErr != nil {
+		err = runErr
+	}
+	if err != nil {
+		if other != "" {
+			return nil, fmt.Errorf("%w\n%s", err, other)
+		}
+		return nil, err
+	}
+	if other != "" {
+		// e.g. "go: downloading ..."
+		fmt.Fprintln(os.Stderr, other)
+	}
+	return found, nil
+}
+
+// parseAnalysisJSON parses the -json output of an analysis driver, a stream of
+// {package: {analyzer: [diagnostic...] or {"error": ...}}} objects interleaved with other output.
+// It returns the diagnostics, the other output, and an error if an analyzer failed.
+func parseAnalysisJSON(out []byte, sev findings.Severity) ([]findings.Finding, string, error) {
+	var found []findings.Finding
+	var other []string
+	var errs []string
+
+	text := string(out)
+	for text != "" {
+		line, rest, _ := strings.Cut(text, "\n")
+		if !strings.HasPrefix(line, "{") {
+			// go vet prints "# <package>" before the JSON of each package.
+			if line != "" && !strings.HasPrefix(line, "# ") {
+				other = append(other, line)
+			}
+			text = rest
+			continue
+		}
+
+		var tree map[string]map[string]json.RawMessage
+		decoder := json.NewDecoder(strings.NewReader(text))
+		if err := decoder.Decode(&tree); err != nil {
+			other = append(other, line)
+			text = rest
+			continue
+		}
+		text = text[decoder.InputOffset():]
+
+		for pkg, analyzers := range tree {
+			for analyzer, result := range analyzers {
+				var diagnostics []struct {
+					Posn    string `json:"posn"`
+					Message string `json:"message"`
+				}
+				if err := json.Unmarshal(result, &diagnostics); err != nil {
+					var failure struct {
+						Err string `json:"error"`
+					}
+					if err := json.Unmarshal(result, &failure); err != nil {
+						return nil, "", fmt.Errorf("unexpected result for %s in %s: %s", analyzer, pkg, result)
+					}
+					errs = append(errs, fmt.Sprintf("%s: %s: %s", pkg, analyzer, failure.Err))
+					continue
+				}
+				for _, d := range diagnostics {
+					path, line, column := parsePosn(d.Posn)
+					found = append(found, findings.Finding{
+						Path:     path,
+						Line:     line,
+						Column:   column,
+						Rule:     analyzer,
+						Message:  d.Message,
+						Severity: sev,
+					})
+				}
+			}
+		}
+	}
+	findings.Sort(found)
+
+	var err error
+	if len(errs) > 0 {
+		err = fmt.Errorf("analysis failed:\n%s", strings.Join(errs, "\n"))
+	}
+	return found, strings.Join(other, "\n"), err
+}
+
+// parsePosn splits a position of the form file:line:column (or file:line, or file).
+func parsePosn(posn string) (string, int, int) {
+	var numbers []int
+	for len(numbers) < 2 {
+		i := strings.LastIndex(posn, ":")
+		if i == -1 {
+			break
+		}
+		n, err := strconv.Atoi(posn[i+1:])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		posn = posn[:i]
+	}
+	switch len(numbers) {
+	case 2:
+		return posn, numbers[0], numbers[1]
+	case 1:
+		return posn, numbers[0], 0
+	}
+	return posn, 0, 0
+}
+
+// findDuplicates returns the duplicated blocks of code under root as warnings; they do not fail the lint.
+func findDuplicates(root string, cfg *config.Config) ([]findings.Finding, error) {
+	opts := dupcode.Options{Skip: cfg.Skip}
+	if cfg.Lint != nil && cfg.Lint.DupCode != nil {
+		opts.MinTokens = cfg.Lint.DupCode.MinTokens
+		opts.IgnoreIdentifiers = cfg.Lint.DupCode.IgnoreIdentifiers
+	}
+
+	klog.Infof("Checking for duplicate code in %s", root)
+	duplicates, err := dupcode.Find(root, opts)
+	if err != nil {
+		return nil, fmt.Errorf("duplicate code check failed in %s: %w", root, err)
+	}
+	var found []findings.Finding
+	for _, d := range duplicates {
+		found = append(found, findings.Finding{
+			Path:     filepath.Join(root, d.A.File),
+			Line:     d.A.StartLine,
+			Rule:     "dupcode",
+			Message:  d.String(),
+			Severity: findings.SeverityWarning,
+		})
+	}
+	return found, nil
+}
+
+// changedPackages returns the patterns of the packages of the module in dir that contain Go files among files,
+// and whether its go.mod or go.sum is among them. Files in nested modules (of goMods) are not in the module.
+func changedPackages(dir string, goMods []string, files []string) ([]string, bool) {
+	var pkgs []string
+	modChanged := false
+	for _, f := range files {
+		if moduleDir(f, goMods) != dir {
+			continue
+		}
+		switch {
+		case isModFile(f):
+			modChanged = true
+		case strings.HasSuffix(f, ".go"):
+			rel, err := filepath.Rel(dir, filepath.Dir(f))
+			if err != nil {
+				continue
+			}
+			pkg := "./" + filepath.ToSlash(rel)
+			if rel == "." {
+				pkg = "."
+			}
+			if !slices.Contains(pkgs, pkg) {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+	slices.Sort(pkgs)
+	return pkgs, modChanged
+}
+
+// moduleDir returns the directory of the innermost of goMods containing path, or "" if none does.
+func moduleDir(path string, goMods []string) string {
+	best := ""
+	for _, goMod := range goMods {
+		dir := filepath.Dir(goMod)
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) && len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best
+}
+
+// isModFile returns true if path is a go.mod or go.sum file.
+func isModFile(path string) bool {
+	name := filepath.Base(path)
+	return name == "go.mod" || name == "go.sum"
 }
 
 // hasGoFiles returns true if the directory or any of its subdirectories
@@ -17,7 +17,11 @@ package golang
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
 )
 
 func TestHasGoFiles(t *testing.T) {
@@ -118,3 +122,102 @@ func TestHasGoFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAnalysisJSON(t *testing.T) {
+	out := `go: downloading example.com/dep v1.0.0
+# example.com/x/b
+{
+	"example.com/x/b": {
+		"printf": [
+			{
+				"posn": "/src/b/b.go:5:39",
+				"end": "/src/b/b.go:5:41",
+				"message": "fmt.Sprintf format %d has arg \"x\" of wrong type string"
+			}
+		]
+	}
+}
+# example.com/x/a
+{
+	"example.com/x/a": {
+		"unused": [
+			{
+				"posn": "/src/a/a.go:3:6",
+				"message": "func f is unused"
+			}
+		],
+		"testcontext": []
+	}
+}
+`
+	got, other, err := parseAnalysisJSON([]byte(out), findings.SeverityWarning)
+	if err != nil {
+		t.Fatalf("parseAnalysisJSON failed: %v", err)
+	}
+	want := []findings.Finding{
+		{Path: "/src/a/a.go", Line: 3, Column: 6, Rule: "unused", Message: "func f is unused", Severity: findings.SeverityWarning},
+		{Path: "/src/b/b.go", Line: 5, Column: 39, Rule: "printf", Message: `fmt.Sprintf format %d has arg "x" of wrong type string`, Severity: findings.SeverityWarning},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAnalysisJSON() = %+v, want %+v", got, want)
+	}
+	if other != "go: downloading example.com/dep v1.0.0" {
+		t.Errorf("parseAnalysisJSON() other output = %q", other)
+	}
+}
+
+func TestParseAnalysisJSONError(t *testing.T) {
+	out := `{
+	"example.com/x": {
+		"unused": {
+			"error": "type checking failed"
+		}
+	}
+}
+`
+	_, _, err := parseAnalysisJSON([]byte(out), findings.SeverityError)
+	if err == nil || !strings.Contains(err.Error(), "example.com/x: unused: type checking failed") {
+		t.Errorf("parseAnalysisJSON() error = %v, want analyzer failure", err)
+	}
+}
+
+func TestParsePosn(t *testing.T) {
+	tests := []struct {
+		posn         string
+		path         string
+		line, column int
+	}{
+		{"/src/a.go:3:6", "/src/a.go", 3, 6},
+		{"/src/a.go:3", "/src/a.go", 3, 0},
+		{"/src/a.go", "/src/a.go", 0, 0},
+		{"C:/src/a.go:3:6", "C:/src/a.go", 3, 6},
+	}
+	for _, tt := range tests {
+		path, line, column := parsePosn(tt.posn)
+		if path != tt.path || line != tt.line || column != tt.column {
+			t.Errorf("parsePosn(%q) = %q, %d, %d, want %q, %d, %d", tt.posn, path, line, column, tt.path, tt.line, tt.column)
+		}
+	}
+}
+
+func TestChangedPackages(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "repo")
+	goMods := []string{filepath.Join(root, "go.mod"), filepath.Join(root, "tools", "go.mod")}
+	files := []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "pkg", "a", "a.go"),
+		filepath.Join(root, "pkg", "a", "a_test.go"),
+		filepath.Join(root, "README.md"),
+		filepath.Join(root, "tools", "go.sum"),
+		filepath.Join(root, "tools", "cmd", "tool.go"),
+	}
+
+	pkgs, modChanged := changedPackages(root, goMods, files)
+	if want := []string{".", "./pkg/a"}; !reflect.DeepEqual(pkgs, want) || modChanged {
+		t.Errorf("changedPackages(root) = %v, %v; want %v, false", pkgs, modChanged, want)
+	}
+	pkgs, modChanged = changedPackages(filepath.Join(root, "tools"), goMods, files)
+	if want := []string{"./cmd"}; !reflect.DeepEqual(pkgs, want) || !modChanged {
+		t.Errorf("changedPackages(tools) = %v, %v; want %v, true", pkgs, modChanged, want)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+	"k8s.io/klog/v2"
+)
+
+// analysisCache replays the findings of the analyses whose inputs have not changed since they last ran.
+type analysisCache struct {
+	cm *cache.Manager
+	// inputs is a hash of the Go files and the go.mod, go.sum and go.work files under the root.
+	inputs string
+}
+
+// newAnalysisCache returns the cache of the analyses of the modules under root.
+// If the cache cannot be used, the analyses always run.
+func newAnalysisCache(root string) *analysisCache {
+	cm, err := cache.NewManager(root)
+	if err != nil {
+		klog.V(2).Infof("Failed to initialize cache: %v", err)
+		return &analysisCache{}
+	}
+	inputs, err := goInputsHash(root, cm)
+	if err != nil {
+		klog.V(2).Infof("Failed to hash the Go files of %s: %v", root, err)
+		return &analysisCache{}
+	}
+	return &analysisCache{cm: cm, inputs: inputs}
+}
+
+// run returns the findings of the analysis tool, running fn unless its result for the same inputs is cached.
+// The key identifies everything else the findings depend on: the version of the tool, the module, the packages,
+// the flags and the severity.
+func (c *analysisCache) run(tool string, key []string, fn func() ([]findings.Finding, error)) ([]findings.Finding, error) {
+	if c.cm == nil || len(key) == 0 || key[0] == "" {
+		return fn()
+	}
+	hash := cache.Key(append([]string{c.inputs}, key...)...)
+	if result, ok := c.cm.Done(tool, hash); ok {
+		klog.Infof("Go files are unchanged, replaying the findings of %s", tool)
+		return result.Findings, nil
+	}
+	found, err := fn()
+	if err == nil {
+		c.cm.MarkDone(tool, hash, &cache.Result{Findings: found})
+	}
+	return found, err
+}
+
+// runAP runs the hidden "ap lint <analyzer>" command over the packages pkgs in dir, as runAPAnalyzer does,
+// replaying its findings if they are cached.
+func (c *analysisCache) runAP(ctx context.Context, dir string, sev findings.Severity, pkgs []string, args ...string) ([]findings.Finding, error) {
+	key := append([]string{apVersion(), dir, string(sev)}, pkgs...)
+	return c.run("ap lint "+args[0], append(key, args...), func() ([]findings.Finding, error) {
+		return runAPAnalyzer(ctx, dir, sev, pkgs, args...)
+	})
+}
+
+// save writes the cache, if it is used.
+func (c *analysisCache) save() {
+	if c.cm == nil {
+		return
+	}
+	if err := c.cm.Save(); err != nil {
+		klog.Warningf("Failed to save cache: %v", err)
+	}
+}
+
+// goInputsHash returns a hash of the content of the Go files and the go.mod, go.sum and go.work files under root.
+func goInputsHash(root string, cm *cache.Manager) (string, error) {
+	ignoreList := walker.NewIgnoreList([]string{".git", "vendor", "node_modules"})
+	files, err := walker.Walk(root, ignoreList, func(_ string, info os.FileInfo) bool {
+		switch info.Name() {
+		case "go.mod", "go.sum", "go.work":
+			return true
+		}
+		return filepath.Ext(info.Name()) == ".go"
+	})
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, file := range files {
+		meta, err := cm.GetOrUpdateMetadata(file)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, file, meta.Hash)
+	}
+	return cache.Key(parts...), nil
+}
+
+// goVersion returns the version and settings of the Go toolchain used in dir, or "" if they are not known.
+func goVersion(ctx context.Context, dir string) string {
+	cmd := exec.CommandContext(ctx, "go", "env", "GOVERSION", "GOROOT", "GOFLAGS", "GOOS", "GOARCH")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// apVersion returns a hash of the ap executable, which runs the ap analyzers, or "" if it is not known.
+func apVersion() string {
+	hash, err := cache.ExecutableHash()
+	if err != nil {
+		return ""
+	}
+	return hash
+}
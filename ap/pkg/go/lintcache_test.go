@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestAnalysisCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/m\n")
+	write("a.go", "package m\n")
+
+	want := []findings.Finding{{Path: "a.go", Line: 1, Rule: "printf", Message: "bad format", Severity: findings.SeverityError}}
+	runs := 0
+	analyze := func() ([]findings.Finding, error) {
+		runs++
+		return want, nil
+	}
+	lint := func(key ...string) {
+		t.Helper()
+		analyses := newAnalysisCache(root)
+		found, err := analyses.run("go vet", key, analyze)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 1 || found[0] != want[0] {
+			t.Errorf("run() = %v, want %v", found, want)
+		}
+		analyses.save()
+	}
+
+	lint("go1.26", root)
+	lint("go1.26", root)
+	if runs != 1 {
+		t.Errorf("analysis ran %d times over unchanged files, want once", runs)
+	}
+
+	lint("go1.27", root)
+	if runs != 2 {
+		t.Errorf("analysis ran %d times after the Go version changed, want twice", runs)
+	}
+
+	write("a.go", "package m\n\nvar x int\n")
+	lint("go1.27", root)
+	if runs != 3 {
+		t.Errorf("analysis ran %d times after a Go file changed, want 3 times", runs)
+	}
+
+	// Without a version, the analysis cannot be cached.
+	lint("", root)
+	lint("", root)
+	if runs != 5 {
+		t.Errorf("analysis ran %d times without a version, want 5 times", runs)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// MajorVersionsRule is the rule name of the findings of checkMajorVersions.
+const MajorVersionsRule = "majorversions"
+
+// requirement is a direct requirement of a go.mod file on a library.
+type requirement struct {
+	goMod   string
+	line    int
+	library string
+	major   string
+}
+
+// checkMajorVersions reports the libraries that the go.mod files require at more than one major version,
+// with a finding at each of their requirements. Only direct requirements are checked: indirect ones are not
+// used by the module's own code, so their types cannot be mixed with those of another major version.
+// A table of the offending modules and versions is written to w.
+func checkMajorVersions(root string, goMods []string, libraries []string, sev findings.Severity, w io.Writer) ([]findings.Finding, error) {
+	checked := map[string]bool{}
+	for _, library := range libraries {
+		checked[libraryPath(library)] = true
+	}
+
+	byLibrary := map[string][]requirement{}
+	for _, goMod := range goMods {
+		data, err := os.ReadFile(goMod)
+		if err != nil {
+			return nil, err
+		}
+		f, err := modfile.ParseLax(goMod, data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", goMod, err)
+		}
+		for _, req := range f.Require {
+			library := libraryPath(req.Mod.Path)
+			if req.Indirect || !checked[library] {
+				continue
+			}
+			byLibrary[library] = append(byLibrary[library], requirement{
+				goMod:   goMod,
+				line:    req.Syntax.Start.Line,
+				library: library,
+				major:   semver.Major(req.Mod.Version),
+			})
+		}
+	}
+
+	var mixed []string
+	for library, reqs := range byLibrary {
+		majors := map[string]bool{}
+		for _, req := range reqs {
+			majors[req.major] = true
+		}
+		if len(majors) > 1 {
+			mixed = append(mixed, library)
+		}
+	}
+	if len(mixed) == 0 {
+		return nil, nil
+	}
+	slices.Sort(mixed)
+
+	var found []findings.Finding
+	fmt.Fprintln(w, "Libraries required at more than one major version:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LIBRARY\tVERSION\tMODULE")
+	for _, library := range mixed {
+		reqs := byLibrary[library]
+		slices.SortFunc(reqs, func(a, b requirement) int {
+			return cmp.Or(semver.Compare(a.major, b.major), strings.Compare(a.goMod, b.goMod))
+		})
+
+		var versions []string
+		for _, req := range reqs {
+			versions = append(versions, fmt.Sprintf("%s in %s", req.major, relPath(root, req.goMod)))
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", library, req.major, relPath(root, req.goMod))
+		}
+		for _, req := range reqs {
+			found = append(found, findings.Finding{
+				Path:     req.goMod,
+				Line:     req.line,
+				Rule:     MajorVersionsRule,
+				Message:  fmt.Sprintf("%s must be required at a single major version across modules, but is required at %s", library, strings.Join(versions, ", ")),
+				Severity: sev,
+			})
+		}
+	}
+	tw.Flush()
+	return found, nil
+}
+
+// libraryPath returns path without its major version suffix, e.g. github.com/google/go-github for
+// github.com/google/go-github/v81, and gopkg.in/yaml for gopkg.in/yaml.v3.
+func libraryPath(path string) string {
+	if prefix, _, ok := module.SplitPathVersion(path); ok {
+		return prefix
+	}
+	return path
+}
+
+// relPath returns path relative to root, or path itself if it is not under root.
+func relPath(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestCheckMajorVersions(t *testing.T) {
+	root := t.TempDir()
+	goMods := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.24
+
+require (
+	github.com/google/go-github/v81 v81.0.0
+	gopkg.in/yaml.v3 v3.0.1
+	k8s.io/klog/v2 v2.130.1
+)
+`,
+		"tools/go.mod": `module example.com/app/tools
+
+go 1.24
+
+require github.com/google/go-github/v60 v60.0.0
+
+require (
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	k8s.io/klog/v2 v2.100.0
+)
+`,
+	}
+	var paths []string
+	for relPath, content := range goMods {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	var table strings.Builder
+	found, err := checkMajorVersions(root, paths, []string{"github.com/google/go-github", "gopkg.in/yaml", "k8s.io/klog"}, findings.SeverityError, &table)
+	if err != nil {
+		t.Fatalf("checkMajorVersions() error = %v", err)
+	}
+
+	// The indirect requirement on yaml.v2 and the two v2 requirements on klog are fine.
+	var got []string
+	for _, f := range found {
+		rel, _ := filepath.Rel(root, f.Path)
+		got = append(got, fmt.Sprintf("%s:%d", rel, f.Line))
+		if !strings.Contains(f.Message, "required at v60 in tools/go.mod, v81 in go.mod") {
+			t.Errorf("finding message = %q, want it to list the versions", f.Message)
+		}
+	}
+	want := []string{"tools/go.mod:5", "go.mod:6"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("findings at %v, want %v", got, want)
+	}
+
+	wantTable := `Libraries required at more than one major version:
+LIBRARY                      VERSION  MODULE
+github.com/google/go-github  v60      tools/go.mod
+github.com/google/go-github  v81      go.mod
+`
+	if table.String() != wantTable {
+		t.Errorf("table =\n%s\nwant:\n%s", table.String(), wantTable)
+	}
+}
+
+func TestLibraryPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"github.com/google/go-github/v81": "github.com/google/go-github",
+		"gopkg.in/yaml.v3":                "gopkg.in/yaml",
+		"k8s.io/klog":                     "k8s.io/klog",
+		"sigs.k8s.io/yaml":                "sigs.k8s.io/yaml",
+	} {
+		if got := libraryPath(path); got != want {
+			t.Errorf("libraryPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
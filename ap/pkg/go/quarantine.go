@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// QuarantineRule is the rule of the findings for expired quarantine entries.
+const QuarantineRule = "quarantine"
+
+// QuarantineConfig is the contents of .ap/quarantine.yaml.
+type QuarantineConfig struct {
+	// Tests are the tests that ap test skips until they expire.
+	Tests []QuarantinedTest `json:"tests,omitempty"`
+
+	// lines are the lines of the entries of Tests in the file, for findings.
+	lines []int
+}
+
+// QuarantinedTest is a known-bad test that ap test skips, instead of it being commented out.
+type QuarantinedTest struct {
+	// Package is the directory of the package of the test, relative to the ap root (e.g. ap/pkg/k8s).
+	Package string `json:"package"`
+	// Test is the name of a top-level test function (e.g. TestDeploy); its subtests are skipped with it.
+	Test string `json:"test"`
+	// Owner is who is fixing the test.
+	Owner string `json:"owner"`
+	// Issue is the link to the issue tracking the fix.
+	Issue string `json:"issue"`
+	// Expires is the last day (YYYY-MM-DD) the test is skipped; after it, the test runs again and ap lint fails.
+	Expires string `json:"expires"`
+}
+
+// testNameRegex matches the name of a top-level test function.
+var testNameRegex = regexp.MustCompile(`^(Test|Example|Fuzz)\w*$`)
+
+// LoadQuarantine loads .ap/quarantine.yaml from root, returning an empty config if it does not exist.
+func LoadQuarantine(root string) (*QuarantineConfig, error) {
+	configFile := filepath.Join(root, ".ap", "quarantine.yaml")
+
+	var config QuarantineConfig
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := sigsyaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	for i, t := range config.Tests {
+		switch {
+		case t.Package == "" || t.Test == "" || t.Owner == "" || t.Issue == "" || t.Expires == "":
+			return nil, fmt.Errorf("error in %s: tests[%d]: package, test, owner, issue and expires are required", configFile, i)
+		case !testNameRegex.MatchString(t.Test):
+			return nil, fmt.Errorf("error in %s: tests[%d]: test must be the name of a top-level test function, not %q", configFile, i, t.Test)
+		case filepath.IsAbs(t.Package) || strings.HasPrefix(filepath.Clean(t.Package), ".."):
+			return nil, fmt.Errorf("error in %s: tests[%d]: package must be a directory relative to the ap root, not %q", configFile, i, t.Package)
+		}
+		if _, err := time.Parse(time.DateOnly, t.Expires); err != nil {
+			return nil, fmt.Errorf("error in %s: tests[%d]: expires must be a date (YYYY-MM-DD), not %q", configFile, i, t.Expires)
+		}
+	}
+
+	// The lines of the entries are only used for findings, so a file that yaml.v3 cannot parse has none.
+	var doc struct {
+		Tests []yaml.Node `yaml:"tests"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err == nil {
+		for _, node := range doc.Tests {
+			config.lines = append(config.lines, node.Line)
+		}
+	}
+	return &config, nil
+}
+
+// Expired reports whether the quarantine of t has expired at now: it ends with the day of Expires, in UTC.
+func (t *QuarantinedTest) Expired(now time.Time) bool {
+	expires, err := time.Parse(time.DateOnly, t.Expires)
+	return err == nil && !now.UTC().Before(expires.AddDate(0, 0, 1))
+}
+
+// String returns a one-line description of the entry.
+func (t *QuarantinedTest) String() string {
+	return fmt.Sprintf("%s %s (owner %s, %s, expires %s)", t.Package, t.Test, t.Owner, t.Issue, t.Expires)
+}
+
+// Active returns the entries that have not expired at now.
+func (c *QuarantineConfig) Active(now time.Time) []QuarantinedTest {
+	var active []QuarantinedTest
+	for _, t := range c.Tests {
+		if !t.Expired(now) {
+			active = append(active, t)
+		}
+	}
+	return active
+}
+
+// CheckQuarantine returns an error finding for each entry of the .ap/quarantine.yaml of root that has expired at
+// now: the test runs again, and either passes and should be removed from the file, or needs a new expiry date.
+func CheckQuarantine(root string, now time.Time) ([]findings.Finding, error) {
+	cfg, err := LoadQuarantine(root)
+	if err != nil {
+		return nil, err
+	}
+	var found []findings.Finding
+	for i, t := range cfg.Tests {
+		if !t.Expired(now) {
+			continue
+		}
+		f := findings.Finding{
+			Path:     filepath.Join(root, ".ap", "quarantine.yaml"),
+			Rule:     QuarantineRule,
+			Message:  fmt.Sprintf("the quarantine of %s in %s expired on %s; fix the test and remove the entry, or extend it (owner %s, %s)", t.Test, t.Package, t.Expires, t.Owner, t.Issue),
+			Severity: findings.SeverityError,
+		}
+		if i < len(cfg.lines) {
+			f.Line = cfg.lines[i]
+		}
+		found = append(found, f)
+	}
+	return found, nil
+}
+
+// skipPatterns returns the -skip pattern for each package (as a pattern relative to the module, see packagePattern)
+// with tests quarantined in the module whose path relative to the ap root is rel.
+func skipPatterns(tests []QuarantinedTest, rel string) map[string]string {
+	byPackage := map[string][]string{}
+	for _, t := range tests {
+		pkgRel, err := filepath.Rel(rel, filepath.Clean(t.Package))
+		if err != nil || pkgRel == ".." || strings.HasPrefix(pkgRel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		pkg := packagePattern(pkgRel)
+		byPackage[pkg] = append(byPackage[pkg], regexp.QuoteMeta(t.Test))
+	}
+	patterns := map[string]string{}
+	for pkg, names := range byPackage {
+		sort.Strings(names)
+		patterns[pkg] = "^(" + strings.Join(names, "|") + ")$"
+	}
+	return patterns
+}
+
+// writeQuarantineSummary lists the quarantined tests, and the expired entries whose tests ran again, to w.
+func writeQuarantineSummary(w io.Writer, cfg *QuarantineConfig, now time.Time) {
+	var active, expired []string
+	for _, t := range cfg.Tests {
+		if t.Expired(now) {
+			expired = append(expired, t.String())
+		} else {
+			active = append(active, t.String())
+		}
+	}
+	if len(active) > 0 {
+		fmt.Fprintf(w, "Quarantined tests (skipped, see .ap/quarantine.yaml):\n  %s\n", strings.Join(active, "\n  "))
+	}
+	if len(expired) > 0 {
+		fmt.Fprintf(w, "Expired quarantine entries (the tests ran again; ap lint fails until they are removed or extended):\n  %s\n", strings.Join(expired, "\n  "))
+	}
+}
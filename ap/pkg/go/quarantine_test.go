@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeQuarantine(t *testing.T, root string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".ap", "quarantine.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const quarantineYAML = `tests:
+- package: pkg/flaky
+  test: TestFlaky
+  owner: alice
+  issue: https://example.com/issues/1
+  expires: "2026-03-01"
+- package: pkg/old
+  test: TestOld
+  owner: bob
+  issue: https://example.com/issues/2
+  expires: "2026-01-31"
+`
+
+func TestLoadQuarantine(t *testing.T) {
+	cfg, err := LoadQuarantine(t.TempDir())
+	if err != nil || len(cfg.Tests) != 0 {
+		t.Fatalf("LoadQuarantine() without a file = %+v, %v, want an empty config", cfg, err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: quarantineYAML},
+		{name: "missing owner", content: "tests:\n- package: pkg\n  test: TestA\n  issue: x\n  expires: \"2026-01-01\"\n", wantErr: "owner, issue and expires are required"},
+		{name: "subtest", content: "tests:\n- package: pkg\n  test: TestA/sub\n  owner: a\n  issue: x\n  expires: \"2026-01-01\"\n", wantErr: "top-level test function"},
+		{name: "outside root", content: "tests:\n- package: ../pkg\n  test: TestA\n  owner: a\n  issue: x\n  expires: \"2026-01-01\"\n", wantErr: "relative to the ap root"},
+		{name: "bad date", content: "tests:\n- package: pkg\n  test: TestA\n  owner: a\n  issue: x\n  expires: next week\n", wantErr: "must be a date"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeQuarantine(t, root, tc.content)
+			_, err := LoadQuarantine(root)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadQuarantine() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadQuarantine() error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckQuarantine(t *testing.T) {
+	root := t.TempDir()
+	writeQuarantine(t, root, quarantineYAML)
+
+	// An entry expires at the end of its day.
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	found, err := CheckQuarantine(root, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Line != 7 || found[0].Rule != QuarantineRule || !strings.Contains(found[0].Message, "TestOld in pkg/old expired on 2026-01-31") {
+		t.Errorf("CheckQuarantine() = %+v, want one finding for TestOld at line 7", found)
+	}
+
+	cfg, err := LoadQuarantine(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active := cfg.Active(now.Add(-time.Second)); len(active) != 2 {
+		t.Errorf("Active() on the last day = %+v, want both entries", active)
+	}
+}
+
+func TestSkipPatterns(t *testing.T) {
+	tests := []QuarantinedTest{
+		{Package: "tools/cmd", Test: "TestB"},
+		{Package: "tools/cmd", Test: "TestA"},
+		{Package: "tools", Test: "TestRoot"},
+		{Package: "other", Test: "TestOther"},
+	}
+	got := skipPatterns(tests, "tools")
+	want := map[string]string{"./cmd": "^(TestA|TestB)$", ".": "^(TestRoot)$"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("skipPatterns() = %v, want %v", got, want)
+	}
+}
+
+func TestTestSkipsQuarantinedTests(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":              "module example.com/q\n\ngo 1.22\n",
+		"a/a_test.go":         "package a\n\nimport \"testing\"\n\nfunc TestBroken(t *testing.T) { t.Fatal(\"broken\") }\n\nfunc TestFine(t *testing.T) {}\n",
+		"b/b_test.go":         "package b\n\nimport \"testing\"\n\nfunc TestBroken(t *testing.T) { t.Fatal(\"broken too\") }\n",
+		".ap/quarantine.yaml": "tests:\n- package: a\n  test: TestBroken\n  owner: alice\n  issue: https://example.com/issues/1\n  expires: \"2999-01-01\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The quarantine only applies to the package it names.
+	if err := Test(t.Context(), root); err == nil {
+		t.Fatal("Test() succeeded, want b.TestBroken to fail")
+	}
+	results, err := LoadResults(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range results {
+		if r.Test != "" {
+			got = append(got, r.String()[:4]+" "+r.Package+" "+r.Test)
+		}
+	}
+	want := []string{"PASS example.com/q/a TestFine", "FAIL example.com/q/b TestBroken"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("test results = %v, want %v", got, want)
+	}
+}
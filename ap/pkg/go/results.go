@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TestResult is the outcome of a single test (or of a package, if Test is empty)
+// recorded in the go test -json output under .build/test-results/go.
+type TestResult struct {
+	// Dir is the module directory the test was run from.
+	Dir     string
+	Package string
+	Test    string
+	// Action is the final status: "pass", "fail" or "skip".
+	Action  string
+	Elapsed float64
+	Output  []string
+}
+
+// String returns a one-line description of the result.
+func (r *TestResult) String() string {
+	name := r.Test
+	if name == "" {
+		name = "(package)"
+	}
+	return fmt.Sprintf("%s %s %s (%.2fs)", strings.ToUpper(r.Action), r.Package, name, r.Elapsed)
+}
+
+// LoadResults reads the go test results recorded by Test under root.
+// Results are sorted by package and test name.
+func LoadResults(root string) ([]*TestResult, error) {
+	resultsDir := filepath.Join(root, ".build", "test-results", "go")
+	var results []*TestResult
+	err := filepath.WalkDir(resultsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		// Test writes the results for the module in <root>/<rel> to <rel>.json ("root.json" for root itself).
+		rel, err := filepath.Rel(resultsDir, strings.TrimSuffix(path, ".json"))
+		if err != nil {
+			return err
+		}
+		dir := root
+		if rel != "root" {
+			dir = filepath.Join(root, rel)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fileResults, err := parseTestEvents(f, dir)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		results = append(results, fileResults...)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no test results found in %s; run ap test first", resultsDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Package != results[j].Package {
+			return results[i].Package < results[j].Package
+		}
+		return results[i].Test < results[j].Test
+	})
+	return results, nil
+}
+
+// parseTestEvents aggregates a go test -json stream into per-test results.
+func parseTestEvents(r io.Reader, dir string) ([]*TestResult, error) {
+	type key struct{ pkg, test string }
+	byKey := make(map[key]*TestResult)
+	var order []key
+
+	get := func(pkg, test string) *TestResult {
+		k := key{pkg, test}
+		result, ok := byKey[k]
+		if !ok {
+			result = &TestResult{Dir: dir, Package: pkg, Test: test}
+			byKey[k] = result
+			order = append(order, k)
+		}
+		return result
+	}
+
+	decoder := json.NewDecoder(r)
+	for {
+		var event testEvent
+		err := decoder.Decode(&event)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch event.Action {
+		case "output":
+			result := get(event.Package, event.Test)
+			result.Output = append(result.Output, event.Output)
+		case "build-output":
+			// Build failures are reported against the package that failed to build.
+			pkg := strings.TrimSuffix(strings.Fields(event.ImportPath + " ")[0], ".test")
+			result := get(pkg, "")
+			result.Output = append(result.Output, event.Output)
+		case "pass", "fail", "skip":
+			result := get(event.Package, event.Test)
+			result.Action = event.Action
+			result.Elapsed = event.Elapsed
+		}
+	}
+
+	var results []*TestResult
+	for _, k := range order {
+		if result := byKey[k]; result.Action != "" {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// Failures returns the failed results, omitting tests that failed only because a subtest failed.
+func Failures(results []*TestResult) []*TestResult {
+	var failures []*TestResult
+	for _, r := range results {
+		if r.Action != "fail" {
+			continue
+		}
+		hasFailedChild := false
+		for _, other := range results {
+			if other != r && other.Action == "fail" && other.Package == r.Package && isChildTest(r.Test, other.Test) {
+				hasFailedChild = true
+				break
+			}
+		}
+		if !hasFailedChild {
+			failures = append(failures, r)
+		}
+	}
+	return failures
+}
+
+// isChildTest returns true if child is a subtest of parent; every test is a child of the package ("").
+func isChildTest(parent, child string) bool {
+	if parent == "" {
+		return child != ""
+	}
+	return strings.HasPrefix(child, parent+"/")
+}
+
+// runPattern returns the go test -run pattern matching exactly the given test.
+func runPattern(test string) string {
+	var parts []string
+	for _, part := range strings.Split(test, "/") {
+		parts = append(parts, "^"+regexp.QuoteMeta(part)+"$")
+	}
+	return strings.Join(parts, "/")
+}
+
+// Rerun runs the test (or package) again, returning the updated result.
+// The captured output of the test is written to out.
+func Rerun(ctx context.Context, result *TestResult, out io.Writer) (*TestResult, error) {
+	args := []string{"test", "-json", "-count=1"}
+	if result.Test != "" {
+		args = append(args, "-run", runPattern(result.Test))
+	}
+	args = append(args, result.Package)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = result.Dir
+	cmd.Stderr = out
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	results, parseErr := parseTestEvents(stdout, result.Dir)
+	// A non-zero exit just means the test failed, which is reflected in the results.
+	_ = cmd.Wait()
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to parse go test output: %w", parseErr)
+	}
+
+	for _, r := range results {
+		if r.Package == result.Package && r.Test == result.Test {
+			for _, line := range r.Output {
+				fmt.Fprint(out, line)
+			}
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no result for %s in go test output", result.String())
+}
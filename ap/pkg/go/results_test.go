@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testEvents = `{"Action":"run","Package":"example.com/a","Test":"TestOK"}
+{"Action":"output","Package":"example.com/a","Test":"TestOK","Output":"=== RUN   TestOK\n"}
+{"Action":"pass","Package":"example.com/a","Test":"TestOK","Elapsed":0.1}
+{"Action":"run","Package":"example.com/a","Test":"TestParent"}
+{"Action":"run","Package":"example.com/a","Test":"TestParent/sub"}
+{"Action":"output","Package":"example.com/a","Test":"TestParent/sub","Output":"    a_test.go:10: boom\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestParent/sub","Elapsed":0.2}
+{"Action":"fail","Package":"example.com/a","Test":"TestParent","Elapsed":0.2}
+{"Action":"fail","Package":"example.com/a","Elapsed":0.5}
+{"ImportPath":"example.com/b [example.com/b.test]","Action":"build-output","Output":"b.go:3:1: syntax error\n"}
+{"Action":"fail","Package":"example.com/b","Elapsed":0}
+`
+
+func TestParseTestEvents(t *testing.T) {
+	results, err := parseTestEvents(strings.NewReader(testEvents), "/src")
+	if err != nil {
+		t.Fatalf("parseTestEvents failed: %v", err)
+	}
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"PASS example.com/a TestOK (0.10s)",
+		"FAIL example.com/a TestParent/sub (0.20s)",
+		"FAIL example.com/a TestParent (0.20s)",
+		"FAIL example.com/a (package) (0.50s)",
+		"FAIL example.com/b (package) (0.00s)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected results:\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if results[4].Dir != "/src" {
+		t.Errorf("expected Dir /src, got %q", results[4].Dir)
+	}
+	if len(results[4].Output) != 1 || results[4].Output[0] != "b.go:3:1: syntax error\n" {
+		t.Errorf("expected build output to be attached to the package, got %q", results[4].Output)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	results, err := parseTestEvents(strings.NewReader(testEvents), "/src")
+	if err != nil {
+		t.Fatalf("parseTestEvents failed: %v", err)
+	}
+
+	var got []string
+	for _, r := range Failures(results) {
+		got = append(got, r.Package+" "+r.Test)
+	}
+	want := []string{"example.com/a TestParent/sub", "example.com/b "}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Failures() = %q, want %q", got, want)
+	}
+}
+
+func TestRunPattern(t *testing.T) {
+	pattern := runPattern("TestFoo/case_1.5")
+	if pattern != `^TestFoo$/^case_1\.5$` {
+		t.Errorf("unexpected pattern %q", pattern)
+	}
+	// Each part of the pattern must match exactly one level of the test name.
+	first := strings.Split(pattern, "/")[0]
+	if regexp.MustCompile(first).MatchString("TestFooBar") {
+		t.Errorf("pattern %q should not match TestFooBar", first)
+	}
+}
+
+func TestLoadResults(t *testing.T) {
+	root := t.TempDir()
+	resultsDir := filepath.Join(root, ".build", "test-results", "go")
+	if err := os.MkdirAll(filepath.Join(resultsDir, "tools"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(resultsDir, "root.json"), []byte(testEvents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	toolsEvents := `{"Action":"pass","Package":"example.com/tools","Test":"TestTool","Elapsed":0}` + "\n"
+	if err := os.WriteFile(filepath.Join(resultsDir, "tools", "lint.json"), []byte(toolsEvents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := LoadResults(root)
+	if err != nil {
+		t.Fatalf("LoadResults failed: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %d", len(results))
+	}
+	last := results[len(results)-1]
+	if last.Package != "example.com/tools" || last.Dir != filepath.Join(root, "tools", "lint") {
+		t.Errorf("unexpected last result %+v", last)
+	}
+	if results[0].Dir != root {
+		t.Errorf("expected results from root.json to have Dir %q, got %q", root, results[0].Dir)
+	}
+
+	if _, err := LoadResults(t.TempDir()); err == nil {
+		t.Errorf("expected an error when there are no results")
+	}
+}
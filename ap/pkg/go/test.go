@@ -17,6 +17,7 @@ package golang
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -40,16 +41,19 @@ type testEvent struct {
 	Output     string    `json:"Output"`
 }
 
-// Test runs go tests in discovered modules.
+// Test runs go tests in discovered modules, skipping the tests quarantined in .ap/quarantine.yaml.
 func Test(ctx context.Context, root string) error {
-	// Find all go.mod files
-	ignoreList := walker.NewIgnoreList([]string{".git", "vendor", "node_modules"})
-	goMods, err := walker.Walk(root, ignoreList, func(_ string, info os.FileInfo) bool {
-		return info.Name() == "go.mod"
-	})
+	goMods, err := findGoMods(root)
+	if err != nil {
+		return err
+	}
+	quarantine, err := LoadQuarantine(root)
 	if err != nil {
 		return err
 	}
+	now := time.Now()
+	active := quarantine.Active(now)
+	defer writeQuarantineSummary(os.Stdout, quarantine, now)
 
 	buildDir := filepath.Join(root, ".build", "test-results", "go")
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
@@ -73,21 +77,89 @@ func Test(ctx context.Context, root string) error {
 		}
 
 		klog.Infof("Running go test in %s", dir)
-		if err := runGoTest(ctx, dir, resultFile); err != nil {
+		if err := testModule(ctx, dir, resultFile, skipPatterns(active, rel)); err != nil {
 			return fmt.Errorf("go test failed in %s: %w", dir, err)
 		}
 	}
 	return nil
 }
 
-func runGoTest(ctx context.Context, dir string, resultFile string) error {
+// testModule runs the tests of the module at dir, writing the go test -json output to resultFile.
+// skip maps the packages with quarantined tests (as ./dir patterns) to the -skip pattern of their tests;
+// those packages are tested on their own, after the others.
+func testModule(ctx context.Context, dir string, resultFile string, skip map[string]string) error {
 	f, err := os.Create(resultFile)
 	if err != nil {
 		return fmt.Errorf("failed to create result file: %w", err)
 	}
 	defer f.Close()
 
-	cmd := exec.CommandContext(ctx, "go", "test", "-json", "./...")
+	if len(skip) == 0 {
+		return runGoTest(ctx, dir, f, "./...")
+	}
+
+	pkgs, err := listPackageDirs(ctx, dir)
+	if err != nil {
+		return err
+	}
+	var others, quarantined []string
+	for _, pkg := range pkgs {
+		if _, ok := skip[pkg]; ok {
+			quarantined = append(quarantined, pkg)
+		} else {
+			others = append(others, pkg)
+		}
+	}
+
+	var errs []error
+	if len(others) > 0 {
+		if err := runGoTest(ctx, dir, f, others...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, pkg := range quarantined {
+		if err := runGoTest(ctx, dir, f, "-skip", skip[pkg], pkg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listPackageDirs returns the packages of the module at dir, as ./dir patterns relative to it.
+func listPackageDirs(ctx context.Context, dir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-f", "{{.Dir}}", "./...")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w", err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		rel, err := filepath.Rel(absDir, line)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, packagePattern(rel))
+	}
+	return pkgs, nil
+}
+
+// findGoMods returns the go.mod files under root.
+func findGoMods(root string) ([]string, error) {
+	ignoreList := walker.NewIgnoreList([]string{".git", "vendor", "node_modules"})
+	return walker.Walk(root, ignoreList, func(_ string, info os.FileInfo) bool {
+		return info.Name() == "go.mod"
+	})
+}
+
+// runGoTest runs go test -json with args in dir, printing the results and writing the events to w.
+func runGoTest(ctx context.Context, dir string, w io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"test", "-json"}, args...)...)
 	cmd.Dir = dir
 
 	stdout, err := cmd.StdoutPipe()
@@ -101,7 +173,7 @@ func runGoTest(ctx context.Context, dir string, resultFile string) error {
 	}
 
 	// Read from stdout, write to file AND process for pretty print
-	tr := io.TeeReader(stdout, f)
+	tr := io.TeeReader(stdout, w)
 	decoder := json.NewDecoder(tr)
 
 	for {
@@ -156,3 +228,11 @@ func runGoTest(ctx context.Context, dir string, resultFile string) error {
 
 	return nil
 }
+
+// packagePattern returns the go package pattern of the directory at rel, relative to the module.
+func packagePattern(rel string) string {
+	if rel == "." {
+		return "."
+	}
+	return "./" + filepath.ToSlash(rel)
+}
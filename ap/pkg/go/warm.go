@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// WarmOptions configures Warm.
+type WarmOptions struct {
+	// Parallelism is the number of modules that are built concurrently (defaults to the number of CPUs).
+	Parallelism int
+}
+
+// Warm populates the Go build cache (GOCACHE) for the discovered modules, by building all packages
+// and compiling (but not running) all tests. It is intended for a CI job that primes a shared cache,
+// so that the subsequent test jobs only need to link and run.
+func Warm(ctx context.Context, root string, opt WarmOptions) error {
+	goMods, err := findGoMods(root)
+	if err != nil {
+		return err
+	}
+
+	parallelism := opt.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+
+	// Keep going past failures, so that one broken module does not leave the cache cold for the others.
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, parallelism)
+	for _, goMod := range goMods {
+		dir := filepath.Dir(goMod)
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := warmModule(ctx, dir); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func warmModule(ctx context.Context, dir string) error {
+	for _, args := range [][]string{
+		{"build", "./..."},
+		{"test", "-run=^$", "./..."},
+	} {
+		klog.Infof("Running go %v in %s", args, dir)
+		cmd := exec.CommandContext(ctx, "go", args...)
+		cmd.Dir = dir
+		// Modules run concurrently, so buffer the output rather than interleaving it.
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			os.Stderr.Write(out.Bytes())
+			return fmt.Errorf("go %s failed in %s: %w", args[0], dir, err)
+		}
+	}
+	return nil
+}
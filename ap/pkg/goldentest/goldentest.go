@@ -0,0 +1,232 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goldentest compares test output against golden files, typically under testdata.
+//
+// Run the tests with -update to rewrite the golden files from the current output:
+//
+//	go test ./ap/pkg/generate/... -update
+package goldentest
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/linediff"
+)
+
+var update = flag.Bool("update", false, "update golden files instead of comparing against them")
+
+// Options configures how output is normalized before comparison.
+type Options struct {
+	replacements []string
+}
+
+// Option is a functional option for CompareFile and CompareDir.
+type Option func(*Options)
+
+// WithReplacement replaces all occurrences of old with new in the output before comparison,
+// for example to strip temporary directories or the current year.
+func WithReplacement(old, new string) Option {
+	return func(o *Options) {
+		o.replacements = append(o.replacements, old, new)
+	}
+}
+
+func buildOptions(opts []Option) *Options {
+	o := &Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Normalize applies the replacements and converts line endings to \n,
+// so that golden files are stable across machines.
+func (o *Options) Normalize(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if len(o.replacements) > 0 {
+		content = strings.NewReplacer(o.replacements...).Replace(content)
+	}
+	return content
+}
+
+// CompareFile compares got against the contents of the golden file at goldenPath.
+// With -update, the golden file is written instead.
+func CompareFile(t testing.TB, goldenPath string, got []byte, opts ...Option) {
+	t.Helper()
+
+	o := buildOptions(opts)
+	gotText := o.Normalize(string(got))
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", goldenPath, err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(gotText), 0644); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", goldenPath, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s (run with -update to create it): %v", goldenPath, err)
+	}
+	wantText := o.Normalize(string(want))
+	if wantText != gotText {
+		t.Errorf("output does not match golden file %s (run with -update to accept):\n%s", goldenPath, Diff(wantText, gotText))
+	}
+}
+
+// snapshotFile is a file in a directory snapshot.
+type snapshotFile struct {
+	content    string
+	executable bool
+}
+
+func readSnapshot(dir string, o *Options) (map[string]snapshotFile, error) {
+	files := make(map[string]snapshotFile)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = snapshotFile{
+			content:    o.Normalize(string(content)),
+			executable: info.Mode()&0111 != 0,
+		}
+		return nil
+	})
+	return files, err
+}
+
+// CompareDir compares every file under gotDir against the tree under goldenDir,
+// reporting missing, extra and changed files (including the executable bit).
+// With -update, goldenDir is replaced with a copy of gotDir.
+func CompareDir(t testing.TB, goldenDir string, gotDir string, opts ...Option) {
+	t.Helper()
+
+	o := buildOptions(opts)
+	got, err := readSnapshot(gotDir, o)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", gotDir, err)
+	}
+
+	if *update {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatalf("failed to remove %s: %v", goldenDir, err)
+		}
+		for relPath, f := range got {
+			p := filepath.Join(goldenDir, filepath.FromSlash(relPath))
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatalf("failed to create directory for %s: %v", p, err)
+			}
+			perm := os.FileMode(0644)
+			if f.executable {
+				perm = 0755
+			}
+			if err := os.WriteFile(p, []byte(f.content), perm); err != nil {
+				t.Fatalf("failed to update golden file %s: %v", p, err)
+			}
+		}
+		return
+	}
+
+	want, err := readSnapshot(goldenDir, o)
+	if err != nil {
+		t.Fatalf("failed to read golden directory %s (run with -update to create it): %v", goldenDir, err)
+	}
+
+	var paths []string
+	for relPath := range want {
+		paths = append(paths, relPath)
+	}
+	for relPath := range got {
+		if _, ok := want[relPath]; !ok {
+			paths = append(paths, relPath)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, relPath := range paths {
+		w, inWant := want[relPath]
+		g, inGot := got[relPath]
+		switch {
+		case !inGot:
+			t.Errorf("missing file %s (expected by %s)", relPath, goldenDir)
+		case !inWant:
+			t.Errorf("unexpected file %s (not in %s)", relPath, goldenDir)
+		default:
+			if w.content != g.content {
+				t.Errorf("file %s does not match golden file in %s (run with -update to accept):\n%s", relPath, goldenDir, Diff(w.content, g.content))
+			}
+			if w.executable != g.executable {
+				t.Errorf("file %s: executable=%v, golden file has executable=%v", relPath, g.executable, w.executable)
+			}
+		}
+	}
+}
+
+// Diff returns a line-based diff of want and got, with "-" marking lines only in want
+// and "+" marking lines only in got. Long runs of unchanged lines are elided.
+func Diff(want, got string) string {
+	lines := linediff.Compute(strings.Split(want, "\n"), strings.Split(got, "\n"))
+
+	// Only show unchanged lines that are close to a change.
+	show := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.Op == linediff.Equal {
+			continue
+		}
+		for c := max(0, k-linediff.Context); c <= min(len(lines)-1, k+linediff.Context); c++ {
+			show[c] = true
+		}
+	}
+
+	var sb strings.Builder
+	elided := false
+	for k, l := range lines {
+		if !show[k] {
+			if !elided {
+				sb.WriteString("  ...\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		fmt.Fprintf(&sb, "%c %s\n", l.Op, l.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goldentest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "changed line",
+			want: "a\nb\nc",
+			got:  "a\nx\nc",
+			diff: "  a\n- b\n+ x\n  c\n",
+		},
+		{
+			name: "added line",
+			want: "a\nc",
+			got:  "a\nb\nc",
+			diff: "  a\n+ b\n  c\n",
+		},
+		{
+			name: "elides unchanged lines",
+			want: "1\n2\n3\n4\n5\n6\n7\n8\n9",
+			got:  "1\n2\n3\n4\n5\n6\n7\n8\nX",
+			diff: "  ...\n  6\n  7\n  8\n- 9\n+ X\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("Diff() =\n%s\nwant:\n%s", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	o := buildOptions([]Option{WithReplacement("/tmp/xyz", "$ROOT")})
+	got := o.Normalize("path: /tmp/xyz/foo\r\nnext\r\n")
+	want := "path: $ROOT/foo\nnext\n"
+	if got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+}
+
+func TestCompareDir(t *testing.T) {
+	goldenDir := t.TempDir()
+	gotDir := t.TempDir()
+
+	for _, dir := range []string{goldenDir, gotDir} {
+		if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "sub", "script"), []byte("#!/bin/bash\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello\r\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	CompareDir(t, goldenDir, gotDir)
+}
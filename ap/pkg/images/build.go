@@ -16,86 +16,462 @@ package images
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"k8s.io/klog/v2"
 )
 
-// Build builds docker images found in images/<name>/Dockerfile.
-func Build(ctx context.Context, root string, push bool) error {
+// image is a buildable image discovered under an ap root.
+type image struct {
+	// Name is the image name.
+	Name string
+	// Dockerfile is the path to the Dockerfile relative to the root, empty for ko images.
+	Dockerfile string
+	// Config is the configuration from .ap/images.yaml, if any.
+	Config *ImageConfig
+}
+
+func (i *image) builder() string {
+	if i.Config != nil && i.Config.Builder != "" {
+		return i.Config.Builder
+	}
+	return BuilderDocker
+}
+
+// koBase returns the base image of a ko image.
+func (i *image) koBase() string {
+	if i.Config != nil && i.Config.Base != "" {
+		return i.Config.Base
+	}
+	return DefaultKoBaseImage
+}
+
+// Build builds docker images found in images/<name>/Dockerfile,
+// and images declared in .ap/images.yaml.
+// If push is true, the images are pushed and the returned map holds the pushed digest
+// (e.g. sha256:...) for each image name; the digests are also recorded in .build/images/digests.json.
+// If the images are built reproducibly, base images that are not pinned yet are recorded in .ap/images.lock.json.
+func Build(ctx context.Context, root string, push bool) (map[string]string, error) {
 	imagePrefix := os.Getenv("IMAGE_PREFIX")
 	if push && imagePrefix == "" {
-		return fmt.Errorf("IMAGE_PREFIX is not set; it is required for pushing images")
+		return nil, fmt.Errorf("IMAGE_PREFIX is not set; it is required for pushing images")
 	}
 	tag := os.Getenv("IMAGE_TAG")
 	if tag == "" {
 		tag = "latest"
 	}
 
-	dockerfiles, err := findDockerfiles(root)
+	cfg, err := LoadConfig(root)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	images, err := findImages(root)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, dockerfile := range dockerfiles {
-		relPath, err := filepath.Rel(root, dockerfile)
-		if err != nil {
-			continue
+	vars := newTemplateVars(ctx, root, tag)
+	var repro *reproducibility
+	if cfg.Reproducible {
+		vars.reproducible = true
+		if repro, err = newReproducibility(ctx, root, images, vars, true); err != nil {
+			return nil, err
 		}
+	}
 
-		name := getImageName(relPath)
-		if name == "" {
+	// The cache records the digest pushed for each build context, so unchanged images are not rebuilt.
+	cm, err := cache.NewManager(root)
+	if err != nil {
+		klog.V(2).Infof("Failed to initialize cache: %v", err)
+		cm = nil
+	} else {
+		defer func() {
+			if err := cm.Save(); err != nil {
+				klog.Warningf("Failed to save cache: %v", err)
+			}
+		}()
+	}
+
+	digests := make(map[string]string)
+	for _, img := range images {
+		fullImageName := imageRef(imagePrefix, img.Name, tag)
+
+		// Expand templated values first, so that they are part of the cache key.
+		if img.Config != nil {
+			cfg, err := vars.expandConfig(img.Config)
+			if err != nil {
+				return nil, err
+			}
+			img.Config = cfg
+		}
+
+		var cacheKey string
+		if push && cm != nil {
+			ctxHash, err := contextHash(root, img.Dockerfile, cm)
+			if err != nil {
+				return nil, err
+			}
+			if repro != nil {
+				ctxHash = cache.Key(ctxHash, repro.cacheKey())
+			}
+			cacheKey, err = buildCacheKey(img, fullImageName, ctxHash)
+			if err != nil {
+				return nil, err
+			}
+			if digest, ok := cm.GetImageDigest(cacheKey); ok && remoteDigestMatches(ctx, fullImageName, digest) {
+				klog.Infof("Image %s is up to date (%s), skipping build", fullImageName, digest)
+				digests[img.Name] = digest
+				continue
+			}
+		}
+
+		// The build metadata is not part of the cache key: an unchanged image keeps the metadata it was built with.
+		metadata := vars.buildMetadata()
+
+		var digest string
+		switch img.builder() {
+		case BuilderKo:
+			klog.Infof("Building image %s with ko from %s", fullImageName, root)
+			digest, err = buildKo(ctx, root, img.Config, fullImageName, metadata.LDFlags(), repro, push)
+			if err != nil {
+				return nil, fmt.Errorf("ko build failed for %s: %w", img.Name, err)
+			}
+		default:
+			ok, err := tools.Require(ctx, tools.Requirement{Tool: "docker", Task: "build image " + img.Name})
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			digest, err = buildDocker(ctx, root, img, fullImageName, metadata, repro, push)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if digest == "" {
+			if push {
+				klog.Warningf("Could not determine digest of %s; it will be deployed by tag", fullImageName)
+			}
 			continue
 		}
+		digests[img.Name] = digest
+		if cacheKey != "" {
+			cm.SetImageDigest(cacheKey, digest)
+		}
+	}
 
-		var fullImageName string
-		if imagePrefix != "" {
-			fullImageName = fmt.Sprintf("%s/%s:%s", imagePrefix, name, tag)
-		} else {
-			fullImageName = fmt.Sprintf("%s:%s", name, tag)
+	if push {
+		if err := writeDigests(root, digests); err != nil {
+			return nil, err
 		}
+	}
+	return digests, nil
+}
+
+// imageRef returns the reference an image is built as.
+func imageRef(imagePrefix string, name string, tag string) string {
+	if imagePrefix != "" {
+		return fmt.Sprintf("%s/%s:%s", imagePrefix, name, tag)
+	}
+	return fmt.Sprintf("%s:%s", name, tag)
+}
+
+// LocalImage is an image built by Build without pushing.
+type LocalImage struct {
+	Name string
+
+	// Ref is the image reference.
+	Ref string
+
+	// Archive is the image tarball, for images that are not loaded into the local docker daemon (ko images).
+	// If empty, the image is in the docker daemon as Ref.
+	Archive string
+}
+
+// LocalImages returns the images under root as they are built by Build when not pushing.
+func LocalImages(root string) ([]LocalImage, error) {
+	tag := os.Getenv("IMAGE_TAG")
+	if tag == "" {
+		tag = "latest"
+	}
+
+	images, err := findImages(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var local []LocalImage
+	for _, img := range images {
+		localImage := LocalImage{
+			Name: img.Name,
+			Ref:  imageRef(os.Getenv("IMAGE_PREFIX"), img.Name, tag),
+		}
+		if img.builder() == BuilderKo {
+			localImage.Archive = filepath.Join(root, ".build", "images", img.Name+".tar")
+		}
+		local = append(local, localImage)
+	}
+	return local, nil
+}
+
+// writeDigests records the pushed digests in .build/images/digests.json,
+// so that tooling outside of ap (e.g. GitOps pipelines) can pin the images.
+func writeDigests(root string, digests map[string]string) error {
+	outDir := filepath.Join(root, ".build", "images")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+	data, err := json.MarshalIndent(digests, "", "  ")
+	if err != nil {
+		return err
+	}
+	outFile := filepath.Join(outDir, "digests.json")
+	if err := os.WriteFile(outFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outFile, err)
+	}
+	return nil
+}
+
+// remoteDigestMatches returns true if the image in the registry still has the expected digest.
+func remoteDigestMatches(ctx context.Context, fullImageName string, digest string) bool {
+	ref, err := name.ParseReference(fullImageName)
+	if err != nil {
+		return false
+	}
+	desc, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		klog.V(2).Infof("Could not check remote digest for %s: %v", fullImageName, err)
+		return false
+	}
+	return desc.Digest.String() == digest
+}
 
-		klog.Infof("Building image %s from %s", fullImageName, root)
-		args := []string{"buildx", "build", "-t", fullImageName, "-f", relPath}
-		if push {
+// buildDocker builds the image with docker buildx, returning the pushed digest if push is true.
+// If repro is set, the image is built reproducibly.
+func buildDocker(ctx context.Context, root string, img *image, fullImageName string, meta buildMetadata, repro *reproducibility, push bool) (string, error) {
+	args, err := dockerBuildxArgs(root, img, fullImageName, meta, repro)
+	if err != nil {
+		return "", err
+	}
+
+	klog.Infof("Building image %s from %s", fullImageName, root)
+	var metadataFile string
+	if push {
+		if repro != nil {
+			// --push, with the timestamps of the files in the layers rewritten to SOURCE_DATE_EPOCH.
+			args = append(args, "--output", "type=image,push=true,rewrite-timestamp=true")
+		} else {
 			args = append(args, "--push")
 		}
-		args = append(args, ".")
 
-		cmd := exec.CommandContext(ctx, "docker", args...)
-		cmd.Dir = root
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("docker build failed for %s: %w", name, err)
+		tmpDir, err := os.MkdirTemp("", "ap-build-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp dir: %w", err)
 		}
+		defer os.RemoveAll(tmpDir)
+		metadataFile = filepath.Join(tmpDir, "metadata.json")
+		args = append(args, "--metadata-file", metadataFile)
+	} else if repro != nil {
+		args = append(args, "--output", "type=docker,rewrite-timestamp=true")
+	}
+	args = append(args, ".")
+
+	if err := runDockerBuildx(ctx, root, img, args); err != nil {
+		return "", err
+	}
+	if metadataFile == "" {
+		return "", nil
+	}
+	return readBuiltDigest(img, metadataFile), nil
+}
+
+// dockerBuildxArgs returns the docker buildx build arguments of img, other than the output and the context.
+func dockerBuildxArgs(root string, img *image, fullImageName string, meta buildMetadata, repro *reproducibility) ([]string, error) {
+	if img.Dockerfile == "" {
+		return nil, fmt.Errorf("image %s has no Dockerfile (expected images/%s/Dockerfile)", img.Name, img.Name)
+	}
+
+	dockerfile, err := os.ReadFile(filepath.Join(root, img.Dockerfile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", img.Dockerfile, err)
+	}
+
+	args := []string{"buildx", "build", "-t", fullImageName, "-f", img.Dockerfile}
+	args = append(args, metadataBuildArgs(string(dockerfile), img.Config, meta)...)
+	args = append(args, dockerBuildArgs(img.Config)...)
+	if repro != nil {
+		args = append(args, repro.dockerBuildArgs(string(dockerfile))...)
+	}
+	return args, nil
+}
+
+// runDockerBuildx runs docker with args in root, to build img.
+func runDockerBuildx(ctx context.Context, root string, img *image, args []string) error {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := runner.Run(ctx, cmd); err != nil {
+		return fmt.Errorf("docker build failed for %s: %w", img.Name, err)
 	}
 	return nil
 }
 
+// readBuiltDigest returns the digest of img in the metadata file written by docker buildx, or "" if it has none.
+func readBuiltDigest(img *image, metadataFile string) string {
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		klog.Warningf("Could not read build metadata for %s: %v", img.Name, err)
+		return ""
+	}
+	var metadata struct {
+		Digest string `json:"containerimage.digest"`
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		klog.Warningf("Could not parse build metadata for %s: %v", img.Name, err)
+		return ""
+	}
+	return metadata.Digest
+}
+
+// metadataBuildArgs returns the --build-arg flags for the AP_* metadata args declared in dockerfile.
+// Undeclared args are not passed, so that Dockerfiles which do not use them build without warnings,
+// and args set explicitly in the image config take precedence.
+func metadataBuildArgs(dockerfile string, cfg *ImageConfig, metadata buildMetadata) []string {
+	declared := make(map[string]bool)
+	for _, line := range strings.Split(dockerfile, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "ARG") {
+			continue
+		}
+		name, _, _ := strings.Cut(fields[1], "=")
+		declared[name] = true
+	}
+
+	values := metadata.dockerBuildArgs()
+	var args []string
+	for _, k := range sortedKeys(values) {
+		if !declared[k] || values[k] == "" {
+			continue
+		}
+		if cfg != nil {
+			if _, ok := cfg.BuildArgs[k]; ok {
+				continue
+			}
+		}
+		args = append(args, "--build-arg", k+"="+values[k])
+	}
+	return args
+}
+
+// dockerBuildArgs returns the docker buildx flags for the options in cfg, which may be nil.
+func dockerBuildArgs(cfg *ImageConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	var args []string
+	for _, k := range sortedKeys(cfg.BuildArgs) {
+		args = append(args, "--build-arg", k+"="+cfg.BuildArgs[k])
+	}
+	if cfg.Target != "" {
+		args = append(args, "--target", cfg.Target)
+	}
+	for _, k := range sortedKeys(cfg.Labels) {
+		args = append(args, "--label", k+"="+cfg.Labels[k])
+	}
+	for _, secret := range cfg.Secrets {
+		args = append(args, "--secret", secret)
+	}
+	for _, ssh := range cfg.SSH {
+		args = append(args, "--ssh", ssh)
+	}
+	return args
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // HasImages returns true if there are any images to build under root.
 func HasImages(root string) (bool, error) {
-	dockerfiles, err := findDockerfiles(root)
+	images, err := findImages(root)
 	if err != nil {
 		return false, err
 	}
+	return len(images) > 0, nil
+}
 
+// findImages returns the images under root: those with an images/<name>/Dockerfile,
+// plus any declared in .ap/images.yaml. Images are sorted by name.
+func findImages(root string) ([]*image, error) {
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerfiles, err := findDockerfiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*image)
 	for _, dockerfile := range dockerfiles {
 		relPath, err := filepath.Rel(root, dockerfile)
 		if err != nil {
 			continue
 		}
-		if getImageName(relPath) != "" {
-			return true, nil
+
+		name := getImageName(relPath)
+		if name == "" {
+			continue
+		}
+		byName[name] = &image{
+			Name:       name,
+			Dockerfile: relPath,
+			Config:     cfg.Get(name),
 		}
 	}
 
-	return false, nil
+	for i := range cfg.Images {
+		imgConfig := &cfg.Images[i]
+		if _, ok := byName[imgConfig.Name]; ok {
+			continue
+		}
+		byName[imgConfig.Name] = &image{
+			Name:   imgConfig.Name,
+			Config: imgConfig,
+		}
+	}
+
+	var images []*image
+	for _, img := range byName {
+		images = append(images, img)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Name < images[j].Name
+	})
+	return images, nil
 }
 
 func findDockerfiles(root string) ([]string, error) {
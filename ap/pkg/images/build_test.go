@@ -17,7 +17,10 @@ package images
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
 )
 
 func TestHasImages(t *testing.T) {
@@ -91,3 +94,159 @@ func TestHasImages(t *testing.T) {
 		})
 	}
 }
+
+func TestFindImagesWithConfig(t *testing.T) {
+	root := t.TempDir()
+
+	os.MkdirAll(filepath.Join(root, ".ap"), 0755)
+	os.MkdirAll(filepath.Join(root, "images", "docker-image"), 0755)
+	os.WriteFile(filepath.Join(root, "images", "docker-image", "Dockerfile"), []byte("FROM scratch"), 0644)
+	os.MkdirAll(filepath.Join(root, "images", "switched"), 0755)
+	os.WriteFile(filepath.Join(root, "images", "switched", "Dockerfile"), []byte("FROM scratch"), 0644)
+
+	config := `
+images:
+- name: switched
+  builder: ko
+  main: ./cmd/switched
+- name: ko-only
+  builder: ko
+  main: ./cmd/ko-only
+  base: gcr.io/distroless/base
+`
+	if err := os.WriteFile(filepath.Join(root, ".ap", "images.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	images, err := findImages(root)
+	if err != nil {
+		t.Fatalf("findImages() error = %v", err)
+	}
+
+	type result struct {
+		name       string
+		builder    string
+		dockerfile string
+	}
+	var got []result
+	for _, img := range images {
+		got = append(got, result{name: img.Name, builder: img.builder(), dockerfile: img.Dockerfile})
+	}
+	want := []result{
+		{name: "docker-image", builder: BuilderDocker, dockerfile: filepath.Join("images", "docker-image", "Dockerfile")},
+		{name: "ko-only", builder: BuilderKo},
+		{name: "switched", builder: BuilderKo, dockerfile: filepath.Join("images", "switched", "Dockerfile")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findImages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name:   "missing name",
+			config: "images:\n- builder: ko\n  main: ./cmd/foo\n",
+		},
+		{
+			name:   "ko without main",
+			config: "images:\n- name: foo\n  builder: ko\n",
+		},
+		{
+			name:   "unknown builder",
+			config: "images:\n- name: foo\n  builder: bazel\n",
+		},
+		{
+			name:   "ko with build args",
+			config: "images:\n- name: foo\n  builder: ko\n  main: ./cmd/foo\n  buildArgs:\n    FOO: bar\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			os.MkdirAll(filepath.Join(root, ".ap"), 0755)
+			if err := os.WriteFile(filepath.Join(root, ".ap", "images.yaml"), []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(root); err == nil {
+				t.Errorf("LoadConfig() expected error")
+			}
+		})
+	}
+}
+
+func TestDockerBuildArgs(t *testing.T) {
+	cfg := &ImageConfig{
+		Name:      "foo",
+		BuildArgs: map[string]string{"VERSION": "v1", "GIT_SHA": "abc"},
+		Target:    "release",
+		Labels:    map[string]string{"org.opencontainers.image.revision": "abc"},
+		Secrets:   []string{"id=npmrc,src=/home/me/.npmrc"},
+		SSH:       []string{"default"},
+	}
+
+	got := dockerBuildArgs(cfg)
+	want := []string{
+		"--build-arg", "GIT_SHA=abc",
+		"--build-arg", "VERSION=v1",
+		"--target", "release",
+		"--label", "org.opencontainers.image.revision=abc",
+		"--secret", "id=npmrc,src=/home/me/.npmrc",
+		"--ssh", "default",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerBuildArgs() = %v, want %v", got, want)
+	}
+
+	if got := dockerBuildArgs(nil); got != nil {
+		t.Errorf("dockerBuildArgs(nil) = %v, want nil", got)
+	}
+}
+
+func TestMetadataBuildArgs(t *testing.T) {
+	dockerfile := "FROM golang\nARG AP_LDFLAGS\narg AP_VERSION=dev\nARG AP_BUILD_DATE\nRUN go build -ldflags \"$AP_LDFLAGS\" .\n"
+	cfg := &ImageConfig{BuildArgs: map[string]string{"AP_VERSION": "custom"}}
+	metadata := buildMetadata{Version: "v1.2.3", GitSHA: "0123abcd"}
+
+	got := metadataBuildArgs(dockerfile, cfg, metadata)
+	// AP_VERSION is set in the config, AP_BUILD_DATE is empty and AP_GIT_SHA is not declared.
+	want := []string{"--build-arg", "AP_LDFLAGS=" + metadata.LDFlags()}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metadataBuildArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildDocker(t *testing.T) {
+	root := t.TempDir()
+	dockerfile := filepath.Join("images", "foo", "Dockerfile")
+	if err := os.MkdirAll(filepath.Join(root, "images", "foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, dockerfile), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	build := []string{"docker", "buildx", "build", "-t", "gcr.io/p/foo:v1", "-f", dockerfile, "--target", "release", "."}
+	replayer := runner.NewReplayer(runner.Invocation{Args: build})
+	ctx := runner.NewContext(t.Context(), replayer)
+	img := &image{Name: "foo", Dockerfile: dockerfile, Config: &ImageConfig{Name: "foo", Target: "release"}}
+
+	if _, err := buildDocker(ctx, root, img, "gcr.io/p/foo:v1", buildMetadata{Version: "v1"}, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	calls := replayer.Calls()
+	if len(calls) != 1 || calls[0].Dir != root {
+		t.Errorf("ran %+v, want docker buildx build in %s", calls, root)
+	}
+
+	// A failed build is reported with the image name.
+	replayer = runner.NewReplayer(runner.Invocation{Args: build, ExitCode: 1})
+	ctx = runner.NewContext(t.Context(), replayer)
+	if _, err := buildDocker(ctx, root, img, "gcr.io/p/foo:v1", buildMetadata{Version: "v1"}, nil, false); err == nil {
+		t.Errorf("buildDocker() succeeded, want the docker failure")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BuilderDocker builds the image from images/<name>/Dockerfile using docker buildx.
+	BuilderDocker = "docker"
+	// BuilderKo compiles a Go binary and layers it onto a base image, without docker.
+	BuilderKo = "ko"
+)
+
+// DefaultKoBaseImage is the base image used by the ko builder when none is configured.
+const DefaultKoBaseImage = "gcr.io/distroless/static:nonroot"
+
+// Config is the contents of .ap/images.yaml.
+type Config struct {
+	Images []ImageConfig `json:"images"`
+
+	// Reproducible builds every image reproducibly: timestamps are taken from SOURCE_DATE_EPOCH (the time of the
+	// commit being built, unless set), base images are pinned by the digests recorded in .ap/images.lock.json,
+	// and the timestamps of the files in the layers are rewritten. ap build --verify-reproducible checks the result.
+	Reproducible bool `json:"reproducible,omitempty"`
+}
+
+// ImageConfig holds the per-image build configuration.
+type ImageConfig struct {
+	// Name is the image name, matching images/<name>/ for Dockerfile builds.
+	Name string `json:"name"`
+
+	// Builder selects how the image is built: "docker" (default) or "ko".
+	Builder string `json:"builder,omitempty"`
+
+	// Main is the Go package to compile for ko builds, e.g. "./cmd/server".
+	Main string `json:"main,omitempty"`
+
+	// Base is the base image for ko builds (defaults to DefaultKoBaseImage).
+	Base string `json:"base,omitempty"`
+
+	// Platform is the os/arch to build for with ko (defaults to linux/amd64).
+	Platform string `json:"platform,omitempty"`
+
+	// BuildArgs are passed to docker buildx as --build-arg.
+	// Values may reference ${GIT_SHA}, ${VERSION}, ${IMAGE_TAG} or environment variables.
+	BuildArgs map[string]string `json:"buildArgs,omitempty"`
+
+	// Target is the Dockerfile stage to build.
+	Target string `json:"target,omitempty"`
+
+	// Labels are set on the image, e.g. org.opencontainers.image.revision: ${GIT_SHA}.
+	// Values are expanded like BuildArgs.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Secrets are passed to docker buildx as --secret, e.g. "id=npmrc,src=${HOME}/.npmrc".
+	Secrets []string `json:"secrets,omitempty"`
+
+	// SSH are passed to docker buildx as --ssh, e.g. "default".
+	SSH []string `json:"ssh,omitempty"`
+}
+
+// LoadConfig loads .ap/images.yaml from root, returning an empty config if it does not exist.
+func LoadConfig(root string) (*Config, error) {
+	configFile := filepath.Join(root, ".ap", "images.yaml")
+
+	var config Config
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	for i := range config.Images {
+		img := &config.Images[i]
+		if img.Name == "" {
+			return nil, fmt.Errorf("error in %s: image %d has no name", configFile, i)
+		}
+		switch img.Builder {
+		case "", BuilderDocker:
+		case BuilderKo:
+			if img.Main == "" {
+				return nil, fmt.Errorf("error in %s: image %q uses the ko builder but has no main package", configFile, img.Name)
+			}
+			if len(img.BuildArgs) > 0 || img.Target != "" || len(img.Secrets) > 0 || len(img.SSH) > 0 {
+				return nil, fmt.Errorf("error in %s: image %q uses the ko builder, which does not support buildArgs, target, secrets or ssh", configFile, img.Name)
+			}
+		default:
+			return nil, fmt.Errorf("error in %s: image %q has unknown builder %q", configFile, img.Name, img.Builder)
+		}
+	}
+
+	return &config, nil
+}
+
+// Get returns the configuration for the named image, or nil if it is not configured.
+func (c *Config) Get(name string) *ImageConfig {
+	for i := range c.Images {
+		if c.Images[i].Name == name {
+			return &c.Images[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+)
+
+// dockerIgnore implements the .dockerignore matching rules:
+// patterns are anchored at the context root, support ** and ! negation,
+// and the last matching pattern wins.
+type dockerIgnore struct {
+	patterns []dockerIgnorePattern
+	// hasNegation is true if any pattern re-includes files, in which case
+	// excluded directories must still be walked.
+	hasNegation bool
+}
+
+type dockerIgnorePattern struct {
+	segments []string
+	negate   bool
+}
+
+func parseDockerIgnore(data []byte) *dockerIgnore {
+	d := &dockerIgnore{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negate := false
+		if strings.HasPrefix(line, "!") {
+			negate = true
+			d.hasNegation = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = path.Clean(strings.TrimPrefix(filepath.ToSlash(line), "/"))
+		if line == "." {
+			continue
+		}
+		d.patterns = append(d.patterns, dockerIgnorePattern{
+			segments: strings.Split(line, "/"),
+			negate:   negate,
+		})
+	}
+	return d
+}
+
+// loadDockerIgnore reads <dockerfile>.dockerignore if present, falling back to .dockerignore in the root.
+func loadDockerIgnore(root string, dockerfile string) (*dockerIgnore, error) {
+	candidates := []string{filepath.Join(root, ".dockerignore")}
+	if dockerfile != "" {
+		candidates = append([]string{filepath.Join(root, dockerfile+".dockerignore")}, candidates...)
+	}
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parseDockerIgnore(data), nil
+	}
+	return &dockerIgnore{}, nil
+}
+
+// Excluded returns true if the path (relative to the context root) is excluded.
+func (d *dockerIgnore) Excluded(relPath string) bool {
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	excluded := false
+	for _, p := range d.patterns {
+		// A pattern matching a parent directory excludes everything below it.
+		for i := 1; i <= len(segments); i++ {
+			if matchIgnoreSegments(p.segments, segments[:i]) {
+				excluded = !p.negate
+				break
+			}
+		}
+	}
+	return excluded
+}
+
+func matchIgnoreSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchIgnoreSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchIgnoreSegments(pattern[1:], segments[1:])
+}
+
+// contextHash computes a hash of the build context rooted at root, respecting .dockerignore.
+// .git and .build (where ap writes its own outputs) are always excluded.
+// File hashes are looked up through the cache manager when available, so unchanged files are not re-read.
+func contextHash(root string, dockerfile string, cm *cache.Manager) (string, error) {
+	ignore, err := loadDockerIgnore(root, dockerfile)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	err = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			if relPath == ".git" || relPath == ".build" {
+				return filepath.SkipDir
+			}
+			if !ignore.hasNegation && ignore.Excluded(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// The Dockerfile is always part of the build, even if ignored.
+		if relPath != dockerfile && ignore.Excluded(relPath) {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			if d.Type()&os.ModeSymlink != 0 {
+				target, err := os.Readlink(p)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(h, "%s\x00symlink:%s\n", filepath.ToSlash(relPath), target)
+			}
+			return nil
+		}
+
+		fileHash, err := hashContextFile(p, cm)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%s\n", filepath.ToSlash(relPath), fileHash)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash build context %s: %w", root, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashContextFile(p string, cm *cache.Manager) (string, error) {
+	if cm != nil {
+		meta, err := cm.GetOrUpdateMetadata(p)
+		if err == nil {
+			return meta.Hash, nil
+		}
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildCacheKey identifies a build of img as fullImageName from the given context hash.
+func buildCacheKey(img *image, fullImageName string, ctxHash string) (string, error) {
+	configJSON, err := json.Marshal(img.Config)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s", fullImageName, img.Dockerfile, configJSON, ctxHash)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
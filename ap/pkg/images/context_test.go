@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDockerIgnoreExcluded(t *testing.T) {
+	ignore := parseDockerIgnore([]byte(`
+# comment
+*.md
+!README.md
+/docs
+**/*.tmp
+build/out
+`))
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"main.go", false},
+		{"CHANGES.md", true},
+		{"README.md", false},
+		{"sub/CHANGES.md", false},
+		{"docs", true},
+		{"docs/guide.txt", true},
+		{"a/b/c.tmp", true},
+		{"c.tmp", true},
+		{"build/out/bin", true},
+		{"build/other", false},
+	}
+
+	for _, tt := range tests {
+		if got := ignore.Excluded(tt.path); got != tt.want {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestContextHash(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFile := func(relPath string, content string) {
+		t.Helper()
+		p := filepath.Join(tmpDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dockerfile := filepath.Join("images", "foo", "Dockerfile")
+	writeFile(dockerfile, "FROM scratch\n")
+	writeFile("main.go", "package main\n")
+	writeFile(".dockerignore", "*.log\n")
+
+	hash := func() string {
+		t.Helper()
+		h, err := contextHash(tmpDir, dockerfile, nil)
+		if err != nil {
+			t.Fatalf("contextHash failed: %v", err)
+		}
+		return h
+	}
+
+	base := hash()
+	if got := hash(); got != base {
+		t.Errorf("contextHash is not stable: %q != %q", got, base)
+	}
+
+	// Ignored files and ap outputs do not affect the hash.
+	writeFile("debug.log", "noise")
+	writeFile(".build/images/foo.tar", "output")
+	writeFile(".git/HEAD", "ref: refs/heads/main")
+	if got := hash(); got != base {
+		t.Errorf("contextHash changed after writing ignored files")
+	}
+
+	// Source changes do.
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	changed := hash()
+	if changed == base {
+		t.Errorf("contextHash did not change after modifying main.go")
+	}
+
+	writeFile(dockerfile, "FROM alpine\n")
+	if got := hash(); got == changed {
+		t.Errorf("contextHash did not change after modifying the Dockerfile")
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"k8s.io/klog/v2"
+)
+
+// koAppDir is where the binary is placed in the image, matching ko's convention.
+const koAppDir = "/ko-app"
+
+// buildKo compiles the configured Go main package and layers the binary onto the base image.
+// If push is true the image is pushed to the registry and its digest is returned,
+// otherwise it is written as a tarball to .build/images/<name>.tar under root.
+// The binary is linked with ldflags, which set the metadata reported by ap/pkg/version.
+// If repro is set, the image is built reproducibly.
+func buildKo(ctx context.Context, root string, img *ImageConfig, fullImageName string, ldflags string, repro *reproducibility, push bool) (string, error) {
+	image, err := koImage(ctx, root, img, ldflags, repro)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := name.ParseReference(fullImageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid image name %q: %w", fullImageName, err)
+	}
+
+	if push {
+		klog.Infof("Pushing image %s", fullImageName)
+		if err := remote.Write(ref, image, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain)); err != nil {
+			return "", fmt.Errorf("failed to push %s: %w", fullImageName, err)
+		}
+		digest, err := image.Digest()
+		if err != nil {
+			return "", fmt.Errorf("failed to compute digest of %s: %w", fullImageName, err)
+		}
+		return digest.String(), nil
+	}
+
+	outDir := filepath.Join(root, ".build", "images")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+	outFile := filepath.Join(outDir, img.Name+".tar")
+	klog.Infof("Writing image %s to %s", fullImageName, outFile)
+	if err := tarball.WriteToFile(outFile, ref, image); err != nil {
+		return "", fmt.Errorf("failed to write image tarball for %s: %w", img.Name, err)
+	}
+	return "", nil
+}
+
+// koImage compiles the main package of img and returns the image of the binary layered onto the base image.
+// If repro is set, the base image is pinned and the image is created at SOURCE_DATE_EPOCH.
+// platformEnv returns the environment that cross-compiles a static Go binary for platform.
+func platformEnv(platform *v1.Platform) []string {
+	env := []string{"CGO_ENABLED=0", "GOOS=" + platform.OS, "GOARCH=" + platform.Architecture}
+	// OCI arm variants are v6 or v7, where GOARM wants 6 or 7.
+	if platform.Variant != "" && platform.Architecture == "arm" {
+		env = append(env, "GOARM="+strings.TrimPrefix(platform.Variant, "v"))
+	}
+	return env
+}
+
+func koImage(ctx context.Context, root string, img *ImageConfig, ldflags string, repro *reproducibility) (v1.Image, error) {
+	platformStr := img.Platform
+	if platformStr == "" {
+		platformStr = "linux/amd64"
+	}
+	platform, err := v1.ParsePlatform(platformStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid platform %q for image %s: %w", platformStr, img.Name, err)
+	}
+
+	base := (&image{Name: img.Name, Config: img}).koBase()
+	if repro != nil {
+		base = repro.pin(base)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ap-ko-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	binaryPath := filepath.Join(tmpDir, img.Name)
+	klog.Infof("Compiling %s for %s", img.Main, platformStr)
+	args := []string{"build", "-trimpath"}
+	if ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
+	}
+	args = append(args, "-o", binaryPath, img.Main)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), platformEnv(platform)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := runner.Run(ctx, cmd); err != nil {
+		return nil, fmt.Errorf("go build failed for %s: %w", img.Name, err)
+	}
+
+	layer, err := binaryLayer(binaryPath, img.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	baseRef, err := name.ParseReference(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base image %q: %w", base, err)
+	}
+	baseImage, err := remote.Image(baseRef,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithPlatform(*platform))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch base image %s: %w", base, err)
+	}
+
+	image, err := mutate.AppendLayers(baseImage, layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to append layer: %w", err)
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image config: %w", err)
+	}
+	cfg := configFile.Config.DeepCopy()
+	entrypoint := path.Join(koAppDir, img.Name)
+	cfg.Entrypoint = []string{entrypoint}
+	cfg.Cmd = nil
+	cfg.Env = append(cfg.Env, "KO_DATA_PATH=/var/run/ko")
+	if len(img.Labels) > 0 {
+		if cfg.Labels == nil {
+			cfg.Labels = make(map[string]string)
+		}
+		for k, v := range img.Labels {
+			cfg.Labels[k] = v
+		}
+	}
+	image, err = mutate.Config(image, *cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set image config: %w", err)
+	}
+	if repro != nil {
+		seconds, err := strconv.ParseInt(repro.epoch, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", repro.epoch, err)
+		}
+		if image, err = mutate.CreatedAt(image, v1.Time{Time: time.Unix(seconds, 0)}); err != nil {
+			return nil, fmt.Errorf("failed to set the creation time of image %s: %w", img.Name, err)
+		}
+	}
+	return image, nil
+}
+
+// binaryLayer creates an image layer containing the binary at /ko-app/<name>.
+func binaryLayer(binaryPath string, name string) (v1.Layer, error) {
+	content, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binary: %w", err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	dir := koAppDir[1:]
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     dir,
+		Typeflag: tar.TypeDir,
+		Mode:     0555,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     path.Join(dir, name),
+		Typeflag: tar.TypeReg,
+		Mode:     0555,
+		Size:     int64(len(content)),
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	layerData := buf.Bytes()
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(layerData)), nil
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestBinaryLayer(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "server")
+	if err := os.WriteFile(binary, []byte("binary-content"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	layer, err := binaryLayer(binary, "server")
+	if err != nil {
+		t.Fatalf("binaryLayer() error = %v", err)
+	}
+
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		t.Fatalf("Uncompressed() error = %v", err)
+	}
+	defer rc.Close()
+
+	files := make(map[string]string)
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read layer: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(content)
+	}
+
+	if got := files["ko-app/server"]; got != "binary-content" {
+		t.Errorf("expected ko-app/server to contain binary, got files %v", files)
+	}
+}
+
+func TestPlatformEnv(t *testing.T) {
+	tests := []struct {
+		platform string
+		want     []string
+	}{
+		{platform: "linux/amd64", want: []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=amd64"}},
+		{platform: "linux/arm64/v8", want: []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm64"}},
+		{platform: "linux/arm/v7", want: []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm", "GOARM=7"}},
+		{platform: "linux/arm/v6", want: []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm", "GOARM=6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			platform, err := v1.ParsePlatform(tt.platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := platformEnv(platform); !slices.Equal(got, tt.want) {
+				t.Errorf("platformEnv(%s) = %q, want %q", tt.platform, got, tt.want)
+			}
+		})
+	}
+}
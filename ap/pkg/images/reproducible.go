@@ -0,0 +1,348 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"k8s.io/klog/v2"
+)
+
+// LockFile records the digests of the base images of reproducible builds, relative to the ap root.
+var LockFile = filepath.Join(".ap", "images.lock.json")
+
+// Lock is the contents of .ap/images.lock.json.
+type Lock struct {
+	// Bases maps the base images, as referenced by the Dockerfiles and .ap/images.yaml, to their digests.
+	Bases map[string]string `json:"bases"`
+}
+
+// LoadLock loads .ap/images.lock.json from root, returning an empty lock if it does not exist.
+func LoadLock(root string) (*Lock, error) {
+	lockFile := filepath.Join(root, LockFile)
+
+	lock := Lock{Bases: map[string]string{}}
+	data, err := os.ReadFile(lockFile)
+	if os.IsNotExist(err) {
+		return &lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", lockFile, err)
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", lockFile, err)
+	}
+	if lock.Bases == nil {
+		lock.Bases = map[string]string{}
+	}
+	return &lock, nil
+}
+
+// write writes the lock to .ap/images.lock.json under root.
+func (l *Lock) write(root string) error {
+	lockFile := filepath.Join(root, LockFile)
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	klog.Infof("Updating %s", lockFile)
+	if err := os.WriteFile(lockFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockFile, err)
+	}
+	return nil
+}
+
+// resolve records the current digest of each of bases that is not in the lock yet, or of every base if refresh is
+// set, and drops the bases no longer used if refresh is set. It reports whether the lock changed.
+func (l *Lock) resolve(ctx context.Context, bases []string, refresh bool) (bool, error) {
+	old := maps.Clone(l.Bases)
+	if refresh {
+		l.Bases = map[string]string{}
+	}
+	for _, base := range bases {
+		if _, ok := l.Bases[base]; ok {
+			continue
+		}
+		digest, err := resolveDigest(ctx, base)
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve the digest of base image %s: %w", base, err)
+		}
+		l.Bases[base] = digest
+	}
+	return !maps.Equal(old, l.Bases), nil
+}
+
+// resolveDigest returns the digest of the image ref in its registry, that of the index for multi-platform images.
+var resolveDigest = func(ctx context.Context, ref string) (string, error) {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(parsed, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// UpdateLock updates the digests in the .ap/images.lock.json of root to the current digests of the base images,
+// as ap versionbump does, if root has a lock or builds its images reproducibly. Bases no longer used are dropped.
+func UpdateLock(ctx context.Context, root string) error {
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(root, LockFile)); os.IsNotExist(err) && !cfg.Reproducible {
+		return nil
+	}
+	images, err := findImages(root)
+	if err != nil {
+		return err
+	}
+	bases, err := baseImages(root, images)
+	if err != nil {
+		return err
+	}
+	lock, err := LoadLock(root)
+	if err != nil {
+		return err
+	}
+	changed, err := lock.resolve(ctx, bases, true)
+	if err != nil || !changed {
+		return err
+	}
+	return lock.write(root)
+}
+
+// baseImages returns the base images of images that can be pinned, sorted.
+func baseImages(root string, images []*image) ([]string, error) {
+	var bases []string
+	for _, img := range images {
+		if img.builder() == BuilderKo {
+			bases = append(bases, img.koBase())
+			continue
+		}
+		if img.Dockerfile == "" {
+			continue
+		}
+		dockerfile, err := os.ReadFile(filepath.Join(root, img.Dockerfile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", img.Dockerfile, err)
+		}
+		bases = append(bases, dockerfileBases(string(dockerfile))...)
+	}
+	slices.Sort(bases)
+	return slices.Compact(bases), nil
+}
+
+// dockerfileBases returns the images the stages of dockerfile are built from. Earlier stages, scratch,
+// images that are already pinned by digest and images named by build args cannot be pinned, and are left out.
+func dockerfileBases(dockerfile string) []string {
+	stages := map[string]bool{"scratch": true}
+	var bases []string
+	for _, line := range strings.Split(dockerfile, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		fields = fields[1:]
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		base := fields[0]
+		if !stages[strings.ToLower(base)] && !strings.Contains(base, "@") && !strings.Contains(base, "$") {
+			bases = append(bases, base)
+		}
+		if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+			stages[strings.ToLower(fields[2])] = true
+		}
+	}
+	return bases
+}
+
+// reproducibility holds the settings of a reproducible build.
+type reproducibility struct {
+	// epoch is the value of SOURCE_DATE_EPOCH, in seconds.
+	epoch string
+
+	// lock holds the digests the base images are pinned to.
+	lock *Lock
+}
+
+// newReproducibility pins the base images of images, resolving those not in the lock of root yet,
+// and recording them in the lock if write is set.
+func newReproducibility(ctx context.Context, root string, images []*image, vars *templateVars, write bool) (*reproducibility, error) {
+	epoch, err := vars.lookup("SOURCE_DATE_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	if epoch == "" {
+		return nil, fmt.Errorf("SOURCE_DATE_EPOCH is not set and could not be taken from the commit being built")
+	}
+
+	bases, err := baseImages(root, images)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := LoadLock(root)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := lock.resolve(ctx, bases, false)
+	if err != nil {
+		return nil, err
+	}
+	if changed && write {
+		if err := lock.write(root); err != nil {
+			return nil, err
+		}
+	}
+	return &reproducibility{epoch: epoch, lock: lock}, nil
+}
+
+// pin returns base pinned to the digest in the lock, e.g. alpine:3.20@sha256:..., or base if it is not in the lock.
+func (r *reproducibility) pin(base string) string {
+	if digest, ok := r.lock.Bases[base]; ok {
+		return base + "@" + digest
+	}
+	return base
+}
+
+// dockerBuildArgs returns the docker buildx flags that set SOURCE_DATE_EPOCH and pin the base images of dockerfile.
+// The bases are replaced with named build contexts, so that the Dockerfile itself does not change.
+func (r *reproducibility) dockerBuildArgs(dockerfile string) []string {
+	args := []string{"--build-arg", "SOURCE_DATE_EPOCH=" + r.epoch}
+	for _, base := range dockerfileBases(dockerfile) {
+		if pinned := r.pin(base); pinned != base {
+			args = append(args, "--build-context", base+"=docker-image://"+pinned)
+		}
+	}
+	return args
+}
+
+// cacheKey returns the part of the cache key of an image that depends on the pinned base images.
+// The epoch is not part of it: an unchanged image keeps the timestamps it was built with.
+func (r *reproducibility) cacheKey() string {
+	var parts []string
+	for _, base := range slices.Sorted(maps.Keys(r.lock.Bases)) {
+		parts = append(parts, base+"@"+r.lock.Bases[base])
+	}
+	return strings.Join(parts, ",")
+}
+
+// VerifyReproducible builds each image under root twice, from scratch and without pushing it, and returns an error
+// if an image is built with a different digest the second time. The images are built reproducibly whether or not
+// .ap/images.yaml asks for it; base images that are not in .ap/images.lock.json are resolved but not recorded.
+func VerifyReproducible(ctx context.Context, root string) error {
+	tag := cmp.Or(os.Getenv("IMAGE_TAG"), "latest")
+	images, err := findImages(root)
+	if err != nil {
+		return err
+	}
+	vars := newTemplateVars(ctx, root, tag)
+	vars.reproducible = true
+	repro, err := newReproducibility(ctx, root, images, vars, false)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, img := range images {
+		if img.Config != nil {
+			cfg, err := vars.expandConfig(img.Config)
+			if err != nil {
+				return err
+			}
+			img.Config = cfg
+		}
+		fullImageName := imageRef(os.Getenv("IMAGE_PREFIX"), img.Name, tag)
+		metadata := vars.buildMetadata()
+
+		var digests [2]string
+		for i := range digests {
+			if digests[i], err = verificationBuild(ctx, root, img, fullImageName, metadata, repro); err != nil {
+				return err
+			}
+		}
+		switch {
+		case digests[0] == "":
+			// The build was skipped, as its tool is missing.
+		case digests[0] != digests[1]:
+			errs = append(errs, fmt.Errorf("image %s is not reproducible: it was built as %s, then as %s", img.Name, digests[0], digests[1]))
+		default:
+			klog.Infof("Image %s is reproducible (%s)", img.Name, digests[0])
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verificationBuild builds img from scratch, without pushing or loading it, and returns its digest,
+// or "" if the build is skipped.
+func verificationBuild(ctx context.Context, root string, img *image, fullImageName string, meta buildMetadata, repro *reproducibility) (string, error) {
+	if img.builder() == BuilderKo {
+		image, err := koImage(ctx, root, img.Config, meta.LDFlags(), repro)
+		if err != nil {
+			return "", fmt.Errorf("ko build failed for %s: %w", img.Name, err)
+		}
+		digest, err := image.Digest()
+		if err != nil {
+			return "", fmt.Errorf("failed to compute digest of %s: %w", img.Name, err)
+		}
+		return digest.String(), nil
+	}
+
+	ok, err := tools.Require(ctx, tools.Requirement{Tool: "docker", Task: "verify image " + img.Name})
+	if !ok || err != nil {
+		return "", err
+	}
+	args, err := dockerBuildxArgs(root, img, fullImageName, meta, repro)
+	if err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp("", "ap-build-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	metadataFile := filepath.Join(tmpDir, "metadata.json")
+	output := "type=oci,dest=" + filepath.Join(tmpDir, "image.tar") + ",rewrite-timestamp=true"
+	args = append(args, "--no-cache", "--output", output, "--metadata-file", metadataFile, ".")
+
+	klog.Infof("Building image %s from %s to verify it is reproducible", fullImageName, root)
+	if err := runDockerBuildx(ctx, root, img, args); err != nil {
+		return "", err
+	}
+	digest := readBuiltDigest(img, metadataFile)
+	if digest == "" {
+		return "", fmt.Errorf("docker buildx did not report the digest of image %s", img.Name)
+	}
+	return digest, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/version"
+	"k8s.io/klog/v2"
+)
+
+// templateVars expands ${VAR} references in images.yaml values.
+// The built-in variables are:
+//   - GIT_SHA: the commit being built (git rev-parse HEAD)
+//   - VERSION: the output of git describe --tags --always --dirty
+//   - IMAGE_TAG: the tag the image is pushed with
+//   - BUILD_DATE: the build time in RFC 3339 format, taken from SOURCE_DATE_EPOCH if it is set
+//   - SOURCE_DATE_EPOCH: the environment variable, or the time of the commit being built for reproducible builds
+//
+// Any other variable is looked up in the environment.
+type templateVars struct {
+	ctx      context.Context
+	root     string
+	imageTag string
+
+	// reproducible defaults SOURCE_DATE_EPOCH to the time of the commit being built.
+	reproducible bool
+
+	// values caches the built-in variables, which are computed on first use.
+	values map[string]string
+}
+
+func newTemplateVars(ctx context.Context, root string, imageTag string) *templateVars {
+	return &templateVars{
+		ctx:      ctx,
+		root:     root,
+		imageTag: imageTag,
+		values:   make(map[string]string),
+	}
+}
+
+func (v *templateVars) lookup(key string) (string, error) {
+	if value, ok := v.values[key]; ok {
+		return value, nil
+	}
+
+	var value string
+	var err error
+	switch key {
+	case "GIT_SHA":
+		value, err = v.git("rev-parse", "HEAD")
+	case "VERSION":
+		value, err = v.git("describe", "--tags", "--always", "--dirty")
+	case "IMAGE_TAG":
+		value = v.imageTag
+	case "BUILD_DATE":
+		var epoch string
+		if epoch, err = v.lookup("SOURCE_DATE_EPOCH"); err == nil {
+			value, err = buildDate(epoch)
+		}
+	case "SOURCE_DATE_EPOCH":
+		value = os.Getenv(key)
+		if value == "" && v.reproducible {
+			value, err = v.git("log", "-1", "--format=%ct")
+		}
+	default:
+		return os.Getenv(key), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to compute %s: %w", key, err)
+	}
+	v.values[key] = value
+	return value, nil
+}
+
+// buildDate returns the build time: the time of epoch, the value of SOURCE_DATE_EPOCH, if it is set.
+func buildDate(epoch string) (string, error) {
+	t := time.Now()
+	if epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+		}
+		t = time.Unix(seconds, 0)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// buildMetadata is the version information injected into every image ap builds.
+type buildMetadata struct {
+	Version   string
+	GitSHA    string
+	BuildDate string
+}
+
+// buildMetadata returns the values of VERSION, GIT_SHA and BUILD_DATE.
+// Values that cannot be computed, for example outside of a git checkout, are left empty.
+func (v *templateVars) buildMetadata() buildMetadata {
+	get := func(key string) string {
+		value, err := v.lookup(key)
+		if err != nil {
+			klog.V(2).Infof("Not injecting %s into the build: %v", key, err)
+		}
+		return value
+	}
+	return buildMetadata{
+		Version:   get("VERSION"),
+		GitSHA:    get("GIT_SHA"),
+		BuildDate: get("BUILD_DATE"),
+	}
+}
+
+// LDFlags returns the Go linker flags that set the metadata reported by ap/pkg/version.
+func (m buildMetadata) LDFlags() string {
+	return version.LDFlags(m.Version, m.GitSHA, m.BuildDate)
+}
+
+// dockerBuildArgs returns the metadata as the AP_* build args a Dockerfile can declare.
+func (m buildMetadata) dockerBuildArgs() map[string]string {
+	return map[string]string{
+		"AP_VERSION":    m.Version,
+		"AP_GIT_SHA":    m.GitSHA,
+		"AP_BUILD_DATE": m.BuildDate,
+		"AP_LDFLAGS":    m.LDFlags(),
+	}
+}
+
+func (v *templateVars) git(args ...string) (string, error) {
+	cmd := exec.CommandContext(v.ctx, "git", args...)
+	cmd.Dir = v.root
+	out, err := runner.Output(v.ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Expand replaces ${VAR} and $VAR references in s.
+func (v *templateVars) Expand(s string) (string, error) {
+	var errs []error
+	expanded := os.Expand(s, func(key string) string {
+		value, err := v.lookup(key)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return value
+	})
+	if len(errs) > 0 {
+		return "", errs[0]
+	}
+	return expanded, nil
+}
+
+func (v *templateVars) expandMap(m map[string]string) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(m))
+	for k, value := range m {
+		expanded, err := v.Expand(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand %q: %w", k, err)
+		}
+		out[k] = expanded
+	}
+	return out, nil
+}
+
+func (v *templateVars) expandSlice(values []string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		expanded, err := v.Expand(value)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded)
+	}
+	return out, nil
+}
+
+// expandConfig returns a copy of cfg with the templated fields expanded.
+func (v *templateVars) expandConfig(cfg *ImageConfig) (*ImageConfig, error) {
+	out := *cfg
+
+	var err error
+	if out.BuildArgs, err = v.expandMap(cfg.BuildArgs); err != nil {
+		return nil, fmt.Errorf("image %s: buildArgs: %w", cfg.Name, err)
+	}
+	if out.Labels, err = v.expandMap(cfg.Labels); err != nil {
+		return nil, fmt.Errorf("image %s: labels: %w", cfg.Name, err)
+	}
+	if out.Secrets, err = v.expandSlice(cfg.Secrets); err != nil {
+		return nil, fmt.Errorf("image %s: secrets: %w", cfg.Name, err)
+	}
+	if out.SSH, err = v.expandSlice(cfg.SSH); err != nil {
+		return nil, fmt.Errorf("image %s: ssh: %w", cfg.Name, err)
+	}
+	return &out, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExpandConfig(t *testing.T) {
+	t.Setenv("AP_TEST_REGISTRY", "example.com")
+
+	vars := newTemplateVars(context.Background(), t.TempDir(), "v1.2.3")
+	// Pre-populate the git-derived values, so the test does not depend on a git checkout.
+	vars.values["GIT_SHA"] = "0123abcd"
+
+	cfg := &ImageConfig{
+		Name: "foo",
+		BuildArgs: map[string]string{
+			"GIT_SHA":  "${GIT_SHA}",
+			"REGISTRY": "$AP_TEST_REGISTRY",
+		},
+		Labels: map[string]string{
+			"org.opencontainers.image.version": "${IMAGE_TAG}",
+		},
+		SSH: []string{"default"},
+	}
+
+	got, err := vars.expandConfig(cfg)
+	if err != nil {
+		t.Fatalf("expandConfig failed: %v", err)
+	}
+
+	want := &ImageConfig{
+		Name: "foo",
+		BuildArgs: map[string]string{
+			"GIT_SHA":  "0123abcd",
+			"REGISTRY": "example.com",
+		},
+		Labels: map[string]string{
+			"org.opencontainers.image.version": "v1.2.3",
+		},
+		SSH: []string{"default"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandConfig() = %+v, want %+v", got, want)
+	}
+
+	// The original config must not be modified.
+	if cfg.BuildArgs["GIT_SHA"] != "${GIT_SHA}" {
+		t.Errorf("expandConfig modified its input")
+	}
+}
+
+func TestExpandGitOutsideRepo(t *testing.T) {
+	vars := newTemplateVars(context.Background(), t.TempDir(), "latest")
+	if _, err := vars.Expand("${GIT_SHA}"); err == nil {
+		t.Errorf("expected error expanding GIT_SHA outside of a git repo")
+	}
+}
+
+func TestBuildDateFromSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1767323045")
+
+	vars := newTemplateVars(context.Background(), t.TempDir(), "latest")
+	got, err := vars.Expand("${BUILD_DATE}")
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if want := "2026-01-02T03:04:05Z"; got != want {
+		t.Errorf("BUILD_DATE = %q, want %q", got, want)
+	}
+}
+
+func TestBuildMetadataOutsideRepo(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1767323045")
+
+	vars := newTemplateVars(context.Background(), t.TempDir(), "latest")
+	got := vars.buildMetadata()
+	if got.Version != "" || got.GitSHA != "" || got.BuildDate != "2026-01-02T03:04:05Z" {
+		t.Errorf("buildMetadata() = %+v, want only the build date", got)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// TrackLabel tells the pods of a canary Deployment apart from those of the stable Deployment it is a copy of.
+	// Canary deploys require it in the selector of the stable Deployment (e.g. ap.gke.io/track: stable),
+	// and not in the selector of the Service, which then routes to the pods of both.
+	TrackLabel = "ap.gke.io/track"
+
+	// canaryTrack is the TrackLabel value of canary pods.
+	canaryTrack = "canary"
+
+	// canarySuffix is appended to the name of a Deployment to name its canary.
+	canarySuffix = "-canary"
+)
+
+// canaryDeployment is the subset of a Deployment that canary deploys need.
+type canaryDeployment struct {
+	resourceHeader `yaml:",inline"`
+	Spec           struct {
+		Replicas *int `yaml:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `yaml:"matchLabels"`
+		} `yaml:"selector"`
+		Template struct {
+			Metadata struct {
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+// canaryService is the subset of a Service that canary deploys need.
+type canaryService struct {
+	resourceHeader `yaml:",inline"`
+	Spec           struct {
+		Selector map[string]string `yaml:"selector"`
+	} `yaml:"spec"`
+}
+
+// selects returns true if the service routes to the pods of the deployment.
+func (s *canaryService) selects(d *canaryDeployment) bool {
+	if len(s.Spec.Selector) == 0 || s.Metadata.Namespace != d.Metadata.Namespace {
+		return false
+	}
+	for key, value := range s.Spec.Selector {
+		if d.Spec.Template.Metadata.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// canaryManifest returns a canary copy of each Deployment in content that is behind a Service.
+// The Service splits traffic between the stable and canary pods by their number, so the canary gets
+// enough replicas for about percent of the traffic, and at least one. Other resources are left out.
+func canaryManifest(content string, percent int) (string, error) {
+	if percent <= 0 || percent >= 100 {
+		return "", fmt.Errorf("canary percentage must be between 1 and 99, got %d", percent)
+	}
+
+	var docs []*yaml.Node
+	var services []canaryService
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to decode YAML: %w", err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		docs = append(docs, &doc)
+
+		var header resourceHeader
+		if err := doc.Decode(&header); err != nil {
+			return "", fmt.Errorf("failed to decode resource: %w", err)
+		}
+		if header.Kind != "Service" {
+			continue
+		}
+		var service canaryService
+		if err := doc.Decode(&service); err != nil {
+			return "", fmt.Errorf("failed to decode service %s: %w", header.Metadata.Name, err)
+		}
+		services = append(services, service)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	found := false
+	for _, doc := range docs {
+		var header resourceHeader
+		if err := doc.Decode(&header); err != nil {
+			return "", fmt.Errorf("failed to decode resource: %w", err)
+		}
+		if header.Kind != "Deployment" {
+			continue
+		}
+		var deployment canaryDeployment
+		if err := doc.Decode(&deployment); err != nil {
+			return "", fmt.Errorf("failed to decode deployment %s: %w", header.Metadata.Name, err)
+		}
+		i := slices.IndexFunc(services, func(s canaryService) bool { return s.selects(&deployment) })
+		if i == -1 {
+			klog.Infof("Leaving deployment %s out of the canary: it is not selected by a Service", deployment.Metadata.Name)
+			continue
+		}
+		service := services[i]
+		if _, ok := service.Spec.Selector[TrackLabel]; ok {
+			return "", fmt.Errorf("service %s selects pods by the %s label, so it would not route to canary pods", service.Metadata.Name, TrackLabel)
+		}
+		if _, ok := deployment.Spec.Selector.MatchLabels[TrackLabel]; !ok {
+			return "", fmt.Errorf("deployment %s cannot be canaried: its selector must include the %s label (e.g. %s: stable, also set on its pods), so that it does not select the canary pods",
+				deployment.Metadata.Name, TrackLabel, TrackLabel)
+		}
+		found = true
+
+		stable := 1
+		if deployment.Spec.Replicas != nil {
+			stable = *deployment.Spec.Replicas
+		}
+		replicas := canaryReplicas(stable, percent)
+		klog.Infof("Canary of deployment %s: %d replicas alongside %d stable replicas, for %d%% of the traffic of service %s",
+			deployment.Metadata.Name, replicas, stable, 100*replicas/(stable+replicas), service.Metadata.Name)
+
+		root := doc.Content[0]
+		metadata := mappingChild(root, "metadata")
+		setMappingValue(metadata, "name", deployment.Metadata.Name+canarySuffix)
+		if _, ok := deployment.Metadata.Labels[TrackLabel]; ok {
+			setMappingValue(mappingChild(metadata, "labels"), TrackLabel, canaryTrack)
+		}
+		spec := mappingChild(root, "spec")
+		setMappingValue(spec, "replicas", strconv.Itoa(replicas))
+		setMappingTag(spec, "replicas", "!!int")
+		setMappingValue(mappingChild(mappingChild(spec, "selector"), "matchLabels"), TrackLabel, canaryTrack)
+		setMappingValue(mappingChild(mappingChild(mappingChild(spec, "template"), "metadata"), "labels"), TrackLabel, canaryTrack)
+
+		if err := encoder.Encode(doc); err != nil {
+			return "", fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no Deployment is selected by a Service; canary deploys split the traffic of a Service between stable and canary pods")
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return out.String(), nil
+}
+
+// setMappingTag sets the tag of the value stored under key in node, e.g. so that a number is not quoted.
+func setMappingTag(node *yaml.Node, key string, tag string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1].Tag = tag
+		}
+	}
+}
+
+// canaryReplicas returns the number of canary replicas to run alongside stable replicas
+// for percent of the traffic, rounded to the nearest replica and at least one.
+func canaryReplicas(stable int, percent int) int {
+	return max(1, (stable*percent+(100-percent)/2)/(100-percent))
+}
+
+// canaryRefs returns the canaries that canary deploys may have created for the Deployments in refs.
+func canaryRefs(refs []resourceRef) []resourceRef {
+	var canaries []resourceRef
+	for _, ref := range refs {
+		if ref.Group == "apps" && strings.EqualFold(ref.Kind, "Deployment") {
+			ref.Name += canarySuffix
+			canaries = append(canaries, ref)
+		}
+	}
+	return canaries
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCanaryManifest(t *testing.T) {
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  labels:
+    ap.gke.io/track: stable
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: server
+      ap.gke.io/track: stable
+  template:
+    metadata:
+      labels:
+        app: server
+        ap.gke.io/track: stable
+    spec:
+      containers:
+      - name: server
+        image: example.com/server@sha256:abc
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  selector:
+    matchLabels:
+      app: worker
+  template:
+    metadata:
+      labels:
+        app: worker
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+spec:
+  selector:
+    app: server
+`
+	got, err := canaryManifest(input, 25)
+	if err != nil {
+		t.Fatalf("canaryManifest failed: %v", err)
+	}
+
+	want := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server-canary
+  labels:
+    ap.gke.io/track: canary
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: server
+      ap.gke.io/track: canary
+  template:
+    metadata:
+      labels:
+        app: server
+        ap.gke.io/track: canary
+    spec:
+      containers:
+        - name: server
+          image: example.com/server@sha256:abc
+`
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCanaryManifestErrors(t *testing.T) {
+	service := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+spec:
+  selector:
+    app: server
+`
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  selector:
+    matchLabels:
+      app: server
+  template:
+    metadata:
+      labels:
+        app: server
+`
+	tracked := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+spec:
+  selector:
+    matchLabels:
+      app: server
+      ap.gke.io/track: stable
+  template:
+    metadata:
+      labels:
+        app: server
+        ap.gke.io/track: stable
+`
+	for _, tc := range []struct {
+		name    string
+		input   string
+		percent int
+		want    string
+	}{
+		{name: "percentage", input: deployment + service, percent: 100, want: "between 1 and 99"},
+		{name: "no service", input: deployment, percent: 10, want: "no Deployment is selected by a Service"},
+		{name: "no track label", input: deployment + service, percent: 10, want: "its selector must include the ap.gke.io/track label"},
+		{
+			name:    "service selects track",
+			input:   tracked + service + "    ap.gke.io/track: stable\n",
+			percent: 10,
+			want:    "would not route to canary pods",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := canaryManifest(tc.input, tc.percent)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("canaryManifest() error = %v, want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanaryReplicas(t *testing.T) {
+	for _, tc := range []struct {
+		stable, percent, want int
+	}{
+		{stable: 3, percent: 25, want: 1},
+		{stable: 1, percent: 10, want: 1},
+		{stable: 4, percent: 50, want: 4},
+		{stable: 9, percent: 40, want: 6},
+	} {
+		if got := canaryReplicas(tc.stable, tc.percent); got != tc.want {
+			t.Errorf("canaryReplicas(%d, %d) = %d, want %d", tc.stable, tc.percent, got, tc.want)
+		}
+	}
+}
+
+func TestCanaryRefs(t *testing.T) {
+	got := canaryRefs([]resourceRef{
+		{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "server"},
+		{Kind: "Service", Namespace: "prod", Name: "server"},
+	})
+	want := []resourceRef{{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "server-canary"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("canaryRefs() = %+v, want %+v", got, want)
+	}
+}
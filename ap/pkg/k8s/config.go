@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DeployConfig is the contents of .ap/deploy.yaml.
+type DeployConfig struct {
+	// Target selects the cluster and namespace; it can be overridden on the command line.
+	Target
+
+	// WaitForRollouts waits for every Deployment, StatefulSet and DaemonSet to become ready after it is applied.
+	WaitForRollouts bool `json:"waitForRollouts,omitempty"`
+
+	// CreateNamespace creates the target namespace if it does not exist.
+	CreateNamespace bool `json:"createNamespace,omitempty"`
+
+	// FailOnConflicts fails deploys on conflicts with fields owned by other field managers (e.g. an autoscaler setting
+	// replicas), instead of taking the fields over. Manifests are always applied server-side, with ap as the field manager.
+	// It replaces serverSideApply: deploys without it take over such fields, where client-side apply only overwrote them.
+	FailOnConflicts bool `json:"failOnConflicts,omitempty"`
+
+	// Inventory is the name of the ConfigMap recording the deployed resources, used by --prune
+	// (defaults to ap-inventory-<ap root directory name>).
+	Inventory string `json:"inventory,omitempty"`
+
+	// Charts configures how helm charts found under k8s/ directories are rendered.
+	Charts []ChartConfig `json:"charts,omitempty"`
+
+	// Profiles are named environments (e.g. dev, staging, prod), selected with --profile.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+
+	// Images configures where placeholder images are looked for, beyond container images.
+	Images ImagesConfig `json:"images,omitempty"`
+}
+
+// ImagesConfig declares the fields holding placeholder images, in addition to the image of every container.
+type ImagesConfig struct {
+	// Paths are further fields holding images, e.g. in custom resources.
+	Paths []ImagePath `json:"paths,omitempty"`
+
+	// Env are the names of container environment variables whose values are images (e.g. SIDECAR_IMAGE).
+	Env []string `json:"env,omitempty"`
+
+	// Args are container flags whose values are images, given as --flag=image or as --flag image
+	// (e.g. --sidecar-image).
+	Args []string `json:"args,omitempty"`
+}
+
+// ImagePath is a field holding an image. A field holding a mapping is treated as a Helm-style image,
+// whose repository and tag keys are replaced.
+type ImagePath struct {
+	// Kind restricts the path to objects of this kind (default: objects of any kind).
+	Kind string `json:"kind,omitempty"`
+
+	// Path is a JSONPath to the field, made of field names and [*] for every item of a list
+	// (e.g. spec.workers[*].image).
+	Path string `json:"path"`
+}
+
+// Profile configures a deploy environment; its settings take precedence over the top-level ones.
+type Profile struct {
+	// Target selects the cluster and namespace of the environment.
+	Target
+
+	// ImagePrefix is the registry images are pushed to and deployed from, in place of $IMAGE_PREFIX.
+	ImagePrefix string `json:"imagePrefix,omitempty"`
+
+	// ImageTag is the tag images are pushed with, in place of $IMAGE_TAG.
+	ImageTag string `json:"imageTag,omitempty"`
+
+	// Overlays are kustomization directories under k8s/, relative to the ap root, that are only
+	// deployed with this profile (e.g. k8s/overlays/staging).
+	Overlays []string `json:"overlays,omitempty"`
+}
+
+// ChartConfig holds the helm template options for a chart.
+type ChartConfig struct {
+	// Path is the chart directory, relative to the ap root.
+	Path string `json:"path"`
+
+	// ReleaseName is the helm release name (defaults to the chart directory name).
+	ReleaseName string `json:"releaseName,omitempty"`
+
+	// Namespace is passed to helm template as --namespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// ValuesFiles are passed to helm template with -f, relative to the ap root.
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+
+	// Values are inline values, applied after ValuesFiles.
+	Values map[string]any `json:"values,omitempty"`
+}
+
+// LoadDeployConfig loads .ap/deploy.yaml from root, returning an empty config if it does not exist.
+func LoadDeployConfig(root string) (*DeployConfig, error) {
+	configFile := filepath.Join(root, ".ap", "deploy.yaml")
+
+	var config DeployConfig
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	// A relative kubeconfig is relative to the ap root, not to where ap is run from.
+	if config.Kubeconfig != "" && !filepath.IsAbs(config.Kubeconfig) {
+		config.Kubeconfig = filepath.Join(root, config.Kubeconfig)
+	}
+
+	for name, profile := range config.Profiles {
+		if profile.Kubeconfig != "" && !filepath.IsAbs(profile.Kubeconfig) {
+			profile.Kubeconfig = filepath.Join(root, profile.Kubeconfig)
+			config.Profiles[name] = profile
+		}
+	}
+
+	for i, chart := range config.Charts {
+		if chart.Path == "" {
+			return nil, fmt.Errorf("error in %s: chart %d has no path", configFile, i)
+		}
+	}
+
+	for i, p := range config.Images.Paths {
+		if _, err := p.keys(); err != nil {
+			return nil, fmt.Errorf("error in %s: images path %d: %w", configFile, i, err)
+		}
+	}
+
+	return &config, nil
+}
+
+// Chart returns the configuration for the chart at relPath, or nil if it is not configured.
+func (c *DeployConfig) Chart(relPath string) *ChartConfig {
+	for i := range c.Charts {
+		if filepath.Clean(c.Charts[i].Path) == filepath.Clean(relPath) {
+			return &c.Charts[i]
+		}
+	}
+	return nil
+}
+
+// Profile returns the profile called name, or an empty profile if name is empty.
+func (c *DeployConfig) Profile(name string) (*Profile, error) {
+	if name == "" {
+		return &Profile{}, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+		if len(names) == 0 {
+			return nil, fmt.Errorf("profile %q is not defined; there are no profiles in .ap/deploy.yaml", name)
+		}
+		return nil, fmt.Errorf("profile %q is not defined in .ap/deploy.yaml (profiles: %s)", name, strings.Join(names, ", "))
+	}
+	return &profile, nil
+}
+
+// Env returns the environment variables set by the profile, for the image build and deploy.
+func (p *Profile) Env() map[string]string {
+	env := make(map[string]string)
+	if p.ImagePrefix != "" {
+		env["IMAGE_PREFIX"] = p.ImagePrefix
+	}
+	if p.ImageTag != "" {
+		env["IMAGE_TAG"] = p.ImageTag
+	}
+	return env
+}
+
+// profileManifests returns the manifests deployed with the profile called profile (which may be empty):
+// the overlays of profiles are left out, except those of the selected profile.
+func (c *DeployConfig) profileManifests(root string, manifests []string, profile string) ([]string, error) {
+	overlays := make(map[string]bool)
+	for _, p := range c.Profiles {
+		for _, overlay := range p.Overlays {
+			overlays[filepath.Clean(overlay)] = true
+		}
+	}
+
+	var selected []string
+	found := make(map[string]bool)
+	for _, manifest := range manifests {
+		relPath, err := filepath.Rel(root, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if overlays[relPath] {
+			if !slices.ContainsFunc(c.Profiles[profile].Overlays, func(overlay string) bool { return filepath.Clean(overlay) == relPath }) {
+				continue
+			}
+			found[relPath] = true
+		}
+		selected = append(selected, manifest)
+	}
+
+	for _, overlay := range c.Profiles[profile].Overlays {
+		if !found[filepath.Clean(overlay)] {
+			return nil, fmt.Errorf("overlay %s of profile %q in .ap/deploy.yaml is not a kustomization directory under k8s/", overlay, profile)
+		}
+	}
+	return selected, nil
+}
@@ -15,24 +15,32 @@
 package k8s
 
 import (
-	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
 	"gopkg.in/yaml.v3"
 	"k8s.io/klog/v2"
 )
 
-func replacePlaceholderImages(content string, imageRepository string, imageTag string) (string, error) {
+// replacePlaceholderImages replaces placeholder images (e.g. "image: server") with
+// imageRepository/server@digest if a digest is known for the image, and imageRepository/server:imageTag otherwise.
+// Besides container images, the fields declared in cfg are replaced.
+func replacePlaceholderImages(content string, imageRepository string, imageTag string, digests map[string]string, cfg *ImagesConfig) (string, error) {
+	if cfg == nil {
+		cfg = &ImagesConfig{}
+	}
 	decoder := yaml.NewDecoder(strings.NewReader(content))
-	var placeholders []*yaml.Node
+	var placeholders []placeholder
 	for {
 		var node yaml.Node
 		err := decoder.Decode(&node)
@@ -42,7 +50,9 @@ func replacePlaceholderImages(content string, imageRepository string, imageTag s
 		if err != nil {
 			return "", fmt.Errorf("failed to decode YAML: %w", err)
 		}
-		placeholders = collectPlaceholders(&node, placeholders, nil)
+		c := &placeholderCollector{cfg: cfg, kind: objectKind(&node)}
+		c.collect(&node, nil)
+		placeholders = append(placeholders, c.placeholders...)
 	}
 
 	if len(placeholders) == 0 {
@@ -59,26 +69,32 @@ func replacePlaceholderImages(content string, imageRepository string, imageTag s
 	var replacements []replacement
 
 	for _, p := range placeholders {
-		if p.Line == 0 || p.Line > len(lineOffsets) {
-			return "", fmt.Errorf("invalid line number %d for placeholder %q", p.Line, p.Value)
+		if p.node.Line == 0 || p.node.Line > len(lineOffsets) {
+			return "", fmt.Errorf("invalid line number %d for placeholder %q", p.node.Line, p.node.Value)
 		}
-		start := lineOffsets[p.Line-1] + p.Column - 1
+		start := lineOffsets[p.node.Line-1] + p.node.Column - 1
 		if start >= len(content) {
-			return "", fmt.Errorf("invalid column %d on line %d for placeholder %q", p.Column, p.Line, p.Value)
+			return "", fmt.Errorf("invalid column %d on line %d for placeholder %q", p.node.Column, p.node.Line, p.node.Value)
 		}
 
-		end := findEnd(content, start, p.Style)
-
-		base, ok := isPlaceholderImage(p.Value)
-		if !ok {
-			return "", fmt.Errorf("invalid placeholder image %q", p.Value)
+		end := findEnd(content, start, p.node.Style)
+
+		var newVal string
+		switch p.field {
+		case helmRepository:
+			newVal = fmt.Sprintf("%s/%s", imageRepository, p.base)
+		case helmTag:
+			newVal = imageTag
+		default:
+			newVal = fmt.Sprintf("%s/%s:%s", imageRepository, p.base, imageTag)
+			if digest, ok := digests[p.base]; ok {
+				newVal = fmt.Sprintf("%s/%s@%s", imageRepository, p.base, digest)
+			}
 		}
-
-		newVal := fmt.Sprintf("%s/%s:%s", imageRepository, base, imageTag)
 		replacements = append(replacements, replacement{
 			offset: start,
 			length: end - start,
-			newVal: newVal,
+			newVal: p.prefix + newVal,
 		})
 	}
 
@@ -94,32 +110,170 @@ func replacePlaceholderImages(content string, imageRepository string, imageTag s
 	return content, nil
 }
 
-func collectPlaceholders(node *yaml.Node, placeholders []*yaml.Node, path []string) []*yaml.Node {
+// placeholderField is the kind of value a placeholder is replaced with.
+type placeholderField int
+
+const (
+	// imageField is replaced with the full image reference.
+	imageField placeholderField = iota
+	// helmRepository is the repository key of a Helm-style image, replaced with the repository alone.
+	helmRepository
+	// helmTag is the tag key of a Helm-style image, replaced with the image tag.
+	helmTag
+)
+
+// placeholder is a scalar node to be replaced.
+type placeholder struct {
+	node  *yaml.Node
+	field placeholderField
+	// base is the placeholder image without its tag.
+	base string
+	// prefix is kept in front of the replacement, e.g. "--sidecar-image=".
+	prefix string
+}
+
+// placeholderCollector collects the placeholders of one YAML document.
+type placeholderCollector struct {
+	cfg          *ImagesConfig
+	kind         string
+	placeholders []placeholder
+}
+
+func (c *placeholderCollector) collect(node *yaml.Node, path []string) {
 	switch node.Kind {
 	case yaml.DocumentNode:
 		for _, child := range node.Content {
-			placeholders = collectPlaceholders(child, placeholders, path)
+			c.collect(child, path)
 		}
 	case yaml.MappingNode:
+		if isContainerPath(path) {
+			c.collectContainer(node)
+		}
 		for i := 0; i < len(node.Content); i += 2 {
 			keyNode := node.Content[i]
 			valueNode := node.Content[i+1]
-			newPath := append(path, keyNode.Value)
-			if keyNode.Value == "image" && valueNode.Kind == yaml.ScalarNode {
-				if isImageField(newPath) {
-					if _, ok := isPlaceholderImage(valueNode.Value); ok {
-						placeholders = append(placeholders, valueNode)
-					}
+			newPath := append(slices.Clip(path), keyNode.Value)
+			if keyNode.Value == "image" && valueNode.Kind == yaml.ScalarNode && isImageField(newPath) {
+				c.addImage(valueNode, "", valueNode.Value)
+			} else if c.isConfiguredPath(newPath) {
+				switch valueNode.Kind {
+				case yaml.ScalarNode:
+					c.addImage(valueNode, "", valueNode.Value)
+				case yaml.MappingNode:
+					c.addHelmImage(valueNode)
 				}
 			}
-			placeholders = collectPlaceholders(valueNode, placeholders, newPath)
+			c.collect(valueNode, newPath)
 		}
 	case yaml.SequenceNode:
 		for _, child := range node.Content {
-			placeholders = collectPlaceholders(child, placeholders, append(path, "*"))
+			c.collect(child, append(slices.Clip(path), "*"))
+		}
+	}
+}
+
+// collectContainer collects the placeholders in the env vars and args of a container declared in the config.
+func (c *placeholderCollector) collectContainer(container *yaml.Node) {
+	if env := mappingValue(container, "env"); env != nil && env.Kind == yaml.SequenceNode {
+		for _, v := range env.Content {
+			name, value := mappingValue(v, "name"), mappingValue(v, "value")
+			if name == nil || value == nil || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			if slices.Contains(c.cfg.Env, name.Value) {
+				c.addImage(value, "", value.Value)
+			}
+		}
+	}
+
+	for _, key := range []string{"command", "args"} {
+		args := mappingValue(container, key)
+		if args == nil || args.Kind != yaml.SequenceNode {
+			continue
 		}
+		for i, arg := range args.Content {
+			if arg.Kind != yaml.ScalarNode {
+				continue
+			}
+			for _, flag := range c.cfg.Args {
+				if image, ok := strings.CutPrefix(arg.Value, flag+"="); ok {
+					c.addImage(arg, flag+"=", image)
+				} else if arg.Value == flag && i+1 < len(args.Content) && args.Content[i+1].Kind == yaml.ScalarNode {
+					c.addImage(args.Content[i+1], "", args.Content[i+1].Value)
+				}
+			}
+		}
+	}
+}
+
+func (c *placeholderCollector) addImage(node *yaml.Node, prefix string, image string) {
+	if base, ok := isPlaceholderImage(image); ok {
+		c.placeholders = append(c.placeholders, placeholder{node: node, field: imageField, base: base, prefix: prefix})
+	}
+}
+
+// addHelmImage collects a Helm-style image, e.g. {repository: server, tag: latest}.
+// The tag is only replaced if it is present.
+func (c *placeholderCollector) addHelmImage(node *yaml.Node) {
+	repository := mappingValue(node, "repository")
+	if repository == nil || repository.Kind != yaml.ScalarNode {
+		return
+	}
+	tag := mappingValue(node, "tag")
+	image := repository.Value
+	if tag != nil && tag.Kind == yaml.ScalarNode && tag.Value != "" {
+		image += ":" + tag.Value
+	}
+	base, ok := isPlaceholderImage(image)
+	if !ok {
+		return
+	}
+	c.placeholders = append(c.placeholders, placeholder{node: repository, field: helmRepository, base: base})
+	if tag != nil && tag.Kind == yaml.ScalarNode {
+		c.placeholders = append(c.placeholders, placeholder{node: tag, field: helmTag, base: base})
+	}
+}
+
+func (c *placeholderCollector) isConfiguredPath(path []string) bool {
+	for _, p := range c.cfg.Paths {
+		if p.Kind != "" && p.Kind != c.kind {
+			continue
+		}
+		// Paths are validated when the config is loaded.
+		if keys, err := p.keys(); err == nil && slices.Equal(keys, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// keys returns the field names of the path, with "*" for every item of a list.
+func (p ImagePath) keys() ([]string, error) {
+	s := strings.TrimSuffix(strings.TrimPrefix(p.Path, "{"), "}")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "$"), ".")
+	s = strings.ReplaceAll(s, "[*]", ".*")
+	if s == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+	if strings.ContainsAny(s, "[]") {
+		return nil, fmt.Errorf("unsupported path %q: only field names and [*] are supported", p.Path)
+	}
+	keys := strings.Split(s, ".")
+	if slices.Contains(keys, "") {
+		return nil, fmt.Errorf("invalid path %q", p.Path)
 	}
-	return placeholders
+	return keys, nil
+}
+
+// objectKind returns the kind of the object in a YAML document, or "" if it has none.
+func objectKind(doc *yaml.Node) string {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return ""
+	}
+	if kind := mappingValue(doc.Content[0], "kind"); kind != nil && kind.Kind == yaml.ScalarNode {
+		return kind.Value
+	}
+	return ""
 }
 
 func isPlaceholderImage(image string) (string, bool) {
@@ -158,21 +312,44 @@ func isPlaceholderImage(image string) (string, bool) {
 	return base, true
 }
 
+// mappingValue returns the value stored under key in a mapping node, or nil if there is none.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// containerPaths are the lists of containers in pods and in the pod templates of workloads.
+var containerPaths = []string{
+	"spec.containers",
+	"spec.initContainers",
+	"spec.template.spec.containers",
+	"spec.template.spec.initContainers",
+	"spec.jobTemplate.spec.template.spec.containers",
+	"spec.jobTemplate.spec.template.spec.initContainers",
+	"spec.podTemplate.spec.containers",
+	"spec.podTemplate.spec.initContainers",
+}
+
+// isContainerPath returns whether path is a container in one of the containerPaths.
+func isContainerPath(path []string) bool {
+	if len(path) == 0 || path[len(path)-1] != "*" {
+		return false
+	}
+	return slices.Contains(containerPaths, strings.Join(path[:len(path)-1], "."))
+}
+
 func isImageField(path []string) bool {
-	p := strings.Join(path, ".")
-	switch p {
-	case "image",
-		"spec.containers.*.image",
-		"spec.initContainers.*.image",
-		"spec.template.spec.containers.*.image",
-		"spec.template.spec.initContainers.*.image",
-		"spec.jobTemplate.spec.template.spec.containers.*.image",
-		"spec.jobTemplate.spec.template.spec.initContainers.*.image",
-		"spec.podTemplate.spec.containers.*.image",
-		"spec.podTemplate.spec.initContainers.*.image":
-		return true
+	if len(path) == 0 || path[len(path)-1] != "image" {
+		return false
 	}
-	return false
+	return len(path) == 1 || isContainerPath(path[:len(path)-1])
 }
 
 func getLineOffsets(content string) []int {
@@ -223,52 +400,288 @@ func findEnd(content string, start int, style yaml.Style) int {
 	return len(content)
 }
 
+// DeployOptions configures Deploy.
+type DeployOptions struct {
+	// Digests maps image names to the digests pushed by the build; placeholder images with a
+	// digest are pinned to it, so the deploy is reproducible even if the tag is later moved.
+	Digests map[string]string
+
+	// Profile selects a profile from .ap/deploy.yaml, if set.
+	Profile string
+
+	// Target overrides the cluster and namespace configured in .ap/deploy.yaml.
+	Target Target
+
+	// WaitForRollouts waits for Deployments, StatefulSets and DaemonSets to become ready,
+	// in addition to the rollout waiting configured in .ap/deploy.yaml.
+	WaitForRollouts bool
+
+	// Prune deletes resources recorded by previous deploys that are no longer in any manifest.
+	Prune bool
+
+	// CreateNamespace creates the target namespace if it does not exist,
+	// in addition to the namespace creation configured in .ap/deploy.yaml.
+	CreateNamespace bool
+
+	// FailOnConflicts fails the apply on conflicts with fields owned by other field managers, instead of taking
+	// the fields over, in addition to the failOnConflicts configured in .ap/deploy.yaml.
+	FailOnConflicts bool
+
+	// Diff prints the changes a server-side dry run reports for every manifest, and a summary of them, before applying any.
+	Diff bool
+
+	// Canary, if set, is the percentage of traffic to send to canary copies of the Deployments behind a Service,
+	// instead of updating the Deployments themselves. The next full deploy replaces the canaries.
+	Canary int
+
+	// DryRun, if set, makes Deploy apply with a server-side dry run and record the changes it would make,
+	// without changing the cluster.
+	DryRun *dryrun.Report
+}
+
 // Deploy deploys k8s manifests found in k8s directories.
-func Deploy(ctx context.Context, root string) error {
+func Deploy(ctx context.Context, root string, opt DeployOptions) error {
+	cfg, err := LoadDeployConfig(root)
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Profile(opt.Profile)
+	if err != nil {
+		return err
+	}
+	cfg.Target = cfg.Target.WithOverrides(profile.Target).WithOverrides(opt.Target)
+	cfg.FailOnConflicts = cfg.FailOnConflicts || opt.FailOnConflicts
+	for _, chart := range cfg.Charts {
+		if kind, err := sourceKind(filepath.Join(root, chart.Path)); err != nil || kind != sourceHelm {
+			return fmt.Errorf("chart %s in .ap/deploy.yaml is not a helm chart directory", chart.Path)
+		}
+	}
+
 	manifests, err := findManifests(root)
 	if err != nil {
 		return err
 	}
+	manifests, err = cfg.profileManifests(root, manifests, opt.Profile)
+	if err != nil {
+		return err
+	}
+	if ok, err := requireRenderers(ctx, manifests); !ok {
+		return err
+	}
 
-	imageRepository := os.Getenv("IMAGE_PREFIX")
+	imageRepository := cmp.Or(profile.ImagePrefix, os.Getenv("IMAGE_PREFIX"))
 	if imageRepository == "" {
 		return fmt.Errorf("IMAGE_PREFIX is not set; it is required for deploy")
 	}
-	tag := os.Getenv("IMAGE_TAG")
-	if tag == "" {
-		tag = "latest"
+	tag := cmp.Or(profile.ImageTag, os.Getenv("IMAGE_TAG"), "latest")
+
+	annotations := provenance(ctx, root)
+	if cfg.CreateNamespace || opt.CreateNamespace {
+		if err := ensureNamespace(ctx, cfg.Target, annotations, opt.DryRun); err != nil {
+			return err
+		}
 	}
 
+	r := &manifestRenderer{
+		root:            root,
+		cfg:             cfg,
+		imageRepository: imageRepository,
+		tag:             tag,
+		digests:         opt.Digests,
+		inventory:       inventoryName(root, cfg),
+		annotations:     annotations,
+	}
+	record := deployRecord{
+		GitSHA:    annotations[GitSHAAnnotation],
+		Profile:   opt.Profile,
+		Target:    cfg.Target,
+		Inventory: r.inventory,
+		Canary:    opt.Canary,
+	}
+	if opt.Canary != 0 {
+		return deployCanary(ctx, r, manifests, record, cfg.WaitForRollouts || opt.WaitForRollouts, opt.DryRun)
+	}
+
+	// Every manifest is rendered before any is applied, so that a manifest that fails to render
+	// does not leave a partial deploy behind, and so that all the changes can be previewed.
+	inventory := r.inventory
+	var applied []resourceRef
+	var rendered []renderedManifest
 	for _, manifest := range manifests {
 		relPath, _ := filepath.Rel(root, manifest)
+		replaced, refs, err := r.render(ctx, manifest)
+		if err != nil {
+			return err
+		}
+		applied = append(applied, refs...)
+		rendered = append(rendered, renderedManifest{relPath: relPath, content: replaced})
+	}
+	if opt.Diff {
+		if _, err := cfg.Target.diff(ctx, rendered, cfg.FailOnConflicts, os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range rendered {
+		relPath, replaced := m.relPath, m.content
 
 		klog.Infof("Applying manifest %s", relPath)
 
-		content, err := os.ReadFile(manifest)
+		// Jobs that finished and whose spec changed, or whose pod template changed, are replaced, so that they run again.
+		objs, err := cfg.Target.apply(ctx, replaced, applyOptions{failOnConflicts: cfg.FailOnConflicts, replaceJobs: true, report: opt.DryRun})
 		if err != nil {
+			return fmt.Errorf("failed to apply %s: %w", relPath, err)
+		}
+		if opt.DryRun != nil {
+			// Nothing was applied, so there is nothing to wait for.
+			continue
+		}
+
+		// Wait for one-shot Jobs (e.g. migrations) and rollouts before applying later manifests.
+		waitTargets, err := findWaitTargets(replaced, cfg.WaitForRollouts || opt.WaitForRollouts)
+		if err != nil {
+			return fmt.Errorf("failed to find resources to wait for in %s: %w", relPath, err)
+		}
+		for _, target := range waitTargets {
+			target.UID = target.appliedUID(objs)
+			waitFn := waitForJob
+			if hasRollout(target.Kind) {
+				waitFn = waitForRollout
+			}
+			if err := waitFn(ctx, cfg.Target, target); err != nil {
+				return fmt.Errorf("deploy of %s failed: %w", relPath, err)
+			}
+		}
+	}
+
+	previous, err := readInventory(ctx, cfg.Target, inventory)
+	if err != nil {
+		return err
+	}
+	stale := staleResources(previous, applied)
+
+	// A full deploy promotes the canaries of its Deployments, by deleting them now that the Deployments are updated.
+	notCanaries := staleResources(stale, canaryRefs(applied))
+	if err := deleteManaged(ctx, cfg.Target, inventory, staleResources(stale, notCanaries), opt.DryRun); err != nil {
+		return err
+	}
+	stale = notCanaries
+
+	if opt.Prune {
+		if err := deleteManaged(ctx, cfg.Target, inventory, stale, opt.DryRun); err != nil {
 			return err
 		}
+	} else {
+		// Keep tracking the stale resources, so that a later deploy with --prune removes them.
+		applied = append(applied, stale...)
+	}
+	if opt.DryRun != nil {
+		return nil
+	}
+	if err := writeInventory(ctx, cfg.Target, inventory, applied); err != nil {
+		return err
+	}
+	var contents []string
+	for _, m := range rendered {
+		contents = append(contents, m.content)
+	}
+	record.Time = time.Now()
+	record.Manifest = joinManifests(contents)
+	return recordDeploy(ctx, root, record)
+}
 
-		replaced, err := replacePlaceholderImages(string(content), imageRepository, tag)
+// deployCanary applies canary copies of the Deployments behind a Service in manifests, as configured by record.Canary.
+func deployCanary(ctx context.Context, r *manifestRenderer, manifests []string, record deployRecord, waitForRollouts bool, report *dryrun.Report) error {
+	var rendered []string
+	for _, manifest := range manifests {
+		content, _, err := r.render(ctx, manifest)
 		if err != nil {
-			return fmt.Errorf("failed to replace placeholders in %s: %w", relPath, err)
+			return err
 		}
+		rendered = append(rendered, content)
+	}
+	canary, err := canaryManifest(joinManifests(rendered), record.Canary)
+	if err != nil {
+		return err
+	}
+	// The canaries are copies of resources that are already labeled, so this only lists them.
+	_, refs, err := stampManagedBy(canary, r.inventory, r.annotations, r.cfg.Namespace)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("Applying canary for %d%% of the traffic", record.Canary)
+	if _, err := r.cfg.Target.apply(ctx, canary, applyOptions{failOnConflicts: r.cfg.FailOnConflicts, report: report}); err != nil {
+		return fmt.Errorf("failed to apply the canary: %w", err)
+	}
+	if report != nil {
+		return nil
+	}
+	if err := waitForWorkloads(ctx, r.cfg.Target, canary, waitForRollouts); err != nil {
+		return fmt.Errorf("canary failed: %w", err)
+	}
+
+	previous, err := readInventory(ctx, r.cfg.Target, r.inventory)
+	if err != nil {
+		return err
+	}
+	if err := writeInventory(ctx, r.cfg.Target, r.inventory, append(previous, staleResources(refs, previous)...)); err != nil {
+		return err
+	}
+	record.Time = time.Now()
+	record.Manifest = canary
+	return recordDeploy(ctx, r.root, record)
+}
 
-		cmd := exec.CommandContext(ctx, "kubectl", "apply", "-f", "-")
-		cmd.Stdin = bytes.NewBufferString(replaced)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+// manifestRenderer renders the manifests of an ap root for deploy.
+type manifestRenderer struct {
+	root            string
+	cfg             *DeployConfig
+	imageRepository string
+	tag             string
+	digests         map[string]string
+	inventory       string
+	annotations     map[string]string
+}
 
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("kubectl apply failed for %s: %w", relPath, err)
+// render renders the manifest, replaces its placeholder images and labels its resources as managed by the inventory,
+// returning the result and the resources it contains.
+func (r *manifestRenderer) render(ctx context.Context, manifest string) (string, []resourceRef, error) {
+	relPath, _ := filepath.Rel(r.root, manifest)
+	content, err := renderSource(ctx, r.root, manifest, r.cfg)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render %s: %w", relPath, err)
+	}
+
+	replaced, err := replacePlaceholderImages(content, r.imageRepository, r.tag, r.digests, &r.cfg.Images)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to replace placeholders in %s: %w", relPath, err)
+	}
+
+	replaced, refs, err := stampManagedBy(replaced, r.inventory, r.annotations, r.cfg.Namespace)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to label resources in %s: %w", relPath, err)
+	}
+	return replaced, refs, nil
+}
+
+// joinManifests joins YAML streams into one, leaving out empty ones.
+func joinManifests(manifests []string) string {
+	var docs []string
+	for _, manifest := range manifests {
+		if strings.TrimSpace(manifest) != "" {
+			docs = append(docs, strings.TrimSuffix(manifest, "\n")+"\n")
 		}
 	}
-	return nil
+	return strings.Join(docs, "---\n")
 }
 
+// findManifests returns the YAML manifests under k8s directories, in path order.
+// A directory containing a kustomization or a helm chart (Chart.yaml) is returned as a
+// single entry in place of the files within it, and is rendered before it is applied.
 func findManifests(root string) ([]string, error) {
 	ignoreList := walker.NewIgnoreList([]string{".git", "vendor", "node_modules"})
-	return walker.Walk(root, ignoreList, func(path string, info os.FileInfo) bool {
+	files, err := walker.Walk(root, ignoreList, func(path string, info os.FileInfo) bool {
 		if info.IsDir() {
 			return false
 		}
@@ -291,6 +704,42 @@ func findManifests(root string) ([]string, error) {
 		}
 
 		ext := filepath.Ext(path)
-		return ext == ".yaml" || ext == ".yml"
+		return ext == ".yaml" || ext == ".yml" || info.Name() == "Kustomization"
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	renderedDirs := make(map[string]bool)
+	for _, file := range files {
+		name := filepath.Base(file)
+		if name == chartFile || slices.Contains(kustomizationFiles, name) {
+			renderedDirs[filepath.Dir(file)] = true
+		}
+	}
+
+	// renderedAncestor returns the outermost kustomization or chart directory containing path.
+	renderedAncestor := func(path string) string {
+		var outermost string
+		for dir := filepath.Dir(path); dir != root && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if renderedDirs[dir] {
+				outermost = dir
+			}
+		}
+		return outermost
+	}
+
+	var manifests []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if dir := renderedAncestor(file); dir != "" {
+			if !seen[dir] {
+				seen[dir] = true
+				manifests = append(manifests, dir)
+			}
+			continue
+		}
+		manifests = append(manifests, file)
+	}
+	return manifests, nil
 }
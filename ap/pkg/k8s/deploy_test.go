@@ -67,6 +67,42 @@ func TestFindManifests(t *testing.T) {
 				"k8s/resource.yaml",
 			},
 		},
+		{
+			name: "kustomization",
+			files: []string{
+				"k8s/app/kustomization.yaml",
+				"k8s/app/deployment.yaml",
+				"k8s/app/patches/replicas.yaml",
+				"k8s/namespace.yaml",
+			},
+			expected: []string{
+				"k8s/app",
+				"k8s/namespace.yaml",
+			},
+		},
+		{
+			name: "helm chart",
+			files: []string{
+				"k8s/chart/Chart.yaml",
+				"k8s/chart/values.yaml",
+				"k8s/chart/templates/deployment.yaml",
+				"k8s/chart/charts/dep/Chart.yaml",
+			},
+			expected: []string{
+				"k8s/chart",
+			},
+		},
+		{
+			name: "kustomization at k8s root",
+			files: []string{
+				"k8s/Kustomization",
+				"k8s/base/kustomization.yaml",
+				"k8s/base/service.yaml",
+			},
+			expected: []string{
+				"k8s",
+			},
+		},
 		{
 			name: "nested k8s directory",
 			files: []string{
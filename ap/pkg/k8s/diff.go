@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/linediff"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// renderedManifest is a manifest rendered for deploy.
+type renderedManifest struct {
+	relPath string
+	content string
+}
+
+// resourceDiff is the change an apply would make to one resource.
+type resourceDiff struct {
+	// Resource is the kind, namespace and name of the resource, e.g. "Deployment demo/server".
+	Resource string
+	// Created is set if the resource does not exist yet.
+	Created bool
+	// Added and Removed are the number of lines added and removed.
+	Added   int
+	Removed int
+}
+
+// diff compares each object in the manifests with the object a server-side dry run of its apply returns,
+// writing a unified diff of the YAML of each changed object to out followed by a summary of the changes,
+// which it returns. failOnConflicts is as for apply, and Jobs are replaced as deploy replaces them.
+func (t Target) diff(ctx context.Context, manifests []renderedManifest, failOnConflicts bool, out io.Writer) ([]resourceDiff, error) {
+	var r *resources
+	var diffs []resourceDiff
+	for _, m := range manifests {
+		objs, err := decodeObjects(m.content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", m.relPath, err)
+		}
+		if len(objs) > 0 && r == nil {
+			if r, err = t.resources(ctx); err != nil {
+				return nil, err
+			}
+		}
+		for _, obj := range objs {
+			if err := stampSpecHash(obj); err != nil {
+				return nil, fmt.Errorf("failed to diff %s in %s: %w", objectName(obj), m.relPath, err)
+			}
+			// A Job that deploy replaces is created again, from its manifest.
+			replace, err := r.mustReplace(ctx, obj, !failOnConflicts)
+			var live *unstructured.Unstructured
+			merged := obj
+			if err == nil && !replace {
+				live, merged, err = r.applyObject(ctx, obj, !failOnConflicts, true)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to diff %s in %s: %w", objectName(obj), m.relPath, err)
+			}
+			d, text, err := diffObject(live, merged)
+			if err != nil {
+				return nil, fmt.Errorf("failed to diff %s in %s: %w", objectName(obj), m.relPath, err)
+			}
+			if text == "" {
+				continue
+			}
+			fmt.Fprintf(out, "--- %s (live)\n+++ %s (merged)\n%s", d.Resource, d.Resource, text)
+			diffs = append(diffs, d)
+		}
+	}
+
+	fmt.Fprintln(out)
+	if len(diffs) == 0 {
+		fmt.Fprintln(out, "No changes.")
+		return nil, nil
+	}
+	created := 0
+	fmt.Fprintln(out, "Changes:")
+	for _, d := range diffs {
+		if d.Created {
+			created++
+			fmt.Fprintf(out, "  create  %s\n", d.Resource)
+		} else {
+			fmt.Fprintf(out, "  change  %s (+%d -%d)\n", d.Resource, d.Added, d.Removed)
+		}
+	}
+	fmt.Fprintf(out, "%d to create, %d to change.\n", created, len(diffs)-created)
+	return diffs, nil
+}
+
+// diffObject returns the change from live (nil if it does not exist) to merged, and its unified diff,
+// which is empty if nothing changes. Status and the metadata the server updates on every change are left out.
+func diffObject(live, merged *unstructured.Unstructured) (resourceDiff, string, error) {
+	d := resourceDiff{Resource: merged.GetKind() + " " + merged.GetName(), Created: live == nil}
+	if merged.GetNamespace() != "" {
+		d.Resource = merged.GetKind() + " " + merged.GetNamespace() + "/" + merged.GetName()
+	}
+	var before []byte
+	if live != nil {
+		var err error
+		if before, err = yaml.Marshal(appliedContent(live)); err != nil {
+			return d, "", err
+		}
+	}
+	after, err := yaml.Marshal(appliedContent(merged))
+	if err != nil {
+		return d, "", err
+	}
+	lines := linediff.Compute(linediff.Split(string(before)), linediff.Split(string(after)))
+	for _, l := range lines {
+		switch l.Op {
+		case linediff.Insert:
+			d.Added++
+		case linediff.Delete:
+			d.Removed++
+		}
+	}
+	return d, linediff.Hunks(lines), nil
+}
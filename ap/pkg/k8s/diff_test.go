@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestTargetDiff(t *testing.T) {
+	client, dynamicClient := newFakeClient(t, liveManifest)
+	ctx := kubeclient.NewContext(t.Context(), client)
+
+	manifests := []renderedManifest{
+		{relPath: "k8s/settings.yaml", content: applyManifest},
+		{relPath: "k8s/empty.yaml", content: "---\n"},
+	}
+	var out bytes.Buffer
+	diffs, err := Target{Namespace: "demo"}.diff(ctx, manifests, false, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []resourceDiff{
+		{Resource: "ConfigMap demo/settings", Added: 1, Removed: 1},
+		{Resource: "Namespace extra", Created: true, Added: 4},
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("diffs = %+v, want %+v", diffs, want)
+	}
+	wantOut := `--- ConfigMap demo/settings (live)
++++ ConfigMap demo/settings (merged)
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  mode: slow
++  mode: fast
+ kind: ConfigMap
+ metadata:
+   labels:
+--- Namespace extra (live)
++++ Namespace extra (merged)
+@@ -0,0 +1,4 @@
++apiVersion: v1
++kind: Namespace
++metadata:
++  name: extra
+
+Changes:
+  change  ConfigMap demo/settings (+1 -1)
+  create  Namespace extra
+1 to create, 1 to change.
+`
+	if got := out.String(); got != wantOut {
+		t.Errorf("output:\n%s\nwant:\n%s", got, wantOut)
+	}
+	for _, action := range dynamicClient.Actions() {
+		if patch, ok := action.(clienttesting.PatchActionImpl); ok && len(patch.PatchOptions.DryRun) == 0 {
+			t.Errorf("diff applied %s without a dry run", patch.GetName())
+		}
+	}
+}
+
+func TestTargetDiffFailure(t *testing.T) {
+	client, _ := newFakeClient(t, "")
+	ctx := kubeclient.NewContext(t.Context(), client)
+
+	var out bytes.Buffer
+	manifest := "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"
+	_, err := Target{}.diff(ctx, []renderedManifest{{relPath: "k8s/widget.yaml", content: manifest}}, false, &out)
+	if err == nil || !strings.Contains(err.Error(), "k8s/widget.yaml") {
+		t.Errorf("diff() error = %v, want a failure for k8s/widget.yaml", err)
+	}
+}
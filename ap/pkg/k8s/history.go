@@ -0,0 +1,219 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxLocalHistory is the number of deploys kept in .build/deploy-manifest.json, across all targets.
+	maxLocalHistory = 20
+
+	// maxClusterHistory is the number of deploys kept in the history ConfigMap.
+	// ConfigMaps are limited to 1MiB, so only enough deploys to roll back a canary and a deploy are kept.
+	maxClusterHistory = 3
+
+	// historyKey is the ConfigMap data key holding the deploy history, as JSON.
+	historyKey = "history.json"
+)
+
+// deployRecord is a deploy recorded so that it can be rolled back.
+type deployRecord struct {
+	// Time is when the deploy finished.
+	Time time.Time `json:"time"`
+
+	// GitSHA is the commit the deploy was made from, if known.
+	GitSHA string `json:"gitSHA,omitempty"`
+
+	// Profile is the profile from .ap/deploy.yaml that was deployed.
+	Profile string `json:"profile,omitempty"`
+
+	// Target is the cluster and namespace that were deployed to.
+	Target Target `json:"target"`
+
+	// Inventory is the name of the inventory ConfigMap of the deploy.
+	Inventory string `json:"inventory"`
+
+	// Canary is the percentage of traffic of a canary deploy, or 0 for a full deploy.
+	Canary int `json:"canary,omitempty"`
+
+	// Manifest is everything that was applied, after rendering and image replacement.
+	Manifest string `json:"manifest"`
+}
+
+// matches returns true if the record is a deploy to kube tracked by inventory.
+func (r *deployRecord) matches(kube Target, inventory string) bool {
+	return r.Target == kube && r.Inventory == inventory
+}
+
+// deployHistory is the contents of .build/deploy-manifest.json, and of the history ConfigMap.
+type deployHistory struct {
+	// Deploys are the recorded deploys, oldest first.
+	Deploys []deployRecord `json:"deploys"`
+}
+
+// localHistoryPath returns where the deploys from root are recorded.
+func localHistoryPath(root string) string {
+	return filepath.Join(root, ".build", "deploy-manifest.json")
+}
+
+// historyConfigMapName returns the name of the ConfigMap recording the deploys tracked by inventory.
+func historyConfigMapName(inventory string) string {
+	return inventory + "-history"
+}
+
+// readLocalHistory returns the deploys recorded in .build/deploy-manifest.json, which may not exist yet.
+func readLocalHistory(root string) (*deployHistory, error) {
+	path := localHistoryPath(root)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &deployHistory{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	var history deployHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return &history, nil
+}
+
+// writeLocalHistory replaces the deploys to kube tracked by inventory in .build/deploy-manifest.json with records.
+func writeLocalHistory(root string, kube Target, inventory string, records []deployRecord) error {
+	history, err := readLocalHistory(root)
+	if err != nil {
+		return err
+	}
+	var deploys []deployRecord
+	for _, record := range history.Deploys {
+		if !record.matches(kube, inventory) {
+			deploys = append(deploys, record)
+		}
+	}
+	deploys = append(deploys, records...)
+	if len(deploys) > maxLocalHistory {
+		deploys = deploys[len(deploys)-maxLocalHistory:]
+	}
+
+	path := localHistoryPath(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(deployHistory{Deploys: deploys}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// readClusterHistory returns the deploys recorded in the history ConfigMap, or nil if there is none.
+func readClusterHistory(ctx context.Context, kube Target, inventory string) ([]deployRecord, error) {
+	name := historyConfigMapName(inventory)
+	data, err := readConfigMap(ctx, kube, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deploy history %s: %w", name, err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+	var history deployHistory
+	if err := json.Unmarshal([]byte(data[historyKey]), &history); err != nil {
+		return nil, fmt.Errorf("failed to parse deploy history %s: %w", name, err)
+	}
+	return history.Deploys, nil
+}
+
+// historyData returns the data of the ConfigMap recording the last deploys of records.
+func historyData(records []deployRecord) (map[string]string, error) {
+	if len(records) > maxClusterHistory {
+		records = records[len(records)-maxClusterHistory:]
+	}
+	data, err := json.Marshal(deployHistory{Deploys: records})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{historyKey: string(data)}, nil
+}
+
+// writeClusterHistory records the last deploys of records in the history ConfigMap.
+func writeClusterHistory(ctx context.Context, kube Target, inventory string, records []deployRecord) error {
+	name := historyConfigMapName(inventory)
+	data, err := historyData(records)
+	if err != nil {
+		return fmt.Errorf("failed to build deploy history %s: %w", name, err)
+	}
+	if err := applyConfigMap(ctx, kube, name, data); err != nil {
+		return fmt.Errorf("failed to write deploy history %s: %w", name, err)
+	}
+	return nil
+}
+
+// loadHistory returns the deploys to kube tracked by inventory, oldest first.
+// The history ConfigMap is used if it exists, as it also records deploys made from other machines (e.g. CI);
+// otherwise the deploys recorded in .build/deploy-manifest.json are used.
+func loadHistory(ctx context.Context, root string, kube Target, inventory string) ([]deployRecord, error) {
+	records, err := readClusterHistory(ctx, kube, inventory)
+	if err != nil {
+		return nil, err
+	}
+	if records != nil {
+		return records, nil
+	}
+
+	history, err := readLocalHistory(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range history.Deploys {
+		if record.matches(kube, inventory) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// saveHistory records records as the deploys to kube tracked by inventory, both locally and in the cluster.
+// Failing to write the history ConfigMap (e.g. because the manifests are too large for a ConfigMap) is only logged,
+// as the deploy itself succeeded and can still be rolled back from the local history.
+func saveHistory(ctx context.Context, root string, kube Target, inventory string, records []deployRecord) error {
+	if err := writeLocalHistory(root, kube, inventory, records); err != nil {
+		return err
+	}
+	if err := writeClusterHistory(ctx, kube, inventory, records); err != nil {
+		klog.Warningf("%v; rollbacks from other machines will not see this deploy", err)
+	}
+	return nil
+}
+
+// recordDeploy appends record to the deploy history.
+func recordDeploy(ctx context.Context, root string, record deployRecord) error {
+	records, err := loadHistory(ctx, root, record.Target, record.Inventory)
+	if err != nil {
+		return err
+	}
+	return saveHistory(ctx, root, record.Target, record.Inventory, append(records, record))
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLoadHistory(t *testing.T) {
+	root := t.TempDir()
+	prod := Target{Namespace: "prod"}
+	staging := Target{Namespace: "staging"}
+	v1 := deployRecord{Time: time.Unix(1, 0).UTC(), Target: prod, Inventory: "inv", Manifest: "v1"}
+	v2 := deployRecord{Time: time.Unix(2, 0).UTC(), Target: prod, Inventory: "inv", Manifest: "v2"}
+	other := deployRecord{Time: time.Unix(3, 0).UTC(), Target: staging, Inventory: "inv", Manifest: "staging"}
+	if err := writeLocalHistory(root, prod, "inv", []deployRecord{v1, v2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLocalHistory(root, staging, "inv", []deployRecord{other}); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster, err := json.Marshal(deployHistory{Deploys: []deployRecord{v2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a history ConfigMap, the local history is used.
+	clientset := fake.NewClientset()
+	ctx := kubeclient.NewContext(t.Context(), &kubeclient.Client{Interface: clientset})
+
+	got, err := loadHistory(ctx, root, prod, "inv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []deployRecord{v1, v2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loadHistory() = %+v, want %+v", got, want)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "inv-history", Namespace: "prod"},
+		Data:       map[string]string{historyKey: string(cluster)},
+	}
+	if err := clientset.Tracker().Add(configMap); err != nil {
+		t.Fatal(err)
+	}
+	got, err = loadHistory(ctx, root, prod, "inv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []deployRecord{v2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loadHistory() with a history ConfigMap = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteClusterHistory(t *testing.T) {
+	var records []deployRecord
+	for i := range maxClusterHistory + 2 {
+		records = append(records, deployRecord{Time: time.Unix(int64(i), 0).UTC(), Inventory: "inv", Manifest: "v" + strconv.Itoa(i)})
+	}
+	clientset := fake.NewClientset()
+	ctx := kubeclient.NewContext(t.Context(), &kubeclient.Client{Interface: clientset})
+	prod := Target{Namespace: "prod"}
+
+	if err := writeClusterHistory(ctx, prod, "inv", records); err != nil {
+		t.Fatalf("writeClusterHistory failed: %v", err)
+	}
+	got, err := readClusterHistory(ctx, prod, "inv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := records[len(records)-maxClusterHistory:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the history to keep only the last %d deploys, got %+v", maxClusterHistory, got)
+	}
+}
@@ -15,6 +15,7 @@
 package k8s
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -191,7 +192,7 @@ metadata:
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := replacePlaceholderImages(tt.input, "my-repo", "v1")
+			got, err := replacePlaceholderImages(tt.input, "my-repo", "v1", nil, nil)
 			if err != nil {
 				t.Fatalf("replacePlaceholderImages() error = %v", err)
 			}
@@ -201,3 +202,196 @@ metadata:
 		})
 	}
 }
+
+func TestReplacePlaceholderImagesWithDigests(t *testing.T) {
+	digests := map[string]string{
+		"example-server": "sha256:0123456789abcdef",
+	}
+	input := `
+spec:
+  containers:
+  - name: server
+    image: example-server
+  - name: sidecar
+    image: example-sidecar:latest
+`
+	expected := `
+spec:
+  containers:
+  - name: server
+    image: my-repo/example-server@sha256:0123456789abcdef
+  - name: sidecar
+    image: my-repo/example-sidecar:v1
+`
+	got, err := replacePlaceholderImages(input, "my-repo", "v1", digests, nil)
+	if err != nil {
+		t.Fatalf("replacePlaceholderImages() error = %v", err)
+	}
+	if got != expected {
+		t.Errorf("replacePlaceholderImages() = %v, want %v", got, expected)
+	}
+}
+
+func TestReplacePlaceholderImagesConfigured(t *testing.T) {
+	cfg := &ImagesConfig{
+		Paths: []ImagePath{
+			{Kind: "Worker", Path: "spec.workers[*].image"},
+			{Path: "spec.values.image"},
+		},
+		Env:  []string{"SIDECAR_IMAGE"},
+		Args: []string{"--proxy-image", "--init-image"},
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "env var",
+			input: `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        image: server
+        env:
+        - name: SIDECAR_IMAGE
+          value: "sidecar"
+        - name: OTHER
+          value: other
+`,
+			expected: `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        image: my-repo/server:v1
+        env:
+        - name: SIDECAR_IMAGE
+          value: my-repo/sidecar:v1
+        - name: OTHER
+          value: other
+`,
+		},
+		{
+			name: "args",
+			input: `
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: job
+            args: ["--proxy-image=proxy", "--init-image", init, "--other=other"]
+`,
+			expected: `
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: my-repo/job:v1
+            args: [--proxy-image=my-repo/proxy:v1, "--init-image", my-repo/init:v1, "--other=other"]
+`,
+		},
+		{
+			name: "custom resource path",
+			input: `
+kind: Worker
+spec:
+  workers:
+  - image: worker
+  - image: gcr.io/other/worker
+`,
+			expected: `
+kind: Worker
+spec:
+  workers:
+  - image: my-repo/worker:v1
+  - image: gcr.io/other/worker
+`,
+		},
+		{
+			name: "path of another kind",
+			input: `
+kind: Other
+spec:
+  workers:
+  - image: worker
+`,
+			expected: `
+kind: Other
+spec:
+  workers:
+  - image: worker
+`,
+		},
+		{
+			name: "helm-style image",
+			input: `
+kind: HelmRelease
+spec:
+  values:
+    image:
+      repository: server
+      tag: latest
+`,
+			expected: `
+kind: HelmRelease
+spec:
+  values:
+    image:
+      repository: my-repo/server
+      tag: v1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replacePlaceholderImages(tt.input, "my-repo", "v1", nil, cfg)
+			if err != nil {
+				t.Fatalf("replacePlaceholderImages() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("replacePlaceholderImages() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImagePathKeys(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "spec.workers[*].image", want: []string{"spec", "workers", "*", "image"}},
+		{path: "{.spec.image}", want: []string{"spec", "image"}},
+		{path: "$.spec.image", want: []string{"spec", "image"}},
+		{path: "spec.workers[0].image", wantErr: true},
+		{path: "spec..image", wantErr: true},
+		{path: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ImagePath{Path: tt.path}.keys()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("keys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	"gopkg.in/yaml.v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// WaitAnnotation controls whether deploy waits for a resource.
+	// Jobs are waited for by default; set to "none" to opt out, or "complete" to be explicit.
+	// Deployments, StatefulSets and DaemonSets are waited for with "ready", or by default when
+	// rollout waiting is enabled.
+	WaitAnnotation = "ap.gke.io/wait"
+
+	// WaitTimeoutAnnotation overrides how long deploy waits for the resource (e.g. "30m").
+	WaitTimeoutAnnotation = "ap.gke.io/wait-timeout"
+
+	// SpecHashAnnotation records a hash of the spec of a Job as it was applied, so that deploy only runs
+	// a finished Job again when its spec in the manifests changes.
+	SpecHashAnnotation = "ap.gke.io/spec-hash"
+
+	defaultJobTimeout = 10 * time.Minute
+
+	// jobDeletionTimeout is how long deploy waits for a Job it replaces, and its pods, to be deleted.
+	jobDeletionTimeout = 2 * time.Minute
+)
+
+// jobKind is the group and kind of Jobs.
+var jobKind = schema.GroupKind{Group: "batch", Kind: "Job"}
+
+// waitTarget is a resource that deploy should wait on after applying it.
+type waitTarget struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Timeout   time.Duration
+	// UID, if set, is the UID of the applied Job; waiting fails if the Job is replaced by another one.
+	UID types.UID
+}
+
+func (t *waitTarget) String() string {
+	if t.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", strings.ToLower(t.Kind), t.Namespace, t.Name)
+	}
+	return fmt.Sprintf("%s %s", strings.ToLower(t.Kind), t.Name)
+}
+
+// appliedUID returns the UID of the Job of t among applied, or "" if t is not a Job or is not among them.
+func (t *waitTarget) appliedUID(applied []*unstructured.Unstructured) types.UID {
+	if t.Kind != jobKind.Kind {
+		return ""
+	}
+	for _, obj := range applied {
+		if obj.GroupVersionKind().GroupKind() == jobKind && obj.GetName() == t.Name && (t.Namespace == "" || obj.GetNamespace() == t.Namespace) {
+			return obj.GetUID()
+		}
+	}
+	return ""
+}
+
+// resourceHeader is the subset of a manifest we need to identify the resource.
+type resourceHeader struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name        string            `yaml:"name"`
+		Namespace   string            `yaml:"namespace"`
+		Labels      map[string]string `yaml:"labels"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+}
+
+// findWaitTargets returns the resources in the manifest that deploy should wait for.
+// If waitForRollouts is true, workloads with a rollout are waited for unless they opt out.
+func findWaitTargets(content string, waitForRollouts bool) ([]waitTarget, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	var targets []waitTarget
+	for {
+		var obj resourceHeader
+		err := decoder.Decode(&obj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+
+		mode := obj.Metadata.Annotations[WaitAnnotation]
+		switch mode {
+		case "":
+			if obj.Kind != "Job" && !(waitForRollouts && hasRollout(obj.Kind)) {
+				continue
+			}
+		case "none":
+			continue
+		case "complete":
+			if obj.Kind != "Job" {
+				return nil, fmt.Errorf("%s=complete is only supported on Jobs, found on %s %s", WaitAnnotation, obj.Kind, obj.Metadata.Name)
+			}
+		case "ready":
+			if !hasRollout(obj.Kind) {
+				return nil, fmt.Errorf("%s=ready is only supported on Deployments, StatefulSets and DaemonSets, found on %s %s", WaitAnnotation, obj.Kind, obj.Metadata.Name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown value %q for %s on %s %s", mode, WaitAnnotation, obj.Kind, obj.Metadata.Name)
+		}
+
+		if obj.Metadata.Name == "" {
+			return nil, fmt.Errorf("cannot wait for %s without metadata.name (generateName is not supported)", obj.Kind)
+		}
+
+		timeout := defaultJobTimeout
+		if obj.Kind != "Job" {
+			timeout = defaultRolloutTimeout
+		}
+		if s := obj.Metadata.Annotations[WaitTimeoutAnnotation]; s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q on %s %s: %w", WaitTimeoutAnnotation, s, obj.Kind, obj.Metadata.Name, err)
+			}
+			timeout = d
+		}
+
+		targets = append(targets, waitTarget{
+			Kind:      obj.Kind,
+			Name:      obj.Metadata.Name,
+			Namespace: obj.Metadata.Namespace,
+			Timeout:   timeout,
+		})
+	}
+	return targets, nil
+}
+
+// jobResult inspects the job conditions, returning done=true once the job has finished,
+// and a non-nil error if it failed.
+func jobResult(job *batchv1.Job) (bool, error) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return true, fmt.Errorf("job failed: %s: %s", c.Reason, c.Message)
+		}
+	}
+	return false, nil
+}
+
+// waitForJob streams the logs of the job and waits for it to complete or fail.
+func waitForJob(ctx context.Context, kube Target, target waitTarget) error {
+	klog.Infof("Waiting for %s to complete (timeout %v)", target.String(), target.Timeout)
+
+	client, err := kube.client(ctx)
+	if err != nil {
+		return err
+	}
+	namespace := cmp.Or(target.Namespace, kube.Namespace, client.Namespace)
+
+	ctx, cancel := context.WithTimeout(ctx, target.Timeout)
+	defer cancel()
+
+	logsCtx, cancelLogs := context.WithCancel(ctx)
+	defer cancelLogs()
+	var logsDone chan struct{}
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		var job *batchv1.Job
+		err := kubeclient.Retry(ctx, func() error {
+			var err error
+			job, err = client.BatchV1().Jobs(namespace).Get(ctx, target.Name, metav1.GetOptions{})
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("timed out waiting for %s: %w", target.String(), ctx.Err())
+			}
+			return fmt.Errorf("failed to get %s: %w", target.String(), err)
+		}
+		if target.UID != "" && job.UID != target.UID {
+			return fmt.Errorf("%s was replaced by another Job while waiting for it", target.String())
+		}
+		if logsDone == nil {
+			logsDone = make(chan struct{})
+			go func() {
+				defer close(logsDone)
+				if err := followJobLogs(logsCtx, client, job, os.Stdout); err != nil {
+					klog.Warningf("failed to stream logs for %s: %v", target.String(), err)
+				}
+			}()
+		}
+
+		done, jobErr := jobResult(job)
+		if done {
+			// Give the log stream a chance to flush the final lines.
+			select {
+			case <-logsDone:
+			case <-time.After(5 * time.Second):
+			}
+			if jobErr != nil {
+				return fmt.Errorf("%s: %w", target.String(), jobErr)
+			}
+			klog.Infof("%s completed", target.String())
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s: %w", target.String(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// stampSpecHash sets the SpecHashAnnotation of obj, if it is a Job, to a hash of its spec.
+func stampSpecHash(obj *unstructured.Unstructured) error {
+	if obj.GroupVersionKind().GroupKind() != jobKind {
+		return nil
+	}
+	// Maps are marshaled with sorted keys, so the hash only changes with the spec.
+	spec, err := json.Marshal(obj.Object["spec"])
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(spec)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[SpecHashAnnotation] = hex.EncodeToString(sum[:])
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// mustReplace returns whether obj is a Job that has to be deleted and created again instead of being applied:
+// a Job that has finished does not run again when it is applied, so it is replaced if its spec hash (see
+// stampSpecHash) differs from the live one, and the pod template of a Job cannot be changed. Changes to the
+// template are found with a server-side dry run of the apply, which the API server rejects; this also covers
+// finished Jobs applied before ap recorded spec hashes.
+func (r *resources) mustReplace(ctx context.Context, obj *unstructured.Unstructured, force bool) (bool, error) {
+	if obj.GroupVersionKind().GroupKind() != jobKind {
+		return false, nil
+	}
+	resource, err := r.resourceOf(obj)
+	if err != nil {
+		return false, err
+	}
+	live, err := get(ctx, resource, obj.GetName())
+	if err != nil || live == nil {
+		return false, err
+	}
+	var job batchv1.Job
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(live.Object, &job); err != nil {
+		return false, err
+	}
+	if done, _ := jobResult(&job); done {
+		if hash := live.GetAnnotations()[SpecHashAnnotation]; hash != "" {
+			return hash != obj.GetAnnotations()[SpecHashAnnotation], nil
+		}
+	}
+	_, _, err = r.applyObject(ctx, obj.DeepCopy(), force, true)
+	if apierrors.IsInvalid(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// deleteAndWait deletes obj, if it exists, and waits for it to be gone. Its dependents (e.g. the pods of a Job)
+// are deleted first, so that they do not outlive it.
+func (r *resources) deleteAndWait(ctx context.Context, obj *unstructured.Unstructured) error {
+	resource, err := r.resourceOf(obj)
+	if err != nil {
+		return err
+	}
+	live, err := get(ctx, resource, obj.GetName())
+	if err != nil || live == nil {
+		return err
+	}
+	klog.Infof("Deleting %s to replace it", objectName(obj))
+	propagation := metav1.DeletePropagationForeground
+	err = kubeclient.Retry(ctx, func() error {
+		return resource.Delete(ctx, obj.GetName(), metav1.DeleteOptions{
+			Preconditions:     metav1.NewUIDPreconditions(string(live.GetUID())),
+			PropagationPolicy: &propagation,
+		})
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return wait.PollUntilContextTimeout(ctx, time.Second, jobDeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		current, err := get(ctx, resource, obj.GetName())
+		return current == nil || current.GetUID() != live.GetUID(), err
+	})
+}
@@ -0,0 +1,538 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestFindWaitTargets(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		waitForRollouts bool
+		want            []waitTarget
+		wantErr         bool
+	}{
+		{
+			name: "job is waited by default",
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: db
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+`,
+			want: []waitTarget{
+				{Kind: "Job", Name: "migrate", Namespace: "db", Timeout: defaultJobTimeout},
+			},
+		},
+		{
+			name: "opt out",
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    ap.gke.io/wait: none
+`,
+		},
+		{
+			name: "explicit with timeout",
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    ap.gke.io/wait: complete
+    ap.gke.io/wait-timeout: 30m
+`,
+			want: []waitTarget{
+				{Kind: "Job", Name: "migrate", Timeout: 30 * time.Minute},
+			},
+		},
+		{
+			name: "complete on unsupported kind",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  annotations:
+    ap.gke.io/wait: complete
+`,
+			wantErr: true,
+		},
+		{
+			name: "rollouts are waited when enabled",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  annotations:
+    ap.gke.io/wait: none
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+`,
+			waitForRollouts: true,
+			want: []waitTarget{
+				{Kind: "Deployment", Name: "server", Timeout: defaultRolloutTimeout},
+			},
+		},
+		{
+			name: "ready annotation",
+			input: `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: kube-system
+  annotations:
+    ap.gke.io/wait: ready
+    ap.gke.io/wait-timeout: 2m
+`,
+			want: []waitTarget{
+				{Kind: "DaemonSet", Name: "agent", Namespace: "kube-system", Timeout: 2 * time.Minute},
+			},
+		},
+		{
+			name: "ready on unsupported kind",
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    ap.gke.io/wait: ready
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid timeout",
+			input: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    ap.gke.io/wait-timeout: soon
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findWaitTargets(tt.input, tt.waitForRollouts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findWaitTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findWaitTargets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		wantDone bool
+		wantErr  bool
+	}{
+		{
+			name:   "running",
+			status: `{"status":{"active":1}}`,
+		},
+		{
+			name:     "complete",
+			status:   `{"status":{"conditions":[{"type":"Complete","status":"True"}]}}`,
+			wantDone: true,
+		},
+		{
+			name:     "failed",
+			status:   `{"status":{"conditions":[{"type":"Failed","status":"True","reason":"BackoffLimitExceeded"}]}}`,
+			wantDone: true,
+			wantErr:  true,
+		},
+		{
+			name:   "condition not true",
+			status: `{"status":{"conditions":[{"type":"Failed","status":"False"}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var job batchv1.Job
+			if err := json.Unmarshal([]byte(tt.status), &job); err != nil {
+				t.Fatal(err)
+			}
+			done, err := jobResult(&job)
+			if done != tt.wantDone {
+				t.Errorf("jobResult() done = %v, want %v", done, tt.wantDone)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("jobResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForJob(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		status  batchv1.JobConditionType
+		uid     types.UID
+		wantErr bool
+	}{
+		{name: "complete", status: batchv1.JobComplete},
+		{name: "failed", status: batchv1.JobFailed, wantErr: true},
+		{name: "applied job", status: batchv1.JobComplete, uid: "applied"},
+		{name: "replaced job", status: batchv1.JobComplete, uid: "earlier", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := map[string]string{"batch.kubernetes.io/job-name": "migrate"}
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "prod", UID: "applied"},
+				Spec:       batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+					{Type: tc.status, Status: corev1.ConditionTrue, Reason: "Done"},
+				}},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "migrate-abcde", Namespace: "prod", Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate"}}},
+				Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+			}
+			ctx := kubeclient.NewContext(t.Context(), &kubeclient.Client{Interface: fake.NewClientset(job, pod)})
+
+			err := waitForJob(ctx, Target{Namespace: "prod"}, waitTarget{Kind: "Job", Name: "migrate", Timeout: time.Minute, UID: tc.uid})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("waitForJob() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// jobsManifest is a migration Job whose image changed from the live one, which is still running, a finished Job
+// whose image changed, and Jobs with the same spec as the live ones: a finished one, a finished one applied
+// without a spec hash, and a running one.
+const jobsManifest = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: repo/migrate:v2
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: rerun
+spec:
+  template:
+    spec:
+      containers:
+      - name: rerun
+        image: repo/rerun:v2
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: finished
+spec:
+  template:
+    spec:
+      containers:
+      - name: finished
+        image: repo/finished:v1
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: unhashed
+spec:
+  template:
+    spec:
+      containers:
+      - name: unhashed
+        image: repo/unhashed:v1
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: running
+spec:
+  template:
+    spec:
+      containers:
+      - name: running
+        image: repo/running:v1
+`
+
+// liveJobs are the Jobs of jobsManifest as they were last applied. $HASH(name) stands for the spec hash
+// of the Job name in jobsManifest.
+const liveJobs = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: prod
+  uid: migrate-1
+  annotations:
+    ap.gke.io/spec-hash: migrate-v1
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: repo/migrate:v1
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: rerun
+  namespace: prod
+  uid: rerun-1
+  annotations:
+    ap.gke.io/spec-hash: rerun-v1
+spec:
+  template:
+    spec:
+      containers:
+      - name: rerun
+        image: repo/rerun:v1
+status:
+  conditions:
+  - type: Complete
+    status: "True"
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: finished
+  namespace: prod
+  uid: finished-1
+  annotations:
+    ap.gke.io/spec-hash: $HASH(finished)
+spec:
+  template:
+    spec:
+      containers:
+      - name: finished
+        image: repo/finished:v1
+status:
+  conditions:
+  - type: Complete
+    status: "True"
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: unhashed
+  namespace: prod
+  uid: unhashed-1
+spec:
+  template:
+    spec:
+      containers:
+      - name: unhashed
+        image: repo/unhashed:v1
+status:
+  conditions:
+  - type: Failed
+    status: "True"
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: running
+  namespace: prod
+  uid: running-1
+  annotations:
+    ap.gke.io/spec-hash: $HASH(running)
+spec:
+  template:
+    spec:
+      containers:
+      - name: running
+        image: repo/running:v1
+`
+
+// liveJobsManifest returns liveJobs with the spec hashes of the Jobs in jobsManifest filled in.
+func liveJobsManifest(t *testing.T) string {
+	t.Helper()
+	objs, err := decodeObjects(jobsManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replacements []string
+	for _, obj := range objs {
+		if err := stampSpecHash(obj); err != nil {
+			t.Fatal(err)
+		}
+		replacements = append(replacements, "$HASH("+obj.GetName()+")", obj.GetAnnotations()[SpecHashAnnotation])
+	}
+	return strings.NewReplacer(replacements...).Replace(liveJobs)
+}
+
+func TestApplyReplacesJobs(t *testing.T) {
+	jobs := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	for _, tc := range []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "apply"},
+		{name: "dry run", dryRun: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, dynamicClient := newFakeClient(t, liveJobsManifest(t))
+			// The API server rejects changes to the pod template of a Job.
+			dynamicClient.PrependReactor("patch", "jobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				patch := action.(clienttesting.PatchActionImpl)
+				obj := &unstructured.Unstructured{}
+				if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+					return true, nil, err
+				}
+				live, err := dynamicClient.Tracker().Get(jobs, patch.GetNamespace(), patch.GetName())
+				if err != nil {
+					return false, nil, nil
+				}
+				liveTemplate, _, _ := unstructured.NestedMap(live.(*unstructured.Unstructured).Object, "spec", "template")
+				template, _, _ := unstructured.NestedMap(obj.Object, "spec", "template")
+				if !reflect.DeepEqual(liveTemplate, template) {
+					return true, nil, apierrors.NewInvalid(jobKind, patch.GetName(), field.ErrorList{
+						field.Invalid(field.NewPath("spec", "template"), template, "field is immutable"),
+					})
+				}
+				return false, nil, nil
+			})
+			ctx := kubeclient.NewContext(t.Context(), client)
+			var report *dryrun.Report
+			if tc.dryRun {
+				report = &dryrun.Report{}
+			}
+
+			applied, err := Target{Namespace: "prod"}.apply(ctx, jobsManifest, applyOptions{replaceJobs: true, report: report})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(applied) != 5 {
+				t.Errorf("apply() returned %d objects, want 5", len(applied))
+			}
+
+			var deleted []string
+			for _, action := range dynamicClient.Actions() {
+				if del, ok := action.(clienttesting.DeleteActionImpl); ok {
+					deleted = append(deleted, del.GetName())
+					if *del.DeleteOptions.PropagationPolicy != metav1.DeletePropagationForeground {
+						t.Errorf("delete of %s with %+v, want foreground propagation", del.GetName(), del.DeleteOptions)
+					}
+				}
+			}
+			migrate, err := dynamicClient.Resource(jobs).Namespace("prod").Get(ctx, "migrate", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			containers, _, _ := unstructured.NestedSlice(migrate.Object, "spec", "template", "spec", "containers")
+			image := containers[0].(map[string]any)["image"]
+
+			if tc.dryRun {
+				if len(deleted) != 0 || image != "repo/migrate:v1" {
+					t.Errorf("dry run deleted %v and left image %v, want no change", deleted, image)
+				}
+				// The Job applied without a spec hash is not run again, only annotated with it.
+				want := []string{"job.batch/migrate replaced", "job.batch/rerun replaced", "job.batch/unhashed configured"}
+				if got := report.Changes(); !reflect.DeepEqual(got, want) {
+					t.Errorf("dry run changes = %q, want %q", got, want)
+				}
+				return
+			}
+			if want := []string{"migrate", "rerun"}; !slices.Equal(deleted, want) {
+				t.Errorf("deleted %v, want %v", deleted, want)
+			}
+			if image != "repo/migrate:v2" {
+				t.Errorf("image of the redeployed Job = %v, want repo/migrate:v2", image)
+			}
+		})
+	}
+}
+
+func TestAppliedUID(t *testing.T) {
+	applied, err := decodeObjects(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: prod
+  uid: migrate-2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: migrate
+  namespace: prod
+  uid: deployment-1
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&waitTarget{Kind: "Job", Name: "migrate"}).appliedUID(applied); got != "migrate-2" {
+		t.Errorf("appliedUID() of the Job = %q, want migrate-2", got)
+	}
+	if got := (&waitTarget{Kind: "Deployment", Name: "migrate"}).appliedUID(applied); got != "" {
+		t.Errorf("appliedUID() of a Deployment = %q, want none", got)
+	}
+	if got := (&waitTarget{Kind: "Job", Name: "migrate", Namespace: "dev"}).appliedUID(applied); got != "" {
+		t.Errorf("appliedUID() of a Job in another namespace = %q, want none", got)
+	}
+}
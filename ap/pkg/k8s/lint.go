@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/lint"
+	"k8s.io/klog/v2"
+)
+
+// Lint runs kubelint over the manifests under the k8s directories of root, with the rules enabled in
+// .ap/kubelint.yaml. Kustomizations and Helm charts are skipped, as their files are only valid manifests once rendered.
+func Lint(root string) ([]findings.Finding, error) {
+	cfg, err := config.LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := manifestFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	// The findings are cached by the content of the manifests and CRDs, the config and the version of the rules (built into ap).
+	cm, key := lintCacheKey(root, files, cfg)
+	if cm != nil {
+		if result, ok := cm.Done("kubelint", key); ok {
+			klog.Infof("Manifests in %s are unchanged, replaying the findings of kubelint", root)
+			return result.Findings, nil
+		}
+	}
+
+	found, err := lint.Paths(files, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("kubelint failed in %s: %w", root, err)
+	}
+	if cm != nil {
+		cm.MarkDone("kubelint", key, &cache.Result{Findings: found})
+		if err := cm.Save(); err != nil {
+			klog.Warningf("Failed to save cache: %v", err)
+		}
+	}
+	return found, nil
+}
+
+// Fix applies the fixes of the kubelint rules enabled in .ap/kubelint.yaml to the manifests under the k8s directories
+// of root, as Lint finds them, and returns the files it changed.
+func Fix(root string) ([]string, error) {
+	cfg, err := config.LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := manifestFiles(root)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	changed, err := lint.Fix(files, cfg)
+	if err != nil {
+		return changed, fmt.Errorf("kubelint failed to fix the manifests in %s: %w", root, err)
+	}
+	return changed, nil
+}
+
+// manifestFiles returns the manifest files under the k8s directories of root, skipping Kustomizations and Helm charts.
+func manifestFiles(root string) ([]string, error) {
+	manifests, err := findManifests(root)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, manifest := range manifests {
+		info, err := os.Stat(manifest)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, manifest)
+		}
+	}
+	return files, nil
+}
+
+// lintCacheKey returns the cache of root and the key of the kubelint findings of files with cfg, or a nil cache
+// if it cannot be used.
+func lintCacheKey(root string, files []string, cfg *config.Config) (*cache.Manager, string) {
+	cm, err := cache.NewManager(root)
+	if err != nil {
+		klog.V(2).Infof("Failed to initialize cache: %v", err)
+		return nil, ""
+	}
+	version, err := cache.ExecutableHash()
+	if err != nil {
+		klog.V(2).Infof("Failed to hash the ap executable: %v", err)
+		return nil, ""
+	}
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, ""
+	}
+	crdFiles, err := cfg.CRDFiles()
+	if err != nil {
+		return nil, ""
+	}
+	parts := []string{version, string(configJSON)}
+	for _, file := range append(slices.Clone(files), crdFiles...) {
+		meta, err := cm.GetOrUpdateMetadata(file)
+		if err != nil {
+			return nil, ""
+		}
+		parts = append(parts, file, meta.Hash)
+	}
+	return cm, cache.Key(parts...)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestLint(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	statefulSet := "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\n"
+	files := map[string]string{
+		"k8s/db.yaml": statefulSet,
+		// Not a manifest directory.
+		"docs/db.yaml": statefulSet,
+		// Kustomizations are only checked once rendered.
+		"k8s/overlay/kustomization.yaml": "resources:\n- db.yaml\n",
+		"k8s/overlay/db.yaml":            statefulSet,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Lint(root)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Path != filepath.Join(root, "k8s/db.yaml") || got[0].Line != 2 {
+		t.Errorf("Lint() = %+v, want one finding in k8s/db.yaml:2", got)
+	}
+
+	// Changing the rules in .ap/kubelint.yaml invalidates the cached findings.
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "rules:\n  statefulset-updatestrategy: ignore\n  required-labels: warn\n"
+	if err := os.WriteFile(filepath.Join(root, ".ap", "kubelint.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Lint(root)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Rule != "required-labels" || got[0].Severity != findings.SeverityWarning {
+		t.Errorf("Lint() = %+v, want one required-labels warning", got)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// listPods returns the pods in namespace matching selector, oldest first.
+func listPods(ctx context.Context, client *kubeclient.Client, namespace string, selector *metav1.LabelSelector) ([]corev1.Pod, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	var pods *corev1.PodList
+	err = kubeclient.Retry(ctx, func() error {
+		var err error
+		pods, err = client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: s.String()})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(pods.Items, func(a, b corev1.Pod) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+	return pods.Items, nil
+}
+
+// podLogs writes the logs of every container of pods to out, with each line prefixed by [pod/<pod>/<container>],
+// as kubectl logs --all-containers --prefix does. If opts.Follow is set, the logs of all the containers are
+// streamed at the same time, until they end or ctx is done.
+func podLogs(ctx context.Context, client *kubeclient.Client, pods []corev1.Pod, opts corev1.PodLogOptions, out io.Writer) error {
+	w := &prefixWriter{out: out}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, pod := range pods {
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			prefix := fmt.Sprintf("[pod/%s/%s] ", pod.Name, container.Name)
+			opts := opts
+			opts.Container = container.Name
+			logs := func() {
+				if err := containerLogs(ctx, client, &pod, &opts, prefix, w); err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to get the logs of %s: %w", prefix, err))
+				}
+			}
+			if opts.Follow {
+				wg.Go(logs)
+			} else {
+				logs()
+			}
+		}
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// containerLogs writes the logs of a container of pod, as selected by opts, to w.
+func containerLogs(ctx context.Context, client *kubeclient.Client, pod *corev1.Pod, opts *corev1.PodLogOptions, prefix string, w *prefixWriter) error {
+	stream, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		w.writeLine(prefix, scanner.Text())
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// prefixWriter writes the lines of several log streams to out, without interleaving them.
+type prefixWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *prefixWriter) writeLine(prefix, line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintln(w.out, prefix+line)
+}
+
+// followJobLogs streams the logs of the first pod of job to start running, until the pod finishes or ctx is done.
+func followJobLogs(ctx context.Context, client *kubeclient.Client, job *batchv1.Job, out io.Writer) error {
+	if job.Spec.Selector == nil {
+		return nil
+	}
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		pods, err := listPods(ctx, client, job.Namespace, job.Spec.Selector)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if i := slices.IndexFunc(pods, func(p corev1.Pod) bool { return p.Status.Phase != corev1.PodPending }); i != -1 {
+			return podLogs(ctx, client, pods[i:i+1], corev1.PodLogOptions{Follow: true}, out)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
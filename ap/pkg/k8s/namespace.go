@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// namespaceManagedBy is the app.kubernetes.io/managed-by label of namespaces created by deploy.
+const namespaceManagedBy = "ap"
+
+// ensureNamespace creates the namespace of kube if it is set and does not exist, labeled as managed by ap
+// and with the provenance annotations of the deploy that created it. Existing namespaces are left untouched.
+// If report is set, the namespace is created as a server-side dry run instead, recorded in report.
+func ensureNamespace(ctx context.Context, kube Target, annotations map[string]string, report *dryrun.Report) error {
+	if kube.Namespace == "" {
+		return nil
+	}
+	client, err := kube.client(ctx)
+	if err != nil {
+		return err
+	}
+	err = kubeclient.Retry(ctx, func() error {
+		_, err := client.CoreV1().Namespaces().Get(ctx, kube.Namespace, metav1.GetOptions{})
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to check for namespace %s: %w", kube.Namespace, err)
+	}
+
+	klog.Infof("Creating namespace %s", kube.Namespace)
+	opts := metav1.CreateOptions{FieldManager: kubeclient.FieldManager}
+	if report != nil {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	err = kubeclient.Retry(ctx, func() error {
+		_, err := client.CoreV1().Namespaces().Create(ctx, namespaceObject(kube.Namespace, annotations), opts)
+		return err
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", kube.Namespace, err)
+	}
+	if report != nil {
+		report.Addf("namespace/%s created", kube.Namespace)
+	}
+	return nil
+}
+
+// namespaceObject returns the namespace created by deploy.
+func namespaceObject(name string, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": namespaceManagedBy,
+			},
+			Annotations: annotations,
+		},
+	}
+}
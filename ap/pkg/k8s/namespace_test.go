@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestEnsureNamespace(t *testing.T) {
+	kube := Target{Context: "dev", Namespace: "demo"}
+
+	tests := []struct {
+		name        string
+		existing    []runtime.Object
+		dryRun      bool
+		wantCreate  bool
+		wantChanges []string
+	}{
+		{
+			name:     "exists",
+			existing: []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo"}}},
+		},
+		{
+			name:       "missing",
+			wantCreate: true,
+		},
+		{
+			name:        "dry run",
+			dryRun:      true,
+			wantCreate:  true,
+			wantChanges: []string{"namespace/demo created"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clientset := fake.NewClientset(tc.existing...)
+			ctx := kubeclient.NewContext(t.Context(), &kubeclient.Client{Interface: clientset})
+			var report *dryrun.Report
+			if tc.dryRun {
+				report = &dryrun.Report{}
+			}
+
+			if err := ensureNamespace(ctx, kube, map[string]string{"ap.gke-labs.dev/commit": "abc"}, report); err != nil {
+				t.Fatal(err)
+			}
+			var created []k8stesting.CreateActionImpl
+			for _, action := range clientset.Actions() {
+				if create, ok := action.(k8stesting.CreateActionImpl); ok {
+					created = append(created, create)
+				}
+			}
+			if got := len(created) > 0; got != tc.wantCreate {
+				t.Fatalf("created namespace = %v, want %v", got, tc.wantCreate)
+			}
+			if tc.wantCreate {
+				ns := created[0].GetObject().(*corev1.Namespace)
+				if ns.Labels["app.kubernetes.io/managed-by"] != "ap" || ns.Annotations["ap.gke-labs.dev/commit"] != "abc" {
+					t.Errorf("created namespace %+v, want the managed-by label and the annotations", ns.ObjectMeta)
+				}
+				if got, want := created[0].CreateOptions.DryRun, report != nil; (len(got) > 0) != want {
+					t.Errorf("create dry run = %q, want dry run %v", got, want)
+				}
+			}
+			if report != nil && !reflect.DeepEqual(report.Changes(), tc.wantChanges) {
+				t.Errorf("dry run changes = %q, want %q", report.Changes(), tc.wantChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/repo"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// RepoAnnotation records the git remote (origin) of the repository a resource was deployed from.
+	RepoAnnotation = "ap.gke.io/repo"
+	// RootAnnotation records the ap root a resource was deployed from, relative to the repository root.
+	RootAnnotation = "ap.gke.io/root"
+	// GitSHAAnnotation records the commit a resource was deployed from, with a -dirty suffix if there were local changes.
+	GitSHAAnnotation = "ap.gke.io/git-sha"
+	// DeployedByAnnotation records who deployed a resource.
+	DeployedByAnnotation = "ap.gke.io/deployed-by"
+
+	// DeployerEnv overrides the identity recorded in DeployedByAnnotation.
+	DeployerEnv = "AP_DEPLOYER"
+)
+
+// provenance returns the annotations recording where the resources of the ap root at root come from.
+// Values that cannot be determined (e.g. outside of a git checkout) are left out.
+func provenance(ctx context.Context, root string) map[string]string {
+	annotations := make(map[string]string)
+	if deployedBy := deployer(ctx, root); deployedBy != "" {
+		annotations[DeployedByAnnotation] = deployedBy
+	}
+
+	repoRoot, err := repo.FindRoot(root)
+	if err != nil {
+		klog.V(2).Infof("Not recording the source of deployed resources: %v", err)
+		return annotations
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return annotations
+	}
+	if rel, err := filepath.Rel(repoRoot, absRoot); err == nil {
+		annotations[RootAnnotation] = filepath.ToSlash(rel)
+	}
+	if remote, err := git(ctx, root, "remote", "get-url", "origin"); err == nil && remote != "" {
+		annotations[RepoAnnotation] = redactCredentials(remote)
+	}
+	if sha, err := git(ctx, root, "rev-parse", "HEAD"); err == nil {
+		if status, err := git(ctx, root, "status", "--porcelain"); err == nil && status != "" {
+			sha += "-dirty"
+		}
+		annotations[GitSHAAnnotation] = sha
+	} else {
+		klog.V(2).Infof("Not recording the commit of deployed resources: %v", err)
+	}
+	return annotations
+}
+
+// deployer returns the identity of whoever is deploying: $AP_DEPLOYER, the GitHub Actions actor,
+// the git user email, or the OS user, whichever is found first.
+func deployer(ctx context.Context, root string) string {
+	if v := os.Getenv(DeployerEnv); v != "" {
+		return v
+	}
+	if actor := os.Getenv("GITHUB_ACTOR"); actor != "" && os.Getenv("GITHUB_ACTIONS") == "true" {
+		return "github-actions:" + actor
+	}
+	if email, err := git(ctx, root, "config", "user.email"); err == nil && email != "" {
+		return email
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// redactCredentials removes any user info (e.g. an access token) from a remote URL.
+func redactCredentials(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme == "" || u.User == nil {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
+
+// git runs git in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runner.Run(ctx, cmd); err != nil {
+		return "", fmt.Errorf("git %s failed: %w\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
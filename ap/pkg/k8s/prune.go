@@ -0,0 +1,326 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ManagedByLabel is set on every resource applied by deploy, with the inventory name as its value.
+	// Prune only deletes resources that carry the label, so resources created by other tools are never deleted.
+	ManagedByLabel = "ap.gke-labs.dev/managed-by"
+
+	// inventoryKey is the ConfigMap data key holding the applied resources, one per line.
+	inventoryKey = "resources"
+)
+
+// resourceRef identifies an applied resource.
+type resourceRef struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the inventory form of the reference, <kind>[.<group>]/<namespace>/<name>.
+func (r resourceRef) String() string {
+	return r.qualifiedKind() + "/" + r.Namespace + "/" + r.Name
+}
+
+// qualifiedKind returns the lower case kind qualified by its group, e.g. "deployment.apps", as kubectl names resource types.
+func (r resourceRef) qualifiedKind() string {
+	kind := strings.ToLower(r.Kind)
+	if r.Group != "" {
+		kind += "." + r.Group
+	}
+	return kind
+}
+
+func parseResourceRef(s string) (resourceRef, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return resourceRef{}, fmt.Errorf("invalid resource reference %q", s)
+	}
+	kind, group, _ := strings.Cut(parts[0], ".")
+	return resourceRef{Group: group, Kind: kind, Namespace: parts[1], Name: parts[2]}, nil
+}
+
+// inventoryName returns the name of the ConfigMap recording the resources deployed from root.
+func inventoryName(root string, cfg *DeployConfig) string {
+	if cfg.Inventory != "" {
+		return cfg.Inventory
+	}
+	return "ap-inventory-" + strings.ToLower(filepath.Base(root))
+}
+
+// stampManagedBy sets ManagedByLabel and annotations on every resource in content, returning the updated
+// manifest and the resources it contains. Resources without a namespace get defaultNamespace.
+func stampManagedBy(content string, inventory string, annotations map[string]string, defaultNamespace string) (string, []resourceRef, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+
+	var refs []resourceRef
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		var obj resourceHeader
+		if err := doc.Decode(&obj); err != nil {
+			return "", nil, fmt.Errorf("failed to decode resource: %w", err)
+		}
+		if obj.Kind == "" || obj.Metadata.Name == "" {
+			return "", nil, fmt.Errorf("resource without kind or metadata.name cannot be tracked (generateName is not supported)")
+		}
+
+		// Core resources have no group (apiVersion "v1").
+		group := ""
+		if i := strings.Index(obj.APIVersion, "/"); i != -1 {
+			group = obj.APIVersion[:i]
+		}
+		namespace := obj.Metadata.Namespace
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		refs = append(refs, resourceRef{Group: group, Kind: obj.Kind, Namespace: namespace, Name: obj.Metadata.Name})
+
+		metadata := mappingChild(doc.Content[0], "metadata")
+		setMappingValue(mappingChild(metadata, "labels"), ManagedByLabel, inventory)
+		if len(annotations) > 0 {
+			annotationsNode := mappingChild(metadata, "annotations")
+			for _, key := range slices.Sorted(maps.Keys(annotations)) {
+				setMappingValue(annotationsNode, key, annotations[key])
+			}
+		}
+
+		if err := encoder.Encode(&doc); err != nil {
+			return "", nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return out.String(), refs, nil
+}
+
+// mappingChild returns the mapping stored under key in node, creating it if needed.
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			if value.Kind != yaml.MappingNode {
+				// e.g. "labels:" with no value.
+				*value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			return value
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+	return child
+}
+
+func setMappingValue(node *yaml.Node, key string, value string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+// inventoryNamespace is where the inventory ConfigMap is stored.
+func inventoryNamespace(kube Target) string {
+	if kube.Namespace != "" {
+		return kube.Namespace
+	}
+	return "default"
+}
+
+// readInventory returns the resources recorded by the last deploy, or nil if there is no inventory yet.
+func readInventory(ctx context.Context, kube Target, name string) ([]resourceRef, error) {
+	data, err := readConfigMap(ctx, kube, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory %s: %w", name, err)
+	}
+	var refs []resourceRef
+	for _, line := range strings.Split(data[inventoryKey], "\n") {
+		if line == "" {
+			continue
+		}
+		ref, err := parseResourceRef(line)
+		if err != nil {
+			return nil, fmt.Errorf("error in inventory %s: %w", name, err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// inventoryData returns the data of the ConfigMap recording refs.
+func inventoryData(refs []resourceRef) map[string]string {
+	var lines []string
+	for _, ref := range refs {
+		lines = append(lines, ref.String())
+	}
+	sort.Strings(lines)
+	return map[string]string{inventoryKey: strings.Join(lines, "\n")}
+}
+
+// writeInventory records refs as the resources deployed under the inventory name.
+func writeInventory(ctx context.Context, kube Target, name string, refs []resourceRef) error {
+	if err := applyConfigMap(ctx, kube, name, inventoryData(refs)); err != nil {
+		return fmt.Errorf("failed to write inventory %s: %w", name, err)
+	}
+	return nil
+}
+
+// staleResources returns the resources in previous that are not in current.
+func staleResources(previous, current []resourceRef) []resourceRef {
+	keep := make(map[string]bool)
+	for _, ref := range current {
+		keep[strings.ToLower(ref.String())] = true
+	}
+	var stale []resourceRef
+	for _, ref := range previous {
+		if !keep[strings.ToLower(ref.String())] {
+			stale = append(stale, ref)
+		}
+	}
+	return stale
+}
+
+// deleteManaged deletes the resources that are still labeled as managed by the inventory.
+// If report is set, the deletion is a server-side dry run, recorded in report.
+func deleteManaged(ctx context.Context, kube Target, inventory string, refs []resourceRef, report *dryrun.Report) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	r, err := kube.resources(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ref := range deletionOrder(refs) {
+		klog.Infof("Deleting %s", ref.String())
+		deleted, err := r.deleteResource(ctx, ref, inventory, report != nil)
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", ref.String(), err)
+		}
+		if deleted != "" && report != nil {
+			report.Addf("%s deleted", deleted)
+		}
+	}
+	return nil
+}
+
+// deletionOrder returns refs with Namespaces and CRDs last, as deleting them also deletes the resources within them.
+func deletionOrder(refs []resourceRef) []resourceRef {
+	refs = slices.Clone(refs)
+	sort.SliceStable(refs, func(i, j int) bool {
+		return !isContainerKind(refs[i].Kind) && isContainerKind(refs[j].Kind)
+	})
+	return refs
+}
+
+// isContainerKind returns true for kinds whose deletion also deletes other resources.
+func isContainerKind(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "namespace", "customresourcedefinition":
+		return true
+	}
+	return false
+}
+
+// deleteInventory deletes the inventory ConfigMap, and the deploy history recorded with it.
+func deleteInventory(ctx context.Context, kube Target, name string) error {
+	client, err := kube.client(ctx)
+	if err != nil {
+		return err
+	}
+	for _, cm := range []string{name, historyConfigMapName(name)} {
+		err := kubeclient.Retry(ctx, func() error {
+			return client.CoreV1().ConfigMaps(inventoryNamespace(kube)).Delete(ctx, cm, metav1.DeleteOptions{})
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete inventory %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// readConfigMap returns the data of the ConfigMap name in the inventory namespace, or nil if it does not exist.
+func readConfigMap(ctx context.Context, kube Target, name string) (map[string]string, error) {
+	client, err := kube.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cm *corev1.ConfigMap
+	err = kubeclient.Retry(ctx, func() error {
+		cm, err = client.CoreV1().ConfigMaps(inventoryNamespace(kube)).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cm.Data, nil
+}
+
+// applyConfigMap sets the data of the ConfigMap name in the inventory namespace, creating it if needed.
+// It is applied server-side, so that the data is not duplicated in a last-applied-configuration annotation.
+func applyConfigMap(ctx context.Context, kube Target, name string, data map[string]string) error {
+	client, err := kube.client(ctx)
+	if err != nil {
+		return err
+	}
+	cm := corev1ac.ConfigMap(name, inventoryNamespace(kube)).WithData(data)
+	return kubeclient.Retry(ctx, func() error {
+		_, err := client.CoreV1().ConfigMaps(inventoryNamespace(kube)).Apply(ctx, cm, metav1.ApplyOptions{FieldManager: kubeclient.FieldManager, Force: true})
+		return err
+	})
+}
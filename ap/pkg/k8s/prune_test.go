@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStampManagedBy(t *testing.T) {
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  labels:
+    app: server
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+  namespace: web
+---
+`
+	got, refs, err := stampManagedBy(input, "ap-inventory-demo", nil, "prod")
+	if err != nil {
+		t.Fatalf("stampManagedBy failed: %v", err)
+	}
+
+	want := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: server
+  labels:
+    app: server
+    ap.gke-labs.dev/managed-by: ap-inventory-demo
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: server
+  namespace: web
+  labels:
+    ap.gke-labs.dev/managed-by: ap-inventory-demo
+`
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+
+	wantRefs := []resourceRef{
+		{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "server"},
+		{Kind: "Service", Namespace: "web", Name: "server"},
+	}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("refs = %+v, want %+v", refs, wantRefs)
+	}
+
+	if _, _, err := stampManagedBy("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  generateName: cm-\n", "inv", nil, ""); err == nil {
+		t.Errorf("expected an error for a resource without a name")
+	}
+}
+
+func TestStampManagedByAnnotations(t *testing.T) {
+	input := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    owner: team
+`
+	annotations := map[string]string{GitSHAAnnotation: "abc123", RootAnnotation: "services/api"}
+	got, _, err := stampManagedBy(input, "inv", annotations, "")
+	if err != nil {
+		t.Fatalf("stampManagedBy failed: %v", err)
+	}
+
+	want := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    owner: team
+    ap.gke.io/git-sha: abc123
+    ap.gke.io/root: services/api
+  labels:
+    ap.gke-labs.dev/managed-by: inv
+`
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestResourceRefRoundTrip(t *testing.T) {
+	for _, ref := range []resourceRef{
+		{Group: "networking.k8s.io", Kind: "ingress", Namespace: "web", Name: "frontend"},
+		{Kind: "clusterrole", Name: "reader"},
+	} {
+		parsed, err := parseResourceRef(ref.String())
+		if err != nil {
+			t.Fatalf("parseResourceRef(%q) failed: %v", ref.String(), err)
+		}
+		if parsed != ref {
+			t.Errorf("parseResourceRef(%q) = %+v, want %+v", ref.String(), parsed, ref)
+		}
+	}
+
+	if _, err := parseResourceRef("deployment.apps/server"); err == nil {
+		t.Errorf("expected an error for a reference without a namespace field")
+	}
+}
+
+func TestStaleResources(t *testing.T) {
+	previous := []resourceRef{
+		{Group: "apps", Kind: "deployment", Namespace: "prod", Name: "server"},
+		{Group: "apps", Kind: "deployment", Namespace: "prod", Name: "old-worker"},
+		{Kind: "service", Namespace: "prod", Name: "server"},
+	}
+	current := []resourceRef{
+		{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "server"},
+		{Kind: "Service", Namespace: "prod", Name: "server"},
+	}
+
+	got := staleResources(previous, current)
+	want := []resourceRef{{Group: "apps", Kind: "deployment", Namespace: "prod", Name: "old-worker"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("staleResources() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInventoryData(t *testing.T) {
+	got := inventoryData([]resourceRef{
+		{Kind: "Service", Namespace: "prod", Name: "server"},
+		{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "server"},
+	})
+	want := map[string]string{inventoryKey: "deployment.apps/prod/server\nservice/prod/server"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inventoryData() = %q, want %q", got, want)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	kube := Target{Namespace: "prod"}
+	clientset := fake.NewClientset()
+	ctx := kubeclient.NewContext(t.Context(), &kubeclient.Client{Interface: clientset})
+
+	// The inventory does not exist yet.
+	refs, err := readInventory(ctx, kube, "ap-inventory-demo")
+	if err != nil || refs != nil {
+		t.Errorf("readInventory() of a missing inventory = %+v, %v, want none", refs, err)
+	}
+
+	want := []resourceRef{
+		{Group: "apps", Kind: "deployment", Namespace: "prod", Name: "server"},
+		{Kind: "service", Namespace: "prod", Name: "server"},
+	}
+	if err := writeInventory(ctx, kube, "ap-inventory-demo", want); err != nil {
+		t.Fatal(err)
+	}
+	refs, err = readInventory(ctx, kube, "ap-inventory-demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("readInventory() = %+v, want %+v", refs, want)
+	}
+
+	// Deleting the inventory ignores the missing history ConfigMap.
+	if err := deleteInventory(ctx, kube, "ap-inventory-demo"); err != nil {
+		t.Fatal(err)
+	}
+	refs, err = readInventory(ctx, kube, "ap-inventory-demo")
+	if err != nil || refs != nil {
+		t.Errorf("readInventory() of a deleted inventory = %+v, %v, want none", refs, err)
+	}
+}
+
+func TestDeletionOrder(t *testing.T) {
+	refs := []resourceRef{
+		{Kind: "namespace", Name: "app"},
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition", Name: "widgets.example.com"},
+		{Group: "apps", Kind: "deployment", Namespace: "app", Name: "server"},
+		{Kind: "service", Namespace: "app", Name: "server"},
+	}
+
+	var got []string
+	for _, ref := range deletionOrder(refs) {
+		got = append(got, ref.String())
+	}
+	want := []string{
+		"deployment.apps/app/server",
+		"service/app/server",
+		"namespace//app",
+		"customresourcedefinition.apiextensions.k8s.io//widgets.example.com",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deletionOrder() = %q, want %q", got, want)
+	}
+	if refs[0].Kind != "namespace" {
+		t.Errorf("deletionOrder modified its input")
+	}
+}
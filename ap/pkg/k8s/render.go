@@ -0,0 +1,155 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomizationFiles are the file names that mark a kustomize directory.
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+const chartFile = "Chart.yaml"
+
+const (
+	sourceManifest  = "manifest"
+	sourceKustomize = "kustomize"
+	sourceHelm      = "helm"
+)
+
+// sourceKind returns how the path returned by findManifests is rendered:
+// plain manifests are read directly, directories are built with kustomize or helm.
+func sourceKind(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return sourceManifest, nil
+	}
+	if _, err := os.Stat(filepath.Join(path, chartFile)); err == nil {
+		return sourceHelm, nil
+	}
+	for _, name := range kustomizationFiles {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return sourceKustomize, nil
+		}
+	}
+	return "", fmt.Errorf("%s is neither a kustomization nor a helm chart", path)
+}
+
+// requireRenderers checks that kubectl, which renders kustomizations, is installed if any of the manifests is one.
+// It returns false with a nil error if kubectl is missing and missing tools are ignored, so that the caller skips.
+func requireRenderers(ctx context.Context, manifests []string) (bool, error) {
+	for _, manifest := range manifests {
+		if kind, err := sourceKind(manifest); err == nil && kind == sourceKustomize {
+			return tools.Require(ctx, tools.Requirement{Tool: "kubectl", Task: "render kustomizations"})
+		}
+	}
+	return true, nil
+}
+
+// renderSource returns the YAML for the manifest, kustomization or chart at path.
+func renderSource(ctx context.Context, root string, path string, cfg *DeployConfig) (string, error) {
+	kind, err := sourceKind(path)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case sourceKustomize:
+		return runRenderer(ctx, root, "kubectl", "kustomize", path)
+	case sourceHelm:
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return "", err
+		}
+		chart := ChartConfig{Path: relPath}
+		if c := cfg.Chart(relPath); c != nil {
+			chart = *c
+		}
+		if chart.Namespace == "" {
+			chart.Namespace = cfg.Namespace
+		}
+
+		tmpDir, err := os.MkdirTemp("", "ap-helm-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		args, err := helmTemplateArgs(root, &chart, tmpDir)
+		if err != nil {
+			return "", err
+		}
+		return runRenderer(ctx, root, "helm", args...)
+	default:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+}
+
+// helmTemplateArgs builds the helm template command line for chart.
+// Inline values are written to a file in tmpDir.
+func helmTemplateArgs(root string, chart *ChartConfig, tmpDir string) ([]string, error) {
+	releaseName := chart.ReleaseName
+	if releaseName == "" {
+		releaseName = filepath.Base(chart.Path)
+	}
+
+	args := []string{"template", releaseName, filepath.Join(root, chart.Path)}
+	if chart.Namespace != "" {
+		args = append(args, "--namespace", chart.Namespace)
+	}
+	for _, valuesFile := range chart.ValuesFiles {
+		args = append(args, "-f", filepath.Join(root, valuesFile))
+	}
+	if len(chart.Values) > 0 {
+		data, err := yaml.Marshal(chart.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal values for chart %s: %w", chart.Path, err)
+		}
+		valuesFile := filepath.Join(tmpDir, "values.yaml")
+		if err := os.WriteFile(valuesFile, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write values for chart %s: %w", chart.Path, err)
+		}
+		args = append(args, "-f", valuesFile)
+	}
+	return args, nil
+}
+
+func runRenderer(ctx context.Context, root string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = root
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := runner.Run(ctx, cmd); err != nil {
+		return "", fmt.Errorf("%s %s failed: %w", name, args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+)
+
+func TestLoadDeployConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `
+charts:
+- path: k8s/chart
+  releaseName: server
+  namespace: prod
+  valuesFiles:
+  - config/values-prod.yaml
+  values:
+    replicas: 3
+`
+	if err := os.WriteFile(filepath.Join(root, ".ap", "deploy.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadDeployConfig(root)
+	if err != nil {
+		t.Fatalf("LoadDeployConfig failed: %v", err)
+	}
+
+	chart := cfg.Chart("k8s/chart/")
+	if chart == nil {
+		t.Fatalf("expected chart config for k8s/chart")
+	}
+
+	tmpDir := t.TempDir()
+	args, err := helmTemplateArgs("/repo", chart, tmpDir)
+	if err != nil {
+		t.Fatalf("helmTemplateArgs failed: %v", err)
+	}
+	valuesFile := filepath.Join(tmpDir, "values.yaml")
+	want := []string{
+		"template", "server", "/repo/k8s/chart",
+		"--namespace", "prod",
+		"-f", "/repo/config/values-prod.yaml",
+		"-f", valuesFile,
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("helmTemplateArgs() = %v, want %v", args, want)
+	}
+
+	values, err := os.ReadFile(valuesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(values) != "replicas: 3\n" {
+		t.Errorf("unexpected inline values: %q", string(values))
+	}
+}
+
+func TestSourceKind(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"k8s/manifest.yaml",
+		"k8s/app/kustomization.yaml",
+		"k8s/chart/Chart.yaml",
+	}
+	for _, f := range files {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"k8s/manifest.yaml", sourceManifest},
+		{"k8s/app", sourceKustomize},
+		{"k8s/chart", sourceHelm},
+	}
+	for _, tt := range tests {
+		got, err := sourceKind(filepath.Join(root, tt.path))
+		if err != nil {
+			t.Errorf("sourceKind(%s) error = %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("sourceKind(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := sourceKind(filepath.Join(root, "k8s")); err == nil {
+		t.Errorf("expected error for a plain directory")
+	}
+}
+
+func TestRequireRenderers(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"k8s/manifest.yaml", "k8s/app/kustomization.yaml"} {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// kubectl is not installed.
+	t.Setenv("PATH", t.TempDir())
+	manifest, kustomization := filepath.Join(root, "k8s/manifest.yaml"), filepath.Join(root, "k8s/app")
+
+	if ok, err := requireRenderers(tools.NewContext(t.Context(), false), []string{manifest}); !ok || err != nil {
+		t.Errorf("requireRenderers() of plain manifests = %v, %v, want true without kubectl", ok, err)
+	}
+	if ok, err := requireRenderers(tools.NewContext(t.Context(), false), []string{manifest, kustomization}); ok || err == nil {
+		t.Errorf("requireRenderers() of a kustomization = %v, %v, want an error naming kubectl", ok, err)
+	}
+	if ok, err := requireRenderers(tools.NewContext(t.Context(), true), []string{manifest, kustomization}); ok || err != nil {
+		t.Errorf("requireRenderers() of a kustomization ignoring missing tools = %v, %v, want a skip", ok, err)
+	}
+}
+
+func TestTargetWithOverrides(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `
+kubeconfig: config/kubeconfig
+context: staging
+namespace: app
+`
+	if err := os.WriteFile(filepath.Join(root, ".ap", "deploy.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadDeployConfig(root)
+	if err != nil {
+		t.Fatalf("LoadDeployConfig failed: %v", err)
+	}
+
+	got := cfg.Target.WithOverrides(Target{Namespace: "e2e-1234"})
+	want := Target{
+		Kubeconfig: filepath.Join(root, "config", "kubeconfig"),
+		Context:    "staging",
+		Namespace:  "e2e-1234",
+	}
+	if got != want {
+		t.Errorf("WithOverrides() = %+v, want %+v", got, want)
+	}
+
+	if got := cfg.Target.WithOverrides(Target{}); got != cfg.Target {
+		t.Errorf("empty overrides changed the target to %+v", got)
+	}
+}
+
+func TestDeployProfiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".ap/deploy.yaml": `
+namespace: default-ns
+profiles:
+  staging:
+    kubeconfig: config/staging.kubeconfig
+    context: staging
+    imagePrefix: us-docker.pkg.dev/staging/images
+    overlays:
+    - k8s/overlays/staging
+  prod:
+    context: prod
+    namespace: prod-ns
+    imageTag: stable
+    overlays:
+    - k8s/overlays/prod/
+`,
+		"k8s/app.yaml": "content",
+		"k8s/overlays/staging/kustomization.yaml": "content",
+		"k8s/overlays/prod/kustomization.yaml":    "content",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := LoadDeployConfig(root)
+	if err != nil {
+		t.Fatalf("LoadDeployConfig failed: %v", err)
+	}
+	staging, err := cfg.Profile("staging")
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	wantTarget := Target{Kubeconfig: filepath.Join(root, "config/staging.kubeconfig"), Context: "staging", Namespace: "default-ns"}
+	if got := cfg.Target.WithOverrides(staging.Target); got != wantTarget {
+		t.Errorf("staging target = %+v, want %+v", got, wantTarget)
+	}
+	if got, want := staging.Env(), map[string]string{"IMAGE_PREFIX": "us-docker.pkg.dev/staging/images"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Env() = %v, want %v", got, want)
+	}
+	if _, err := cfg.Profile("dev"); err == nil || !strings.Contains(err.Error(), "profiles: prod, staging") {
+		t.Errorf("expected an error listing the profiles, got %v", err)
+	}
+
+	manifests, err := findManifests(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for profile, want := range map[string][]string{
+		"":        {"k8s/app.yaml"},
+		"staging": {"k8s/app.yaml", "k8s/overlays/staging"},
+		"prod":    {"k8s/app.yaml", "k8s/overlays/prod"},
+	} {
+		got, err := cfg.profileManifests(root, manifests, profile)
+		if err != nil {
+			t.Fatalf("profileManifests(%q) failed: %v", profile, err)
+		}
+		var gotRel []string
+		for _, g := range got {
+			rel, _ := filepath.Rel(root, g)
+			gotRel = append(gotRel, rel)
+		}
+		if !reflect.DeepEqual(gotRel, want) {
+			t.Errorf("profileManifests(%q) = %v, want %v", profile, gotRel, want)
+		}
+	}
+
+	cfg.Profiles["prod"] = Profile{Overlays: []string{"k8s/overlays/missing"}}
+	if _, err := cfg.profileManifests(root, manifests, "prod"); err == nil {
+		t.Errorf("expected an error for a missing overlay")
+	}
+}
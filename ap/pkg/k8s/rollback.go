@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"k8s.io/klog/v2"
+)
+
+// RollbackOptions configures Rollback.
+type RollbackOptions struct {
+	// Profile selects a profile from .ap/deploy.yaml, if set.
+	Profile string
+
+	// Target overrides the cluster and namespace configured in .ap/deploy.yaml.
+	Target Target
+
+	// WaitForRollouts waits for the re-applied Deployments, StatefulSets and DaemonSets to become ready.
+	WaitForRollouts bool
+
+	// DryRun, if set, makes Rollback apply and delete with a server-side dry run and record the changes it would make,
+	// without changing the cluster.
+	DryRun *dryrun.Report
+}
+
+// Rollback undoes the last deploy recorded in the deploy history: the canaries of a canary deploy are deleted,
+// and a full deploy is undone by re-applying the manifest recorded by the full deploy before it.
+func Rollback(ctx context.Context, root string, opt RollbackOptions) error {
+	cfg, err := LoadDeployConfig(root)
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Profile(opt.Profile)
+	if err != nil {
+		return err
+	}
+	cfg.Target = cfg.Target.WithOverrides(profile.Target).WithOverrides(opt.Target)
+	inventory := inventoryName(root, cfg)
+
+	records, err := loadHistory(ctx, root, cfg.Target, inventory)
+	if err != nil {
+		return err
+	}
+	last, reapply, remaining, err := planRollback(records)
+	if err != nil {
+		return fmt.Errorf("cannot roll back %s: %w", inventory, err)
+	}
+	previous, err := readInventory(ctx, cfg.Target, inventory)
+	if err != nil {
+		return err
+	}
+
+	var refs []resourceRef
+	if reapply == nil {
+		klog.Infof("Rolling back the %d%% canary deployed at %s", last.Canary, describeRecord(last))
+		// The recorded manifest is already labeled, so this only lists its resources.
+		_, canaries, err := stampManagedBy(last.Manifest, inventory, nil, cfg.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := deleteManaged(ctx, cfg.Target, inventory, canaries, opt.DryRun); err != nil {
+			return err
+		}
+		refs = staleResources(previous, canaries)
+	} else {
+		klog.Infof("Rolling back the deploy at %s to the deploy at %s", describeRecord(last), describeRecord(*reapply))
+		if _, err := cfg.Target.apply(ctx, reapply.Manifest, applyOptions{failOnConflicts: cfg.FailOnConflicts, report: opt.DryRun}); err != nil {
+			return fmt.Errorf("rollback failed: %w", err)
+		}
+		if opt.DryRun != nil {
+			return nil
+		}
+		if err := waitForWorkloads(ctx, cfg.Target, reapply.Manifest, cfg.WaitForRollouts || opt.WaitForRollouts); err != nil {
+			return fmt.Errorf("rollback failed: %w", err)
+		}
+		_, reapplied, err := stampManagedBy(reapply.Manifest, inventory, nil, cfg.Namespace)
+		if err != nil {
+			return err
+		}
+		// Resources only in the rolled back deploy are kept in the inventory, so that a deploy with --prune deletes them.
+		refs = append(previous, staleResources(reapplied, previous)...)
+	}
+	if opt.DryRun != nil {
+		return nil
+	}
+
+	if err := writeInventory(ctx, cfg.Target, inventory, refs); err != nil {
+		return err
+	}
+	return saveHistory(ctx, root, cfg.Target, inventory, remaining)
+}
+
+// planRollback returns the last of records, the record to re-apply to undo it (nil if it is a canary deploy,
+// which is undone by deleting it), and the records that remain in the history after the rollback.
+func planRollback(records []deployRecord) (deployRecord, *deployRecord, []deployRecord, error) {
+	if len(records) == 0 {
+		return deployRecord{}, nil, nil, fmt.Errorf("no deploys are recorded in the history ConfigMap or in .build/deploy-manifest.json")
+	}
+	last := records[len(records)-1]
+	remaining := records[:len(records)-1]
+	if last.Canary != 0 {
+		return last, nil, remaining, nil
+	}
+
+	// Canaries deployed before the last full deploy were promoted by it, so there is nothing left of them to undo.
+	for len(remaining) > 0 && remaining[len(remaining)-1].Canary != 0 {
+		remaining = remaining[:len(remaining)-1]
+	}
+	if len(remaining) == 0 {
+		return deployRecord{}, nil, nil, fmt.Errorf("there is no earlier deploy recorded to roll back to")
+	}
+	return last, &remaining[len(remaining)-1], remaining, nil
+}
+
+// describeRecord returns when and from which commit the deploy of record was made, for logging.
+func describeRecord(record deployRecord) string {
+	s := record.Time.Local().Format(time.DateTime)
+	if record.GitSHA != "" {
+		s += " (" + record.GitSHA + ")"
+	}
+	return s
+}
+
+// waitForWorkloads waits for the workloads in content to become ready, if waitForRollouts is set or they opt in.
+// Jobs are not waited for, as re-applying a finished Job does not run it again.
+func waitForWorkloads(ctx context.Context, kube Target, content string, waitForRollouts bool) error {
+	targets, err := findWaitTargets(content, waitForRollouts)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if !hasRollout(target.Kind) {
+			continue
+		}
+		if err := waitForRollout(ctx, kube, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
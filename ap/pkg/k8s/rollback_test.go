@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanRollback(t *testing.T) {
+	v1 := deployRecord{Manifest: "v1"}
+	v2 := deployRecord{Manifest: "v2"}
+	canary := deployRecord{Manifest: "v2-canary", Canary: 10}
+
+	for _, tc := range []struct {
+		name          string
+		records       []deployRecord
+		wantReapply   *deployRecord
+		wantRemaining []deployRecord
+		wantErr       bool
+	}{
+		{name: "no history", wantErr: true},
+		{name: "single deploy", records: []deployRecord{v1}, wantErr: true},
+		{name: "deploy", records: []deployRecord{v1, v2}, wantReapply: &v1, wantRemaining: []deployRecord{v1}},
+		{name: "canary", records: []deployRecord{v1, canary}, wantRemaining: []deployRecord{v1}},
+		{name: "promoted canary", records: []deployRecord{v1, canary, v2}, wantReapply: &v1, wantRemaining: []deployRecord{v1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			last, reapply, remaining, err := planRollback(tc.records)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tc.records[len(tc.records)-1]; !reflect.DeepEqual(last, want) {
+				t.Errorf("last = %+v, want %+v", last, want)
+			}
+			if !reflect.DeepEqual(reapply, tc.wantReapply) {
+				t.Errorf("reapply = %+v, want %+v", reapply, tc.wantReapply)
+			}
+			if !reflect.DeepEqual(remaining, tc.wantRemaining) {
+				t.Errorf("remaining = %+v, want %+v", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
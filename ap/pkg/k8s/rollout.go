@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const defaultRolloutTimeout = 5 * time.Minute
+
+// hasRollout returns true for the kinds whose rollout deploy can wait for.
+func hasRollout(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return true
+	}
+	return false
+}
+
+// rollout is the progress of the rollout of a workload.
+type rollout struct {
+	// done is set once the rollout has finished.
+	done bool
+	// message describes what the rollout is waiting for.
+	message string
+	// selector selects the pods of the workload.
+	selector *metav1.LabelSelector
+	// conditions are the status conditions of the workload, for diagnostics.
+	conditions []string
+}
+
+// waitForRollout waits for the rollout of a Deployment, StatefulSet or DaemonSet to finish, as kubectl rollout status does.
+// If the rollout does not become ready, the workload conditions, warning events and pod logs are printed to help diagnose it.
+func waitForRollout(ctx context.Context, kube Target, target waitTarget) error {
+	klog.Infof("Waiting for %s to become ready (timeout %v)", target.String(), target.Timeout)
+
+	client, err := kube.client(ctx)
+	if err != nil {
+		return err
+	}
+	namespace := cmp.Or(target.Namespace, kube.Namespace, client.Namespace)
+
+	waitCtx, cancel := context.WithTimeout(ctx, target.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	var status rollout
+	for {
+		status, err = getRollout(waitCtx, client, namespace, target)
+		if err == nil && status.done {
+			klog.Infof("%s is ready", target.String())
+			return nil
+		}
+		if err == nil {
+			klog.V(2).Infof("%s: %s", target.String(), status.message)
+			select {
+			case <-waitCtx.Done():
+				err = fmt.Errorf("timed out: %s", status.message)
+			case <-ticker.C:
+				continue
+			}
+		}
+		printRolloutDiagnostics(ctx, client, namespace, target, status)
+		return fmt.Errorf("%s did not become ready: %w", target.String(), err)
+	}
+}
+
+// getRollout returns the progress of the rollout of target.
+func getRollout(ctx context.Context, client *kubeclient.Client, namespace string, target waitTarget) (rollout, error) {
+	var status rollout
+	err := kubeclient.Retry(ctx, func() error {
+		var err error
+		switch target.Kind {
+		case "Deployment":
+			var d *appsv1.Deployment
+			if d, err = client.AppsV1().Deployments(namespace).Get(ctx, target.Name, metav1.GetOptions{}); err == nil {
+				status, err = deploymentRollout(d)
+			}
+		case "StatefulSet":
+			var s *appsv1.StatefulSet
+			if s, err = client.AppsV1().StatefulSets(namespace).Get(ctx, target.Name, metav1.GetOptions{}); err == nil {
+				status = statefulSetRollout(s)
+			}
+		case "DaemonSet":
+			var d *appsv1.DaemonSet
+			if d, err = client.AppsV1().DaemonSets(namespace).Get(ctx, target.Name, metav1.GetOptions{}); err == nil {
+				status = daemonSetRollout(d)
+			}
+		default:
+			err = fmt.Errorf("%s has no rollout to wait for", target.String())
+		}
+		return err
+	})
+	return status, err
+}
+
+// deploymentRollout returns the progress of the rollout of d, or an error if it exceeded its progress deadline.
+func deploymentRollout(d *appsv1.Deployment) (rollout, error) {
+	status := rollout{selector: d.Spec.Selector}
+	for _, c := range d.Status.Conditions {
+		status.conditions = append(status.conditions, formatCondition(string(c.Type), c.Status, c.Reason, c.Message))
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			return status, fmt.Errorf("deployment %q exceeded its progress deadline", d.Name)
+		}
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	switch {
+	case d.Generation > d.Status.ObservedGeneration:
+		status.message = "waiting for the deployment spec update to be observed"
+	case d.Status.UpdatedReplicas < replicas:
+		status.message = fmt.Sprintf("%d out of %d new replicas have been updated", d.Status.UpdatedReplicas, replicas)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		status.message = fmt.Sprintf("%d old replicas are pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		status.message = fmt.Sprintf("%d of %d updated replicas are available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	default:
+		status.done = true
+	}
+	return status, nil
+}
+
+// statefulSetRollout returns the progress of the rollout of s. StatefulSets that are not updated
+// with the RollingUpdate strategy have no rollout to wait for.
+func statefulSetRollout(s *appsv1.StatefulSet) rollout {
+	status := rollout{selector: s.Spec.Selector}
+	for _, c := range s.Status.Conditions {
+		status.conditions = append(status.conditions, formatCondition(string(c.Type), c.Status, c.Reason, c.Message))
+	}
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	var partition int32
+	if u := s.Spec.UpdateStrategy.RollingUpdate; u != nil && u.Partition != nil {
+		partition = *u.Partition
+	}
+	switch {
+	case s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType:
+		status.done = true
+	case s.Status.ObservedGeneration == 0 || s.Generation > s.Status.ObservedGeneration:
+		status.message = "waiting for the statefulset spec update to be observed"
+	case s.Status.ReadyReplicas < replicas:
+		status.message = fmt.Sprintf("%d of %d pods are ready", s.Status.ReadyReplicas, replicas)
+	case partition > 0 && s.Status.UpdatedReplicas < replicas-partition:
+		status.message = fmt.Sprintf("%d out of %d new pods of the partitioned rollout have been updated", s.Status.UpdatedReplicas, replicas-partition)
+	case partition == 0 && s.Status.UpdateRevision != s.Status.CurrentRevision:
+		status.message = fmt.Sprintf("%d pods are at revision %s", s.Status.UpdatedReplicas, s.Status.UpdateRevision)
+	default:
+		status.done = true
+	}
+	return status
+}
+
+// daemonSetRollout returns the progress of the rollout of d. DaemonSets that are not updated
+// with the RollingUpdate strategy have no rollout to wait for.
+func daemonSetRollout(d *appsv1.DaemonSet) rollout {
+	status := rollout{selector: d.Spec.Selector}
+	for _, c := range d.Status.Conditions {
+		status.conditions = append(status.conditions, formatCondition(string(c.Type), c.Status, c.Reason, c.Message))
+	}
+	switch {
+	case d.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType:
+		status.done = true
+	case d.Generation > d.Status.ObservedGeneration:
+		status.message = "waiting for the daemon set spec update to be observed"
+	case d.Status.UpdatedNumberScheduled < d.Status.DesiredNumberScheduled:
+		status.message = fmt.Sprintf("%d out of %d new pods have been updated", d.Status.UpdatedNumberScheduled, d.Status.DesiredNumberScheduled)
+	case d.Status.NumberAvailable < d.Status.DesiredNumberScheduled:
+		status.message = fmt.Sprintf("%d of %d updated pods are available", d.Status.NumberAvailable, d.Status.DesiredNumberScheduled)
+	default:
+		status.done = true
+	}
+	return status
+}
+
+// formatCondition formats a status condition for diagnostics, e.g. "Available=False MinimumReplicasUnavailable: ...".
+func formatCondition(conditionType string, status corev1.ConditionStatus, reason, message string) string {
+	s := conditionType + "=" + string(status)
+	if reason != "" {
+		s += " " + reason
+	}
+	if message != "" {
+		s += ": " + message
+	}
+	return s
+}
+
+// printRolloutDiagnostics prints the conditions of the workload, the warning events of its namespace
+// and the recent logs of its pods to stderr. Failures are only logged, so that the original rollout error
+// is the one reported.
+func printRolloutDiagnostics(ctx context.Context, client *kubeclient.Client, namespace string, target waitTarget, status rollout) {
+	// The rollout may have failed because ctx timed out, so give ourselves a little more time.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	fmt.Fprintf(os.Stderr, "--- conditions of %s\n", target.String())
+	for _, c := range status.conditions {
+		fmt.Fprintln(os.Stderr, c)
+	}
+
+	// Pod problems (e.g. image pull or scheduling failures) show up as warning events on the pods.
+	fmt.Fprintf(os.Stderr, "--- warning events in namespace %s\n", namespace)
+	var events *corev1.EventList
+	err := kubeclient.Retry(ctx, func() error {
+		var err error
+		events, err = client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=Warning"})
+		return err
+	})
+	if err != nil {
+		klog.Warningf("failed to gather diagnostics for %s: %v", target.String(), err)
+	} else {
+		slices.SortStableFunc(events.Items, func(a, b corev1.Event) int {
+			return a.LastTimestamp.Compare(b.LastTimestamp.Time)
+		})
+		for _, e := range events.Items {
+			fmt.Fprintf(os.Stderr, "%s %s/%s: %s: %s\n", e.LastTimestamp.Format(time.RFC3339),
+				strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, e.Reason, e.Message)
+		}
+	}
+
+	if status.selector == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "--- logs of %s\n", target.String())
+	pods, err := listPods(ctx, client, namespace, status.selector)
+	if err == nil {
+		tail := int64(50)
+		err = podLogs(ctx, client, pods, corev1.PodLogOptions{TailLines: &tail}, os.Stderr)
+	}
+	if err != nil {
+		klog.Warningf("failed to gather diagnostics for %s: %v", target.String(), err)
+	}
+}
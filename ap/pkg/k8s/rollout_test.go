@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentRollout(t *testing.T) {
+	replicas := int32(3)
+	tests := []struct {
+		name        string
+		generation  int64
+		status      appsv1.DeploymentStatus
+		wantDone    bool
+		wantMessage string
+		wantErr     bool
+	}{
+		{
+			name:        "spec not observed",
+			generation:  2,
+			status:      appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantMessage: "waiting for the deployment spec update to be observed",
+		},
+		{
+			name:        "updating",
+			generation:  1,
+			status:      appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3},
+			wantMessage: "1 out of 3 new replicas have been updated",
+		},
+		{
+			name:        "old replicas terminating",
+			generation:  1,
+			status:      appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantMessage: "1 old replicas are pending termination",
+		},
+		{
+			name:        "unavailable",
+			generation:  1,
+			status:      appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+			wantMessage: "2 of 3 updated replicas are available",
+		},
+		{
+			name:       "done",
+			generation: 1,
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			wantDone:   true,
+		},
+		{
+			name:       "progress deadline exceeded",
+			generation: 1,
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "server", Generation: tt.generation},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     tt.status,
+			}
+			got, err := deploymentRollout(d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deploymentRollout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.done != tt.wantDone || got.message != tt.wantMessage {
+				t.Errorf("deploymentRollout() = done %v, %q, want done %v, %q", got.done, got.message, tt.wantDone, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestStatefulSetRollout(t *testing.T) {
+	replicas := int32(2)
+	s := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2, UpdatedReplicas: 1,
+			CurrentRevision: "db-1", UpdateRevision: "db-2"},
+	}
+	if got := statefulSetRollout(s); got.done || got.message != "1 pods are at revision db-2" {
+		t.Errorf("statefulSetRollout() of a rolling update = done %v, %q, want it to wait for the update", got.done, got.message)
+	}
+	s.Status.CurrentRevision = "db-2"
+	if got := statefulSetRollout(s); !got.done {
+		t.Errorf("statefulSetRollout() of an updated StatefulSet = %q, want done", got.message)
+	}
+}
+
+func TestDaemonSetRollout(t *testing.T) {
+	d := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Generation: 1},
+		Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+	}
+	if got := daemonSetRollout(d); got.done || got.message != "2 of 3 updated pods are available" {
+		t.Errorf("daemonSetRollout() = done %v, %q, want it to wait for the pods", got.done, got.message)
+	}
+	d.Status.NumberAvailable = 3
+	if got := daemonSetRollout(d); !got.done {
+		t.Errorf("daemonSetRollout() of an available DaemonSet = %q, want done", got.message)
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	replicas := int32(1)
+	deployment := func(name string, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod", Generation: 1},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			},
+			Status: status,
+		}
+	}
+	ready := deployment("ready", appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1})
+	stuck := deployment("stuck", appsv1.DeploymentStatus{ObservedGeneration: 1, Conditions: []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+	}})
+	ctx := kubeclient.NewContext(t.Context(), &kubeclient.Client{Interface: fake.NewClientset(ready, stuck)})
+	kube := Target{Namespace: "prod"}
+
+	if err := waitForRollout(ctx, kube, waitTarget{Kind: "Deployment", Name: "ready", Timeout: time.Minute}); err != nil {
+		t.Errorf("waitForRollout() of a ready Deployment = %v", err)
+	}
+	if err := waitForRollout(ctx, kube, waitTarget{Kind: "Deployment", Name: "stuck", Timeout: time.Minute}); err == nil {
+		t.Errorf("waitForRollout() of a Deployment past its progress deadline succeeded, want an error")
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/csaupgrade"
+	"k8s.io/klog/v2"
+)
+
+// Target selects the cluster and namespace that deploy applies to.
+// Empty fields fall back to the ambient kubeconfig ($KUBECONFIG or ~/.kube/config) and its current context.
+type Target struct {
+	// Kubeconfig is the path to the kubeconfig file.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// Context is the kubeconfig context to use.
+	Context string `json:"context,omitempty"`
+
+	// Namespace is the default namespace for resources that do not set one.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// WithOverrides returns a copy of t, with the non-empty fields of overrides taking precedence.
+func (t Target) WithOverrides(overrides Target) Target {
+	if overrides.Kubeconfig != "" {
+		t.Kubeconfig = overrides.Kubeconfig
+	}
+	if overrides.Context != "" {
+		t.Context = overrides.Context
+	}
+	if overrides.Namespace != "" {
+		t.Namespace = overrides.Namespace
+	}
+	return t
+}
+
+// client returns the Kubernetes API client for the cluster of the target.
+func (t Target) client(ctx context.Context) (*kubeclient.Client, error) {
+	return kubeclient.New(ctx, kubeclient.Options{Kubeconfig: t.Kubeconfig, Context: t.Context})
+}
+
+// csaFieldManager is the field manager of kubectl client-side apply, which earlier versions of ap applied with.
+const csaFieldManager = "kubectl-client-side-apply"
+
+// resources gives access to the resources of any kind in the cluster of a target.
+type resources struct {
+	kube   *kubeclient.Client
+	mapper meta.RESTMapper
+	// namespace is the namespace of namespaced resources that do not set one.
+	namespace string
+}
+
+// resources returns the resources of the cluster of the target.
+func (t Target) resources(ctx context.Context) (*resources, error) {
+	client, err := t.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if client.Dynamic == nil {
+		return nil, fmt.Errorf("the Kubernetes client has no dynamic client")
+	}
+	return &resources{kube: client, mapper: client.RESTMapper(), namespace: cmp.Or(t.Namespace, client.Namespace, "default")}, nil
+}
+
+// resource returns the client for the resources of mapping in namespace, or in the default namespace if it is empty,
+// along with the namespace. Namespaces are ignored for cluster-scoped resources.
+func (r *resources) resource(mapping *meta.RESTMapping, namespace string) (dynamic.ResourceInterface, string) {
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return r.kube.Dynamic.Resource(mapping.Resource), ""
+	}
+	namespace = cmp.Or(namespace, r.namespace)
+	return r.kube.Dynamic.Resource(mapping.Resource).Namespace(namespace), namespace
+}
+
+// resourceOf returns the client for the resource of obj, setting the default namespace on obj if it is namespaced.
+func (r *resources) resourceOf(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	resource, namespace := r.resource(mapping, obj.GetNamespace())
+	obj.SetNamespace(namespace)
+	return resource, nil
+}
+
+// get returns the object name of the resource, or nil if it does not exist.
+func get(ctx context.Context, resource dynamic.ResourceInterface, name string) (*unstructured.Unstructured, error) {
+	var obj *unstructured.Unstructured
+	err := kubeclient.Retry(ctx, func() error {
+		var err error
+		obj, err = resource.Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return obj, err
+}
+
+// applyObject applies obj server-side, with ap as the field manager, returning the object before and after the apply
+// (live is nil if the object did not exist). Fields owned by other field managers are taken over if force is set,
+// and are otherwise reported as conflicts. If dryRun is set, the apply is a server-side dry run.
+func (r *resources) applyObject(ctx context.Context, obj *unstructured.Unstructured, force bool, dryRun bool) (live, applied *unstructured.Unstructured, err error) {
+	resource, err := r.resourceOf(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	live, err = get(ctx, resource, obj.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	if live != nil && !dryRun {
+		// Hand the fields set by earlier kubectl client-side applies over to ap, so that the fields removed
+		// from the manifest are removed from the object, as kubectl apply --server-side does.
+		patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, sets.New(csaFieldManager), kubeclient.FieldManager)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to upgrade the managed fields: %w", err)
+		}
+		if patch != nil {
+			err = kubeclient.Retry(ctx, func() error {
+				_, err := resource.Patch(ctx, obj.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+				return err
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to upgrade the managed fields: %w", err)
+			}
+		}
+	}
+
+	opts := metav1.ApplyOptions{FieldManager: kubeclient.FieldManager, Force: force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	err = kubeclient.Retry(ctx, func() error {
+		var err error
+		applied, err = resource.Apply(ctx, obj.GetName(), obj, opts)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return live, applied, nil
+}
+
+// applyOptions configures Target.apply.
+type applyOptions struct {
+	// failOnConflicts fails the apply on conflicts with fields owned by other field managers (e.g. an autoscaler
+	// setting replicas), instead of taking the fields over.
+	failOnConflicts bool
+
+	// replaceJobs deletes and recreates the Jobs that have finished and whose spec changed, or whose pod
+	// template changed, instead of applying them in place.
+	replaceJobs bool
+
+	// report, if set, makes the apply a server-side dry run, recording the changes it would make in report.
+	report *dryrun.Report
+}
+
+// apply applies the objects in content server-side, with ap as the field manager, logging the change to each.
+// It returns the applied objects, which are only previews for dry runs.
+func (t Target) apply(ctx context.Context, content string, opts applyOptions) ([]*unstructured.Unstructured, error) {
+	objs, err := decodeObjects(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, nil
+	}
+	r, err := t.resources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dryRun := opts.report != nil
+	var result []*unstructured.Unstructured
+	for _, obj := range objs {
+		name := objectName(obj)
+		if err := stampSpecHash(obj); err != nil {
+			return nil, fmt.Errorf("failed to apply %s: %w", name, err)
+		}
+		replace := false
+		if opts.replaceJobs {
+			if replace, err = r.mustReplace(ctx, obj, !opts.failOnConflicts); err != nil {
+				return nil, fmt.Errorf("failed to apply %s: %w", name, err)
+			}
+		}
+		if replace && dryRun {
+			// The dry run cannot apply the Job without deleting the existing one first.
+			klog.Infof("%s replaced", name)
+			opts.report.Addf("%s replaced", name)
+			result = append(result, obj)
+			continue
+		}
+		if replace {
+			if err := r.deleteAndWait(ctx, obj); err != nil {
+				return nil, fmt.Errorf("failed to replace %s: %w", name, err)
+			}
+		}
+
+		live, applied, err := r.applyObject(ctx, obj, !opts.failOnConflicts, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s: %w", name, err)
+		}
+		change := applyChange(live, applied)
+		if replace {
+			change = "replaced"
+		}
+		klog.Infof("%s %s", name, change)
+		if dryRun && change != "unchanged" {
+			opts.report.Addf("%s %s", name, change)
+		}
+		result = append(result, applied)
+	}
+	return result, nil
+}
+
+// applyChange describes the change made by an apply, as kubectl does: created, configured or unchanged.
+func applyChange(live, applied *unstructured.Unstructured) string {
+	switch {
+	case live == nil:
+		return "created"
+	case reflect.DeepEqual(appliedContent(live), appliedContent(applied)):
+		return "unchanged"
+	default:
+		return "configured"
+	}
+}
+
+// appliedContent returns the content of obj without its status and the metadata the server updates on every change.
+func appliedContent(obj *unstructured.Unstructured) map[string]any {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	return obj.Object
+}
+
+// deleteResource deletes the resource ref, with a server-side dry run if dryRun is set. Resources that do not exist
+// are skipped, and so are those not labeled as managed by inventory, unless inventory is empty.
+// It returns the name of the deleted resource, or "" if it was skipped.
+func (r *resources) deleteResource(ctx context.Context, ref resourceRef, inventory string, dryRun bool) (string, error) {
+	// Inventories record kinds in lower case, which the mapper matches as the singular name of the resource.
+	gvk, err := r.mapper.KindFor(schema.GroupVersionResource{Group: ref.Group, Resource: strings.ToLower(ref.Kind)})
+	if meta.IsNoMatchError(err) {
+		// The cluster no longer serves the kind (e.g. its CRD was deleted), so there is nothing left to delete.
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return "", err
+	}
+	resource, _ := r.resource(mapping, ref.Namespace)
+	obj, err := get(ctx, resource, ref.Name)
+	if err != nil || obj == nil {
+		return "", err
+	}
+	if inventory != "" && obj.GetLabels()[ManagedByLabel] != inventory {
+		return "", nil
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	opts := metav1.DeleteOptions{
+		// The resource is only deleted if it is still the one that was checked.
+		Preconditions:     metav1.NewUIDPreconditions(string(obj.GetUID())),
+		PropagationPolicy: &propagation,
+	}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	err = kubeclient.Retry(ctx, func() error {
+		return resource.Delete(ctx, ref.Name, opts)
+	})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return objectName(obj), nil
+}
+
+// decodeObjects returns the objects in the YAML or JSON documents of content, with the items of lists
+// in place of the lists. Empty documents are skipped.
+func decodeObjects(content string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(content), 4096)
+	var objs []*unstructured.Unstructured
+	for {
+		var doc map[string]any
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: doc}
+		if !obj.IsList() {
+			objs = append(objs, obj)
+			continue
+		}
+		err = obj.EachListItem(func(item runtime.Object) error {
+			objs = append(objs, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", obj.GetKind(), err)
+		}
+	}
+	for _, obj := range objs {
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("resource without kind or metadata.name cannot be applied (generateName is not supported)")
+		}
+	}
+	return objs, nil
+}
+
+// objectName returns the name of obj as kubectl prints it, e.g. "deployment.apps/server".
+func objectName(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return resourceRef{Group: gvk.Group, Kind: gvk.Kind}.qualifiedKind() + "/" + obj.GetName()
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// fakeResources are the API resources served by the clients of newFakeClient.
+var fakeResources = []*metav1.APIResourceList{
+	{GroupVersion: "v1", APIResources: []metav1.APIResource{
+		{Name: "configmaps", SingularName: "configmap", Namespaced: true, Kind: "ConfigMap"},
+		{Name: "namespaces", SingularName: "namespace", Kind: "Namespace"},
+		{Name: "pods", SingularName: "pod", Namespaced: true, Kind: "Pod"},
+		{Name: "services", SingularName: "service", Namespaced: true, Kind: "Service"},
+	}},
+	{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+		{Name: "deployments", SingularName: "deployment", Namespaced: true, Kind: "Deployment"},
+	}},
+	{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{
+		{Name: "jobs", SingularName: "job", Namespaced: true, Kind: "Job"},
+	}},
+}
+
+// newFakeClient returns a client whose dynamic client holds the objects in manifest, and whose typed
+// clientset holds typed. Server-side applies replace the whole object but its UID, or only return it for dry runs.
+func newFakeClient(t *testing.T, manifest string, typed ...runtime.Object) (*kubeclient.Client, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	objs, err := decodeObjects(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var objects []runtime.Object
+	for _, obj := range objs {
+		objects = append(objects, obj)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(clientgoscheme.Scheme, objects...)
+	dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchActionImpl)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		tracker := dynamicClient.Tracker()
+		live, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if err == nil {
+			obj.SetUID(live.(*unstructured.Unstructured).GetUID())
+		}
+		switch {
+		case len(patch.PatchOptions.DryRun) > 0:
+			return true, obj, nil
+		case apierrors.IsNotFound(err):
+			err = tracker.Create(patch.GetResource(), obj, patch.GetNamespace())
+		case err == nil:
+			err = tracker.Update(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		return true, obj, err
+	})
+
+	clientset := fake.NewClientset(typed...)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = fakeResources
+	return &kubeclient.Client{Interface: clientset, Dynamic: dynamicClient, Namespace: "default"}, dynamicClient
+}
+
+// liveManifest is the state of the cluster in the tests of apply, diff and delete.
+const liveManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: demo
+  labels:
+    ap.gke-labs.dev/managed-by: ap-inventory-demo
+data:
+  mode: slow
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+  namespace: demo
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foreign
+  namespace: demo
+  labels:
+    ap.gke-labs.dev/managed-by: other
+`
+
+const applyManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: demo
+  labels:
+    ap.gke-labs.dev/managed-by: ap-inventory-demo
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: extra
+`
+
+var configMaps = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func TestTargetApply(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		dryRun          bool
+		failOnConflicts bool
+	}{
+		{name: "apply"},
+		{name: "dry run", dryRun: true},
+		{name: "fail on conflicts", failOnConflicts: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, dynamicClient := newFakeClient(t, liveManifest)
+			ctx := kubeclient.NewContext(t.Context(), client)
+			var report *dryrun.Report
+			if tc.dryRun {
+				report = &dryrun.Report{}
+			}
+
+			opts := applyOptions{failOnConflicts: tc.failOnConflicts, report: report}
+			if _, err := (Target{Namespace: "demo"}).apply(ctx, applyManifest, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			var applied []string
+			for _, action := range dynamicClient.Actions() {
+				patch, ok := action.(clienttesting.PatchActionImpl)
+				if !ok {
+					continue
+				}
+				applied = append(applied, patch.GetNamespace()+"/"+patch.GetName())
+				opts := patch.PatchOptions
+				if opts.FieldManager != kubeclient.FieldManager || *opts.Force == tc.failOnConflicts || (len(opts.DryRun) > 0) != tc.dryRun {
+					t.Errorf("apply of %s with %+v, want field manager %s, force %v and dry run %v",
+						patch.GetName(), opts, kubeclient.FieldManager, !tc.failOnConflicts, tc.dryRun)
+				}
+			}
+			if want := []string{"demo/settings", "demo/same", "/extra"}; !slices.Equal(applied, want) {
+				t.Errorf("applied %v, want %v", applied, want)
+			}
+
+			settings, err := dynamicClient.Resource(configMaps).Namespace("demo").Get(ctx, "settings", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mode, _, _ := unstructured.NestedString(settings.Object, "data", "mode")
+			if want := map[bool]string{true: "slow", false: "fast"}[tc.dryRun]; mode != want {
+				t.Errorf("mode after the apply = %q, want %q", mode, want)
+			}
+			if tc.dryRun {
+				want := []string{"configmap/settings configured", "namespace/extra created"}
+				if got := report.Changes(); !reflect.DeepEqual(got, want) {
+					t.Errorf("dry run changes = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteManaged(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "delete"},
+		{name: "dry run", dryRun: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, dynamicClient := newFakeClient(t, liveManifest)
+			ctx := kubeclient.NewContext(t.Context(), client)
+			var report *dryrun.Report
+			if tc.dryRun {
+				report = &dryrun.Report{}
+			}
+
+			refs := []resourceRef{
+				{Kind: "configmap", Namespace: "demo", Name: "settings"},
+				// Resources managed by another inventory, or that no longer exist, are skipped.
+				{Kind: "configmap", Namespace: "demo", Name: "foreign"},
+				{Kind: "configmap", Namespace: "demo", Name: "gone"},
+				// So are the kinds the cluster no longer serves.
+				{Group: "example.com", Kind: "widget", Namespace: "demo", Name: "w"},
+			}
+			if err := deleteManaged(ctx, Target{}, "ap-inventory-demo", refs, report); err != nil {
+				t.Fatal(err)
+			}
+
+			var deleted []string
+			for _, action := range dynamicClient.Actions() {
+				if del, ok := action.(clienttesting.DeleteActionImpl); ok {
+					deleted = append(deleted, del.GetName())
+					opts := del.DeleteOptions
+					if (len(opts.DryRun) > 0) != tc.dryRun || opts.Preconditions == nil || *opts.PropagationPolicy != metav1.DeletePropagationBackground {
+						t.Errorf("delete of %s with %+v, want dry run %v, a UID precondition and background propagation", del.GetName(), opts, tc.dryRun)
+					}
+				}
+			}
+			if want := []string{"settings"}; !slices.Equal(deleted, want) {
+				t.Errorf("deleted %v, want %v", deleted, want)
+			}
+			if tc.dryRun {
+				if got, want := report.Changes(), []string{"configmap/settings deleted"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("dry run changes = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeObjects(t *testing.T) {
+	objs, err := decodeObjects(`---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: b
+---
+{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "c"}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, obj := range objs {
+		names = append(names, objectName(obj))
+	}
+	if want := []string{"configmap/a", "deployment.apps/b", "service/c"}; !slices.Equal(names, want) {
+		t.Errorf("decodeObjects() = %v, want %v", names, want)
+	}
+
+	if _, err := decodeObjects("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  generateName: a-\n"); err == nil {
+		t.Errorf("decodeObjects() of an object without a name succeeded, want an error")
+	}
+}
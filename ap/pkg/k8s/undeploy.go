@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"k8s.io/klog/v2"
+)
+
+// UndeployOptions configures Undeploy.
+type UndeployOptions struct {
+	// Profile selects a profile from .ap/deploy.yaml, if set.
+	Profile string
+
+	// Target overrides the cluster and namespace configured in .ap/deploy.yaml.
+	Target Target
+
+	// DryRun, if set, makes Undeploy delete with a server-side dry run and record the deletions it would make,
+	// without changing the cluster.
+	DryRun *dryrun.Report
+}
+
+// Undeploy deletes the resources applied by Deploy.
+// The resources recorded in the inventory are deleted; if there is no inventory (e.g. the resources
+// were deployed by an older version of ap), the resources in the current manifests are deleted instead.
+func Undeploy(ctx context.Context, root string, opt UndeployOptions) error {
+	cfg, err := LoadDeployConfig(root)
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Profile(opt.Profile)
+	if err != nil {
+		return err
+	}
+	cfg.Target = cfg.Target.WithOverrides(profile.Target).WithOverrides(opt.Target)
+	inventory := inventoryName(root, cfg)
+
+	refs, err := readInventory(ctx, cfg.Target, inventory)
+	if err != nil {
+		return err
+	}
+	if refs == nil {
+		klog.Infof("No inventory %s found; deleting the resources in the current manifests", inventory)
+		return deleteManifests(ctx, root, cfg, opt.Profile, opt.DryRun)
+	}
+
+	if err := deleteManaged(ctx, cfg.Target, inventory, refs, opt.DryRun); err != nil {
+		return err
+	}
+	if opt.DryRun != nil {
+		return nil
+	}
+	if err := deleteInventory(ctx, cfg.Target, inventory); err != nil {
+		return err
+	}
+	// There is nothing left to roll back to.
+	return writeLocalHistory(root, cfg.Target, inventory, nil)
+}
+
+// deleteManifests deletes the resources in the manifests under root deployed with profile, in the reverse of the order they are applied.
+func deleteManifests(ctx context.Context, root string, cfg *DeployConfig, profile string, report *dryrun.Report) error {
+	manifests, err := findManifests(root)
+	if err != nil {
+		return err
+	}
+	manifests, err = cfg.profileManifests(root, manifests, profile)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+	if ok, err := requireRenderers(ctx, manifests); !ok {
+		return err
+	}
+	r, err := cfg.Target.resources(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(manifests) - 1; i >= 0; i-- {
+		manifest := manifests[i]
+		relPath, _ := filepath.Rel(root, manifest)
+
+		klog.Infof("Deleting manifest %s", relPath)
+
+		content, err := renderSource(ctx, root, manifest, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", relPath, err)
+		}
+
+		objs, err := decodeObjects(content)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", relPath, err)
+		}
+		for _, obj := range objs {
+			ref := resourceRef{Group: obj.GroupVersionKind().Group, Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+			deleted, err := r.deleteResource(ctx, ref, "", report != nil)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s from %s: %w", objectName(obj), relPath, err)
+			}
+			if deleted != "" && report != nil {
+				report.Addf("%s deleted", deleted)
+			}
+		}
+	}
+	return nil
+}
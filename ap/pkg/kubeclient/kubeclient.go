@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubeclient provides the Kubernetes API client ap uses to read and update cluster state,
+// instead of shelling out to kubectl. Unit tests set a fake clientset in the context with NewContext.
+package kubeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+)
+
+// FieldManager is the field manager of the changes ap applies server-side.
+const FieldManager = "ap"
+
+// Client is a Kubernetes API client for one cluster.
+type Client struct {
+	kubernetes.Interface
+
+	// Config is the REST configuration of the cluster, used to port-forward and exec into pods.
+	// It is nil for fake clients.
+	Config *rest.Config
+
+	// Dynamic is the client for resources of any kind, e.g. those in the manifests ap deploys.
+	Dynamic dynamic.Interface
+
+	// Namespace is the namespace of the kubeconfig context, or "default".
+	Namespace string
+}
+
+// Options selects the cluster to connect to.
+// Empty fields fall back to the ambient kubeconfig ($KUBECONFIG or ~/.kube/config) and its current context.
+type Options struct {
+	// Kubeconfig is the path to the kubeconfig file.
+	Kubeconfig string
+
+	// Context is the kubeconfig context to use.
+	Context string
+}
+
+type contextKey struct{}
+
+// NewContext returns a context in which New returns c, e.g. a client with a fake clientset.
+func NewContext(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// New returns the client set in ctx by NewContext, or else a client for the cluster selected by opt.
+func New(ctx context.Context, opt Options) (*Client, error) {
+	if c, ok := ctx.Value(contextKey{}).(*Client); ok {
+		return c, nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if opt.Kubeconfig != "" {
+		rules.ExplicitPath = opt.Kubeconfig
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: opt.Context})
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return &Client{Interface: clientset, Config: config, Dynamic: dynamicClient, Namespace: namespace}, nil
+}
+
+// RESTMapper returns a mapper from the kinds of objects to the resources serving them.
+// The resources of the cluster are discovered on first use, and cached by the mapper.
+func (c *Client) RESTMapper() meta.ResettableRESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(c.Discovery()))
+}
+
+// Backoff is the backoff between the attempts of Retry.
+var Backoff = wait.Backoff{
+	Steps:    5,
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not transient, or runs out of attempts,
+// backing off between attempts. The last error is returned.
+func Retry(ctx context.Context, fn func() error) error {
+	backoff := Backoff
+	for {
+		err := fn()
+		if err == nil || !IsTransient(err) || backoff.Steps <= 1 {
+			return err
+		}
+		delay := backoff.Step()
+		klog.V(2).Infof("Retrying in %v after transient error: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// IsTransient returns true for errors that are worth retrying, e.g. throttling, server timeouts or dropped connections.
+func IsTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionReset(err) ||
+		utilnet.IsConnectionRefused(err) ||
+		utilnet.IsProbableEOF(err)
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewFromContext(t *testing.T) {
+	want := &Client{Interface: fake.NewClientset(), Namespace: "default"}
+	got, err := New(NewContext(t.Context(), want), Options{Kubeconfig: "does-not-exist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("New() = %v, want the client of the context", got)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	backoff := Backoff
+	Backoff.Duration = time.Millisecond
+	t.Cleanup(func() { Backoff = backoff })
+
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "success",
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "transient",
+			errs:      []error{apierrors.NewTooManyRequests("slow down", 1), apierrors.NewServerTimeout(configMaps, "get", 1), nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "not found",
+			errs:      []error{apierrors.NewNotFound(configMaps, "inventory")},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "out of attempts",
+			errs: []error{
+				apierrors.NewInternalError(errors.New("boom")),
+				apierrors.NewInternalError(errors.New("boom")),
+				apierrors.NewInternalError(errors.New("boom")),
+				apierrors.NewInternalError(errors.New("boom")),
+				apierrors.NewInternalError(errors.New("boom")),
+			},
+			wantCalls: 5,
+			wantErr:   true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(t.Context(), func() error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Retry() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("Retry() called fn %d times, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{apierrors.NewServiceUnavailable("unavailable"), true},
+		{apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "inventory", errors.New("conflict")), false},
+		{context.DeadlineExceeded, false},
+		{errors.New("connection reset by peer"), true},
+	} {
+		if got := IsTransient(tc.err); got != tc.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package languages
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the contents of .ap/languages.yaml.
+type Config struct {
+	// Python formats and tests the Python projects, found by their pyproject.toml.
+	Python LanguageConfig `json:"python,omitempty"`
+
+	// TypeScript formats and tests the TypeScript and JavaScript projects, found by their package.json.
+	TypeScript LanguageConfig `json:"typescript,omitempty"`
+}
+
+// LanguageConfig enables a language, and selects its formatter and test runner.
+type LanguageConfig struct {
+	// Enabled turns on the formatting and testing of the projects of the language.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Formatter is the formatter to run (ruff for Python, prettier for TypeScript, the defaults), or "none".
+	Formatter string `json:"formatter,omitempty"`
+
+	// Tester is the test runner to run (pytest for Python, vitest for TypeScript, the defaults), or "none".
+	Tester string `json:"tester,omitempty"`
+
+	// Skip lists the project directories that are not formatted or tested, as paths or globs relative to the ap root.
+	Skip []string `json:"skip,omitempty"`
+}
+
+// LoadConfig loads .ap/languages.yaml from root, returning an empty config if it does not exist.
+func LoadConfig(root string) (*Config, error) {
+	configFile := filepath.Join(root, ".ap", "languages.yaml")
+
+	var config Config
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	for _, p := range plugins {
+		cfg := config.language(p.Name)
+		if err := validateTool(cfg.Formatter, p.Formatters); err != nil {
+			return nil, fmt.Errorf("error in %s: %s formatter: %w", configFile, p.Name, err)
+		}
+		if err := validateTool(cfg.Tester, p.Testers); err != nil {
+			return nil, fmt.Errorf("error in %s: %s tester: %w", configFile, p.Name, err)
+		}
+		for _, pattern := range cfg.Skip {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("error in %s: invalid %s skip pattern %q: %w", configFile, p.Name, pattern, err)
+			}
+		}
+	}
+	return &config, nil
+}
+
+// validateTool checks that name is empty, "none" or one of the tools.
+func validateTool(name string, tools map[string]Tool) error {
+	if name == "" || name == "none" {
+		return nil
+	}
+	if _, ok := tools[name]; !ok {
+		names := append(slices.Sorted(maps.Keys(tools)), "none")
+		return fmt.Errorf("must be one of %s, not %q", strings.Join(names, ", "), name)
+	}
+	return nil
+}
+
+// language returns the configuration of the language called name.
+func (c *Config) language(name string) *LanguageConfig {
+	switch name {
+	case "python":
+		return &c.Python
+	case "typescript":
+		return &c.TypeScript
+	}
+	return &LanguageConfig{}
+}
+
+// skips returns whether the project at rel, relative to the ap root, is skipped.
+func (c *LanguageConfig) skips(rel string) bool {
+	for _, pattern := range c.Skip {
+		if ok, _ := filepath.Match(filepath.Clean(pattern), rel); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package languages formats and tests the projects of languages other than Go (e.g. Python and TypeScript),
+// as tasks run by ap format and ap test alongside the Go ones.
+package languages
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+)
+
+// Plugin formats and tests the projects of a language.
+type Plugin struct {
+	// Name is the name of the language in .ap/languages.yaml.
+	Name string
+
+	// Manifest is the name of the file marking the directory of a project.
+	Manifest string
+
+	// Extensions are the extensions of the source files, which are formatted.
+	Extensions []string
+
+	// Inputs are the names of the other files the tests depend on, e.g. lock files.
+	Inputs []string
+
+	// Formatters and Testers are the supported tools, by name.
+	Formatters map[string]Tool
+	Testers    map[string]Tool
+
+	// DefaultFormatter and DefaultTester are used unless .ap/languages.yaml selects another tool.
+	DefaultFormatter string
+	DefaultTester    string
+}
+
+// Tool is a formatter or a test runner, run in the directory of a project.
+type Tool struct {
+	// Command is the command line. The files to format are appended to the command line of a formatter.
+	Command []string
+
+	// CheckCommand is the command line of a formatter reporting the changes it would make, without making them,
+	// and failing if there are any.
+	CheckCommand []string
+
+	// ReportArgs returns the arguments making a test runner write a JUnit XML report to path.
+	ReportArgs func(path string) []string
+}
+
+// plugins are the supported languages.
+var plugins = []*Plugin{
+	{
+		Name:       "python",
+		Manifest:   "pyproject.toml",
+		Extensions: []string{".py", ".pyi"},
+		Inputs:     []string{"pyproject.toml", "uv.lock", "poetry.lock", "requirements.txt", "conftest.py"},
+		Formatters: map[string]Tool{
+			"ruff": {
+				Command:      []string{"ruff", "format"},
+				CheckCommand: []string{"ruff", "format", "--check", "--diff"},
+			},
+		},
+		Testers: map[string]Tool{
+			"pytest": {
+				Command:    []string{"pytest"},
+				ReportArgs: func(path string) []string { return []string{"--junitxml=" + path} },
+			},
+		},
+		DefaultFormatter: "ruff",
+		DefaultTester:    "pytest",
+	},
+	{
+		Name:       "typescript",
+		Manifest:   "package.json",
+		Extensions: []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"},
+		Inputs:     []string{"package.json", "package-lock.json", "pnpm-lock.yaml", "yarn.lock", "tsconfig.json"},
+		Formatters: map[string]Tool{
+			"prettier": {
+				Command:      []string{"npx", "prettier", "--write"},
+				CheckCommand: []string{"npx", "prettier", "--check"},
+			},
+		},
+		Testers: map[string]Tool{
+			"vitest": {
+				Command: []string{"npx", "vitest", "run"},
+				ReportArgs: func(path string) []string {
+					return []string{"--reporter=default", "--reporter=junit", "--outputFile.junit=" + path}
+				},
+			},
+		},
+		DefaultFormatter: "prettier",
+		DefaultTester:    "vitest",
+	},
+}
+
+// ignoredDirs are the directories that hold dependencies or build outputs rather than sources.
+var ignoredDirs = []string{".git", ".build", "vendor", "node_modules", ".venv", "venv", "__pycache__", "dist"}
+
+// project is a directory with the manifest of a language.
+type project struct {
+	plugin *Plugin
+	// dir is the directory of the project, relative to the ap root.
+	dir string
+	// files are the source files of the project, relative to the ap root, excluding those of nested projects.
+	files []string
+}
+
+// FormatTasks returns the tasks formatting the projects of the enabled languages under root.
+// If files is not empty, only those files (relative to root or absolute) are formatted.
+// With check, the tasks report the changes formatting would make instead of making them.
+func FormatTasks(root string, files []string, check bool) ([]tasks.Task, error) {
+	projects, err := findProjects(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var only map[string]bool
+	if len(files) > 0 {
+		only = map[string]bool{}
+		for _, f := range files {
+			if filepath.IsAbs(f) {
+				if rel, err := filepath.Rel(root, f); err == nil {
+					f = rel
+				}
+			}
+			only[filepath.Clean(f)] = true
+		}
+	}
+
+	var formatTasks []tasks.Task
+	for _, p := range projects {
+		name, tool, ok := p.tool(p.config.Formatter, p.plugin.DefaultFormatter, p.plugin.Formatters)
+		if !ok {
+			continue
+		}
+		var projectFiles []string
+		for _, f := range p.files {
+			if only == nil || only[f] {
+				projectFiles = append(projectFiles, f)
+			}
+		}
+		if len(projectFiles) == 0 {
+			continue
+		}
+		formatTasks = append(formatTasks, &Task{
+			Kind:     KindFormat,
+			Language: p.plugin.Name,
+			ToolName: name,
+			Tool:     tool,
+			Dir:      p.dir,
+			Files:    projectFiles,
+			Check:    check,
+		})
+	}
+	return formatTasks, nil
+}
+
+// TestTasks returns the tasks testing the projects of the enabled languages under root.
+func TestTasks(root string) ([]tasks.Task, error) {
+	projects, err := findProjects(root)
+	if err != nil {
+		return nil, err
+	}
+	var testTasks []tasks.Task
+	for _, p := range projects {
+		name, tool, ok := p.tool(p.config.Tester, p.plugin.DefaultTester, p.plugin.Testers)
+		if !ok {
+			continue
+		}
+		testTasks = append(testTasks, &Task{
+			Kind:     KindTest,
+			Language: p.plugin.Name,
+			ToolName: name,
+			Tool:     tool,
+			Dir:      p.dir,
+			Inputs:   p.inputs(),
+		})
+	}
+	return testTasks, nil
+}
+
+// configuredProject is a project of an enabled language, with the configuration of the language.
+type configuredProject struct {
+	project
+	config *LanguageConfig
+}
+
+// tool returns the tool selected by name, or the default one, and false if there is none.
+func (p *configuredProject) tool(name string, defaultName string, tools map[string]Tool) (string, Tool, bool) {
+	name = cmp.Or(name, defaultName)
+	tool, ok := tools[name]
+	return name, tool, ok
+}
+
+// inputs returns the files the tests of the project depend on, relative to the ap root.
+func (p *configuredProject) inputs() []string {
+	inputs := slices.Clone(p.files)
+	for _, name := range p.plugin.Inputs {
+		inputs = append(inputs, filepath.Join(p.dir, name))
+	}
+	slices.Sort(inputs)
+	return slices.Compact(inputs)
+}
+
+// findProjects returns the projects of the enabled languages under root, in path order.
+func findProjects(root string) ([]*configuredProject, error) {
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []*configuredProject
+	for _, plugin := range plugins {
+		langCfg := cfg.language(plugin.Name)
+		if !langCfg.Enabled {
+			continue
+		}
+		found, err := plugin.findProjects(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range found {
+			if !langCfg.skips(p.dir) {
+				projects = append(projects, &configuredProject{project: p, config: langCfg})
+			}
+		}
+	}
+	return projects, nil
+}
+
+// findProjects returns the projects of the language under root, with their source files.
+// Each source file belongs to the innermost project containing it.
+func (p *Plugin) findProjects(root string) ([]project, error) {
+	ignoreList := walker.NewIgnoreList(ignoredDirs)
+	paths, err := walker.Walk(root, ignoreList, func(_ string, info os.FileInfo) bool {
+		return info.Name() == p.Manifest || slices.Contains(p.Extensions, filepath.Ext(info.Name()))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []project
+	byDir := map[string]int{}
+	var sources []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Base(rel) == p.Manifest {
+			byDir[filepath.Dir(rel)] = len(projects)
+			projects = append(projects, project{plugin: p, dir: filepath.Dir(rel)})
+		} else {
+			sources = append(sources, rel)
+		}
+	}
+
+	for _, source := range sources {
+		for dir := filepath.Dir(source); ; dir = filepath.Dir(dir) {
+			if i, ok := byDir[dir]; ok {
+				projects[i].files = append(projects[i].files, source)
+				break
+			}
+			if dir == "." || dir == string(filepath.Separator) {
+				break
+			}
+		}
+	}
+	slices.SortFunc(projects, func(a, b project) int { return strings.Compare(a.dir, b.dir) })
+	return projects, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package languages
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "defaults", config: "python:\n  enabled: true\n"},
+		{name: "tools", config: "python:\n  enabled: true\n  formatter: none\ntypescript:\n  enabled: true\n  tester: vitest\n"},
+		{name: "unknown formatter", config: "python:\n  formatter: black\n", wantErr: `python formatter: must be one of ruff, none, not "black"`},
+		{name: "unknown tester", config: "typescript:\n  tester: jest\n", wantErr: `typescript tester: must be one of vitest, none, not "jest"`},
+		{name: "invalid skip", config: "python:\n  skip: ['[']\n", wantErr: `invalid python skip pattern "["`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, map[string]string{".ap/languages.yaml": tc.config})
+			_, err := LoadConfig(root)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatTasks(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/languages.yaml":               "python:\n  enabled: true\n  skip: [legacy]\ntypescript:\n  enabled: true\n",
+		"svc/pyproject.toml":               "",
+		"svc/app/main.py":                  "",
+		"svc/app/README.md":                "",
+		"svc/.venv/lib/site.py":            "",
+		"legacy/pyproject.toml":            "",
+		"legacy/old.py":                    "",
+		"web/package.json":                 "{}",
+		"web/src/index.ts":                 "",
+		"web/node_modules/dep/index.js":    "",
+		"web/packages/ui/package.json":     "{}",
+		"web/packages/ui/src/button.tsx":   "",
+		"tools/script.py":                  "",
+		"main.go":                          "package main\n",
+		"web/packages/ui/vitest.config.ts": "",
+	})
+
+	got, err := FormatTasks(root, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"ruff svc":                 {"svc/app/main.py"},
+		"prettier web":             {"web/src/index.ts"},
+		"prettier web/packages/ui": {"web/packages/ui/src/button.tsx", "web/packages/ui/vitest.config.ts"},
+	}
+	if files := taskFiles(got); !reflect.DeepEqual(files, want) {
+		t.Errorf("format tasks = %v, want %v", files, want)
+	}
+
+	got, err = FormatTasks(root, []string{filepath.Join(root, "web/src/index.ts"), "README.md"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := taskFiles(got); !reflect.DeepEqual(files, map[string][]string{"prettier web": {"web/src/index.ts"}}) {
+		t.Errorf("format tasks of the changed files = %v, want only prettier web", files)
+	}
+	if !got[0].(*Task).Check {
+		t.Errorf("format task does not check")
+	}
+}
+
+func taskFiles(ts []tasks.Task) map[string][]string {
+	files := map[string][]string{}
+	for _, task := range ts {
+		files[task.GetName()] = task.(*Task).Files
+	}
+	return files
+}
+
+func TestFormatTaskRun(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/languages.yaml": "python:\n  enabled: true\n",
+		"svc/pyproject.toml": "",
+		"svc/app/main.py":    "",
+	})
+	ts, err := FormatTasks(root, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer := runner.NewReplayer(
+		runner.Invocation{Args: []string{"ruff", "format", "app/main.py"}},
+		runner.Invocation{Args: []string{"ruff", "format", "--check", "--diff", "app/main.py"}, ExitCode: 1},
+	)
+	ctx := runner.NewContext(t.Context(), replayer)
+	if err := tasks.Run(ctx, root, ts); err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	if calls := replayer.Calls(); len(calls) != 1 || calls[0].Dir != filepath.Join(root, "svc") {
+		t.Errorf("ran %+v, want ruff format in svc", calls)
+	}
+
+	ts, err = FormatTasks(root, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tasks.Run(ctx, root, ts)
+	if err == nil || !strings.Contains(err.Error(), "python files in svc need formatting") {
+		t.Errorf("format check error = %v, want python files in svc need formatting", err)
+	}
+}
+
+func TestTestTaskRun(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/languages.yaml": "typescript:\n  enabled: true\n",
+		"package.json":       "{}",
+		"src/index.ts":       "export const a = 1;\n",
+	})
+	ts, err := TestTasks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].GetName() != "vitest ." {
+		t.Fatalf("test tasks = %v, want vitest .", ts)
+	}
+
+	report, err := filepath.Abs(filepath.Join(root, ".build", "test-results", "typescript", "root.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vitest := runner.Invocation{Args: []string{"npx", "vitest", "run", "--reporter=default", "--reporter=junit", "--outputFile.junit=" + report}}
+	replayer := runner.NewReplayer(vitest, vitest)
+	ctx := runner.NewContext(t.Context(), replayer)
+
+	// The second run is skipped, since the inputs have not changed since the tests passed.
+	for range 2 {
+		if err := tasks.Run(ctx, root, ts); err != nil {
+			t.Fatalf("test failed: %v", err)
+		}
+	}
+	if n := len(replayer.Calls()); n != 1 {
+		t.Errorf("ran vitest %d times, want 1", n)
+	}
+
+	writeFiles(t, root, map[string]string{"src/index.ts": "export const a = 2;\n"})
+	if err := tasks.Run(ctx, root, ts); err != nil {
+		t.Fatalf("test failed: %v", err)
+	}
+	if n := len(replayer.Calls()); n != 2 {
+		t.Errorf("ran vitest %d times after changing a source file, want 2", n)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package languages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tasks"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"k8s.io/klog/v2"
+)
+
+// Kind is what a task does to a project.
+type Kind string
+
+const (
+	KindFormat Kind = "format"
+	KindTest   Kind = "test"
+)
+
+// Task formats or tests a project with a tool of its language.
+type Task struct {
+	Kind     Kind
+	Language string
+	// ToolName is the name of the tool in .ap/languages.yaml, e.g. ruff.
+	ToolName string
+	Tool     Tool
+	// Dir is the directory of the project, relative to the ap root.
+	Dir string
+
+	// Files are the files to format, relative to the ap root.
+	Files []string
+	// Check reports the changes formatting would make instead of making them.
+	Check bool
+
+	// Inputs are the files the tests depend on, relative to the ap root. Tests are skipped if they passed
+	// with the same inputs before.
+	Inputs []string
+
+	// Stdout receives the output of the tool, os.Stdout if it is nil.
+	Stdout io.Writer
+}
+
+// SetOutput sends the output of the language tasks in tasks to w.
+func SetOutput(tasks []tasks.Task, w io.Writer) {
+	for _, task := range tasks {
+		if t, ok := task.(*Task); ok {
+			t.Stdout = w
+		}
+	}
+}
+
+// GetName returns the name of the task, e.g. "pytest py/app".
+func (t *Task) GetName() string {
+	return t.ToolName + " " + filepath.ToSlash(t.Dir)
+}
+
+// Run runs the tool in the project, under the ap root at root.
+func (t *Task) Run(ctx context.Context, root string) error {
+	command := t.Tool.Command
+	if t.Check {
+		command = t.Tool.CheckCommand
+	}
+	if ok, err := tools.Require(ctx, tools.Requirement{Tool: command[0], Task: fmt.Sprintf("%s %s projects with %s", t.Kind, t.Language, t.ToolName)}); !ok {
+		return err
+	}
+
+	args := append([]string{}, command[1:]...)
+	switch t.Kind {
+	case KindFormat:
+		for _, f := range t.Files {
+			rel, err := filepath.Rel(t.Dir, f)
+			if err != nil {
+				return err
+			}
+			args = append(args, rel)
+		}
+	case KindTest:
+		if t.Tool.ReportArgs != nil {
+			report, err := t.reportPath(root)
+			if err != nil {
+				return err
+			}
+			args = append(args, t.Tool.ReportArgs(report)...)
+		}
+	}
+
+	var cm *cache.Manager
+	var hash string
+	if t.Kind == KindTest {
+		cm, hash = t.cacheKey(root, command)
+		if cm != nil {
+			if _, ok := cm.Done(t.cacheTool(), hash); ok {
+				klog.Infof("Inputs of %s are unchanged since its tests passed, skipping them", t.GetName())
+				return nil
+			}
+		}
+	}
+
+	klog.Infof("Running task: %s", t.GetName())
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	cmd.Dir = filepath.Join(root, t.Dir)
+	cmd.Stdout = t.Stdout
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	cmd.Stderr = os.Stderr
+	if err := runner.Run(ctx, cmd); err != nil {
+		if t.Check {
+			return fmt.Errorf("%s files in %s need formatting; run ap format to fix them", t.Language, t.Dir)
+		}
+		return fmt.Errorf("task %s failed: %w", t.GetName(), err)
+	}
+
+	if cm != nil {
+		cm.MarkDone(t.cacheTool(), hash, &cache.Result{})
+		if err := cm.Save(); err != nil {
+			klog.Warningf("Failed to save cache: %v", err)
+		}
+	}
+	return nil
+}
+
+// reportPath returns the path of the JUnit report of the tests, next to the go test results:
+// .build/test-results/<language>/<project dir>.xml ("root.xml" for a project at the ap root).
+func (t *Task) reportPath(root string) (string, error) {
+	name := t.Dir
+	if name == "." {
+		name = "root"
+	}
+	path := filepath.Join(root, ".build", "test-results", t.Language, name+".xml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create test results dir: %w", err)
+	}
+	return filepath.Abs(path)
+}
+
+// cacheTool is the tool the results of the task are recorded under in the cache.
+func (t *Task) cacheTool() string {
+	return "ap test " + t.Language
+}
+
+// cacheKey returns the cache and the key of the tests with their current inputs,
+// or a nil cache if it cannot be used.
+func (t *Task) cacheKey(root string, command []string) (*cache.Manager, string) {
+	cm, err := cache.NewManager(root)
+	if err != nil {
+		klog.V(2).Infof("Failed to initialize cache: %v", err)
+		return nil, ""
+	}
+	parts := append([]string{t.Dir}, command...)
+	for _, input := range t.Inputs {
+		path := filepath.Join(root, input)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		meta, err := cm.GetOrUpdateMetadata(path)
+		if err != nil {
+			klog.V(2).Infof("Failed to hash %s: %v", path, err)
+			return nil, ""
+		}
+		parts = append(parts, input, meta.Hash)
+	}
+	return cm, cache.Key(parts...)
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mutate implements mutation testing: it applies small changes (mutants) to the code of a
+// package, runs the package tests against each of them, and reports the mutants the tests did not
+// notice. A surviving mutant points at behavior the tests execute but do not assert.
+package mutate
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Operator is a kind of mutation.
+type Operator string
+
+const (
+	// NegateConditional replaces a comparison with its negation, e.g. == with != or < with >=.
+	NegateConditional Operator = "negate-conditional"
+	// SwapOperator replaces an arithmetic or logical operator with its counterpart, e.g. + with - or && with ||.
+	SwapOperator Operator = "swap-operator"
+	// DropErrorCheck disables an "if err != nil" check, so that the error is ignored.
+	DropErrorCheck Operator = "drop-error-check"
+)
+
+// negations maps each comparison operator to its negation.
+var negations = map[token.Token]token.Token{
+	token.EQL: token.NEQ,
+	token.NEQ: token.EQL,
+	token.LSS: token.GEQ,
+	token.GEQ: token.LSS,
+	token.GTR: token.LEQ,
+	token.LEQ: token.GTR,
+}
+
+// swaps maps each arithmetic or logical operator to the operator it is swapped with.
+var swaps = map[token.Token]token.Token{
+	token.ADD:  token.SUB,
+	token.SUB:  token.ADD,
+	token.MUL:  token.QUO,
+	token.QUO:  token.MUL,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+}
+
+// Mutant is a single change to a source file.
+type Mutant struct {
+	Operator Operator
+	// Pos is the location of the change.
+	Pos token.Position
+	// Original is the source text that is replaced, and Replacement the text it is replaced with.
+	Original    string
+	Replacement string
+
+	// start and end are the byte offsets of Original in the file.
+	start, end int
+}
+
+// Apply returns a copy of src, the content of the mutant's file, with the mutation applied.
+func (m Mutant) Apply(src []byte) []byte {
+	out := make([]byte, 0, len(src)-len(m.Original)+len(m.Replacement))
+	out = append(out, src[:m.start]...)
+	out = append(out, m.Replacement...)
+	return append(out, src[m.end:]...)
+}
+
+// Find returns the mutants of the Go source file filename with content src, in source order.
+// Generated files have no mutants.
+func Find(filename string, src []byte) ([]Mutant, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	if ast.IsGenerated(file) {
+		return nil, nil
+	}
+
+	text := func(pos, end token.Pos) string {
+		return string(src[fset.Position(pos).Offset:fset.Position(end).Offset])
+	}
+	var mutants []Mutant
+	add := func(op Operator, pos, end token.Pos, replacement string) {
+		start, stop := fset.Position(pos).Offset, fset.Position(end).Offset
+		mutants = append(mutants, Mutant{
+			Operator:    op,
+			Pos:         fset.Position(pos),
+			Original:    text(pos, end),
+			Replacement: replacement,
+			start:       start,
+			end:         stop,
+		})
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GenDecl:
+			// Constant expressions are often evaluated at compile time, where a mutation
+			// is reported as an overflow or a division by zero rather than by a test.
+			return n.Tok != token.CONST
+		case *ast.IfStmt:
+			if isErrorCheck(n.Cond) {
+				// Keep the condition, so that the error variable is still used.
+				add(DropErrorCheck, n.Cond.Pos(), n.Cond.End(), "false && "+text(n.Cond.Pos(), n.Cond.End()))
+			}
+		case *ast.BinaryExpr:
+			opEnd := n.OpPos + token.Pos(len(n.Op.String()))
+			if neg, ok := negations[n.Op]; ok {
+				add(NegateConditional, n.OpPos, opEnd, neg.String())
+			}
+			if swap, ok := swaps[n.Op]; ok && !(n.Op == token.ADD && (isString(n.X) || isString(n.Y))) {
+				add(SwapOperator, n.OpPos, opEnd, swap.String())
+			}
+		}
+		return true
+	})
+
+	// Operators of enclosing expressions are visited before those of their operands.
+	sort.SliceStable(mutants, func(i, j int) bool { return mutants[i].start < mutants[j].start })
+	return mutants, nil
+}
+
+// isErrorCheck reports whether cond is "err != nil", for a variable named err or ending in Err.
+func isErrorCheck(cond ast.Expr) bool {
+	bin, ok := cond.(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return false
+	}
+	if y, ok := bin.Y.(*ast.Ident); !ok || y.Name != "nil" {
+		return false
+	}
+	x, ok := bin.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	return x.Name == "err" || strings.HasSuffix(x.Name, "Err")
+}
+
+// isString reports whether expr is a string literal: "+" is then a concatenation, which has no
+// counterpart to swap it with.
+func isString(expr ast.Expr) bool {
+	lit, ok := expr.(*ast.BasicLit)
+	return ok && lit.Kind == token.STRING
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const source = `package sample
+
+import "errors"
+
+const limit = 1 + 2
+
+func Clamp(n int) int {
+	if n > limit {
+		return limit
+	}
+	return n
+}
+
+func Greeting(name string) string {
+	return "hello " + name
+}
+
+func Check(ok bool, n int) error {
+	err := validate(n)
+	if err != nil {
+		return err
+	}
+	if !ok && n == 0 {
+		return errors.New("not ok")
+	}
+	return nil
+}
+
+func validate(n int) error {
+	if n < 0 {
+		return errors.New("negative")
+	}
+	return nil
+}
+`
+
+func TestFind(t *testing.T) {
+	mutants, err := Find("sample.go", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range mutants {
+		got = append(got, string(m.Operator)+" "+m.Original+" -> "+m.Replacement)
+	}
+	want := []string{
+		"negate-conditional > -> <=",
+		"drop-error-check err != nil -> false && err != nil",
+		"negate-conditional != -> ==",
+		"swap-operator && -> ||",
+		"negate-conditional == -> !=",
+		"negate-conditional < -> >=",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Find() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if mutants[0].Pos.Line != 8 || mutants[0].Pos.Column != 7 {
+		t.Errorf("first mutant at %v, want 8:7", mutants[0].Pos)
+	}
+	if mutated := mutants[0].Apply([]byte(source)); !bytes.Contains(mutated, []byte("if n <= limit {")) {
+		t.Errorf("Apply() did not replace the operator:\n%s", mutated)
+	}
+}
+
+func TestFind_Generated(t *testing.T) {
+	src := "// Code generated by hand. DO NOT EDIT.\n\n" + source
+	mutants, err := Find("sample.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutants) != 0 {
+		t.Errorf("Find() returned %d mutants for a generated file, want none", len(mutants))
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/sample\n\ngo 1.24\n",
+		"max.go": "package sample\n\nfunc Max(a, b int) int {\n\tif a > b {\n\t\treturn a\n\t}\n\treturn b\n}\n",
+		"sum.go": "package sample\n\nfunc Sum(a, b int) int {\n\treturn a + b\n}\n",
+		"max_test.go": "package sample\n\nimport \"testing\"\n\nfunc TestMax(t *testing.T) {\n\tif Max(1, 2) != 2 || Max(2, 1) != 2 {\n\t\tt.Error(\"wrong max\")\n\t}\n}\n\n" +
+			// Sum is executed, but its result is never checked.
+			"func TestSum(t *testing.T) {\n\tSum(1, 2)\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs, err := Run(t.Context(), Options{Dir: dir, Patterns: []string{"./..."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("Run() returned %d packages, want 1", len(pkgs))
+	}
+	if got := pkgs[0].Count(Killed); got != 1 {
+		t.Errorf("killed = %d, want 1", got)
+	}
+
+	var report bytes.Buffer
+	WriteReport(&report, dir, pkgs)
+	want := `sum.go:4:11: swap-operator survived: "+" -> "-"
+example.com/sample: 2 mutants, 1 killed, 0 timed out, 1 survived, 0 invalid (score 50.0%)
+`
+	if report.String() != want {
+		t.Errorf("WriteReport() =\n%s\nwant\n%s", report.String(), want)
+	}
+}
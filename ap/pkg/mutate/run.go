@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Status is the outcome of running the tests against a mutant.
+type Status string
+
+const (
+	// Killed means the tests failed: they noticed the mutation.
+	Killed Status = "killed"
+	// Survived means the tests passed despite the mutation.
+	Survived Status = "survived"
+	// TimedOut means the tests did not finish in time, typically because the mutation introduced
+	// an endless loop; it counts as killed.
+	TimedOut Status = "timed out"
+	// Invalid means the mutated package does not compile; it does not count towards the score.
+	Invalid Status = "invalid"
+)
+
+// Result is the outcome of a single mutant.
+type Result struct {
+	Mutant
+	Status Status
+}
+
+// Package is the mutation testing report of a single package.
+type Package struct {
+	ImportPath string
+	Dir        string
+	Results    []Result
+}
+
+// Count returns the number of mutants with the given status.
+func (p *Package) Count(status Status) int {
+	n := 0
+	for _, r := range p.Results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Score returns the percentage of (compiling) mutants the tests killed.
+func (p *Package) Score() float64 {
+	valid := len(p.Results) - p.Count(Invalid)
+	if valid == 0 {
+		return 100
+	}
+	return 100 * float64(p.Count(Killed)+p.Count(TimedOut)) / float64(valid)
+}
+
+// Options configures a mutation testing run.
+type Options struct {
+	// Dir is the directory the package patterns are resolved in.
+	Dir string
+	// Patterns are the go package patterns to mutate, e.g. ./pkg/cache/...
+	Patterns []string
+}
+
+// goPackage is the subset of the go list output that is used.
+type goPackage struct {
+	ImportPath   string
+	Dir          string
+	GoFiles      []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// Run mutates the packages matching opt.Patterns one at a time, and runs their tests against each
+// mutant. The source files are never modified: the mutants are passed to go test as overlays.
+func Run(ctx context.Context, opt Options) ([]*Package, error) {
+	pkgs, err := listPackages(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ap-mutate-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var reports []*Package
+	for _, pkg := range pkgs {
+		if len(pkg.TestGoFiles)+len(pkg.XTestGoFiles) == 0 {
+			klog.Infof("Skipping %s: no tests", pkg.ImportPath)
+			continue
+		}
+		report, err := runPackage(ctx, opt.Dir, tmpDir, pkg)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// listPackages resolves the package patterns with go list.
+func listPackages(ctx context.Context, opt Options) ([]goPackage, error) {
+	args := append([]string{"list", "-json=ImportPath,Dir,GoFiles,TestGoFiles,XTestGoFiles"}, opt.Patterns...)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = opt.Dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list %s failed: %w", strings.Join(opt.Patterns, " "), err)
+	}
+
+	var pkgs []goPackage
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg goPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode go list output: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// runPackage runs the tests of pkg against each of its mutants.
+func runPackage(ctx context.Context, dir string, tmpDir string, pkg goPackage) (*Package, error) {
+	report := &Package{ImportPath: pkg.ImportPath, Dir: pkg.Dir}
+
+	start := time.Now()
+	if status, out := goTest(ctx, dir, pkg.ImportPath, "", 0); status != Survived {
+		return nil, fmt.Errorf("tests of %s fail without mutations:\n%s", pkg.ImportPath, out)
+	}
+	// Mutants that make the tests hang are stopped once they take much longer than the unmutated run.
+	timeout := 3*time.Since(start) + 30*time.Second
+
+	for _, name := range pkg.GoFiles {
+		path := filepath.Join(pkg.Dir, name)
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		mutants, err := Find(path, src)
+		if err != nil {
+			return nil, err
+		}
+		if len(mutants) > 0 {
+			klog.Infof("Testing %d mutants of %s", len(mutants), path)
+		}
+
+		for _, mutant := range mutants {
+			overlay, err := writeOverlay(tmpDir, path, mutant.Apply(src))
+			if err != nil {
+				return nil, err
+			}
+			status, _ := goTest(ctx, dir, pkg.ImportPath, overlay, timeout)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.Results = append(report.Results, Result{Mutant: mutant, Status: status})
+		}
+	}
+	return report, nil
+}
+
+// writeOverlay writes content as the replacement for path, and returns the go build overlay file
+// that replaces it.
+func writeOverlay(tmpDir string, path string, content []byte) (string, error) {
+	mutated := filepath.Join(tmpDir, "mutant.go")
+	if err := os.WriteFile(mutated, content, 0644); err != nil {
+		return "", err
+	}
+	overlay := struct{ Replace map[string]string }{Replace: map[string]string{path: mutated}}
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		return "", err
+	}
+	overlayFile := filepath.Join(tmpDir, "overlay.json")
+	if err := os.WriteFile(overlayFile, data, 0644); err != nil {
+		return "", err
+	}
+	return overlayFile, nil
+}
+
+// goTest runs the tests of the package importPath, with the given overlay file (if any), and returns
+// Survived if they passed. On failure, the go test output is returned as well.
+func goTest(ctx context.Context, dir string, importPath string, overlay string, timeout time.Duration) (Status, string) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// vet is disabled, as it reports some mutations (e.g. x != x) before the tests run.
+	args := []string{"test", "-count=1", "-failfast", "-vet=off"}
+	if overlay != "" {
+		args = append(args, "-overlay", overlay)
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, importPath)...)
+	cmd.Dir = dir
+	cmd.WaitDelay = 5 * time.Second
+	out, err := cmd.CombinedOutput()
+	switch {
+	case err == nil:
+		return Survived, ""
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return TimedOut, string(out)
+	case bytes.Contains(out, []byte("[build failed]")) || bytes.Contains(out, []byte("[setup failed]")):
+		return Invalid, string(out)
+	default:
+		return Killed, string(out)
+	}
+}
+
+// WriteReport writes the surviving mutants of each package, with their locations relative to dir,
+// followed by a summary line per package.
+func WriteReport(w io.Writer, dir string, pkgs []*Package) {
+	for _, pkg := range pkgs {
+		for _, r := range pkg.Results {
+			if r.Status != Survived {
+				continue
+			}
+			path := r.Pos.Filename
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				path = rel
+			}
+			fmt.Fprintf(w, "%s:%d:%d: %s survived: %q -> %q\n", path, r.Pos.Line, r.Pos.Column, r.Operator, r.Original, r.Replacement)
+		}
+	}
+	for _, pkg := range pkgs {
+		fmt.Fprintf(w, "%s: %d mutants, %d killed, %d timed out, %d survived, %d invalid (score %.1f%%)\n",
+			pkg.ImportPath, len(pkg.Results), pkg.Count(Killed), pkg.Count(TimedOut), pkg.Count(Survived), pkg.Count(Invalid), pkg.Score())
+	}
+}
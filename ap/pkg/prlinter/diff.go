@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prlinter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is the change to one file in a unified diff, as produced by git diff.
+type FileDiff struct {
+	// OldPath and NewPath are the paths of the file before and after the change; OldPath is empty for an added
+	// file, and NewPath for a deleted one.
+	OldPath string
+	NewPath string
+
+	Hunks []Hunk
+}
+
+// Hunk is a contiguous change to a file.
+type Hunk struct {
+	// OldStart and NewStart are the 1-based first lines of the hunk in the old and the new file,
+	// and OldLines and NewLines the number of lines it spans in each.
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+
+	// Lines are the lines of the hunk, with their " ", "-" or "+" prefix.
+	Lines []string
+}
+
+// AddedLines returns the line numbers in the new file of the lines that were added or modified.
+func (f *FileDiff) AddedLines() []int {
+	var lines []int
+	for _, h := range f.Hunks {
+		line := h.NewStart
+		for _, l := range h.Lines {
+			switch {
+			case strings.HasPrefix(l, "+"):
+				lines = append(lines, line)
+				line++
+			case strings.HasPrefix(l, " "):
+				line++
+			}
+		}
+	}
+	return lines
+}
+
+// hunkHeaderRegexp matches a hunk header, e.g. "@@ -12,3 +12,4 @@ func main() {".
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseDiff parses the output of git diff into the changes to each file.
+func ParseDiff(diff string) ([]FileDiff, error) {
+	var files []FileDiff
+	var file *FileDiff
+	// oldLeft and newLeft are the lines of the current hunk still to be read, so that its lines
+	// starting with "---" or "+++" are not taken for file headers.
+	oldLeft, newLeft := 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		if file != nil && (oldLeft > 0 || newLeft > 0) {
+			h := &file.Hunks[len(file.Hunks)-1]
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				// git omits the space of empty context lines in some configurations.
+				line = " "
+				oldLeft--
+				newLeft--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+				continue
+			default:
+				return nil, fmt.Errorf("unexpected line in hunk of %s: %q", file.path(), line)
+			}
+			h.Lines = append(h.Lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FileDiff{})
+			file = &files[len(files)-1]
+			if a, b, ok := strings.Cut(strings.TrimPrefix(line, "diff --git "), " b/"); ok {
+				// The paths are only known from this line for changes without hunks, e.g. a binary file or a mode change.
+				file.OldPath, file.NewPath = diffPath(a), diffPath("b/"+b)
+			}
+		case file == nil, strings.HasPrefix(line, `\`):
+			continue
+		case strings.HasPrefix(line, "--- "):
+			file.OldPath = diffPath(strings.TrimPrefix(line, "--- "))
+		case strings.HasPrefix(line, "+++ "):
+			file.NewPath = diffPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "rename to "):
+			file.NewPath = unquote(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "@@ "):
+			m := hunkHeaderRegexp.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header in %s: %q", file.path(), line)
+			}
+			h := Hunk{OldStart: atoi(m[1]), OldLines: atoiOr(m[2], 1), NewStart: atoi(m[3]), NewLines: atoiOr(m[4], 1)}
+			file.Hunks = append(file.Hunks, h)
+			oldLeft, newLeft = h.OldLines, h.NewLines
+		}
+	}
+	return files, nil
+}
+
+// path returns the path of the file in error messages.
+func (f *FileDiff) path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// diffPath returns the path of a "--- a/path" or "+++ b/path" line, or "" for /dev/null.
+func diffPath(s string) string {
+	s = unquote(strings.TrimRight(s, "\t"))
+	if s == "/dev/null" {
+		return ""
+	}
+	if _, path, ok := strings.Cut(s, "/"); ok && (strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/")) {
+		return path
+	}
+	return s
+}
+
+// unquote returns s without the quotes git puts around paths with special characters.
+func unquote(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// atoiOr returns the number s, or def if s is empty, as git omits the line count of single-line ranges.
+func atoiOr(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	return atoi(s)
+}
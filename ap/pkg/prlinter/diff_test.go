@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prlinter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,4 +3,5 @@ package main
+ import "fmt"
+ 
+-func main() {
++func main() { // changed
++	fmt.Println("hi")
+--- not a header
+ }
+@@ -20 +21 @@ func other() {
+-	old()
++	new()
+\ No newline at end of file
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++one
++two
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/old name.go b/new name.go
+similarity index 90%
+rename from old name.go
+rename to new name.go
+diff --git a/image.png b/image.png
+index 5555555..6666666 100644
+Binary files a/image.png and b/image.png differ
+`
+	files, err := ParseDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type change struct {
+		OldPath, NewPath string
+		Hunks            int
+		Added            []int
+	}
+	var got []change
+	for _, f := range files {
+		got = append(got, change{f.OldPath, f.NewPath, len(f.Hunks), f.AddedLines()})
+	}
+	want := []change{
+		{"main.go", "main.go", 2, []int{5, 6, 21}},
+		{"", "new.txt", 1, []int{1, 2}},
+		{"gone.txt", "", 1, nil},
+		{"old name.go", "new name.go", 0, nil},
+		{"image.png", "image.png", 0, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDiff() =\n%+v\nwant\n%+v", got, want)
+	}
+	if h := files[0].Hunks[0]; h.OldStart != 3 || h.OldLines != 4 || h.NewStart != 3 || h.NewLines != 5 || len(h.Lines) != 7 {
+		t.Errorf("first hunk = %+v, want -3,4 +3,5 with 7 lines", h)
+	}
+}
+
+func TestParseDiffInvalid(t *testing.T) {
+	if _, err := ParseDiff("diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ bogus @@\n"); err == nil {
+		t.Error("ParseDiff() of an invalid hunk header succeeded, want an error")
+	}
+}
@@ -18,37 +18,147 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/largefiles"
 	"k8s.io/klog/v2"
 )
 
-// Lint runs PR-specific linting checks.
-func Lint(ctx context.Context, repoRoot string) error {
+// Lint runs PR-specific linting checks against the base branch, if one is found.
+// Double-spaced code fails the lint with an error; the files added since the base branch are checked for
+// binaries and large files, as configured in the go.yaml of repoRoot, and reported as findings.
+// If files (absolute paths) is not empty, only those of the added files are checked.
+func Lint(ctx context.Context, repoRoot string, files []string) ([]findings.Finding, error) {
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, err := tools.Require(ctx, tools.Requirement{Tool: "git", Task: "lint the changes of the pull request"}); !ok {
+		return nil, err
+	}
+	mergeBase, err := findMergeBase(ctx, repoRoot)
+	if err != nil || mergeBase == "" {
+		return nil, err
+	}
+
+	diff, err := getDiff(ctx, repoRoot, mergeBase)
+	if err != nil {
+		return nil, fmt.Errorf("error getting diff: %w", err)
+	}
+
+	if err := checkDoubleSpacing(diff); err != nil {
+		return nil, err
+	}
+
+	if !cfg.IsLargeFilesEnabled() {
+		return nil, nil
+	}
+	maxSize, err := cfg.LargeFilesMaxSize()
+	if err != nil {
+		return nil, err
+	}
+	added, err := addedFiles(ctx, repoRoot, mergeBase)
+	if err != nil {
+		return nil, fmt.Errorf("error listing added files: %w", err)
+	}
+	if len(files) > 0 {
+		added = slices.DeleteFunc(added, func(file string) bool {
+			return !slices.Contains(files, filepath.Join(repoRoot, file))
+		})
+	}
+	severity := findings.SeverityWarning
+	if cfg.IsLargeFilesError() {
+		severity = findings.SeverityError
+	}
+	return largefiles.Check(repoRoot, added, largefiles.Options{MaxSize: maxSize, Allow: cfg.LargeFilesAllow(), Severity: severity})
+}
+
+// EnforceOnChangedLines applies the rules configured in lint.changedLinesOnly of the go.yaml of repoRoot to all,
+// findings with paths relative to repoRoot: their errors only fail the lint on the lines added or modified since
+// the base branch, and are downgraded to warnings elsewhere, so that a new rule can be enforced on new code
+// before the existing code is cleaned up. Without a base branch, no line has changed.
+func EnforceOnChangedLines(ctx context.Context, repoRoot string, all []findings.Finding) ([]findings.Finding, error) {
+	cfg, err := config.Load(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	rules := cfg.ChangedLinesOnlyRules()
+	if len(rules) == 0 {
+		return all, nil
+	}
+
+	changed := map[string][]int{}
+	if ok, err := tools.Require(ctx, tools.Requirement{Tool: "git", Task: "find the lines changed by the pull request"}); !ok {
+		return nil, err
+	}
+	mergeBase, err := findMergeBase(ctx, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if mergeBase != "" {
+		diff, err := getDiff(ctx, repoRoot, mergeBase)
+		if err != nil {
+			return nil, fmt.Errorf("error getting diff: %w", err)
+		}
+		files, err := ParseDiff(diff)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing diff: %w", err)
+		}
+		for _, f := range files {
+			if f.NewPath != "" {
+				changed[f.NewPath] = f.AddedLines()
+			}
+		}
+	}
+	return onChangedLines(all, rules, changed), nil
+}
+
+// onChangedLines returns all with the errors of rules downgraded to warnings, unless they are on one of the
+// changed lines of their file. Findings without a line are on changed lines if any line of their file changed.
+func onChangedLines(all []findings.Finding, rules []string, changed map[string][]int) []findings.Finding {
+	all = slices.Clone(all)
+	for i, f := range all {
+		if f.Severity == findings.SeverityWarning || !slices.Contains(rules, f.Rule) {
+			continue
+		}
+		lines := changed[filepath.ToSlash(f.Path)]
+		if f.Line == 0 && len(lines) > 0 || slices.Contains(lines, f.Line) {
+			continue
+		}
+		all[i].Severity = findings.SeverityWarning
+	}
+	return all
+}
+
+// findMergeBase returns the merge base of HEAD and the base branch, or "" if there is no base branch.
+func findMergeBase(ctx context.Context, repoRoot string) (string, error) {
 	baseBranch, err := detectBaseBranch(ctx, repoRoot)
 	if err != nil {
 		klog.V(2).Infof("Could not detect base branch: %v", err)
-		return nil
+		return "", nil
 	}
 
 	if baseBranch == "" {
 		klog.V(2).Info("No base branch detected, skipping PR lint")
-		return nil
+		return "", nil
 	}
 
 	klog.Infof("Comparing against base branch %q", baseBranch)
 
-	diff, err := getDiff(ctx, repoRoot, baseBranch)
+	cmd := exec.CommandContext(ctx, "git", "merge-base", baseBranch, "HEAD")
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
 	if err != nil {
-		return fmt.Errorf("error getting diff: %w", err)
-	}
-
-	if err := checkDoubleSpacing(diff); err != nil {
-		return err
+		return "", fmt.Errorf("error finding merge base: %w", err)
 	}
-
-	return nil
+	return strings.TrimSpace(string(out)), nil
 }
 
 func detectBaseBranch(ctx context.Context, repoRoot string) (string, error) {
@@ -81,19 +191,10 @@ func detectBaseBranch(ctx context.Context, repoRoot string) (string, error) {
 	return "", nil
 }
 
-func getDiff(ctx context.Context, repoRoot, baseBranch string) (string, error) {
-	// Find the merge base between baseBranch and HEAD
-	cmd := exec.CommandContext(ctx, "git", "merge-base", baseBranch, "HEAD")
-	cmd.Dir = repoRoot
-	mergeBaseOut, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("error finding merge base: %w", err)
-	}
-	mergeBase := strings.TrimSpace(string(mergeBaseOut))
-
+func getDiff(ctx context.Context, repoRoot, mergeBase string) (string, error) {
 	// git diff mergeBase
 	// This compares the merge base with the working tree, including staged changes.
-	cmd = exec.CommandContext(ctx, "git", "diff", mergeBase)
+	cmd := exec.CommandContext(ctx, "git", "diff", mergeBase)
 	cmd.Dir = repoRoot
 	out, err := cmd.Output()
 	if err != nil {
@@ -102,6 +203,24 @@ func getDiff(ctx context.Context, repoRoot, baseBranch string) (string, error) {
 	return string(out), nil
 }
 
+// addedFiles returns the files added in the working tree (including staged changes) since mergeBase,
+// relative to repoRoot.
+func addedFiles(ctx context.Context, repoRoot, mergeBase string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", "--no-renames", "--diff-filter=A", "-z", mergeBase)
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, filepath.FromSlash(file))
+		}
+	}
+	return files, nil
+}
+
 func checkDoubleSpacing(diff string) error {
 	lines := strings.Split(diff, "\n")
 
@@ -15,7 +15,13 @@
 package prlinter
 
 import (
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/gittest"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
 )
 
 func TestCheckDoubleSpacing(t *testing.T) {
@@ -62,3 +68,106 @@ func TestCheckDoubleSpacing(t *testing.T) {
 		})
 	}
 }
+
+func TestLint_LargeFiles(t *testing.T) {
+	repo := gittest.NewRepo(t, "-b", "main")
+	repo.WriteFile(".ap/go.yaml", "lint:\n  largefiles:\n    maxSize: 1KB\n    allow: [\"**/testdata/\"]\n")
+	repo.WriteFile("existing.bin", "\x00 committed before the pull request")
+	repo.Git("add", ".")
+	repo.Git("commit", "-q", "-m", "base")
+
+	repo.Git("checkout", "-q", "-b", "feature")
+	repo.WriteFile("added.bin", "\x00")
+	repo.WriteFile("large.txt", strings.Repeat("x", 2000))
+	repo.WriteFile("testdata/fixture.bin", "\x00")
+	repo.WriteFile("existing.bin", "\x00 modified")
+	repo.Git("add", ".")
+	repo.Git("commit", "-q", "-m", "feature")
+	repo.WriteFile("staged.bin", "\x00")
+	repo.Git("add", "staged.bin")
+
+	found, err := Lint(t.Context(), repo.Dir, nil)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	var got []string
+	for _, f := range found {
+		got = append(got, f.Path)
+	}
+	if want := "added.bin large.txt staged.bin"; strings.Join(got, " ") != want {
+		t.Errorf("Lint() flagged %v, want %s", got, want)
+	}
+
+	found, err = Lint(t.Context(), repo.Dir, []string{filepath.Join(repo.Dir, "staged.bin")})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(found) != 1 || found[0].Path != "staged.bin" {
+		t.Errorf("Lint(staged.bin) = %v, want only staged.bin", found)
+	}
+}
+
+func TestOnChangedLines(t *testing.T) {
+	all := []findings.Finding{
+		{Path: "a.go", Line: 3, Rule: "strict", Severity: findings.SeverityError},
+		{Path: "a.go", Line: 10, Rule: "strict", Severity: findings.SeverityError},
+		{Path: "a.go", Line: 10, Rule: "other", Severity: findings.SeverityError},
+		{Path: "a.go", Rule: "strict", Severity: findings.SeverityError},
+		{Path: "b.go", Rule: "strict", Severity: findings.SeverityError},
+	}
+	got := onChangedLines(all, []string{"strict"}, map[string][]int{"a.go": {3, 4}})
+	var severities []findings.Severity
+	for _, f := range got {
+		severities = append(severities, f.Severity)
+	}
+	want := []findings.Severity{
+		findings.SeverityError,
+		findings.SeverityWarning,
+		findings.SeverityError,
+		findings.SeverityError,
+		findings.SeverityWarning,
+	}
+	if !reflect.DeepEqual(severities, want) {
+		t.Errorf("onChangedLines() severities = %q, want %q", severities, want)
+	}
+	if all[1].Severity != findings.SeverityError {
+		t.Errorf("onChangedLines() modified its input")
+	}
+}
+
+func TestEnforceOnChangedLines(t *testing.T) {
+	repo := gittest.NewRepo(t, "-b", "main")
+	repo.WriteFile(".ap/go.yaml", "lint:\n  changedLinesOnly: [mutexcopy]\n")
+	repo.WriteFile("main.go", "package main\n\nfunc a() {}\n\nfunc b() {}\n")
+	repo.Git("add", ".")
+	repo.Git("commit", "-q", "-m", "base")
+
+	all := []findings.Finding{
+		{Path: "main.go", Line: 3, Rule: "mutexcopy", Severity: findings.SeverityError},
+		{Path: "main.go", Line: 5, Rule: "mutexcopy", Severity: findings.SeverityError},
+	}
+	severities := func() string {
+		t.Helper()
+		found, err := EnforceOnChangedLines(t.Context(), repo.Dir, all)
+		if err != nil {
+			t.Fatalf("EnforceOnChangedLines failed: %v", err)
+		}
+		var got []string
+		for _, f := range found {
+			got = append(got, string(f.Severity))
+		}
+		return strings.Join(got, " ")
+	}
+
+	// On the base branch, no line has changed.
+	repo.Git("checkout", "-q", "-b", "feature")
+	if got, want := severities(), "warning warning"; got != want {
+		t.Errorf("severities without changes = %s, want %s", got, want)
+	}
+
+	repo.WriteFile("main.go", "package main\n\nfunc a() {}\n\nfunc b() { a() }\n")
+	repo.Git("commit", "-q", "-am", "feature")
+	if got, want := severities(), "warning error"; got != want {
+		t.Errorf("severities with line 5 changed = %s, want %s", got, want)
+	}
+}
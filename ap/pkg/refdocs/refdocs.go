@@ -0,0 +1,205 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refdocs generates the Markdown reference documentation of ap: a page for each command, from the cobra
+// command tree, and a page for each config file, from the Go types it is loaded into and their doc comments.
+package refdocs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/configcheck"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DefaultOutput is the directory of the reference documentation, relative to the repository root,
+// when .ap/generate.yaml does not set one.
+const DefaultOutput = "docs/reference"
+
+// generatedNotice starts every page, so that readers know not to edit them.
+const generatedNotice = "<!-- Code generated by ap generate. DO NOT EDIT. -->\n\n"
+
+// Subdirectories of the output holding the pages of commands and config files.
+const (
+	commandsDir = "commands"
+	configDir   = "config"
+)
+
+// Generate returns the pages documenting the commands under root and the config files, keyed by their
+// path relative to the output directory. The doc comments of the config types are read from the
+// source of the module checked out in sourceDir.
+func Generate(ctx context.Context, root *cobra.Command, sourceDir string) (map[string][]byte, error) {
+	comments, err := configcheck.LoadComments(ctx, sourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := map[string][]byte{}
+	var index bytes.Buffer
+	index.WriteString(generatedNotice)
+	fmt.Fprintf(&index, "# %s reference\n\n", root.Name())
+	index.WriteString("## Commands\n\n")
+	for _, c := range commands(root) {
+		pages[filepath.Join(commandsDir, pageName(c))] = commandPage(c)
+		depth := strings.Count(c.CommandPath(), " ")
+		fmt.Fprintf(&index, "%s- [%s](%s/%s) - %s\n", strings.Repeat("  ", depth), c.CommandPath(), commandsDir, pageName(c), c.Short)
+	}
+
+	index.WriteString("\n## Config files\n\n")
+	for _, name := range configcheck.Files() {
+		page, err := configcheck.Reference(name, comments)
+		if err != nil {
+			return nil, err
+		}
+		file := strings.TrimSuffix(name, ".yaml") + ".md"
+		pages[filepath.Join(configDir, file)] = append([]byte(generatedNotice), page...)
+		fmt.Fprintf(&index, "- [.ap/%s](%s/%s)\n", name, configDir, file)
+	}
+	pages["README.md"] = index.Bytes()
+	return pages, nil
+}
+
+// Write writes pages to dir, removing the pages of commands and config files that no longer exist.
+func Write(dir string, pages map[string][]byte) error {
+	for _, sub := range []string{commandsDir, configDir} {
+		stale, err := filepath.Glob(filepath.Join(dir, sub, "*.md"))
+		if err != nil {
+			return err
+		}
+		for _, path := range stale {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if _, ok := pages[rel]; !ok {
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("failed to remove stale page: %w", err)
+				}
+			}
+		}
+	}
+
+	for rel, data := range pages {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// documented returns whether c has a page: hidden commands and those cobra adds do not.
+func documented(c *cobra.Command) bool {
+	return !c.Hidden && c.Name() != "help" && c.Name() != "completion"
+}
+
+// commands returns c and the documented commands under it, depth first.
+func commands(c *cobra.Command) []*cobra.Command {
+	if !documented(c) {
+		return nil
+	}
+	all := []*cobra.Command{c}
+	for _, sub := range c.Commands() {
+		all = append(all, commands(sub)...)
+	}
+	return all
+}
+
+// pageName returns the file name of the page of c, e.g. ap_ci_run.md.
+func pageName(c *cobra.Command) string {
+	return strings.ReplaceAll(c.CommandPath(), " ", "_") + ".md"
+}
+
+// commandPage returns the page documenting command c.
+func commandPage(c *cobra.Command) []byte {
+	var b bytes.Buffer
+	b.WriteString(generatedNotice)
+	fmt.Fprintf(&b, "# %s\n\n", c.CommandPath())
+	if c.Short != "" {
+		fmt.Fprintf(&b, "%s\n\n", c.Short)
+	}
+	if c.Long != "" {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(c.Long))
+	}
+	if c.Runnable() {
+		fmt.Fprintf(&b, "## Usage\n\n```\n%s\n```\n\n", c.UseLine())
+	}
+	if c.Example != "" {
+		fmt.Fprintf(&b, "## Examples\n\n```\n%s\n```\n\n", strings.TrimRight(c.Example, "\n"))
+	}
+
+	if flags := visibleFlags(c.LocalFlags()); len(flags) > 0 {
+		b.WriteString("## Flags\n\n")
+		b.WriteString("| Flag | Type | Default | Description |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, f := range flags {
+			name := "`--" + f.Name + "`"
+			if f.Shorthand != "" {
+				name = "`-" + f.Shorthand + "`, " + name
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", name, f.Value.Type(), defaultValue(f), strings.ReplaceAll(f.Usage, "|", `\|`))
+		}
+		b.WriteString("\n")
+	}
+
+	subcommands := slices.DeleteFunc(slices.Clone(c.Commands()), func(sub *cobra.Command) bool { return !documented(sub) })
+	if len(subcommands) > 0 {
+		b.WriteString("## Subcommands\n\n")
+		for _, sub := range subcommands {
+			fmt.Fprintf(&b, "- [%s](%s) - %s\n", sub.CommandPath(), pageName(sub), sub.Short)
+		}
+		b.WriteString("\n")
+	}
+
+	if parent := c.Parent(); parent != nil {
+		b.WriteString("## See also\n\n")
+		fmt.Fprintf(&b, "- [%s](%s) - %s\n", parent.CommandPath(), pageName(parent), parent.Short)
+		if root := c.Root(); root != parent {
+			fmt.Fprintf(&b, "- [%s](%s) - the flags of all commands\n", root.CommandPath(), pageName(root))
+		}
+		b.WriteString("\n")
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// visibleFlags returns the flags of fs that are not hidden or deprecated, sorted by name.
+func visibleFlags(fs *pflag.FlagSet) []*pflag.Flag {
+	var flags []*pflag.Flag
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden && f.Deprecated == "" {
+			flags = append(flags, f)
+		}
+	})
+	slices.SortFunc(flags, func(a, b *pflag.Flag) int { return strings.Compare(a.Name, b.Name) })
+	return flags
+}
+
+// defaultValue returns the default of flag f as a table cell, or "" if it is the zero value of its type.
+func defaultValue(f *pflag.Flag) string {
+	switch f.DefValue {
+	case "", "false", "0", "[]", "0s":
+		return ""
+	}
+	return "`" + f.DefValue + "`"
+}
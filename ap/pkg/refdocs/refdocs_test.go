@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refdocs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func testCommands() *cobra.Command {
+	root := &cobra.Command{Use: "tool", Short: "Tool does things"}
+	root.PersistentFlags().Bool("dry-run", false, "Show the changes")
+
+	parent := &cobra.Command{Use: "widgets", Short: "Manage widgets"}
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a widget",
+		Long:  "Create a widget with the given name.",
+		RunE:  func(*cobra.Command, []string) error { return nil },
+	}
+	create.Flags().StringP("color", "c", "blue", "Color of the widget, e.g. red|green")
+	create.Flags().Int("count", 0, "How many to create")
+	create.Flags().String("secret", "", "Not shown")
+	_ = create.Flags().MarkHidden("secret")
+	parent.AddCommand(create, &cobra.Command{Use: "internal", Hidden: true, Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(parent)
+	return root
+}
+
+func TestCommandPage(t *testing.T) {
+	root := testCommands()
+	var names []string
+	for _, c := range commands(root) {
+		names = append(names, pageName(c))
+	}
+	if got, want := strings.Join(names, " "), "tool.md tool_widgets.md tool_widgets_create.md"; got != want {
+		t.Errorf("pages = %s, want %s", got, want)
+	}
+
+	create, _, err := root.Find([]string{"widgets", "create"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := generatedNotice + "# tool widgets create" + `
+
+Create a widget
+
+Create a widget with the given name.
+
+## Usage
+
+` + "```\ntool widgets create NAME [flags]\n```" + `
+
+## Flags
+
+| Flag | Type | Default | Description |
+| --- | --- | --- | --- |
+| ` + "`-c`, `--color` | string | `blue`" + ` | Color of the widget, e.g. red\|green |
+| ` + "`--count`" + ` | int |  | How many to create |
+
+## See also
+
+- [tool widgets](tool_widgets.md) - Manage widgets
+- [tool](tool.md) - the flags of all commands
+`
+	if got := string(commandPage(create)); got != want {
+		t.Errorf("commandPage(create) =\n%s\nwant\n%s", got, want)
+	}
+
+	parent, _, err := root.Find([]string{"widgets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(commandPage(parent)); !strings.Contains(got, "## Subcommands\n\n- [tool widgets create](tool_widgets_create.md) - Create a widget\n\n") || strings.Contains(got, "internal") {
+		t.Errorf("commandPage(widgets) does not list exactly the visible subcommands:\n%s", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, commandsDir, "tool_removed.md")
+	kept := filepath.Join(dir, "notes.md")
+	for _, path := range []string{stale, kept} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pages := map[string][]byte{
+		"README.md":                           []byte("index"),
+		filepath.Join(commandsDir, "tool.md"): []byte("tool"),
+	}
+	if err := Write(dir, pages); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("the page of a removed command was not deleted")
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("a file other than a page was deleted: %v", err)
+	}
+	for rel, want := range pages {
+		if got, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(got) != string(want) {
+			t.Errorf("%s = %q, %v; want %q", rel, got, err, want)
+		}
+	}
+}
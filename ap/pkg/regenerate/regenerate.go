@@ -0,0 +1,299 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package regenerate runs ap generate over a repository and, if generated files have drifted,
+// opens a pull request with the regenerated files.
+package regenerate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/google/go-github/v81/github"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultBranch = "ap/regenerate"
+	DefaultBase   = "main"
+	DefaultRemote = "origin"
+
+	title = "Regenerate files with ap generate"
+
+	// The identity of commits made by GitHub Actions, for checkouts without a configured git user.
+	botName  = "github-actions[bot]"
+	botEmail = "41898282+github-actions[bot]@users.noreply.github.com"
+)
+
+// Options configures Run.
+type Options struct {
+	RepoRoot string
+
+	// Owner and Repo name the GitHub repository to open the pull request in.
+	Owner string
+	Repo  string
+
+	// Base is the branch the pull request merges into (defaults to DefaultBase).
+	Base string
+	// Branch is the branch the regenerated files are pushed to (defaults to DefaultBranch).
+	// It is force-pushed, so an open pull request from an earlier run is updated in place.
+	Branch string
+	// Remote is the git remote to push to (defaults to DefaultRemote).
+	Remote string
+
+	// DryRun, if set, makes Run record the regenerated files in the report and restore them,
+	// without committing, pushing or opening a pull request.
+	DryRun *dryrun.Report
+
+	// Out receives the summary of the drift.
+	Out io.Writer
+}
+
+// Generator regenerates the files under repoRoot, calling afterStep with the name of each generator after it runs.
+type Generator func(ctx context.Context, repoRoot string, afterStep func(ctx context.Context, name string) error) error
+
+// PullRequests is the part of the GitHub API used to open or update the pull request.
+type PullRequests interface {
+	List(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
+	Create(ctx context.Context, owner string, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error)
+}
+
+// Drift lists the files changed by each generator, in the order the generators ran.
+type Drift []GeneratorChanges
+
+// GeneratorChanges are the files changed by a generator.
+type GeneratorChanges struct {
+	Generator string
+	Files     []string
+}
+
+// Run regenerates the files in the repository and, if any changed, commits them to a branch
+// and opens (or updates) a pull request for them. The repository must have no uncommitted changes.
+func Run(ctx context.Context, opt Options, generate Generator, pulls PullRequests) error {
+	if opt.Base == "" {
+		opt.Base = DefaultBase
+	}
+	if opt.Branch == "" {
+		opt.Branch = DefaultBranch
+	}
+	if opt.Remote == "" {
+		opt.Remote = DefaultRemote
+	}
+	if opt.Out == nil {
+		opt.Out = os.Stdout
+	}
+
+	changed, err := changedFiles(ctx, opt.RepoRoot)
+	if err != nil {
+		return err
+	}
+	if len(changed) != 0 {
+		return fmt.Errorf("%s has uncommitted changes; regenerate needs a clean checkout", opt.RepoRoot)
+	}
+
+	var drift Drift
+	seen := make(map[string]bool)
+	afterStep := func(ctx context.Context, name string) error {
+		changed, err := changedFiles(ctx, opt.RepoRoot)
+		if err != nil {
+			return err
+		}
+		// A file is attributed to the first generator that changed it.
+		var files []string
+		for _, file := range changed {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+		if len(files) > 0 {
+			drift = append(drift, GeneratorChanges{Generator: name, Files: files})
+		}
+		return nil
+	}
+	if err := generate(ctx, opt.RepoRoot, afterStep); err != nil {
+		return err
+	}
+
+	if len(drift) == 0 {
+		fmt.Fprintln(opt.Out, "Generated files are up to date")
+		return nil
+	}
+	fmt.Fprint(opt.Out, drift.Markdown())
+
+	if opt.DryRun != nil {
+		for _, changes := range drift {
+			for _, file := range changes.Files {
+				opt.DryRun.Addf("regenerate %s (%s)", file, changes.Generator)
+			}
+		}
+		opt.DryRun.Addf("push branch %s and open a pull request against %s in %s/%s", opt.Branch, opt.Base, opt.Owner, opt.Repo)
+		return restore(ctx, opt.RepoRoot)
+	}
+
+	if err := commitAndPush(ctx, opt, drift); err != nil {
+		return err
+	}
+	return openPullRequest(ctx, opt, drift, pulls)
+}
+
+// Markdown returns the drift as a list of files per generator, for the pull request description.
+func (d Drift) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("`ap generate` regenerated files that were out of date:\n\n")
+	for _, changes := range d {
+		fmt.Fprintf(&sb, "- **%s**:", changes.Generator)
+		for _, file := range changes.Files {
+			fmt.Fprintf(&sb, " `%s`", file)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// commitMessage returns the message of the commit with the regenerated files.
+func (d Drift) commitMessage() string {
+	var sb strings.Builder
+	sb.WriteString(title + "\n\n")
+	for _, changes := range d {
+		fmt.Fprintf(&sb, "%s: %s\n", changes.Generator, strings.Join(changes.Files, ", "))
+	}
+	return sb.String()
+}
+
+// commitAndPush commits the regenerated files to opt.Branch and force-pushes it,
+// then switches back to the branch that was checked out.
+func commitAndPush(ctx context.Context, opt Options, drift Drift) error {
+	original, err := git(ctx, opt.RepoRoot, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	if original == "HEAD" {
+		// Detached, as in a GitHub Actions checkout.
+		if original, err = git(ctx, opt.RepoRoot, "rev-parse", "HEAD"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := git(ctx, opt.RepoRoot, "checkout", "-B", opt.Branch); err != nil {
+		return err
+	}
+	if _, err := git(ctx, opt.RepoRoot, "add", "-A"); err != nil {
+		return err
+	}
+	commitArgs := []string{"commit", "-m", drift.commitMessage()}
+	if email, _ := git(ctx, opt.RepoRoot, "config", "user.email"); email == "" {
+		commitArgs = append([]string{"-c", "user.name=" + botName, "-c", "user.email=" + botEmail}, commitArgs...)
+	}
+	if _, err := git(ctx, opt.RepoRoot, commitArgs...); err != nil {
+		return err
+	}
+
+	klog.Infof("Pushing %s to %s", opt.Branch, opt.Remote)
+	if _, err := git(ctx, opt.RepoRoot, "push", "--force", opt.Remote, opt.Branch); err != nil {
+		return err
+	}
+
+	_, err = git(ctx, opt.RepoRoot, "checkout", original)
+	return err
+}
+
+// openPullRequest opens a pull request from opt.Branch, or updates the description of the one that is already open.
+func openPullRequest(ctx context.Context, opt Options, drift Drift, pulls PullRequests) error {
+	body := drift.Markdown()
+
+	existing, _, err := pulls.List(ctx, opt.Owner, opt.Repo, &github.PullRequestListOptions{
+		State: "open",
+		Head:  opt.Owner + ":" + opt.Branch,
+		Base:  opt.Base,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(existing) > 0 {
+		pr := existing[0]
+		if _, _, err := pulls.Edit(ctx, opt.Owner, opt.Repo, pr.GetNumber(), &github.PullRequest{Body: github.Ptr(body)}); err != nil {
+			return fmt.Errorf("failed to update pull request #%d: %w", pr.GetNumber(), err)
+		}
+		fmt.Fprintf(opt.Out, "Updated pull request %s\n", pr.GetHTMLURL())
+		return nil
+	}
+
+	pr, _, err := pulls.Create(ctx, opt.Owner, opt.Repo, &github.NewPullRequest{
+		Title: github.Ptr(title),
+		Head:  github.Ptr(opt.Branch),
+		Base:  github.Ptr(opt.Base),
+		Body:  github.Ptr(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pull request: %w", err)
+	}
+	fmt.Fprintf(opt.Out, "Opened pull request %s\n", pr.GetHTMLURL())
+	return nil
+}
+
+// changedFiles returns the paths of the modified and untracked files in the repository.
+func changedFiles(ctx context.Context, repoRoot string) ([]string, error) {
+	// Not git(), as trimming would drop the status of the first entry.
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = repoRoot
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+	var files []string
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' {
+			// Renames and copies are followed by the original path.
+			i++
+		}
+	}
+	return files, nil
+}
+
+// restore discards the regenerated files, returning the repository to its clean state.
+func restore(ctx context.Context, repoRoot string) error {
+	if _, err := git(ctx, repoRoot, "checkout", "--", "."); err != nil {
+		return err
+	}
+	_, err := git(ctx, repoRoot, "clean", "-fd")
+	return err
+}
+
+// git runs git in dir, returning its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package regenerate
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/google/go-github/v81/github"
+)
+
+type fakePulls struct {
+	open    []*github.PullRequest
+	created []*github.NewPullRequest
+	edited  []*github.PullRequest
+}
+
+func (f *fakePulls) List(_ context.Context, _ string, _ string, _ *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	return f.open, nil, nil
+}
+
+func (f *fakePulls) Create(_ context.Context, _ string, _ string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
+	f.created = append(f.created, pull)
+	return &github.PullRequest{HTMLURL: github.Ptr("https://github.com/example/repo/pull/1")}, nil, nil
+}
+
+func (f *fakePulls) Edit(_ context.Context, _ string, _ string, _ int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error) {
+	f.edited = append(f.edited, pull)
+	return pull, nil, nil
+}
+
+// setupRepo creates a git repository with a committed file and a bare "origin" remote.
+func setupRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "repo")
+	remote := filepath.Join(dir, "remote.git")
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run(dir, "init", "--bare", "-q", remote)
+	run(root, "init", "-q", "-b", "main")
+	run(root, "config", "user.email", "test@example.com")
+	run(root, "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(root, "workflow.yaml"), []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(root, "add", "-A")
+	run(root, "commit", "-q", "-m", "initial")
+	run(root, "remote", "add", "origin", remote)
+	return root
+}
+
+// fakeGenerate updates workflow.yaml in one step and adds a script in the next.
+func fakeGenerate(ctx context.Context, repoRoot string, afterStep func(ctx context.Context, name string) error) error {
+	if err := os.WriteFile(filepath.Join(repoRoot, "workflow.yaml"), []byte("new\n"), 0644); err != nil {
+		return err
+	}
+	if err := afterStep(ctx, "github-actions"); err != nil {
+		return err
+	}
+	if err := afterStep(ctx, "controller-gen"); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(repoRoot, "dev"), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "dev", "ap-lint"), []byte("#!/bin/bash\n"), 0755); err != nil {
+		return err
+	}
+	return afterStep(ctx, "ap-lint")
+}
+
+func TestRunOpensPullRequest(t *testing.T) {
+	root := setupRepo(t)
+	pulls := &fakePulls{}
+	var out bytes.Buffer
+
+	opt := Options{RepoRoot: root, Owner: "example", Repo: "repo", Out: &out}
+	if err := Run(context.Background(), opt, fakeGenerate, pulls); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(pulls.created) != 1 {
+		t.Fatalf("created %d pull requests, want 1", len(pulls.created))
+	}
+	wantBody := "`ap generate` regenerated files that were out of date:\n\n" +
+		"- **github-actions**: `workflow.yaml`\n" +
+		"- **ap-lint**: `dev/ap-lint`\n"
+	if got := pulls.created[0].GetBody(); got != wantBody {
+		t.Errorf("pull request body = %q, want %q", got, wantBody)
+	}
+	if got := pulls.created[0].GetHead(); got != DefaultBranch {
+		t.Errorf("pull request head = %q, want %q", got, DefaultBranch)
+	}
+
+	// The branch was pushed with the regenerated files, and the original branch is checked out again.
+	show, err := git(context.Background(), root, "show", "origin/"+DefaultBranch+":workflow.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if show != "new" {
+		t.Errorf("pushed workflow.yaml = %q, want %q", show, "new")
+	}
+	if head, _ := git(context.Background(), root, "rev-parse", "--abbrev-ref", "HEAD"); head != "main" {
+		t.Errorf("checked out branch = %q, want main", head)
+	}
+}
+
+func TestRunUpdatesOpenPullRequest(t *testing.T) {
+	root := setupRepo(t)
+	pulls := &fakePulls{open: []*github.PullRequest{{Number: github.Ptr(7)}}}
+
+	opt := Options{RepoRoot: root, Owner: "example", Repo: "repo", Out: &bytes.Buffer{}}
+	if err := Run(context.Background(), opt, fakeGenerate, pulls); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(pulls.created) != 0 || len(pulls.edited) != 1 {
+		t.Errorf("created %d and edited %d pull requests, want 0 and 1", len(pulls.created), len(pulls.edited))
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	root := setupRepo(t)
+	report := &dryrun.Report{}
+
+	opt := Options{RepoRoot: root, Owner: "example", Repo: "repo", DryRun: report, Out: &bytes.Buffer{}}
+	if err := Run(context.Background(), opt, fakeGenerate, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []string{
+		"regenerate workflow.yaml (github-actions)",
+		"regenerate dev/ap-lint (ap-lint)",
+		"push branch ap/regenerate and open a pull request against main in example/repo",
+	}
+	if got := report.Changes(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("dry run changes = %q, want %q", got, want)
+	}
+	if changed, err := changedFiles(context.Background(), root); err != nil || len(changed) != 0 {
+		t.Errorf("files left changed after dry run: %v (%v)", changed, err)
+	}
+}
+
+func TestRunNoDrift(t *testing.T) {
+	root := setupRepo(t)
+	var out bytes.Buffer
+
+	opt := Options{RepoRoot: root, Owner: "example", Repo: "repo", Out: &out}
+	noop := func(ctx context.Context, _ string, afterStep func(ctx context.Context, name string) error) error {
+		return afterStep(ctx, "github-actions")
+	}
+	if err := Run(context.Background(), opt, noop, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "up to date") {
+		t.Errorf("output = %q, want up to date", out.String())
+	}
+}
+
+func TestRunRequiresCleanCheckout(t *testing.T) {
+	root := setupRepo(t)
+	if err := os.WriteFile(filepath.Join(root, "workflow.yaml"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opt := Options{RepoRoot: root, Owner: "example", Repo: "repo", Out: &bytes.Buffer{}}
+	if err := Run(context.Background(), opt, fakeGenerate, nil); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("Run() error = %v, want uncommitted changes", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// Bump is the part of a semantic version that a release increments.
+type Bump int
+
+const (
+	BumpPatch Bump = iota
+	BumpMinor
+	BumpMajor
+)
+
+// ParseBump parses "major", "minor" or "patch".
+func ParseBump(s string) (Bump, error) {
+	switch s {
+	case "major":
+		return BumpMajor, nil
+	case "minor":
+		return BumpMinor, nil
+	case "patch":
+		return BumpPatch, nil
+	}
+	return 0, fmt.Errorf("unknown bump %q (must be major, minor or patch)", s)
+}
+
+// Commit is a commit, parsed as a conventional commit (https://www.conventionalcommits.org) where possible.
+type Commit struct {
+	Hash string
+
+	// Type and Scope are from the "type(scope): description" subject; Type is "" for other subjects.
+	Type  string
+	Scope string
+	// Description is the description of a conventional commit, or else the whole subject.
+	Description string
+	// Breaking is set by a "!" after the type or scope, or a "BREAKING CHANGE:" footer.
+	Breaking bool
+}
+
+var (
+	conventionalSubject = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+	breakingFooter      = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+)
+
+// ParseCommit parses the subject and body of a commit.
+func ParseCommit(hash string, subject string, body string) Commit {
+	c := Commit{Hash: hash, Description: subject}
+	if m := conventionalSubject.FindStringSubmatch(subject); m != nil {
+		c.Type = strings.ToLower(m[1])
+		c.Scope = m[2]
+		c.Breaking = m[3] == "!"
+		c.Description = m[4]
+	}
+	if breakingFooter.MatchString(body) {
+		c.Breaking = true
+	}
+	return c
+}
+
+// BumpFor returns the bump that commits call for: major for a breaking change, minor for a feature,
+// and patch otherwise. Before 1.0.0, breaking changes only bump the minor version.
+func BumpFor(current string, commits []Commit) Bump {
+	bump := BumpPatch
+	for _, c := range commits {
+		switch {
+		case c.Breaking:
+			bump = max(bump, BumpMajor)
+		case c.Type == "feat":
+			bump = max(bump, BumpMinor)
+		}
+	}
+	if bump == BumpMajor && semver.Major(current) == "v0" {
+		bump = BumpMinor
+	}
+	return bump
+}
+
+// Next returns the version after current (e.g. "v1.2.3"), or after v0.0.0 if current is "".
+func Next(current string, bump Bump) (string, error) {
+	if current == "" {
+		current = "v0.0.0"
+	}
+	if !semver.IsValid(current) {
+		return "", fmt.Errorf("invalid version %q", current)
+	}
+	var major, minor, patch int
+	if _, err := fmt.Sscanf(semver.Canonical(current), "v%d.%d.%d", &major, &minor, &patch); err != nil {
+		return "", fmt.Errorf("invalid version %q: %w", current, err)
+	}
+	switch bump {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	return fmt.Sprintf("v%d.%d.%d", major, minor, patch), nil
+}
+
+// changelogSections are the sections of the changelog, in order, with the commit types they list.
+var changelogSections = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+}
+
+// unlistedTypes are the types of commits that do not change the released code.
+var unlistedTypes = map[string]bool{"docs": true, "test": true, "ci": true, "chore": true, "style": true, "build": true}
+
+// Changelog returns the changelog section of a release, listing its breaking changes, features, fixes and
+// performance improvements, then any other commits. Documentation, test, CI and chore commits are left out.
+func Changelog(version string, date time.Time, commits []Commit) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s (%s)\n", version, date.Format("2006-01-02"))
+
+	writeSection := func(title string, include func(Commit) bool) {
+		var entries []string
+		for _, c := range commits {
+			if include(c) {
+				entries = append(entries, c.entry())
+			}
+		}
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n", title)
+		for _, entry := range entries {
+			sb.WriteString("- " + entry + "\n")
+		}
+	}
+
+	writeSection("Breaking changes", func(c Commit) bool { return c.Breaking })
+	inSection := make(map[string]bool)
+	for _, section := range changelogSections {
+		writeSection(section.title, func(c Commit) bool { return !c.Breaking && slices.Contains(section.types, c.Type) })
+		for _, t := range section.types {
+			inSection[t] = true
+		}
+	}
+	writeSection("Other changes", func(c Commit) bool { return !c.Breaking && !inSection[c.Type] && !unlistedTypes[c.Type] })
+	return sb.String()
+}
+
+// entry formats the commit as a changelog entry, e.g. "**images:** support ko (abc1234)".
+func (c Commit) entry() string {
+	hash := c.Hash
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	if c.Scope != "" {
+		return fmt.Sprintf("**%s:** %s (%s)", c.Scope, c.Description, hash)
+	}
+	return fmt.Sprintf("%s (%s)", c.Description, hash)
+}
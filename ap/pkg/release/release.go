@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package release tags a release of a repository with the next semantic version, computed from the
+// conventional commits since the last release, and publishes its images and release notes.
+package release
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/google/go-github/v81/github"
+	"golang.org/x/mod/semver"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultRemote = "origin"
+
+	// The identity of tags made by GitHub Actions, for checkouts without a configured git user.
+	botName  = "github-actions[bot]"
+	botEmail = "41898282+github-actions[bot]@users.noreply.github.com"
+)
+
+// Options configures Run.
+type Options struct {
+	RepoRoot string
+
+	// Remote is the git remote the tag is pushed to (defaults to DefaultRemote).
+	Remote string
+
+	// Bump, if set, overrides the bump computed from the commits.
+	Bump *Bump
+
+	// Owner and Repo name the GitHub repository to create a release in.
+	// If they are not set, no GitHub release is created.
+	Owner string
+	Repo  string
+
+	// DryRun, if set, makes Run record the release it would make in the report, without tagging,
+	// building or publishing anything.
+	DryRun *dryrun.Report
+
+	// Out receives the changelog section of the release.
+	Out io.Writer
+}
+
+// Builder builds the repository and pushes its images, tagged with tag.
+// A nil Builder skips the build, e.g. when pushing the tag triggers a workflow that builds the release.
+type Builder func(ctx context.Context, tag string) error
+
+// Releases is the part of the GitHub API used to create the release.
+type Releases interface {
+	CreateRelease(ctx context.Context, owner string, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+}
+
+// Run releases the commits since the latest version tag: it tags HEAD with the next version,
+// builds and pushes the images with that tag (unless build is nil), pushes the tag and, if configured, creates a GitHub release
+// with the changelog as its notes. The repository must have no uncommitted changes.
+func Run(ctx context.Context, opt Options, build Builder, releases Releases) error {
+	if opt.Remote == "" {
+		opt.Remote = DefaultRemote
+	}
+	if opt.Out == nil {
+		opt.Out = os.Stdout
+	}
+
+	status, err := git(ctx, opt.RepoRoot, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return fmt.Errorf("%s has uncommitted changes; a release must be built from a clean checkout", opt.RepoRoot)
+	}
+
+	latest, err := latestVersion(ctx, opt.RepoRoot)
+	if err != nil {
+		return err
+	}
+	commits, err := commitsSince(ctx, opt.RepoRoot, latest)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("there are no commits to release since %s", latest)
+	}
+
+	bump := BumpFor(latest, commits)
+	if opt.Bump != nil {
+		bump = *opt.Bump
+	}
+	version, err := Next(latest, bump)
+	if err != nil {
+		return err
+	}
+	notes := Changelog(version, time.Now(), commits)
+	fmt.Fprint(opt.Out, notes)
+
+	if opt.DryRun != nil {
+		if latest == "" {
+			opt.DryRun.Addf("tag HEAD as %s, the first release", version)
+		} else {
+			opt.DryRun.Addf("tag HEAD as %s, with the %d commits since %s", version, len(commits), latest)
+		}
+		if build != nil {
+			opt.DryRun.Addf("build and push images tagged %s", version)
+		}
+		opt.DryRun.Addf("push tag %s to %s", version, opt.Remote)
+		if opt.Owner != "" {
+			opt.DryRun.Addf("create GitHub release %s in %s/%s", version, opt.Owner, opt.Repo)
+		}
+		return nil
+	}
+
+	klog.Infof("Tagging %s", version)
+	tagArgs := []string{"tag", "-a", version, "-m", notes}
+	if email, _ := git(ctx, opt.RepoRoot, "config", "user.email"); email == "" {
+		tagArgs = append([]string{"-c", "user.name=" + botName, "-c", "user.email=" + botEmail}, tagArgs...)
+	}
+	if _, err := git(ctx, opt.RepoRoot, tagArgs...); err != nil {
+		return err
+	}
+
+	if err := runBuild(ctx, build, version); err != nil {
+		// Nothing has been published under the tag, so it is removed to allow the release to be retried.
+		if _, deleteErr := git(ctx, opt.RepoRoot, "tag", "-d", version); deleteErr != nil {
+			klog.Warningf("failed to delete tag %s: %v", version, deleteErr)
+		}
+		return fmt.Errorf("failed to build release %s: %w", version, err)
+	}
+
+	klog.Infof("Pushing tag %s to %s", version, opt.Remote)
+	if _, err := git(ctx, opt.RepoRoot, "push", opt.Remote, "refs/tags/"+version); err != nil {
+		return err
+	}
+
+	if opt.Owner == "" {
+		return nil
+	}
+	// The heading of the notes is left out, as the version is already the name of the release.
+	_, body, _ := strings.Cut(notes, "\n")
+	created, _, err := releases.CreateRelease(ctx, opt.Owner, opt.Repo, &github.RepositoryRelease{
+		TagName: github.Ptr(version),
+		Name:    github.Ptr(version),
+		Body:    github.Ptr(strings.TrimLeft(body, "\n")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub release %s: %w", version, err)
+	}
+	fmt.Fprintf(opt.Out, "Created release %s\n", created.GetHTMLURL())
+	return nil
+}
+
+// runBuild runs build, if set, for the release tagged version.
+func runBuild(ctx context.Context, build Builder, version string) error {
+	if build == nil {
+		return nil
+	}
+	return build(ctx, version)
+}
+
+// latestVersion returns the highest release version (e.g. "v1.2.3") tagged on the history of HEAD,
+// or "" if there is none. Pre-release versions are ignored.
+func latestVersion(ctx context.Context, repoRoot string) (string, error) {
+	out, err := git(ctx, repoRoot, "tag", "--merged", "HEAD", "--list", "v*")
+	if err != nil {
+		return "", err
+	}
+	latest := ""
+	for _, tag := range strings.Fields(out) {
+		if !semver.IsValid(tag) || semver.Prerelease(tag) != "" || semver.Build(tag) != "" {
+			continue
+		}
+		if latest == "" || semver.Compare(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	return latest, nil
+}
+
+// commitsSince returns the commits on HEAD since the version tag, newest first, or all commits if it is "".
+func commitsSince(ctx context.Context, repoRoot string, version string) ([]Commit, error) {
+	args := []string{"log", "--format=%H%x1f%s%x1f%b%x1e"}
+	if version != "" {
+		args = append(args, version+"..HEAD")
+	}
+	out, err := git(ctx, repoRoot, args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		commits = append(commits, ParseCommit(fields[0], fields[1], fields[2]))
+	}
+	return commits, nil
+}
+
+// git runs git in dir, returning its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/dryrun"
+	"github.com/google/go-github/v81/github"
+)
+
+type fakeReleases struct {
+	created []*github.RepositoryRelease
+}
+
+func (f *fakeReleases) CreateRelease(_ context.Context, _ string, _ string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	f.created = append(f.created, release)
+	return &github.RepositoryRelease{HTMLURL: github.Ptr("https://github.com/example/repo/releases/tag/" + release.GetTagName())}, nil, nil
+}
+
+// setupRepo creates a git repository with a bare "origin" remote, committing each of subjects in turn.
+func setupRepo(t *testing.T, subjects ...string) (root string, remote string) {
+	t.Helper()
+	dir := t.TempDir()
+	root = filepath.Join(dir, "repo")
+	remote = filepath.Join(dir, "remote.git")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "--bare", "-q", remote)
+	runGit(t, root, "init", "-q", "-b", "main")
+	runGit(t, root, "config", "user.email", "test@example.com")
+	runGit(t, root, "config", "user.name", "Test")
+	runGit(t, root, "remote", "add", "origin", remote)
+	commit(t, root, subjects...)
+	return root, remote
+}
+
+func commit(t *testing.T, root string, messages ...string) {
+	t.Helper()
+	for _, message := range messages {
+		runGit(t, root, "commit", "-q", "--allow-empty", "-m", message)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		current string
+		commits []Commit
+		want    string
+	}{
+		{current: "", commits: []Commit{{Type: "fix"}}, want: "v0.0.1"},
+		{current: "v1.2.3", commits: []Commit{{Type: "fix"}, {Type: "docs"}}, want: "v1.2.4"},
+		{current: "v1.2.3", commits: []Commit{{Type: "fix"}, {Type: "feat"}}, want: "v1.3.0"},
+		{current: "v1.2.3", commits: []Commit{{Type: "feat"}, {Type: "fix", Breaking: true}}, want: "v2.0.0"},
+		// Before 1.0.0, breaking changes bump the minor version.
+		{current: "v0.4.1", commits: []Commit{{Type: "feat", Breaking: true}}, want: "v0.5.0"},
+	}
+	for _, tt := range tests {
+		got, err := Next(tt.current, BumpFor(tt.current, tt.commits))
+		if err != nil {
+			t.Fatalf("Next(%q) failed: %v", tt.current, err)
+		}
+		if got != tt.want {
+			t.Errorf("Next(%q, %+v) = %q, want %q", tt.current, tt.commits, got, tt.want)
+		}
+	}
+}
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		subject string
+		body    string
+		want    Commit
+	}{
+		{subject: "feat(images): support ko", want: Commit{Type: "feat", Scope: "images", Description: "support ko"}},
+		{subject: "fix!: drop --legacy", want: Commit{Type: "fix", Description: "drop --legacy", Breaking: true}},
+		{subject: "refactor: split deploy", body: "BREAKING CHANGE: deploy.yaml moved", want: Commit{Type: "refactor", Description: "split deploy", Breaking: true}},
+		{subject: "Update README: typos", want: Commit{Description: "Update README: typos"}},
+	}
+	for _, tt := range tests {
+		if got := ParseCommit("", tt.subject, tt.body); got != tt.want {
+			t.Errorf("ParseCommit(%q) = %+v, want %+v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	commits := []Commit{
+		ParseCommit("1111111aaaa", "feat(deploy)!: apply with server-side apply", ""),
+		ParseCommit("2222222bbbb", "feat: add ap release", ""),
+		ParseCommit("3333333cccc", "fix(lint): report the right line", ""),
+		ParseCommit("4444444dddd", "docs: explain budgets", ""),
+		ParseCommit("5555555eeee", "Bump dependencies", ""),
+	}
+	got := Changelog("v2.0.0", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), commits)
+	want := `## v2.0.0 (2026-03-04)
+
+### Breaking changes
+
+- **deploy:** apply with server-side apply (1111111)
+
+### Features
+
+- add ap release (2222222)
+
+### Bug fixes
+
+- **lint:** report the right line (3333333)
+
+### Other changes
+
+- Bump dependencies (5555555)
+`
+	if got != want {
+		t.Errorf("Changelog() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	root, remote := setupRepo(t, "feat: initial version")
+	runGit(t, root, "tag", "v1.0.0")
+	runGit(t, root, "tag", "v1.1.0-rc.1")
+	commit(t, root, "fix: handle empty config", "feat(build): push with digests")
+
+	var builtTag string
+	build := func(_ context.Context, tag string) error {
+		builtTag = tag
+		return nil
+	}
+	releases := &fakeReleases{}
+	var out bytes.Buffer
+	opt := Options{RepoRoot: root, Owner: "example", Repo: "repo", Out: &out}
+	if err := Run(context.Background(), opt, build, releases); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if builtTag != "v1.1.0" {
+		t.Errorf("built with tag %q, want v1.1.0", builtTag)
+	}
+	if got := runGit(t, remote, "tag", "--list", "v1.1.0"); got != "v1.1.0" {
+		t.Errorf("tag v1.1.0 was not pushed to the remote")
+	}
+	if got := runGit(t, root, "tag", "--list", "--format=%(contents)", "v1.1.0"); !strings.Contains(got, "- **build:** push with digests") {
+		t.Errorf("tag message does not contain the changelog:\n%s", got)
+	}
+	if len(releases.created) != 1 {
+		t.Fatalf("created %d releases, want 1", len(releases.created))
+	}
+	body := releases.created[0].GetBody()
+	if !strings.HasPrefix(body, "### Features\n") || !strings.Contains(body, "- handle empty config (") {
+		t.Errorf("unexpected release notes:\n%s", body)
+	}
+	if !strings.Contains(out.String(), "Created release https://github.com/example/repo/releases/tag/v1.1.0") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	// Nothing new to release.
+	if err := Run(context.Background(), opt, build, releases); err == nil || !strings.Contains(err.Error(), "no commits to release since v1.1.0") {
+		t.Errorf("Run() with nothing to release = %v, want an error", err)
+	}
+}
+
+func TestRunBuildFailureRemovesTag(t *testing.T) {
+	root, remote := setupRepo(t, "fix: first")
+	build := func(context.Context, string) error { return errors.New("push denied") }
+
+	err := Run(context.Background(), Options{RepoRoot: root, Out: &bytes.Buffer{}}, build, nil)
+	if err == nil || !strings.Contains(err.Error(), "push denied") {
+		t.Fatalf("Run() = %v, want the build error", err)
+	}
+	if got := runGit(t, root, "tag", "--list"); got != "" {
+		t.Errorf("tags after a failed build = %q, want none", got)
+	}
+	if got := runGit(t, remote, "tag", "--list"); got != "" {
+		t.Errorf("remote tags after a failed build = %q, want none", got)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	root, remote := setupRepo(t, "feat: first")
+	report := &dryrun.Report{}
+	bump := BumpMajor
+	opt := Options{RepoRoot: root, Bump: &bump, DryRun: report, Out: &bytes.Buffer{}}
+	build := func(context.Context, string) error {
+		t.Errorf("a dry run built the release")
+		return nil
+	}
+	if err := Run(context.Background(), opt, build, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []string{
+		"tag HEAD as v1.0.0, the first release",
+		"build and push images tagged v1.0.0",
+		"push tag v1.0.0 to origin",
+	}
+	if got := report.Changes(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("dry run changes = %q, want %q", got, want)
+	}
+	if got := runGit(t, root, "tag", "--list") + runGit(t, remote, "tag", "--list"); got != "" {
+		t.Errorf("a dry run created tags %q", got)
+	}
+}
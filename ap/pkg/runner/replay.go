@@ -0,0 +1,284 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Invocation is a command that was run, and its outcome.
+type Invocation struct {
+	// Args are the command line, starting with the command. In the invocations given to a Replayer,
+	// a "*" argument matches any argument.
+	Args []string `json:"args"`
+	// Dir is the working directory of the command, if it is not the current directory.
+	Dir string `json:"dir,omitempty"`
+	// Stdin is what the command read from its standard input.
+	Stdin string `json:"stdin,omitempty"`
+
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
+}
+
+// CommandLine returns the command line of the invocation, with its arguments separated by spaces.
+func (i Invocation) CommandLine() string {
+	return strings.Join(i.Args, " ")
+}
+
+// matches reports whether the invocation (a recorded or canned one) is for the command line args.
+func (i Invocation) matches(args []string) bool {
+	if len(i.Args) != len(args) {
+		return false
+	}
+	for n, arg := range i.Args {
+		if arg != "*" && arg != args[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// ExitError is the error of a replayed command that exited with a non-zero code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Replayer is a Runner that replays invocations instead of running commands. Each command is matched with
+// the first unused invocation of the same command line, and writes its output and exits with its code.
+// Commands without an invocation fail, as if they were not installed. All the commands, with their input,
+// are recorded for assertions.
+type Replayer struct {
+	mu          sync.Mutex
+	invocations []Invocation
+	used        []bool
+	calls       []Invocation
+	started     map[*exec.Cmd]error
+}
+
+// NewReplayer returns a Replayer of the given invocations.
+func NewReplayer(invocations ...Invocation) *Replayer {
+	return &Replayer{
+		invocations: invocations,
+		used:        make([]bool, len(invocations)),
+		started:     make(map[*exec.Cmd]error),
+	}
+}
+
+// LoadReplayer returns a Replayer of the invocations saved by Recorder.Save in path.
+func LoadReplayer(path string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var invocations []Invocation
+	if err := json.Unmarshal(data, &invocations); err != nil {
+		return nil, fmt.Errorf("failed to parse invocations in %s: %w", path, err)
+	}
+	return NewReplayer(invocations...), nil
+}
+
+func (r *Replayer) Run(cmd *exec.Cmd) error {
+	if err := r.Start(cmd); err != nil {
+		return err
+	}
+	return r.Wait(cmd)
+}
+
+func (r *Replayer) Start(cmd *exec.Cmd) error {
+	call := Invocation{Args: cmd.Args, Dir: cmd.Dir}
+	if cmd.Stdin != nil {
+		stdin, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return err
+		}
+		call.Stdin = string(stdin)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	for i, inv := range r.invocations {
+		if r.used[i] || !inv.matches(cmd.Args) {
+			continue
+		}
+		r.used[i] = true
+		if err := writeOutput(cmd.Stdout, inv.Stdout); err != nil {
+			return err
+		}
+		if err := writeOutput(cmd.Stderr, inv.Stderr); err != nil {
+			return err
+		}
+		var err error
+		if inv.ExitCode != 0 {
+			err = &ExitError{Code: inv.ExitCode}
+		}
+		r.started[cmd] = err
+		return nil
+	}
+	return fmt.Errorf("%s: no replayed invocation for %q", cmd.Args[0], call.CommandLine())
+}
+
+func (r *Replayer) Wait(cmd *exec.Cmd) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err, ok := r.started[cmd]
+	if !ok {
+		return fmt.Errorf("%s: not started", cmd.Args[0])
+	}
+	delete(r.started, cmd)
+	return err
+}
+
+// LookPath reports every executable as installed, since no command is run.
+func (r *Replayer) LookPath(file string) (string, error) {
+	return file, nil
+}
+
+// Calls returns the commands that were run, in order.
+func (r *Replayer) Calls() []Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Invocation(nil), r.calls...)
+}
+
+// CommandLines returns the command lines of the commands that were run, in order.
+func (r *Replayer) CommandLines() []string {
+	var lines []string
+	for _, call := range r.Calls() {
+		lines = append(lines, call.CommandLine())
+	}
+	return lines
+}
+
+func writeOutput(w io.Writer, output string) error {
+	if w == nil || output == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, output)
+	return err
+}
+
+// Recorder is a Runner that runs commands with another Runner, and records them with their outcome,
+// e.g. to save them as the invocations of a Replayer.
+type Recorder struct {
+	// Runner runs the commands; nil runs them for real.
+	Runner Runner
+
+	mu          sync.Mutex
+	invocations []Invocation
+	started     map[*exec.Cmd]*recording
+}
+
+// recording is the input and output captured from a started command.
+type recording struct {
+	stdin, stdout, stderr bytes.Buffer
+}
+
+func (r *Recorder) runner() Runner {
+	if r.Runner == nil {
+		return Exec{}
+	}
+	return r.Runner
+}
+
+func (r *Recorder) Run(cmd *exec.Cmd) error {
+	if err := r.Start(cmd); err != nil {
+		return err
+	}
+	return r.Wait(cmd)
+}
+
+func (r *Recorder) Start(cmd *exec.Cmd) error {
+	rec := &recording{}
+	if cmd.Stdin != nil {
+		cmd.Stdin = io.TeeReader(cmd.Stdin, &rec.stdin)
+	}
+	cmd.Stdout = teeWriter(cmd.Stdout, &rec.stdout)
+	cmd.Stderr = teeWriter(cmd.Stderr, &rec.stderr)
+	if err := r.runner().Start(cmd); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started == nil {
+		r.started = make(map[*exec.Cmd]*recording)
+	}
+	r.started[cmd] = rec
+	return nil
+}
+
+func (r *Recorder) Wait(cmd *exec.Cmd) error {
+	err := r.runner().Wait(cmd)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.started[cmd]
+	if !ok {
+		return err
+	}
+	delete(r.started, cmd)
+	inv := Invocation{
+		Args:   cmd.Args,
+		Dir:    cmd.Dir,
+		Stdin:  rec.stdin.String(),
+		Stdout: rec.stdout.String(),
+		Stderr: rec.stderr.String(),
+	}
+	if code, ok := ExitCode(err); ok {
+		inv.ExitCode = code
+	}
+	r.invocations = append(r.invocations, inv)
+	return err
+}
+
+func (r *Recorder) LookPath(file string) (string, error) {
+	return r.runner().LookPath(file)
+}
+
+// Invocations returns the commands that were run, in the order they completed.
+func (r *Recorder) Invocations() []Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Invocation(nil), r.invocations...)
+}
+
+// Save writes the recorded invocations to path as JSON, for LoadReplayer.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Invocations(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// teeWriter returns a writer writing to both w (if set) and buf.
+func teeWriter(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
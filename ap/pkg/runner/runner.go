@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runner runs the external commands ap shells out to (docker, kubectl, git, ...).
+// Commands are built with os/exec as usual, and run through the Runner of the context, so that unit tests
+// can record the command lines a package produces and replay canned outputs without the tools installed.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// Runner runs commands.
+type Runner interface {
+	// Run starts cmd and waits for it to complete, like cmd.Run.
+	Run(cmd *exec.Cmd) error
+	// Start starts cmd without waiting for it to complete, like cmd.Start.
+	Start(cmd *exec.Cmd) error
+	// Wait waits for cmd, started with Start, to complete, like cmd.Wait.
+	Wait(cmd *exec.Cmd) error
+	// LookPath searches for an executable named file, like exec.LookPath.
+	LookPath(file string) (string, error)
+}
+
+// Exec runs commands for real.
+type Exec struct{}
+
+func (Exec) Run(cmd *exec.Cmd) error              { return cmd.Run() }
+func (Exec) Start(cmd *exec.Cmd) error            { return cmd.Start() }
+func (Exec) Wait(cmd *exec.Cmd) error             { return cmd.Wait() }
+func (Exec) LookPath(file string) (string, error) { return exec.LookPath(file) }
+
+type contextKey struct{}
+
+// NewContext returns a context in which commands are run with r.
+func NewContext(ctx context.Context, r Runner) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the Runner of ctx, which runs commands for real unless NewContext set another.
+func FromContext(ctx context.Context) Runner {
+	if r, ok := ctx.Value(contextKey{}).(Runner); ok {
+		return r
+	}
+	return Exec{}
+}
+
+// Run runs cmd with the Runner of ctx.
+func Run(ctx context.Context, cmd *exec.Cmd) error {
+	return FromContext(ctx).Run(cmd)
+}
+
+// Start starts cmd with the Runner of ctx; Wait must be called with the same context.
+func Start(ctx context.Context, cmd *exec.Cmd) error {
+	return FromContext(ctx).Start(cmd)
+}
+
+// Wait waits for cmd, started with Start, to complete.
+func Wait(ctx context.Context, cmd *exec.Cmd) error {
+	return FromContext(ctx).Wait(cmd)
+}
+
+// LookPath searches for an executable named file with the Runner of ctx.
+func LookPath(ctx context.Context, file string) (string, error) {
+	return FromContext(ctx).LookPath(file)
+}
+
+// Output runs cmd with the Runner of ctx and returns its standard output, like cmd.Output.
+// Unless cmd.Stderr is set, the standard error is in the returned *exec.ExitError.
+func Output(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil {
+		return nil, errors.New("runner: Stdout already set")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	captureErr := cmd.Stderr == nil
+	if captureErr {
+		cmd.Stderr = &stderr
+	}
+	err := Run(ctx, cmd)
+	var exitErr *exec.ExitError
+	if captureErr && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// ExitCode returns the exit code of a command that ran and failed with err, which is either an
+// *exec.ExitError or a replayed *ExitError.
+func ExitCode(err error) (int, bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+	var replayed *ExitError
+	if errors.As(err, &replayed) {
+		return replayed.Code, true
+	}
+	return 0, false
+}
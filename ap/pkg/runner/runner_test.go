@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplayer(t *testing.T) {
+	r := NewReplayer(
+		Invocation{Args: []string{"kubectl", "get", "namespace", "*"}, Stdout: "namespace/demo\n"},
+		Invocation{Args: []string{"kubectl", "apply", "-f", "-"}, Stderr: "denied\n", ExitCode: 1},
+	)
+	ctx := NewContext(t.Context(), r)
+
+	out, err := Output(ctx, exec.CommandContext(ctx, "kubectl", "get", "namespace", "demo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "namespace/demo\n" {
+		t.Errorf("Output() = %q, want the replayed stdout", out)
+	}
+
+	apply := exec.CommandContext(ctx, "kubectl", "apply", "-f", "-")
+	apply.Stdin = strings.NewReader("kind: Namespace\n")
+	var stderr bytes.Buffer
+	apply.Stderr = &stderr
+	err = Run(ctx, apply)
+	if code, ok := ExitCode(err); !ok || code != 1 {
+		t.Errorf("Run() = %v, want exit code 1", err)
+	}
+	if stderr.String() != "denied\n" {
+		t.Errorf("stderr = %q, want the replayed stderr", stderr.String())
+	}
+
+	// Each invocation is replayed once.
+	if err := Run(ctx, exec.CommandContext(ctx, "kubectl", "get", "namespace", "other")); err == nil {
+		t.Errorf("Run() of a command without an invocation succeeded, want an error")
+	}
+
+	want := []string{"kubectl get namespace demo", "kubectl apply -f -", "kubectl get namespace other"}
+	if got := r.CommandLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CommandLines() = %q, want %q", got, want)
+	}
+	if stdin := r.Calls()[1].Stdin; stdin != "kind: Namespace\n" {
+		t.Errorf("Stdin of apply = %q, want the manifest", stdin)
+	}
+	if _, err := LookPath(ctx, "docker"); err != nil {
+		t.Errorf("LookPath() = %v, want every tool to be available when replaying", err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	rec := &Recorder{}
+	ctx := NewContext(t.Context(), rec)
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", "cat; echo oops >&2; exit 3")
+	cmd.Stdin = strings.NewReader("hello\n")
+	out, err := Output(ctx, cmd)
+	if code, ok := ExitCode(err); !ok || code != 3 {
+		t.Fatalf("Output() = %v, want exit code 3", err)
+	}
+	if string(out) != "hello\n" {
+		t.Errorf("Output() = %q, want %q", out, "hello\n")
+	}
+
+	path := filepath.Join(t.TempDir(), "invocations.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = NewContext(t.Context(), replayer)
+	replayed, err := Output(ctx, exec.CommandContext(ctx, "sh", "-c", "cat; echo oops >&2; exit 3"))
+	if code, ok := ExitCode(err); !ok || code != 3 {
+		t.Fatalf("replayed Output() = %v, want exit code 3", err)
+	}
+	if string(replayed) != "hello\n" {
+		t.Errorf("replayed Output() = %q, want the recorded output", replayed)
+	}
+	got := rec.Invocations()[0]
+	if got.Stdin != "hello\n" || got.Stderr != "oops\n" {
+		t.Errorf("recorded %+v, want its stdin and stderr", got)
+	}
+}
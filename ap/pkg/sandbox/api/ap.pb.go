@@ -361,6 +361,524 @@ func (x *ChangedFile) GetContent() []byte {
 	return nil
 }
 
+type GetInfoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The protocol version of the client.
+	ProtocolVersion int32 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetInfoRequest) Reset() {
+	*x = GetInfoRequest{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRequest) ProtoMessage() {}
+
+func (x *GetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetInfoRequest) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+type GetInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The version of ap serving the sandbox.
+	ApVersion string `protobuf:"bytes,1,opt,name=ap_version,json=apVersion,proto3" json:"ap_version,omitempty"`
+	// The protocol version of the server.
+	ProtocolVersion int32 `protobuf:"varint,2,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// The oldest client protocol version the server accepts.
+	MinProtocolVersion int32 `protobuf:"varint,3,opt,name=min_protocol_version,json=minProtocolVersion,proto3" json:"min_protocol_version,omitempty"`
+	// The ap commands that RunTask can run.
+	Commands []string `protobuf:"bytes,4,rep,name=commands,proto3" json:"commands,omitempty"`
+	// The optional features the server supports.
+	Capabilities  []string `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoResponse) Reset() {
+	*x = GetInfoResponse{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoResponse) ProtoMessage() {}
+
+func (x *GetInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetInfoResponse) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetInfoResponse) GetApVersion() string {
+	if x != nil {
+		return x.ApVersion
+	}
+	return ""
+}
+
+func (x *GetInfoResponse) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *GetInfoResponse) GetMinProtocolVersion() int32 {
+	if x != nil {
+		return x.MinProtocolVersion
+	}
+	return 0
+}
+
+func (x *GetInfoResponse) GetCommands() []string {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{9}
+}
+
+type HealthResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Serving bool                   `protobuf:"varint,1,opt,name=serving,proto3" json:"serving,omitempty"`
+	// Why the sandbox is not serving, if it is not.
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HealthResponse) GetServing() bool {
+	if x != nil {
+		return x.Serving
+	}
+	return false
+}
+
+func (x *HealthResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type CacheEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The key of the entry: the tool and the hash of its inputs.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// The result of the tool, JSON-encoded.
+	Result        []byte `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheEntry) Reset() {
+	*x = CacheEntry{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheEntry) ProtoMessage() {}
+
+func (x *CacheEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheEntry.ProtoReflect.Descriptor instead.
+func (*CacheEntry) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CacheEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CacheEntry) GetResult() []byte {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ListCacheKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCacheKeysRequest) Reset() {
+	*x = ListCacheKeysRequest{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCacheKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCacheKeysRequest) ProtoMessage() {}
+
+func (x *ListCacheKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCacheKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListCacheKeysRequest) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{12}
+}
+
+type ListCacheKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCacheKeysResponse) Reset() {
+	*x = ListCacheKeysResponse{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCacheKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCacheKeysResponse) ProtoMessage() {}
+
+func (x *ListCacheKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCacheKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListCacheKeysResponse) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListCacheKeysResponse) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type GetCacheEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCacheEntriesRequest) Reset() {
+	*x = GetCacheEntriesRequest{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCacheEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheEntriesRequest) ProtoMessage() {}
+
+func (x *GetCacheEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheEntriesRequest.ProtoReflect.Descriptor instead.
+func (*GetCacheEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetCacheEntriesRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type GetCacheEntriesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The entries found; keys that are not in the cache are left out.
+	Entries       []*CacheEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCacheEntriesResponse) Reset() {
+	*x = GetCacheEntriesResponse{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCacheEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheEntriesResponse) ProtoMessage() {}
+
+func (x *GetCacheEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheEntriesResponse.ProtoReflect.Descriptor instead.
+func (*GetCacheEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetCacheEntriesResponse) GetEntries() []*CacheEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type PutCacheEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*CacheEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutCacheEntriesRequest) Reset() {
+	*x = PutCacheEntriesRequest{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutCacheEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCacheEntriesRequest) ProtoMessage() {}
+
+func (x *PutCacheEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCacheEntriesRequest.ProtoReflect.Descriptor instead.
+func (*PutCacheEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PutCacheEntriesRequest) GetEntries() []*CacheEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type PutCacheEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutCacheEntriesResponse) Reset() {
+	*x = PutCacheEntriesResponse{}
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutCacheEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutCacheEntriesResponse) ProtoMessage() {}
+
+func (x *PutCacheEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ap_pkg_sandbox_api_ap_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutCacheEntriesResponse.ProtoReflect.Descriptor instead.
+func (*PutCacheEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP(), []int{17}
+}
+
 var File_ap_pkg_sandbox_api_ap_proto protoreflect.FileDescriptor
 
 const file_ap_pkg_sandbox_api_ap_proto_rawDesc = "" +
@@ -383,11 +901,44 @@ const file_ap_pkg_sandbox_api_ap_proto_rawDesc = "" +
 	"\rchanged_files\x18\x04 \x03(\v2\x1a.ap.sandbox.v1.ChangedFileR\fchangedFiles\";\n" +
 	"\vChangedFile\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\fR\acontent2\xf7\x01\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\";\n" +
+	"\x0eGetInfoRequest\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\x05R\x0fprotocolVersion\"\xcd\x01\n" +
+	"\x0fGetInfoResponse\x12\x1d\n" +
+	"\n" +
+	"ap_version\x18\x01 \x01(\tR\tapVersion\x12)\n" +
+	"\x10protocol_version\x18\x02 \x01(\x05R\x0fprotocolVersion\x120\n" +
+	"\x14min_protocol_version\x18\x03 \x01(\x05R\x12minProtocolVersion\x12\x1a\n" +
+	"\bcommands\x18\x04 \x03(\tR\bcommands\x12\"\n" +
+	"\fcapabilities\x18\x05 \x03(\tR\fcapabilities\"\x0f\n" +
+	"\rHealthRequest\"D\n" +
+	"\x0eHealthResponse\x12\x18\n" +
+	"\aserving\x18\x01 \x01(\bR\aserving\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"6\n" +
+	"\n" +
+	"CacheEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06result\x18\x02 \x01(\fR\x06result\"\x16\n" +
+	"\x14ListCacheKeysRequest\"+\n" +
+	"\x15ListCacheKeysResponse\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\",\n" +
+	"\x16GetCacheEntriesRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"N\n" +
+	"\x17GetCacheEntriesResponse\x123\n" +
+	"\aentries\x18\x01 \x03(\v2\x19.ap.sandbox.v1.CacheEntryR\aentries\"M\n" +
+	"\x16PutCacheEntriesRequest\x123\n" +
+	"\aentries\x18\x01 \x03(\v2\x19.ap.sandbox.v1.CacheEntryR\aentries\"\x19\n" +
+	"\x17PutCacheEntriesResponse2\x88\x03\n" +
 	"\x0eSandboxService\x12N\n" +
 	"\tWriteFile\x12\x1f.ap.sandbox.v1.WriteFileRequest\x1a .ap.sandbox.v1.WriteFileResponse\x12K\n" +
 	"\bReadFile\x12\x1e.ap.sandbox.v1.ReadFileRequest\x1a\x1f.ap.sandbox.v1.ReadFileResponse\x12H\n" +
-	"\aRunTask\x12\x1d.ap.sandbox.v1.RunTaskRequest\x1a\x1e.ap.sandbox.v1.RunTaskResponseB7Z5github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/apib\x06proto3"
+	"\aRunTask\x12\x1d.ap.sandbox.v1.RunTaskRequest\x1a\x1e.ap.sandbox.v1.RunTaskResponse\x12H\n" +
+	"\aGetInfo\x12\x1d.ap.sandbox.v1.GetInfoRequest\x1a\x1e.ap.sandbox.v1.GetInfoResponse\x12E\n" +
+	"\x06Health\x12\x1c.ap.sandbox.v1.HealthRequest\x1a\x1d.ap.sandbox.v1.HealthResponse2\xae\x02\n" +
+	"\fCacheService\x12Z\n" +
+	"\rListCacheKeys\x12#.ap.sandbox.v1.ListCacheKeysRequest\x1a$.ap.sandbox.v1.ListCacheKeysResponse\x12`\n" +
+	"\x0fGetCacheEntries\x12%.ap.sandbox.v1.GetCacheEntriesRequest\x1a&.ap.sandbox.v1.GetCacheEntriesResponse\x12`\n" +
+	"\x0fPutCacheEntries\x12%.ap.sandbox.v1.PutCacheEntriesRequest\x1a&.ap.sandbox.v1.PutCacheEntriesResponseB7Z5github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/apib\x06proto3"
 
 var (
 	file_ap_pkg_sandbox_api_ap_proto_rawDescOnce sync.Once
@@ -401,29 +952,52 @@ func file_ap_pkg_sandbox_api_ap_proto_rawDescGZIP() []byte {
 	return file_ap_pkg_sandbox_api_ap_proto_rawDescData
 }
 
-var file_ap_pkg_sandbox_api_ap_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_ap_pkg_sandbox_api_ap_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_ap_pkg_sandbox_api_ap_proto_goTypes = []any{
-	(*WriteFileRequest)(nil),  // 0: ap.sandbox.v1.WriteFileRequest
-	(*WriteFileResponse)(nil), // 1: ap.sandbox.v1.WriteFileResponse
-	(*ReadFileRequest)(nil),   // 2: ap.sandbox.v1.ReadFileRequest
-	(*ReadFileResponse)(nil),  // 3: ap.sandbox.v1.ReadFileResponse
-	(*RunTaskRequest)(nil),    // 4: ap.sandbox.v1.RunTaskRequest
-	(*RunTaskResponse)(nil),   // 5: ap.sandbox.v1.RunTaskResponse
-	(*ChangedFile)(nil),       // 6: ap.sandbox.v1.ChangedFile
+	(*WriteFileRequest)(nil),        // 0: ap.sandbox.v1.WriteFileRequest
+	(*WriteFileResponse)(nil),       // 1: ap.sandbox.v1.WriteFileResponse
+	(*ReadFileRequest)(nil),         // 2: ap.sandbox.v1.ReadFileRequest
+	(*ReadFileResponse)(nil),        // 3: ap.sandbox.v1.ReadFileResponse
+	(*RunTaskRequest)(nil),          // 4: ap.sandbox.v1.RunTaskRequest
+	(*RunTaskResponse)(nil),         // 5: ap.sandbox.v1.RunTaskResponse
+	(*ChangedFile)(nil),             // 6: ap.sandbox.v1.ChangedFile
+	(*GetInfoRequest)(nil),          // 7: ap.sandbox.v1.GetInfoRequest
+	(*GetInfoResponse)(nil),         // 8: ap.sandbox.v1.GetInfoResponse
+	(*HealthRequest)(nil),           // 9: ap.sandbox.v1.HealthRequest
+	(*HealthResponse)(nil),          // 10: ap.sandbox.v1.HealthResponse
+	(*CacheEntry)(nil),              // 11: ap.sandbox.v1.CacheEntry
+	(*ListCacheKeysRequest)(nil),    // 12: ap.sandbox.v1.ListCacheKeysRequest
+	(*ListCacheKeysResponse)(nil),   // 13: ap.sandbox.v1.ListCacheKeysResponse
+	(*GetCacheEntriesRequest)(nil),  // 14: ap.sandbox.v1.GetCacheEntriesRequest
+	(*GetCacheEntriesResponse)(nil), // 15: ap.sandbox.v1.GetCacheEntriesResponse
+	(*PutCacheEntriesRequest)(nil),  // 16: ap.sandbox.v1.PutCacheEntriesRequest
+	(*PutCacheEntriesResponse)(nil), // 17: ap.sandbox.v1.PutCacheEntriesResponse
 }
 var file_ap_pkg_sandbox_api_ap_proto_depIdxs = []int32{
-	6, // 0: ap.sandbox.v1.RunTaskResponse.changed_files:type_name -> ap.sandbox.v1.ChangedFile
-	0, // 1: ap.sandbox.v1.SandboxService.WriteFile:input_type -> ap.sandbox.v1.WriteFileRequest
-	2, // 2: ap.sandbox.v1.SandboxService.ReadFile:input_type -> ap.sandbox.v1.ReadFileRequest
-	4, // 3: ap.sandbox.v1.SandboxService.RunTask:input_type -> ap.sandbox.v1.RunTaskRequest
-	1, // 4: ap.sandbox.v1.SandboxService.WriteFile:output_type -> ap.sandbox.v1.WriteFileResponse
-	3, // 5: ap.sandbox.v1.SandboxService.ReadFile:output_type -> ap.sandbox.v1.ReadFileResponse
-	5, // 6: ap.sandbox.v1.SandboxService.RunTask:output_type -> ap.sandbox.v1.RunTaskResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	6,  // 0: ap.sandbox.v1.RunTaskResponse.changed_files:type_name -> ap.sandbox.v1.ChangedFile
+	11, // 1: ap.sandbox.v1.GetCacheEntriesResponse.entries:type_name -> ap.sandbox.v1.CacheEntry
+	11, // 2: ap.sandbox.v1.PutCacheEntriesRequest.entries:type_name -> ap.sandbox.v1.CacheEntry
+	0,  // 3: ap.sandbox.v1.SandboxService.WriteFile:input_type -> ap.sandbox.v1.WriteFileRequest
+	2,  // 4: ap.sandbox.v1.SandboxService.ReadFile:input_type -> ap.sandbox.v1.ReadFileRequest
+	4,  // 5: ap.sandbox.v1.SandboxService.RunTask:input_type -> ap.sandbox.v1.RunTaskRequest
+	7,  // 6: ap.sandbox.v1.SandboxService.GetInfo:input_type -> ap.sandbox.v1.GetInfoRequest
+	9,  // 7: ap.sandbox.v1.SandboxService.Health:input_type -> ap.sandbox.v1.HealthRequest
+	12, // 8: ap.sandbox.v1.CacheService.ListCacheKeys:input_type -> ap.sandbox.v1.ListCacheKeysRequest
+	14, // 9: ap.sandbox.v1.CacheService.GetCacheEntries:input_type -> ap.sandbox.v1.GetCacheEntriesRequest
+	16, // 10: ap.sandbox.v1.CacheService.PutCacheEntries:input_type -> ap.sandbox.v1.PutCacheEntriesRequest
+	1,  // 11: ap.sandbox.v1.SandboxService.WriteFile:output_type -> ap.sandbox.v1.WriteFileResponse
+	3,  // 12: ap.sandbox.v1.SandboxService.ReadFile:output_type -> ap.sandbox.v1.ReadFileResponse
+	5,  // 13: ap.sandbox.v1.SandboxService.RunTask:output_type -> ap.sandbox.v1.RunTaskResponse
+	8,  // 14: ap.sandbox.v1.SandboxService.GetInfo:output_type -> ap.sandbox.v1.GetInfoResponse
+	10, // 15: ap.sandbox.v1.SandboxService.Health:output_type -> ap.sandbox.v1.HealthResponse
+	13, // 16: ap.sandbox.v1.CacheService.ListCacheKeys:output_type -> ap.sandbox.v1.ListCacheKeysResponse
+	15, // 17: ap.sandbox.v1.CacheService.GetCacheEntries:output_type -> ap.sandbox.v1.GetCacheEntriesResponse
+	17, // 18: ap.sandbox.v1.CacheService.PutCacheEntries:output_type -> ap.sandbox.v1.PutCacheEntriesResponse
+	11, // [11:19] is the sub-list for method output_type
+	3,  // [3:11] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_ap_pkg_sandbox_api_ap_proto_init() }
@@ -437,9 +1011,9 @@ func file_ap_pkg_sandbox_api_ap_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ap_pkg_sandbox_api_ap_proto_rawDesc), len(file_ap_pkg_sandbox_api_ap_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   18,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_ap_pkg_sandbox_api_ap_proto_goTypes,
 		DependencyIndexes: file_ap_pkg_sandbox_api_ap_proto_depIdxs,
@@ -22,6 +22,8 @@ const (
 	SandboxService_WriteFile_FullMethodName = "/ap.sandbox.v1.SandboxService/WriteFile"
 	SandboxService_ReadFile_FullMethodName  = "/ap.sandbox.v1.SandboxService/ReadFile"
 	SandboxService_RunTask_FullMethodName   = "/ap.sandbox.v1.SandboxService/RunTask"
+	SandboxService_GetInfo_FullMethodName   = "/ap.sandbox.v1.SandboxService/GetInfo"
+	SandboxService_Health_FullMethodName    = "/ap.sandbox.v1.SandboxService/Health"
 )
 
 // SandboxServiceClient is the client API for SandboxService service.
@@ -34,6 +36,10 @@ type SandboxServiceClient interface {
 	ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error)
 	// RunTask runs an ap command in the sandbox.
 	RunTask(ctx context.Context, in *RunTaskRequest, opts ...grpc.CallOption) (*RunTaskResponse, error)
+	// GetInfo returns the version of ap in the sandbox, the protocol versions it speaks, and what it supports.
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	// Health reports whether the sandbox is ready to run tasks.
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 }
 
 type sandboxServiceClient struct {
@@ -74,6 +80,26 @@ func (c *sandboxServiceClient) RunTask(ctx context.Context, in *RunTaskRequest,
 	return out, nil
 }
 
+func (c *sandboxServiceClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInfoResponse)
+	err := c.cc.Invoke(ctx, SandboxService_GetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, SandboxService_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SandboxServiceServer is the server API for SandboxService service.
 // All implementations must embed UnimplementedSandboxServiceServer
 // for forward compatibility.
@@ -84,6 +110,10 @@ type SandboxServiceServer interface {
 	ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error)
 	// RunTask runs an ap command in the sandbox.
 	RunTask(context.Context, *RunTaskRequest) (*RunTaskResponse, error)
+	// GetInfo returns the version of ap in the sandbox, the protocol versions it speaks, and what it supports.
+	GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error)
+	// Health reports whether the sandbox is ready to run tasks.
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	mustEmbedUnimplementedSandboxServiceServer()
 }
 
@@ -103,6 +133,12 @@ func (UnimplementedSandboxServiceServer) ReadFile(context.Context, *ReadFileRequ
 func (UnimplementedSandboxServiceServer) RunTask(context.Context, *RunTaskRequest) (*RunTaskResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RunTask not implemented")
 }
+func (UnimplementedSandboxServiceServer) GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedSandboxServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
+}
 func (UnimplementedSandboxServiceServer) mustEmbedUnimplementedSandboxServiceServer() {}
 func (UnimplementedSandboxServiceServer) testEmbeddedByValue()                        {}
 
@@ -178,6 +214,42 @@ func _SandboxService_RunTask_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SandboxService_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServiceServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SandboxService_GetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServiceServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SandboxService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SandboxService_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SandboxService_ServiceDesc is the grpc.ServiceDesc for SandboxService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -197,6 +269,204 @@ var SandboxService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RunTask",
 			Handler:    _SandboxService_RunTask_Handler,
 		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _SandboxService_GetInfo_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _SandboxService_Health_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ap/pkg/sandbox/api/ap.proto",
+}
+
+const (
+	CacheService_ListCacheKeys_FullMethodName   = "/ap.sandbox.v1.CacheService/ListCacheKeys"
+	CacheService_GetCacheEntries_FullMethodName = "/ap.sandbox.v1.CacheService/GetCacheEntries"
+	CacheService_PutCacheEntries_FullMethodName = "/ap.sandbox.v1.CacheService/PutCacheEntries"
+)
+
+// CacheServiceClient is the client API for CacheService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CacheService exchanges the entries of the ap result cache, which are keyed by the tool and a hash of its
+// inputs, so that results computed on one side of the sandbox are not recomputed on the other.
+type CacheServiceClient interface {
+	// ListCacheKeys returns the keys of the entries in the sandbox cache.
+	ListCacheKeys(ctx context.Context, in *ListCacheKeysRequest, opts ...grpc.CallOption) (*ListCacheKeysResponse, error)
+	// GetCacheEntries returns the entries of the sandbox cache with the given keys.
+	GetCacheEntries(ctx context.Context, in *GetCacheEntriesRequest, opts ...grpc.CallOption) (*GetCacheEntriesResponse, error)
+	// PutCacheEntries adds entries to the sandbox cache.
+	PutCacheEntries(ctx context.Context, in *PutCacheEntriesRequest, opts ...grpc.CallOption) (*PutCacheEntriesResponse, error)
+}
+
+type cacheServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCacheServiceClient(cc grpc.ClientConnInterface) CacheServiceClient {
+	return &cacheServiceClient{cc}
+}
+
+func (c *cacheServiceClient) ListCacheKeys(ctx context.Context, in *ListCacheKeysRequest, opts ...grpc.CallOption) (*ListCacheKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCacheKeysResponse)
+	err := c.cc.Invoke(ctx, CacheService_ListCacheKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) GetCacheEntries(ctx context.Context, in *GetCacheEntriesRequest, opts ...grpc.CallOption) (*GetCacheEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCacheEntriesResponse)
+	err := c.cc.Invoke(ctx, CacheService_GetCacheEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) PutCacheEntries(ctx context.Context, in *PutCacheEntriesRequest, opts ...grpc.CallOption) (*PutCacheEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutCacheEntriesResponse)
+	err := c.cc.Invoke(ctx, CacheService_PutCacheEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CacheServiceServer is the server API for CacheService service.
+// All implementations must embed UnimplementedCacheServiceServer
+// for forward compatibility.
+//
+// CacheService exchanges the entries of the ap result cache, which are keyed by the tool and a hash of its
+// inputs, so that results computed on one side of the sandbox are not recomputed on the other.
+type CacheServiceServer interface {
+	// ListCacheKeys returns the keys of the entries in the sandbox cache.
+	ListCacheKeys(context.Context, *ListCacheKeysRequest) (*ListCacheKeysResponse, error)
+	// GetCacheEntries returns the entries of the sandbox cache with the given keys.
+	GetCacheEntries(context.Context, *GetCacheEntriesRequest) (*GetCacheEntriesResponse, error)
+	// PutCacheEntries adds entries to the sandbox cache.
+	PutCacheEntries(context.Context, *PutCacheEntriesRequest) (*PutCacheEntriesResponse, error)
+	mustEmbedUnimplementedCacheServiceServer()
+}
+
+// UnimplementedCacheServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCacheServiceServer struct{}
+
+func (UnimplementedCacheServiceServer) ListCacheKeys(context.Context, *ListCacheKeysRequest) (*ListCacheKeysResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCacheKeys not implemented")
+}
+func (UnimplementedCacheServiceServer) GetCacheEntries(context.Context, *GetCacheEntriesRequest) (*GetCacheEntriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCacheEntries not implemented")
+}
+func (UnimplementedCacheServiceServer) PutCacheEntries(context.Context, *PutCacheEntriesRequest) (*PutCacheEntriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutCacheEntries not implemented")
+}
+func (UnimplementedCacheServiceServer) mustEmbedUnimplementedCacheServiceServer() {}
+func (UnimplementedCacheServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeCacheServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CacheServiceServer will
+// result in compilation errors.
+type UnsafeCacheServiceServer interface {
+	mustEmbedUnimplementedCacheServiceServer()
+}
+
+func RegisterCacheServiceServer(s grpc.ServiceRegistrar, srv CacheServiceServer) {
+	// If the following call panics, it indicates UnimplementedCacheServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CacheService_ServiceDesc, srv)
+}
+
+func _CacheService_ListCacheKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCacheKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).ListCacheKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_ListCacheKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).ListCacheKeys(ctx, req.(*ListCacheKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_GetCacheEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCacheEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).GetCacheEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_GetCacheEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).GetCacheEntries(ctx, req.(*GetCacheEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_PutCacheEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutCacheEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).PutCacheEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_PutCacheEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).PutCacheEntries(ctx, req.(*PutCacheEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CacheService_ServiceDesc is the grpc.ServiceDesc for CacheService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CacheService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ap.sandbox.v1.CacheService",
+	HandlerType: (*CacheServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCacheKeys",
+			Handler:    _CacheService_ListCacheKeys_Handler,
+		},
+		{
+			MethodName: "GetCacheEntries",
+			Handler:    _CacheService_GetCacheEntries_Handler,
+		},
+		{
+			MethodName: "PutCacheEntries",
+			Handler:    _CacheService_PutCacheEntries_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "ap/pkg/sandbox/api/ap.proto",
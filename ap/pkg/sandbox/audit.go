@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultAuditLogPath is where the sandbox server records the commands it runs.
+// It is outside the served root, so it is not synced back or overwritten by WriteFile.
+const DefaultAuditLogPath = "/var/log/ap-sandbox/audit.jsonl"
+
+// AuditRecord is an entry of the audit log: a command run in the sandbox.
+type AuditRecord struct {
+	Time       time.Time `json:"time"`
+	Command    []string  `json:"command"`
+	Dir        string    `json:"dir"`
+	DurationMs int64     `json:"durationMs"`
+	// ExitCode is -1 if the command could not be started.
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// auditLog appends records to a JSON lines file.
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// record appends r to the log. A nil log records nothing.
+func (l *auditLog) record(r AuditRecord) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadAuditLog parses an audit log.
+func ReadAuditLog(r io.Reader) ([]AuditRecord, error) {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("error in audit log line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// PrintAuditLog prints records as a table.
+func PrintAuditLog(w io.Writer, records []AuditRecord) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tDURATION\tEXIT\tCOMMAND")
+	for _, r := range records {
+		exit := fmt.Sprint(r.ExitCode)
+		if r.Error != "" {
+			exit += " (" + r.Error + ")"
+		}
+		duration := (time.Duration(r.DurationMs) * time.Millisecond).String()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Time.UTC().Format(time.RFC3339), duration, exit, strings.Join(r.Command, " "))
+	}
+	return tw.Flush()
+}
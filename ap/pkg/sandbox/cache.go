@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+)
+
+// cacheBatchSize is the number of entries exchanged per request, to stay below the gRPC message size limit.
+const cacheBatchSize = 500
+
+// cacheServer serves the result cache of the sandbox root, which the ap commands run by RunTask use.
+type cacheServer struct {
+	api.UnimplementedCacheServiceServer
+	root string
+}
+
+func (s *cacheServer) ListCacheKeys(_ context.Context, _ *api.ListCacheKeysRequest) (*api.ListCacheKeysResponse, error) {
+	// The cache is loaded for every request, as the commands run by RunTask update it on disk.
+	cm, err := cache.NewManager(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	return &api.ListCacheKeysResponse{Keys: cm.ResultKeys()}, nil
+}
+
+func (s *cacheServer) GetCacheEntries(_ context.Context, req *api.GetCacheEntriesRequest) (*api.GetCacheEntriesResponse, error) {
+	cm, err := cache.NewManager(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	entries, err := cacheEntries(cm, req.Keys)
+	if err != nil {
+		return nil, err
+	}
+	return &api.GetCacheEntriesResponse{Entries: entries}, nil
+}
+
+func (s *cacheServer) PutCacheEntries(_ context.Context, req *api.PutCacheEntriesRequest) (*api.PutCacheEntriesResponse, error) {
+	cm, err := cache.NewManager(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	if err := addCacheEntries(cm, req.Entries); err != nil {
+		return nil, err
+	}
+	if err := cm.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save cache: %w", err)
+	}
+	return &api.PutCacheEntriesResponse{}, nil
+}
+
+// cacheEntries returns the entries of cm with the given keys, leaving out those it does not have.
+func cacheEntries(cm *cache.Manager, keys []string) ([]*api.CacheEntry, error) {
+	var entries []*api.CacheEntry
+	for _, key := range keys {
+		result, ok := cm.ResultByKey(key)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cache entry %s: %w", key, err)
+		}
+		entries = append(entries, &api.CacheEntry{Key: key, Result: data})
+	}
+	return entries, nil
+}
+
+// addCacheEntries records entries in cm.
+func addCacheEntries(cm *cache.Manager, entries []*api.CacheEntry) error {
+	for _, entry := range entries {
+		var result cache.Result
+		if err := json.Unmarshal(entry.Result, &result); err != nil {
+			return fmt.Errorf("failed to decode cache entry %s: %w", entry.Key, err)
+		}
+		cm.SetResultByKey(entry.Key, &result)
+	}
+	return nil
+}
+
+// missingKeys returns the keys that are not in have, which is sorted.
+func missingKeys(keys []string, have []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if _, found := slices.BinarySearch(have, key); !found {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// pushCache sends the entries of the local cache cm that the sandbox does not have, and returns their number.
+func pushCache(ctx context.Context, client api.CacheServiceClient, cm *cache.Manager) (int, error) {
+	remote, err := client.ListCacheKeys(ctx, &api.ListCacheKeysRequest{})
+	if err != nil {
+		return 0, err
+	}
+	slices.Sort(remote.Keys)
+	missing := missingKeys(cm.ResultKeys(), remote.Keys)
+	for batch := range slices.Chunk(missing, cacheBatchSize) {
+		entries, err := cacheEntries(cm, batch)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := client.PutCacheEntries(ctx, &api.PutCacheEntriesRequest{Entries: entries}); err != nil {
+			return 0, err
+		}
+	}
+	return len(missing), nil
+}
+
+// pullCache adds the entries of the sandbox cache that the local cache cm does not have, and returns their number.
+// The caller saves cm.
+func pullCache(ctx context.Context, client api.CacheServiceClient, cm *cache.Manager) (int, error) {
+	remote, err := client.ListCacheKeys(ctx, &api.ListCacheKeysRequest{})
+	if err != nil {
+		return 0, err
+	}
+	missing := missingKeys(remote.Keys, cm.ResultKeys())
+	for batch := range slices.Chunk(missing, cacheBatchSize) {
+		resp, err := client.GetCacheEntries(ctx, &api.GetCacheEntriesRequest{Keys: batch})
+		if err != nil {
+			return 0, err
+		}
+		if err := addCacheEntries(cm, resp.Entries); err != nil {
+			return 0, err
+		}
+	}
+	return len(missing), nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialCache serves the cache of root in-process and returns a client connected to it.
+func dialCache(t *testing.T, root string) api.CacheServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	api.RegisterCacheServiceServer(s, &cacheServer{root: root})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return api.NewCacheServiceClient(conn)
+}
+
+func TestCacheExchange(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	sandboxRoot, localRoot := t.TempDir(), t.TempDir()
+	client := dialCache(t, sandboxRoot)
+
+	local, err := cache.NewManager(localRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local.MarkDone("gofmt", "aaa", nil)
+	local.MarkDone("kubelint", "bbb", &cache.Result{Findings: []findings.Finding{{Path: "deploy.yaml", Line: 3, Message: "no limits"}}})
+
+	n, err := pushCache(t.Context(), client, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("pushCache() copied %d entries, want 2", n)
+	}
+	// The sandbox already has them.
+	if n, err := pushCache(t.Context(), client, local); err != nil || n != 0 {
+		t.Errorf("second pushCache() = %d, %v, want 0 entries", n, err)
+	}
+
+	// A command run in the sandbox records a result, and the client copies it back.
+	sandbox, err := cache.NewManager(sandboxRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, ok := sandbox.Done("kubelint", "bbb"); !ok || len(result.Findings) != 1 || result.Findings[0].Message != "no limits" {
+		t.Errorf("sandbox cache has kubelint result %+v (%v), want the pushed finding", result, ok)
+	}
+	sandbox.MarkDone("goimports", "ccc", nil)
+	if err := sandbox.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err = pullCache(t.Context(), client, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("pullCache() copied %d entries, want 1", n)
+	}
+	if _, ok := local.Done("goimports", "ccc"); !ok {
+		t.Errorf("local cache does not have the result computed in the sandbox")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+)
+
+// podReadyTimeout is how long to wait for a new sandbox pod to become ready.
+const podReadyTimeout = 60 * time.Second
+
+// podPollInterval is how often the sandbox pod is checked while waiting for it to become ready.
+var podPollInterval = time.Second
+
+// ensurePod creates the sandbox pod running image if it does not exist, and waits for it to be ready.
+func ensurePod(ctx context.Context, client *kubeclient.Client, image string) error {
+	pods := client.CoreV1().Pods(client.Namespace)
+	err := kubeclient.Retry(ctx, func() error {
+		_, err := pods.Get(ctx, PodName, metav1.GetOptions{})
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get sandbox pod: %w", err)
+	}
+
+	klog.Infof("Creating pod %s...", PodName)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   PodName,
+			Labels: map[string]string{"run": PodName},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:  PodName,
+				Image: image,
+				Args:  []string{"serve"},
+			}},
+		},
+	}
+	err = kubeclient.Retry(ctx, func() error {
+		_, err := pods.Create(ctx, pod, metav1.CreateOptions{FieldManager: kubeclient.FieldManager})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox pod: %w", err)
+	}
+
+	klog.Infof("Waiting for pod %s to be ready...", PodName)
+	err = wait.PollUntilContextTimeout(ctx, podPollInterval, podReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		pod, err := pods.Get(ctx, PodName, metav1.GetOptions{})
+		if err != nil {
+			if kubeclient.IsTransient(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("pod exited with phase %s", pod.Status.Phase)
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("pod did not become ready: %w", err)
+	}
+	return nil
+}
+
+// portForward forwards localhost:port to the same port of the sandbox pod, until the returned stop function is called.
+func portForward(ctx context.Context, client *kubeclient.Client, port int) (func(), error) {
+	if client.Config == nil {
+		return nil, fmt.Errorf("port-forwarding needs a connection to a cluster")
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(client.Config)
+	if err != nil {
+		return nil, err
+	}
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(client.Namespace).Name(PodName).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", port, port)}, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-readyCh:
+		return func() { close(stopCh) }, nil
+	case err := <-errCh:
+		return nil, err
+	case <-ctx.Done():
+		close(stopCh)
+		return nil, ctx.Err()
+	}
+}
+
+// podLogs copies the logs of the sandbox pod to out.
+func podLogs(ctx context.Context, client *kubeclient.Client, out io.Writer) error {
+	stream, err := client.CoreV1().Pods(client.Namespace).GetLogs(PodName, &corev1.PodLogOptions{}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = io.Copy(out, stream)
+	return err
+}
+
+// podExec runs command in the sandbox pod, returning its stdout. Its stderr is copied to os.Stderr.
+func podExec(ctx context.Context, client *kubeclient.Client, command ...string) ([]byte, error) {
+	if client.Config == nil {
+		return nil, fmt.Errorf("exec needs a connection to a cluster")
+	}
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(client.Namespace).Name(PodName).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{Command: command, Stdout: true, Stderr: true}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(client.Config, http.MethodPost, req.URL())
+	if err != nil {
+		return nil, err
+	}
+	var stdout bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: os.Stderr}); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
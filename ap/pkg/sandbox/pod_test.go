@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestEnsurePod(t *testing.T) {
+	podPollInterval = time.Millisecond
+	t.Cleanup(func() { podPollInterval = time.Second })
+
+	t.Run("exists", func(t *testing.T) {
+		clientset := fake.NewClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: PodName, Namespace: "dev"}})
+		if err := ensurePod(t.Context(), &kubeclient.Client{Interface: clientset, Namespace: "dev"}, "ap:test"); err != nil {
+			t.Fatal(err)
+		}
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "create" {
+				t.Errorf("unexpected %s of an existing pod", action.GetVerb())
+			}
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		clientset := fake.NewClientset()
+		// The fake clientset does not run pods, so mark the pod ready as it is created.
+		clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+			return false, nil, nil
+		})
+		if err := ensurePod(t.Context(), &kubeclient.Client{Interface: clientset, Namespace: "dev"}, "ap:test"); err != nil {
+			t.Fatal(err)
+		}
+		pod, err := clientset.CoreV1().Pods("dev").Get(t.Context(), PodName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := pod.Spec.Containers; len(c) != 1 || c[0].Image != "ap:test" || len(c[0].Args) != 1 || c[0].Args[0] != "serve" {
+			t.Errorf("created pod containers = %+v, want ap:test running serve", c)
+		}
+		if pod.Spec.RestartPolicy != corev1.RestartPolicyNever {
+			t.Errorf("created pod restart policy = %q, want Never", pod.Spec.RestartPolicy)
+		}
+	})
+}
+
+func TestPodLogs(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: PodName, Namespace: "dev"}})
+	var out bytes.Buffer
+	if err := podLogs(t.Context(), &kubeclient.Client{Interface: clientset, Namespace: "dev"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	// The fake clientset returns canned logs.
+	if out.String() != "fake logs" {
+		t.Errorf("podLogs() = %q, want the logs of the pod", out.String())
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/version"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProtocolVersion is the version of the sandbox protocol spoken by this ap. It is increased whenever a change
+// would make an older client or server misbehave, rather than fail, such as a change in how files are synced.
+// Version 1 had no GetInfo or Health RPCs.
+const ProtocolVersion = 2
+
+// MinProtocolVersion is the oldest client protocol version the server accepts.
+const MinProtocolVersion = 2
+
+// Capabilities are the optional features of a server, which clients check before relying on them.
+const (
+	// CapabilitySync is writing and reading files with WriteFile and ReadFile.
+	CapabilitySync = "sync"
+	// CapabilityRunTask is running ap commands with RunTask.
+	CapabilityRunTask = "run-task"
+	// CapabilityChangedFiles is returning the test results and formatted files of RunTask.
+	CapabilityChangedFiles = "changed-files"
+	// CapabilityAuditLog is recording the commands run by RunTask in the audit log.
+	CapabilityAuditLog = "audit-log"
+	// CapabilityCache is exchanging result cache entries with the CacheService.
+	CapabilityCache = "cache"
+)
+
+// requiredCapabilities are the capabilities Run needs before syncing anything.
+var requiredCapabilities = []string{CapabilitySync, CapabilityRunTask}
+
+func (s *server) GetInfo(_ context.Context, req *api.GetInfoRequest) (*api.GetInfoResponse, error) {
+	if req.ProtocolVersion < MinProtocolVersion {
+		return nil, status.Errorf(codes.FailedPrecondition, "client protocol version %d is older than the oldest supported by this server (%d); update ap", req.ProtocolVersion, MinProtocolVersion)
+	}
+	capabilities := []string{CapabilitySync, CapabilityRunTask, CapabilityChangedFiles, CapabilityCache}
+	if s.audit != nil {
+		capabilities = append(capabilities, CapabilityAuditLog)
+	}
+	return &api.GetInfoResponse{
+		ApVersion:          apVersion(),
+		ProtocolVersion:    ProtocolVersion,
+		MinProtocolVersion: MinProtocolVersion,
+		Commands:           s.commands,
+		Capabilities:       capabilities,
+	}, nil
+}
+
+func (s *server) Health(_ context.Context, _ *api.HealthRequest) (*api.HealthResponse, error) {
+	if info, err := os.Stat(s.root); err != nil || !info.IsDir() {
+		return &api.HealthResponse{Message: fmt.Sprintf("root %s is not a directory", s.root)}, nil
+	}
+	// RunTask runs ap from the PATH.
+	if _, err := exec.LookPath("ap"); err != nil {
+		return &api.HealthResponse{Message: "ap is not in the PATH of the server"}, nil
+	}
+	return &api.HealthResponse{Serving: true}, nil
+}
+
+// apVersion returns the version of the running ap, or its git SHA for development builds.
+func apVersion() string {
+	info := version.Get()
+	switch {
+	case info.Version != "":
+		return info.Version
+	case info.GitSHA != "":
+		return info.GitSHA
+	}
+	return "unknown"
+}
+
+// negotiate checks that the server is healthy, speaks a compatible protocol, supports the capabilities
+// Run needs, and can run the ap command args, before anything is synced.
+func negotiate(ctx context.Context, client api.SandboxServiceClient, args []string) (*api.GetInfoResponse, error) {
+	health, err := client.Health(ctx, &api.HealthRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("the ap serving the sandbox predates protocol version %d; delete the pod (kubectl delete pod %s) so that it is recreated with a current image", ProtocolVersion, PodName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the health of the sandbox: %w", err)
+	}
+	if !health.Serving {
+		return nil, fmt.Errorf("the sandbox is not serving: %s", health.Message)
+	}
+
+	info, err := client.GetInfo(ctx, &api.GetInfoRequest{ProtocolVersion: ProtocolVersion})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the sandbox info: %w", err)
+	}
+	if err := checkCompatible(info, args); err != nil {
+		return nil, fmt.Errorf("the sandbox (ap %s) is incompatible with this ap (%s): %w", info.ApVersion, apVersion(), err)
+	}
+	return info, nil
+}
+
+// checkCompatible returns an error if a server with info cannot run the ap command args for this client.
+func checkCompatible(info *api.GetInfoResponse, args []string) error {
+	if ProtocolVersion < info.MinProtocolVersion || ProtocolVersion > info.ProtocolVersion {
+		return fmt.Errorf("the server speaks protocol versions %d to %d, and this client %d", info.MinProtocolVersion, info.ProtocolVersion, ProtocolVersion)
+	}
+	var missing []string
+	for _, c := range requiredCapabilities {
+		if !slices.Contains(info.Capabilities, c) {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the server does not support %s", strings.Join(missing, ", "))
+	}
+	if command := commandName(args); command != "" && !slices.Contains(info.Commands, command) {
+		return fmt.Errorf("the server cannot run ap %s (it supports %s)", command, strings.Join(info.Commands, ", "))
+	}
+	return nil
+}
+
+// commandName returns the ap command args runs, or "" if it cannot tell, e.g. because args starts with flags.
+func commandName(args []string) string {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return ""
+	}
+	return args[0]
+}
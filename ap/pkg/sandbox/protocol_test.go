@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sandbox
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// oldServer is a server from before protocol negotiation.
+type oldServer struct {
+	api.UnimplementedSandboxServiceServer
+}
+
+// incompatibleServer only accepts newer clients.
+type incompatibleServer struct {
+	*server
+}
+
+func (s incompatibleServer) GetInfo(ctx context.Context, req *api.GetInfoRequest) (*api.GetInfoResponse, error) {
+	info, err := s.server.GetInfo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	info.ProtocolVersion, info.MinProtocolVersion = ProtocolVersion+2, ProtocolVersion+1
+	return info, nil
+}
+
+// dial serves srv in-process and returns a client connected to it.
+func dial(t *testing.T, srv api.SandboxServiceServer) api.SandboxServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	api.RegisterSandboxServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return api.NewSandboxServiceClient(conn)
+}
+
+func TestNegotiate(t *testing.T) {
+	// A fake ap, so that the server is healthy.
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "ap"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	healthy := &server{root: root, commands: []string{"format", "lint", "test"}}
+
+	info, err := negotiate(t.Context(), dial(t, healthy), []string{"test", "./..."})
+	if err != nil {
+		t.Fatalf("negotiate() error = %v", err)
+	}
+	if info.ProtocolVersion != ProtocolVersion || info.ApVersion == "" {
+		t.Errorf("negotiate() = %v, want protocol version %d and an ap version", info, ProtocolVersion)
+	}
+
+	tests := []struct {
+		name    string
+		srv     api.SandboxServiceServer
+		args    []string
+		wantErr string
+	}{
+		{"flags first", healthy, []string{"--v=2", "deploy"}, ""},
+		{"unsupported command", healthy, []string{"deploy"}, "cannot run ap deploy"},
+		{"old server", oldServer{}, []string{"test"}, "predates protocol version"},
+		{"incompatible server", incompatibleServer{healthy}, []string{"test"}, "speaks protocol versions"},
+		{"missing root", &server{root: filepath.Join(root, "missing")}, []string{"test"}, "not serving"},
+	}
+	for _, tt := range tests {
+		_, err := negotiate(t.Context(), dial(t, tt.srv), tt.args)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: negotiate() error = %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: negotiate() error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// The server rejects clients that are too old.
+	if _, err := healthy.GetInfo(t.Context(), &api.GetInfoRequest{ProtocolVersion: 1}); err == nil {
+		t.Errorf("GetInfo() from a protocol version 1 client succeeded, want an error")
+	}
+}
@@ -15,67 +15,53 @@
 package sandbox
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/kubeclient"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"k8s.io/klog/v2"
 )
 
+// PodName is the name of the sandbox pod.
+const PodName = "ap-sandbox"
+
 // Run runs the ap command in a sandbox pod.
 func Run(ctx context.Context, root string, args []string) error {
-	podName := "ap-sandbox"
+	podName := PodName
 	image := "local/ap-golang:latest"
 
 	klog.Infof("Ensuring sandbox pod %s is running...", podName)
 
-	// Check if pod exists
-	checkCmd := exec.CommandContext(ctx, "kubectl", "get", "pod", podName, "--no-headers")
-	if err := checkCmd.Run(); err != nil {
-		// Pod doesn't exist, create it
-		klog.Infof("Creating pod %s...", podName)
-		runCmd := exec.CommandContext(ctx, "kubectl", "run", podName,
-			"--image="+image,
-			"--restart=Never",
-			"--", "serve")
-		if err := runCmd.Run(); err != nil {
-			return fmt.Errorf("failed to create sandbox pod: %w", err)
-		}
-
-		// Wait for pod to be ready
-		klog.Infof("Waiting for pod %s to be ready...", podName)
-		waitCmd := exec.CommandContext(ctx, "kubectl", "wait", "--for=condition=Ready", "pod/"+podName, "--timeout=60s")
-		if err := waitCmd.Run(); err != nil {
-			return fmt.Errorf("pod did not become ready: %w", err)
-		}
+	kube, err := kubeclient.New(ctx, kubeclient.Options{})
+	if err != nil {
+		return err
+	}
+	if err := ensurePod(ctx, kube, image); err != nil {
+		return err
 	}
 
 	// Port forward
 	klog.Infof("Setting up port-forward...")
 	localPort := 50051
-	pfCmd := exec.CommandContext(ctx, "kubectl", "port-forward", "pod/"+podName, fmt.Sprintf("%d:%d", localPort, localPort))
-	// Redirect pf output to avoid noise
-	pfCmd.Stdout = nil
-	pfCmd.Stderr = nil
-	if err := pfCmd.Start(); err != nil {
+	stopPortForward, err := portForward(ctx, kube, localPort)
+	if err != nil {
 		return fmt.Errorf("failed to start port-forward: %w", err)
 	}
-	defer func() {
-		if pfCmd.Process != nil {
-			pfCmd.Process.Kill()
-		}
-	}()
+	defer stopPortForward()
 
 	// Wait for port-forward to be ready by trying to connect
 	var conn *grpc.ClientConn
-	var err error
 	for i := 0; i < 10; i++ {
 		conn, err = grpc.Dial(fmt.Sprintf("localhost:%d", localPort), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock(), grpc.WithTimeout(1*time.Second))
 		if err == nil {
@@ -89,6 +75,13 @@ func Run(ctx context.Context, root string, args []string) error {
 	defer conn.Close()
 	client := api.NewSandboxServiceClient(conn)
 
+	// Check that the server can run the command before spending time on syncing.
+	info, err := negotiate(ctx, client, args)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Connected to sandbox running ap %s (protocol version %d)", info.ApVersion, info.ProtocolVersion)
+
 	// Copy code using gRPC
 	klog.Infof("Copying code to sandbox using gRPC...")
 	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -122,6 +115,20 @@ func Run(ctx context.Context, root string, args []string) error {
 		return fmt.Errorf("failed to sync code to sandbox: %w", err)
 	}
 
+	// Share the result cache with the sandbox, so that neither side recomputes results the other already has.
+	var cm *cache.Manager
+	cacheClient := api.NewCacheServiceClient(conn)
+	if slices.Contains(info.Capabilities, CapabilityCache) {
+		cm, err = cache.NewManager(root)
+		if err != nil {
+			klog.Warningf("Failed to open the local cache, not sharing it with the sandbox: %v", err)
+		} else if n, err := pushCache(ctx, cacheClient, cm); err != nil {
+			klog.Warningf("Failed to copy cache entries to the sandbox: %v", err)
+		} else {
+			klog.Infof("Copied %d cache entries to the sandbox", n)
+		}
+	}
+
 	// Run the task
 	klog.Infof("Executing task: ap %s", strings.Join(args, " "))
 	resp, err := client.RunTask(ctx, &api.RunTaskRequest{
@@ -148,9 +155,52 @@ func Run(ctx context.Context, root string, args []string) error {
 		}
 	}
 
+	if cm != nil {
+		if n, err := pullCache(ctx, cacheClient, cm); err != nil {
+			klog.Warningf("Failed to copy cache entries from the sandbox: %v", err)
+		} else if n > 0 {
+			klog.Infof("Copied %d cache entries from the sandbox", n)
+			if err := cm.Save(); err != nil {
+				klog.Warningf("Failed to save cache: %v", err)
+			}
+		}
+	}
+
 	if resp.ExitCode != 0 {
 		return fmt.Errorf("sandbox command failed with exit code %d", resp.ExitCode)
 	}
 
 	return nil
 }
+
+// LogsOptions configures Logs.
+type LogsOptions struct {
+	// Audit prints the audit log of the commands run in the sandbox, instead of the server's logs.
+	Audit bool
+
+	Out io.Writer
+}
+
+// Logs prints the logs of the sandbox pod.
+func Logs(ctx context.Context, opt LogsOptions) error {
+	client, err := kubeclient.New(ctx, kubeclient.Options{})
+	if err != nil {
+		return err
+	}
+	if !opt.Audit {
+		if err := podLogs(ctx, client, opt.Out); err != nil {
+			return fmt.Errorf("failed to get logs of %s: %w", PodName, err)
+		}
+		return nil
+	}
+
+	out, err := podExec(ctx, client, "cat", DefaultAuditLogPath)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log of %s: %w", PodName, err)
+	}
+	records, err := ReadAuditLog(bytes.NewReader(out))
+	if err != nil {
+		return err
+	}
+	return PrintAuditLog(opt.Out, records)
+}
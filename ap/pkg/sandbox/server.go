@@ -25,6 +25,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/execprofile"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
 	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
@@ -33,6 +35,12 @@ import (
 type server struct {
 	api.UnimplementedSandboxServiceServer
 	root string
+
+	// audit records the commands run by RunTask; nil disables auditing.
+	audit *auditLog
+
+	// commands are the ap commands RunTask can run, reported by GetInfo.
+	commands []string
 }
 
 func (s *server) WriteFile(_ context.Context, req *api.WriteFileRequest) (*api.WriteFileResponse, error) {
@@ -63,21 +71,38 @@ func (s *server) RunTask(ctx context.Context, req *api.RunTaskRequest) (*api.Run
 	// We assume 'ap' is in the PATH in the sandbox pod.
 	cmd := exec.CommandContext(ctx, "ap", req.Args...)
 	cmd.Dir = s.root
-	cmd.Env = append(os.Environ(), "AP_ROOT="+s.root)
+	// The commands are run for an agent, whatever the profile of the server.
+	cmd.Env = append(os.Environ(), "AP_ROOT="+s.root, execprofile.Env+"="+execprofile.Agent)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err := runner.Run(ctx, cmd)
+	record := AuditRecord{
+		Time:       startTime,
+		Command:    cmd.Args,
+		Dir:        cmd.Dir,
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
 	exitCode := 0
 	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
+		if code, ok := runner.ExitCode(err); ok {
+			exitCode = code
 		} else {
+			record.ExitCode = -1
+			record.Error = err.Error()
+			if auditErr := s.audit.record(record); auditErr != nil {
+				klog.Errorf("Failed to record command in audit log: %v", auditErr)
+			}
 			return nil, fmt.Errorf("failed to run ap: %w", err)
 		}
 	}
+	record.ExitCode = exitCode
+	// Fail the request if the command could not be recorded, so that unaudited commands do not go unnoticed.
+	if err := s.audit.record(record); err != nil {
+		return nil, err
+	}
 
 	resp := &api.RunTaskResponse{
 		ExitCode: int32(exitCode),
@@ -129,17 +154,35 @@ func (s *server) RunTask(ctx context.Context, req *api.RunTaskRequest) (*api.Run
 	return resp, nil
 }
 
+// ServeOptions configures Serve.
+type ServeOptions struct {
+	// Root is the directory files are synced to and commands run in.
+	Root string
+	Port int
+
+	// AuditLogPath is the file every command run by the server is recorded in; empty disables the audit log.
+	AuditLogPath string
+
+	// Commands are the ap commands the server can run, reported to clients by GetInfo.
+	Commands []string
+}
+
 // Serve starts the gRPC server.
-func Serve(ctx context.Context, root string, port int) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+func Serve(ctx context.Context, opt ServeOptions) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", opt.Port))
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
 
 	s := grpc.NewServer()
-	api.RegisterSandboxServiceServer(s, &server{root: root})
+	srv := &server{root: opt.Root, commands: opt.Commands}
+	if opt.AuditLogPath != "" {
+		srv.audit = &auditLog{path: opt.AuditLogPath}
+	}
+	api.RegisterSandboxServiceServer(s, srv)
+	api.RegisterCacheServiceServer(s, &cacheServer{root: opt.Root})
 
-	klog.Infof("Sandbox server listening on %v", lis.Addr())
+	klog.Infof("Sandbox server (ap %s, protocol version %d) listening on %v", apVersion(), ProtocolVersion, lis.Addr())
 
 	go func() {
 		<-ctx.Done()
@@ -15,10 +15,13 @@
 package sandbox
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gke-labs/gke-labs-infra/ap/pkg/sandbox/api"
 )
@@ -65,3 +68,69 @@ func TestServerWriteRead(t *testing.T) {
 		t.Errorf("Content mismatch from ReadFile: got %q, want %q", string(resp.Content), string(testContent))
 	}
 }
+
+func TestRunTaskAudit(t *testing.T) {
+	// A fake ap, which fails for "lint".
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$1\" = lint ]; then exit 3; fi\necho ok\n"
+	if err := os.WriteFile(filepath.Join(binDir, "ap"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	auditPath := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	s := &server{root: root, audit: &auditLog{path: auditPath}}
+	ctx := context.Background()
+
+	if _, err := s.RunTask(ctx, &api.RunTaskRequest{Args: []string{"test", "./..."}}); err != nil {
+		t.Fatalf("RunTask failed: %v", err)
+	}
+	resp, err := s.RunTask(ctx, &api.RunTaskRequest{Args: []string{"lint"}})
+	if err != nil {
+		t.Fatalf("RunTask failed: %v", err)
+	}
+	if resp.ExitCode != 3 {
+		t.Errorf("RunTask exit code = %d, want 3", resp.ExitCode)
+	}
+
+	f, err := os.Open(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := ReadAuditLog(f)
+	if err != nil {
+		t.Fatalf("ReadAuditLog failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("audit log has %d records, want 2", len(records))
+	}
+	if got := strings.Join(records[0].Command, " "); got != "ap test ./..." {
+		t.Errorf("first command = %q, want %q", got, "ap test ./...")
+	}
+	if records[0].ExitCode != 0 || records[1].ExitCode != 3 {
+		t.Errorf("exit codes = %d, %d, want 0, 3", records[0].ExitCode, records[1].ExitCode)
+	}
+	if records[1].Dir != root || records[1].Time.IsZero() {
+		t.Errorf("second record = %+v, want dir %s and a time", records[1], root)
+	}
+}
+
+func TestPrintAuditLog(t *testing.T) {
+	records := []AuditRecord{
+		{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Command: []string{"ap", "test"}, DurationMs: 1500, ExitCode: 1},
+		{Time: time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC), Command: []string{"ap", "lint"}, ExitCode: -1, Error: "not found"},
+	}
+	var out bytes.Buffer
+	if err := PrintAuditLog(&out, records); err != nil {
+		t.Fatal(err)
+	}
+	want := `TIME                  DURATION  EXIT            COMMAND
+2026-01-02T03:04:05Z  1.5s      1               ap test
+2026-01-02T03:05:00Z  0s        -1 (not found)  ap lint
+`
+	if out.String() != want {
+		t.Errorf("PrintAuditLog() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
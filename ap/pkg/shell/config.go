@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the contents of .ap/shell.yaml.
+type Config struct {
+	// Mode is "ignore", "warn" (default) or "error"; it sets the severity of the findings.
+	Mode string `json:"mode,omitempty"`
+
+	// Shellcheck is "auto" (default) to run shellcheck if it is installed, "always" to require it,
+	// or "never" to only run the built-in checks.
+	Shellcheck string `json:"shellcheck,omitempty"`
+
+	// Skip lists the scripts that are not linted, as paths or globs relative to the ap root.
+	Skip []string `json:"skip,omitempty"`
+}
+
+// LoadConfig loads .ap/shell.yaml from root, returning an empty config if it does not exist.
+func LoadConfig(root string) (*Config, error) {
+	configFile := filepath.Join(root, ".ap", "shell.yaml")
+
+	var config Config
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	if config.Mode != "" && !slices.Contains([]string{"ignore", "warn", "error"}, config.Mode) {
+		return nil, fmt.Errorf("error in %s: mode must be ignore, warn or error, not %q", configFile, config.Mode)
+	}
+	if config.Shellcheck != "" && !slices.Contains([]string{"auto", "always", "never"}, config.Shellcheck) {
+		return nil, fmt.Errorf("error in %s: shellcheck must be auto, always or never, not %q", configFile, config.Shellcheck)
+	}
+	for _, pattern := range config.Skip {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("error in %s: invalid skip pattern %q: %w", configFile, pattern, err)
+		}
+	}
+	return &config, nil
+}
+
+// severity returns the severity of the findings, or "" if they are ignored.
+func (c *Config) severity() findings.Severity {
+	switch c.Mode {
+	case "ignore":
+		return ""
+	case "error":
+		return findings.SeverityError
+	}
+	return findings.SeverityWarning
+}
+
+// skipped returns true if the script at relPath (relative to the ap root) is not linted.
+func (c *Config) skipped(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range c.Skip {
+		pattern = filepath.ToSlash(filepath.Clean(pattern))
+		if ok, _ := filepath.Match(pattern, relPath); ok || relPath == pattern {
+			return true
+		}
+		if strings.HasPrefix(relPath, pattern+"/") {
+			return true
+		}
+	}
+	return false
+}
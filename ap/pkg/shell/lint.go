@@ -0,0 +1,421 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shell lints the shell scripts of an ap root, such as dev/tasks scripts and generated presubmits.
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/repo"
+)
+
+const (
+	// StrictModeRule reports scripts that do not stop on errors.
+	StrictModeRule = "shellstrict"
+	// QuoteRule reports unquoted variable expansions, which are split into words and globbed.
+	QuoteRule = "shellquote"
+)
+
+// skipDirs are not searched for scripts.
+var skipDirs = map[string]bool{".git": true, ".build": true, "node_modules": true, "vendor": true, "third_party": true, "testdata": true}
+
+// Lint checks the shell scripts under root: the files ending in .sh, and the files starting with a sh or bash shebang.
+// Scripts in other ap roots or nested repositories under root are left to them.
+// If shellcheck is installed, it is run over the scripts in place of the built-in quoting check.
+func Lint(ctx context.Context, root string) ([]findings.Finding, error) {
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	severity := cfg.severity()
+	if severity == "" {
+		return nil, nil
+	}
+
+	scripts, err := findScripts(root, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(scripts) == 0 {
+		return nil, nil
+	}
+
+	useShellcheck := false
+	switch cfg.Shellcheck {
+	case "never":
+	case "always":
+		if _, err := exec.LookPath("shellcheck"); err != nil {
+			return nil, fmt.Errorf("shellcheck is required by .ap/shell.yaml in %s, but is not installed: %w", root, err)
+		}
+		useShellcheck = true
+	default:
+		_, err := exec.LookPath("shellcheck")
+		useShellcheck = err == nil
+	}
+
+	var all []findings.Finding
+	for _, script := range scripts {
+		data, err := os.ReadFile(script)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, checkStrictMode(script, data)...)
+		if !useShellcheck {
+			all = append(all, checkQuoting(script, data)...)
+		}
+	}
+	if useShellcheck {
+		found, err := shellcheck(ctx, root, scripts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, found...)
+	}
+	for i := range all {
+		all[i].Severity = severity
+	}
+	return all, nil
+}
+
+// findScripts returns the shell scripts under root that are not skipped.
+func findScripts(root string, cfg *Config) ([]string, error) {
+	var scripts []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if skipDirs[d.Name()] || cfg.skipped(rel) || repo.IsNestedRepo(path) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, ".ap")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || cfg.skipped(rel) {
+			return nil
+		}
+		isScript, err := isShellScript(path)
+		if err != nil {
+			return err
+		}
+		if isScript {
+			scripts = append(scripts, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scripts, nil
+}
+
+// shebangRegex matches the shebang of a sh or bash script, capturing the shell.
+var shebangRegex = regexp.MustCompile(`^#!\s*(?:/usr)?/bin/(?:env\s+)?(bash|sh)\b`)
+
+// isShellScript returns true if the file at path is a sh or bash script.
+func isShellScript(path string) (bool, error) {
+	if strings.HasSuffix(path, ".sh") {
+		return true, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	head := make([]byte, 64)
+	n, _ := f.Read(head)
+	return shebangRegex.Match(head[:n]), nil
+}
+
+// setRegex matches a set command at the start of a line.
+var setRegex = regexp.MustCompile(`^\s*set\s+(.*)$`)
+
+// checkStrictMode reports a script that does not set errexit, nounset and (for bash) pipefail.
+// Files without a shebang are sourced by other scripts rather than run, so they are not checked.
+func checkStrictMode(path string, data []byte) []findings.Finding {
+	shebang := shebangRegex.FindSubmatch(data)
+	if shebang == nil {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		m := setRegex.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		args := strings.Fields(strings.SplitN(m[1], "#", 2)[0])
+		for i := 0; i < len(args); i++ {
+			arg := args[i]
+			if !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			flags := strings.TrimPrefix(arg, "-")
+			if strings.HasSuffix(flags, "o") && i+1 < len(args) {
+				set[args[i+1]] = true
+				flags = strings.TrimSuffix(flags, "o")
+				i++
+			}
+			if strings.Contains(flags, "e") {
+				set["errexit"] = true
+			}
+			if strings.Contains(flags, "u") {
+				set["nounset"] = true
+			}
+		}
+	}
+
+	required := []string{"errexit", "nounset"}
+	if string(shebang[1]) == "bash" {
+		required = append(required, "pipefail")
+	}
+	var missing []string
+	for _, option := range required {
+		if !set[option] {
+			missing = append(missing, option)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	var fix []string
+	for _, option := range missing {
+		fix = append(fix, "set -o "+option)
+	}
+	return []findings.Finding{{
+		Path:    path,
+		Line:    1,
+		Rule:    StrictModeRule,
+		Message: fmt.Sprintf("script does not set %s, so it keeps going after a failure; add %s", strings.Join(missing, ", "), strings.Join(fix, "; ")),
+	}}
+}
+
+// assignmentRegex matches a word that assigns a variable.
+var assignmentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[[^]]*\])?\+?=`)
+
+// heredocRegex matches the start of a here-document, capturing its delimiter.
+var heredocRegex = regexp.MustCompile(`<<-?\s*['"]?([A-Za-z_][A-Za-z0-9_]*)['"]?`)
+
+// checkQuoting reports variable expansions outside of double quotes, where the shell splits
+// their value into words and expands globs in it. Expansions in assignments, [[ ]] tests,
+// arithmetic, case words and here-documents are not split, so they are not reported.
+func checkQuoting(path string, data []byte) []findings.Finding {
+	var found []findings.Finding
+	lines := strings.Split(string(data), "\n")
+
+	var heredocEnd string
+	inDouble, inSingle := false, false
+	for lineNo := 0; lineNo < len(lines); lineNo++ {
+		line := lines[lineNo]
+		if heredocEnd != "" {
+			if strings.TrimLeft(line, "\t") == heredocEnd {
+				heredocEnd = ""
+			}
+			continue
+		}
+		if m := heredocRegex.FindStringSubmatch(line); m != nil && !inDouble && !inSingle {
+			heredocEnd = m[1]
+		}
+
+		inTest := false
+		wordStart := 0
+		prevWord := ""
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case inSingle:
+				if c == '\'' {
+					inSingle = false
+				}
+				continue
+			case c == '\\':
+				i++
+				continue
+			case inDouble:
+				if c == '"' {
+					inDouble = false
+				} else if c == '$' && strings.HasPrefix(line[i:], "$(") {
+					i = skipParens(line, i+1)
+				}
+				continue
+			}
+
+			switch c {
+			case ' ', '\t', ';', '|', '&':
+				if word := line[wordStart:i]; word != "" {
+					prevWord = word
+				}
+				wordStart = i + 1
+				continue
+			case '#':
+				if i == wordStart {
+					i = len(line)
+					continue
+				}
+			case '\'':
+				inSingle = true
+				continue
+			case '"':
+				inDouble = true
+				continue
+			}
+
+			if i == wordStart {
+				switch {
+				case strings.HasPrefix(line[i:], "[["):
+					inTest = true
+				case strings.HasPrefix(line[i:], "]]"):
+					inTest = false
+				case strings.HasPrefix(line[i:], "(("):
+					i = skipParens(line, i)
+					continue
+				}
+			}
+
+			if c != '$' || i+1 >= len(line) {
+				continue
+			}
+			if strings.HasPrefix(line[i:], "$(") {
+				i = skipParens(line, i+1)
+				continue
+			}
+			name, end := expansion(line, i)
+			if name == "" {
+				continue
+			}
+			if !inTest && prevWord != "case" && !assignmentRegex.MatchString(line[wordStart:i]) {
+				found = append(found, findings.Finding{
+					Path:    path,
+					Line:    lineNo + 1,
+					Column:  i + 1,
+					Rule:    QuoteRule,
+					Message: fmt.Sprintf("%s is not quoted, so its value is split into words and globbed; use \"%s\"", name, name),
+				})
+			}
+			i = end - 1
+		}
+	}
+	return found
+}
+
+// expansion returns the parameter expansion starting at line[i] (a '$') and the index after it,
+// or "" if it is not one that is split into words (e.g. $? or ${#var}).
+func expansion(line string, i int) (string, int) {
+	next := line[i+1]
+	switch {
+	case next == '{':
+		end := strings.IndexByte(line[i:], '}')
+		if end < 0 || strings.HasPrefix(line[i:], "${#") {
+			return "", i + 1
+		}
+		return line[i : i+end+1], i + end + 1
+	case next == '@' || next == '*' || (next >= '0' && next <= '9'):
+		return line[i : i+2], i + 2
+	case next == '_' || (next >= 'a' && next <= 'z') || (next >= 'A' && next <= 'Z'):
+		end := i + 1
+		for end < len(line) && (line[end] == '_' || (line[end] >= 'a' && line[end] <= 'z') || (line[end] >= 'A' && line[end] <= 'Z') || (line[end] >= '0' && line[end] <= '9')) {
+			end++
+		}
+		return line[i:end], end
+	}
+	return "", i + 1
+}
+
+// skipParens returns the index of the parenthesis closing the one at line[i], or the end of the line.
+func skipParens(line string, i int) int {
+	depth := 0
+	for ; i < len(line); i++ {
+		switch line[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(line)
+}
+
+// shellcheckOutput is the json1 output of shellcheck.
+type shellcheckOutput struct {
+	Comments []struct {
+		File    string `json:"file"`
+		Line    int    `json:"line"`
+		Column  int    `json:"column"`
+		Level   string `json:"level"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"comments"`
+}
+
+// shellcheck runs shellcheck over scripts, reporting problems of warning level and above.
+func shellcheck(ctx context.Context, root string, scripts []string) ([]findings.Finding, error) {
+	cmd := exec.CommandContext(ctx, "shellcheck", append([]string{"--format=json1", "--severity=warning", "--external-sources"}, scripts...)...)
+	cmd.Dir = root
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// shellcheck exits with 1 when it reports problems.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("shellcheck failed in %s: %w\n%s", root, err, stderr.String())
+		}
+	}
+
+	var out shellcheckOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("failed to parse shellcheck output: %w", err)
+	}
+	var found []findings.Finding
+	for _, c := range out.Comments {
+		path := c.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		found = append(found, findings.Finding{
+			Path:    path,
+			Line:    c.Line,
+			Column:  c.Column,
+			Rule:    fmt.Sprintf("SC%d", c.Code),
+			Message: c.Message,
+		})
+	}
+	return found, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestCheckStrictMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"set -o", "#!/bin/bash\nset -o errexit\nset -o nounset\nset -o pipefail\n", ""},
+		{"combined flags", "#!/usr/bin/env bash\nset -euo pipefail\n", ""},
+		{"separate flags", "#!/bin/bash\nset -eu -o pipefail # strict\n", ""},
+		{"sh does not need pipefail", "#!/bin/sh\nset -eu\n", ""},
+		{"missing pipefail", "#!/bin/bash\nset -eu\n", "script does not set pipefail"},
+		{"missing all", "#!/bin/bash\necho hello\n", "script does not set errexit, nounset, pipefail"},
+		{"sourced library", "log() { echo \"$@\"; }\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := checkStrictMode("script", []byte(tt.content))
+			if tt.want == "" {
+				if len(found) != 0 {
+					t.Errorf("expected no findings, got %v", found)
+				}
+				return
+			}
+			if len(found) != 1 || !strings.Contains(found[0].Message, tt.want) {
+				t.Errorf("expected a finding containing %q, got %v", tt.want, found)
+			}
+		})
+	}
+}
+
+func TestCheckQuoting(t *testing.T) {
+	script := `#!/bin/bash
+# $COMMENT is fine
+echo $NAME "$QUOTED" '$SINGLE'
+cp ${SRC} "${DST}/"
+DIR=$HOME/dir
+local count=$1
+export PATH=$PATH:/bin
+if [[ -z $EMPTY ]]; then
+  echo "$(ls $INNER)"
+fi
+case $MODE in
+  *) echo $(( COUNT + 1 )) ${#ARRAY[@]} $? ;;
+esac
+cat <<EOF
+$HEREDOC
+EOF
+rm -rf $@ --flag=$FLAG
+echo "multi
+line $STILL_QUOTED" $AFTER
+`
+	var got []string
+	for _, f := range checkQuoting("script", []byte(script)) {
+		got = append(got, strings.Fields(f.String())[0]+" "+strings.Fields(f.Message)[0])
+	}
+	want := []string{
+		"script:3:6: $NAME",
+		"script:4:4: ${SRC}",
+		"script:17:8: $@",
+		"script:17:18: $FLAG",
+		"script:19:21: $AFTER",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkQuoting() = %v, want %v", got, want)
+	}
+}
+
+func TestLint(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".ap/shell.yaml":           "mode: error\nshellcheck: never\nskip:\n- hack/legacy\n",
+		"dev/tasks/test-unit":      "#!/bin/bash\nset -euo pipefail\ngo test $PKGS\n",
+		"dev/tasks/README.md":      "Run $TASK\n",
+		"hack/legacy/old.sh":       "#!/bin/bash\necho $OLD\n",
+		"lib/common.sh":            "log() { echo \"$@\"; }\n",
+		"pkg/testdata/bad.sh":      "#!/bin/bash\necho $BAD\n",
+		"nested/.ap/go.yaml":       "",
+		"nested/dev/tasks/test-go": "#!/bin/bash\necho $NESTED\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Lint(t.Context(), root)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	findings.Relativize(found, root)
+	want := []findings.Finding{{
+		Path:     "dev/tasks/test-unit",
+		Line:     3,
+		Column:   9,
+		Rule:     QuoteRule,
+		Message:  `$PKGS is not quoted, so its value is split into words and globbed; use "$PKGS"`,
+		Severity: findings.SeverityError,
+	}}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("Lint() = %v, want %v", found, want)
+	}
+}
+
+func TestLoadConfigValidates(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".ap", "shell.yaml"), []byte("shellcheck: sometimes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(root); err == nil || !strings.Contains(err.Error(), "shellcheck must be auto, always or never") {
+		t.Errorf("expected an error for an invalid shellcheck value, got %v", err)
+	}
+}
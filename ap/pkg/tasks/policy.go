@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the contents of .ap/tasks.yaml.
+type Config struct {
+	// Defaults applies to every task script.
+	Defaults Policy `json:"defaults,omitempty"`
+
+	// Scripts overrides the defaults for individual scripts, keyed by script name (e.g. "test-e2e").
+	Scripts map[string]Policy `json:"scripts,omitempty"`
+}
+
+// Policy limits what a task script can do.
+type Policy struct {
+	// Timeout is the maximum run time of the script (e.g. "30m"); the script is killed when it is exceeded.
+	Timeout string `json:"timeout,omitempty"`
+
+	// CPUs limits the CPU the script can use, in cores (e.g. "2" or "0.5").
+	CPUs string `json:"cpus,omitempty"`
+
+	// Memory limits the memory the script can use (e.g. "512Mi" or "4G").
+	Memory string `json:"memory,omitempty"`
+
+	// Network can be set to false to run the script without network access.
+	Network *bool `json:"network,omitempty"`
+
+	// Image is the container image used to run the script if the limits cannot be enforced on the host.
+	Image string `json:"image,omitempty"`
+
+	// Container always runs the script in Image, even if the limits could be enforced on the host.
+	Container bool `json:"container,omitempty"`
+}
+
+// LoadConfig loads .ap/tasks.yaml from root, returning an empty config if it does not exist.
+func LoadConfig(root string) (*Config, error) {
+	configFile := filepath.Join(root, ".ap", "tasks.yaml")
+
+	var config Config
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	if err := config.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("error in %s defaults: %w", configFile, err)
+	}
+	for name, policy := range config.Scripts {
+		if err := policy.validate(); err != nil {
+			return nil, fmt.Errorf("error in %s for script %s: %w", configFile, name, err)
+		}
+	}
+	return &config, nil
+}
+
+// PolicyFor returns the policy for the named script: the defaults, overridden by the fields set for the script.
+func (c *Config) PolicyFor(name string) Policy {
+	policy := c.Defaults
+	override, ok := c.Scripts[name]
+	if !ok {
+		return policy
+	}
+	if override.Timeout != "" {
+		policy.Timeout = override.Timeout
+	}
+	if override.CPUs != "" {
+		policy.CPUs = override.CPUs
+	}
+	if override.Memory != "" {
+		policy.Memory = override.Memory
+	}
+	if override.Network != nil {
+		policy.Network = override.Network
+	}
+	if override.Image != "" {
+		policy.Image = override.Image
+	}
+	if override.Container {
+		policy.Container = true
+	}
+	return policy
+}
+
+func (p *Policy) validate() error {
+	if _, err := p.timeout(); err != nil {
+		return err
+	}
+	if p.CPUs != "" {
+		if cpus, err := strconv.ParseFloat(p.CPUs, 64); err != nil || cpus <= 0 {
+			return fmt.Errorf("invalid cpus %q", p.CPUs)
+		}
+	}
+	if p.Memory != "" {
+		if _, err := parseMemory(p.Memory); err != nil {
+			return err
+		}
+	}
+	if p.Container && p.Image == "" {
+		return fmt.Errorf("container is set but no image is configured")
+	}
+	return nil
+}
+
+// timeout returns the parsed timeout, or 0 if there is none.
+func (p *Policy) timeout() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", p.Timeout)
+	}
+	return d, nil
+}
+
+func (p *Policy) networkDisabled() bool {
+	return p.Network != nil && !*p.Network
+}
+
+func (p *Policy) hasLimits() bool {
+	return p.CPUs != "" || p.Memory != "" || p.networkDisabled()
+}
+
+// memoryUnits are the suffixes accepted by parseMemory, longest first.
+var memoryUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30},
+	{"K", 1000}, {"M", 1000 * 1000}, {"G", 1000 * 1000 * 1000},
+}
+
+// parseMemory parses a memory quantity (e.g. "512Mi", "4G" or "1048576") into bytes.
+func parseMemory(s string) (int64, error) {
+	number, multiplier := s, int64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			number, multiplier = strings.TrimSuffix(s, unit.suffix), unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// hostSupport reports which limits can be enforced on the host.
+type hostSupport struct {
+	// cgroups is true if systemd-run can create a transient user scope with resource limits.
+	cgroups bool
+	// netns is true if unshare can create an unprivileged network namespace.
+	netns bool
+}
+
+// hostSupportByRunner caches what detectHostSupport found with each runner.Runner,
+// as the host does not change while ap runs.
+var hostSupportByRunner sync.Map
+
+// detectHostSupport probes the host with the runner of ctx.
+func detectHostSupport(ctx context.Context) hostSupport {
+	r := runner.FromContext(ctx)
+	if host, ok := hostSupportByRunner.Load(r); ok {
+		return host.(hostSupport)
+	}
+	probe := func(name string, args ...string) bool {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		return runner.Run(ctx, exec.CommandContext(ctx, name, args...)) == nil
+	}
+	host := hostSupport{
+		cgroups: probe("systemd-run", "--user", "--scope", "--quiet", "--collect", "true"),
+		netns:   probe("unshare", "--net", "--map-root-user", "true"),
+	}
+	hostSupportByRunner.Store(r, host)
+	return host
+}
+
+// commandLine returns the command that runs the script at path under the policy,
+// and whether it runs in a container (named containerName, with the variables in env set).
+// It returns an error if the limits cannot be enforced.
+func (p *Policy) commandLine(root, path, containerName string, env []string, host hostSupport) ([]string, bool, error) {
+	if p.Container {
+		return p.containerCommandLine(root, path, containerName, env), true, nil
+	}
+
+	canEnforce := (p.CPUs == "" && p.Memory == "" || host.cgroups) && (!p.networkDisabled() || host.netns)
+	if !canEnforce {
+		if p.Image != "" {
+			return p.containerCommandLine(root, path, containerName, env), true, nil
+		}
+		return nil, false, fmt.Errorf("the resource limits cannot be enforced on this host (requires systemd-run --user and unshare); configure an image to run the script in a container")
+	}
+
+	var args []string
+	if p.CPUs != "" || p.Memory != "" {
+		args = append(args, "systemd-run", "--user", "--scope", "--quiet", "--collect")
+		if p.CPUs != "" {
+			cpus, _ := strconv.ParseFloat(p.CPUs, 64)
+			args = append(args, "-p", fmt.Sprintf("CPUQuota=%d%%", int(cpus*100)))
+		}
+		if p.Memory != "" {
+			memory, _ := parseMemory(p.Memory)
+			args = append(args, "-p", fmt.Sprintf("MemoryMax=%d", memory))
+		}
+		args = append(args, "--")
+	}
+	if p.networkDisabled() {
+		args = append(args, "unshare", "--net", "--map-root-user", "--")
+	}
+	return append(args, path), false, nil
+}
+
+// containerCommandLine runs the script in the policy image, with the ap root mounted at the same path.
+func (p *Policy) containerCommandLine(root, path, containerName string, env []string) []string {
+	args := []string{"docker", "run", "--rm", "--init", "--name", containerName, "-v", root + ":" + root, "-w", root}
+	for _, e := range env {
+		args = append(args, "-e", e)
+	}
+	if p.CPUs != "" {
+		args = append(args, "--cpus", p.CPUs)
+	}
+	if p.Memory != "" {
+		memory, _ := parseMemory(p.Memory)
+		args = append(args, "--memory", strconv.FormatInt(memory, 10))
+	}
+	if p.networkDisabled() {
+		args = append(args, "--network", "none")
+	}
+	return append(args, p.Image, path)
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tasks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+)
+
+func writeTasksConfig(t *testing.T, root, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".ap", "tasks.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	writeTasksConfig(t, root, `
+defaults:
+  timeout: 30m
+  memory: 4Gi
+scripts:
+  test-e2e:
+    timeout: 2h
+    network: false
+    image: golang:1.26
+`)
+
+	config, err := LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	networkOff := false
+	want := Policy{Timeout: "2h", Memory: "4Gi", Network: &networkOff, Image: "golang:1.26"}
+	if got := config.PolicyFor("test-e2e"); !reflect.DeepEqual(got, want) {
+		t.Errorf("PolicyFor(test-e2e) = %+v, want %+v", got, want)
+	}
+	if got := config.PolicyFor("test-unit"); !reflect.DeepEqual(got, Policy{Timeout: "30m", Memory: "4Gi"}) {
+		t.Errorf("PolicyFor(test-unit) = %+v, want the defaults", got)
+	}
+
+	for _, invalid := range []string{
+		"defaults:\n  timeout: forever\n",
+		"defaults:\n  cpus: lots\n",
+		"scripts:\n  test-e2e:\n    memory: 4TB\n",
+		"scripts:\n  test-e2e:\n    container: true\n",
+	} {
+		writeTasksConfig(t, root, invalid)
+		if _, err := LoadConfig(root); err == nil {
+			t.Errorf("expected an error loading %q", invalid)
+		}
+	}
+
+	if _, err := LoadConfig(t.TempDir()); err != nil {
+		t.Errorf("expected a missing config to be allowed, got %v", err)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := map[string]int64{
+		"1048576": 1048576,
+		"512Mi":   512 << 20,
+		"4Gi":     4 << 30,
+		"2G":      2000000000,
+		"64K":     64000,
+	}
+	for input, want := range tests {
+		got, err := parseMemory(input)
+		if err != nil {
+			t.Errorf("parseMemory(%q) failed: %v", input, err)
+		} else if got != want {
+			t.Errorf("parseMemory(%q) = %d, want %d", input, got, want)
+		}
+	}
+	for _, input := range []string{"", "Mi", "-1Gi", "1.5G"} {
+		if _, err := parseMemory(input); err == nil {
+			t.Errorf("expected an error parsing %q", input)
+		}
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	networkOff := false
+	full := hostSupport{cgroups: true, netns: true}
+
+	tests := []struct {
+		name          string
+		policy        Policy
+		env           []string
+		host          hostSupport
+		want          string
+		wantContainer bool
+		wantErr       bool
+	}{
+		{
+			name: "no limits",
+			want: "/src/dev/tasks/test-e2e",
+		},
+		{
+			name:   "cgroups and network namespace",
+			policy: Policy{CPUs: "1.5", Memory: "1Gi", Network: &networkOff},
+			host:   full,
+			want:   "systemd-run --user --scope --quiet --collect -p CPUQuota=150% -p MemoryMax=1073741824 -- unshare --net --map-root-user -- /src/dev/tasks/test-e2e",
+		},
+		{
+			name:          "container fallback",
+			policy:        Policy{Memory: "1Gi", Network: &networkOff, Image: "golang"},
+			host:          hostSupport{netns: true},
+			want:          "docker run --rm --init --name ap-task -v /src:/src -w /src --memory 1073741824 --network none golang /src/dev/tasks/test-e2e",
+			wantContainer: true,
+		},
+		{
+			name:          "always in container",
+			policy:        Policy{CPUs: "2", Image: "golang", Container: true},
+			env:           []string{"KUBECONFIG=/src/.build/e2e/kubeconfig"},
+			host:          full,
+			want:          "docker run --rm --init --name ap-task -v /src:/src -w /src -e KUBECONFIG=/src/.build/e2e/kubeconfig --cpus 2 golang /src/dev/tasks/test-e2e",
+			wantContainer: true,
+		},
+		{
+			name:    "cannot enforce",
+			policy:  Policy{Network: &networkOff},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, inContainer, err := tt.policy.commandLine("/src", "/src/dev/tasks/test-e2e", "ap-task", tt.env, tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("commandLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("commandLine() = %q, want %q", got, tt.want)
+			}
+			if inContainer != tt.wantContainer {
+				t.Errorf("commandLine() inContainer = %v, want %v", inContainer, tt.wantContainer)
+			}
+		})
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	root := t.TempDir()
+	script := filepath.Join(root, "sleep")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 60\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	task := &TaskScript{Name: "sleep", Path: script, Policy: Policy{Timeout: "100ms"}}
+	err := task.Run(context.Background(), root)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestDetectHostSupport(t *testing.T) {
+	// unshare is not replayed, as if it were not installed.
+	replayer := runner.NewReplayer(runner.Invocation{Args: []string{"systemd-run", "--user", "--scope", "--quiet", "--collect", "true"}})
+	ctx := runner.NewContext(context.Background(), replayer)
+
+	want := hostSupport{cgroups: true}
+	if got := detectHostSupport(ctx); got != want {
+		t.Errorf("detectHostSupport() = %+v, want %+v", got, want)
+	}
+	// The host is only probed once.
+	if got := detectHostSupport(ctx); got != want {
+		t.Errorf("second detectHostSupport() = %+v, want %+v", got, want)
+	}
+	if got := len(replayer.Calls()); got != 2 {
+		t.Errorf("ran %d commands, want 2: %q", got, replayer.CommandLines())
+	}
+}
@@ -16,13 +16,17 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/tools"
 	"k8s.io/klog/v2"
 )
 
@@ -36,15 +40,67 @@ type Task interface {
 type TaskScript struct {
 	Name string
 	Path string
+
+	// Policy limits the resources and run time of the script, as configured in .ap/tasks.yaml.
+	Policy Policy
+
+	// Env holds additional environment variables (KEY=value) for the script.
+	Env []string
 }
 
 func (t *TaskScript) Run(ctx context.Context, root string) error {
 	klog.Infof("Running task: %s", t.Name)
-	cmd := exec.CommandContext(ctx, t.Path)
+
+	timeout, err := t.Policy.timeout()
+	if err != nil {
+		return fmt.Errorf("task %s: %w", t.Name, err)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var host hostSupport
+	if t.Policy.hasLimits() && !t.Policy.Container {
+		host = detectHostSupport(ctx)
+	}
+	containerName := fmt.Sprintf("ap-task-%s-%d", t.Name, os.Getpid())
+	args, inContainer, err := t.Policy.commandLine(root, t.Path, containerName, t.Env, host)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", t.Name, err)
+	}
+
+	if inContainer {
+		if ok, err := tools.Require(ctx, tools.Requirement{Tool: "docker", Task: "run task " + t.Name + " in a container"}); !ok {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	cmd.Dir = root
+	if len(t.Env) > 0 {
+		cmd.Env = append(os.Environ(), t.Env...)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	// Don't wait forever for background processes of the script that hold on to stdout/stderr.
+	cmd.WaitDelay = 10 * time.Second
+	killProcessGroup(cmd)
+	if inContainer {
+		// Killing the docker client does not stop the container.
+		killGroup := cmd.Cancel
+		cmd.Cancel = func() error {
+			// ctx is done by now, but still holds the runner.
+			killCtx := context.WithoutCancel(ctx)
+			_ = runner.Run(killCtx, exec.CommandContext(killCtx, "docker", "kill", containerName))
+			return killGroup()
+		}
+	}
+	if err := runner.Run(ctx, cmd); err != nil {
+		if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("task %s timed out after %v", t.Name, timeout)
+		}
 		return fmt.Errorf("task %s failed: %w", t.Name, err)
 	}
 	return nil
@@ -89,6 +145,11 @@ func FindTaskScripts(root string, opts ...FindOption) ([]Task, error) {
 		return nil, fmt.Errorf("failed to read tasks dir: %w", err)
 	}
 
+	config, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
 	var tasks []Task
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -102,8 +163,9 @@ func FindTaskScripts(root string, opts ...FindOption) ([]Task, error) {
 			continue
 		}
 		tasks = append(tasks, &TaskScript{
-			Name: name,
-			Path: filepath.Join(tasksDir, name),
+			Name:   name,
+			Path:   filepath.Join(tasksDir, name),
+			Policy: config.PolicyFor(name),
 		})
 	}
 
@@ -115,6 +177,18 @@ func FindTaskScripts(root string, opts ...FindOption) ([]Task, error) {
 	return tasks, nil
 }
 
+// DryRunEnv is set to "true" for task scripts run by a dry run, which should then skip any side effects (e.g. pushing).
+const DryRunEnv = "AP_DRY_RUN"
+
+// AddEnv adds environment variables (KEY=value) to the task scripts in tasks.
+func AddEnv(tasks []Task, env ...string) {
+	for _, task := range tasks {
+		if script, ok := task.(*TaskScript); ok {
+			script.Env = append(script.Env, env...)
+		}
+	}
+}
+
 // Run executes a list of tasks.
 func Run(ctx context.Context, root string, tasks []Task) error {
 	for _, task := range tasks {
@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/runner"
+	"k8s.io/klog/v2"
+)
+
+// ignoreMissingFlag is the flag that skips the tasks whose tools are missing, instead of failing.
+const ignoreMissingFlag = "--ignore-missing-tools"
+
+// Requirement is an external tool (e.g. docker, kubectl or git) that a task needs.
+type Requirement struct {
+	// Tool is the name of the executable, looked up on PATH.
+	Tool string
+	// Task describes what the tool is used for, e.g. "build Docker images".
+	Task string
+	// Optional tasks are always skipped when their tool is missing.
+	Optional bool
+	// Essential tasks cannot be skipped: the command fails without their tool, even with --ignore-missing-tools.
+	Essential bool
+}
+
+// Outcome describes what happens to the task when its tool is missing: "skipped" or "will fail".
+func (r Requirement) Outcome(ignoreMissing bool) string {
+	if r.skippable(ignoreMissing) {
+		return "skipped"
+	}
+	return "will fail"
+}
+
+func (r Requirement) skippable(ignoreMissing bool) bool {
+	return r.Optional || (ignoreMissing && !r.Essential)
+}
+
+// Available reports whether tool is installed.
+func Available(tool string) bool {
+	_, err := exec.LookPath(tool)
+	return err == nil
+}
+
+// Missing returns the requirements whose tools are not installed.
+func Missing(reqs []Requirement) []Requirement {
+	var missing []Requirement
+	for _, req := range reqs {
+		if !Available(req.Tool) {
+			missing = append(missing, req)
+		}
+	}
+	return missing
+}
+
+// MissingError is returned for a task that cannot run because its tool is not installed.
+type MissingError struct {
+	Requirement
+	// Skippable is set if --ignore-missing-tools would have skipped the task.
+	Skippable bool
+}
+
+func (e *MissingError) Error() string {
+	msg := fmt.Sprintf("%s is not installed; it is required to %s", e.Tool, e.Task)
+	if e.Skippable {
+		msg += " (use " + ignoreMissingFlag + " to skip this)"
+	}
+	return msg
+}
+
+// WriteReport writes a table of the missing requirements, and what happens to their tasks.
+func WriteReport(w io.Writer, missing []Requirement, ignoreMissing bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MISSING TOOL\tTASK\tOUTCOME")
+	for _, req := range missing {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", req.Tool, req.Task, req.Outcome(ignoreMissing))
+	}
+	return tw.Flush()
+}
+
+// Summary returns a one-line summary of the skipped tasks, or "" if none were skipped.
+func Summary(skipped []Requirement) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	byTool := map[string][]string{}
+	var toolNames []string
+	for _, req := range skipped {
+		if _, ok := byTool[req.Tool]; !ok {
+			toolNames = append(toolNames, req.Tool)
+		}
+		if !slices.Contains(byTool[req.Tool], req.Task) {
+			byTool[req.Tool] = append(byTool[req.Tool], req.Task)
+		}
+	}
+	var parts []string
+	for _, tool := range toolNames {
+		parts = append(parts, fmt.Sprintf("%s (missing %s)", strings.Join(byTool[tool], ", "), tool))
+	}
+	return fmt.Sprintf("skipped %d task(s) because of missing tools: %s", len(skipped), strings.Join(parts, "; "))
+}
+
+// recorder holds the mode of a command, and the tasks it skipped.
+type recorder struct {
+	ignoreMissing bool
+
+	mu      sync.Mutex
+	skipped []Requirement
+}
+
+type contextKey struct{}
+
+// NewContext returns a context in which Require skips the tasks whose tools are missing if ignoreMissing is set,
+// and records the skipped tasks for Skipped.
+func NewContext(ctx context.Context, ignoreMissing bool) context.Context {
+	return context.WithValue(ctx, contextKey{}, &recorder{ignoreMissing: ignoreMissing})
+}
+
+// Skipped returns the tasks that were skipped in ctx because their tools were missing, in the order they were skipped.
+func Skipped(ctx context.Context) []Requirement {
+	r, _ := ctx.Value(contextKey{}).(*recorder)
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.skipped)
+}
+
+// Require checks that the tool of req is installed before its task runs.
+// It returns true if the task can run. If the tool is missing, it returns false, with
+// a *MissingError unless the task is skipped: it is optional, or --ignore-missing-tools is set and it is not essential.
+func Require(ctx context.Context, req Requirement) (bool, error) {
+	if _, err := runner.LookPath(ctx, req.Tool); err == nil {
+		return true, nil
+	}
+	r, _ := ctx.Value(contextKey{}).(*recorder)
+	ignoreMissing := r != nil && r.ignoreMissing
+	if !req.skippable(ignoreMissing) {
+		return false, &MissingError{Requirement: req, Skippable: !req.Essential}
+	}
+	klog.Warningf("Skipping task %q: %s is not installed", req.Task, req.Tool)
+	if r != nil {
+		r.mu.Lock()
+		r.skipped = append(r.skipped, req)
+		r.mu.Unlock()
+	}
+	return false, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withPath makes PATH a directory containing only executables with the given names.
+func withPath(t *testing.T, names ...string) {
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestRequire(t *testing.T) {
+	withPath(t, "git")
+
+	tests := []struct {
+		name          string
+		req           Requirement
+		ignoreMissing bool
+		wantOK        bool
+		wantErr       bool
+		wantSkipped   bool
+	}{
+		{name: "installed", req: Requirement{Tool: "git", Task: "lint"}, wantOK: true},
+		{name: "missing", req: Requirement{Tool: "docker", Task: "build"}, wantErr: true},
+		{name: "missing and ignored", req: Requirement{Tool: "docker", Task: "build"}, ignoreMissing: true, wantSkipped: true},
+		{name: "missing optional", req: Requirement{Tool: "shellcheck", Task: "lint", Optional: true}, wantSkipped: true},
+		{name: "missing essential", req: Requirement{Tool: "kubectl", Task: "deploy", Essential: true}, ignoreMissing: true, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := NewContext(t.Context(), tc.ignoreMissing)
+			ok, err := Require(ctx, tc.req)
+			if ok != tc.wantOK {
+				t.Errorf("Require() = %v, want %v", ok, tc.wantOK)
+			}
+			var missing *MissingError
+			if gotErr := errors.As(err, &missing); gotErr != tc.wantErr {
+				t.Errorf("Require() error = %v, want a MissingError: %v", err, tc.wantErr)
+			}
+			if skipped := Skipped(ctx); (len(skipped) == 1) != tc.wantSkipped {
+				t.Errorf("Skipped() = %v, want skipped: %v", skipped, tc.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestMissingError(t *testing.T) {
+	withPath(t)
+
+	_, err := Require(t.Context(), Requirement{Tool: "docker", Task: "build image web"})
+	want := "docker is not installed; it is required to build image web (use --ignore-missing-tools to skip this)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Require() error = %v, want %q", err, want)
+	}
+
+	_, err = Require(t.Context(), Requirement{Tool: "git", Task: "tag the release", Essential: true})
+	want = "git is not installed; it is required to tag the release"
+	if err == nil || err.Error() != want {
+		t.Errorf("Require() error = %v, want %q", err, want)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	withPath(t, "git")
+
+	missing := Missing([]Requirement{
+		{Tool: "git", Task: "lint the changes"},
+		{Tool: "docker", Task: "build images"},
+		{Tool: "shellcheck", Task: "lint shell scripts", Optional: true},
+		{Tool: "gcloud", Task: "delete clusters", Essential: true},
+	})
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, missing, true); err != nil {
+		t.Fatal(err)
+	}
+	want := `MISSING TOOL  TASK                OUTCOME
+docker        build images        skipped
+shellcheck    lint shell scripts  skipped
+gcloud        delete clusters     will fail
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteReport() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	got := Summary([]Requirement{
+		{Tool: "docker", Task: "build image web"},
+		{Tool: "kubectl", Task: "deploy manifests"},
+		{Tool: "docker", Task: "build image api"},
+	})
+	want := "skipped 3 task(s) because of missing tools: build image web, build image api (missing docker); deploy manifests (missing kubectl)"
+	if got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if got := Summary(nil); got != "" {
+		t.Errorf("Summary(nil) = %q, want empty", got)
+	}
+}
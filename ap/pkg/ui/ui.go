@@ -0,0 +1,172 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ui implements an interactive terminal browser for the results recorded under .build.
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+)
+
+// RerunFunc runs a test again, returning its new result.
+type RerunFunc func(ctx context.Context, result *golang.TestResult, out io.Writer) (*golang.TestResult, error)
+
+// Browser presents the failed tests and lets the user inspect and re-run them.
+type Browser struct {
+	in    *bufio.Scanner
+	out   io.Writer
+	rerun RerunFunc
+
+	results []*golang.TestResult
+	// items is the list currently shown to the user, which commands refer to by number.
+	items []*golang.TestResult
+}
+
+// NewBrowser creates a Browser over results, reading commands from in and writing to out.
+func NewBrowser(results []*golang.TestResult, in io.Reader, out io.Writer, rerun RerunFunc) *Browser {
+	return &Browser{
+		in:      bufio.NewScanner(in),
+		out:     out,
+		rerun:   rerun,
+		results: results,
+	}
+}
+
+const help = `Commands:
+  <n>      show the output of item n
+  r <n>    re-run item n
+  f        list failed tests (default)
+  a        list all tests
+  h        show this help
+  q        quit
+`
+
+// Run runs the interactive loop until the user quits or the input ends.
+func (b *Browser) Run(ctx context.Context) error {
+	b.printSummary()
+	b.listFailures()
+	fmt.Fprint(b.out, help)
+
+	for {
+		fmt.Fprint(b.out, "> ")
+		if !b.in.Scan() {
+			fmt.Fprintln(b.out)
+			return b.in.Err()
+		}
+		fields := strings.Fields(b.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "q", "quit", "exit":
+			return nil
+		case "h", "help", "?":
+			fmt.Fprint(b.out, help)
+		case "f":
+			b.listFailures()
+		case "a":
+			b.list(b.results)
+		case "r":
+			if len(fields) != 2 {
+				fmt.Fprintln(b.out, "usage: r <n>")
+				continue
+			}
+			item, ok := b.item(fields[1])
+			if !ok {
+				continue
+			}
+			if err := b.rerunItem(ctx, item); err != nil {
+				fmt.Fprintf(b.out, "re-run failed: %v\n", err)
+			}
+		default:
+			item, ok := b.item(fields[0])
+			if !ok {
+				continue
+			}
+			b.show(item)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Browser) printSummary() {
+	counts := make(map[string]int)
+	for _, r := range b.results {
+		if r.Test != "" {
+			counts[r.Action]++
+		}
+	}
+	fmt.Fprintf(b.out, "%d failed, %d passed, %d skipped\n\n", counts["fail"], counts["pass"], counts["skip"])
+}
+
+func (b *Browser) listFailures() {
+	failures := golang.Failures(b.results)
+	if len(failures) == 0 {
+		fmt.Fprintln(b.out, "No failures.")
+	}
+	b.list(failures)
+}
+
+func (b *Browser) list(items []*golang.TestResult) {
+	b.items = items
+	for i, item := range items {
+		fmt.Fprintf(b.out, "%3d) %s\n", i+1, item.String())
+	}
+}
+
+func (b *Browser) item(s string) (*golang.TestResult, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		fmt.Fprintf(b.out, "unknown command %q; type h for help\n", s)
+		return nil, false
+	}
+	if n < 1 || n > len(b.items) {
+		fmt.Fprintf(b.out, "no item %d\n", n)
+		return nil, false
+	}
+	return b.items[n-1], true
+}
+
+func (b *Browser) show(item *golang.TestResult) {
+	fmt.Fprintf(b.out, "=== %s\n", item.String())
+	for _, line := range item.Output {
+		fmt.Fprint(b.out, line)
+		if !strings.HasSuffix(line, "\n") {
+			fmt.Fprintln(b.out)
+		}
+	}
+}
+
+func (b *Browser) rerunItem(ctx context.Context, item *golang.TestResult) error {
+	fmt.Fprintf(b.out, "Re-running %s\n", item.String())
+	updated, err := b.rerun(ctx, item, b.out)
+	if err != nil {
+		return err
+	}
+	// Update in place, so the new status shows up in the listings.
+	*item = *updated
+	fmt.Fprintf(b.out, "=== %s\n", item.String())
+	return nil
+}
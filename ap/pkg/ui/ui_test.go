@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	golang "github.com/gke-labs/gke-labs-infra/ap/pkg/go"
+)
+
+func TestBrowser(t *testing.T) {
+	results := []*golang.TestResult{
+		{Package: "example.com/a", Test: "TestOK", Action: "pass"},
+		{Package: "example.com/a", Test: "TestBroken", Action: "fail", Output: []string{"    a_test.go:10: boom\n"}},
+	}
+
+	var rerun []string
+	fakeRerun := func(ctx context.Context, result *golang.TestResult, out io.Writer) (*golang.TestResult, error) {
+		rerun = append(rerun, result.Test)
+		updated := *result
+		updated.Action = "pass"
+		updated.Output = []string{"ok\n"}
+		return &updated, nil
+	}
+
+	in := strings.NewReader("1\nr 1\nr 7\nbogus\na\nq\n")
+	var out bytes.Buffer
+	if err := NewBrowser(results, in, &out, fakeRerun).Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(rerun) != 1 || rerun[0] != "TestBroken" {
+		t.Errorf("expected TestBroken to be re-run once, got %v", rerun)
+	}
+	if results[1].Action != "pass" {
+		t.Errorf("expected re-run result to replace the original, got %q", results[1].Action)
+	}
+
+	for _, want := range []string{
+		"1 failed, 1 passed, 0 skipped",
+		"  1) FAIL example.com/a TestBroken",
+		"a_test.go:10: boom",
+		"Re-running FAIL example.com/a TestBroken",
+		"=== PASS example.com/a TestBroken",
+		"no item 7",
+		`unknown command "bogus"`,
+		"  2) PASS example.com/a TestBroken",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
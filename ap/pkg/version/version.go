@@ -15,44 +15,111 @@
 package version
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"runtime/debug"
+	"strings"
 )
 
-// Run prints version information
-func Run(_ context.Context, _ string) error {
-	info, ok := debug.ReadBuildInfo()
-	if !ok {
-		return fmt.Errorf("failed to read build info")
-	}
+// Package is the import path of this package.
+// ap build sets its variables in every Go binary it builds, see LDFlags.
+const Package = "github.com/gke-labs/gke-labs-infra/ap/pkg/version"
 
-	fmt.Printf("Module: %s\n", info.Main.Path)
-	if info.Main.Version != "" {
-		fmt.Printf("Version: %s\n", info.Main.Version)
-	}
+// These are set at link time with -ldflags "-X ...".
+// When they are empty, Get falls back to the build info recorded by the Go toolchain.
+var (
+	version   string
+	gitSHA    string
+	buildDate string
+)
 
-	var revision string
-	var modified bool
+// Info is the build metadata of a binary.
+type Info struct {
+	Module    string `json:"module,omitempty"`
+	Version   string `json:"version,omitempty"`
+	GitSHA    string `json:"gitSHA,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+}
 
-	for _, setting := range info.Settings {
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	info := Info{
+		Version:   version,
+		GitSHA:    gitSHA,
+		BuildDate: buildDate,
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = buildInfo.Main.Path
+	info.GoVersion = buildInfo.GoVersion
+	if info.Version == "" && buildInfo.Main.Version != "(devel)" {
+		info.Version = buildInfo.Main.Version
+	}
+	for _, setting := range buildInfo.Settings {
 		switch setting.Key {
 		case "vcs.revision":
-			revision = setting.Value
+			if info.GitSHA == "" {
+				info.GitSHA = setting.Value
+			}
 		case "vcs.modified":
-			modified = setting.Value == "true"
+			info.Modified = setting.Value == "true"
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
 		}
 	}
+	return info
+}
 
-	if revision != "" {
-		fmt.Printf("Git SHA: %s", revision)
-		if modified {
-			fmt.Printf(" (modified)")
+// LDFlags returns the linker flags that set the values reported by Get.
+// Empty values are omitted.
+func LDFlags(version, gitSHA, buildDate string) string {
+	var flags []string
+	for _, kv := range []struct{ name, value string }{
+		{"version", version},
+		{"gitSHA", gitSHA},
+		{"buildDate", buildDate},
+	} {
+		if kv.value != "" {
+			flags = append(flags, fmt.Sprintf("-X %s.%s=%s", Package, kv.name, kv.value))
 		}
-		fmt.Println()
-	} else {
-		fmt.Println("Git SHA: unknown")
 	}
+	return strings.Join(flags, " ")
+}
 
+// Print writes info to w, as JSON if asJSON is set.
+func Print(w io.Writer, info Info, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Fprintf(w, "Module: %s\n", info.Module)
+	if info.Version != "" {
+		fmt.Fprintf(w, "Version: %s\n", info.Version)
+	}
+	if info.GitSHA != "" {
+		fmt.Fprintf(w, "Git SHA: %s", info.GitSHA)
+		if info.Modified {
+			fmt.Fprintf(w, " (modified)")
+		}
+		fmt.Fprintln(w)
+	} else {
+		fmt.Fprintln(w, "Git SHA: unknown")
+	}
+	if info.BuildDate != "" {
+		fmt.Fprintf(w, "Build date: %s\n", info.BuildDate)
+	}
+	if info.GoVersion != "" {
+		fmt.Fprintf(w, "Go version: %s\n", info.GoVersion)
+	}
 	return nil
 }
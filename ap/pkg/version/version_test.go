@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestLDFlags(t *testing.T) {
+	got := LDFlags("v1.2.3", "0123abcd", "")
+	want := "-X " + Package + ".version=v1.2.3 -X " + Package + ".gitSHA=0123abcd"
+	if got != want {
+		t.Errorf("LDFlags() = %q, want %q", got, want)
+	}
+	if got := LDFlags("", "", ""); got != "" {
+		t.Errorf("LDFlags() with no values = %q, want empty", got)
+	}
+}
+
+func TestGetPrefersInjectedValues(t *testing.T) {
+	defer func(v, sha, date string) { version, gitSHA, buildDate = v, sha, date }(version, gitSHA, buildDate)
+	version, gitSHA, buildDate = "v1.2.3", "0123abcd", "2026-01-02T03:04:05Z"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.GitSHA != "0123abcd" || info.BuildDate != "2026-01-02T03:04:05Z" {
+		t.Errorf("Get() = %+v, want the injected values", info)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	info := Info{Module: "example.com/foo", Version: "v1.2.3", GitSHA: "0123abcd", Modified: true, BuildDate: "2026-01-02T03:04:05Z"}
+
+	var text bytes.Buffer
+	if err := Print(&text, info, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "Module: example.com/foo\nVersion: v1.2.3\nGit SHA: 0123abcd (modified)\nBuild date: 2026-01-02T03:04:05Z\n"
+	if text.String() != want {
+		t.Errorf("Print() = %q, want %q", text.String(), want)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, info, true); err != nil {
+		t.Fatal(err)
+	}
+	var got Info
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("Print() did not write JSON: %v\n%s", err, out.String())
+	}
+	if got != info {
+		t.Errorf("Print() JSON = %+v, want %+v", got, info)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versionbump
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/generate"
+	"github.com/google/go-github/v81/github"
+	"golang.org/x/mod/semver"
+	"k8s.io/klog/v2"
+)
+
+// Repositories is the part of the GitHub API used to find the latest release of an action.
+type Repositories interface {
+	GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error)
+	GetCommitSHA1(ctx context.Context, owner, repo, ref, lastSHA string) (string, *github.Response, error)
+}
+
+// ActionOptions configures BumpActions.
+type ActionOptions struct {
+	// Pin pins every action to the commit of its release, with the tag in a comment.
+	// Actions that are already pinned stay pinned either way.
+	Pin bool
+
+	// Repositories looks up the releases of the actions.
+	Repositories Repositories
+}
+
+// usesRegex matches the uses entries of workflow steps that refer to an action in a repository, e.g.
+// "uses: actions/checkout@v4" or "uses: actions/checkout@08c6903cd8c0fde910a37f88322edcfb5dd907a8 # v5.0.0".
+// Local actions (./path) and docker:// images have no @ref and are not matched.
+var usesRegex = regexp.MustCompile(`(?m)^([ \t]*(?:-[ \t]+)?uses:[ \t]+)([\w.-]+/[\w./-]+)@([\w.-]+)(?:[ \t]+#[ \t]*(.*?))?[ \t]*$`)
+
+// shaRegex matches a full commit SHA.
+var shaRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// BumpActions updates the actions used by the GitHub Actions workflows of repoRoot to their latest release.
+// A ref that is a major (v4) or minor (v4.2) version tag is bumped to the same precision, a pinned commit
+// (with its tag in a comment) to the commit of the latest release, and refs that are not versions (e.g. main) are left alone.
+// The versions of the actions used by the generated workflow are also written to .ap/ci.yaml, so that ap generate keeps them.
+func BumpActions(ctx context.Context, repoRoot string, opt ActionOptions) error {
+	b := &actionBumper{
+		ActionOptions: opt,
+		releases:      map[string]string{},
+	}
+
+	if err := b.bumpCIConfig(ctx, repoRoot); err != nil {
+		return err
+	}
+
+	var workflows []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(repoRoot, ".github", "workflows", pattern))
+		if err != nil {
+			return err
+		}
+		workflows = append(workflows, matches...)
+	}
+	slices.Sort(workflows)
+
+	for _, workflow := range workflows {
+		content, err := os.ReadFile(workflow)
+		if err != nil {
+			return err
+		}
+		newContent, err := b.bumpWorkflow(ctx, content)
+		if err != nil {
+			return fmt.Errorf("failed to bump %s: %w", workflow, err)
+		}
+		if string(newContent) != string(content) {
+			klog.Infof("Updating %s", workflow)
+			if err := os.WriteFile(workflow, newContent, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// actionBumper bumps the versions of actions, looking up each repository's latest release once.
+type actionBumper struct {
+	ActionOptions
+
+	// releases holds the tag of the latest release of each repository, or "" if it has no usable release.
+	releases map[string]string
+}
+
+// bumpWorkflow returns content with every uses entry bumped.
+func (b *actionBumper) bumpWorkflow(ctx context.Context, content []byte) ([]byte, error) {
+	var bumpErr error
+	newContent := usesRegex.ReplaceAllStringFunc(string(content), func(match string) string {
+		m := usesRegex.FindStringSubmatch(match)
+		if bumpErr != nil {
+			return match
+		}
+		version, err := b.bump(ctx, m[2], m[3], m[4])
+		if err != nil {
+			bumpErr = err
+			return match
+		}
+		return m[1] + m[2] + "@" + version
+	})
+	if bumpErr != nil {
+		return nil, bumpErr
+	}
+	return []byte(newContent), nil
+}
+
+// bumpCIConfig bumps the versions of the actions used by the generated workflow, writing the ones that
+// differ from their defaults to the actions of .ap/ci.yaml.
+func (b *actionBumper) bumpCIConfig(ctx context.Context, repoRoot string) error {
+	cfg, err := generate.LoadCIConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Backends) > 0 && !slices.Contains(cfg.Backends, generate.BackendGitHubActions) {
+		return nil
+	}
+
+	actions := map[string]string{}
+	changed := false
+	for action, defaultVersion := range generate.DefaultActions() {
+		current, configured := cfg.Actions[action]
+		if !configured {
+			current = defaultVersion
+		}
+		ref, comment, _ := strings.Cut(current, "#")
+		version, err := b.bump(ctx, action, strings.TrimSpace(ref), strings.TrimSpace(comment))
+		if err != nil {
+			return err
+		}
+		if version != strings.TrimSpace(current) {
+			changed = true
+		}
+		if configured || version != defaultVersion {
+			actions[action] = version
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	configFile := filepath.Join(repoRoot, ".ap", "ci.yaml")
+	content, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	klog.Infof("Updating %s", configFile)
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, setActions(content, actions), 0644)
+}
+
+// bump returns the version that a uses entry of action at ref (with comment, if any) is bumped to,
+// e.g. "v5" for "v4", or the commit of the latest release followed by " # " and its tag for a pinned ref.
+func (b *actionBumper) bump(ctx context.Context, action, ref, comment string) (string, error) {
+	// The comment of a ref that is not pinned is not a tag, and is kept.
+	withComment := func(version string) string {
+		if comment != "" {
+			return version + " # " + comment
+		}
+		return version
+	}
+	unchanged := withComment(ref)
+
+	pinned := shaRegex.MatchString(ref)
+	current := ref
+	if pinned {
+		current = comment
+	}
+	if !semver.IsValid(current) {
+		return unchanged, nil
+	}
+
+	owner, repo, _ := strings.Cut(action, "/")
+	repo, _, _ = strings.Cut(repo, "/")
+	latest, err := b.latestRelease(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	if latest == "" || semver.Compare(latest, current) < 0 {
+		return unchanged, nil
+	}
+
+	if pinned || b.Pin {
+		if pinned && latest == current {
+			return unchanged, nil
+		}
+		sha, _, err := b.Repositories.GetCommitSHA1(ctx, owner, repo, "refs/tags/"+latest, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to find the commit of %s/%s %s: %w", owner, repo, latest, err)
+		}
+		return sha + " # " + latest, nil
+	}
+
+	switch current {
+	case semver.Major(current):
+		return withComment(semver.Major(latest)), nil
+	case semver.MajorMinor(current):
+		return withComment(semver.MajorMinor(latest)), nil
+	}
+	return withComment(latest), nil
+}
+
+// latestRelease returns the tag of the latest release of owner/repo, or "" if it has no release with a semver tag.
+func (b *actionBumper) latestRelease(ctx context.Context, owner, repo string) (string, error) {
+	key := owner + "/" + repo
+	if tag, ok := b.releases[key]; ok {
+		return tag, nil
+	}
+	release, resp, err := b.Repositories.GetLatestRelease(ctx, owner, repo)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		klog.Warningf("%s has no releases; leaving its version unchanged", key)
+		b.releases[key] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find the latest release of %s: %w", key, err)
+	}
+	tag := release.GetTagName()
+	if !semver.IsValid(tag) {
+		klog.Warningf("The latest release of %s has tag %q, which is not a version; leaving its version unchanged", key, tag)
+		tag = ""
+	}
+	b.releases[key] = tag
+	return tag, nil
+}
+
+// setActions returns the content of .ap/ci.yaml with its actions replaced by actions.
+// The other settings are kept as written, with their comments.
+func setActions(content []byte, actions map[string]string) []byte {
+	var block strings.Builder
+	if len(actions) > 0 {
+		block.WriteString("actions:\n")
+		keys := make([]string, 0, len(actions))
+		for action := range actions {
+			keys = append(keys, action)
+		}
+		slices.Sort(keys)
+		for _, action := range keys {
+			version := actions[action]
+			if strings.Contains(version, "#") {
+				version = strconv.Quote(version)
+			}
+			fmt.Fprintf(&block, "  %s: %s\n", action, version)
+		}
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	start := slices.IndexFunc(lines, func(line string) bool {
+		return strings.HasPrefix(line, "actions:")
+	})
+	if start < 0 {
+		out := string(content)
+		if out != "" && !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		return []byte(out + block.String())
+	}
+	end := start + 1
+	for end < len(lines) && (strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t")) {
+		end++
+	}
+	return []byte(strings.Join(lines[:start], "") + block.String() + strings.Join(lines[end:], ""))
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versionbump
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// fakeRepositories serves the latest releases and tag commits of a fixed set of repositories.
+type fakeRepositories struct {
+	// latest is the tag of the latest release, by owner/repo.
+	latest map[string]string
+	// commits is the commit of each tag, by owner/repo@tag.
+	commits map[string]string
+	// lookups counts the latest release lookups.
+	lookups int
+}
+
+func (f *fakeRepositories) GetLatestRelease(_ context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error) {
+	f.lookups++
+	tag, ok := f.latest[owner+"/"+repo]
+	if !ok {
+		resp := &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		return nil, resp, &github.ErrorResponse{Response: resp.Response, Message: "Not Found"}
+	}
+	return &github.RepositoryRelease{TagName: github.Ptr(tag)}, nil, nil
+}
+
+func (f *fakeRepositories) GetCommitSHA1(_ context.Context, owner, repo, ref, _ string) (string, *github.Response, error) {
+	return f.commits[owner+"/"+repo+"@"+ref], nil, nil
+}
+
+const (
+	checkoutV5SHA = "08c6903cd8c0fde910a37f88322edcfb5dd907a8"
+	setupGoV6SHA  = "44694675825211faa026b3c33043df3e48a5fa00"
+)
+
+func newFakeRepositories() *fakeRepositories {
+	return &fakeRepositories{
+		latest: map[string]string{
+			"actions/checkout":        "v5.0.0",
+			"actions/setup-go":        "v6.0.0",
+			"actions/cache":           "v4.2.4",
+			"actions/upload-artifact": "v4.6.2",
+			"github/codeql-action":    "v3.30.1",
+			"example/unreleased":      "nightly",
+		},
+		commits: map[string]string{
+			"actions/checkout@refs/tags/v5.0.0": checkoutV5SHA,
+			"actions/setup-go@refs/tags/v6.0.0": setupGoV6SHA,
+		},
+	}
+}
+
+func TestBumpWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     bool
+		content string
+		want    string
+	}{
+		{
+			name:    "major version",
+			content: "      - uses: actions/checkout@v4\n",
+			want:    "      - uses: actions/checkout@v5\n",
+		},
+		{
+			name:    "full version",
+			content: "        uses: github/codeql-action/init@v3.28.0\n",
+			want:    "        uses: github/codeql-action/init@v3.30.1\n",
+		},
+		{
+			name:    "minor version",
+			content: "        uses: github/codeql-action/init@v3.28\n",
+			want:    "        uses: github/codeql-action/init@v3.30\n",
+		},
+		{
+			name:    "up to date",
+			content: "        uses: actions/cache@v4\n",
+			want:    "        uses: actions/cache@v4\n",
+		},
+		{
+			name:    "comment kept",
+			content: "        uses: actions/checkout@v4 # see #123\n",
+			want:    "        uses: actions/checkout@v5 # see #123\n",
+		},
+		{
+			name:    "pinned",
+			content: "        uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4.2.2\n",
+			want:    "        uses: actions/checkout@" + checkoutV5SHA + " # v5.0.0\n",
+		},
+		{
+			name:    "pin",
+			pin:     true,
+			content: "        uses: actions/setup-go@v5\n",
+			want:    "        uses: actions/setup-go@" + setupGoV6SHA + " # v6.0.0\n",
+		},
+		{
+			name:    "branch",
+			content: "        uses: actions/checkout@main\n",
+			want:    "        uses: actions/checkout@main\n",
+		},
+		{
+			name:    "no semver release",
+			content: "        uses: example/unreleased@v1\n",
+			want:    "        uses: example/unreleased@v1\n",
+		},
+		{
+			name:    "no release",
+			content: "        uses: example/missing@v1\n",
+			want:    "        uses: example/missing@v1\n",
+		},
+		{
+			name:    "local action",
+			content: "        uses: ./.github/actions/setup\n",
+			want:    "        uses: ./.github/actions/setup\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &actionBumper{
+				ActionOptions: ActionOptions{Pin: tt.pin, Repositories: newFakeRepositories()},
+				releases:      map[string]string{},
+			}
+			got, err := b.bumpWorkflow(context.Background(), []byte(tt.content))
+			if err != nil {
+				t.Fatalf("bumpWorkflow() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("bumpWorkflow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetActions(t *testing.T) {
+	actions := map[string]string{
+		"actions/setup-go": "v6",
+		"actions/checkout": checkoutV5SHA + " # v5.0.0",
+	}
+	block := "actions:\n  actions/checkout: \"" + checkoutV5SHA + " # v5.0.0\"\n  actions/setup-go: v6\n"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "new file",
+			content: "",
+			want:    block,
+		},
+		{
+			name:    "appended",
+			content: "# CI settings\ncache: false",
+			want:    "# CI settings\ncache: false\n" + block,
+		},
+		{
+			name:    "replaced",
+			content: "cache: false\nactions:\n  actions/checkout: v4\nmatrix:\n  go: [stable]\n",
+			want:    "cache: false\n" + block + "matrix:\n  go: [stable]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(setActions([]byte(tt.content), actions)); got != tt.want {
+				t.Errorf("setActions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpActions(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".ap/ci.yaml":                          "cache: false\nactions:\n  actions/setup-go: v5\n",
+		".github/workflows/ci-presubmits.yaml": "steps:\n  - uses: actions/checkout@v4\n  - uses: actions/setup-go@v5\n",
+		".github/workflows/release.yml":        "steps:\n  - uses: actions/checkout@v4\n  - uses: actions/upload-artifact@v4\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos := newFakeRepositories()
+	if err := BumpActions(context.Background(), root, ActionOptions{Repositories: repos}); err != nil {
+		t.Fatalf("BumpActions() error = %v", err)
+	}
+
+	want := map[string]string{
+		".ap/ci.yaml":                          "cache: false\nactions:\n  actions/checkout: v5\n  actions/setup-go: v6\n",
+		".github/workflows/ci-presubmits.yaml": "steps:\n  - uses: actions/checkout@v5\n  - uses: actions/setup-go@v6\n",
+		".github/workflows/release.yml":        "steps:\n  - uses: actions/checkout@v5\n  - uses: actions/upload-artifact@v4\n",
+	}
+	for name, wantContent := range want {
+		got, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != wantContent {
+			t.Errorf("%s = %q, want %q", name, got, wantContent)
+		}
+	}
+	if repos.lookups != 4 {
+		t.Errorf("looked up %d latest releases, want 4 (one per repository)", repos.lookups)
+	}
+}
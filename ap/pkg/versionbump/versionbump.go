@@ -19,13 +19,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/download"
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/images"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
 	"k8s.io/klog/v2"
 )
@@ -64,29 +64,27 @@ func Run(ctx context.Context, root string) error {
 		}
 	}
 
+	// The base images are pinned to their current digests once the Dockerfiles refer to the new versions.
+	if err := images.UpdateLock(ctx, root); err != nil {
+		errs = append(errs, fmt.Errorf("failed to update the digests of the base images: %w", err))
+	}
+
 	return errors.Join(errs...)
 }
 
 func fetchLatestGoVersion(ctx context.Context) (string, error) {
-	url := "https://go.dev/dl/?mode=json"
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client, err := download.NewFromEnv()
 	if err != nil {
 		return "", err
 	}
-
-	resp, err := http.DefaultClient.Do(req)
+	url := "https://go.dev/dl/?mode=json"
+	data, err := client.Get(ctx, download.Resource{URL: url})
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch %s: %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("unexpected status code %d fetching %s: %s", resp.StatusCode, url, string(body))
+		return "", err
 	}
 
 	var versions []GoVersion
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+	if err := json.Unmarshal(data, &versions); err != nil {
 		return "", fmt.Errorf("failed to decode JSON from %s: %w", url, err)
 	}
 
@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package worktree detects the files changed in a directory tree by a step, such as a fixer or a test run.
+package worktree
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// skipDirs are not considered when looking for changed files.
+var skipDirs = map[string]bool{
+	".git":         true,
+	".build":       true,
+	"node_modules": true,
+}
+
+// Snapshot records the content hash and mode of each regular file in a tree, keyed by slash-separated relative path.
+type Snapshot map[string]string
+
+// Change is a file that differs between two snapshots.
+type Change struct {
+	// Path is the slash-separated path of the file, relative to the root of the tree.
+	Path string
+	// Kind is one of added, modified or deleted.
+	Kind string
+}
+
+// Take returns a snapshot of the regular files under root, skipping .git, .build and node_modules directories.
+func Take(root string) (Snapshot, error) {
+	files := make(Snapshot)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && skipDirs[d.Name()] && path != root {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = fmt.Sprintf("%x %o", sha256.Sum256(data), info.Mode().Perm())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// Changes returns the files that differ between before and after, sorted by path.
+func Changes(before, after Snapshot) []Change {
+	var changes []Change
+	for path, hash := range before {
+		if afterHash, ok := after[path]; !ok {
+			changes = append(changes, Change{Path: path, Kind: "deleted"})
+		} else if afterHash != hash {
+			changes = append(changes, Change{Path: path, Kind: "modified"})
+		}
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, Change{Path: path, Kind: "added"})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "a")
+	write("pkg/b.go", "b")
+	write("pkg/c.go", "c")
+
+	before, err := Take(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("pkg/b.go", "changed")
+	if err := os.Remove(filepath.Join(root, "pkg/c.go")); err != nil {
+		t.Fatal(err)
+	}
+	write("pkg/testdata/out.txt", "new")
+	write(".build/test-results/go/root.json", "ignored")
+	if err := os.Chmod(filepath.Join(root, "a.go"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	after, err := Take(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Path: "a.go", Kind: "modified"},
+		{Path: "pkg/b.go", Kind: "modified"},
+		{Path: "pkg/c.go", Kind: "deleted"},
+		{Path: "pkg/testdata/out.txt", Kind: "added"},
+	}
+	if got := Changes(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %v, want %v", got, want)
+	}
+	if got := Changes(after, after); len(got) != 0 {
+		t.Errorf("Changes() of identical snapshots = %v, want none", got)
+	}
+}
@@ -18,45 +18,95 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/repo"
 )
 
 type Caches struct {
 	Metadata map[string]*FileMetadata `json:"metadata"`
-	Gofmt    map[string]bool          `json:"gofmt"`
+	Images   map[string]string        `json:"images"`
+	// Results records the results of each tool for the inputs it has processed, keyed by tool and input hash.
+	Results map[string]*Result `json:"results"`
+}
+
+// Result is what a tool found in an input, replayed when the tool runs again over the same input.
+type Result struct {
+	// Findings are the findings the tool reported.
+	Findings []findings.Finding `json:"findings,omitempty"`
 }
 
+// DisableEnv names the environment variable that, when "off", disables the cache: nothing is read from it or
+// written to it, so every tool processes all of its inputs.
+const DisableEnv = "AP_CACHE"
+
+// obsoleteFiles are the files of a namespace that are no longer used.
+var obsoleteFiles = []string{"gofmt.json", "formatted.json"}
+
 type Manager struct {
 	dir    string
+	root   string
 	caches *Caches
 	mu     sync.Mutex
+	// disabled is set when the cache is turned off with DisableEnv; the manager then only lasts for the process.
+	disabled bool
 }
 
-func NewManager() (*Manager, error) {
-	cacheDir, err := os.UserCacheDir()
+// NewManager returns a manager of the cache of the repository at repoRoot, or containing the directory repoRoot.
+// Each repository (checkout) has its own namespace in the cache, keyed by a hash of its root.
+func NewManager(repoRoot string) (*Manager, error) {
+	base, err := Dir()
 	if err != nil {
 		return nil, err
 	}
-	dir := filepath.Join(cacheDir, "ap", "codestyle")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, err
+	root, err := repo.FindRoot(repoRoot)
+	if err != nil {
+		// Outside of a git repository, the directory is the namespace.
+		root, err = filepath.Abs(repoRoot)
+		if err != nil {
+			return nil, err
+		}
 	}
+	dir := filepath.Join(base, reposDir, namespaceKey(root))
 
 	m := &Manager{
-		dir: dir,
+		dir:  dir,
+		root: root,
 		caches: &Caches{
 			Metadata: make(map[string]*FileMetadata),
-			Gofmt:    make(map[string]bool),
+			Images:   make(map[string]string),
+			Results:  make(map[string]*Result),
 		},
+		disabled: os.Getenv(DisableEnv) == "off",
+	}
+	if m.disabled {
+		return m, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
 	}
 	// Ignore errors on load (start fresh)
 	_ = m.load()
 	return m, nil
 }
 
+// Dir returns the directory of the cache, which holds a namespace for each repository.
+func Dir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "ap", "codestyle"), nil
+}
+
 func (m *Manager) load() error {
 	metaPath := filepath.Join(m.dir, "metadata.json")
 	if data, err := os.ReadFile(metaPath); err == nil {
@@ -66,11 +116,19 @@ func (m *Manager) load() error {
 		}
 	}
 
-	gofmtPath := filepath.Join(m.dir, "gofmt.json")
-	if data, err := os.ReadFile(gofmtPath); err == nil {
-		var gofmt map[string]bool
-		if err := json.Unmarshal(data, &gofmt); err == nil {
-			m.caches.Gofmt = gofmt
+	imagesPath := filepath.Join(m.dir, "images.json")
+	if data, err := os.ReadFile(imagesPath); err == nil {
+		var images map[string]string
+		if err := json.Unmarshal(data, &images); err == nil && images != nil {
+			m.caches.Images = images
+		}
+	}
+
+	resultsPath := filepath.Join(m.dir, "results.json")
+	if data, err := os.ReadFile(resultsPath); err == nil {
+		var results map[string]*Result
+		if err := json.Unmarshal(data, &results); err == nil && results != nil {
+			m.caches.Results = results
 		}
 	}
 	return nil
@@ -79,6 +137,9 @@ func (m *Manager) load() error {
 func (m *Manager) Save() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.disabled {
+		return nil
+	}
 
 	metaPath := filepath.Join(m.dir, "metadata.json")
 	metaData, err := json.MarshalIndent(m.caches.Metadata, "", "  ")
@@ -89,15 +150,34 @@ func (m *Manager) Save() error {
 		return err
 	}
 
-	gofmtPath := filepath.Join(m.dir, "gofmt.json")
-	gofmtData, err := json.MarshalIndent(m.caches.Gofmt, "", "  ")
+	imagesPath := filepath.Join(m.dir, "images.json")
+	imagesData, err := json.MarshalIndent(m.caches.Images, "", "  ")
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(gofmtPath, gofmtData, 0644); err != nil {
+	if err := os.WriteFile(imagesPath, imagesData, 0644); err != nil {
 		return err
 	}
-	return nil
+
+	resultsPath := filepath.Join(m.dir, "results.json")
+	resultsData, err := json.MarshalIndent(m.caches.Results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(resultsPath, resultsData, 0644); err != nil {
+		return err
+	}
+	for _, name := range obsoleteFiles {
+		if err := os.Remove(filepath.Join(m.dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	// Saving marks the namespace as used, and is when the other namespaces are evicted once in a while.
+	if err := writeNamespaceInfo(m.dir, namespaceInfo{Root: m.root, LastUsed: now()}); err != nil {
+		return err
+	}
+	return autoPrune(filepath.Dir(filepath.Dir(m.dir)), m.dir)
 }
 
 // GetOrUpdateMetadata returns the FileMetadata with Hash populated.
@@ -128,16 +208,65 @@ func (m *Manager) GetOrUpdateMetadata(path string) (*FileMetadata, error) {
 	return current, nil
 }
 
-func (m *Manager) IsGofmtDone(hash string) bool {
+// Done returns the result of tool for the input with the given hash, if tool has already processed it.
+// The hash must cover everything the result depends on, e.g. with Key.
+func (m *Manager) Done(tool, hash string) (*Result, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result, ok := m.caches.Results[tool+":"+hash]
+	return result, ok
+}
+
+// MarkDone records the result of tool for the input with the given hash; a nil result is an empty one.
+func (m *Manager) MarkDone(tool, hash string, result *Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if result == nil {
+		result = &Result{}
+	}
+	m.caches.Results[tool+":"+hash] = result
+}
+
+// ResultKeys returns the keys of the recorded results, sorted. A key is the tool and the input hash of
+// Done and MarkDone; as the hash is content-addressed, results can be shared between caches by key.
+func (m *Manager) ResultKeys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.caches.Results))
+	for key := range m.caches.Results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ResultByKey returns the recorded result with the given key, as returned by ResultKeys.
+func (m *Manager) ResultByKey(key string) (*Result, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result, ok := m.caches.Results[key]
+	return result, ok
+}
+
+// SetResultByKey records a result with the given key, e.g. one copied from another cache.
+func (m *Manager) SetResultByKey(key string, result *Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.caches.Results[key] = result
+}
+
+func (m *Manager) GetImageDigest(key string) (string, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.caches.Gofmt[hash]
+	digest, ok := m.caches.Images[key]
+	return digest, ok
 }
 
-func (m *Manager) MarkGofmtDone(hash string) {
+// SetImageDigest records the digest of the image pushed for the given build key.
+func (m *Manager) SetImageDigest(key string, digest string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.caches.Gofmt[hash] = true
+	m.caches.Images[key] = digest
 }
 
 func hashFile(path string) (string, error) {
@@ -153,3 +282,20 @@ func hashFile(path string) (string, error) {
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
+
+// Key returns a hash of parts, for inputs made of several parts such as the hashes of files, a configuration
+// and the version of a tool.
+func Key(parts ...string) string {
+	h := sha256.New()
+	io.WriteString(h, strings.Join(parts, "\x00"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ExecutableHash returns a hash of the running executable, for results that depend on the checks built into it.
+var ExecutableHash = sync.OnceValues(func() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return hashFile(path)
+})
@@ -0,0 +1,224 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// reposDir is the directory of the cache holding the namespace of each repository.
+	reposDir = "repos"
+	// namespaceFile records the repository root and last use of a namespace.
+	namespaceFile = "namespace.json"
+	// prunedFile is touched whenever the cache is pruned automatically.
+	prunedFile = "pruned"
+)
+
+// legacyFiles are the files of the cache from before it had a namespace per repository.
+var legacyFiles = []string{"metadata.json", "gofmt.json", "images.json", "formatted.json"}
+
+// PruneInterval is how often saving the cache also prunes it, with DefaultPruneOptions.
+const PruneInterval = 24 * time.Hour
+
+// DefaultPruneOptions evict the namespaces unused for 30 days, and the least recently used ones beyond 512MB.
+var DefaultPruneOptions = PruneOptions{
+	MaxAge:  30 * 24 * time.Hour,
+	MaxSize: 512 << 20,
+}
+
+// now is overridden in tests.
+var now = time.Now
+
+// Namespace is the cache of one repository.
+type Namespace struct {
+	// Root is the root of the repository, or "" if it is not known.
+	Root string
+	// Dir is the directory holding the namespace.
+	Dir string
+	// LastUsed is when the cache of the repository was last saved.
+	LastUsed time.Time
+	// Size is the total size of the files of the namespace, in bytes.
+	Size int64
+}
+
+// namespaceInfo is the content of namespaceFile.
+type namespaceInfo struct {
+	Root     string    `json:"root"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// namespaceKey returns the name of the namespace directory of the repository at root.
+func namespaceKey(root string) string {
+	h := sha256.Sum256([]byte(root))
+	return hex.EncodeToString(h[:8])
+}
+
+func writeNamespaceInfo(dir string, info namespaceInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, namespaceFile), data, 0644)
+}
+
+// Namespaces returns the namespaces of the cache, most recently used first.
+func Namespaces() ([]Namespace, error) {
+	base, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return namespaces(base)
+}
+
+func namespaces(base string) ([]Namespace, error) {
+	entries, err := os.ReadDir(filepath.Join(base, reposDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var all []Namespace
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ns := Namespace{Dir: filepath.Join(base, reposDir, entry.Name())}
+		var info namespaceInfo
+		if data, err := os.ReadFile(filepath.Join(ns.Dir, namespaceFile)); err == nil && json.Unmarshal(data, &info) == nil {
+			ns.Root = info.Root
+			ns.LastUsed = info.LastUsed
+		}
+		err := filepath.WalkDir(ns.Dir, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			ns.Size += fi.Size()
+			// A namespace that was never saved is as old as its files.
+			if info.LastUsed.IsZero() && fi.ModTime().After(ns.LastUsed) {
+				ns.LastUsed = fi.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ns)
+	}
+	slices.SortFunc(all, func(a, b Namespace) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+	return all, nil
+}
+
+// PruneOptions configure which namespaces Prune evicts.
+type PruneOptions struct {
+	// MaxAge evicts the namespaces not used for longer; zero keeps them regardless of age.
+	MaxAge time.Duration
+	// MaxSize evicts the least recently used namespaces until the cache is no larger (in bytes); zero means no limit.
+	MaxSize int64
+	// DryRun returns the namespaces that would be evicted, without removing them.
+	DryRun bool
+}
+
+// Prune evicts the namespaces of repositories that no longer exist, those unused for longer than opt.MaxAge,
+// and then the least recently used ones until the cache fits in opt.MaxSize. It returns the evicted namespaces.
+// The files of the cache from before it had namespaces are removed too.
+func Prune(opt PruneOptions) ([]Namespace, error) {
+	base, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return prune(base, opt, "")
+}
+
+// prune evicts namespaces from the cache at base, except the one in keep.
+func prune(base string, opt PruneOptions, keep string) ([]Namespace, error) {
+	all, err := namespaces(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, ns := range all {
+		total += ns.Size
+	}
+	evict := func(ns Namespace) bool {
+		if ns.Dir == keep {
+			return false
+		}
+		if ns.Root == "" {
+			return true
+		}
+		if _, err := os.Stat(ns.Root); errors.Is(err, fs.ErrNotExist) {
+			return true
+		}
+		return opt.MaxAge > 0 && now().Sub(ns.LastUsed) > opt.MaxAge
+	}
+
+	var evicted []Namespace
+	// Oldest first, so that the size limit evicts the least recently used.
+	for _, ns := range slices.Backward(all) {
+		if evict(ns) || (opt.MaxSize > 0 && total > opt.MaxSize && ns.Dir != keep) {
+			evicted = append(evicted, ns)
+			total -= ns.Size
+		}
+	}
+	if opt.DryRun {
+		return evicted, nil
+	}
+
+	for _, ns := range evicted {
+		if err := os.RemoveAll(ns.Dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range legacyFiles {
+		if err := os.Remove(filepath.Join(base, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return evicted, nil
+}
+
+// autoPrune prunes the cache at base with DefaultPruneOptions, keeping the namespace in keep,
+// unless it was pruned within PruneInterval.
+func autoPrune(base, keep string) error {
+	stamp := filepath.Join(base, prunedFile)
+	if fi, err := os.Stat(stamp); err == nil && now().Sub(fi.ModTime()) < PruneInterval {
+		return nil
+	}
+	if _, err := prune(base, DefaultPruneOptions, keep); err != nil {
+		return err
+	}
+	if err := os.WriteFile(stamp, nil, 0644); err != nil {
+		return err
+	}
+	t := now()
+	return os.Chtimes(stamp, t, t)
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// saveAt saves a cache of the repository at root with one image digest, at the given time.
+func saveAt(t *testing.T, root string, at time.Time) *Manager {
+	t.Helper()
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+
+	m, err := NewManager(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetImageDigest("key", "sha256:"+root)
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestNamespaces(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	repoA, repoB := t.TempDir(), t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	saveAt(t, repoA, start)
+	saveAt(t, repoB, start.Add(time.Hour))
+
+	m, err := NewManager(repoA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest, _ := m.GetImageDigest("key"); digest != "sha256:"+repoA {
+		t.Errorf("GetImageDigest() = %q, want the digest saved for %s", digest, repoA)
+	}
+
+	all, err := Namespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Root != repoB || all[1].Root != repoA {
+		t.Fatalf("Namespaces() = %+v, want %s then %s", all, repoB, repoA)
+	}
+	if !all[0].LastUsed.Equal(start.Add(time.Hour)) || all[0].Size == 0 {
+		t.Errorf("Namespaces()[0] = %+v, want last used at %v with a size", all[0], start.Add(time.Hour))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opt  PruneOptions
+		// want are the indexes of the repositories whose namespaces are evicted.
+		want []int
+	}{
+		{name: "nothing", opt: PruneOptions{}, want: []int{2}},
+		{name: "max age", opt: PruneOptions{MaxAge: 150 * time.Minute}, want: []int{0, 2}},
+		{name: "max size", opt: PruneOptions{MaxSize: 1}, want: []int{0, 1, 2, 3}},
+		{name: "dry run", opt: PruneOptions{MaxAge: 150 * time.Minute, DryRun: true}, want: []int{0, 2}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheHome := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", cacheHome)
+			base := filepath.Join(cacheHome, "ap", "codestyle")
+
+			// Repositories used an hour apart, so that saving does not prune them; the third no longer exists.
+			roots := []string{t.TempDir(), t.TempDir(), filepath.Join(t.TempDir(), "deleted"), t.TempDir()}
+			var dirs []string
+			for i, root := range roots {
+				dirs = append(dirs, saveAt(t, root, start.Add(time.Duration(i)*time.Hour)).dir)
+			}
+			if err := os.WriteFile(filepath.Join(base, "gofmt.json"), []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			now = func() time.Time { return start.Add(3 * time.Hour) }
+
+			evicted, err := Prune(tc.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, ns := range evicted {
+				got = append(got, slices.Index(dirs, ns.Dir))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Prune() evicted %v, want %v", got, tc.want)
+			}
+
+			for _, i := range tc.want {
+				_, err := os.Stat(dirs[i])
+				if removed := os.IsNotExist(err); removed == tc.opt.DryRun {
+					t.Errorf("namespace of repository %d removed: %v, want %v", i, removed, !tc.opt.DryRun)
+				}
+			}
+			_, err = os.Stat(filepath.Join(base, "gofmt.json"))
+			if removed := os.IsNotExist(err); removed == tc.opt.DryRun {
+				t.Errorf("legacy cache file removed: %v, want %v", removed, !tc.opt.DryRun)
+			}
+		})
+	}
+}
+
+func TestSaveKeepsOwnNamespace(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := saveAt(t, filepath.Join(t.TempDir(), "deleted"), start)
+
+	// The first save in a day prunes the other namespaces.
+	current := saveAt(t, t.TempDir(), start.Add(PruneInterval))
+	if _, err := os.Stat(old.dir); !os.IsNotExist(err) {
+		t.Errorf("namespace of a deleted repository was not pruned on save")
+	}
+	if _, err := os.Stat(current.dir); err != nil {
+		t.Errorf("namespace being saved was pruned: %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	repo := t.TempDir()
+	saveAt(t, repo, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	t.Setenv(DisableEnv, "off")
+	m, err := NewManager(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest, ok := m.GetImageDigest("key"); ok {
+		t.Errorf("GetImageDigest() = %q with the cache off, want nothing read", digest)
+	}
+	m.SetImageDigest("key", "sha256:new")
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(DisableEnv, "")
+	m, err = NewManager(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest, _ := m.GetImageDigest("key"); digest != "sha256:"+repo {
+		t.Errorf("GetImageDigest() = %q, want the digest saved before the cache was turned off", digest)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cobracmd checks that cobra commands follow our CLI conventions:
+// they use RunE rather than Run, propagate cmd.Context() rather than creating a new context,
+// and mark required flags with MarkFlagRequired rather than checking them by hand.
+package cobracmd
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const cobraPath = "github.com/spf13/cobra"
+
+var Analyzer = &analysis.Analyzer{
+	Name: "cobracmd",
+	Doc:  "check that cobra commands use RunE, propagate cmd.Context() and mark required flags",
+	Run:  run,
+}
+
+// errorReturningFields maps the cobra.Command hooks to the variants that return an error.
+var errorReturningFields = map[string]string{
+	"Run":               "RunE",
+	"PreRun":            "PreRunE",
+	"PostRun":           "PostRunE",
+	"PersistentPreRun":  "PersistentPreRunE",
+	"PersistentPostRun": "PersistentPostRunE",
+}
+
+// requiredFlagMessage matches hand-written checks such as fmt.Errorf("--config is required").
+var requiredFlagMessage = regexp.MustCompile(`^--([a-zA-Z0-9-]+) is required$`)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	// Required flags are usually defined in the Build*Command function but checked in the Run* function,
+	// so we collect them across the whole package before reporting.
+	definedFlags := make(map[string]bool)
+	markedFlags := make(map[string]bool)
+	var requiredChecks []requiredCheck
+
+	for _, f := range pass.Files {
+		v := &visitor{
+			pass:           pass,
+			definedFlags:   definedFlags,
+			markedFlags:    markedFlags,
+			requiredChecks: &requiredChecks,
+		}
+		ast.Walk(v, f)
+	}
+
+	for _, check := range requiredChecks {
+		if definedFlags[check.flag] && !markedFlags[check.flag] {
+			pass.Reportf(check.pos.Pos(), "flag --%s should be marked with cmd.MarkFlagRequired(%q)", check.flag, check.flag)
+		}
+	}
+	return nil, nil
+}
+
+type requiredCheck struct {
+	pos  ast.Node
+	flag string
+}
+
+type visitor struct {
+	pass *analysis.Pass
+
+	// currentFuncHasCmd is true inside a function that has a *cobra.Command parameter.
+	currentFuncHasCmd bool
+
+	definedFlags   map[string]bool
+	markedFlags    map[string]bool
+	requiredChecks *[]requiredCheck
+}
+
+func (v *visitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		v.walkFunc(n.Type, n.Body)
+		return nil
+	case *ast.FuncLit:
+		v.walkFunc(n.Type, n.Body)
+		return nil
+	case *ast.CompositeLit:
+		v.checkCompositeLit(n)
+	case *ast.CallExpr:
+		v.checkCall(n)
+	}
+
+	return v
+}
+
+func (v *visitor) walkFunc(typ *ast.FuncType, body *ast.BlockStmt) {
+	if body == nil {
+		return
+	}
+	oldHasCmd := v.currentFuncHasCmd
+	v.currentFuncHasCmd = hasCobraCommand(v.pass, typ.Params)
+	ast.Walk(v, body)
+	v.currentFuncHasCmd = oldHasCmd
+}
+
+func (v *visitor) checkCompositeLit(lit *ast.CompositeLit) {
+	if !isCobraType(v.pass.TypesInfo.TypeOf(lit), "Command") {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if replacement, ok := errorReturningFields[key.Name]; ok {
+			v.pass.Reportf(kv.Pos(), "use %s instead of %s, so that errors are returned rather than handled in place", replacement, key.Name)
+		}
+	}
+}
+
+func (v *visitor) checkCall(call *ast.CallExpr) {
+	fn := typeFunc(v.pass, call)
+	if fn == nil || fn.Pkg() == nil {
+		return
+	}
+
+	switch fn.Pkg().Path() {
+	case "context":
+		if (fn.Name() == "Background" || fn.Name() == "TODO") && v.currentFuncHasCmd {
+			v.pass.Reportf(call.Pos(), "use cmd.Context() instead of context.%s()", fn.Name())
+		}
+	case "fmt", "errors":
+		if (fn.Name() == "Errorf" || fn.Name() == "New") && len(call.Args) > 0 {
+			if m := requiredFlagMessage.FindStringSubmatch(stringValue(v.pass, call.Args[0])); m != nil {
+				*v.requiredChecks = append(*v.requiredChecks, requiredCheck{pos: call, flag: m[1]})
+			}
+		}
+	case cobraPath:
+		switch fn.Name() {
+		case "MarkFlagRequired", "MarkPersistentFlagRequired":
+			if name := flagName(v.pass, fn, call); name != "" {
+				v.markedFlags[name] = true
+			}
+		}
+	case "github.com/spf13/pflag":
+		if isFlagDefinition(fn) {
+			if name := flagName(v.pass, fn, call); name != "" {
+				v.definedFlags[name] = true
+			}
+		}
+	}
+}
+
+// isFlagDefinition returns true for the pflag.FlagSet methods that define a flag, e.g. StringVar or Bool.
+func isFlagDefinition(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return false
+	}
+	if strings.HasSuffix(fn.Name(), "Var") || strings.HasSuffix(fn.Name(), "VarP") {
+		return true
+	}
+	// String, Bool etc. return a pointer to the flag value (but Lookup returns a *Flag).
+	if sig.Results().Len() != 1 {
+		return false
+	}
+	ptr, ok := sig.Results().At(0).Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	_, isNamed := ptr.Elem().(*types.Named)
+	return !isNamed
+}
+
+// flagName returns the value of the "name" argument of a call such as StringVar(&p, "name", ...).
+func flagName(pass *analysis.Pass, fn *types.Func, call *ast.CallExpr) string {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok {
+		return ""
+	}
+	params := sig.Params()
+	for i := 0; i < params.Len() && i < len(call.Args); i++ {
+		if params.At(i).Name() == "name" {
+			return stringValue(pass, call.Args[i])
+		}
+	}
+	return ""
+}
+
+func typeFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := pass.TypesInfo.Uses[ident].(*types.Func)
+	return fn
+}
+
+func stringValue(pass *analysis.Pass, expr ast.Expr) string {
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	if lit, ok := expr.(*ast.BasicLit); ok {
+		if s, err := strconv.Unquote(lit.Value); err == nil {
+			return s
+		}
+	}
+	return ""
+}
+
+func hasCobraCommand(pass *analysis.Pass, params *ast.FieldList) bool {
+	if params == nil {
+		return false
+	}
+	for _, field := range params.List {
+		if ptr, ok := pass.TypesInfo.TypeOf(field.Type).(*types.Pointer); ok && isCobraType(ptr.Elem(), "Command") {
+			return true
+		}
+	}
+	return false
+}
+
+func isCobraType(typ types.Type, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == cobraPath && obj.Name() == name
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type options struct {
+	Config string
+	Owner  string
+	Repo   string
+	DryRun bool
+}
+
+func BuildGoodCommand() *cobra.Command {
+	var opt options
+	cmd := &cobra.Command{
+		Use: "good",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runGood(cmd.Context(), opt)
+		},
+	}
+	cmd.Flags().StringVar(&opt.Config, "config", opt.Config, "Path to the config file")
+	cmd.MarkFlagRequired("config")
+	cmd.Flags().StringVar(&opt.Owner, "owner", opt.Owner, "The github owner")
+	cobra.MarkFlagRequired(cmd.Flags(), "owner")
+	return cmd
+}
+
+func runGood(_ context.Context, opt options) error {
+	if opt.Config == "" {
+		return fmt.Errorf("--config is required")
+	}
+	if opt.Owner == "" {
+		return errors.New("--owner is required")
+	}
+	return nil
+}
+
+func BuildBadCommand() *cobra.Command {
+	var opt options
+	cmd := &cobra.Command{
+		Use:    "bad",
+		PreRun: func(_ *cobra.Command, _ []string) {}, // want "use PreRunE instead of PreRun.*"
+		Run: func(_ *cobra.Command, _ []string) { // want "use RunE instead of Run.*"
+			_ = runBad(context.Background(), opt) // want `use cmd.Context\(\) instead of context.Background\(\)`
+		},
+	}
+	cmd.Flags().StringVar(&opt.Repo, "repo", opt.Repo, "The github repo name")
+	cmd.Flags().Bool("dry-run", true, "If true, do not make changes")
+	cmd.Flags().Lookup("token")
+	return cmd
+}
+
+func runBad(_ context.Context, opt options) error {
+	if opt.Repo == "" {
+		return fmt.Errorf("--repo is required") // want `flag --repo should be marked with cmd.MarkFlagRequired\("repo"\)`
+	}
+	if !opt.DryRun {
+		return fmt.Errorf("--dry-run is required") // want `flag --dry-run should be marked with cmd.MarkFlagRequired\("dry-run"\)`
+	}
+	// Not a flag defined in this package.
+	return fmt.Errorf("--token is required")
+}
+
+func notACommand() {
+	_ = context.TODO() // OK, no cobra command here
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+
+	"github.com/spf13/pflag"
+)
+
+type Command struct {
+	Use     string
+	Run     func(cmd *Command, args []string)
+	RunE    func(cmd *Command, args []string) error
+	PreRun  func(cmd *Command, args []string)
+	PreRunE func(cmd *Command, args []string) error
+}
+
+func (c *Command) Context() context.Context { return nil }
+
+func (c *Command) Flags() *pflag.FlagSet { return nil }
+
+func (c *Command) MarkFlagRequired(name string) error { return nil }
+
+func MarkFlagRequired(flags *pflag.FlagSet, name string) error { return nil }
@@ -0,0 +1,25 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pflag
+
+type Flag struct{}
+
+type FlagSet struct{}
+
+func (f *FlagSet) StringVar(p *string, name string, value string, usage string) {}
+
+func (f *FlagSet) Bool(name string, value bool, usage string) *bool { return nil }
+
+func (f *FlagSet) Lookup(name string) *Flag { return nil }
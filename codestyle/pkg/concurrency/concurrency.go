@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package concurrency holds heuristics for the concurrency bugs that show up in our servers:
+// locks copied by value, WaitGroup.Add called inside the goroutine it counts, shared maps written
+// by HTTP and webhook handlers without a lock, and time.Tick in goroutines that can return.
+package concurrency
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzers are the concurrency analyzers, run together by ap lint.
+var Analyzers = []*analysis.Analyzer{
+	MutexCopyAnalyzer,
+	WaitGroupAddAnalyzer,
+	HandlerMapWriteAnalyzer,
+	TimeTickAnalyzer,
+}
+
+// isSyncType returns true if t is one of the named types of the sync package.
+func isSyncType(t types.Type, names ...string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	if obj.Pkg() == nil || obj.Pkg().Path() != "sync" {
+		return false
+	}
+	for _, name := range names {
+		if obj.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lockIn returns the name of the sync type held by value in t (e.g. "sync.Mutex"), or "" if there is none.
+// Locks behind pointers, slices, maps and channels are shared rather than copied, so they are not looked for.
+func lockIn(t types.Type) string {
+	return lockInType(t, map[types.Type]bool{})
+}
+
+func lockInType(t types.Type, seen map[types.Type]bool) string {
+	if seen[t] {
+		return ""
+	}
+	seen[t] = true
+
+	if isSyncType(t, "Mutex", "RWMutex", "WaitGroup", "Once", "Cond") {
+		return "sync." + types.Unalias(t).(*types.Named).Obj().Name()
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		for i := range u.NumFields() {
+			if lock := lockInType(u.Field(i).Type(), seen); lock != "" {
+				return lock
+			}
+		}
+	case *types.Array:
+		return lockInType(u.Elem(), seen)
+	}
+	return ""
+}
+
+// calledMethod returns the method called by call, or nil if it does not call a method.
+func calledMethod(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	selection := pass.TypesInfo.Selections[sel]
+	if selection == nil || selection.Kind() != types.MethodVal {
+		return nil
+	}
+	fn, _ := selection.Obj().(*types.Func)
+	return fn
+}
+
+// isSyncMethod returns true if fn is the method name of one of the types of the sync package.
+func isSyncMethod(fn *types.Func, typeNames []string, name string) bool {
+	if fn == nil || fn.Name() != name {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	return isSyncType(t, typeNames...)
+}
+
+// calledFunc returns the package-level function called by call, or nil if it calls something else.
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Type().(*types.Signature).Recv() != nil {
+		return nil
+	}
+	return fn
+}
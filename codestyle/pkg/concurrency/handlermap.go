@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var HandlerMapWriteAnalyzer = &analysis.Analyzer{
+	Name: "handlermapwrite",
+	Doc:  "check for HTTP and webhook handlers that write to a shared map without holding a lock, although requests are served concurrently",
+	Run:  runHandlerMapWrite,
+}
+
+func runHandlerMapWrite(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch fn := n.(type) {
+			case *ast.FuncDecl:
+				if fn.Body != nil && isHandler(pass, fn.Type) {
+					checkHandler(pass, fn, fn.Body)
+				}
+			case *ast.FuncLit:
+				if isHandler(pass, fn.Type) {
+					checkHandler(pass, fn, fn.Body)
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// isHandler returns true for the functions that handle a request: HTTP handlers, which take an *http.Request,
+// and admission webhook handlers, which take an admission.Request.
+func isHandler(pass *analysis.Pass, typ *ast.FuncType) bool {
+	for _, field := range typ.Params.List {
+		t := pass.TypesInfo.TypeOf(field.Type)
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		named, ok := types.Unalias(t).(*types.Named)
+		if !ok || named.Obj().Pkg() == nil || named.Obj().Name() != "Request" {
+			continue
+		}
+		if path := named.Obj().Pkg().Path(); path == "net/http" || strings.HasSuffix(path, "/webhook/admission") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHandler reports the writes of the handler fn to maps that outlive the request, unless it takes a lock.
+// Nested handlers are checked on their own.
+func checkHandler(pass *analysis.Pass, fn ast.Node, body *ast.BlockStmt) {
+	var writes []ast.Expr
+	locks := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return !isHandler(pass, n.Type)
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				writes = append(writes, mapIndexed(pass, lhs)...)
+			}
+		case *ast.IncDecStmt:
+			writes = append(writes, mapIndexed(pass, n.X)...)
+		case *ast.CallExpr:
+			if isSyncMethod(calledMethod(pass, n), []string{"Mutex", "RWMutex"}, "Lock") {
+				locks = true
+			}
+			if ident, ok := ast.Unparen(n.Fun).(*ast.Ident); ok && len(n.Args) > 0 {
+				if b, ok := pass.TypesInfo.Uses[ident].(*types.Builtin); ok && b.Name() == "delete" {
+					writes = append(writes, n.Args[0])
+				}
+			}
+		}
+		return true
+	})
+	if locks {
+		return
+	}
+
+	receiver := receiverOf(pass, fn)
+	for _, m := range writes {
+		obj := rootVar(pass, m)
+		if obj == nil {
+			continue
+		}
+		// Variables declared outside the handler (package variables, and those captured by a handler closure)
+		// and the fields of its receiver are shared by all requests.
+		if obj == receiver || obj.Pos() < fn.Pos() || obj.Pos() >= fn.End() {
+			pass.Reportf(m.Pos(), "handler writes to the shared map %s without holding a lock, but requests are served concurrently", types.ExprString(m))
+		}
+	}
+}
+
+// mapIndexed returns the map of expr if it is a map index expression, e.g. m for m[k].
+func mapIndexed(pass *analysis.Pass, expr ast.Expr) []ast.Expr {
+	index, ok := ast.Unparen(expr).(*ast.IndexExpr)
+	if !ok {
+		return nil
+	}
+	if _, ok := pass.TypesInfo.TypeOf(index.X).Underlying().(*types.Map); !ok {
+		return nil
+	}
+	return []ast.Expr{index.X}
+}
+
+// receiverOf returns the receiver of fn if it is a method, or nil.
+func receiverOf(pass *analysis.Pass, fn ast.Node) types.Object {
+	decl, ok := fn.(*ast.FuncDecl)
+	if !ok || decl.Recv == nil || len(decl.Recv.List[0].Names) == 0 {
+		return nil
+	}
+	return pass.TypesInfo.Defs[decl.Recv.List[0].Names[0]]
+}
+
+// rootVar returns the variable that expr is a part of, e.g. s for s.cache[k].byName, or nil if it is not part of a variable.
+func rootVar(pass *analysis.Pass, expr ast.Expr) *types.Var {
+	for {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.Ident:
+			v, _ := pass.TypesInfo.Uses[e].(*types.Var)
+			return v
+		case *ast.SelectorExpr:
+			if x, ok := e.X.(*ast.Ident); ok {
+				if _, ok := pass.TypesInfo.Uses[x].(*types.PkgName); ok {
+					v, _ := pass.TypesInfo.Uses[e.Sel].(*types.Var)
+					return v
+				}
+			}
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
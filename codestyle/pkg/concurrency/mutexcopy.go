@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var MutexCopyAnalyzer = &analysis.Analyzer{
+	Name: "mutexcopy",
+	Doc:  "check for values holding a sync.Mutex (or another sync type) that are copied, so that the copy locks independently",
+	Run:  runMutexCopy,
+}
+
+func runMutexCopy(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Recv != nil {
+					for _, field := range n.Recv.List {
+						if lock := lockIn(pass.TypesInfo.TypeOf(field.Type)); lock != "" {
+							pass.Reportf(field.Pos(), "method %s has a value receiver, which copies the %s it holds; use a pointer receiver", n.Name.Name, lock)
+						}
+					}
+				}
+				checkParams(pass, n.Type)
+			case *ast.FuncLit:
+				checkParams(pass, n.Type)
+			case *ast.AssignStmt:
+				for i, rhs := range n.Rhs {
+					// Values assigned to _ are discarded rather than copied.
+					if len(n.Lhs) == len(n.Rhs) && isBlank(n.Lhs[i]) {
+						continue
+					}
+					checkCopy(pass, rhs, "assignment")
+				}
+			case *ast.ValueSpec:
+				for _, value := range n.Values {
+					checkCopy(pass, value, "variable declaration")
+				}
+			case *ast.ReturnStmt:
+				for _, result := range n.Results {
+					checkCopy(pass, result, "return")
+				}
+			case *ast.RangeStmt:
+				if n.Value != nil && n.Tok == token.DEFINE {
+					if lock := lockIn(pass.TypesInfo.TypeOf(n.Value)); lock != "" {
+						pass.Reportf(n.Value.Pos(), "range variable copies the %s held by each element; range over the indices, or store pointers", lock)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// checkParams reports the parameters of typ that are passed by value and hold a lock.
+func checkParams(pass *analysis.Pass, typ *ast.FuncType) {
+	if typ.Params == nil {
+		return
+	}
+	for _, field := range typ.Params.List {
+		if lock := lockIn(pass.TypesInfo.TypeOf(field.Type)); lock != "" {
+			pass.Reportf(field.Pos(), "parameter is passed by value, which copies the %s it holds; pass a pointer", lock)
+		}
+	}
+}
+
+// checkCopy reports expr if it copies an existing value that holds a lock. New values (composite literals
+// and the results of calls) are not copies of a lock in use.
+func checkCopy(pass *analysis.Pass, expr ast.Expr, what string) {
+	switch ast.Unparen(expr).(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.StarExpr:
+	default:
+		return
+	}
+	if tv, ok := pass.TypesInfo.Types[expr]; !ok || !tv.IsValue() {
+		return
+	}
+	if ident, ok := ast.Unparen(expr).(*ast.Ident); ok && ident.Name == "nil" {
+		return
+	}
+	if lock := lockIn(pass.TypesInfo.TypeOf(expr)); lock != "" {
+		pass.Reportf(expr.Pos(), "%s copies a value holding a %s; use a pointer to share it", what, lock)
+	}
+}
+
+// isBlank returns true if expr is the blank identifier.
+func isBlank(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlermapwrite
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var hits = map[string]int{}
+
+type server struct {
+	mu       sync.Mutex
+	sessions map[string]string
+	seen     map[string]bool
+}
+
+func handleHits(w http.ResponseWriter, r *http.Request) {
+	hits[r.URL.Path]++ // want `handler writes to the shared map hits without holding a lock, but requests are served concurrently`
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.sessions[r.URL.Path] = "active" // want `handler writes to the shared map s.sessions without holding a lock, but requests are served concurrently`
+	delete(s.seen, r.URL.Path)        // want `handler writes to the shared map s.seen without holding a lock, but requests are served concurrently`
+
+	local := map[string]string{}
+	local["path"] = r.URL.Path
+	r.Header["X-Seen"] = []string{"true"}
+}
+
+func (s *server) handleLocked(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[r.URL.Path] = "active"
+}
+
+func (s *server) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.seen[r.URL.Path] = true // want `handler writes to the shared map s.seen without holding a lock, but requests are served concurrently`
+	}
+}
+
+func (s *server) Handle(ctx context.Context, req admission.Request) admission.Response {
+	s.seen[req.UID] = true // want `handler writes to the shared map s.seen without holding a lock, but requests are served concurrently`
+	return admission.Response{Allowed: true}
+}
+
+func (s *server) record(path string) {
+	s.seen[path] = true
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutexcopy
+
+import "sync"
+
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+type registry struct {
+	counters [2]counter
+	shared   *sync.Mutex
+}
+
+func (c *counter) Inc() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+}
+
+func (c counter) Value() int { // want `method Value has a value receiver, which copies the sync.Mutex it holds; use a pointer receiver`
+	return c.n
+}
+
+func report(c counter) {} // want `parameter is passed by value, which copies the sync.Mutex it holds; pass a pointer`
+
+func share(c *counter, mu *sync.Mutex) {}
+
+func copies(c *counter, r *registry) counter {
+	fresh := counter{}
+	snapshot := *c                    // want `assignment copies a value holding a sync.Mutex; use a pointer to share it`
+	var first = r.counters            // want `variable declaration copies a value holding a sync.Mutex; use a pointer to share it`
+	for _, each := range r.counters { // want `range variable copies the sync.Mutex held by each element; range over the indices, or store pointers`
+		_ = each
+	}
+	for i := range r.counters {
+		r.counters[i].Inc()
+	}
+	shared := r.shared
+	_, _, _, _ = fresh, snapshot, first, shared
+	return *c // want `return copies a value holding a sync.Mutex; use a pointer to share it`
+}
+
+func newCounter() counter {
+	return counter{}
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeticklib
+
+import "time"
+
+func poll(done <-chan struct{}) {
+	tick := time.Tick(time.Second) // want `time.Tick outside the main function keeps its ticker running after the goroutine using it returns; use time.NewTicker and Stop it`
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick:
+		}
+	}
+}
+
+func pollStopped(done <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waitgroupadd
+
+import "sync"
+
+func bad(items []string) {
+	var wg sync.WaitGroup
+	for range items {
+		go func() {
+			wg.Add(1) // want `WaitGroup.Add is called inside the goroutine it counts, so Wait can return before it runs; call Add before the go statement`
+			defer wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func good(items []string) {
+	var wg sync.WaitGroup
+	for range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func nested(wg *sync.WaitGroup) {
+	go func() {
+		// The goroutine starts more goroutines, counting them before they start.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+		}()
+	}()
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var TimeTickAnalyzer = &analysis.Analyzer{
+	Name: "timetick",
+	Doc:  "check for time.Tick outside the main function, where the goroutine using it can return and leave the ticker running",
+	Run:  runTimeTick,
+}
+
+func runTimeTick(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && pass.Pkg.Name() == "main" && fn.Recv == nil && fn.Name.Name == "main" && fn.Body != nil {
+				// The main goroutine runs for the life of the program, so its tickers are never leaked;
+				// the function literals in main (e.g. the goroutines it starts) are still checked.
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					if lit, ok := n.(*ast.FuncLit); ok {
+						checkTimeTick(pass, lit.Body)
+						return false
+					}
+					return true
+				})
+				continue
+			}
+			checkTimeTick(pass, decl)
+		}
+	}
+	return nil, nil
+}
+
+// checkTimeTick reports the calls to time.Tick in node.
+func checkTimeTick(pass *analysis.Pass, node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if fn := calledFunc(pass, call); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "time" && fn.Name() == "Tick" {
+			pass.Reportf(call.Pos(), "time.Tick outside the main function keeps its ticker running after the goroutine using it returns; use time.NewTicker and Stop it")
+		}
+		return true
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var WaitGroupAddAnalyzer = &analysis.Analyzer{
+	Name: "waitgroupadd",
+	Doc:  "check for sync.WaitGroup.Add called inside the goroutine it counts, which lets Wait return before the goroutine starts",
+	Run:  runWaitGroupAdd,
+}
+
+func runWaitGroupAdd(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			stmt, ok := n.(*ast.GoStmt)
+			if !ok {
+				return true
+			}
+			lit, ok := ast.Unparen(stmt.Call.Fun).(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			// The goroutine counts itself if it calls both Add and Done on a wait group. A goroutine that
+			// calls Add for the goroutines it starts itself (which call Done) is fine.
+			var adds []*ast.CallExpr
+			done := map[string]bool{}
+			ast.Inspect(lit.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					// Nested function literals run whenever they are called, not necessarily in this goroutine.
+					return false
+				case *ast.CallExpr:
+					fn := calledMethod(pass, n)
+					switch {
+					case isSyncMethod(fn, []string{"WaitGroup"}, "Add"):
+						adds = append(adds, n)
+					case isSyncMethod(fn, []string{"WaitGroup"}, "Done"):
+						done[waitGroupOf(n)] = true
+					}
+				}
+				return true
+			})
+			for _, add := range adds {
+				if done[waitGroupOf(add)] {
+					pass.Reportf(add.Pos(), "WaitGroup.Add is called inside the goroutine it counts, so Wait can return before it runs; call Add before the go statement")
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// waitGroupOf returns the wait group that the method call is called on, e.g. "s.wg" for s.wg.Add(1).
+func waitGroupOf(call *ast.CallExpr) string {
+	return types.ExprString(ast.Unparen(call.Fun).(*ast.SelectorExpr).X)
+}
@@ -0,0 +1,278 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dupcode finds blocks of Go code that are duplicated across files.
+package dupcode
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+)
+
+// DefaultMinTokens is the default minimum length of a reported duplicate, in tokens.
+const DefaultMinTokens = 100
+
+// Options configures Find.
+type Options struct {
+	// MinTokens is the minimum length of a reported duplicate, in tokens.
+	MinTokens int
+
+	// IncludeTests also checks _test.go files, which are skipped by default
+	// as table-driven tests are often repetitive by design.
+	IncludeTests bool
+
+	// IgnoreIdentifiers treats all identifiers and literals as equal, to also find
+	// blocks that were copied and then had their variables renamed.
+	IgnoreIdentifiers bool
+
+	// Skip are additional ignore patterns for the walk.
+	Skip []string
+}
+
+// Location is a range of lines in a file.
+type Location struct {
+	// File is the path of the file, relative to the root.
+	File      string
+	StartLine int
+	EndLine   int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d-%d", l.File, l.StartLine, l.EndLine)
+}
+
+// Duplicate is a block of code that appears in two locations.
+type Duplicate struct {
+	Tokens int
+	A, B   Location
+}
+
+func (d Duplicate) String() string {
+	return fmt.Sprintf("duplicate code (%d tokens): %s and %s", d.Tokens, d.A, d.B)
+}
+
+// tok is a token, reduced to what is compared when looking for duplicates.
+type tok struct {
+	kind token.Token
+	lit  string
+	line int
+}
+
+func (t tok) equal(other tok) bool {
+	return t.kind == other.kind && t.lit == other.lit
+}
+
+type file struct {
+	relPath string
+	tokens  []tok
+}
+
+// Find returns the duplicated blocks of Go code under root.
+func Find(root string, opts Options) ([]Duplicate, error) {
+	if opts.MinTokens <= 0 {
+		opts.MinTokens = DefaultMinTokens
+	}
+
+	var files []*file
+	fv := walker.NewFileView(root, append([]string{".git", "vendor", "node_modules", "testdata"}, opts.Skip...))
+	err := fv.Walk(func(f walker.File) error {
+		if !strings.HasSuffix(f.Path, ".go") {
+			return nil
+		}
+		if !opts.IncludeTests && strings.HasSuffix(f.Path, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(f.Path)
+		if err != nil {
+			return err
+		}
+		if isGenerated(src) {
+			return nil
+		}
+		files = append(files, &file{relPath: f.RelPath, tokens: tokenize(src, opts.IgnoreIdentifiers)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking for go files: %w", err)
+	}
+
+	return findDuplicates(files, opts.MinTokens), nil
+}
+
+// isGenerated returns true if src has the standard "Code generated ... DO NOT EDIT." marker.
+func isGenerated(src []byte) bool {
+	for _, line := range bytes.Split(src, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("// Code generated ")) && bytes.HasSuffix(line, []byte(" DO NOT EDIT.")) {
+			return true
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false
+		}
+	}
+	return false
+}
+
+// tokenize returns the tokens of src, without comments and automatically inserted semicolons.
+// Import declarations are skipped, as identical import blocks are not interesting duplicates.
+// If ignoreIdentifiers is true, the values of identifiers and literals are dropped.
+func tokenize(src []byte, ignoreIdentifiers bool) []tok {
+	fset := token.NewFileSet()
+	f := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(f, src, nil, 0)
+
+	var tokens []tok
+	inImport := false
+	depth := 0
+	for {
+		pos, kind, lit := s.Scan()
+		if kind == token.EOF {
+			break
+		}
+		if kind == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+
+		// Skip "import (...)" and "import [name] "path"".
+		if kind == token.IMPORT {
+			inImport = true
+			continue
+		}
+		if inImport {
+			switch {
+			case kind == token.LPAREN:
+				depth++
+			case kind == token.RPAREN:
+				depth--
+				inImport = depth > 0
+			case kind == token.STRING && depth == 0:
+				inImport = false
+			}
+			continue
+		}
+
+		if lit == "" || (ignoreIdentifiers && (kind == token.IDENT || kind.IsLiteral())) {
+			lit = kind.String()
+		}
+		tokens = append(tokens, tok{kind: kind, lit: lit, line: f.Line(pos)})
+	}
+	return tokens
+}
+
+// findDuplicates finds the maximal runs of at least minTokens tokens that appear in two places.
+func findDuplicates(files []*file, minTokens int) []Duplicate {
+	type position struct {
+		file  int
+		index int
+	}
+
+	// Index every window of minTokens tokens by its hash.
+	windows := make(map[uint64][]position)
+	for fi, f := range files {
+		for i, h := range windowHashes(f.tokens, minTokens) {
+			windows[h] = append(windows[h], position{fi, i})
+		}
+	}
+
+	var duplicates []Duplicate
+	for fi, f := range files {
+		hashes := windowHashes(f.tokens, minTokens)
+		for i := 0; i < len(hashes); i++ {
+			for _, other := range windows[hashes[i]] {
+				// Only report each pair once, from its first occurrence.
+				if other.file < fi || (other.file == fi && other.index <= i) {
+					continue
+				}
+				otherTokens := files[other.file].tokens
+
+				// Skip the windows within a match that was already reported.
+				if i > 0 && other.index > 0 && f.tokens[i-1].equal(otherTokens[other.index-1]) {
+					continue
+				}
+
+				n := 0
+				for i+n < len(f.tokens) && other.index+n < len(otherTokens) && f.tokens[i+n].equal(otherTokens[other.index+n]) {
+					if other.file == fi && i+n >= other.index {
+						// Don't let a block match an overlapping copy of itself.
+						break
+					}
+					n++
+				}
+				if n < minTokens {
+					// A hash collision, or an overlapping match.
+					continue
+				}
+
+				duplicates = append(duplicates, Duplicate{
+					Tokens: n,
+					A:      Location{File: f.relPath, StartLine: f.tokens[i].line, EndLine: f.tokens[i+n-1].line},
+					B:      Location{File: files[other.file].relPath, StartLine: otherTokens[other.index].line, EndLine: otherTokens[other.index+n-1].line},
+				})
+			}
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Tokens != duplicates[j].Tokens {
+			return duplicates[i].Tokens > duplicates[j].Tokens
+		}
+		return duplicates[i].A.String() < duplicates[j].A.String()
+	})
+	return duplicates
+}
+
+// windowHashes returns the rolling hash of every window of n tokens.
+func windowHashes(tokens []tok, n int) []uint64 {
+	if len(tokens) < n {
+		return nil
+	}
+
+	const base = 1000003
+	hashToken := func(t tok) uint64 {
+		// FNV-1a of the token kind and literal.
+		h := uint64(14695981039346656037)
+		h = (h ^ uint64(t.kind)) * 1099511628211
+		for i := 0; i < len(t.lit); i++ {
+			h = (h ^ uint64(t.lit[i])) * 1099511628211
+		}
+		return h
+	}
+
+	// pow is base^(n-1), the weight of the token leaving the window.
+	pow := uint64(1)
+	for i := 1; i < n; i++ {
+		pow *= base
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-n+1)
+	var h uint64
+	for i, t := range tokens {
+		if i >= n {
+			h -= hashToken(tokens[i-n]) * pow
+		}
+		h = h*base + hashToken(t)
+		if i >= n-1 {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
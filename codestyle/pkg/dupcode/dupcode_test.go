@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dupcode
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const findRepoRoot = `
+func findRepoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not in a git repository")
+		}
+		dir = parent
+	}
+}
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestFind(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"ap/root.go":             "package ap\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n" + findRepoRoot,
+		"tools/foo/root.go":      "package foo\n\n// A comment that is not part of the comparison.\nimport \"os\"\n\nvar x = 1\n" + findRepoRoot,
+		"tools/foo/other.go":     "package foo\n\nfunc other() {}\n",
+		"tools/foo/root_test.go": "package foo\n" + findRepoRoot,
+		"gen/root.go":            "// Code generated by hand. DO NOT EDIT.\n\npackage gen\n" + findRepoRoot,
+	})
+
+	duplicates, err := Find(root, Options{MinTokens: 50})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("expected 1 duplicate, got %d: %v", len(duplicates), duplicates)
+	}
+	got := duplicates[0].String()
+	want := "duplicate code (87 tokens): ap/root.go:8-23 and tools/foo/root.go:8-23"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	duplicates, err = Find(root, Options{MinTokens: 50, IncludeTests: true})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(duplicates) != 3 {
+		t.Errorf("expected 3 duplicates including tests, got %d: %v", len(duplicates), duplicates)
+	}
+
+	duplicates, err = Find(root, Options{MinTokens: 200})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("expected no duplicates above the minimum length, got %v", duplicates)
+	}
+}
+
+func TestIgnoreIdentifiers(t *testing.T) {
+	renamed := strings.NewReplacer("dir", "d", "parent", "up", `".git"`, `".hg"`).Replace(findRepoRoot)
+	root := writeFiles(t, map[string]string{
+		"a.go": "package a\n" + findRepoRoot,
+		"b.go": "package b\n" + renamed,
+	})
+
+	duplicates, err := Find(root, Options{MinTokens: 50})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("expected renamed copies not to match by default, got %v", duplicates)
+	}
+
+	duplicates, err = Find(root, Options{MinTokens: 50, IgnoreIdentifiers: true})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(duplicates) != 1 {
+		t.Errorf("expected renamed copies to match with IgnoreIdentifiers, got %v", duplicates)
+	}
+}
+
+func TestFindWithinFile(t *testing.T) {
+	// The same block twice in one file is reported, but a block never matches itself.
+	root := writeFiles(t, map[string]string{
+		"a.go": "package a\n" + findRepoRoot + strings.Replace(findRepoRoot, "findRepoRoot", "findRepoRoot2", 1),
+	})
+
+	duplicates, err := Find(root, Options{MinTokens: 50})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("expected 1 duplicate, got %v", duplicates)
+	}
+	if d := duplicates[0]; d.A.File != "a.go" || d.B.File != "a.go" || d.A.StartLine >= d.B.StartLine {
+		t.Errorf("unexpected duplicate %v", d)
+	}
+}
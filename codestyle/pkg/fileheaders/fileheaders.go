@@ -22,9 +22,12 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/linediff"
 	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
@@ -32,26 +35,86 @@ import (
 
 var generatedCodeRegexp = regexp.MustCompile(`Code generated .* DO NOT EDIT`)
 
+// ConfigFiles are the locations of the config, relative to the repository root, in the order they are looked for.
+// Only the first that exists is used; the others are older names, still read for compatibility.
+var ConfigFiles = []string{
+	".ap/headers.yaml",
+	".ap/file-headers.yaml",
+	".codestyle/file-headers.yaml",
+}
+
+// DefaultSkip are the patterns of the directories that are never given headers, in addition to Config.Skip.
+var DefaultSkip = []string{
+	".git/",
+	".svn/",
+	".hg/",
+	"vendor/",
+	"third_party/",
+	"node_modules/",
+}
+
+// Config is the contents of .ap/headers.yaml.
 type Config struct {
-	License         string   `json:"license"`
-	CopyrightHolder string   `json:"copyrightHolder"`
-	Skip            []string `json:"skip"`
-	SkipGenerated   *bool    `json:"skipGenerated"`
+	// License is the license the headers refer to: apache-2.0, mit or bsd-3-clause, or with SPDXOnly,
+	// any SPDX license identifier.
+	License         string `json:"license"`
+	CopyrightHolder string `json:"copyrightHolder"`
+
+	// SPDXOnly makes headers a copyright line and an SPDX-License-Identifier line, instead of the license text.
+	SPDXOnly bool `json:"spdxOnly,omitempty"`
+
+	// Template is the text of the headers, without comment markers, replacing that of the license.
+	// It is a Go template, which can refer to {{.Year}}, {{.CopyrightHolder}} and {{.SPDX}}.
+	Template string `json:"template,omitempty"`
+
+	// Skip lists gitignore-style patterns (e.g. "*.json", "docs/", "hack/*.sh" or "**/testdata/") of the files and
+	// directories that are not given headers, in addition to DefaultSkip. Patterns containing a slash are relative
+	// to the repository root; the others match the name of a file or directory at any depth.
+	Skip []string `json:"skip"`
+
+	// SkipGenerated skips files marked as generated with a "Code generated ... DO NOT EDIT" comment (defaults to true).
+	SkipGenerated *bool `json:"skipGenerated"`
+
+	// GitFiles has git list the files that are given headers: those that are tracked, and untracked files that
+	// git does not ignore. Unlike the .gitignore files that are always honored, this also applies to the excludes
+	// of git's own config, and to files named explicitly.
+	GitFiles bool `json:"gitFiles,omitempty"`
+
+	// Overrides configure the headers of subtrees differently, e.g. vendored code under a foreign license.
+	Overrides []Override `json:"overrides,omitempty"`
+
+	// Normalize rewrites existing headers that are not in the configured form (off by default).
+	Normalize *Normalize `json:"normalize,omitempty"`
 }
 
-type FileHeadersOptions struct {
-	IgnoreFiles []string `json:"ignore"`
+// Normalize configures the rewriting of existing headers, e.g. those added by other tools or older configs.
+type Normalize struct {
+	// Headers rewrites leading copyright comments with another holder or formatting (e.g. a block comment in Go)
+	// to the configured header, keeping their year.
+	Headers bool `json:"headers,omitempty"`
+
+	// Years extends the year of headers to the current one, e.g. from 2023 to 2023-2026.
+	Years bool `json:"years,omitempty"`
+
+	// AllowedHolders are the copyright holders whose headers are left as they are, e.g. "The Kubernetes Authors".
+	AllowedHolders []string `json:"allowedHolders,omitempty"`
 }
 
-func (o *FileHeadersOptions) InitDefaults() {
-	o.IgnoreFiles = []string{
-		".git/",
-		".svn/",
-		".hg/",
-		"vendor/",
-		"third_party/",
-		"node_modules/",
-	}
+// Override configures the headers of the files under a directory.
+// The override of the deepest directory containing a file applies.
+type Override struct {
+	// Path is the directory, relative to the repository root.
+	Path string `json:"path"`
+
+	// License, CopyrightHolder, SPDXOnly and Template replace those of the Config, if set.
+	// An override setting License without a Template does not inherit the Template of the Config.
+	License         string `json:"license,omitempty"`
+	CopyrightHolder string `json:"copyrightHolder,omitempty"`
+	SPDXOnly        *bool  `json:"spdxOnly,omitempty"`
+	Template        string `json:"template,omitempty"`
+
+	// Skip leaves the files under Path without headers, e.g. for code under a license other than Apache 2.0.
+	Skip bool `json:"skip,omitempty"`
 }
 
 // processor handles file processing
@@ -60,31 +123,87 @@ type processor struct {
 	ignoreList *walker.IgnoreList
 }
 
-func (p *processor) shouldIgnoreFile(relPath string, isDir bool) bool {
-	return p.ignoreList.ShouldIgnore(relPath, isDir)
+func (p *processor) shouldIgnoreFile(relPath string) bool {
+	return p.ignoreList.ShouldIgnoreFile(relPath)
 }
 
+// Run adds headers to the files that are missing them, and fixes malformed (and, if configured, outdated) headers.
+// If files is empty, all the files under repoRoot are processed.
 func Run(ctx context.Context, repoRoot string, files []string) error {
-	var errs []error
+	log := klog.FromContext(ctx)
+	return forEachFile(ctx, repoRoot, files, func(absPath string, fix *fix) error {
+		switch fix.reason {
+		case reasonMissing:
+			log.Info("Adding file header", "file", fix.relPath)
+		case reasonOutdated:
+			log.Info("Updating outdated file header", "file", fix.relPath)
+		default:
+			log.Info("Fixing malformed file header", "file", fix.relPath)
+		}
+		return os.WriteFile(absPath, fix.after, 0644)
+	})
+}
+
+// The reasons a header needs a fix.
+const (
+	reasonMissing   = "missing header"
+	reasonMalformed = "malformed header"
+	reasonOutdated  = "outdated header"
+)
+
+// Problem is a file whose header is missing, malformed or outdated, reported by Check.
+type Problem struct {
+	// Path is the path of the file, relative to the repository root.
+	Path string
+	// Reason is "missing header", "malformed header" or "outdated header".
+	Reason string
+	// Diff is a unified diff of the change Run would make.
+	Diff string
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Reason
+}
 
-	var opt FileHeadersOptions
-	opt.InitDefaults()
+// Check returns the files whose headers Run would add or fix, without changing any files.
+func Check(ctx context.Context, repoRoot string, files []string) ([]Problem, error) {
+	var problems []Problem
+	err := forEachFile(ctx, repoRoot, files, func(_ string, fix *fix) error {
+		relPath := filepath.ToSlash(fix.relPath)
+		problems = append(problems, Problem{
+			Path:   relPath,
+			Reason: fix.reason,
+			Diff:   linediff.Unified(relPath, string(fix.before), string(fix.after)),
+		})
+		return nil
+	})
+	return problems, err
+}
+
+// forEachFile calls fn with each of files, or all the files under repoRoot, whose header needs a fix.
+func forEachFile(ctx context.Context, repoRoot string, files []string, fn func(absPath string, fix *fix) error) error {
+	var errs []error
 
 	log := klog.FromContext(ctx)
 
-	configFile := filepath.Join(repoRoot, ".ap/headers.yaml")
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+	configFile, err := findConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	if configFile == "" {
 		klog.V(2).Info("No .ap/headers.yaml found, skipping file headers")
 		return nil
 	}
+	if rel, _ := filepath.Rel(repoRoot, configFile); filepath.ToSlash(rel) != ConfigFiles[0] {
+		klog.Warningf("%s is deprecated; rename it to %s", rel, ConfigFiles[0])
+	}
 
 	config, err := loadConfig(configFile)
 	if err != nil {
 		return err
 	}
 
-	// Combine default ignores with config skips
-	allIgnores := append(opt.IgnoreFiles, config.Skip...)
+	allIgnores := append(slices.Clone(DefaultSkip), config.Skip...)
 	ignoreList := walker.NewIgnoreList(allIgnores)
 
 	processor := &processor{
@@ -92,11 +211,53 @@ func Run(ctx context.Context, repoRoot string, files []string) error {
 		ignoreList: ignoreList,
 	}
 
+	// The cache records the files whose headers are fine, for the configuration and year they were checked with.
+	var cm *cache.Manager
+	var configHash string
+	if m, err := cache.NewManager(repoRoot); err != nil {
+		log.V(2).Info("Failed to initialize cache", "error", err)
+	} else if meta, err := m.GetOrUpdateMetadata(configFile); err == nil {
+		cm, configHash = m, meta.Hash
+		defer func() {
+			if err := cm.Save(); err != nil {
+				log.Error(err, "Failed to save cache")
+			}
+		}()
+	}
+	year := fmt.Sprint(time.Now().Year())
+
+	processFile := func(absPath, relPath string) error {
+		if !processor.handles(absPath, relPath) {
+			return nil
+		}
+		var key string
+		if cm != nil {
+			if meta, err := cm.GetOrUpdateMetadata(absPath); err == nil {
+				key = cache.Key(configHash, filepath.ToSlash(relPath), meta.Hash, year)
+				if _, done := cm.Done("fileheaders", key); done {
+					return nil
+				}
+			}
+		}
+		fix, err := processor.processFile(absPath, relPath)
+		if err != nil {
+			return err
+		}
+		if fix == nil {
+			if key != "" {
+				cm.MarkDone("fileheaders", key, nil)
+			}
+			return nil
+		}
+		return fn(absPath, fix)
+	}
+
 	if len(files) == 0 {
 		fv := walker.NewFileView(repoRoot, allIgnores)
+		fv.GitFiles = config.GitFiles
 		err := fv.Walk(func(f walker.File) error {
 			// f.RelPath is already relative to repoRoot
-			if err := processor.processFile(ctx, f.Path, f.RelPath); err != nil {
+			if err := processFile(f.Path, f.RelPath); err != nil {
 				log.Error(err, "Error processing file", "file", f.RelPath)
 				// We don't abort walk on individual file error usually, but Walk signature expects error.
 				// We should collect errors.
@@ -108,6 +269,14 @@ func Run(ctx context.Context, repoRoot string, files []string) error {
 			return fmt.Errorf("error walking directory: %w", err)
 		}
 	} else {
+		var gitFiles []string
+		if config.GitFiles {
+			gitFiles, err = walker.GitFiles(repoRoot)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Ensure we use absolute paths for IO, but relative paths for ignore checks.
 		for _, file := range files {
 			absPath := file
@@ -121,8 +290,13 @@ func Run(ctx context.Context, repoRoot string, files []string) error {
 				errs = append(errs, fmt.Errorf("skipping file outside repo root %s: %w", file, err))
 				continue
 			}
+			if config.GitFiles {
+				if _, found := slices.BinarySearch(gitFiles, relPath); !found {
+					continue
+				}
+			}
 
-			if err := processor.processFile(ctx, absPath, relPath); err != nil {
+			if err := processFile(absPath, relPath); err != nil {
 				log.Error(err, "Error processing file", "file", file)
 				errs = append(errs, fmt.Errorf("error processing %s: %w", file, err))
 			}
@@ -131,6 +305,21 @@ func Run(ctx context.Context, repoRoot string, files []string) error {
 	return errors.Join(errs...)
 }
 
+// findConfig returns the path of the first of ConfigFiles that exists in repoRoot, or "" if there is none.
+func findConfig(repoRoot string) (string, error) {
+	for _, name := range ConfigFiles {
+		path := filepath.Join(repoRoot, name)
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("error checking %s: %w", path, err)
+		}
+	}
+	return "", nil
+}
+
 func loadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -138,7 +327,21 @@ func loadConfig(path string) (*Config, error) {
 	}
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if err := config.header("").validate(); err != nil {
+		return nil, fmt.Errorf("error in %s: %w", path, err)
+	}
+	for i, override := range config.Overrides {
+		if override.Path == "" {
+			return nil, fmt.Errorf("error in %s: override %d has no path", path, i+1)
+		}
+		if override.Skip {
+			continue
+		}
+		if err := config.header(filepath.Join(override.Path, "file")).validate(); err != nil {
+			return nil, fmt.Errorf("error in %s: override %d: %w", path, i+1, err)
+		}
 	}
 	if config.SkipGenerated == nil {
 		t := true
@@ -147,22 +350,35 @@ func loadConfig(path string) (*Config, error) {
 	return &config, nil
 }
 
-func (p *processor) processFile(ctx context.Context, absPath, relPath string) error {
-	log := klog.FromContext(ctx)
+// fix is the change to the header of a file.
+type fix struct {
+	relPath       string
+	before, after []byte
+	// reason is why the header needs the fix, one of the reason constants.
+	reason string
+}
 
-	if p.shouldIgnoreFile(relPath, false) {
-		return nil
+// handles reports whether the file should have a header: it is not ignored or skipped, and has a comment style.
+func (p *processor) handles(absPath, relPath string) bool {
+	if p.shouldIgnoreFile(relPath) || p.config.skips(relPath) {
+		return false
 	}
+	return getCommentStyle(filepath.Base(absPath), filepath.Ext(absPath)) != ""
+}
+
+// processFile returns the fix the header of the file needs, or nil if it needs none.
+func (p *processor) processFile(absPath, relPath string) (*fix, error) {
+	if !p.handles(absPath, relPath) {
+		return nil, nil
+	}
+	header := p.config.header(relPath)
 
 	ext := filepath.Ext(absPath)
 	commentStyle := getCommentStyle(filepath.Base(absPath), ext)
-	if commentStyle == "" {
-		return nil
-	}
 
 	content, err := os.ReadFile(absPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check for generated file
@@ -174,13 +390,68 @@ func (p *processor) processFile(ctx context.Context, absPath, relPath string) er
 
 	if p.config.SkipGenerated != nil && *p.config.SkipGenerated {
 		if generatedCodeRegexp.Match(checkBuf) {
-			return nil
+			return nil, nil
+		}
+	}
+
+	existing, err := header.existing(commentStyle)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+
+	hasShebang := len(lines) > 0 && strings.HasPrefix(lines[0], "#!")
+	start := 0
+	if hasShebang {
+		start = 1
+		for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+	}
+
+	// A header starting like the configured one must match it entirely, in the year it was added.
+	if start < len(lines) {
+		if m := existing.FindStringSubmatch(lines[start]); m != nil {
+			year := fmt.Sprint(time.Now().Year())
+			if i := existing.SubexpIndex("year"); i >= 0 && m[i] != "" {
+				year = m[i]
+			}
+			want, err := p.renderLines(header, commentStyle, year)
+			if err != nil {
+				return nil, err
+			}
+			got := commentBlock(lines[start:], commentStyle, len(want))
+			if slices.Equal(got, want) {
+				return nil, nil
+			}
+			reason := reasonMalformed
+			if current, err := header.render(commentStyle, year); err == nil && strings.Join(got, "\n")+"\n" == current {
+				reason = reasonOutdated
+			}
+			return replaceHeader(relPath, content, lines, start, len(got), want, reason), nil
+		}
+	}
+
+	// Other leading copyright comments are rewritten if configured, unless their holder is allowed.
+	if normalize := p.config.Normalize; normalize != nil && normalize.Headers && start < len(lines) {
+		if n, holder, year := leadingCopyright(lines[start:], commentStyle, ext == ".go"); n > 0 && !normalize.allows(holder) {
+			if year == "" {
+				year = fmt.Sprint(time.Now().Year())
+			}
+			want, err := p.renderLines(header, commentStyle, year)
+			if err != nil {
+				return nil, err
+			}
+			return replaceHeader(relPath, content, lines, start, n, want, reasonOutdated), nil
 		}
 	}
 
 	expectedCopyright := commentStyle + " Copyright"
 	if bytes.Contains(checkBuf, []byte(expectedCopyright)) {
-		return nil
+		return nil, nil
+	}
+	if existing.Match(checkBuf) {
+		return nil, nil
 	}
 
 	// Check for K8s style block headers in Go files
@@ -188,35 +459,122 @@ func (p *processor) processFile(ctx context.Context, absPath, relPath string) er
 		// Look for /* ... Copyright ... */ pattern
 		// We use a simplified regex that looks for /* followed by Copyright within the buffer
 		if regexp.MustCompile(`(?s)/\*.*?Copyright`).Match(checkBuf) {
-			return nil
+			return nil, nil
 		}
 	}
 
-	log.Info("Adding file header", "file", relPath)
-
-	header, err := p.generateHeader(commentStyle)
+	text, err := header.render(commentStyle, fmt.Sprint(time.Now().Year()))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	lines := strings.Split(string(content), "\n")
 	var newLines []string
 
-	hasShebang := len(lines) > 0 && strings.HasPrefix(lines[0], "#!")
-
 	if hasShebang {
 		newLines = append(newLines, lines[0])
 		newLines = append(newLines, "")
-		newLines = append(newLines, header)
+		newLines = append(newLines, text)
 		if len(lines) > 1 {
 			newLines = append(newLines, lines[1:]...)
 		}
 	} else {
-		newLines = append(newLines, header)
+		newLines = append(newLines, text)
 		newLines = append(newLines, lines...)
 	}
 
 	output := strings.Join(newLines, "\n")
-	return os.WriteFile(absPath, []byte(output), 0644)
+	return &fix{relPath: relPath, before: content, after: []byte(output), reason: reasonMissing}, nil
+}
+
+// renderLines returns the lines of the header of the given year, extended to the current year if configured.
+func (p *processor) renderLines(header header, style, year string) ([]string, error) {
+	if p.config.Normalize != nil && p.config.Normalize.Years {
+		year = extendYear(year, time.Now().Year())
+	}
+	text, err := header.render(style, year)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), nil
+}
+
+// replaceHeader returns the fix replacing the n lines of the header at start with want.
+func replaceHeader(relPath string, content []byte, lines []string, start, n int, want []string, reason string) *fix {
+	rest := lines[start+n:]
+	if len(rest) > 0 && strings.TrimSpace(rest[0]) != "" {
+		// Headers are separated from what follows them by an empty line.
+		want = append(want, "")
+	}
+	newLines := slices.Concat(lines[:start], want, rest)
+	return &fix{relPath: relPath, before: content, after: []byte(strings.Join(newLines, "\n")), reason: reason}
+}
+
+// extendYear returns year ("2023" or "2023-2025") extended to now ("2023-2026"), or now if it started then.
+func extendYear(year string, now int) string {
+	first, _, _ := strings.Cut(year, "-")
+	if first == fmt.Sprint(now) {
+		return first
+	}
+	return fmt.Sprintf("%s-%d", first, now)
+}
+
+var (
+	copyrightRegexp         = regexp.MustCompile(`^Copyright\s+(?:(?:\([cC]\)|©)\s+)?(?:(\d{4}(?:\s*-\s*\d{4})?),?\s+)?(.*)`)
+	allRightsReservedRegexp = regexp.MustCompile(`(?i)[\s.,]*all rights reserved\.?\s*$`)
+)
+
+// leadingCopyright returns the number of lines of the comment at the start of lines, if it has a copyright line,
+// with the holder and year (if any) of the copyright. Go files can also start with a block comment.
+func leadingCopyright(lines []string, style string, blockComments bool) (n int, holder, year string) {
+	var text []string
+	if blockComments && strings.HasPrefix(strings.TrimSpace(lines[0]), "/*") {
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if i == 0 {
+				line = strings.TrimPrefix(line, "/*")
+			}
+			line, closed := strings.CutSuffix(line, "*/")
+			text = append(text, strings.TrimPrefix(strings.TrimSpace(line), "*"))
+			if closed {
+				n = i + 1
+				break
+			}
+		}
+	} else {
+		for _, line := range lines {
+			if !strings.HasPrefix(line, style) {
+				break
+			}
+			text = append(text, strings.TrimPrefix(line, style))
+			n++
+		}
+	}
+	for _, line := range text {
+		if m := copyrightRegexp.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			holder = allRightsReservedRegexp.ReplaceAllString(m[2], "")
+			return n, strings.TrimRight(holder, ". "), strings.ReplaceAll(m[1], " ", "")
+		}
+	}
+	return 0, "", ""
+}
+
+// allows returns whether the headers of holder are left as they are.
+func (n *Normalize) allows(holder string) bool {
+	return slices.ContainsFunc(n.AllowedHolders, func(allowed string) bool {
+		return strings.EqualFold(strings.TrimRight(allowed, ". "), holder)
+	})
+}
+
+// commentBlock returns the leading comment lines of lines, up to n of them, without trailing whitespace.
+func commentBlock(lines []string, style string, n int) []string {
+	var block []string
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if len(block) == n || (line != style && !strings.HasPrefix(line, style+" ")) {
+			break
+		}
+		block = append(block, line)
+	}
+	return block
 }
 
 func getCommentStyle(name, ext string) string {
@@ -232,28 +590,47 @@ func getCommentStyle(name, ext string) string {
 	return ""
 }
 
-func (p *processor) generateHeader(style string) (string, error) {
-	year := time.Now().Year()
-
-	if p.config.License != "apache-2.0" {
-		return "", fmt.Errorf("unsupported license: %s", p.config.License)
-	}
-
-	var lines []string
-	lines = append(lines, fmt.Sprintf("%s Copyright %d %s", style, year, p.config.CopyrightHolder))
-	lines = append(lines, style)
-	lines = append(lines, fmt.Sprintf("%s Licensed under the Apache License, Version 2.0 (the \"License\");", style))
-	lines = append(lines, fmt.Sprintf("%s you may not use this file except in compliance with the License.", style))
-	lines = append(lines, fmt.Sprintf("%s You may obtain a copy of the License at", style))
-	lines = append(lines, style)
-	lines = append(lines, fmt.Sprintf("%s     http://www.apache.org/licenses/LICENSE-2.0", style))
-	lines = append(lines, style)
-	lines = append(lines, fmt.Sprintf("%s Unless required by applicable law or agreed to in writing, software", style))
-	lines = append(lines, fmt.Sprintf("%s distributed under the License is distributed on an \"AS IS\" BASIS,", style))
-	lines = append(lines, fmt.Sprintf("%s WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.", style))
-	lines = append(lines, fmt.Sprintf("%s See the License for the specific language governing permissions and", style))
-	lines = append(lines, fmt.Sprintf("%s limitations under the License.", style))
-	lines = append(lines, "")
-
-	return strings.Join(lines, "\n"), nil
+// override returns the override of the deepest directory containing the file at relPath, or nil if there is none.
+func (c *Config) override(relPath string) *Override {
+	relPath = filepath.ToSlash(relPath)
+	var match *Override
+	for i := range c.Overrides {
+		dir := strings.Trim(filepath.ToSlash(c.Overrides[i].Path), "/")
+		if !strings.HasPrefix(relPath, dir+"/") {
+			continue
+		}
+		if match == nil || len(dir) > len(strings.Trim(filepath.ToSlash(match.Path), "/")) {
+			match = &c.Overrides[i]
+		}
+	}
+	return match
+}
+
+// skips returns true if the override applying to the file at relPath leaves it without a header.
+func (c *Config) skips(relPath string) bool {
+	match := c.override(relPath)
+	return match != nil && match.Skip
+}
+
+// header returns the header of the file at relPath, applying the override of the deepest directory containing it.
+func (c *Config) header(relPath string) header {
+	h := header{License: c.License, CopyrightHolder: c.CopyrightHolder, SPDXOnly: c.SPDXOnly, Template: c.Template}
+	match := c.override(relPath)
+	if match == nil {
+		return h
+	}
+	if match.License != "" {
+		h.License = match.License
+		h.Template = ""
+	}
+	if match.CopyrightHolder != "" {
+		h.CopyrightHolder = match.CopyrightHolder
+	}
+	if match.SPDXOnly != nil {
+		h.SPDXOnly = *match.SPDXOnly
+	}
+	if match.Template != "" {
+		h.Template = match.Template
+	}
+	return h
 }
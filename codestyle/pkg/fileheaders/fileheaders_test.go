@@ -16,10 +16,18 @@ package fileheaders
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/gittest"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
 )
 
 func TestRun_Skip(t *testing.T) {
@@ -194,3 +202,414 @@ package main
 		t.Errorf("File was modified but should have been skipped. Content:\n%s", string(content))
 	}
 }
+
+func TestRun_Overrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".ap/headers.yaml": `
+license: apache-2.0
+copyrightHolder: Google LLC
+overrides:
+- path: pkg/forked
+  copyrightHolder: The Kubernetes Authors
+- path: pkg/forked/mit
+  skip: true
+`,
+		"main.go":                 "package main\n",
+		"pkg/forked/forked.go":    "package forked\n",
+		"pkg/forked/mit/mit.go":   "package mit\n",
+		"pkg/forkedother/main.go": "package forkedother\n",
+		// Files in skipped directories are skipped even when passed explicitly.
+		"vendor/example.com/lib/lib.go": "package lib\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Run(context.Background(), tmpDir, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := Run(context.Background(), tmpDir, []string{"vendor/example.com/lib/lib.go"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	wantHolder := map[string]string{
+		"main.go":                       "Google LLC",
+		"pkg/forked/forked.go":          "The Kubernetes Authors",
+		"pkg/forked/mit/mit.go":         "",
+		"pkg/forkedother/main.go":       "Google LLC",
+		"vendor/example.com/lib/lib.go": "",
+	}
+	for path, holder := range wantHolder {
+		content, err := os.ReadFile(filepath.Join(tmpDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if holder == "" {
+			if string(content) != files[path] {
+				t.Errorf("%s was given a header, but should have been skipped:\n%s", path, content)
+			}
+			continue
+		}
+		if !strings.Contains(string(content), " "+holder+"\n") {
+			t.Errorf("%s does not have a header for %s:\n%s", path, holder, content)
+		}
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	if got, err := findConfig(tmpDir); err != nil || got != "" {
+		t.Errorf("findConfig() without a config = %q, %v; want none", got, err)
+	}
+
+	// The config is looked for in .ap first, falling back to the older names.
+	for i := len(ConfigFiles) - 1; i >= 0; i-- {
+		path := filepath.Join(tmpDir, ConfigFiles[i])
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("license: apache-2.0\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := findConfig(tmpDir)
+		if err != nil {
+			t.Fatalf("findConfig failed: %v", err)
+		}
+		if got != path {
+			t.Errorf("findConfig() = %q, want %q", got, path)
+		}
+	}
+}
+
+func TestRun_Licenses(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".ap/headers.yaml": `
+license: mit
+copyrightHolder: Example Authors
+overrides:
+- path: bsd
+  license: bsd-3-clause
+- path: spdx
+  license: GPL-2.0-only
+  spdxOnly: true
+- path: custom
+  copyrightHolder: The Custom Project
+  template: |
+    This file is part of {{.CopyrightHolder}} ({{.Year}}).
+
+    SPDX-License-Identifier: {{.SPDX}}
+`,
+		"main.go":        "package main\n",
+		"bsd/bsd.go":     "package bsd\n",
+		"spdx/spdx.sh":   "#!/bin/bash\necho hi\n",
+		"custom/main.go": "package custom\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The second run must recognize the headers added by the first, including those without a copyright line.
+	for i := 0; i < 2; i++ {
+		if err := Run(context.Background(), tmpDir, nil); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	}
+
+	year := fmt.Sprint(time.Now().Year())
+	want := map[string]string{
+		"main.go": "// Copyright " + year + " Example Authors\n" +
+			"//\n" +
+			"// Use of this source code is governed by an MIT-style\n" +
+			"// license that can be found in the LICENSE file or at\n" +
+			"// https://opensource.org/licenses/MIT.\n" +
+			"\npackage main\n",
+		"bsd/bsd.go": "// Copyright " + year + " Example Authors\n" +
+			"//\n" +
+			"// Use of this source code is governed by a BSD-style\n" +
+			"// license that can be found in the LICENSE file.\n" +
+			"\npackage bsd\n",
+		"spdx/spdx.sh": "#!/bin/bash\n\n" +
+			"# Copyright " + year + " Example Authors\n" +
+			"# SPDX-License-Identifier: GPL-2.0-only\n" +
+			"\necho hi\n",
+		"custom/main.go": "// This file is part of The Custom Project (" + year + ").\n" +
+			"//\n" +
+			"// SPDX-License-Identifier: MIT\n" +
+			"\npackage custom\n",
+	}
+	for path, want := range want {
+		got, err := os.ReadFile(filepath.Join(tmpDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s =\n%s\nwant\n%s", path, got, want)
+		}
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown license":     "license: gpl-3.0\n",
+		"spdxOnly no license": "spdxOnly: true\n",
+		"bad template":        "template: \"{{.Year\"\n",
+		"unknown field":       "template: \"{{.Author}}\"\n",
+		"bad override":        "license: mit\noverrides:\n- path: x\n  license: wtfpl\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), "headers.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadConfig(path); err == nil {
+			t.Errorf("%s: loadConfig() succeeded, want an error", name)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".ap/headers.yaml": "license: bsd-3-clause\ncopyrightHolder: Example Authors\nskip: [\"*.yaml\"]\n",
+		"missing.go":       "package main\n",
+		"truncated.go":     "// Copyright 2020 Example Authors\npackage main\n",
+		"altered.sh":       "#!/bin/sh\n\n# Copyright 2021 Example Authors\n#\n# Use of this source code is governed by a BSD-style\n# license.\n\necho hi\n",
+		"ok.go":            "// Copyright 2019-2024 Example Authors\n//\n// Use of this source code is governed by a BSD-style\n// license that can be found in the LICENSE file.\n// More comments.\n\npackage main\n",
+		"other.go":         "// Copyright 2020 Someone Else\n\npackage main\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err := Check(context.Background(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	var got []string
+	diffs := map[string]string{}
+	for _, p := range problems {
+		got = append(got, p.String())
+		diffs[p.Path] = p.Diff
+	}
+	slices.Sort(got)
+	want := []string{"altered.sh: malformed header", "missing.go: missing header", "truncated.go: malformed header"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Check() = %v, want %v", got, want)
+	}
+
+	// Malformed headers are fixed in the year they were added.
+	wantDiff := `--- a/altered.sh
++++ b/altered.sh
+@@ -3,6 +3,6 @@
+ # Copyright 2021 Example Authors
+ #
+ # Use of this source code is governed by a BSD-style
+-# license.
++# license that can be found in the LICENSE file.
+ 
+ echo hi
+`
+	if diffs["altered.sh"] != wantDiff {
+		t.Errorf("diff of altered.sh =\n%s\nwant\n%s", diffs["altered.sh"], wantDiff)
+	}
+	if !strings.HasPrefix(diffs["missing.go"], "--- a/missing.go\n+++ b/missing.go\n@@ -1,1 +1,6 @@\n+// Copyright ") {
+		t.Errorf("diff of missing.go =\n%s\nwant the header to be added at the top", diffs["missing.go"])
+	}
+
+	// Check changes nothing, and after Run there is nothing left to report.
+	for path, content := range files {
+		if got, err := os.ReadFile(filepath.Join(tmpDir, path)); err != nil || string(got) != content {
+			t.Errorf("Check changed %s", path)
+		}
+	}
+	if err := Run(context.Background(), tmpDir, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if problems, err := Check(context.Background(), tmpDir, nil); err != nil || len(problems) != 0 {
+		t.Errorf("Check() after Run = %v, %v; want no problems", problems, err)
+	}
+	fixed, err := os.ReadFile(filepath.Join(tmpDir, "truncated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFixed := "// Copyright 2020 Example Authors\n//\n// Use of this source code is governed by a BSD-style\n// license that can be found in the LICENSE file.\n\npackage main\n"
+	if string(fixed) != wantFixed {
+		t.Errorf("truncated.go after Run =\n%s\nwant\n%s", fixed, wantFixed)
+	}
+}
+
+func TestRun_Normalize(t *testing.T) {
+	tmpDir := t.TempDir()
+	year := time.Now().Year()
+	header := func(year string) string {
+		return "// Copyright " + year + " Example Authors\n//\n// Use of this source code is governed by a BSD-style\n// license that can be found in the LICENSE file.\n"
+	}
+	files := map[string]string{
+		".ap/headers.yaml": `
+license: bsd-3-clause
+copyrightHolder: Example Authors
+skip: ["*.yaml"]
+normalize:
+  headers: true
+  years: true
+  allowedHolders: [The Kubernetes Authors.]
+`,
+		"holder.go":  "// Copyright (c) 2019 Old Corp. All rights reserved.\n// Licensed under something else.\npackage main\n",
+		"block.go":   "/*\nCopyright 2018-2020 Example Authors.\n\nSome license text.\n*/\n\npackage main\n",
+		"kube.go":    "/*\nCopyright 2017 The Kubernetes Authors.\n*/\n\npackage main\n",
+		"stale.go":   header("2021") + "\npackage main\n",
+		"current.go": header(fmt.Sprint(year)) + "\npackage main\n",
+		"doc.go":     "// Package doc mentions the Copyright 2020 of someone.\npackage doc\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err := Check(context.Background(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	slices.Sort(got)
+	want := []string{"block.go: outdated header", "doc.go: missing header", "holder.go: outdated header", "stale.go: outdated header"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Check() = %v, want %v", got, want)
+	}
+
+	if err := Run(context.Background(), tmpDir, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	wantFiles := map[string]string{
+		"holder.go":  header(fmt.Sprintf("2019-%d", year)) + "\npackage main\n",
+		"block.go":   header(fmt.Sprintf("2018-%d", year)) + "\npackage main\n",
+		"kube.go":    files["kube.go"],
+		"stale.go":   header(fmt.Sprintf("2021-%d", year)) + "\npackage main\n",
+		"current.go": files["current.go"],
+	}
+	for path, want := range wantFiles {
+		got, err := os.ReadFile(filepath.Join(tmpDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s after Run =\n%s\nwant\n%s", path, got, want)
+		}
+	}
+}
+
+func TestRun_GitFiles(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	tmpDir := repo.Dir
+	files := map[string]string{
+		".ap/headers.yaml":     "license: apache-2.0\ncopyrightHolder: Google LLC\ngitFiles: true\nskip: [\"*.yaml\"]\n",
+		".gitignore":           ".build/\n*.gen.go\n",
+		"tracked.go":           "package main\n",
+		"untracked.go":         "package main\n",
+		"ignored.gen.go":       "package main\n",
+		".build/out/main.go":   "package main\n",
+		"deleted/unstaged.go":  "package main\n",
+		"nested/dir/ok.go":     "package dir\n",
+		"nested/dir/.build.go": "package dir\n",
+	}
+	for path, content := range files {
+		repo.WriteFile(path, content)
+	}
+	repo.Git("add", "tracked.go", "deleted/unstaged.go", "nested")
+	if err := os.RemoveAll(filepath.Join(tmpDir, "deleted")); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := Check(context.Background(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Path)
+	}
+	slices.Sort(got)
+	want := []string{"nested/dir/.build.go", "nested/dir/ok.go", "tracked.go", "untracked.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Check() = %v, want %v", got, want)
+	}
+
+	// Files given explicitly are skipped too if git ignores them.
+	problems, err = Check(context.Background(), tmpDir, []string{"ignored.gen.go", "untracked.go"})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Path != "untracked.go" {
+		t.Errorf("Check(ignored.gen.go, untracked.go) = %v, want only untracked.go", problems)
+	}
+}
+
+func TestCheck_Cached(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	config := "license: apache-2.0\ncopyrightHolder: Google LLC\nskip: [\"*.yaml\"]\n"
+	files := map[string]string{
+		".ap/headers.yaml": config,
+		"cached.go":        "package main\n",
+		"new.go":           "package main\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Record cached.go as checked, as if it had a header when it was last checked with the same config.
+	cm, err := cache.NewManager(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	cm.MarkDone("fileheaders", cache.Key(hash(config), "cached.go", hash(files["cached.go"]), fmt.Sprint(time.Now().Year())), nil)
+	if err := cm.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := Check(context.Background(), tmpDir, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Path != "new.go" {
+		t.Errorf("Check() = %v, want only new.go (cached.go is cached)", problems)
+	}
+}
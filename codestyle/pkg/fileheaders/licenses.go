@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileheaders
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// license is a license that headers can be generated for by name.
+type license struct {
+	// spdx is the SPDX identifier of the license.
+	spdx string
+	// text is the template of the header, after the copyright line.
+	text string
+}
+
+// licenses are the licenses that can be named in Config.License.
+var licenses = map[string]license{
+	"apache-2.0": {
+		spdx: "Apache-2.0",
+		text: `Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.`,
+	},
+	"mit": {
+		spdx: "MIT",
+		text: `Use of this source code is governed by an MIT-style
+license that can be found in the LICENSE file or at
+https://opensource.org/licenses/MIT.`,
+	},
+	"bsd-3-clause": {
+		spdx: "BSD-3-Clause",
+		text: `Use of this source code is governed by a BSD-style
+license that can be found in the LICENSE file.`,
+	},
+}
+
+// copyrightLine is the first line of the headers of named licenses.
+const copyrightLine = "Copyright {{.Year}} {{.CopyrightHolder}}"
+
+// header is the header of a file, after the overrides for its directory are applied.
+type header struct {
+	License         string
+	CopyrightHolder string
+	Template        string
+	SPDXOnly        bool
+}
+
+// templateData is what header templates can refer to.
+type templateData struct {
+	Year            string
+	CopyrightHolder string
+	// SPDX is the SPDX identifier of the license.
+	SPDX string
+}
+
+// template returns the template of the header, without comment markers.
+func (h header) template() (string, error) {
+	if h.Template != "" {
+		return strings.TrimRight(h.Template, "\n"), nil
+	}
+	if h.SPDXOnly {
+		return copyrightLine + "\nSPDX-License-Identifier: {{.SPDX}}", nil
+	}
+	l, ok := licenses[h.License]
+	if !ok {
+		return "", fmt.Errorf("unsupported license %q (supported: %s; set spdxOnly or template for other licenses)", h.License, strings.Join(slices.Sorted(maps.Keys(licenses)), ", "))
+	}
+	return copyrightLine + "\n\n" + l.text, nil
+}
+
+// spdx returns the SPDX identifier of the license: that of a named license, or else the license as written.
+func (h header) spdx() string {
+	if l, ok := licenses[h.License]; ok {
+		return l.spdx
+	}
+	return h.License
+}
+
+// validate checks that a header can be generated.
+func (h header) validate() error {
+	if h.SPDXOnly && h.License == "" && h.Template == "" {
+		return fmt.Errorf("spdxOnly requires a license")
+	}
+	_, err := h.render("//", "2026")
+	return err
+}
+
+// render returns the header as comments in the given style, followed by an empty line.
+func (h header) render(style string, year string) (string, error) {
+	text, err := h.expand(year)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, style)
+			continue
+		}
+		lines = append(lines, style+" "+line)
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// expand fills in the template of the header.
+func (h header) expand(year string) (string, error) {
+	text, err := h.template()
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New("header").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error in header template: %w", err)
+	}
+	var sb strings.Builder
+	data := templateData{Year: year, CopyrightHolder: h.CopyrightHolder, SPDX: h.spdx()}
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error in header template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// yearPlaceholder stands in for the year while building the regexp matching existing headers.
+const yearPlaceholder = "\x00year\x00"
+
+// existing returns a regexp matching the first line of the header as it would have been added in any year,
+// so that headers without a copyright line are recognized too. The year is captured as "year".
+func (h header) existing(style string) (*regexp.Regexp, error) {
+	text, err := h.expand(yearPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	pattern := regexp.QuoteMeta(style + " " + strings.TrimSpace(first))
+	// Only the first occurrence captures the year, as group names must be unique.
+	pattern = strings.Replace(pattern, yearPlaceholder, `(?P<year>\d{4}(?:-\d{4})?)`, 1)
+	pattern = strings.ReplaceAll(pattern, yearPlaceholder, `\d{4}(?:-\d{4})?`)
+	return regexp.Compile(`(?m)^` + pattern + `[ \t\r]*$`)
+}
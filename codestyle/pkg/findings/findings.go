@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package findings holds the problems reported by linters, and renders them in
+// the output formats shared by ap lint and kubelint.
+package findings
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Severity is how serious a finding is; only errors fail a lint.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem reported by a linter at a position in a file.
+type Finding struct {
+	// Path is the path of the file, relative to the repository root where possible.
+	Path string
+	// Line and Column are 1-based; 0 if unknown.
+	Line   int
+	Column int
+
+	// Rule is the name of the rule or analyzer that reported the finding.
+	Rule     string
+	Message  string
+	Severity Severity
+}
+
+// String returns the finding in the conventional path:line:col: message form.
+func (f Finding) String() string {
+	var sb strings.Builder
+	sb.WriteString(f.Path)
+	if f.Line > 0 {
+		fmt.Fprintf(&sb, ":%d", f.Line)
+		if f.Column > 0 {
+			fmt.Fprintf(&sb, ":%d", f.Column)
+		}
+	}
+	sb.WriteString(": ")
+	if f.Severity == SeverityWarning {
+		sb.WriteString("warning: ")
+	}
+	sb.WriteString(f.Message)
+	if f.Rule != "" {
+		fmt.Fprintf(&sb, " [%s]", f.Rule)
+	}
+	return sb.String()
+}
+
+// HasErrors returns true if any of findings is an error.
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool {
+		return f.Severity != SeverityWarning
+	})
+}
+
+// Relativize rewrites the absolute paths in findings to be relative to root.
+func Relativize(findings []Finding, root string) {
+	for i := range findings {
+		if !filepath.IsAbs(findings[i].Path) {
+			continue
+		}
+		if rel, err := filepath.Rel(root, findings[i].Path); err == nil && !strings.HasPrefix(rel, "..") {
+			findings[i].Path = rel
+		}
+	}
+}
+
+// Sort orders findings by position, then rule.
+func Sort(findings []Finding) {
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+			cmp.Compare(a.Rule, b.Rule),
+		)
+	})
+}
+
+// Format is an output format for findings.
+type Format string
+
+const (
+	// FormatText prints one finding per line, as path:line:col: message [rule].
+	FormatText Format = "text"
+	// FormatGitHub prints GitHub Actions workflow commands, which annotate the files in pull requests.
+	FormatGitHub Format = "github"
+	// FormatSARIF prints a SARIF 2.1.0 log, as accepted by GitHub code scanning.
+	FormatSARIF Format = "sarif"
+	// FormatJUnit prints a JUnit XML report, with a failing test case per finding.
+	FormatJUnit Format = "junit"
+)
+
+// Formats lists the supported output formats.
+var Formats = []Format{FormatText, FormatGitHub, FormatSARIF, FormatJUnit}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == s {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (supported: %s)", s, strings.Join(formatNames(), ", "))
+}
+
+func formatNames() []string {
+	var names []string
+	for _, format := range Formats {
+		names = append(names, string(format))
+	}
+	return names
+}
+
+// Write renders findings in format to w; tool names the linter in the SARIF and JUnit reports.
+func Write(w io.Writer, format Format, tool string, findings []Finding) error {
+	findings = slices.Clone(findings)
+	Sort(findings)
+
+	switch format {
+	case FormatText, "":
+		for _, f := range findings {
+			if _, err := fmt.Fprintln(w, f.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatGitHub:
+		return writeGitHub(w, findings)
+	case FormatSARIF:
+		return writeSARIF(w, tool, findings)
+	case FormatJUnit:
+		return writeJUnit(w, tool, findings)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package findings
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/goldentest"
+)
+
+var testFindings = []Finding{
+	{Path: "k8s/db.yaml", Line: 12, Rule: "statefulset-updatestrategy", Message: "StatefulSet updateStrategy should be explicitly set.", Severity: SeverityError},
+	{Path: "pkg/cmd/root.go", Line: 40, Column: 3, Rule: "cobracmd", Message: "use RunE instead of Run, so that errors are returned", Severity: SeverityWarning},
+	{Path: "pkg/a.go", Line: 7, Column: 2, Rule: "printf", Message: "fmt.Sprintf format %d has arg \"x\" of wrong type string,\nsee docs", Severity: SeverityError},
+}
+
+func TestWrite(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Write(&out, format, "ap lint", testFindings); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			goldentest.CompareFile(t, filepath.Join("testdata", string(format)+".golden"), out.Bytes())
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors(testFindings[1:2]) {
+		t.Errorf("HasErrors(warnings) = true, want false")
+	}
+	if !HasErrors(testFindings) {
+		t.Errorf("HasErrors(errors) = false, want true")
+	}
+}
+
+func TestRelativize(t *testing.T) {
+	findings := []Finding{{Path: "/repo/pkg/a.go"}, {Path: "/elsewhere/b.go"}, {Path: "c.go"}}
+	Relativize(findings, "/repo")
+	for i, want := range []string{"pkg/a.go", "/elsewhere/b.go", "c.go"} {
+		if findings[i].Path != want {
+			t.Errorf("Relativize()[%d] = %q, want %q", i, findings[i].Path, want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if got, err := ParseFormat("sarif"); err != nil || got != FormatSARIF {
+		t.Errorf("ParseFormat(sarif) = %q, %v", got, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("ParseFormat(xml) succeeded, want error")
+	}
+}
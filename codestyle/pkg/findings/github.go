@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package findings
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeGitHub prints findings as GitHub Actions workflow commands, e.g.
+//
+//	::error file=k8s/app.yaml,line=3,title=statefulset-updatestrategy::StatefulSet updateStrategy should be explicitly set.
+func writeGitHub(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		command := "error"
+		if f.Severity == SeverityWarning {
+			command = "warning"
+		}
+		props := []string{"file=" + escapeProperty(f.Path)}
+		if f.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", f.Line))
+		}
+		if f.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", f.Column))
+		}
+		if f.Rule != "" {
+			props = append(props, "title="+escapeProperty(f.Rule))
+		}
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", command, strings.Join(props, ","), escapeData(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var dataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+var propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return dataEscaper.Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command, which also must not contain ':' or ','.
+func escapeProperty(s string) string {
+	return propertyEscaper.Replace(s)
+}
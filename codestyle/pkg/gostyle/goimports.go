@@ -0,0 +1,172 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gostyle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/imports"
+	"k8s.io/klog/v2"
+)
+
+// runGoimports runs goimports in-process over the Go files: missing imports are added, unused ones removed,
+// and each import block is regrouped into standard library, external and module-local imports.
+// The module-local imports are those under localPrefix, or under the module path of each file if it is empty.
+func runGoimports(ctx context.Context, repoRoot string, files []string, skip []string, localPrefix string, cm *cache.Manager) error {
+	log := klog.FromContext(ctx)
+	filesToFormat, err := goFiles(repoRoot, files, skip)
+	if err != nil {
+		return err
+	}
+
+	modules := &moduleCache{root: repoRoot, paths: map[string]string{}}
+	var errs []error
+	formatted := 0
+	for _, f := range filesToFormat {
+		prefix := localPrefix
+		if prefix == "" {
+			prefix = modules.modulePath(filepath.Dir(f))
+		}
+		// The local prefix changes the output, so it is part of the cache key.
+		formatter := "goimports:" + prefix
+
+		if cm != nil {
+			if meta, err := cm.GetOrUpdateMetadata(f); err == nil {
+				if _, done := cm.Done(formatter, meta.Hash); done {
+					continue
+				}
+			}
+		}
+
+		changed, err := goimportsFile(f, prefix)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("goimports failed on %s: %w", f, err))
+			continue
+		}
+		if changed {
+			formatted++
+		}
+
+		if cm != nil {
+			// Re-check metadata, as goimports might have changed the file.
+			if meta, err := cm.GetOrUpdateMetadata(f); err == nil {
+				cm.MarkDone(formatter, meta.Hash, nil)
+			}
+		}
+	}
+	if formatted > 0 {
+		log.Info("Ran goimports", "files", formatted)
+	}
+	return errors.Join(errs...)
+}
+
+// goimportsFile formats the Go file at path in place, and reports whether it changed.
+func goimportsFile(path string, localPrefix string) (bool, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	// imports.LocalPrefix is global; files are processed one at a time.
+	imports.LocalPrefix = localPrefix
+	out, err := imports.Process(path, ungroupImports(src), nil)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(out, src) {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ungroupImports removes the blank lines inside the import blocks of src.
+// goimports only sorts imports within the groups separated by blank lines, and separates the
+// standard library, external and local imports of each group; with a single group per block,
+// every import ends up in its canonical group.
+func ungroupImports(src []byte) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		// goimports reports the error.
+		return src
+	}
+
+	blank := map[int]bool{}
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+		// Line numbers are 1-based, so lines[start:end] are the lines between the parentheses.
+		start, end := fset.Position(gen.Lparen).Line, fset.Position(gen.Rparen).Line-1
+		for i := start; i < end; i++ {
+			if strings.TrimSpace(string(lines[i])) == "" {
+				blank[i] = true
+			}
+		}
+	}
+	if len(blank) == 0 {
+		return src
+	}
+
+	var out bytes.Buffer
+	for i, line := range lines {
+		if !blank[i] {
+			out.Write(line)
+		}
+	}
+	return out.Bytes()
+}
+
+// moduleCache finds the module paths of directories under root.
+type moduleCache struct {
+	root  string
+	paths map[string]string
+}
+
+// modulePath returns the module path of the go.mod in dir or the closest directory above it,
+// up to the root, or "" if there is none.
+func (m *moduleCache) modulePath(dir string) string {
+	if path, ok := m.paths[dir]; ok {
+		return path
+	}
+	path := ""
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		path = modfile.ModulePath(data)
+	} else if parent := filepath.Dir(dir); parent != dir && strings.HasPrefix(parent, m.root) {
+		path = m.modulePath(parent)
+	}
+	m.paths[dir] = path
+	return path
+}
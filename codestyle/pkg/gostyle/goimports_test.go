@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gostyle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_Goimports(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		".ap/go.yaml": "goimports:\n  enabled: true\n",
+		"go.mod":      "module example.com/app\n\ngo 1.24\n",
+		"main.go": `package main
+
+import (
+	"example.com/app/pkg/util"
+	"fmt"
+
+	"os"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func main() {
+	fmt.Println(util.Name, cobra.Command{}, os.Args)
+}
+`,
+		"pkg/util/util.go": "package util\n\nconst Name = \"util\"\n",
+	}
+	for relPath, content := range files {
+		p := filepath.Join(tmpDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Run(t.Context(), tmpDir, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(tmpDir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The unused import is removed, and the others are grouped with the module's own packages last.
+	want := `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"example.com/app/pkg/util"
+)
+
+func main() {
+	fmt.Println(util.Name, cobra.Command{}, os.Args)
+}
+`
+	if string(got) != want {
+		t.Errorf("main.go =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUngroupImports(t *testing.T) {
+	src := "package p\n\nimport (\n\t\"b\"\n\n\t// Comment.\n\t\"a\"\n)\n\nvar x = `\n\n`\n"
+	want := "package p\n\nimport (\n\t\"b\"\n\t// Comment.\n\t\"a\"\n)\n\nvar x = `\n\n`\n"
+	if got := string(ungroupImports([]byte(src))); got != want {
+		t.Errorf("ungroupImports() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -32,7 +32,7 @@ func Run(ctx context.Context, repoRoot string, files []string) error {
 	log := klog.FromContext(ctx)
 
 	// Initialize cache
-	cm, err := cache.NewManager()
+	cm, err := cache.NewManager(repoRoot)
 	if err != nil {
 		log.V(2).Info("Failed to initialize cache", "error", err)
 	} else {
@@ -53,25 +53,36 @@ func Run(ctx context.Context, repoRoot string, files []string) error {
 			return err
 		}
 	}
+	if cfg.IsGoimportsEnabled() {
+		if err := runGoimports(ctx, repoRoot, files, cfg.Skip, cfg.GoimportsLocalPrefix(), cm); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-func runGofmt(ctx context.Context, repoRoot string, files []string, skip []string, cm *cache.Manager) error {
-	log := klog.FromContext(ctx)
+// goFiles returns the absolute paths of the Go files among files, or of all Go files under repoRoot if files is empty.
+// Files matching skip are left out either way.
+func goFiles(repoRoot string, files []string, skip []string) ([]string, error) {
+	ignores := append([]string{"vendor", ".git"}, skip...)
 	var filesToFormat []string
 	if len(files) > 0 {
+		ignoreList := walker.NewIgnoreList(ignores)
 		for _, f := range files {
 			if strings.HasSuffix(f, ".go") {
 				absPath := f
 				if !filepath.IsAbs(f) {
 					absPath = filepath.Join(repoRoot, f)
 				}
+				if rel, err := filepath.Rel(repoRoot, absPath); err == nil && ignoreList.ShouldIgnoreFile(rel) {
+					continue
+				}
 				filesToFormat = append(filesToFormat, absPath)
 			}
 		}
 	} else {
-		fv := walker.NewFileView(repoRoot, append([]string{"vendor", ".git"}, skip...))
+		fv := walker.NewFileView(repoRoot, ignores)
 		err := fv.Walk(func(f walker.File) error {
 			if strings.HasSuffix(f.Path, ".go") {
 				filesToFormat = append(filesToFormat, f.Path)
@@ -79,9 +90,18 @@ func runGofmt(ctx context.Context, repoRoot string, files []string, skip []strin
 			return nil
 		})
 		if err != nil {
-			return fmt.Errorf("error walking for go files: %w", err)
+			return nil, fmt.Errorf("error walking for go files: %w", err)
 		}
 	}
+	return filesToFormat, nil
+}
+
+func runGofmt(ctx context.Context, repoRoot string, files []string, skip []string, cm *cache.Manager) error {
+	log := klog.FromContext(ctx)
+	filesToFormat, err := goFiles(repoRoot, files, skip)
+	if err != nil {
+		return err
+	}
 
 	// Filter files using cache
 	var dirtyFiles []string
@@ -92,7 +112,7 @@ func runGofmt(ctx context.Context, repoRoot string, files []string, skip []strin
 				dirtyFiles = append(dirtyFiles, f)
 				continue
 			}
-			if !cm.IsGofmtDone(meta.Hash) {
+			if _, done := cm.Done("gofmt", meta.Hash); !done {
 				dirtyFiles = append(dirtyFiles, f)
 			}
 		}
@@ -133,7 +153,7 @@ func runGofmt(ctx context.Context, repoRoot string, files []string, skip []strin
 			if err != nil {
 				continue
 			}
-			cm.MarkGofmtDone(meta.Hash)
+			cm.MarkDone("gofmt", meta.Hash, nil)
 		}
 	}
 
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package largecopy flags large structs that are copied on every call: parameters passed by value,
+// and methods with value receivers. Passing a pointer avoids the copy.
+package largecopy
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// DefaultMaxSize is the size in bytes over which a struct passed by value is reported, if not configured.
+const DefaultMaxSize = 128
+
+var maxSize int64
+
+var Analyzer = &analysis.Analyzer{
+	Name: "largecopy",
+	Doc:  "check for large structs passed by value and methods with large value receivers, which are copied on every call",
+	Run:  run,
+}
+
+func init() {
+	Analyzer.Flags.Int64Var(&maxSize, "max-size", DefaultMaxSize, "the size in bytes over which a struct passed by value is reported")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, f := range pass.Files {
+		if ast.IsGenerated(f) {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			// The sizes of the types in generic functions depend on their instantiation.
+			if !ok || isGeneric(fn) {
+				continue
+			}
+			if fn.Recv != nil {
+				for _, field := range fn.Recv.List {
+					if t, size, ok := largeStruct(pass, field.Type); ok {
+						pass.Reportf(field.Pos(), "method %s has a value receiver of type %s (%d bytes), which is copied on every call; use a pointer receiver", fn.Name.Name, t, size)
+					}
+				}
+			}
+			// Parameters of function literals are left out, as their types usually follow from where the literal is passed.
+			for _, field := range fn.Type.Params.List {
+				if t, size, ok := largeStruct(pass, field.Type); ok {
+					pass.Reportf(field.Pos(), "parameter of type %s (%d bytes) is passed by value, which copies it on every call; pass a pointer", t, size)
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// largeStruct returns the type of expr and its size if it is a struct larger than maxSize.
+func largeStruct(pass *analysis.Pass, expr ast.Expr) (types.Type, int64, bool) {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return nil, 0, false
+	}
+	if _, ok := t.Underlying().(*types.Struct); !ok {
+		return nil, 0, false
+	}
+	size := pass.TypesSizes.Sizeof(t)
+	if size <= maxSize {
+		return nil, 0, false
+	}
+	return t, size, true
+}
+
+// isGeneric returns true if fn has type parameters, or is a method of a generic type.
+func isGeneric(fn *ast.FuncDecl) bool {
+	if fn.Type.TypeParams != nil {
+		return true
+	}
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return false
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	switch recv.(type) {
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
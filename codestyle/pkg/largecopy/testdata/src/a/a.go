@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package a
+
+type small struct {
+	id   int
+	name string
+}
+
+type large struct {
+	buf [32]int64
+	id  int
+}
+
+type box[T any] struct {
+	items [32]T
+}
+
+func (s small) Name() string {
+	return s.name
+}
+
+func (l large) ID() int { // want `method ID has a value receiver of type a.large \(264 bytes\), which is copied on every call; use a pointer receiver`
+	return l.id
+}
+
+func (l *large) Reset() {
+	l.id = 0
+}
+
+func (b box[T]) First() T {
+	return b.items[0]
+}
+
+func describe(s small, l large) string { // want `parameter of type a.large \(264 bytes\) is passed by value, which copies it on every call; pass a pointer`
+	return s.name
+}
+
+func describePtr(l *large, ls []large) int {
+	return l.id + len(ls)
+}
+
+func sum[T any](b box[T]) int {
+	return len(b.items)
+}
+
+func callback() {
+	each := func(l large) int { return l.id }
+	_ = each
+}
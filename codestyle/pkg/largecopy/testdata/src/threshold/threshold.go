@@ -0,0 +1,31 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package threshold
+
+type point struct {
+	x, y int64
+}
+
+type span struct {
+	start, end point
+}
+
+func area(p point) int64 {
+	return p.x * p.y
+}
+
+func length(s span) int64 { // want `parameter of type threshold.span \(32 bytes\) is passed by value, which copies it on every call; pass a pointer`
+	return s.end.x - s.start.x
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package largefiles flags binary files and files over a size limit, so that built binaries and large
+// fixtures are not committed by accident.
+package largefiles
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+)
+
+const Rule = "largefiles"
+
+// DefaultMaxSize is the size in bytes over which files are flagged, if not configured.
+const DefaultMaxSize = 1 << 20
+
+// sniffLen is how much of a file is read to tell whether it is binary; git looks at as much.
+const sniffLen = 8000
+
+// Options configures Check.
+type Options struct {
+	// MaxSize is the size in bytes over which files are flagged (defaults to DefaultMaxSize).
+	MaxSize int64
+	// Allow lists gitignore-style patterns of the files that are never flagged, e.g. "**/testdata/**/*.png".
+	Allow []string
+	// Severity is the severity of the findings (defaults to error).
+	Severity findings.Severity
+}
+
+// Check returns a finding for each of files, relative to repoRoot, that is binary or larger than
+// opt.MaxSize, unless it matches opt.Allow. Files that do not exist are skipped.
+func Check(repoRoot string, files []string, opt Options) ([]findings.Finding, error) {
+	maxSize := opt.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	severity := opt.Severity
+	if severity == "" {
+		severity = findings.SeverityError
+	}
+	allow := walker.NewIgnoreList(opt.Allow)
+
+	var found []findings.Finding
+	for _, file := range files {
+		if allow.ShouldIgnoreFile(file) {
+			continue
+		}
+		path := filepath.Join(repoRoot, file)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		var message string
+		if info.Size() > maxSize {
+			message = fmt.Sprintf("file is %s, over the limit of %s", FormatSize(info.Size()), FormatSize(maxSize))
+		} else if binary, err := isBinary(path); err != nil {
+			return nil, err
+		} else if binary {
+			message = "file is binary"
+		}
+		if message != "" {
+			found = append(found, findings.Finding{
+				Path:     filepath.ToSlash(file),
+				Rule:     Rule,
+				Message:  message + "; add it to lint.largefiles.allow in .ap/go.yaml if it belongs in the repository",
+				Severity: severity,
+			})
+		}
+	}
+	return found, nil
+}
+
+// isBinary returns true if the start of the file at path contains a NUL byte, as git decides.
+func isBinary(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// units are the suffixes of sizes, largest first; KB and KiB are both 1024 bytes.
+var units = []struct {
+	suffix string
+	size   int64
+}{
+	{"GiB", 1 << 30}, {"GB", 1 << 30},
+	{"MiB", 1 << 20}, {"MB", 1 << 20},
+	{"KiB", 1 << 10}, {"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "1MB", "512KB" or "2048" (bytes).
+func ParseSize(s string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range units {
+		if n, ok := strings.CutSuffix(number, strings.ToUpper(unit.suffix)); ok {
+			number, multiplier = strings.TrimSpace(n), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: want a number of bytes, optionally followed by KB, MB or GB", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// FormatSize returns size in the largest unit it is at least one of, rounded to a tenth, e.g. "1.5MB".
+func FormatSize(size int64) string {
+	for _, unit := range units {
+		if strings.HasSuffix(unit.suffix, "iB") || unit.size == 1 || size < unit.size {
+			continue
+		}
+		return strconv.FormatFloat(math.Round(float64(size)*10/float64(unit.size))/10, 'f', -1, 64) + unit.suffix
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package largefiles
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"small.txt":               "hello\n",
+		"big.txt":                 strings.Repeat("x", 2048),
+		"tool":                    "\x7fELF\x00\x00",
+		"pkg/testdata/golden.png": "\x89PNG\x00",
+		"pkg/testdata/dump.json":  strings.Repeat("y", 4096),
+	}
+	for path, content := range files {
+		p := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := []string{"small.txt", "big.txt", "tool", "pkg/testdata/golden.png", "pkg/testdata/dump.json", "deleted.bin"}
+	found, err := Check(dir, names, Options{MaxSize: 1024, Allow: []string{"**/testdata/**/*.png"}})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	var got []string
+	for _, f := range found {
+		if f.Severity != findings.SeverityError || f.Rule != Rule {
+			t.Errorf("finding %v: want an error of rule %s", f, Rule)
+		}
+		message, _, _ := strings.Cut(f.Message, ";")
+		got = append(got, f.Path+": "+message)
+	}
+	want := []string{
+		"big.txt: file is 2KB, over the limit of 1KB",
+		"tool: file is binary",
+		"pkg/testdata/dump.json: file is 4KB, over the limit of 1KB",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"1MB":   1 << 20,
+		"512KB": 512 << 10,
+		"2 MiB": 2 << 20,
+		"1.5mb": 3 << 19,
+		"2048":  2048,
+		"100B":  100,
+		"1GB":   1 << 30,
+	}
+	for s, want := range tests {
+		if got, err := ParseSize(s); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "big", "-1MB", "1TB"} {
+		if _, err := ParseSize(s); err == nil {
+			t.Errorf("ParseSize(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		100:          "100B",
+		1 << 10:      "1KB",
+		1 << 20:      "1MB",
+		3 << 19:      "1.5MB",
+		1<<30 + 1000: "1GB",
+	}
+	for size, want := range tests {
+		if got := FormatSize(size); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linediff computes line-based diffs of texts and formats them as unified diffs.
+package linediff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change.
+const Context = 3
+
+// Op is the operation of a line in a diff.
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Delete Op = '-'
+	Insert Op = '+'
+)
+
+// Line is a line of a diff, at index A of the old lines and index B of the new lines.
+type Line struct {
+	Op   Op
+	Text string
+	A, B int
+}
+
+// Split splits s into lines, without a final empty line for a trailing newline.
+func Split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Compute returns a shortest diff from a to b, with deletions before insertions in each change.
+func Compute(a, b []string) []Line {
+	// The common prefix and suffix are unchanged without searching them.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for i := range prefix {
+		lines = append(lines, Line{Equal, a[i], i, i})
+	}
+	for _, l := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		l.A += prefix
+		l.B += prefix
+		lines = append(lines, l)
+	}
+	for i, j := len(a)-suffix, len(b)-suffix; i < len(a); i, j = i+1, j+1 {
+		lines = append(lines, Line{Equal, a[i], i, j})
+	}
+	return lines
+}
+
+// myers returns a shortest diff from a to b using Myers' O(ND) algorithm.
+// It keeps the furthest reaching paths of each round, which is quadratic in the number of changes only.
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+	// v[offset+k] is the furthest x reached on diagonal k = x - y.
+	offset := n + m
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	panic("linediff: no path found")
+}
+
+// backtrack walks the furthest reaching paths in trace back from the end of a and b.
+// trace[d] holds the paths of round d-1 for the diagonals -d to d.
+func backtrack(a, b []string, trace [][]int) []Line {
+	var lines []Line
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			lines = append(lines, Line{Equal, a[x], x, y})
+		}
+		if x == prevX {
+			y--
+			lines = append(lines, Line{Insert, b[y], x, y})
+		} else {
+			x--
+			lines = append(lines, Line{Delete, a[x], x, y})
+		}
+	}
+	for x > 0 {
+		x--
+		y--
+		lines = append(lines, Line{Equal, a[x], x, y})
+	}
+	slices.Reverse(lines)
+	return lines
+}
+
+// Hunks formats lines as the hunks of a unified diff, with Context unchanged lines around each change.
+func Hunks(lines []Line) string {
+	var sb strings.Builder
+	for k := 0; k < len(lines); {
+		if lines[k].Op == Equal {
+			k++
+			continue
+		}
+		// A hunk runs until the unchanged lines between two changes are too many to show.
+		last := k
+		for c := k + 1; c < len(lines) && c-last <= 2*Context; c++ {
+			if lines[c].Op != Equal {
+				last = c
+			}
+		}
+		start, end := max(0, k-Context), min(len(lines), last+Context+1)
+		lengthA, lengthB := 0, 0
+		for _, l := range lines[start:end] {
+			if l.Op != Insert {
+				lengthA++
+			}
+			if l.Op != Delete {
+				lengthB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(lines[start].A, lengthA), hunkRange(lines[start].B, lengthB))
+		for _, l := range lines[start:end] {
+			fmt.Fprintf(&sb, "%c%s\n", l.Op, l.Text)
+		}
+		k = end
+	}
+	return sb.String()
+}
+
+// Unified returns a unified diff from before to after of the file at path, or "" if they are equal.
+func Unified(path, before, after string) string {
+	hunks := Hunks(Compute(Split(before), Split(after)))
+	if hunks == "" {
+		return ""
+	}
+	return fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path) + hunks
+}
+
+// hunkRange formats the range of a hunk; an empty range starts at the line before it.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linediff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "equal", a: "a b c", b: "a b c", want: " a  b  c"},
+		{name: "changed line", a: "a b c", b: "a x c", want: " a -b +x  c"},
+		{name: "inserted", a: "a c", b: "a b c", want: " a +b  c"},
+		{name: "deleted", a: "a b c", b: "a c", want: " a -b  c"},
+		{name: "from empty", a: "", b: "a b", want: "+a +b"},
+		{name: "to empty", a: "a b", b: "", want: "-a -b"},
+		{name: "moved", a: "a b c d", b: "b c d a", want: "-a  b  c  d +a"},
+		{name: "several changes", a: "a b c d e f", b: "x b c y e f z", want: "-a +x  b  c -d +y  e  f +z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+			lines := Compute(a, b)
+			var got []string
+			i, j := 0, 0
+			for _, l := range lines {
+				if l.A != i || l.B != j {
+					t.Fatalf("Compute() line %+v is at %d,%d, want %d,%d", l, l.A, l.B, i, j)
+				}
+				if l.Op != Insert {
+					i++
+				}
+				if l.Op != Delete {
+					j++
+				}
+				got = append(got, string(l.Op)+l.Text)
+			}
+			if s := strings.Join(got, " "); s != tt.want {
+				t.Errorf("Compute() = %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nl\nm\n"
+	got := Hunks(Compute(Split(before), Split(after)))
+	want := `@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,5 +8,5 @@
+ h
+ i
+ j
+-k
+ l
++m
+`
+	if got != want {
+		t.Errorf("Hunks() = %q, want %q", got, want)
+	}
+	if got := Hunks(Compute(Split(before), Split(before))); got != "" {
+		t.Errorf("Hunks() of equal texts = %q, want none", got)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	got := Unified("main.go", "package main\n", "// Copyright\n\npackage main\n")
+	want := "--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,3 @@\n+// Copyright\n+\n package main\n"
+	if got != want {
+		t.Errorf("Unified() = %q, want %q", got, want)
+	}
+	if got := Unified("main.go", "package main\n", "package main\n"); got != "" {
+		t.Errorf("Unified() of equal texts = %q, want none", got)
+	}
+}
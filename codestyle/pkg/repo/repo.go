@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package repo discovers the root of the git repository that a tool is run in.
+package repo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FindRoot returns the root of the git work tree containing startDir.
+//
+// In order, it uses:
+//   - the REPO_ROOT environment variable, if set;
+//   - the GIT_WORK_TREE environment variable, if set (e.g. CI checkouts with a separate GIT_DIR);
+//   - the closest ancestor of startDir with a .git directory, or a .git file pointing at the git
+//     directory (as used by worktrees and submodules);
+//   - git rev-parse --show-toplevel, for any other layout git understands.
+func FindRoot(startDir string) (string, error) {
+	if root := os.Getenv("REPO_ROOT"); root != "" {
+		return filepath.Abs(root)
+	}
+	if root := os.Getenv("GIT_WORK_TREE"); root != "" {
+		return filepath.Abs(root)
+	}
+
+	startDir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", err
+	}
+	for dir := startDir; ; dir = filepath.Dir(dir) {
+		if isWorkTreeRoot(dir) {
+			return dir, nil
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if root, err := gitTopLevel(startDir); err == nil {
+		return root, nil
+	}
+	return "", fmt.Errorf("could not find git repository root (starting at %s)", startDir)
+}
+
+// isWorkTreeRoot returns true if dir has a .git directory, or a .git file with a gitdir: line.
+func isWorkTreeRoot(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".git"))
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return true
+	}
+	_, err = readGitFile(dir)
+	return err == nil
+}
+
+// readGitFile returns the git directory referenced by the .git file in dir.
+func readGitFile(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".git"))
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s/.git is not a gitdir file", dir)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// GitDir returns the git directory for the work tree at root: root/.git for a normal checkout,
+// the directory referenced by the .git file for worktrees and submodules, or GIT_DIR if it is set.
+func GitDir(root string) (string, error) {
+	if gitDir := os.Getenv("GIT_DIR"); gitDir != "" {
+		return filepath.Abs(gitDir)
+	}
+	dotGit := filepath.Join(root, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+	return readGitFile(root)
+}
+
+// IsNestedRepo returns true if dir is the root of a git work tree nested inside another
+// (e.g. a submodule), whose files belong to that other repository.
+func IsNestedRepo(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+func gitTopLevel(dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	root := string(bytes.TrimSpace(out))
+	if root == "" {
+		return "", fmt.Errorf("git rev-parse returned no work tree (bare repository?)")
+	}
+	return root, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func clearGitEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"REPO_ROOT", "GIT_WORK_TREE", "GIT_DIR"} {
+		t.Setenv(name, "")
+	}
+}
+
+func mkdirs(t *testing.T, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	clearGitEnv(t)
+	tmp := t.TempDir()
+
+	// A normal checkout, with a submodule and a linked worktree.
+	main := filepath.Join(tmp, "main")
+	sub := filepath.Join(main, "third_party", "lib")
+	worktree := filepath.Join(tmp, "feature")
+	mkdirs(t,
+		filepath.Join(main, ".git", "modules", "lib"),
+		filepath.Join(main, ".git", "worktrees", "feature"),
+		filepath.Join(main, "pkg", "a"),
+		filepath.Join(sub, "pkg"),
+		filepath.Join(worktree, "pkg"),
+	)
+	if err := os.WriteFile(filepath.Join(sub, ".git"), []byte("gitdir: ../../.git/modules/lib\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+filepath.Join(main, ".git", "worktrees", "feature")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		startDir   string
+		wantRoot   string
+		wantGitDir string
+	}{
+		{"checkout", filepath.Join(main, "pkg", "a"), main, filepath.Join(main, ".git")},
+		{"submodule", filepath.Join(sub, "pkg"), sub, filepath.Join(main, ".git", "modules", "lib")},
+		{"worktree", filepath.Join(worktree, "pkg"), worktree, filepath.Join(main, ".git", "worktrees", "feature")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := FindRoot(tt.startDir)
+			if err != nil {
+				t.Fatalf("FindRoot failed: %v", err)
+			}
+			if root != tt.wantRoot {
+				t.Errorf("FindRoot() = %q, want %q", root, tt.wantRoot)
+			}
+			gitDir, err := GitDir(root)
+			if err != nil {
+				t.Fatalf("GitDir failed: %v", err)
+			}
+			if gitDir != tt.wantGitDir {
+				t.Errorf("GitDir() = %q, want %q", gitDir, tt.wantGitDir)
+			}
+		})
+	}
+
+	if !IsNestedRepo(sub) || IsNestedRepo(filepath.Join(main, "pkg")) {
+		t.Errorf("IsNestedRepo did not detect the submodule")
+	}
+}
+
+func TestFindRootIgnoresOtherGitFiles(t *testing.T) {
+	clearGitEnv(t)
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, "repo")
+	dir := filepath.Join(root, "testdata")
+	mkdirs(t, filepath.Join(root, ".git"), dir)
+	// A .git file that is not a gitdir reference does not make a work tree.
+	if err := os.WriteFile(filepath.Join(dir, ".git"), []byte("not a gitdir file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindRoot(dir)
+	if err != nil {
+		t.Fatalf("FindRoot failed: %v", err)
+	}
+	if got != root {
+		t.Errorf("FindRoot() = %q, want %q", got, root)
+	}
+}
+
+func TestFindRootEnvironment(t *testing.T) {
+	clearGitEnv(t)
+	tmp := t.TempDir()
+
+	t.Setenv("GIT_WORK_TREE", filepath.Join(tmp, "checkout"))
+	got, err := FindRoot(tmp)
+	if err != nil {
+		t.Fatalf("FindRoot failed: %v", err)
+	}
+	if got != filepath.Join(tmp, "checkout") {
+		t.Errorf("expected GIT_WORK_TREE to be used, got %q", got)
+	}
+
+	// REPO_ROOT takes precedence.
+	t.Setenv("REPO_ROOT", filepath.Join(tmp, "override"))
+	got, err = FindRoot(tmp)
+	if err != nil {
+		t.Fatalf("FindRoot failed: %v", err)
+	}
+	if got != filepath.Join(tmp, "override") {
+		t.Errorf("expected REPO_ROOT to be used, got %q", got)
+	}
+
+	t.Setenv("GIT_DIR", filepath.Join(tmp, "repo.git"))
+	gitDir, err := GitDir(got)
+	if err != nil {
+		t.Fatalf("GitDir failed: %v", err)
+	}
+	if gitDir != filepath.Join(tmp, "repo.git") {
+		t.Errorf("expected GIT_DIR to be used, got %q", gitDir)
+	}
+}
+
+func TestFindRootOutsideRepo(t *testing.T) {
+	clearGitEnv(t)
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+	if _, err := FindRoot(t.TempDir()); err == nil {
+		t.Errorf("expected an error outside of a git repository")
+	}
+}
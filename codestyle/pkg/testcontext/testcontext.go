@@ -15,7 +15,11 @@
 package testcontext
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
@@ -27,23 +31,57 @@ var Analyzer = &analysis.Analyzer{
 	Run:  run,
 }
 
+// minGoVersion is the first Go version with testing.T.Context; files built with older versions get no fixes.
+const minGoVersion = "go1.24"
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	for _, f := range pass.Files {
 		isTestFile := strings.HasSuffix(pass.Fset.File(f.Pos()).Name(), "_test.go")
 
 		v := &visitor{
 			pass:       pass,
+			file:       f,
 			isTestFile: isTestFile,
+			canFix:     canFix(pass, f),
+			cleanups:   make(map[*ast.FuncLit]bool),
 		}
 		ast.Walk(v, f)
+		v.report()
 	}
 	return nil, nil
 }
 
+// canFix returns true if f is built with a Go version that has testing.T.Context.
+func canFix(pass *analysis.Pass, f *ast.File) bool {
+	v := pass.TypesInfo.FileVersions[f]
+	return v == "" || version.Compare(v, minGoVersion) >= 0
+}
+
 type visitor struct {
 	pass            *analysis.Pass
+	file            *ast.File
 	isTestFile      bool
 	currentFuncHasT bool
+
+	// canFix is set if the file's Go version has testing.T.Context.
+	canFix bool
+
+	// t is the testing.T (or B, F, TB) parameter whose Context replaces context.Background() in the current function,
+	// or nil if there is none (e.g. in a function registered with t.Cleanup, which runs after the context is canceled).
+	t *types.Var
+
+	// cleanups are the function literals passed to Cleanup.
+	cleanups map[*ast.FuncLit]bool
+
+	// found are the calls to context.Background() and context.TODO() to report.
+	found []found
+}
+
+// found is a call to context.Background() or context.TODO() in a test.
+type found struct {
+	call *ast.CallExpr
+	name string
+	t    *types.Var
 }
 
 func (v *visitor) Visit(node ast.Node) ast.Visitor {
@@ -53,23 +91,31 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 
 	switch n := node.(type) {
 	case *ast.FuncDecl:
-		oldHasT := v.currentFuncHasT
+		oldHasT, oldT := v.currentFuncHasT, v.t
 		v.currentFuncHasT = hasTestingT(v.pass, n.Type.Params)
+		v.t = testingParam(v.pass, n.Type.Params)
 		if n.Body != nil {
 			ast.Walk(v, n.Body)
 		}
-		v.currentFuncHasT = oldHasT
+		v.currentFuncHasT, v.t = oldHasT, oldT
 		return nil
 	case *ast.FuncLit:
-		oldHasT := v.currentFuncHasT
+		oldHasT, oldT := v.currentFuncHasT, v.t
 		v.currentFuncHasT = hasTestingT(v.pass, n.Type.Params)
+		if t := testingParam(v.pass, n.Type.Params); t != nil {
+			v.t = t
+		}
+		if v.cleanups[n] {
+			v.t = nil
+		}
 		if n.Body != nil {
 			ast.Walk(v, n.Body)
 		}
-		v.currentFuncHasT = oldHasT
+		v.currentFuncHasT, v.t = oldHasT, oldT
 		return nil
 	case *ast.CallExpr:
 		v.checkCall(n)
+		v.checkCleanup(n)
 	}
 
 	return v
@@ -85,13 +131,132 @@ func (v *visitor) checkCall(call *ast.CallExpr) {
 		if pkg := obj.Pkg(); pkg != nil && pkg.Path() == "context" {
 			if obj.Name() == "Background" || obj.Name() == "TODO" {
 				if v.isTestFile || v.currentFuncHasT {
-					v.pass.Reportf(call.Pos(), "consider using t.Context() instead of context.%s()", obj.Name())
+					v.found = append(v.found, found{call: call, name: obj.Name(), t: v.t})
 				}
 			}
 		}
 	}
 }
 
+// checkCleanup records the function literals passed to the Cleanup method of a testing type.
+func (v *visitor) checkCleanup(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Cleanup" || !isTestingT(v.pass, sel.X) {
+		return
+	}
+	for _, arg := range call.Args {
+		if lit, ok := arg.(*ast.FuncLit); ok {
+			v.cleanups[lit] = true
+		}
+	}
+}
+
+// report reports the calls found in the file, with a fix replacing each with t.Context() where possible.
+// If every use of the context package in the file is replaced, the fixes also remove its import.
+func (v *visitor) report() {
+	fixable := 0
+	for _, f := range v.found {
+		if v.fixable(f) {
+			fixable++
+		}
+	}
+	var removeImport []analysis.TextEdit
+	if fixable > 0 && fixable == v.contextUses() {
+		removeImport = v.removeContextImport()
+	}
+
+	for _, f := range v.found {
+		diag := analysis.Diagnostic{
+			Pos:     f.call.Pos(),
+			Message: fmt.Sprintf("consider using t.Context() instead of context.%s()", f.name),
+		}
+		if v.fixable(f) {
+			edits := []analysis.TextEdit{{Pos: f.call.Pos(), End: f.call.End(), NewText: []byte(f.t.Name() + ".Context()")}}
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   fmt.Sprintf("Replace context.%s() with %s.Context()", f.name, f.t.Name()),
+				TextEdits: append(edits, removeImport...),
+			}}
+		}
+		v.pass.Report(diag)
+	}
+}
+
+// fixable returns true if the call can be replaced with t.Context(): there is a testing parameter,
+// and its name refers to it at the call.
+func (v *visitor) fixable(f found) bool {
+	if !v.canFix || f.t == nil || f.t.Name() == "_" || f.t.Name() == "" {
+		return false
+	}
+	scope := v.pass.Pkg.Scope().Innermost(f.call.Pos())
+	if scope == nil {
+		return false
+	}
+	_, obj := scope.LookupParent(f.t.Name(), f.call.Pos())
+	return obj == f.t
+}
+
+// contextUses returns the number of references to the context package in the file.
+func (v *visitor) contextUses() int {
+	n := 0
+	ast.Inspect(v.file, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok {
+			if pkgName, ok := v.pass.TypesInfo.Uses[id].(*types.PkgName); ok && pkgName.Imported().Path() == "context" {
+				n++
+			}
+		}
+		return true
+	})
+	return n
+}
+
+// removeContextImport returns the edits removing the import of the context package from the file.
+func (v *visitor) removeContextImport() []analysis.TextEdit {
+	tf := v.pass.Fset.File(v.file.Pos())
+	for _, decl := range v.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if imp.Path.Value != `"context"` || (imp.Name != nil && imp.Name.Name == "_") {
+				continue
+			}
+			if len(gen.Specs) == 1 {
+				return []analysis.TextEdit{lineEdit(tf, gen.Pos(), gen.End())}
+			}
+			return []analysis.TextEdit{lineEdit(tf, imp.Pos(), imp.End())}
+		}
+	}
+	return nil
+}
+
+// lineEdit returns an edit deleting the lines from pos to end, including the newline that ends them.
+func lineEdit(tf *token.File, pos, end token.Pos) analysis.TextEdit {
+	start := tf.LineStart(tf.Line(pos))
+	next := token.Pos(tf.Base() + tf.Size())
+	if line := tf.Line(end); line < tf.LineCount() {
+		next = tf.LineStart(line + 1)
+	}
+	return analysis.TextEdit{Pos: start, End: next}
+}
+
+// testingParam returns the first parameter of a testing type, or nil if there is none.
+func testingParam(pass *analysis.Pass, params *ast.FieldList) *types.Var {
+	if params == nil {
+		return nil
+	}
+	for _, field := range params.List {
+		if !isTestingT(pass, field.Type) || len(field.Names) == 0 {
+			continue
+		}
+		if obj, ok := pass.TypesInfo.Defs[field.Names[0]].(*types.Var); ok {
+			return obj
+		}
+	}
+	return nil
+}
+
 func hasTestingT(pass *analysis.Pass, params *ast.FieldList) bool {
 	if params == nil {
 		return false
@@ -0,0 +1,39 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	_ = context.TODO() // want "consider using t.Context().*"
+	t.Cleanup(func() {
+		// The context of t is canceled before cleanups run.
+		_ = context.Background() // want "consider using t.Context().*"
+	})
+}
+
+func TestShadowed(t *testing.T) {
+	for _, t := range []string{"a"} {
+		_ = t
+		_ = context.TODO() // want "consider using t.Context().*"
+	}
+}
+
+func helper(ctx context.Context, _ testing.TB) {
+	_ = context.Background() // want "consider using t.Context().*"
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+	"k8s.io/klog/v2"
+)
+
+// The rules of the encoding checks. BOMs and CRLF line endings are fixed by ap format, so they are warnings;
+// the others are errors, as they cannot be fixed mechanically and may hide what the code does.
+const (
+	BOMRule       = "bom"
+	CRLFRule      = "crlf"
+	BidiRule      = "bidi"
+	InvisibleRule = "invisiblechar"
+	UTF8Rule      = "utf8"
+)
+
+// DefaultCRLF are the extensions of the files whose lines may end in CRLF, if not configured.
+var DefaultCRLF = []string{".bat", ".cmd"}
+
+// sniffLen is how much of a file is read to tell whether it is binary, as in largefiles.
+const sniffLen = 8000
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// bidiChars are the bidirectional control characters, which reorder how text is displayed: code using them can
+// read differently from how it compiles ("trojan source", CVE-2021-42574).
+var bidiChars = map[rune]string{
+	'\u061C': "ARABIC LETTER MARK",
+	'\u200E': "LEFT-TO-RIGHT MARK",
+	'\u200F': "RIGHT-TO-LEFT MARK",
+	'\u202A': "LEFT-TO-RIGHT EMBEDDING",
+	'\u202B': "RIGHT-TO-LEFT EMBEDDING",
+	'\u202C': "POP DIRECTIONAL FORMATTING",
+	'\u202D': "LEFT-TO-RIGHT OVERRIDE",
+	'\u202E': "RIGHT-TO-LEFT OVERRIDE",
+	'\u2066': "LEFT-TO-RIGHT ISOLATE",
+	'\u2067': "RIGHT-TO-LEFT ISOLATE",
+	'\u2068': "FIRST STRONG ISOLATE",
+	'\u2069': "POP DIRECTIONAL ISOLATE",
+}
+
+// invisibleChars are characters that are not displayed, so that identifiers or strings that look the same differ.
+// The zero-width joiners are left out, as emoji and some scripts need them.
+var invisibleChars = map[rune]string{
+	'\u00AD': "SOFT HYPHEN",
+	'\u180E': "MONGOLIAN VOWEL SEPARATOR",
+	'\u200B': "ZERO WIDTH SPACE",
+	'\u2060': "WORD JOINER",
+	'\u2061': "FUNCTION APPLICATION",
+	'\u2062': "INVISIBLE TIMES",
+	'\u2063': "INVISIBLE SEPARATOR",
+	'\u2064': "INVISIBLE PLUS",
+	'\uFEFF': "ZERO WIDTH NO-BREAK SPACE",
+}
+
+// EncodingConfig configures the encoding hygiene of text files, which is on by default: ap format removes byte
+// order marks and converts CRLF line endings to LF, and ap lint reports them, as well as bidirectional control
+// characters, invisible characters and content that is not UTF-8.
+type EncodingConfig struct {
+	// Enabled is false to turn off the checks and fixes.
+	Enabled *bool `json:"enabled"`
+	// CRLF lists the extensions (e.g. ".bat") of the files whose lines may end in CRLF; defaults to DefaultCRLF.
+	CRLF []string `json:"crlf"`
+	// Skip lists gitignore-style patterns of the files that are not checked or fixed.
+	Skip []string `json:"skip"`
+}
+
+// encoding returns the encoding config, or nil if the checks are turned off.
+func (c *Config) encoding() *EncodingConfig {
+	if c.Encoding == nil {
+		return &EncodingConfig{CRLF: DefaultCRLF}
+	}
+	if c.Encoding.Enabled != nil && !*c.Encoding.Enabled {
+		return nil
+	}
+	e := *c.Encoding
+	if e.CRLF == nil {
+		e.CRLF = DefaultCRLF
+	}
+	return &e
+}
+
+// allowsCRLF returns true if the lines of the file at path may end in CRLF.
+func (e *EncodingConfig) allowsCRLF(path string) bool {
+	return slices.Contains(e.CRLF, filepath.Ext(path))
+}
+
+// isBinary returns true if the start of content contains a NUL byte, as git decides.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), sniffLen)], 0) >= 0
+}
+
+// FixEncoding removes the byte order mark of content and, unless keepCRLF is set, converts its CRLF line endings
+// to LF. Binary content and content that is not UTF-8 are not fixed, as their encoding is not known.
+func FixEncoding(content []byte, keepCRLF bool) ([]byte, error) {
+	if isBinary(content) {
+		return nil, errors.New("binary content")
+	}
+	if !utf8.Valid(content) {
+		return nil, errors.New("content is not UTF-8")
+	}
+	out := bytes.TrimPrefix(content, bom)
+	if !keepCRLF {
+		out = bytes.ReplaceAll(out, []byte("\r\n"), []byte("\n"))
+	}
+	return out, nil
+}
+
+// CheckEncoding reports the encoding problems of the text files under root: byte order marks, CRLF line endings,
+// bidirectional control characters, invisible characters and content that is not UTF-8. Files in other ap roots
+// under root are left to them. Files whose content has already been checked are replayed from the codestyle cache.
+func CheckEncoding(ctx context.Context, root string) ([]findings.Finding, error) {
+	log := klog.FromContext(ctx)
+
+	cfg, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	enc := cfg.encoding()
+	if enc == nil {
+		return nil, nil
+	}
+
+	cm, err := cache.NewManager(root)
+	if err != nil {
+		log.V(2).Info("Failed to initialize cache", "error", err)
+	} else {
+		defer func() {
+			if err := cm.Save(); err != nil {
+				log.Error(err, "Failed to save cache")
+			}
+		}()
+	}
+
+	skip := append(append(slices.Clone(DefaultSkip), cfg.Skip...), enc.Skip...)
+	nested := map[string]bool{}
+	var all []findings.Finding
+	err = walker.NewFileView(root, skip).Walk(func(file walker.File) error {
+		if inNestedRoot(root, filepath.Dir(file.Path), nested) {
+			return nil
+		}
+		tool := "encodingcheck"
+		if enc.allowsCRLF(file.Path) {
+			tool += "-keepcrlf"
+		}
+		var hash string
+		if cm != nil {
+			if meta, err := cm.GetOrUpdateMetadata(file.Path); err == nil {
+				hash = meta.Hash
+				if result, done := cm.Done(tool, hash); done {
+					all = append(all, withPath(result.Findings, file.Path)...)
+					return nil
+				}
+			}
+		}
+
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return err
+		}
+		var found []findings.Finding
+		if !isBinary(content) {
+			found = checkEncoding(content, enc.allowsCRLF(file.Path))
+		}
+		if cm != nil && hash != "" {
+			cm.MarkDone(tool, hash, &cache.Result{Findings: found})
+		}
+		all = append(all, withPath(found, file.Path)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking %s: %w", root, err)
+	}
+	return all, nil
+}
+
+// inNestedRoot returns true if dir is in an ap root nested under root; the results for directories are memoized in nested.
+func inNestedRoot(root string, dir string, nested map[string]bool) bool {
+	if dir == root || !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		return false
+	}
+	if result, ok := nested[dir]; ok {
+		return result
+	}
+	_, err := os.Stat(filepath.Join(dir, ".ap"))
+	result := err == nil || inNestedRoot(root, filepath.Dir(dir), nested)
+	nested[dir] = result
+	return result
+}
+
+// withPath returns copies of found with their path set to path; findings are cached by content, without a path.
+func withPath(found []findings.Finding, path string) []findings.Finding {
+	var out []findings.Finding
+	for _, f := range found {
+		f.Path = path
+		out = append(out, f)
+	}
+	return out
+}
+
+// checkEncoding returns the encoding problems of content, without their path.
+func checkEncoding(content []byte, allowCRLF bool) []findings.Finding {
+	var found []findings.Finding
+	if bytes.HasPrefix(content, bom) {
+		found = append(found, findings.Finding{
+			Line:     1,
+			Rule:     BOMRule,
+			Message:  "file starts with a byte order mark; run ap format to remove it",
+			Severity: findings.SeverityWarning,
+		})
+		content = content[len(bom):]
+	}
+
+	crlf := false
+	for i, line := range bytes.SplitAfter(content, []byte("\n")) {
+		lineNumber := i + 1
+		if !allowCRLF && !crlf && bytes.HasSuffix(line, []byte("\r\n")) {
+			crlf = true
+			found = append(found, findings.Finding{
+				Line:     lineNumber,
+				Rule:     CRLFRule,
+				Message:  "file has CRLF line endings; run ap format to convert them to LF",
+				Severity: findings.SeverityWarning,
+			})
+		}
+		if !utf8.Valid(line) {
+			found = append(found, findings.Finding{
+				Line:     lineNumber,
+				Rule:     UTF8Rule,
+				Message:  "line is not valid UTF-8; convert the file to UTF-8",
+				Severity: findings.SeverityError,
+			})
+		}
+		column := 1
+		for len(line) > 0 {
+			r, size := utf8.DecodeRune(line)
+			if name, ok := bidiChars[r]; ok {
+				found = append(found, findings.Finding{
+					Line:     lineNumber,
+					Column:   column,
+					Rule:     BidiRule,
+					Message:  fmt.Sprintf("bidirectional control character U+%04X (%s) can make the text read differently from what it does; remove it or write it as an escape", r, name),
+					Severity: findings.SeverityError,
+				})
+			} else if name, ok := invisibleChars[r]; ok {
+				found = append(found, findings.Finding{
+					Line:     lineNumber,
+					Column:   column,
+					Rule:     InvisibleRule,
+					Message:  fmt.Sprintf("invisible character U+%04X (%s); remove it or write it as an escape", r, name),
+					Severity: findings.SeverityError,
+				})
+			}
+			line = line[size:]
+			column += size
+		}
+	}
+	return found
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+)
+
+func TestFixEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		keepCRLF bool
+		want     string
+		wantErr  bool
+	}{
+		{name: "bom", content: "\uFEFFkey: value\n", want: "key: value\n"},
+		{name: "crlf", content: "a\r\nb\r\n", want: "a\nb\n"},
+		{name: "keep crlf", content: "\uFEFFa\r\nb\r\n", keepCRLF: true, want: "a\r\nb\r\n"},
+		{name: "lone cr", content: "a\rb\n", want: "a\rb\n"},
+		{name: "bidi is left alone", content: "x := \"\u202E\"\n", want: "x := \"\u202E\"\n"},
+		{name: "not utf-8", content: "caf\xe9\r\n", wantErr: true},
+		{name: "binary", content: "\x00\x01\r\n", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FixEncoding([]byte(tc.content), tc.keepCRLF)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("FixEncoding(%q) = %q, want an error", tc.content, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("FixEncoding(%q) = %q, want %q", tc.content, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckEncoding(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"bom.yaml":            "\uFEFFkey: value\n",
+		"crlf.txt":            "a\r\nb\r\n",
+		"run.bat":             "echo a\r\n",
+		"trojan.go":           "package main\n\n// ok \u202E } \u2066 if x {\n",
+		"invisible.md":        "a\u200Bb\n",
+		"latin1.txt":          "ok\ncaf\xe9\n",
+		"image.png":           "\x89PNG\x00\u202E",
+		"emoji.md":            "\U0001F468\u200D\U0001F4BB\n",
+		"testdata/crlf.txt":   "a\r\n",
+		"nested/.ap/go.yaml":  "",
+		"nested/crlf.txt":     "a\r\n",
+		"skipped/crlf.txt":    "a\r\n",
+		".ap/format.yaml":     "encoding:\n  skip:\n  - skipped/\n",
+		"clean/file.txt":      "plain text\n",
+		"clean/windows.cmd":   "echo a\r\n",
+		"clean/.gitkeep":      "",
+		"clean/unicode.md":    "caf\u00E9 \u2014 na\u00EFve\n",
+		"clean/zwnj.txt":      "\u0645\u06CC\u200C\u062E\u0648\u0627\u0647\u0645\n",
+		"clean/tab-and-cr.sh": "echo a\r",
+	})
+
+	type key struct {
+		path   string
+		line   int
+		column int
+		rule   string
+		sev    findings.Severity
+	}
+	want := []key{
+		{"bom.yaml", 1, 0, BOMRule, findings.SeverityWarning},
+		{"crlf.txt", 1, 0, CRLFRule, findings.SeverityWarning},
+		{"invisible.md", 1, 2, InvisibleRule, findings.SeverityError},
+		{"latin1.txt", 2, 0, UTF8Rule, findings.SeverityError},
+		{"trojan.go", 3, 7, BidiRule, findings.SeverityError},
+		{"trojan.go", 3, 13, BidiRule, findings.SeverityError},
+	}
+
+	// The second run replays the findings from the cache.
+	for _, run := range []string{"first", "cached"} {
+		found, err := CheckEncoding(t.Context(), root)
+		if err != nil {
+			t.Fatalf("CheckEncoding failed: %v", err)
+		}
+		var got []key
+		for _, f := range found {
+			rel, err := filepath.Rel(root, f.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, key{filepath.ToSlash(rel), f.Line, f.Column, f.Rule, f.Severity})
+		}
+		slices.SortFunc(got, func(a, b key) int {
+			if a.path != b.path {
+				if a.path < b.path {
+					return -1
+				}
+				return 1
+			}
+			return a.column - b.column
+		})
+		if !slices.Equal(got, want) {
+			t.Errorf("%s run: CheckEncoding() =\n%v\nwant:\n%v", run, got, want)
+		}
+	}
+}
+
+func TestCheckEncodingDisabled(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/format.yaml": "encoding:\n  enabled: false\n",
+		"crlf.txt":        "a\r\n",
+	})
+
+	found, err := CheckEncoding(t.Context(), root)
+	if err != nil {
+		t.Fatalf("CheckEncoding failed: %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("CheckEncoding() = %v, want no findings when encoding is disabled", found)
+	}
+}
+
+func TestRunFixesEncoding(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/format.yaml": "yaml:\n  enabled: true\nencoding:\n  crlf: [.ps1]\n",
+		"app.yaml":        "\uFEFFmetadata:\r\n    name: app\r\n",
+		"notes.txt":       "a\r\nb\u202E\r\n",
+		"run.ps1":         "Write-Host a\r\n",
+		"run.bat":         "echo a\r\n",
+	})
+
+	if err := Run(t.Context(), root, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := map[string]string{
+		"app.yaml":  "metadata:\n  name: app\n",
+		"notes.txt": "a\nb\u202E\n",
+		"run.ps1":   "Write-Host a\r\n",
+		"run.bat":   "echo a\n",
+	}
+	for relPath, content := range want {
+		if got := readFile(t, filepath.Join(root, relPath)); got != content {
+			t.Errorf("%s = %q, want %q", relPath, got, content)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// headingRE matches an ATX heading, capturing its level, text and any closing sequence.
+	headingRE = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	// fenceRE matches the opening or closing line of a fenced code block.
+	fenceRE = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	// delimiterRE matches the delimiter row of a table, which separates the header from the body.
+	delimiterRE = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// FormatMarkdown aligns the columns of the tables in content, and writes its ATX headings
+// as "#", a space and the title, without a closing sequence and surrounded by blank lines.
+// Code blocks and front matter are left as they are.
+func FormatMarkdown(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+	var out []string
+
+	// headingEnded is set after a heading, so that a blank line is added before any text that follows it.
+	headingEnded := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if i == 0 && line == "---" {
+			end := i + 1
+			for end < len(lines) && lines[end] != "---" {
+				end++
+			}
+			out = append(out, lines[i:min(end+1, len(lines))]...)
+			i = end
+			continue
+		}
+
+		if headingEnded && strings.TrimSpace(line) != "" {
+			out = append(out, "")
+		}
+		headingEnded = false
+
+		if m := fenceRE.FindStringSubmatch(line); m != nil {
+			end := i + 1
+			for end < len(lines) && !closesFence(lines[end], m[1]) {
+				end++
+			}
+			out = append(out, lines[i:min(end+1, len(lines))]...)
+			i = end
+			continue
+		}
+
+		if m := headingRE.FindStringSubmatch(line); m != nil {
+			if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+				out = append(out, "")
+			}
+			heading := m[1]
+			if m[2] != "" {
+				heading += " " + m[2]
+			}
+			out = append(out, heading)
+			headingEnded = true
+			continue
+		}
+
+		if i+1 < len(lines) && strings.Contains(line, "|") && delimiterRE.MatchString(lines[i+1]) && strings.Contains(lines[i]+lines[i+1], "|") {
+			end := i + 2
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && strings.Contains(lines[end], "|") {
+				end++
+			}
+			out = append(out, formatTable(lines[i:end])...)
+			i = end - 1
+			continue
+		}
+
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// closesFence reports whether line closes a code block opened with fence.
+func closesFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence[:3]) && strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence)
+}
+
+// formatTable pads the cells of a table so that its columns line up.
+// The table is returned as it is if a row has more cells than the header.
+func formatTable(lines []string) []string {
+	indent := lines[0][:len(lines[0])-len(strings.TrimLeft(lines[0], " \t"))]
+
+	var rows [][]string
+	for _, line := range lines {
+		rows = append(rows, splitRow(line))
+	}
+	columns := len(rows[0])
+	if len(rows[1]) != columns {
+		return lines
+	}
+	for _, row := range rows[2:] {
+		if len(row) > columns {
+			return lines
+		}
+	}
+
+	aligns := make([]string, columns)
+	widths := make([]int, columns)
+	for c, cell := range rows[1] {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns[c] = "center"
+		case strings.HasSuffix(cell, ":"):
+			aligns[c] = "right"
+		case strings.HasPrefix(cell, ":"):
+			aligns[c] = "left"
+		}
+		widths[c] = 3
+	}
+	for r, row := range rows {
+		if r == 1 {
+			continue
+		}
+		for c, cell := range row {
+			widths[c] = max(widths[c], utf8.RuneCountInString(cell))
+		}
+	}
+
+	out := make([]string, len(rows))
+	for r, row := range rows {
+		cells := make([]string, columns)
+		for c := range cells {
+			if r == 1 {
+				cells[c] = delimiterCell(aligns[c], widths[c])
+				continue
+			}
+			var cell string
+			if c < len(row) {
+				cell = row[c]
+			}
+			cells[c] = padCell(cell, aligns[c], widths[c])
+		}
+		out[r] = indent + "| " + strings.Join(cells, " | ") + " |"
+	}
+	return out
+}
+
+// splitRow returns the trimmed cells of a table row.
+// Pipes escaped with a backslash or inside code spans do not separate cells.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case ch == '\\' && i+1 < len(line):
+			cell.WriteByte(ch)
+			i++
+			cell.WriteByte(line[i])
+		case ch == '`':
+			inCode = !inCode
+			cell.WriteByte(ch)
+		case ch == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(ch)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// delimiterCell returns the delimiter row cell of a column.
+func delimiterCell(align string, width int) string {
+	switch align {
+	case "center":
+		return ":" + strings.Repeat("-", width-2) + ":"
+	case "right":
+		return strings.Repeat("-", width-1) + ":"
+	case "left":
+		return ":" + strings.Repeat("-", width-1)
+	}
+	return strings.Repeat("-", width)
+}
+
+// padCell pads cell to width according to the alignment of its column.
+func padCell(cell, align string, width int) string {
+	padding := width - utf8.RuneCountInString(cell)
+	switch align {
+	case "center":
+		return strings.Repeat(" ", padding/2) + cell + strings.Repeat(" ", padding-padding/2)
+	case "right":
+		return strings.Repeat(" ", padding) + cell
+	}
+	return cell + strings.Repeat(" ", padding)
+}
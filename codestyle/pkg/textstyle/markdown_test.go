@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"testing"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "aligns tables",
+			in: `| Name | Default | Description |
+|:--|--:|:-:|
+| ` + "`indent`" + ` | 2 | Spaces per level |
+| skip | | Patterns \| globs |
+`,
+			want: `| Name     | Default |    Description    |
+| :------- | ------: | :---------------: |
+| ` + "`indent`" + ` |       2 | Spaces per level  |
+| skip     |         | Patterns \| globs |
+`,
+		},
+		{
+			name: "pads short rows",
+			in:   "a|b\n-|-\n|1|\n",
+			want: "| a   | b   |\n| --- | --- |\n| 1   |     |\n",
+		},
+		{
+			name: "normalizes headings",
+			in:   "#   Title ##\nText.\n## C#\n### Usage\n",
+			want: "# Title\n\nText.\n\n## C#\n\n### Usage\n",
+		},
+		{
+			name: "leaves code and front matter",
+			in:   "---\ntitle: x\n---\n```sh\n#   not a heading\n|a|b|\n|-|-|\n```\n",
+			want: "---\ntitle: x\n---\n```sh\n#   not a heading\n|a|b|\n|-|-|\n```\n",
+		},
+		{
+			name: "not a table",
+			in:   "a | b\n---\n",
+			want: "a | b\n---\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(FormatMarkdown([]byte(tt.in))); got != tt.want {
+				t.Errorf("FormatMarkdown() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
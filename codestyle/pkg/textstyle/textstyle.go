@@ -0,0 +1,277 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package textstyle formats YAML and Markdown files, as gostyle formats Go files, and fixes and checks the
+// encoding of text files. Each formatter is opt-in in .ap/format.yaml, except for the encoding fixes, and files
+// whose content a formatter has already processed are skipped using the codestyle cache.
+package textstyle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/walker"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFile is the location of the config, relative to the repository root.
+const ConfigFile = ".ap/format.yaml"
+
+// DefaultIndent is the number of spaces YAML is indented by, if not configured.
+const DefaultIndent = 2
+
+// DefaultSkip are the patterns of the files and directories that are never formatted, in addition to the configured ones.
+// Files in testdata are often deliberately formatted as they are, for example as golden output.
+var DefaultSkip = []string{
+	".git/",
+	"vendor/",
+	"third_party/",
+	"node_modules/",
+	"testdata/",
+}
+
+// Config is the contents of .ap/format.yaml.
+type Config struct {
+	YAML     *YAMLConfig     `json:"yaml"`
+	Markdown *MarkdownConfig `json:"markdown"`
+	Encoding *EncodingConfig `json:"encoding"`
+
+	// Skip lists gitignore-style patterns of the files and directories no formatter changes, in addition to DefaultSkip.
+	Skip []string `json:"skip"`
+}
+
+// YAMLConfig configures the formatting of .yaml and .yml files: they are re-indented,
+// keeping comments, blank lines, key order and quoting.
+type YAMLConfig struct {
+	Enabled *bool `json:"enabled"`
+	// Indent is the number of spaces per level of nesting; it defaults to DefaultIndent.
+	Indent int `json:"indent"`
+	// CompactSequences writes the items of a sequence in a mapping at the indentation of its key,
+	// as kubectl does, instead of indenting them.
+	CompactSequences bool `json:"compactSequences"`
+	// Skip lists gitignore-style patterns of the YAML files that are not formatted.
+	Skip []string `json:"skip"`
+}
+
+// MarkdownConfig configures the formatting of .md files: table columns are aligned,
+// and headings are written as "# Title" with a blank line before and after.
+type MarkdownConfig struct {
+	Enabled *bool `json:"enabled"`
+	// Skip lists gitignore-style patterns of the Markdown files that are not formatted.
+	Skip []string `json:"skip"`
+}
+
+// formatter rewrites the content of the files it matches.
+type formatter struct {
+	// name identifies the formatter and its options in the cache.
+	name    string
+	matches func(path string) bool
+	format  func(path string, content []byte) ([]byte, error)
+	skip    []string
+}
+
+// Run formats the files of repoRoot with the formatters enabled in .ap/format.yaml.
+// If files is empty, the whole tree is formatted.
+func Run(ctx context.Context, repoRoot string, files []string) error {
+	log := klog.FromContext(ctx)
+
+	cfg, err := LoadConfig(repoRoot)
+	if err != nil {
+		return err
+	}
+	formatters := cfg.formatters()
+	if len(formatters) == 0 {
+		return nil
+	}
+
+	cm, err := cache.NewManager(repoRoot)
+	if err != nil {
+		log.V(2).Info("Failed to initialize cache", "error", err)
+	} else {
+		defer func() {
+			if err := cm.Save(); err != nil {
+				log.Error(err, "Failed to save cache")
+			}
+		}()
+	}
+
+	var errs []error
+	for _, f := range formatters {
+		if err := f.run(ctx, repoRoot, files, append(slices.Clone(DefaultSkip), cfg.Skip...), cm); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadConfig loads .ap/format.yaml from repoRoot, returning an empty config if it does not exist.
+func LoadConfig(repoRoot string) (*Config, error) {
+	path := filepath.Join(repoRoot, ConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if cfg.YAML != nil && cfg.YAML.Indent < 0 {
+		return nil, fmt.Errorf("error in %s: yaml.indent must be positive", path)
+	}
+	return &cfg, nil
+}
+
+// formatters returns the enabled formatters.
+func (c *Config) formatters() []formatter {
+	var formatters []formatter
+	// Encoding goes first, so that the other formatters do not fail on a byte order mark.
+	if enc := c.encoding(); enc != nil {
+		formatters = append(formatters, formatter{
+			name:    "encoding-crlf" + strings.Join(enc.CRLF, ","),
+			matches: func(string) bool { return true },
+			format: func(path string, content []byte) ([]byte, error) {
+				return FixEncoding(content, enc.allowsCRLF(path))
+			},
+			skip: enc.Skip,
+		})
+	}
+	if c.YAML != nil && c.YAML.Enabled != nil && *c.YAML.Enabled {
+		indent := c.YAML.Indent
+		if indent == 0 {
+			indent = DefaultIndent
+		}
+		compact := c.YAML.CompactSequences
+		name := fmt.Sprintf("yaml-indent%d", indent)
+		if compact {
+			name += "-compact"
+		}
+		formatters = append(formatters, formatter{
+			name: name,
+			matches: func(path string) bool {
+				return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+			},
+			format: func(_ string, content []byte) ([]byte, error) { return FormatYAML(content, indent, compact) },
+			skip:   c.YAML.Skip,
+		})
+	}
+	if c.Markdown != nil && c.Markdown.Enabled != nil && *c.Markdown.Enabled {
+		formatters = append(formatters, formatter{
+			name:    "markdown",
+			matches: func(path string) bool { return strings.HasSuffix(path, ".md") },
+			format:  func(_ string, content []byte) ([]byte, error) { return FormatMarkdown(content), nil },
+			skip:    c.Markdown.Skip,
+		})
+	}
+	return formatters
+}
+
+// run formats the files f matches, skipping those whose content it has already processed.
+func (f *formatter) run(ctx context.Context, repoRoot string, files []string, skip []string, cm *cache.Manager) error {
+	log := klog.FromContext(ctx)
+
+	ignores := walker.NewIgnoreList(append(slices.Clone(skip), f.skip...))
+	var paths []string
+	if len(files) > 0 {
+		for _, file := range files {
+			absPath := file
+			if !filepath.IsAbs(file) {
+				absPath = filepath.Join(repoRoot, file)
+			}
+			relPath, err := filepath.Rel(repoRoot, absPath)
+			if err != nil || !f.matches(absPath) || ignores.ShouldIgnoreFile(relPath) {
+				continue
+			}
+			paths = append(paths, absPath)
+		}
+	} else {
+		fv := walker.NewFileView(repoRoot, append(slices.Clone(skip), f.skip...))
+		err := fv.Walk(func(file walker.File) error {
+			if f.matches(file.Path) {
+				paths = append(paths, file.Path)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error walking for %s files: %w", f.name, err)
+		}
+	}
+
+	var errs []error
+	formatted := 0
+	for _, path := range paths {
+		if cm != nil {
+			if meta, err := cm.GetOrUpdateMetadata(path); err == nil {
+				if _, done := cm.Done(f.name, meta.Hash); done {
+					continue
+				}
+			}
+		}
+
+		changed, err := f.formatFile(ctx, path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error formatting %s: %w", path, err))
+			continue
+		}
+		if changed {
+			formatted++
+		}
+
+		if cm != nil {
+			// Re-check metadata, as the file may have changed.
+			if meta, err := cm.GetOrUpdateMetadata(path); err == nil {
+				cm.MarkDone(f.name, meta.Hash, nil)
+			}
+		}
+	}
+	if formatted > 0 {
+		log.Info("Formatted files", "formatter", f.name, "files", formatted)
+	}
+	return errors.Join(errs...)
+}
+
+// formatFile formats the file at path in place, and reports whether it changed.
+func (f *formatter) formatFile(ctx context.Context, path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	out, err := f.format(path, content)
+	if err != nil {
+		// Files that cannot be parsed, such as templates, are left as they are.
+		klog.FromContext(ctx).V(2).Info("Not formatting file", "file", path, "error", err)
+		return false, nil
+	}
+	if bytes.Equal(out, content) {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
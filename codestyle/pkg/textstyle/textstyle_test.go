@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/cache"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		p := filepath.Join(root, relPath)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/format.yaml":       "yaml:\n  enabled: true\nmarkdown:\n  enabled: true\n  skip:\n  - CHANGELOG.md\n",
+		"k8s/app.yaml":          "metadata:\n    name: app\n",
+		"k8s/testdata/out.yaml": "metadata:\n    name: app\n",
+		"README.md":             "#  Example\n|a|b|\n|-|-|\n",
+		"CHANGELOG.md":          "#  Changes\n",
+	})
+
+	if err := Run(t.Context(), root, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := map[string]string{
+		"k8s/app.yaml":          "metadata:\n  name: app\n",
+		"k8s/testdata/out.yaml": "metadata:\n    name: app\n",
+		"README.md":             "# Example\n\n| a   | b   |\n| --- | --- |\n",
+		"CHANGELOG.md":          "#  Changes\n",
+	}
+	for relPath, content := range want {
+		if got := readFile(t, filepath.Join(root, relPath)); got != content {
+			t.Errorf("%s =\n%s\nwant:\n%s", relPath, got, content)
+		}
+	}
+}
+
+func TestRunNotEnabled(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"app.yaml": "metadata:\n    name: app\n"})
+
+	if err := Run(t.Context(), root, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(root, "app.yaml")); got != "metadata:\n    name: app\n" {
+		t.Errorf("app.yaml was formatted without .ap/format.yaml: %q", got)
+	}
+}
+
+func TestRunSkipsCachedFiles(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".ap/format.yaml": "yaml:\n  enabled: true\n",
+		"app.yaml":        "metadata:\n    name: app\n",
+	})
+
+	// Record the file as processed, as a previous run would have.
+	cm, err := cache.NewManager(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := cm.GetOrUpdateMetadata(filepath.Join(root, "app.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm.MarkDone("yaml-indent2", meta.Hash, nil)
+	if err := cm.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Run(t.Context(), root, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(root, "app.yaml")); got != "metadata:\n    name: app\n" {
+		t.Errorf("app.yaml was formatted although its content is in the cache: %q", got)
+	}
+
+	// A different indent is a different formatter, so the cache entry does not apply.
+	writeFiles(t, root, map[string]string{".ap/format.yaml": "yaml:\n  enabled: true\n  indent: 3\n"})
+	if err := Run(t.Context(), root, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(root, "app.yaml")); got != "metadata:\n   name: app\n" {
+		t.Errorf("app.yaml = %q, want it indented by 3", got)
+	}
+}
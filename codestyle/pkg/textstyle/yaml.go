@@ -0,0 +1,314 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// blankLineMarker stands in for a blank line of the input while the YAML is re-encoded,
+// which otherwise drops blank lines. It is written as a head comment and replaced afterwards.
+const blankLineMarker = "#ap-format:blank-line"
+
+// FormatYAML re-indents the YAML documents in content by indent spaces per level.
+// Sequences in mappings are indented too, unless compact is set, in which case their
+// items start at the indentation of their key, as kubectl writes them.
+// Comments, blank lines between entries, key order, quoting and flow style are kept.
+// It returns an error, and content should be left as it is, if content cannot be parsed
+// or would not mean the same once formatted.
+func FormatYAML(content []byte, indent int, compact bool) ([]byte, error) {
+	docs, err := decodeYAML(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return content, nil
+	}
+
+	for _, doc := range docs {
+		if len(doc.Content) == 0 {
+			// Only comments; there is nothing to indent.
+			return content, nil
+		}
+	}
+	formatted, err := EncodeYAML(docs, content, indent, compact)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sameYAML(content, formatted); err != nil {
+		return nil, err
+	}
+	return formatted, nil
+}
+
+// EncodeYAML encodes docs, parsed from content and possibly modified since, indenting by indent spaces per level
+// and with compact sequences if compact is set (see FormatYAML). The comments of docs, and the blank lines of content
+// between their entries, are kept; blank lines are marked in the head comments of docs while encoding them.
+func EncodeYAML(docs []*yaml.Node, content []byte, indent int, compact bool) ([]byte, error) {
+	lines := strings.Split(string(content), "\n")
+	for _, doc := range docs {
+		if len(doc.Content) > 0 {
+			markBlankLines(doc.Content[0], lines)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	encoded := buf.String()
+	if compact {
+		encoded = compactSequences(encoded, indent)
+	}
+
+	var out strings.Builder
+	if strings.HasPrefix(string(content), "---\n") {
+		out.WriteString("---\n")
+	}
+	previousBlank := false
+	for _, line := range strings.SplitAfter(encoded, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == blankLineMarker {
+			if !previousBlank {
+				out.WriteString("\n")
+			}
+			previousBlank = true
+			continue
+		}
+		out.WriteString(line)
+		previousBlank = line == "\n"
+	}
+	return []byte(out.String()), nil
+}
+
+// DetectYAMLStyle returns the indentation of the YAML in content, and whether its sequences in mappings are compact,
+// as used by FormatYAML. It returns DefaultIndent and compact sequences, as kubectl writes them, if content does not
+// tell.
+func DetectYAMLStyle(content []byte) (indent int, compact bool) {
+	indent, compact = 0, true
+	sequenceSeen := false
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasSuffix(strings.TrimSpace(stripComment(trimmed)), ":") {
+			continue
+		}
+		keyIndent := len(line) - len(trimmed)
+		for strings.HasPrefix(trimmed, "- ") {
+			keyIndent += 2
+			trimmed = trimmed[2:]
+		}
+		next := nextLine(lines[i+1:])
+		nextTrimmed := strings.TrimLeft(next, " ")
+		nextIndent := len(next) - len(nextTrimmed)
+		switch {
+		case nextTrimmed == "" || nextIndent < keyIndent:
+		case strings.HasPrefix(nextTrimmed, "- "):
+			if !sequenceSeen {
+				sequenceSeen = true
+				compact = nextIndent == keyIndent
+			}
+		case indent == 0 && nextIndent > keyIndent:
+			indent = nextIndent - keyIndent
+		}
+		if indent != 0 && sequenceSeen {
+			break
+		}
+	}
+	if indent == 0 {
+		indent = DefaultIndent
+	}
+	return indent, compact
+}
+
+// compactSequences outdents the block sequences that are values of mapping keys in yaml,
+// as written by the encoder with indent, so that their items start at the indentation of their key.
+func compactSequences(yaml string, indent int) string {
+	lines := strings.Split(yaml, "\n")
+
+	// shifts holds the indentation of the keys of the sequences being outdented, innermost last.
+	var shifts []int
+	// scalarIndent is the indentation of the line that started the block scalar being read, or -1.
+	scalarIndent := -1
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			continue
+		}
+		lineIndent := len(line) - len(trimmed)
+		if scalarIndent >= 0 && lineIndent > scalarIndent {
+			lines[i] = outdent(line, len(shifts)*indent)
+			continue
+		}
+		scalarIndent = -1
+
+		for len(shifts) > 0 && lineIndent <= shifts[len(shifts)-1] {
+			shifts = shifts[:len(shifts)-1]
+		}
+		lines[i] = outdent(line, len(shifts)*indent)
+
+		// The key of the line starts after any sequence indicators.
+		keyIndent := lineIndent
+		for strings.HasPrefix(trimmed, "- ") {
+			keyIndent += 2
+			trimmed = trimmed[2:]
+		}
+		value := strings.TrimSpace(stripComment(trimmed))
+		switch {
+		case strings.HasSuffix(value, ":"):
+			if next := nextLine(lines[i+1:]); strings.HasPrefix(next, strings.Repeat(" ", keyIndent+indent)+"- ") {
+				shifts = append(shifts, keyIndent)
+			}
+		case blockScalarRE.MatchString(value):
+			scalarIndent = lineIndent
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blockScalarRE matches a line that starts a block scalar, such as "key: |" or "- >-".
+var blockScalarRE = regexp.MustCompile(`(^|:\s)[|>][-+0-9]*$`)
+
+// outdent removes up to by spaces of indentation from line.
+func outdent(line string, by int) string {
+	return line[min(by, len(line)-len(strings.TrimLeft(line, " "))):]
+}
+
+// nextLine returns the first of lines that is not blank or a comment.
+func nextLine(lines []string) string {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
+// stripComment removes a trailing comment from a line, ignoring # in quoted strings.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// decodeYAML parses the documents of content.
+func decodeYAML(content []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
+// markBlankLines gives the entries of node that follow a blank line in lines a blankLineMarker head comment.
+// First entries are not marked: the first entry of a document is separated from the document comment
+// by the encoder itself, and the first key of a mapping in a sequence shares the line of the item.
+func markBlankLines(node *yaml.Node, lines []string) {
+	if node.Style&yaml.FlowStyle != 0 {
+		return
+	}
+	for i, child := range node.Content {
+		entry := node.Kind == yaml.SequenceNode || (node.Kind == yaml.MappingNode && i%2 == 0)
+		if entry && i > 0 && followsBlankLine(child.Line, lines) {
+			if child.HeadComment == "" {
+				child.HeadComment = blankLineMarker
+			} else {
+				child.HeadComment = blankLineMarker + "\n" + child.HeadComment
+			}
+		}
+		markBlankLines(child, lines)
+	}
+}
+
+// followsBlankLine reports whether the line before the 1-based line number, and any comments above it, is blank.
+func followsBlankLine(line int, lines []string) bool {
+	i := line - 2
+	for i >= 0 && strings.HasPrefix(strings.TrimSpace(lines[i]), "#") {
+		i--
+	}
+	return i >= 0 && strings.TrimSpace(lines[i]) == ""
+}
+
+// sameYAML returns an error if a and b do not hold the same documents.
+func sameYAML(a, b []byte) error {
+	aValues, err := decodeValues(a)
+	if err != nil {
+		return err
+	}
+	bValues, err := decodeValues(b)
+	if err != nil {
+		return fmt.Errorf("formatted YAML does not parse: %w", err)
+	}
+	if !reflect.DeepEqual(aValues, bValues) {
+		return errors.New("formatting would change the meaning of the YAML")
+	}
+	return nil
+}
+
+// decodeValues parses the documents of content into plain values.
+func decodeValues(content []byte) ([]any, error) {
+	var values []any
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var v any
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
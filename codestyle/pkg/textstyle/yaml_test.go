@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textstyle
+
+import (
+	"testing"
+)
+
+func TestFormatYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		indent  int
+		compact bool
+		in      string
+		want    string
+	}{
+		{
+			name:   "reindents manifests",
+			indent: 2,
+			in: `# Copyright header
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: app   # the app
+
+    labels:
+         app: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        args: ["--port", "8080"]
+
+      # The sidecar.
+      - name: sidecar
+        command: |
+            echo hello
+              indented
+`,
+			want: `# Copyright header
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app # the app
+
+  labels:
+    app: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          args: ["--port", "8080"]
+
+        # The sidecar.
+        - name: sidecar
+          command: |
+            echo hello
+              indented
+`,
+		},
+		{
+			name:   "keeps document separators",
+			indent: 2,
+			in:     "---\nkind: Namespace\nmetadata:\n    name: a\n---\nkind: Namespace\nmetadata:\n    name: b\n",
+			want:   "---\nkind: Namespace\nmetadata:\n  name: a\n---\nkind: Namespace\nmetadata:\n  name: b\n",
+		},
+		{
+			name:    "compact sequences",
+			indent:  2,
+			compact: true,
+			in: `spec:
+  containers:
+    - name: app
+      # The arguments.
+      args:
+          - --port
+          - "8080"
+      command:
+        - sh
+        - -c
+        - |
+          items:
+            - a
+  volumes:
+  - name: data
+metadata:
+  name: app
+`,
+			want: `spec:
+  containers:
+  - name: app
+    # The arguments.
+    args:
+    - --port
+    - "8080"
+    command:
+    - sh
+    - -c
+    - |
+      items:
+        - a
+  volumes:
+  - name: data
+metadata:
+  name: app
+`,
+		},
+		{
+			name:   "configured indent",
+			indent: 4,
+			in:     "a:\n  b:\n    c: 1\n",
+			want:   "a:\n    b:\n        c: 1\n",
+		},
+		{
+			name:   "formatted already",
+			indent: 2,
+			in:     "a:\n  b: 1\n\nc: 2\n",
+			want:   "a:\n  b: 1\n\nc: 2\n",
+		},
+		{
+			name:   "comments only",
+			indent: 2,
+			in:     "# Nothing here yet.\n",
+			want:   "# Nothing here yet.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatYAML([]byte(tt.in), tt.indent, tt.compact)
+			if err != nil {
+				t.Fatalf("FormatYAML() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("FormatYAML() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatYAMLInvalid(t *testing.T) {
+	// Helm templates are not YAML until rendered.
+	in := "metadata:\n  name: {{ .Release.Name }}\n  {{- include \"labels\" . }}\n"
+	if _, err := FormatYAML([]byte(in), 2, false); err == nil {
+		t.Errorf("FormatYAML() of a template succeeded, want an error")
+	}
+}
+
+func TestDetectYAMLStyle(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantIndent  int
+		wantCompact bool
+	}{
+		{name: "kubectl", in: "spec:\n  containers:\n  - name: web\n    ports:\n    - containerPort: 80\n", wantIndent: 2, wantCompact: true},
+		{name: "indented sequences", in: "# comment:\nspec:\n    containers:\n        - name: web\n", wantIndent: 4, wantCompact: false},
+		{name: "sequence in sequence item", in: "items:\n  - name: a\n    args:\n      - x\n", wantIndent: 2, wantCompact: false},
+		{name: "flat", in: "kind: Service\n", wantIndent: DefaultIndent, wantCompact: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indent, compact := DetectYAMLStyle([]byte(tt.in))
+			if indent != tt.wantIndent || compact != tt.wantCompact {
+				t.Errorf("DetectYAMLStyle() = %d, %v, want %d, %v", indent, compact, tt.wantIndent, tt.wantCompact)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unused
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"golang.org/x/tools/go/packages"
+)
+
+// ExportedRule is the rule name of the findings of Exported.
+const ExportedRule = "unusedexported"
+
+// position identifies a declaration across the packages of a load, whose test variants
+// type-check the same files into different objects.
+type position struct {
+	filename string
+	offset   int
+}
+
+// Exported reports the exported functions and types of the module in dir that nothing in the module uses.
+// Unlike the unused analyzer, which sees one package at a time, it loads every package of the module (with their tests),
+// so uses from other packages count. Uses from tests count too; main packages, test files and generated files are not checked.
+// Methods are not checked either, as they can be used through interfaces, and a type that is only the receiver
+// of its own methods is unused.
+func Exported(ctx context.Context, dir string, sev findings.Severity) ([]findings.Finding, error) {
+	cfg := &packages.Config{
+		Context: ctx,
+		Dir:     dir,
+		Fset:    token.NewFileSet(),
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Tests:   true,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages in %s: %w", dir, err)
+	}
+	var errs []error
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			errs = append(errs, err)
+		}
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to load packages in %s: %w", dir, errors.Join(errs...))
+	}
+
+	posOf := func(pos token.Pos) position {
+		p := cfg.Fset.Position(pos)
+		return position{p.Filename, p.Offset}
+	}
+
+	used := map[position]bool{}
+	for _, pkg := range pkgs {
+		receivers := receiverIdents(pkg.Syntax)
+		for ident, obj := range pkg.TypesInfo.Uses {
+			// A type is not used by the declarations of its own methods.
+			if receivers[ident] {
+				continue
+			}
+			if fn, ok := obj.(*types.Func); ok {
+				obj = fn.Origin()
+			}
+			if obj.Pkg() != nil {
+				used[posOf(obj.Pos())] = true
+			}
+		}
+	}
+
+	var found []findings.Finding
+	seen := map[position]bool{}
+	for _, pkg := range pkgs {
+		// The test variants of a package ("p [p.test]") declare the same objects as the package itself.
+		if pkg.Name == "main" || pkg.ID != pkg.PkgPath {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			filename := cfg.Fset.Position(f.Pos()).Filename
+			if isGenerated(f) || strings.HasSuffix(filename, "_test.go") {
+				continue
+			}
+			for _, decl := range f.Decls {
+				for _, ident := range exportedDecls(decl) {
+					pos := posOf(ident.Pos())
+					if used[pos] || seen[pos] {
+						continue
+					}
+					seen[pos] = true
+					kind := "function"
+					if _, ok := pkg.TypesInfo.Defs[ident].(*types.TypeName); ok {
+						kind = "type"
+					}
+					p := cfg.Fset.Position(ident.Pos())
+					found = append(found, findings.Finding{
+						Path:     p.Filename,
+						Line:     p.Line,
+						Column:   p.Column,
+						Rule:     ExportedRule,
+						Message:  fmt.Sprintf("exported %s %s.%s is not used anywhere in the module", kind, pkg.Name, ident.Name),
+						Severity: sev,
+					})
+				}
+			}
+		}
+	}
+	slices.SortFunc(found, func(a, b findings.Finding) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), cmp.Compare(a.Line, b.Line))
+	})
+	return found, nil
+}
+
+// receiverIdents returns the identifiers in the receivers of the methods declared in files.
+func receiverIdents(files []*ast.File) map[*ast.Ident]bool {
+	idents := map[*ast.Ident]bool{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+				ast.Inspect(fn.Recv, func(n ast.Node) bool {
+					if ident, ok := n.(*ast.Ident); ok {
+						idents[ident] = true
+					}
+					return true
+				})
+			}
+		}
+	}
+	return idents
+}
+
+// exportedDecls returns the names of the exported functions (not methods) and types declared by decl.
+func exportedDecls(decl ast.Decl) []*ast.Ident {
+	var idents []*ast.Ident
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv == nil && decl.Name.IsExported() {
+			idents = append(idents, decl.Name)
+		}
+	case *ast.GenDecl:
+		if decl.Tok != token.TYPE {
+			break
+		}
+		for _, spec := range decl.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+				idents = append(idents, ts.Name)
+			}
+		}
+	}
+	return idents
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lib
+
+// Used is used by another package.
+func Used() {}
+
+// UsedInPackage is used by its own package.
+func UsedInPackage() {}
+
+// UsedInTest is only used by a test.
+func UsedInTest() {}
+
+// Unused is not used anywhere.
+func Unused() {
+	UsedInPackage()
+}
+
+// Map is used with type arguments.
+func Map[T any](v T) T { return v }
+
+// UsedType is used by another package.
+type UsedType struct{}
+
+// UnusedType is not used anywhere.
+type UnusedType struct{}
+
+// Method is not checked, as methods can be used through interfaces.
+func (UnusedType) Method() {}
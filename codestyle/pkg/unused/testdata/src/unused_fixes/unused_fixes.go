@@ -0,0 +1,36 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unused_fixes
+
+type config struct {
+	name string
+
+	// retries is documented.
+	retries int // want "field retries is unused"
+	a, b    int // want "field a is unused"
+	c, d    int // want "field d is unused"
+}
+
+type inline struct{ used, spare int } // want "field spare is unused"
+
+func greet(prefix string, n int) string { // want "parameter n is unused, consider removing or renaming it as _"
+	return prefix
+}
+
+func Main() {
+	c := config{name: "x", b: 1, c: 2}
+	i := inline{used: 1}
+	_ = greet(c.name, c.b+c.c+i.used)
+}
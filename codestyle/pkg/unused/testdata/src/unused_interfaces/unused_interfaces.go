@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unused_interfaces
+
+import "sort"
+
+// task is implemented by the task types, whose methods are only called through it.
+type task interface {
+	run() error
+	name() string
+}
+
+type buildTask struct{}
+
+func (buildTask) run() error { return nil }
+
+func (buildTask) name() string { return "build" }
+
+func (buildTask) cleanup() {} // want "method cleanup is unused"
+
+type testTask struct{}
+
+func (*testTask) run() error { return nil }
+
+func (*testTask) name() string { return "test" }
+
+func RunAll() {
+	for _, t := range []task{buildTask{}, &testTask{}} {
+		_ = t.run()
+	}
+}
+
+// byName is only passed to sort.Sort, which calls its (exported) methods through sort.Interface.
+type byName []string
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Less(i, j int) bool { return b[i] < b[j] }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+func Sort(names []string) {
+	sort.Sort(byName(names))
+}
+
+// closer is declared, but never used.
+type closer interface {
+	close() error
+}
+
+type file struct{}
+
+func (file) close() error { return nil } // want "method close is unused"
+
+// describe takes an interface literal, implemented by point.
+func describe(s interface{ describe() string }) string {
+	return s.describe()
+}
+
+type point struct{}
+
+func (point) describe() string { return "point" }
+
+func Describe() string {
+	return describe(point{})
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unused_nolint
+
+type options struct {
+	verbose bool //nolint:unused // set by reflection
+	//nolint:unused
+	debug bool
+	quiet bool //keep:unused
+	trace bool // want "field trace is unused"
+}
+
+//nolint:unused
+func legacy() {}
+
+//nolint:errcheck,unused // kept for the next release
+func next() {}
+
+// nolint
+func anything() {}
+
+//nolint:errcheck
+func other() {} // want "func other is unused"
+
+func handler(id string, v int) { //nolint:unused
+}
+
+// keep is kept, as its parameters are.
+//
+//keep:unused
+func keep(a int) {}
+
+func Main() {
+	var o options
+	_ = o
+	handler("", 0)
+}
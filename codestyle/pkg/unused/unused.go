@@ -15,14 +15,20 @@
 package unused
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
+	"slices"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
 )
 
-var checkParameters bool
+var (
+	checkParameters bool
+	suppressComment string
+)
 
 var Analyzer = &analysis.Analyzer{
 	Name: "unused",
@@ -32,6 +38,7 @@ var Analyzer = &analysis.Analyzer{
 
 func init() {
 	Analyzer.Flags.BoolVar(&checkParameters, "check-parameters", false, "report unused parameters")
+	Analyzer.Flags.StringVar(&suppressComment, "suppress-comment", "", "a comment that suppresses the findings on its line or of the declaration it documents, in addition to //nolint:unused")
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
@@ -44,19 +51,22 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 	}
 
+	ifaces := referencedInterfaces(pass)
+
 	for _, f := range pass.Files {
 		if isGenerated(f) {
 			continue
 		}
+		r := newReporter(pass, f)
 		ast.Inspect(f, func(n ast.Node) bool {
 			switch node := n.(type) {
 			case *ast.FuncDecl:
-				checkUnusedParams(pass, node.Type.Params, node.Body, used)
-				checkUnusedFunc(pass, node, used)
+				checkUnusedParams(r, node.Type.Params, node.Body, used, node.Doc)
+				checkUnusedFunc(r, node, used, ifaces)
 			case *ast.FuncLit:
-				checkUnusedParams(pass, node.Type.Params, node.Body, used)
+				checkUnusedParams(r, node.Type.Params, node.Body, used, nil)
 			case *ast.StructType:
-				checkUnusedFields(pass, node, used)
+				checkUnusedFields(r, node, used)
 			}
 			return true
 		})
@@ -64,7 +74,8 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	return nil, nil
 }
 
-func checkUnusedParams(pass *analysis.Pass, params *ast.FieldList, body *ast.BlockStmt, used map[token.Pos]bool) {
+// checkUnusedParams reports the unused parameters of a function, which is documented by doc if it is declared.
+func checkUnusedParams(r *reporter, params *ast.FieldList, body *ast.BlockStmt, used map[token.Pos]bool, doc *ast.CommentGroup) {
 	if !checkParameters {
 		return
 	}
@@ -76,15 +87,19 @@ func checkUnusedParams(pass *analysis.Pass, params *ast.FieldList, body *ast.Blo
 			if name.Name == "_" {
 				continue
 			}
-			obj := pass.TypesInfo.Defs[name]
+			obj := r.pass.TypesInfo.Defs[name]
 			if obj != nil && !used[obj.Pos()] {
-				pass.Reportf(name.Pos(), "parameter %s is unused, consider removing or renaming it as _", name.Name)
+				fix := analysis.SuggestedFix{
+					Message:   "Rename the parameter to _",
+					TextEdits: []analysis.TextEdit{{Pos: name.Pos(), End: name.End(), NewText: []byte("_")}},
+				}
+				r.report(name.Pos(), []*ast.CommentGroup{doc}, []analysis.SuggestedFix{fix}, "parameter %s is unused, consider removing or renaming it as _", name.Name)
 			}
 		}
 	}
 }
 
-func checkUnusedFunc(pass *analysis.Pass, fn *ast.FuncDecl, used map[token.Pos]bool) {
+func checkUnusedFunc(r *reporter, fn *ast.FuncDecl, used map[token.Pos]bool, ifaces []*types.Interface) {
 	name := fn.Name.Name
 	if name == "main" || name == "init" || strings.HasPrefix(name, "Test") || strings.HasPrefix(name, "Benchmark") || strings.HasPrefix(name, "Example") {
 		return
@@ -93,20 +108,72 @@ func checkUnusedFunc(pass *analysis.Pass, fn *ast.FuncDecl, used map[token.Pos]b
 	if ast.IsExported(name) {
 		return
 	}
-	obj := pass.TypesInfo.Defs[fn.Name]
+	obj := r.pass.TypesInfo.Defs[fn.Name]
 	if obj != nil && !used[obj.Pos()] {
+		docs := []*ast.CommentGroup{fn.Doc}
 		if fn.Recv == nil {
-			pass.Reportf(fn.Name.Pos(), "func %s is unused", name)
-		} else {
-			// For methods, we should be careful about interfaces.
-			// But if it's unexported, it can only satisfy unexported interfaces in the same package.
-			// For now, let's report it as unused if it's not in Uses.
-			pass.Reportf(fn.Name.Pos(), "method %s is unused", name)
+			r.report(fn.Name.Pos(), docs, nil, "func %s is unused", name)
+		} else if !implementsReferenced(obj.(*types.Func), ifaces) {
+			// Methods called through an interface are not in Uses, so those implementing a referenced
+			// interface are assumed to be used.
+			r.report(fn.Name.Pos(), docs, nil, "method %s is unused", name)
 		}
 	}
 }
 
-func checkUnusedFields(pass *analysis.Pass, st *ast.StructType, used map[token.Pos]bool) {
+// referencedInterfaces returns the non-empty interfaces the package refers to by name (e.g. in a declaration
+// or a conversion), or uses as the type of a value. Interfaces that are only declared are left out.
+func referencedInterfaces(pass *analysis.Pass) []*types.Interface {
+	var ifaces []*types.Interface
+	seen := make(map[*types.Interface]bool)
+	add := func(t types.Type) {
+		iface, ok := t.Underlying().(*types.Interface)
+		if ok && iface.NumMethods() > 0 && !seen[iface] {
+			seen[iface] = true
+			ifaces = append(ifaces, iface)
+		}
+	}
+	for _, obj := range pass.TypesInfo.Uses {
+		if tn, ok := obj.(*types.TypeName); ok {
+			add(tn.Type())
+		}
+	}
+	for _, tv := range pass.TypesInfo.Types {
+		if !tv.IsType() && tv.Type != nil {
+			add(tv.Type)
+		}
+	}
+	return ifaces
+}
+
+// implementsReferenced reports whether the method fn is part of the implementation of one of ifaces by its
+// receiver type (or a pointer to it).
+func implementsReferenced(fn *types.Func, ifaces []*types.Interface) bool {
+	recv := fn.Type().(*types.Signature).Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	for _, iface := range ifaces {
+		if !hasMethod(iface, fn.Name()) {
+			continue
+		}
+		if types.Implements(recv, iface) || types.Implements(types.NewPointer(recv), iface) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMethod(iface *types.Interface, name string) bool {
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkUnusedFields(r *reporter, st *ast.StructType, used map[token.Pos]bool) {
 	if st.Fields == nil {
 		return
 	}
@@ -115,9 +182,13 @@ func checkUnusedFields(pass *analysis.Pass, st *ast.StructType, used map[token.P
 			if name.Name == "_" || ast.IsExported(name.Name) {
 				continue
 			}
-			obj := pass.TypesInfo.Defs[name]
+			obj := r.pass.TypesInfo.Defs[name]
 			if obj != nil && !used[obj.Pos()] {
-				pass.Reportf(name.Pos(), "field %s is unused", name.Name)
+				fix := analysis.SuggestedFix{
+					Message:   "Remove the field",
+					TextEdits: []analysis.TextEdit{removeField(r.pass, st, field, name)},
+				}
+				r.report(name.Pos(), []*ast.CommentGroup{field.Doc, field.Comment}, []analysis.SuggestedFix{fix}, "field %s is unused", name.Name)
 			}
 		}
 	}
@@ -133,3 +204,98 @@ func isGenerated(f *ast.File) bool {
 	}
 	return false
 }
+
+// removeField returns the edit removing the field name, one of the names of field in st.
+// A field on lines of its own is removed with its lines, including its comments.
+func removeField(pass *analysis.Pass, st *ast.StructType, field *ast.Field, name *ast.Ident) analysis.TextEdit {
+	if len(field.Names) > 1 {
+		// Remove the name with the comma after it, or before it for the last name.
+		i := slices.Index(field.Names, name)
+		if i < len(field.Names)-1 {
+			return analysis.TextEdit{Pos: name.Pos(), End: field.Names[i+1].Pos()}
+		}
+		return analysis.TextEdit{Pos: field.Names[i-1].End(), End: name.End()}
+	}
+
+	start, end := field.Pos(), field.End()
+	if field.Doc != nil {
+		start = field.Doc.Pos()
+	}
+	if field.Comment != nil {
+		end = field.Comment.End()
+	}
+	tf := pass.Fset.File(start)
+	first, last := tf.Line(start), tf.Line(end)
+	alone := tf.Line(st.Fields.Opening) < first && tf.Line(st.Fields.Closing) > last
+	for _, other := range st.Fields.List {
+		if other != field && tf.Line(other.Pos()) <= last && tf.Line(other.End()) >= first {
+			alone = false
+		}
+	}
+	if !alone {
+		return analysis.TextEdit{Pos: start, End: end}
+	}
+	return analysis.TextEdit{Pos: tf.LineStart(first), End: tf.LineStart(last + 1)}
+}
+
+// reporter reports the findings in a file, unless they are suppressed by a comment.
+type reporter struct {
+	pass *analysis.Pass
+	// lines holds the lines of the file with a suppression comment.
+	lines map[int]bool
+}
+
+func newReporter(pass *analysis.Pass, f *ast.File) *reporter {
+	r := &reporter{pass: pass, lines: make(map[int]bool)}
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if isSuppression(c.Text) {
+				r.lines[pass.Fset.Position(c.Slash).Line] = true
+			}
+		}
+	}
+	return r
+}
+
+// report reports a finding at pos, unless a suppression comment is on the same line or in one of docs,
+// the comments of the declaration reported.
+func (r *reporter) report(pos token.Pos, docs []*ast.CommentGroup, fixes []analysis.SuggestedFix, format string, args ...any) {
+	if r.lines[r.pass.Fset.Position(pos).Line] {
+		return
+	}
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if isSuppression(c.Text) {
+				return
+			}
+		}
+	}
+	r.pass.Report(analysis.Diagnostic{
+		Pos:            pos,
+		Message:        fmt.Sprintf(format, args...),
+		SuggestedFixes: fixes,
+	})
+}
+
+// isSuppression returns true if the comment text suppresses the findings of the analyzer: //nolint:unused
+// (as in golangci-lint, possibly among other linters and followed by an explanation), a bare //nolint
+// (for every linter), or the comment configured with -suppress-comment.
+func isSuppression(text string) bool {
+	if suppressComment != "" && strings.HasPrefix(text, suppressComment) {
+		return true
+	}
+	// gofmt adds a space to a bare //nolint doc comment.
+	directive, ok := strings.CutPrefix(strings.TrimLeft(strings.TrimPrefix(text, "//"), " "), "nolint")
+	if !ok {
+		return false
+	}
+	directive, _, _ = strings.Cut(directive, " ")
+	if directive == "" {
+		return true
+	}
+	linters, ok := strings.CutPrefix(directive, ":")
+	return ok && slices.Contains(strings.Split(linters, ","), "unused")
+}
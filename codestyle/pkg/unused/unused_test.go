@@ -1,9 +1,27 @@
 // Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package unused
 
 import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
@@ -12,9 +30,57 @@ func TestUnused(t *testing.T) {
 	analysistest.Run(t, testdata, Analyzer, "unused_test")
 }
 
+func TestUnusedInterfaceMethods(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "unused_interfaces")
+}
+
 func TestUnusedParameters(t *testing.T) {
 	testdata := analysistest.TestData()
 	Analyzer.Flags.Set("check-parameters", "true")
 	defer Analyzer.Flags.Set("check-parameters", "false")
 	analysistest.Run(t, testdata, Analyzer, "unused_params")
 }
+
+func TestSuggestedFixes(t *testing.T) {
+	testdata := analysistest.TestData()
+	Analyzer.Flags.Set("check-parameters", "true")
+	defer Analyzer.Flags.Set("check-parameters", "false")
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "unused_fixes")
+}
+
+func TestSuppression(t *testing.T) {
+	testdata := analysistest.TestData()
+	Analyzer.Flags.Set("check-parameters", "true")
+	Analyzer.Flags.Set("suppress-comment", "//keep:unused")
+	defer Analyzer.Flags.Set("check-parameters", "false")
+	defer Analyzer.Flags.Set("suppress-comment", "")
+	analysistest.Run(t, testdata, Analyzer, "unused_nolint")
+}
+
+func TestExported(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "exported"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := Exported(t.Context(), dir, findings.SeverityWarning)
+	if err != nil {
+		t.Fatalf("Exported() error = %v", err)
+	}
+
+	var got []string
+	for _, f := range found {
+		rel, err := filepath.Rel(dir, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s:%d: %s", rel, f.Line, f.Message))
+	}
+	want := []string{
+		"lib/lib.go:27: exported function lib.Unused is not used anywhere in the module",
+		"lib/lib.go:38: exported type lib.UnusedType is not used anywhere in the module",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Exported() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
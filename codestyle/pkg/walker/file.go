@@ -15,8 +15,13 @@
 package walker
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // File represents a file in the file system.
@@ -30,46 +35,85 @@ type File struct {
 type FileView struct {
 	Dir    string
 	Ignore *IgnoreList
+	// GitFiles limits the walk to the files returned by GitFiles, so that files ignored by .gitignore
+	// (e.g. build output) are skipped.
+	GitFiles bool
+	// Gitignore skips the files ignored by the .gitignore files of Dir and its subdirectories, in addition
+	// to those matching Ignore. Their negated patterns ("!pattern") cannot re-include files matching Ignore.
+	Gitignore bool
+	// Workers is the number of directories read concurrently (defaults to GOMAXPROCS).
+	Workers int
+	// FollowSymlinks walks the directories that symbolic links point to, except those containing the link,
+	// which would loop. Otherwise links are passed to the callback as files, as filepath.Walk does.
+	FollowSymlinks bool
 }
 
-// NewFileView creates a new FileView.
+// NewFileView creates a new FileView, which honors .gitignore files.
 func NewFileView(dir string, ignorePatterns []string) *FileView {
 	return &FileView{
-		Dir:    dir,
-		Ignore: NewIgnoreList(ignorePatterns),
+		Dir:       dir,
+		Ignore:    NewIgnoreList(ignorePatterns),
+		Gitignore: true,
 	}
 }
 
-// Walk walks the directory tree and calls callback for each file.
+// Walk walks the directory tree and calls callback for each file, in lexical order.
+// Directories are read concurrently, but callback is called for one file at a time.
 func (v *FileView) Walk(callback func(File) error) error {
-	return filepath.Walk(v.Dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	if v.GitFiles {
+		return v.walkGitFiles(callback)
+	}
+	return v.walkTree(callback)
+}
 
-		relPath, err := filepath.Rel(v.Dir, path)
+// walkGitFiles calls callback for each of the GitFiles of the directory that is not ignored.
+func (v *FileView) walkGitFiles(callback func(File) error) error {
+	relPaths, err := GitFiles(v.Dir)
+	if err != nil {
+		return err
+	}
+	for _, relPath := range relPaths {
+		if v.Ignore != nil && v.Ignore.ShouldIgnoreFile(relPath) {
+			continue
+		}
+		path := filepath.Join(v.Dir, relPath)
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			// Deleted, but not yet staged.
+			continue
+		}
 		if err != nil {
 			return err
 		}
-		if relPath == "." {
-			return nil
+		if info.IsDir() {
+			// A submodule.
+			continue
 		}
-
-		if v.Ignore != nil && v.Ignore.ShouldIgnore(relPath, info.IsDir()) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+		if err := callback(File{Path: path, Info: info, RelPath: relPath}); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if info.IsDir() {
-			return nil
+// GitFiles returns the files under dir that git knows of, relative to dir and sorted: those that are tracked,
+// and those that are untracked but not ignored by .gitignore.
+func GitFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of %s with git: %w: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, filepath.FromSlash(file))
 		}
-
-		return callback(File{
-			Path:    path,
-			Info:    info,
-			RelPath: relPath,
-		})
-	})
+	}
+	slices.Sort(files)
+	// Files with merge conflicts are listed once for each stage.
+	return slices.Compact(files), nil
 }
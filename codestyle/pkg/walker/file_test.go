@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package walker
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFileView_Gitignore(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":               "*.gen.go\n/out/\n",
+		"main.go":                  "",
+		"main.gen.go":              "",
+		"out/bin":                  "",
+		"pkg/out/keep.go":          "",
+		"pkg/.gitignore":           "!special.gen.go\nlocal.txt\n",
+		"pkg/special.gen.go":       "",
+		"pkg/local.txt":            "",
+		"local.txt":                "",
+		"vendor/dep.go":            "",
+		"vendor/.gitignore":        "!*\n",
+		"testdata/skip/x.gen.go":   "",
+		"testdata/skip/.gitignore": "!x.gen.go\n",
+	}
+	for path, content := range files {
+		p := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := NewFileView(dir, []string{"vendor/", "testdata/"})
+	var got []string
+	if err := v.Walk(func(f File) error {
+		got = append(got, filepath.ToSlash(f.RelPath))
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	slices.Sort(got)
+	want := []string{".gitignore", "local.txt", "main.go", "pkg/.gitignore", "pkg/out/keep.go", "pkg/special.gen.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+
+	v.Gitignore = false
+	got = nil
+	if err := v.Walk(func(f File) error {
+		got = append(got, filepath.ToSlash(f.RelPath))
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if !slices.Contains(got, "main.gen.go") || !slices.Contains(got, "out/bin") {
+		t.Errorf("Walk() without Gitignore = %v, want the files ignored by .gitignore too", got)
+	}
+}
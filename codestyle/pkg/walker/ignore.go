@@ -15,7 +15,10 @@
 package walker
 
 import (
+	"bufio"
+	"bytes"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -47,6 +50,10 @@ type pathMatcher struct {
 	segments          []segmentMatcher
 	mustBeDir         bool
 	matchBasenameOnly bool
+	// negate re-includes the paths the pattern matches, as with a "!" prefix in .gitignore.
+	negate bool
+	// base are the segments of the directory the pattern is relative to, e.g. that of its .gitignore file.
+	base []string
 }
 
 func (p *pathMatcher) Matches(pathSegments []string, isDir bool) bool {
@@ -54,6 +61,13 @@ func (p *pathMatcher) Matches(pathSegments []string, isDir bool) bool {
 		return false
 	}
 
+	if len(p.base) > 0 {
+		if len(pathSegments) <= len(p.base) || !slices.Equal(pathSegments[:len(p.base)], p.base) {
+			return false
+		}
+		pathSegments = pathSegments[len(p.base):]
+	}
+
 	if p.matchBasenameOnly {
 		if len(pathSegments) == 0 {
 			return false
@@ -98,20 +112,58 @@ func matchSegments(pattern []segmentMatcher, path []string) bool {
 }
 
 // IgnoreList matches paths against a list of patterns, similar to .gitignore.
+// As in .gitignore, the last pattern matching a path decides, so "!pattern" re-includes paths ignored before.
 type IgnoreList struct {
 	matchers []*pathMatcher
 }
 
 // NewIgnoreList creates a new IgnoreList.
 func NewIgnoreList(patterns []string) *IgnoreList {
-	var matchers []*pathMatcher
+	l := &IgnoreList{}
+	l.AddPatterns("", patterns)
+	return l
+}
+
+// AddPatterns adds patterns relative to dir, a directory relative to the root of the walk ("" for the root).
+// They only match paths under dir, as those of a .gitignore file in dir do.
+func (l *IgnoreList) AddPatterns(dir string, patterns []string) {
+	var base []string
+	if dir = filepath.ToSlash(filepath.Clean(dir)); dir != "." {
+		base = strings.Split(dir, "/")
+	}
 	for _, p := range patterns {
-		matchers = append(matchers, parsePattern(p))
+		m := parsePattern(p)
+		m.base = base
+		l.matchers = append(l.matchers, m)
 	}
-	return &IgnoreList{matchers: matchers}
+}
+
+// ParseGitignore returns the patterns of a .gitignore file, without its comments and blank lines.
+func ParseGitignore(data []byte) []string {
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Trailing spaces are ignored unless escaped with a backslash.
+		if trimmed := strings.TrimRight(line, " "); !strings.HasSuffix(trimmed, "\\") || trimmed == line {
+			line = trimmed
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
 }
 
 func parsePattern(pattern string) *pathMatcher {
+	negate := strings.HasPrefix(pattern, "!")
+	pattern = strings.TrimPrefix(pattern, "!")
+	if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+	pattern = strings.ReplaceAll(pattern, `\ `, " ")
+
 	mustBeDir := strings.HasSuffix(pattern, "/")
 	cleanPattern := strings.TrimSuffix(pattern, "/")
 
@@ -121,6 +173,8 @@ func parsePattern(pattern string) *pathMatcher {
 	// If it contains /, it's anchored.
 
 	isAnchored := strings.Contains(cleanPattern, "/")
+	// A leading slash anchors the pattern to its directory, e.g. "/build" matches build but not pkg/build.
+	cleanPattern = strings.TrimPrefix(cleanPattern, "/")
 
 	// Special case: if pattern is just "**", it matches everything?
 	// gitignore says: "A leading "**" followed by a slash means match in all directories."
@@ -142,20 +196,40 @@ func parsePattern(pattern string) *pathMatcher {
 		segments:          segments,
 		mustBeDir:         mustBeDir,
 		matchBasenameOnly: !isAnchored,
+		negate:            negate,
 	}
 }
 
 // ShouldIgnore returns true if the path should be ignored.
 // path should be relative to the root of the walk.
 func (l *IgnoreList) ShouldIgnore(path string, isDir bool) bool {
+	ignored, _ := l.match(path, isDir)
+	return ignored
+}
+
+// match returns whether path is ignored, and whether any pattern matches it at all.
+func (l *IgnoreList) match(path string, isDir bool) (ignored, matched bool) {
 	// Normalize path to use /
 	path = filepath.ToSlash(path)
 	pathSegments := strings.Split(path, "/")
 
-	for _, m := range l.matchers {
+	// The last matching pattern decides.
+	for _, m := range slices.Backward(l.matchers) {
 		if m.Matches(pathSegments, isDir) {
+			return !m.negate, true
+		}
+	}
+	return false, false
+}
+
+// ShouldIgnoreFile returns true if the file at path, or any directory containing it, should be ignored.
+// path should be relative to the root of the walk.
+func (l *IgnoreList) ShouldIgnoreFile(path string) bool {
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i := 1; i < len(segments); i++ {
+		if l.ShouldIgnore(strings.Join(segments[:i], "/"), true) {
 			return true
 		}
 	}
-	return false
+	return l.ShouldIgnore(path, false)
 }
@@ -15,6 +15,7 @@
 package walker
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -114,3 +115,43 @@ func TestIgnoreList(t *testing.T) {
 		}
 	}
 }
+
+func TestShouldIgnoreFile(t *testing.T) {
+	l := NewIgnoreList([]string{"vendor/", "hack/*.sh", "*.json"})
+
+	for _, path := range []string{"vendor/foo.go", "pkg/vendor/a/b.go", "hack/boilerplate.sh", "config/app.json"} {
+		if !l.ShouldIgnoreFile(path) {
+			t.Errorf("ShouldIgnoreFile(%q) = false, want true", path)
+		}
+	}
+	for _, path := range []string{"vendor.go", "pkg/hack/boilerplate.sh", "hack/sub/run.sh", "main.go"} {
+		if l.ShouldIgnoreFile(path) {
+			t.Errorf("ShouldIgnoreFile(%q) = true, want false", path)
+		}
+	}
+}
+
+func TestIgnoreList_GitignoreSemantics(t *testing.T) {
+	l := NewIgnoreList([]string{"*.log", "!keep.log", "/build", `\#notes`})
+	l.AddPatterns("pkg/sub", []string{"*.tmp", "!/important.tmp", "/out/"})
+
+	for _, path := range []string{"debug.log", "pkg/debug.log", "build", "#notes", "pkg/sub/x.tmp", "pkg/sub/deeper/x.tmp", "pkg/sub/out/a.go"} {
+		if !l.ShouldIgnoreFile(path) {
+			t.Errorf("ShouldIgnoreFile(%q) = false, want true", path)
+		}
+	}
+	for _, path := range []string{"keep.log", "pkg/keep.log", "pkg/build", "x.tmp", "pkg/x.tmp", "pkg/sub/important.tmp", "pkg/sub/deeper/out/a.go"} {
+		if l.ShouldIgnoreFile(path) {
+			t.Errorf("ShouldIgnoreFile(%q) = true, want false", path)
+		}
+	}
+}
+
+func TestParseGitignore(t *testing.T) {
+	data := "# comment\n\n*.o\r\n/build/  \ntrailing\\ \n\\#hash\n!keep.o\n"
+	got := ParseGitignore([]byte(data))
+	want := []string{"*.o", "/build/", `trailing\ `, `\#hash`, "!keep.o"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseGitignore() = %q, want %q", got, want)
+	}
+}
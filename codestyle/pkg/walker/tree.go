@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package walker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// dirNode is a directory of the tree being walked, which a worker reads while the walk is
+// still calling back for the files before it.
+type dirNode struct {
+	path, relPath string
+	// ancestors are the real paths of the directory and those containing it, to detect symlink loops.
+	ancestors []string
+	gitignore *ignoreScope
+
+	// done is closed once entries and err are set.
+	done    chan struct{}
+	entries []dirEntry
+	err     error
+}
+
+// dirEntry is a file to call back for, or a subdirectory to walk, in a dirNode.
+type dirEntry struct {
+	file File
+	dir  *dirNode
+}
+
+// ignoreScope holds the patterns of the .gitignore file of a directory, chained to those of the
+// directories containing it. The patterns of the deepest .gitignore matching a path decide.
+type ignoreScope struct {
+	list   *IgnoreList
+	parent *ignoreScope
+}
+
+func (s *ignoreScope) shouldIgnore(relPath string, isDir bool) bool {
+	for ; s != nil; s = s.parent {
+		if ignored, matched := s.list.match(relPath, isDir); matched {
+			return ignored
+		}
+	}
+	return false
+}
+
+// treeWalk reads the directories of a walk with a bounded number of workers.
+type treeWalk struct {
+	view *FileView
+	// sem holds a token for each directory being read.
+	sem chan struct{}
+	// stop is closed when the walk ends early, so that the directories not read yet are skipped.
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// walkTree walks Dir, reading directories concurrently, and calls callback in lexical order.
+func (v *FileView) walkTree(callback func(File) error) error {
+	info, err := os.Stat(v.Dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	realDir, err := filepath.EvalSymlinks(v.Dir)
+	if err != nil {
+		return err
+	}
+
+	workers := v.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	w := &treeWalk{
+		view: v,
+		sem:  make(chan struct{}, workers),
+		stop: make(chan struct{}),
+	}
+	root := w.start(v.Dir, ".", []string{realDir}, nil)
+	err = w.visit(root, callback)
+	w.stopOnce.Do(func() { close(w.stop) })
+	w.wg.Wait()
+	return err
+}
+
+// start returns the node of a directory, which a worker reads as soon as one is free.
+func (w *treeWalk) start(path, relPath string, ancestors []string, gitignore *ignoreScope) *dirNode {
+	node := &dirNode{path: path, relPath: relPath, ancestors: ancestors, gitignore: gitignore, done: make(chan struct{})}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer close(node.done)
+		select {
+		case w.sem <- struct{}{}:
+		case <-w.stop:
+			node.err = errStopped
+			return
+		}
+		defer func() { <-w.sem }()
+		node.entries, node.err = w.read(node)
+	}()
+	return node
+}
+
+var errStopped = errors.New("walk stopped")
+
+// visit calls callback for the files under node, in order, waiting for the directories to be read.
+func (w *treeWalk) visit(node *dirNode, callback func(File) error) error {
+	<-node.done
+	if node.err != nil {
+		return node.err
+	}
+	entries := node.entries
+	// The entries are no longer needed once visited; large trees are not kept in memory.
+	node.entries = nil
+	for _, entry := range entries {
+		var err error
+		if entry.dir != nil {
+			err = w.visit(entry.dir, callback)
+		} else {
+			err = callback(entry.file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read lists the directory of node, skipping ignored entries, and starts reading its subdirectories.
+func (w *treeWalk) read(node *dirNode) ([]dirEntry, error) {
+	v := w.view
+	dirEntries, err := os.ReadDir(node.path)
+	if err != nil {
+		return nil, err
+	}
+
+	gitignore := node.gitignore
+	if v.Gitignore {
+		data, err := os.ReadFile(filepath.Join(node.path, ".gitignore"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if patterns := ParseGitignore(data); len(patterns) > 0 {
+			list := NewIgnoreList(nil)
+			list.AddPatterns(node.relPath, patterns)
+			gitignore = &ignoreScope{list: list, parent: gitignore}
+		}
+	}
+
+	var entries []dirEntry
+	for _, e := range dirEntries {
+		path := filepath.Join(node.path, e.Name())
+		relPath := filepath.Join(node.relPath, e.Name())
+		info, err := e.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// Deleted since the directory was read.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		isDir := info.IsDir()
+		var realPath string
+		if v.FollowSymlinks && info.Mode()&fs.ModeSymlink != 0 {
+			if target, err := os.Stat(path); err == nil && target.IsDir() {
+				if realPath, err = filepath.EvalSymlinks(path); err != nil {
+					return nil, err
+				}
+				if slices.Contains(node.ancestors, realPath) {
+					klog.V(2).Infof("Not following %s, which links to a directory containing it", path)
+					continue
+				}
+				isDir = true
+			}
+		}
+
+		if v.Ignore != nil && v.Ignore.ShouldIgnore(relPath, isDir) {
+			continue
+		}
+		if gitignore.shouldIgnore(relPath, isDir) {
+			continue
+		}
+
+		if !isDir {
+			entries = append(entries, dirEntry{file: File{Path: path, Info: info, RelPath: relPath}})
+			continue
+		}
+		if realPath == "" {
+			realPath = filepath.Join(node.ancestors[len(node.ancestors)-1], e.Name())
+		}
+		ancestors := append(slices.Clip(node.ancestors), realPath)
+		entries = append(entries, dirEntry{dir: w.start(path, relPath, ancestors, gitignore)})
+	}
+	return entries, nil
+}
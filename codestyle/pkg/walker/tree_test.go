@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package walker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeTree(t *testing.T, dir string, files []string) {
+	t.Helper()
+	for _, file := range files {
+		p := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func walkPaths(t *testing.T, v *FileView) []string {
+	t.Helper()
+	var got []string
+	if err := v.Walk(func(f File) error {
+		got = append(got, filepath.ToSlash(f.RelPath))
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	return got
+}
+
+func TestWalkTree_Order(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := range 20 {
+		for j := range 5 {
+			files = append(files, fmt.Sprintf("d%02d/sub%d/f.go", i, j), fmt.Sprintf("d%02d/f%d.go", i, j))
+		}
+	}
+	files = append(files, "a.go", "z.go", "skipped/f.go")
+	writeTree(t, dir, files)
+
+	var want []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if info.IsDir() && rel == "skipped" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			want = append(want, filepath.ToSlash(rel))
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{1, 4, 64} {
+		v := &FileView{Dir: dir, Ignore: NewIgnoreList([]string{"skipped/"}), Workers: workers}
+		if got := walkPaths(t, v); !slices.Equal(got, want) {
+			t.Errorf("Walk with %d workers = %v, want the order of filepath.Walk %v", workers, got, want)
+		}
+	}
+}
+
+func TestWalkTree_Symlinks(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, []string{"a/file.go", "other/x.go"})
+	for link, target := range map[string]string{
+		"a/loop":    "..",
+		"a/self":    ".",
+		"b":         "a",
+		"c":         filepath.Join(dir, "other"),
+		"dangling":  "missing",
+		"file-link": "a/file.go",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := &FileView{Dir: dir}
+	want := []string{"a/file.go", "a/loop", "a/self", "b", "c", "dangling", "file-link", "other/x.go"}
+	if got := walkPaths(t, v); !slices.Equal(got, want) {
+		t.Errorf("Walk() = %v, want the links as files %v", got, want)
+	}
+
+	v.FollowSymlinks = true
+	want = []string{"a/file.go", "b/file.go", "c/x.go", "dangling", "file-link", "other/x.go"}
+	if got := walkPaths(t, v); !slices.Equal(got, want) {
+		t.Errorf("Walk() following symlinks = %v, want %v", got, want)
+	}
+}
+
+func TestWalkTree_StopsOnError(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := range 50 {
+		files = append(files, fmt.Sprintf("d%02d/f.go", i))
+	}
+	writeTree(t, dir, files)
+
+	errStop := errors.New("stop")
+	var calls int
+	err := (&FileView{Dir: dir, Workers: 2}).Walk(func(f File) error {
+		calls++
+		if f.RelPath == filepath.Join("d02", "f.go") {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || calls != 3 {
+		t.Errorf("Walk() = %v after %d calls, want %v after 3", err, calls, errStop)
+	}
+}
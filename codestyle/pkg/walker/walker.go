@@ -16,47 +16,23 @@ package walker
 
 import (
 	"os"
-	"path/filepath"
 )
 
 // Filter is a function that returns true if the file should be included.
 type Filter func(path string, info os.FileInfo) bool
 
 // Walk walks the directory tree rooted at root and returns a list of files.
-// It skips paths matched by the ignore list.
+// It skips paths matched by the ignore list, and those ignored by .gitignore files.
 // If filter is provided, it only returns files for which filter returns true.
 func Walk(root string, ignore *IgnoreList, filter Filter) ([]string, error) {
 	var files []string
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, err := filepath.Rel(root, path)
-		if err != nil {
-			return err
-		}
-		if relPath == "." {
+	v := &FileView{Dir: root, Ignore: ignore, Gitignore: true}
+	err := v.Walk(func(f File) error {
+		if filter != nil && !filter(f.Path, f.Info) {
 			return nil
 		}
-
-		if ignore != nil && ignore.ShouldIgnore(relPath, info.IsDir()) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		if filter != nil && !filter(path, info) {
-			return nil
-		}
-
-		files = append(files, path)
+		files = append(files, f.Path)
 		return nil
 	})
 
@@ -40,6 +40,8 @@ func Run(ctx context.Context) error {
 	rootCmd.AddCommand(commands.BuildUpdateRepoCommand())
 	rootCmd.AddCommand(commands.BuildExportCommand())
 	rootCmd.AddCommand(commands.BuildApplyCommand())
+	rootCmd.AddCommand(commands.BuildDiffCommand())
+	rootCmd.AddCommand(commands.BuildAuditCommand())
 
 	return rootCmd.ExecuteContext(ctx)
 }
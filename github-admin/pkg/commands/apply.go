@@ -21,9 +21,9 @@ import (
 	"os"
 
 	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
 	"github.com/google/go-github/v81/github"
 	"github.com/spf13/cobra"
-	"golang.org/x/oauth2"
 	"sigs.k8s.io/yaml"
 )
 
@@ -31,6 +31,24 @@ type ApplyOptions struct {
 	ConfigPath  string
 	GitHubToken string
 	DryRun      bool
+
+	// StateFile records per-repo progress; defaults to <config>.state.json.
+	StateFile string
+	// Resume skips repos that were applied successfully by a previous run.
+	Resume bool
+
+	// PruneLabels deletes the labels of repos that configure labels, but not these.
+	PruneLabels bool
+
+	// PrunePermissions removes the access of the teams and direct collaborators of repos that configure
+	// permissions, but not these.
+	PrunePermissions bool
+
+	// PruneRulesets deletes the rulesets of repos that configure rulesets, but not these.
+	PruneRulesets bool
+
+	// Prune turns on all of the above.
+	Prune bool
 }
 
 func (o *ApplyOptions) InitDefaults() {
@@ -52,8 +70,15 @@ func BuildApplyCommand() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&opt.ConfigPath, "config", opt.ConfigPath, "Path to the config file")
+	cmd.MarkFlagRequired("config")
 	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
 	cmd.Flags().BoolVar(&opt.DryRun, "dry-run", opt.DryRun, "If true, do not make changes")
+	cmd.Flags().StringVar(&opt.StateFile, "state-file", opt.StateFile, "Path to the file recording per-repo progress (default <config>.state.json)")
+	cmd.Flags().BoolVar(&opt.Resume, "resume", opt.Resume, "Skip repos that were applied successfully by a previous run, according to the state file")
+	cmd.Flags().BoolVar(&opt.PruneLabels, "prune-labels", opt.PruneLabels, "Delete the labels that are not in the config, for the repos that configure labels")
+	cmd.Flags().BoolVar(&opt.PrunePermissions, "prune-permissions", opt.PrunePermissions, "Remove the teams and direct collaborators that are not in the config, for the repos that configure permissions")
+	cmd.Flags().BoolVar(&opt.PruneRulesets, "prune-rulesets", opt.PruneRulesets, "Delete the rulesets that are not in the config, for the repos that configure rulesets")
+	cmd.Flags().BoolVar(&opt.Prune, "prune", opt.Prune, "Shorthand for --prune-labels, --prune-permissions and --prune-rulesets")
 
 	return cmd
 }
@@ -62,11 +87,14 @@ func RunApply(ctx context.Context, opt ApplyOptions) error {
 	if opt.ConfigPath == "" {
 		return fmt.Errorf("--config is required")
 	}
-	if opt.GitHubToken == "" {
-		opt.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	if opt.Prune {
+		opt.PruneLabels = true
+		opt.PrunePermissions = true
+		opt.PruneRulesets = true
 	}
-	if opt.GitHubToken == "" {
-		return fmt.Errorf("--token or GITHUB_TOKEN env var is required")
+	client, err := githubclient.New(ctx, opt.GitHubToken)
+	if err != nil {
+		return err
 	}
 
 	configs, err := LoadConfigs(opt.ConfigPath)
@@ -74,17 +102,58 @@ func RunApply(ctx context.Context, opt ApplyOptions) error {
 		return err
 	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: opt.GitHubToken},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
+	stateFile := opt.StateFile
+	if stateFile == "" {
+		stateFile = opt.ConfigPath + ".state.json"
+	}
+	state := newApplyState()
+	if opt.Resume {
+		state, err = loadApplyState(stateFile)
+		if err != nil {
+			return err
+		}
+	}
 
+	// We continue past per-repo failures, so one bad repo does not block the rest of a large apply.
+	var results []repoResult
 	var errs []error
 	for _, cfg := range configs {
-		if err := applyRepo(ctx, client, cfg, opt.DryRun); err != nil {
-			errs = append(errs, fmt.Errorf("error applying config to %s/%s: %w", cfg.Owner, cfg.Name, err))
+		repo := fmt.Sprintf("%s/%s", cfg.Owner, cfg.Name)
+		if opt.Resume && state.IsDone(repo) {
+			fmt.Printf("Skipping %s, already applied\n", repo)
+			results = append(results, repoResult{Repo: repo, Status: repoStatusSkipped})
+			continue
+		}
+
+		err := defaultRetryPolicy.do(ctx, func() error {
+			return applyRepo(ctx, client, cfg, opt)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error applying config to %s: %w", repo, err))
+			results = append(results, repoResult{Repo: repo, Status: repoStatusFailed, Err: err})
+		} else {
+			results = append(results, repoResult{Repo: repo, Status: repoStatusDone})
 		}
+
+		// A dry run makes no changes, so there is no progress to record.
+		if !opt.DryRun {
+			state.Record(repo, err)
+			if err := state.save(stateFile); err != nil {
+				return err
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	fmt.Println()
+	if err := printSummary(os.Stdout, results); err != nil {
+		return err
+	}
+	if len(errs) > 0 && !opt.DryRun {
+		fmt.Printf("Re-run with --resume to retry only the failed repos.\n")
 	}
 
 	return errors.Join(errs...)
@@ -109,7 +178,8 @@ func LoadConfigs(path string) ([]config.RepositoryConfig, error) {
 	return configs, nil
 }
 
-func applyRepo(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool) error {
+func applyRepo(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, opt ApplyOptions) error {
+	dryRun := opt.DryRun
 	fmt.Printf("Applying config to %s/%s...\n", cfg.Owner, cfg.Name)
 
 	// Update Repo Settings
@@ -133,6 +203,9 @@ func applyRepo(ctx context.Context, client *github.Client, cfg config.Repository
 		repoReq.HasWiki = cfg.Settings.HasWiki
 		repoReq.HasDownloads = cfg.Settings.HasDownloads
 	}
+	if cfg.Discussions != nil {
+		repoReq.HasDiscussions = cfg.Discussions.Enabled
+	}
 
 	if !dryRun {
 		_, _, err := client.Repositories.Edit(ctx, cfg.Owner, cfg.Name, repoReq)
@@ -188,55 +261,49 @@ func applyRepo(ctx context.Context, client *github.Client, cfg config.Repository
 	}
 
 	// Apply Rulesets
-	if err := applyRulesets(ctx, client, cfg, dryRun); err != nil {
+	if err := applyRulesets(ctx, client, cfg, dryRun, opt.PruneRulesets); err != nil {
 		return fmt.Errorf("failed to apply rulesets: %w", err)
 	}
 
-	return nil
-}
+	if cfg.Permissions != nil {
+		if err := applyPermissions(ctx, client, cfg, dryRun, opt.PrunePermissions); err != nil {
+			return fmt.Errorf("failed to apply permissions: %w", err)
+		}
+	}
+	if cfg.Labels != nil {
+		if err := applyLabels(ctx, client, cfg, dryRun, opt.PruneLabels); err != nil {
+			return fmt.Errorf("failed to apply labels: %w", err)
+		}
+	}
+	if len(cfg.Milestones) > 0 {
+		if err := applyMilestones(ctx, client, cfg, dryRun); err != nil {
+			return fmt.Errorf("failed to apply milestones: %w", err)
+		}
+	}
+	if len(cfg.Webhooks) > 0 {
+		if err := applyWebhooks(ctx, client, cfg, dryRun); err != nil {
+			return fmt.Errorf("failed to apply webhooks: %w", err)
+		}
+	}
+	if len(cfg.RequiredApps) > 0 {
+		if err := applyRequiredApps(ctx, client, cfg, dryRun); err != nil {
+			return fmt.Errorf("failed to apply required apps: %w", err)
+		}
+	}
 
-func applyRulesets(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool) error {
-	// List existing rulesets to find IDs
-	existingRulesets, _, err := client.Repositories.GetAllRulesets(ctx, cfg.Owner, cfg.Name, nil)
-	if err != nil {
-		// If 404, it might mean the repo doesn't exist or feature not available.
-		// For now, assume error is real.
-		return fmt.Errorf("failed to list existing rulesets: %w", err)
-	}
-
-	existingMap := make(map[string]*github.RepositoryRuleset)
-	for _, rs := range existingRulesets {
-		existingMap[rs.Name] = rs
-	}
-
-	for _, rsConfig := range cfg.Rulesets {
-		rsReq := rulesetFromConfig(rsConfig)
-
-		if existing, ok := existingMap[rsConfig.Name]; ok {
-			// Update
-			if dryRun {
-				fmt.Printf("[DryRun] Would update ruleset %s for %s\n", rsConfig.Name, cfg.Name)
-			} else {
-				if existing.ID == nil {
-					return fmt.Errorf("existing ruleset %s has no ID", rsConfig.Name)
-				}
-				_, _, err := client.Repositories.UpdateRuleset(ctx, cfg.Owner, cfg.Name, *existing.ID, *rsReq)
-				if err != nil {
-					return fmt.Errorf("failed to update ruleset %s: %w", rsConfig.Name, err)
-				}
-			}
-		} else {
-			// Create
-			if dryRun {
-				fmt.Printf("[DryRun] Would create ruleset %s for %s\n", rsConfig.Name, cfg.Name)
-			} else {
-				_, _, err := client.Repositories.CreateRuleset(ctx, cfg.Owner, cfg.Name, *rsReq)
-				if err != nil {
-					return fmt.Errorf("failed to create ruleset %s: %w", rsConfig.Name, err)
-				}
-			}
+	// Community files are proposed in a pull request, so that they are reviewed like any other change.
+	if cfg.Community != nil {
+		if err := applyCommunityFiles(ctx, client, cfg, dryRun); err != nil {
+			return fmt.Errorf("failed to apply community files: %w", err)
 		}
 	}
+
+	if cfg.Discussions != nil && len(cfg.Discussions.Categories) > 0 && cfg.Discussions.Enabled != nil && *cfg.Discussions.Enabled {
+		if err := checkDiscussionCategories(ctx, client, cfg); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -263,19 +330,83 @@ func rulesetFromConfig(rs *config.RepositoryRuleset) *github.RepositoryRuleset {
 	}
 
 	if rs.Rules != nil {
-		res.Rules = &github.RepositoryRulesetRules{}
-		if rs.Rules.MergeQueue != nil {
-			mq := rs.Rules.MergeQueue
-			res.Rules.MergeQueue = &github.MergeQueueRuleParameters{
-				CheckResponseTimeoutMinutes:  mq.CheckResponseTimeoutMinutes,
-				GroupingStrategy:             github.MergeGroupingStrategy(mq.GroupingStrategy),
-				MaxEntriesToBuild:            mq.MaxEntriesToBuild,
-				MaxEntriesToMerge:            mq.MaxEntriesToMerge,
-				MergeMethod:                  github.MergeQueueMergeMethod(mq.MergeMethod),
-				MinEntriesToMerge:            mq.MinEntriesToMerge,
-				MinEntriesToMergeWaitMinutes: mq.MinEntriesToMergeWaitMinutes,
+		res.Rules = rulesFromConfig(rs.Rules)
+	}
+	return res
+}
+
+// defaultAllowedMergeMethods are the merge methods of the pull_request rule when the config does not list them.
+var defaultAllowedMergeMethods = []github.PullRequestMergeMethod{"merge", "squash", "rebase"}
+
+func rulesFromConfig(rules *config.RulesetRules) *github.RepositoryRulesetRules {
+	res := &github.RepositoryRulesetRules{}
+	if rules.Creation {
+		res.Creation = &github.EmptyRuleParameters{}
+	}
+	if rules.Update != nil {
+		res.Update = &github.UpdateRuleParameters{UpdateAllowsFetchAndMerge: rules.Update.UpdateAllowsFetchAndMerge}
+	}
+	if rules.Deletion {
+		res.Deletion = &github.EmptyRuleParameters{}
+	}
+	if rules.NonFastForward {
+		res.NonFastForward = &github.EmptyRuleParameters{}
+	}
+	if rules.RequiredLinearHistory {
+		res.RequiredLinearHistory = &github.EmptyRuleParameters{}
+	}
+	if rules.RequiredSignatures {
+		res.RequiredSignatures = &github.EmptyRuleParameters{}
+	}
+	if pr := rules.PullRequest; pr != nil {
+		res.PullRequest = &github.PullRequestRuleParameters{
+			AllowedMergeMethods:            defaultAllowedMergeMethods,
+			DismissStaleReviewsOnPush:      pr.DismissStaleReviewsOnPush,
+			RequireCodeOwnerReview:         pr.RequireCodeOwnerReview,
+			RequireLastPushApproval:        pr.RequireLastPushApproval,
+			RequiredApprovingReviewCount:   pr.RequiredApprovingReviewCount,
+			RequiredReviewThreadResolution: pr.RequiredReviewThreadResolution,
+		}
+		if len(pr.AllowedMergeMethods) > 0 {
+			res.PullRequest.AllowedMergeMethods = nil
+			for _, method := range pr.AllowedMergeMethods {
+				res.PullRequest.AllowedMergeMethods = append(res.PullRequest.AllowedMergeMethods, github.PullRequestMergeMethod(method))
 			}
 		}
 	}
+	if rsc := rules.RequiredStatusChecks; rsc != nil {
+		res.RequiredStatusChecks = &github.RequiredStatusChecksRuleParameters{
+			DoNotEnforceOnCreate:             github.Ptr(rsc.DoNotEnforceOnCreate),
+			RequiredStatusChecks:             []*github.RuleStatusCheck{},
+			StrictRequiredStatusChecksPolicy: rsc.Strict,
+		}
+		for _, check := range rsc.Checks {
+			res.RequiredStatusChecks.RequiredStatusChecks = append(res.RequiredStatusChecks.RequiredStatusChecks, &github.RuleStatusCheck{
+				Context:       check.Context,
+				IntegrationID: check.IntegrationID,
+			})
+		}
+	}
+	if p := rules.TagNamePattern; p != nil {
+		res.TagNamePattern = &github.PatternRuleParameters{
+			Negate:   github.Ptr(p.Negate),
+			Operator: github.PatternRuleOperator(p.Operator),
+			Pattern:  p.Pattern,
+		}
+		if p.Name != "" {
+			res.TagNamePattern.Name = github.Ptr(p.Name)
+		}
+	}
+	if mq := rules.MergeQueue; mq != nil {
+		res.MergeQueue = &github.MergeQueueRuleParameters{
+			CheckResponseTimeoutMinutes:  mq.CheckResponseTimeoutMinutes,
+			GroupingStrategy:             github.MergeGroupingStrategy(mq.GroupingStrategy),
+			MaxEntriesToBuild:            mq.MaxEntriesToBuild,
+			MaxEntriesToMerge:            mq.MaxEntriesToMerge,
+			MergeMethod:                  github.MergeQueueMergeMethod(mq.MergeMethod),
+			MinEntriesToMerge:            mq.MinEntriesToMerge,
+			MinEntriesToMergeWaitMinutes: mq.MinEntriesToMergeWaitMinutes,
+		}
+	}
 	return res
 }
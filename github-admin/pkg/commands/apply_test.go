@@ -89,6 +89,64 @@ func TestRulesetFromConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Ruleset with Branch Rules",
+			cfg: &config.RepositoryRuleset{
+				Name:        "main",
+				Enforcement: "active",
+				Rules: &config.RulesetRules{
+					Deletion:           true,
+					NonFastForward:     true,
+					RequiredSignatures: true,
+					PullRequest:        &config.PullRequestRule{RequiredApprovingReviewCount: 1},
+					RequiredStatusChecks: &config.RequiredStatusChecksRule{
+						Checks: []config.StatusCheck{{Context: "presubmit", IntegrationID: github.Ptr(int64(15368))}},
+						Strict: true,
+					},
+				},
+			},
+			want: &github.RepositoryRuleset{
+				Name:        "main",
+				Enforcement: "active",
+				Rules: &github.RepositoryRulesetRules{
+					Deletion:           &github.EmptyRuleParameters{},
+					NonFastForward:     &github.EmptyRuleParameters{},
+					RequiredSignatures: &github.EmptyRuleParameters{},
+					PullRequest: &github.PullRequestRuleParameters{
+						AllowedMergeMethods:          []github.PullRequestMergeMethod{"merge", "squash", "rebase"},
+						RequiredApprovingReviewCount: 1,
+					},
+					RequiredStatusChecks: &github.RequiredStatusChecksRuleParameters{
+						DoNotEnforceOnCreate:             github.Ptr(false),
+						RequiredStatusChecks:             []*github.RuleStatusCheck{{Context: "presubmit", IntegrationID: github.Ptr(int64(15368))}},
+						StrictRequiredStatusChecksPolicy: true,
+					},
+				},
+			},
+		},
+		{
+			name: "Tag Protection Ruleset",
+			cfg: &config.RepositoryRuleset{
+				Name:        "release-tags",
+				Target:      "tag",
+				Enforcement: "active",
+				Rules: &config.RulesetRules{
+					Creation:       true,
+					Update:         &config.UpdateRule{},
+					TagNamePattern: &config.PatternRule{Operator: "starts_with", Pattern: "v"},
+				},
+			},
+			want: &github.RepositoryRuleset{
+				Name:        "release-tags",
+				Target:      github.Ptr(github.RulesetTarget("tag")),
+				Enforcement: "active",
+				Rules: &github.RepositoryRulesetRules{
+					Creation:       &github.EmptyRuleParameters{},
+					Update:         &github.UpdateRuleParameters{},
+					TagNamePattern: &github.PatternRuleParameters{Negate: github.Ptr(false), Operator: "starts_with", Pattern: "v"},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
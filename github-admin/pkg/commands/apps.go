@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+// applyRequiredApps makes sure that the required GitHub Apps of the repository can access it, adding the
+// repository to the installations that are limited to selected repositories.
+func applyRequiredApps(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool) error {
+	installations, err := listInstallations(ctx, client, cfg.Owner)
+	if err != nil {
+		return err
+	}
+	bySlug := make(map[string]*github.Installation)
+	for _, inst := range installations {
+		bySlug[inst.GetAppSlug()] = inst
+	}
+
+	for _, slug := range cfg.RequiredApps {
+		inst, ok := bySlug[slug]
+		if !ok {
+			return fmt.Errorf("GitHub App %s is not installed in %s; install it from https://github.com/apps/%s", slug, cfg.Owner, slug)
+		}
+		ok, err := installationHasRepo(ctx, client, inst, cfg.Name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DryRun] Would add %s to the installation of GitHub App %s\n", cfg.Name, slug)
+			continue
+		}
+		repo, _, err := client.Repositories.Get(ctx, cfg.Owner, cfg.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get repo: %w", err)
+		}
+		if _, _, err := client.Apps.AddRepository(ctx, inst.GetID(), repo.GetID()); err != nil {
+			return fmt.Errorf("failed to add %s to the installation of GitHub App %s: %w", cfg.Name, slug, err)
+		}
+	}
+	return nil
+}
+
+// installationHasRepo reports whether the installation can access the repository of its account.
+func installationHasRepo(ctx context.Context, client *github.Client, inst *github.Installation, repo string) (bool, error) {
+	if inst.GetRepositorySelection() == "all" {
+		return true, nil
+	}
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		repos, resp, err := client.Apps.ListUserRepos(ctx, inst.GetID(), opt)
+		if err != nil {
+			return false, fmt.Errorf("failed to list the repositories of GitHub App %s: %w", inst.GetAppSlug(), err)
+		}
+		for _, r := range repos.Repositories {
+			if strings.EqualFold(r.GetName(), repo) {
+				return true, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// listInstallations returns the GitHub App installations of the organization.
+func listInstallations(ctx context.Context, client *github.Client, org string) ([]*github.Installation, error) {
+	var all []*github.Installation
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		installations, resp, err := client.Organizations.ListInstallations(ctx, org, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitHub App installations: %w", err)
+		}
+		all = append(all, installations.Installations...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// exportApps returns the slugs of the GitHub Apps installed on the repository. Repositories of users,
+// which have no organization installations, have none.
+func exportApps(ctx context.Context, client *github.Client, owner, repo string) ([]string, error) {
+	installations, err := listInstallations(ctx, client, owner)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var apps []string
+	for _, inst := range installations {
+		ok, err := installationHasRepo(ctx, client, inst, repo)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			apps = append(apps, inst.GetAppSlug())
+		}
+	}
+	return apps, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+)
+
+// fakeApps serves the GitHub App installations of organization example, recording the mutating requests.
+type fakeApps struct {
+	requests []string
+}
+
+func (f *fakeApps) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	switch key {
+	case "GET /orgs/example/installations":
+		json.NewEncoder(w).Encode(map[string]any{
+			"total_count": 3,
+			"installations": []map[string]any{
+				{"id": 1, "app_slug": "github-automation", "repository_selection": "selected"},
+				{"id": 2, "app_slug": "everywhere", "repository_selection": "all"},
+				{"id": 3, "app_slug": "elsewhere", "repository_selection": "selected"},
+			},
+		})
+	case "GET /user/installations/1/repositories":
+		json.NewEncoder(w).Encode(map[string]any{"total_count": 1, "repositories": []map[string]any{{"name": "other"}}})
+	case "GET /user/installations/3/repositories":
+		json.NewEncoder(w).Encode(map[string]any{"total_count": 1, "repositories": []map[string]any{{"name": "Repo"}}})
+	case "GET /repos/example/repo":
+		json.NewEncoder(w).Encode(map[string]any{"id": 42, "name": "repo"})
+	default:
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.requests = append(f.requests, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestApplyRequiredApps(t *testing.T) {
+	cfg := config.RepositoryConfig{Owner: "example", Name: "repo", RequiredApps: []string{"github-automation", "everywhere", "elsewhere"}}
+
+	dryRun := &fakeApps{}
+	if err := applyRequiredApps(t.Context(), newFakeClient(t, dryRun), cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(dryRun.requests) != 0 {
+		t.Errorf("dry run requests = %q, want none", dryRun.requests)
+	}
+
+	fake := &fakeApps{}
+	if err := applyRequiredApps(t.Context(), newFakeClient(t, fake), cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"PUT /user/installations/1/repositories/42"}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+}
+
+func TestApplyRequiredAppsNotInstalled(t *testing.T) {
+	cfg := config.RepositoryConfig{Owner: "example", Name: "repo", RequiredApps: []string{"missing"}}
+	if err := applyRequiredApps(t.Context(), newFakeClient(t, &fakeApps{}), cfg, false); err == nil {
+		t.Error("applyRequiredApps() succeeded, want an error for the app that is not installed")
+	}
+}
+
+func TestExportApps(t *testing.T) {
+	got, err := exportApps(t.Context(), newFakeClient(t, &fakeApps{}), "example", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"everywhere", "elsewhere"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("exportApps() = %q, want %q", got, want)
+	}
+
+	// Users have no organization installations.
+	got, err = exportApps(t.Context(), newFakeClient(t, &fakeApps{}), "someone", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("exportApps() for a user = %q, want none", got)
+	}
+}
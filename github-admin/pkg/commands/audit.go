@@ -0,0 +1,271 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/google/go-github/v81/github"
+	"github.com/spf13/cobra"
+)
+
+type AuditExportOptions struct {
+	Org         string
+	Since       string
+	GitHubToken string
+	Output      string
+	Format      string
+	// Include selects the event types: web, git or all.
+	Include string
+	// Actions and Actors filter the exported events; an action also matches the actions in its category
+	// (e.g. "repo" matches "repo.create").
+	Actions []string
+	Actors  []string
+}
+
+func (o *AuditExportOptions) InitDefaults() {
+	o.Since = "30d"
+	o.Output = "-" // stdout
+	o.Format = "jsonl"
+	o.Include = "web"
+}
+
+func BuildAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Work with the organization audit log",
+	}
+	cmd.AddCommand(BuildAuditExportCommand())
+	return cmd
+}
+
+func BuildAuditExportCommand() *cobra.Command {
+	var opt AuditExportOptions
+	opt.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the organization audit log as newline-delimited JSON or CSV",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command does not take positional arguments")
+			}
+			return RunAuditExport(cmd.Context(), opt)
+		},
+	}
+	cmd.Flags().StringVar(&opt.Org, "org", opt.Org, "The github organization")
+	cmd.MarkFlagRequired("org")
+	cmd.Flags().StringVar(&opt.Since, "since", opt.Since, "Export events since this time: a duration (e.g. 30d, 12h) or a date (e.g. 2026-01-01)")
+	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&opt.Output, "output", opt.Output, "Output file path (default is stdout)")
+	cmd.Flags().StringVar(&opt.Format, "format", opt.Format, "Output format: jsonl or csv")
+	cmd.Flags().StringVar(&opt.Include, "include", opt.Include, "Event types to export: web, git or all")
+	cmd.Flags().StringSliceVar(&opt.Actions, "action", opt.Actions, "Only export these actions, or actions in these categories (e.g. repo.create, org); can be repeated")
+	cmd.Flags().StringSliceVar(&opt.Actors, "actor", opt.Actors, "Only export events by these actors; can be repeated")
+
+	return cmd
+}
+
+func RunAuditExport(ctx context.Context, opt AuditExportOptions) error {
+	if opt.Org == "" {
+		return fmt.Errorf("--org is required")
+	}
+	if opt.Format != "jsonl" && opt.Format != "csv" {
+		return fmt.Errorf("unknown --format %q, must be jsonl or csv", opt.Format)
+	}
+	since, err := parseSince(opt.Since, time.Now())
+	if err != nil {
+		return err
+	}
+	client, err := githubclient.New(ctx, opt.GitHubToken)
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if opt.Output != "-" {
+		f, err := os.Create(opt.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	n, err := exportAuditLog(ctx, client, opt, since, out)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d audit log events for %s\n", n, opt.Org)
+	return nil
+}
+
+// exportAuditLog writes the audit log events of opt.Org since the given time to out, oldest first,
+// returning the number of events written.
+func exportAuditLog(ctx context.Context, client *github.Client, opt AuditExportOptions, since time.Time, out io.Writer) (int, error) {
+	w, err := newAuditWriter(opt.Format, out)
+	if err != nil {
+		return 0, err
+	}
+
+	// The search phrase narrows the query to the day; the exact time and the filters are applied here.
+	listOpts := &github.GetAuditLogOptions{
+		Phrase:            github.Ptr("created:>=" + since.UTC().Format(time.DateOnly)),
+		Include:           github.Ptr(opt.Include),
+		Order:             github.Ptr("asc"),
+		ListCursorOptions: github.ListCursorOptions{PerPage: 100},
+	}
+	n := 0
+	for {
+		var entries []*github.AuditEntry
+		var resp *github.Response
+		err := defaultRetryPolicy.do(ctx, func() error {
+			var err error
+			entries, resp, err = client.Organizations.GetAuditLog(ctx, opt.Org, listOpts)
+			return err
+		})
+		if err != nil {
+			return n, fmt.Errorf("failed to get audit log for org %s: %w", opt.Org, err)
+		}
+
+		for _, entry := range entries {
+			if entryTime(entry).Before(since) || !matchesAuditFilters(entry, opt.Actions, opt.Actors) {
+				continue
+			}
+			if err := w.write(entry); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		if resp.After == "" {
+			break
+		}
+		listOpts.After = resp.After
+	}
+	return n, w.flush()
+}
+
+// parseSince parses a duration before now (e.g. 30d, 12h), a date or an RFC 3339 time.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, must be a duration (e.g. 30d, 12h), a date (e.g. 2026-01-01) or an RFC 3339 time", s)
+}
+
+// entryTime returns when the event occurred.
+func entryTime(entry *github.AuditEntry) time.Time {
+	if entry.Timestamp != nil {
+		return entry.Timestamp.Time
+	}
+	return entry.GetCreatedAt().Time
+}
+
+func matchesAuditFilters(entry *github.AuditEntry, actions []string, actors []string) bool {
+	if len(actors) > 0 && !slices.Contains(actors, entry.GetActor()) {
+		return false
+	}
+	if len(actions) == 0 {
+		return true
+	}
+	action := entry.GetAction()
+	for _, a := range actions {
+		if action == a || strings.HasPrefix(action, a+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// auditCSVHeader are the columns of the CSV export; the full events are only in the JSON export.
+var auditCSVHeader = []string{"time", "action", "actor", "user", "repo", "country", "document_id"}
+
+// auditWriter writes audit log events in an export format.
+type auditWriter struct {
+	json *json.Encoder
+	csv  *csv.Writer
+}
+
+func newAuditWriter(format string, out io.Writer) (*auditWriter, error) {
+	if format == "jsonl" {
+		return &auditWriter{json: json.NewEncoder(out)}, nil
+	}
+	w := csv.NewWriter(out)
+	if err := w.Write(auditCSVHeader); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return &auditWriter{csv: w}, nil
+}
+
+func (w *auditWriter) write(entry *github.AuditEntry) error {
+	if w.json != nil {
+		if err := w.json.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write audit log event: %w", err)
+		}
+		return nil
+	}
+
+	repo, _ := entry.AdditionalFields["repo"].(string)
+	var country string
+	if entry.ActorLocation != nil {
+		country = entry.ActorLocation.GetCountryCode()
+	}
+	record := []string{
+		entryTime(entry).UTC().Format(time.RFC3339),
+		entry.GetAction(),
+		entry.GetActor(),
+		entry.GetUser(),
+		repo,
+		country,
+		entry.GetDocumentID(),
+	}
+	if err := w.csv.Write(record); err != nil {
+		return fmt.Errorf("failed to write audit log event: %w", err)
+	}
+	return nil
+}
+
+func (w *auditWriter) flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return fmt.Errorf("failed to write audit log events: %w", err)
+	}
+	return nil
+}
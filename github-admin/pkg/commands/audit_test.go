@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAuditLog serves two pages of the audit log of org example.
+type fakeAuditLog struct {
+	// phrases records the search phrase of each request.
+	phrases []string
+}
+
+func (f *fakeAuditLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/orgs/example/audit-log" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	f.phrases = append(f.phrases, r.URL.Query().Get("phrase"))
+
+	var page []map[string]any
+	if r.URL.Query().Get("after") == "" {
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/orgs/example/audit-log?after=cursor1>; rel="next"`, r.Host))
+		page = []map[string]any{
+			{"@timestamp": 1767139200000, "action": "repo.create", "actor": "alice", "repo": "example/old"}, // 2025-12-31
+			{"@timestamp": 1767312000000, "action": "repo.create", "actor": "alice", "repo": "example/new"}, // 2026-01-02
+			{"@timestamp": 1767312060000, "action": "org.add_member", "actor": "bob", "user": "carol"},
+		}
+	} else {
+		page = []map[string]any{
+			{"@timestamp": 1767398400000, "action": "repo.destroy", "actor": "bob", "repo": "example/new", "actor_location": map[string]any{"country_code": "US"}},
+			{"@timestamp": 1767398460000, "action": "repository_ruleset.create", "actor": "alice"},
+		}
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestExportAuditLog(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	fake := &fakeAuditLog{}
+	client := newFakeClient(t, fake)
+
+	var out bytes.Buffer
+	opt := AuditExportOptions{Org: "example", Format: "jsonl", Include: "web", Actions: []string{"repo"}}
+	n, err := exportAuditLog(t.Context(), client, opt, since, &out)
+	if err != nil {
+		t.Fatalf("exportAuditLog failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("exported %d events, want 2", n)
+	}
+	if want := []string{"created:>=2026-01-01", "created:>=2026-01-01"}; strings.Join(fake.phrases, ",") != strings.Join(want, ",") {
+		t.Errorf("phrases = %v, want %v", fake.phrases, want)
+	}
+
+	// The event before --since and the actions of other categories are left out.
+	var repos []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		repos = append(repos, fmt.Sprintf("%v %v", event["action"], event["repo"]))
+	}
+	if got, want := strings.Join(repos, ","), "repo.create example/new,repo.destroy example/new"; got != want {
+		t.Errorf("exported events = %v, want %v", got, want)
+	}
+
+	out.Reset()
+	opt = AuditExportOptions{Org: "example", Format: "csv", Include: "web", Actors: []string{"bob"}}
+	if _, err := exportAuditLog(t.Context(), client, opt, since, &out); err != nil {
+		t.Fatalf("exportAuditLog failed: %v", err)
+	}
+	want := `time,action,actor,user,repo,country,document_id
+2026-01-02T00:01:00Z,org.add_member,bob,carol,,,
+2026-01-03T00:00:00Z,repo.destroy,bob,,example/new,US,
+`
+	if out.String() != want {
+		t.Errorf("CSV export = %q, want %q", out.String(), want)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since   string
+		want    time.Time
+		wantErr bool
+	}{
+		{since: "30d", want: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{since: "12h", want: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{since: "2026-01-01", want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{since: "2026-01-01T08:00:00Z", want: time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)},
+		{since: "last month", wantErr: true},
+		{since: "-3d", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			got, err := parseSince(tt.since, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
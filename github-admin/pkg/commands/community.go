@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/google/go-github/v81/github"
+)
+
+// defaultCommunityBranch is the branch community file changes are pushed to, if not configured.
+const defaultCommunityBranch = "github-admin/community"
+
+// communityFiles returns the desired content of the community files of cfg, keyed by path in the repository.
+func communityFiles(cfg *config.CommunityFiles) map[string]string {
+	files := make(map[string]string)
+	for name, content := range cfg.IssueTemplates {
+		files[path.Join(".github", "ISSUE_TEMPLATE", name)] = content
+	}
+	if cfg.PullRequestTemplate != nil {
+		files[".github/pull_request_template.md"] = *cfg.PullRequestTemplate
+	}
+	return files
+}
+
+// applyCommunityFiles proposes the configured community files in a pull request against the default branch.
+// Only files whose content differs from the default branch are included; if none do, nothing is done.
+func applyCommunityFiles(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool) error {
+	branch := cfg.Community.Branch
+	if branch == "" {
+		branch = defaultCommunityBranch
+	}
+	result, err := githubclient.Propose(ctx, client, githubclient.Proposal{
+		Owner:  cfg.Owner,
+		Repo:   cfg.Name,
+		Branch: branch,
+		Files:  communityFiles(cfg.Community),
+		Title:  "Update issue and pull request templates",
+		Body:   "github-admin updates the contribution templates to match the org configuration:",
+		DryRun: dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(result.Changes) == 0:
+		fmt.Printf("Community files of %s are up to date\n", cfg.Name)
+	case dryRun:
+		fmt.Printf("[DryRun] Would open a pull request from %s updating %s for %s\n", branch, strings.Join(result.Paths(), ", "), cfg.Name)
+	case result.Updated:
+		fmt.Printf("Updated pull request %s for %s\n", result.URL, cfg.Name)
+	default:
+		fmt.Printf("Opened pull request %s for %s\n", result.URL, cfg.Name)
+	}
+	return nil
+}
+
+// checkDiscussionCategories reports the configured discussion categories the repository does not have.
+// Categories can only be created in the GitHub UI, so they are not created here.
+func checkDiscussionCategories(ctx context.Context, client *github.Client, cfg config.RepositoryConfig) error {
+	existing, err := listDiscussionCategories(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, category := range cfg.Discussions.Categories {
+		if !existing[category.Name] {
+			missing = append(missing, category.Name)
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("Warning: %s is missing discussion categories %s; create them in the repository's Discussions settings\n", cfg.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// listDiscussionCategories returns the names of the discussion categories of a repository.
+// The REST API does not expose them, so they are queried with GraphQL.
+func listDiscussionCategories(ctx context.Context, client *github.Client, owner, repo string) (map[string]bool, error) {
+	query := map[string]any{
+		"query": `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) { discussionCategories(first: 100) { nodes { name } } }
+}`,
+		"variables": map[string]string{"owner": owner, "name": repo},
+	}
+	req, err := client.NewRequest(http.MethodPost, "graphql", query)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Data struct {
+			Repository struct {
+				DiscussionCategories struct {
+					Nodes []struct {
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"discussionCategories"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := client.Do(ctx, req, &result); err != nil {
+		return nil, fmt.Errorf("failed to list discussion categories: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("failed to list discussion categories: %s", result.Errors[0].Message)
+	}
+
+	names := make(map[string]bool)
+	for _, node := range result.Data.Repository.DiscussionCategories.Nodes {
+		names[node.Name] = true
+	}
+	return names, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+// fakeGitHub serves the API calls made by applyCommunityFiles for repo example/repo,
+// whose default branch has .github/ISSUE_TEMPLATE/bug.md and no pull request template.
+type fakeGitHub struct {
+	// requests records the mutating requests, as "METHOD path".
+	requests []string
+	// bodies records the decoded bodies of the mutating requests, by "METHOD path".
+	bodies map[string]map[string]any
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	if r.Method != http.MethodGet {
+		f.requests = append(f.requests, key)
+		data, _ := io.ReadAll(r.Body)
+		body := map[string]any{}
+		_ = json.Unmarshal(data, &body)
+		f.bodies[key] = body
+	}
+
+	switch key {
+	case "GET /repos/example/repo":
+		json.NewEncoder(w).Encode(map[string]any{"default_branch": "main"})
+	case "GET /repos/example/repo/contents/.github/ISSUE_TEMPLATE/bug.md":
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte("Describe the bug\n")),
+			"sha":      "bug-sha",
+		})
+	case "GET /repos/example/repo/git/ref/heads/main":
+		json.NewEncoder(w).Encode(map[string]any{"ref": "refs/heads/main", "object": map[string]any{"sha": "base-sha"}})
+	case "POST /repos/example/repo/git/refs":
+		json.NewEncoder(w).Encode(map[string]any{"ref": "refs/heads/github-admin/community"})
+	case "PUT /repos/example/repo/contents/.github/pull_request_template.md":
+		json.NewEncoder(w).Encode(map[string]any{})
+	case "GET /repos/example/repo/pulls":
+		json.NewEncoder(w).Encode([]any{})
+	case "POST /repos/example/repo/pulls":
+		json.NewEncoder(w).Encode(map[string]any{"html_url": "https://github.com/example/repo/pull/1"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Not Found"})
+	}
+}
+
+func newFakeClient(t *testing.T, handler http.Handler) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = baseURL
+	return client
+}
+
+func TestApplyCommunityFiles(t *testing.T) {
+	fake := &fakeGitHub{bodies: map[string]map[string]any{}}
+	client := newFakeClient(t, fake)
+
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Community: &config.CommunityFiles{
+			IssueTemplates:      map[string]string{"bug.md": "Describe the bug\n"},
+			PullRequestTemplate: github.Ptr("## Summary\n"),
+		},
+	}
+	if err := applyCommunityFiles(t.Context(), client, cfg, false); err != nil {
+		t.Fatalf("applyCommunityFiles failed: %v", err)
+	}
+
+	// The unchanged issue template is not rewritten, and nothing is pushed to main.
+	want := []string{
+		"POST /repos/example/repo/git/refs",
+		"PUT /repos/example/repo/contents/.github/pull_request_template.md",
+		"POST /repos/example/repo/pulls",
+	}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+	if got := fake.bodies["POST /repos/example/repo/git/refs"]["ref"]; got != "refs/heads/github-admin/community" {
+		t.Errorf("created ref %v, want refs/heads/github-admin/community", got)
+	}
+	put := fake.bodies["PUT /repos/example/repo/contents/.github/pull_request_template.md"]
+	if put["branch"] != "github-admin/community" || put["content"] != base64.StdEncoding.EncodeToString([]byte("## Summary\n")) {
+		t.Errorf("file update = %v, want the template on the community branch", put)
+	}
+	if got := fake.bodies["POST /repos/example/repo/pulls"]["base"]; got != "main" {
+		t.Errorf("pull request base = %v, want main", got)
+	}
+}
+
+func TestApplyCommunityFilesUpToDate(t *testing.T) {
+	fake := &fakeGitHub{bodies: map[string]map[string]any{}}
+	client := newFakeClient(t, fake)
+
+	cfg := config.RepositoryConfig{
+		Owner:     "example",
+		Name:      "repo",
+		Community: &config.CommunityFiles{IssueTemplates: map[string]string{"bug.md": "Describe the bug\n"}},
+	}
+	if err := applyCommunityFiles(t.Context(), client, cfg, false); err != nil {
+		t.Fatalf("applyCommunityFiles failed: %v", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("requests = %v, want none", fake.requests)
+	}
+}
+
+func TestCommunityFiles(t *testing.T) {
+	files := communityFiles(&config.CommunityFiles{
+		IssueTemplates:      map[string]string{"config.yml": "blank_issues_enabled: false\n", "bug.md": "bug"},
+		PullRequestTemplate: github.Ptr("pr"),
+	})
+	var got []string
+	for p := range files {
+		got = append(got, p)
+	}
+	sort.Strings(got)
+	want := []string{".github/ISSUE_TEMPLATE/bug.md", ".github/ISSUE_TEMPLATE/config.yml", ".github/pull_request_template.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("communityFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,416 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/google/go-github/v81/github"
+	"github.com/spf13/cobra"
+)
+
+type DiffOptions struct {
+	ConfigPath  string
+	GitHubToken string
+	// Color is auto, always or never; auto colors the output when it is a terminal.
+	Color string
+
+	// PruneLabels, PrunePermissions, PruneRulesets and Prune are the flags of apply, which decide whether
+	// the live labels, permissions and rulesets that are not configured are differences.
+	PruneLabels      bool
+	PrunePermissions bool
+	PruneRulesets    bool
+	Prune            bool
+}
+
+func (o *DiffOptions) InitDefaults() {
+	o.Color = "auto"
+}
+
+func BuildDiffCommand() *cobra.Command {
+	var opt DiffOptions
+	opt.InitDefaults()
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show the differences between github repo configurations and the live repos",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command does not take positional arguments")
+			}
+			return RunDiff(cmd.Context(), opt)
+		},
+	}
+	cmd.Flags().StringVar(&opt.ConfigPath, "config", opt.ConfigPath, "Path to the config file")
+	cmd.MarkFlagRequired("config")
+	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&opt.Color, "color", opt.Color, "Color the output: auto, always or never")
+	cmd.Flags().BoolVar(&opt.PruneLabels, "prune-labels", opt.PruneLabels, "Show the labels that apply --prune-labels would delete")
+	cmd.Flags().BoolVar(&opt.PrunePermissions, "prune-permissions", opt.PrunePermissions, "Show the teams and direct collaborators that apply --prune-permissions would remove")
+	cmd.Flags().BoolVar(&opt.PruneRulesets, "prune-rulesets", opt.PruneRulesets, "Show the rulesets that apply --prune-rulesets would delete")
+	cmd.Flags().BoolVar(&opt.Prune, "prune", opt.Prune, "Shorthand for --prune-labels, --prune-permissions and --prune-rulesets")
+
+	return cmd
+}
+
+func RunDiff(ctx context.Context, opt DiffOptions) error {
+	if opt.ConfigPath == "" {
+		return fmt.Errorf("--config is required")
+	}
+	var color bool
+	switch opt.Color {
+	case "always":
+		color = true
+	case "never":
+	case "auto":
+		color = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	default:
+		return fmt.Errorf("unknown --color %q, must be auto, always or never", opt.Color)
+	}
+	if opt.Prune {
+		opt.PruneLabels = true
+		opt.PrunePermissions = true
+		opt.PruneRulesets = true
+	}
+
+	client, err := githubclient.New(ctx, opt.GitHubToken)
+	if err != nil {
+		return err
+	}
+	configs, err := LoadConfigs(opt.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	differ := 0
+	for _, cfg := range configs {
+		var live *config.RepositoryConfig
+		err := defaultRetryPolicy.do(ctx, func() error {
+			repo, _, err := client.Repositories.Get(ctx, cfg.Owner, cfg.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get repo: %w", err)
+			}
+			live, err = exportRepo(ctx, client, repo)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching %s/%s: %w", cfg.Owner, cfg.Name, err)
+		}
+
+		diffs := diffRepo(cfg, *live, opt)
+		if len(diffs) > 0 {
+			differ++
+		}
+		if err := printDiff(os.Stdout, cfg.Owner+"/"+cfg.Name, diffs, color); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\n%d of %d repos differ from the config\n", differ, len(configs))
+	return nil
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// fieldDiff is a field that differs between the config of a repository and its live state.
+// Want is nil for a field that is only live, Got for a field that is only configured.
+type fieldDiff struct {
+	// Path is the YAML path of the field, e.g. settings.allowAutoMerge or labels[name=bug].color.
+	// List items are addressed by their name, title or url.
+	Path string
+	Want any
+	Got  any
+}
+
+// undiffedFields are the paths, without list item selectors, of the configured fields that cannot be read back
+// from GitHub.
+var undiffedFields = []string{
+	"owner",
+	"name",
+	"community",
+	"discussions.categories",
+	"rulesets.previousNames",
+}
+
+// caseInsensitiveFields are the paths of the lists and maps whose items GitHub matches ignoring case.
+var caseInsensitiveFields = []string{
+	"labels",
+	"permissions.teams",
+	"permissions.users",
+}
+
+// selectorPattern matches the list item selectors of a path, e.g. [name=bug].
+var selectorPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// differ compares the generic forms of the config and the live state of a repository.
+type differ struct {
+	// removable reports whether apply removes the live item with the given key from the list or map at path
+	// when it is not configured. Other live items are not differences, as apply leaves them alone.
+	removable func(path string, key string) bool
+	diffs     []fieldDiff
+}
+
+// diffRepo returns the fields of the config desired that differ from the live state of the repository.
+// Fields that are not configured are not managed, so only the configured fields are compared, and the
+// live items of configured lists and maps only if apply, with the prune flags of opt, would remove them.
+func diffRepo(desired, live config.RepositoryConfig, opt DiffOptions) []fieldDiff {
+	normalizeForDiff(&desired)
+	normalizeForDiff(&live)
+
+	// Rulesets that were renamed are deleted even without --prune-rulesets.
+	superseded := make(map[string]bool)
+	for _, rs := range desired.Rulesets {
+		for _, name := range rs.PreviousNames {
+			superseded[name] = true
+		}
+	}
+	d := &differ{removable: func(path string, key string) bool {
+		switch path {
+		case "labels":
+			return opt.PruneLabels
+		case "permissions.teams", "permissions.users":
+			return opt.PrunePermissions
+		case "rulesets":
+			return opt.PruneRulesets || superseded[key]
+		}
+		return false
+	}}
+	d.diffValues("", toGeneric(desired), toGeneric(live))
+	return d.diffs
+}
+
+// normalizeForDiff rewrites the values of cfg that GitHub accepts in several forms, or that have defaults,
+// to one form.
+func normalizeForDiff(cfg *config.RepositoryConfig) {
+	cfg.Labels = slices.Clone(cfg.Labels)
+	for i := range cfg.Labels {
+		cfg.Labels[i].Color = strings.ToLower(labelColor(cfg.Labels[i].Color))
+	}
+	cfg.Webhooks = slices.Clone(cfg.Webhooks)
+	for i := range cfg.Webhooks {
+		hook := &cfg.Webhooks[i]
+		// Secrets cannot be read back; the other fields are compared with their defaults.
+		hook.SecretEnv = ""
+		hook.Events = sortedStrings(webhookEvents(*hook))
+		hook.ContentType = webhookContentType(*hook)
+		hook.Active = github.Ptr(webhookActive(*hook))
+	}
+	if cfg.Permissions != nil {
+		// Unset maps are not managed, so they stay unset.
+		perms := &config.PermissionsConfig{}
+		if cfg.Permissions.Teams != nil {
+			perms.Teams = make(map[string]string)
+			for team, role := range cfg.Permissions.Teams {
+				perms.Teams[team] = normalizeRole(role)
+			}
+		}
+		if cfg.Permissions.Users != nil {
+			perms.Users = make(map[string]string)
+			for user, role := range cfg.Permissions.Users {
+				perms.Users[user] = normalizeRole(role)
+			}
+		}
+		cfg.Permissions = perms
+	}
+}
+
+// toGeneric returns v as the maps, lists and scalars of its JSON form, which is also its YAML form.
+func toGeneric(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal %T: %v", v, err))
+	}
+	var res any
+	if err := json.Unmarshal(data, &res); err != nil {
+		panic(fmt.Sprintf("failed to unmarshal %T: %v", v, err))
+	}
+	return res
+}
+
+// fieldPath returns path without its list item selectors, as in undiffedFields.
+func fieldPath(path string) string {
+	return selectorPattern.ReplaceAllString(path, "")
+}
+
+func (d *differ) diffValues(path string, want, got any) {
+	if slices.Contains(undiffedFields, fieldPath(path)) {
+		return
+	}
+	switch want := want.(type) {
+	case map[string]any:
+		if got, ok := got.(map[string]any); ok {
+			d.diffMaps(path, want, got)
+			return
+		}
+	case []any:
+		if got, ok := got.([]any); ok && listKey(want) != "" {
+			d.diffLists(path, want, got)
+			return
+		}
+	}
+	if !reflect.DeepEqual(want, got) {
+		d.diffs = append(d.diffs, fieldDiff{Path: path, Want: want, Got: got})
+	}
+}
+
+func (d *differ) diffMaps(path string, want, got map[string]any) {
+	fold := slices.Contains(caseInsensitiveFields, fieldPath(path))
+	matched := make(map[string]bool)
+	for _, k := range sortedKeys(want) {
+		liveKey := k
+		if _, ok := got[k]; !ok && fold {
+			for _, g := range sortedKeys(got) {
+				if strings.EqualFold(g, k) {
+					liveKey = g
+					break
+				}
+			}
+		}
+		matched[liveKey] = true
+		d.diffValues(joinPath(path, k), want[k], got[liveKey])
+	}
+	for _, k := range sortedKeys(got) {
+		if !matched[k] && d.removable(fieldPath(path), k) {
+			d.diffs = append(d.diffs, fieldDiff{Path: joinPath(path, k), Got: got[k]})
+		}
+	}
+}
+
+func (d *differ) diffLists(path string, want, got []any) {
+	key := listKey(want)
+	id := func(item map[string]any) string {
+		s := fmt.Sprint(item[key])
+		if slices.Contains(caseInsensitiveFields, fieldPath(path)) {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	byID := make(map[string]map[string]any)
+	for _, item := range got {
+		if m, ok := item.(map[string]any); ok {
+			byID[id(m)] = m
+		}
+	}
+	configured := make(map[string]bool)
+	for _, item := range want {
+		m := item.(map[string]any)
+		configured[id(m)] = true
+		var live any
+		if l, ok := byID[id(m)]; ok {
+			live = l
+		}
+		d.diffValues(fmt.Sprintf("%s[%s=%v]", path, key, m[key]), item, live)
+	}
+	for _, item := range got {
+		m, ok := item.(map[string]any)
+		if !ok || configured[id(m)] || !d.removable(fieldPath(path), fmt.Sprint(m[key])) {
+			continue
+		}
+		d.diffs = append(d.diffs, fieldDiff{Path: fmt.Sprintf("%s[%s=%v]", path, key, m[key]), Got: item})
+	}
+}
+
+// listKey returns the field that identifies the items of the list, or "" if they are not objects with one.
+func listKey(list []any) string {
+	for _, key := range []string{"name", "title", "url"} {
+		ok := len(list) > 0
+		for _, item := range list {
+			m, isMap := item.(map[string]any)
+			if !isMap || m[key] == nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return key
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// printDiff writes the differences of the repository to w: "+" for a field that apply would add,
+// "~" for one it would change from the live value, and "-" for one that is only live.
+func printDiff(w io.Writer, repo string, diffs []fieldDiff, color bool) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintf(w, "%s: no differences\n", repo)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s:\n", repo); err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		var line, c string
+		switch {
+		case d.Got == nil:
+			line, c = fmt.Sprintf("+ %s: %s", d.Path, formatValue(d.Want)), colorGreen
+		case d.Want == nil:
+			line, c = fmt.Sprintf("- %s: %s", d.Path, formatValue(d.Got)), colorRed
+		default:
+			line, c = fmt.Sprintf("~ %s: %s -> %s", d.Path, formatValue(d.Got), formatValue(d.Want)), colorYellow
+		}
+		if color {
+			line = c + line + colorReset
+		}
+		if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatValue returns v in YAML flow style, which JSON is a subset of.
+func formatValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+func TestDiffRepo(t *testing.T) {
+	desired := config.RepositoryConfig{
+		Owner:       "example",
+		Name:        "repo",
+		Description: github.Ptr("An example"),
+		Settings:    &config.RepositorySettings{AllowAutoMerge: github.Ptr(true)},
+		Labels: []config.Label{
+			// Colors are compared as GitHub returns them.
+			{Name: "bug", Color: "#D73A4A"},
+			{Name: "kind/cleanup", Color: "c5def5"},
+		},
+		Permissions: &config.PermissionsConfig{Teams: map[string]string{"maintainers": "push"}},
+		Webhooks:    []config.Webhook{{URL: "https://bot.example.com/hook", SecretEnv: "BOT_WEBHOOK_SECRET"}},
+		Community:   &config.CommunityFiles{},
+	}
+	live := config.RepositoryConfig{
+		Owner:       "example",
+		Name:        "repo",
+		Description: github.Ptr("An example"),
+		Homepage:    github.Ptr("https://example.com"),
+		Settings:    &config.RepositorySettings{AllowAutoMerge: github.Ptr(false), HasWiki: github.Ptr(true)},
+		Labels: []config.Label{
+			{Name: "bug", Color: "d73a4a"},
+			{Name: "wontfix", Color: "ffffff"},
+		},
+		Permissions: &config.PermissionsConfig{Teams: map[string]string{"maintainers": "write", "old-team": "read"}},
+		Webhooks:    []config.Webhook{{URL: "https://bot.example.com/hook", Events: []string{"push"}, ContentType: "form"}},
+	}
+
+	for _, tc := range []struct {
+		name string
+		opt  DiffOptions
+		want string
+	}{
+		{
+			name: "keep",
+			want: `example/repo:
+  + labels[name=kind/cleanup]: {"color":"c5def5","name":"kind/cleanup"}
+  ~ settings.allowAutoMerge: false -> true
+  ~ webhooks[url=https://bot.example.com/hook].contentType: "form" -> "json"
+`,
+		},
+		{
+			name: "prune",
+			opt:  DiffOptions{PruneLabels: true, PrunePermissions: true},
+			want: `example/repo:
+  + labels[name=kind/cleanup]: {"color":"c5def5","name":"kind/cleanup"}
+  - labels[name=wontfix]: {"color":"ffffff","name":"wontfix"}
+  - permissions.teams.old-team: "read"
+  ~ settings.allowAutoMerge: false -> true
+  ~ webhooks[url=https://bot.example.com/hook].contentType: "form" -> "json"
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printDiff(&buf, "example/repo", diffRepo(desired, live, tc.opt), false); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("diff =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDiffRepoConverged(t *testing.T) {
+	// desired is what apply converged the repo to; live is as export reads it back.
+	desired := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Labels: []config.Label{
+			{Name: "bug", Color: "#D73A4A"},
+		},
+		Milestones: []config.Milestone{{Title: "v1"}},
+		Webhooks: []config.Webhook{{
+			URL:       "https://bot.example.com/hook",
+			Events:    []string{"push", "pull_request"},
+			SecretEnv: "BOT_WEBHOOK_SECRET",
+			Active:    github.Ptr(true),
+		}},
+		Permissions: &config.PermissionsConfig{Teams: map[string]string{"maintainers": "push"}},
+		Rulesets: []*config.RepositoryRuleset{{
+			Name:          "merge-queue",
+			PreviousNames: []string{"queue"},
+			Enforcement:   "active",
+			Rules:         &config.RulesetRules{Deletion: true},
+		}},
+	}
+	live := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Labels: []config.Label{
+			{Name: "bug", Color: "d73a4a"},
+			{Name: "wontfix", Color: "ffffff"},
+		},
+		Milestones: []config.Milestone{{Title: "v0", State: "closed"}, {Title: "v1", State: "open"}},
+		Webhooks: []config.Webhook{
+			{URL: "https://bot.example.com/hook", Events: []string{"pull_request", "push"}, ContentType: "json"},
+			{URL: "https://ci.example.com/hook", Events: []string{"push"}, ContentType: "json"},
+		},
+		Permissions: &config.PermissionsConfig{
+			Teams: map[string]string{"maintainers": "write"},
+			Users: map[string]string{"alice": "admin"},
+		},
+		Rulesets: []*config.RepositoryRuleset{
+			{Name: "merge-queue", Target: "branch", Enforcement: "active", Rules: &config.RulesetRules{Deletion: true}},
+			{Name: "tags", Target: "tag", Enforcement: "active"},
+		},
+	}
+
+	// Apply never deletes milestones or webhooks, and only deletes the others with the prune flags.
+	if diffs := diffRepo(desired, live, DiffOptions{}); len(diffs) != 0 {
+		t.Errorf("diffRepo() = %+v, want no differences", diffs)
+	}
+	// Users are not configured, so pruning leaves them alone.
+	if diffs := diffRepo(desired, live, DiffOptions{PrunePermissions: true}); len(diffs) != 0 {
+		t.Errorf("diffRepo() with --prune-permissions = %+v, want no differences", diffs)
+	}
+}
+
+func TestDiffRepoRenames(t *testing.T) {
+	desired := config.RepositoryConfig{
+		Labels:   []config.Label{{Name: "bug", Color: "d73a4a"}},
+		Rulesets: []*config.RepositoryRuleset{{Name: "merge-queue", PreviousNames: []string{"queue"}, Enforcement: "active"}},
+	}
+	live := config.RepositoryConfig{
+		// Labels are matched ignoring case, so apply renames this one.
+		Labels: []config.Label{{Name: "Bug", Color: "d73a4a"}},
+		// A ruleset named in previousNames is renamed or deleted, without --prune-rulesets.
+		Rulesets: []*config.RepositoryRuleset{{Name: "queue", Enforcement: "active"}, {Name: "tags", Enforcement: "active"}},
+	}
+
+	var got []string
+	for _, d := range diffRepo(desired, live, DiffOptions{}) {
+		got = append(got, d.Path)
+	}
+	want := []string{"labels[name=bug].name", "rulesets[name=merge-queue]", "rulesets[name=queue]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("diffRepo() paths = %q, want %q", got, want)
+	}
+}
+
+func TestPrintDiff(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printDiff(&buf, "example/repo", nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "example/repo: no differences\n"; got != want {
+		t.Errorf("printDiff() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	diffs := []fieldDiff{{Path: "description", Want: "new", Got: "old"}}
+	if err := printDiff(&buf, "example/repo", diffs, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "example/repo:\n  \033[33m~ description: \"old\" -> \"new\"\033[0m\n"; got != want {
+		t.Errorf("printDiff() = %q, want %q", got, want)
+	}
+}
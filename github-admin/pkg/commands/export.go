@@ -21,12 +21,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
 	"github.com/google/go-github/v81/github"
 	"github.com/spf13/cobra"
-	"golang.org/x/oauth2"
 	"sigs.k8s.io/yaml"
 )
 
@@ -56,6 +57,7 @@ func BuildExportCommand() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&opt.Owner, "owner", opt.Owner, "The github owner (org or user)")
+	cmd.MarkFlagRequired("owner")
 	cmd.Flags().StringVar(&opt.Repo, "repo", opt.Repo, "The specific repo to export")
 	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
 	cmd.Flags().StringVar(&opt.Output, "output", opt.Output, "Output file path (default is stdout)")
@@ -67,19 +69,11 @@ func RunExport(ctx context.Context, opt ExportOptions) error {
 	if opt.Owner == "" {
 		return fmt.Errorf("--owner is required")
 	}
-	if opt.GitHubToken == "" {
-		opt.GitHubToken = os.Getenv("GITHUB_TOKEN")
-	}
-	if opt.GitHubToken == "" {
-		return fmt.Errorf("--token or GITHUB_TOKEN env var is required")
+	client, err := githubclient.New(ctx, opt.GitHubToken)
+	if err != nil {
+		return err
 	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: opt.GitHubToken},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
-
 	type RepoRef struct {
 		Owner string
 		Name  string
@@ -227,6 +221,9 @@ func exportRepo(ctx context.Context, client *github.Client, repo *github.Reposit
 		},
 		BranchProtection: make(map[string]*config.BranchProtection),
 	}
+	if repo.HasDiscussions != nil {
+		cfg.Discussions = &config.DiscussionsConfig{Enabled: repo.HasDiscussions}
+	}
 
 	// Get branches to check for protection
 	// We specifically care about 'main' but we can check all branches
@@ -256,12 +253,13 @@ func exportRepo(ctx context.Context, client *github.Client, repo *github.Reposit
 	}
 
 	// Export Rulesets
-	rulesets, _, err := client.Repositories.GetAllRulesets(ctx, repo.GetOwner().GetLogin(), repo.GetName(), nil)
+	rulesets, err := listRulesets(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
 	if err != nil {
-		if resp, ok := err.(*github.ErrorResponse); ok && resp.Response.StatusCode == 404 {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 404 {
 			// Rulesets might not be supported or available
 		} else {
-			return nil, fmt.Errorf("failed to get rulesets: %w", err)
+			return nil, err
 		}
 	} else {
 		for _, rsSummary := range rulesets {
@@ -276,6 +274,40 @@ func exportRepo(ctx context.Context, client *github.Client, repo *github.Reposit
 		}
 	}
 
+	cfg.Permissions, err = exportPermissions(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := listLabels(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range labels {
+		cfg.Labels = append(cfg.Labels, mapLabel(l))
+	}
+
+	milestones, err := listMilestones(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range milestones {
+		cfg.Milestones = append(cfg.Milestones, mapMilestone(m))
+	}
+
+	hooks, err := listWebhooks(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hooks {
+		cfg.Webhooks = append(cfg.Webhooks, mapWebhook(h))
+	}
+
+	cfg.RequiredApps, err = exportApps(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -298,20 +330,68 @@ func mapRuleset(rs *github.RepositoryRuleset) *config.RepositoryRuleset {
 	}
 
 	if rs.Rules != nil {
-		res.Rules = &config.RulesetRules{}
-		if rs.Rules.MergeQueue != nil {
-			mq := rs.Rules.MergeQueue
-			res.Rules.MergeQueue = &config.MergeQueueRule{
-				CheckResponseTimeoutMinutes:  mq.CheckResponseTimeoutMinutes,
-				GroupingStrategy:             string(mq.GroupingStrategy),
-				MaxEntriesToBuild:            mq.MaxEntriesToBuild,
-				MaxEntriesToMerge:            mq.MaxEntriesToMerge,
-				MergeMethod:                  string(mq.MergeMethod),
-				MinEntriesToMerge:            mq.MinEntriesToMerge,
-				MinEntriesToMergeWaitMinutes: mq.MinEntriesToMergeWaitMinutes,
+		res.Rules = mapRulesetRules(rs.Rules)
+	}
+	return res
+}
+
+func mapRulesetRules(rules *github.RepositoryRulesetRules) *config.RulesetRules {
+	res := &config.RulesetRules{
+		Creation:              rules.Creation != nil,
+		Deletion:              rules.Deletion != nil,
+		NonFastForward:        rules.NonFastForward != nil,
+		RequiredLinearHistory: rules.RequiredLinearHistory != nil,
+		RequiredSignatures:    rules.RequiredSignatures != nil,
+	}
+	if rules.Update != nil {
+		res.Update = &config.UpdateRule{UpdateAllowsFetchAndMerge: rules.Update.UpdateAllowsFetchAndMerge}
+	}
+	if pr := rules.PullRequest; pr != nil {
+		res.PullRequest = &config.PullRequestRule{
+			DismissStaleReviewsOnPush:      pr.DismissStaleReviewsOnPush,
+			RequireCodeOwnerReview:         pr.RequireCodeOwnerReview,
+			RequireLastPushApproval:        pr.RequireLastPushApproval,
+			RequiredApprovingReviewCount:   pr.RequiredApprovingReviewCount,
+			RequiredReviewThreadResolution: pr.RequiredReviewThreadResolution,
+		}
+		// The default merge methods are left out.
+		if !slices.Equal(pr.AllowedMergeMethods, defaultAllowedMergeMethods) {
+			for _, method := range pr.AllowedMergeMethods {
+				res.PullRequest.AllowedMergeMethods = append(res.PullRequest.AllowedMergeMethods, string(method))
 			}
 		}
 	}
+	if rsc := rules.RequiredStatusChecks; rsc != nil {
+		res.RequiredStatusChecks = &config.RequiredStatusChecksRule{
+			Strict:               rsc.StrictRequiredStatusChecksPolicy,
+			DoNotEnforceOnCreate: rsc.DoNotEnforceOnCreate != nil && *rsc.DoNotEnforceOnCreate,
+		}
+		for _, check := range rsc.RequiredStatusChecks {
+			res.RequiredStatusChecks.Checks = append(res.RequiredStatusChecks.Checks, config.StatusCheck{
+				Context:       check.Context,
+				IntegrationID: check.IntegrationID,
+			})
+		}
+	}
+	if p := rules.TagNamePattern; p != nil {
+		res.TagNamePattern = &config.PatternRule{
+			Operator: string(p.Operator),
+			Pattern:  p.Pattern,
+			Negate:   p.Negate != nil && *p.Negate,
+			Name:     p.GetName(),
+		}
+	}
+	if mq := rules.MergeQueue; mq != nil {
+		res.MergeQueue = &config.MergeQueueRule{
+			CheckResponseTimeoutMinutes:  mq.CheckResponseTimeoutMinutes,
+			GroupingStrategy:             string(mq.GroupingStrategy),
+			MaxEntriesToBuild:            mq.MaxEntriesToBuild,
+			MaxEntriesToMerge:            mq.MaxEntriesToMerge,
+			MergeMethod:                  string(mq.MergeMethod),
+			MinEntriesToMerge:            mq.MinEntriesToMerge,
+			MinEntriesToMergeWaitMinutes: mq.MinEntriesToMergeWaitMinutes,
+		}
+	}
 	return res
 }
 
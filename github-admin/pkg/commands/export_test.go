@@ -155,6 +155,42 @@ func TestMapRuleset(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Ruleset with Branch Rules",
+			rs: &github.RepositoryRuleset{
+				Name:        "main",
+				Enforcement: "active",
+				Rules: &github.RepositoryRulesetRules{
+					Deletion:       &github.EmptyRuleParameters{},
+					NonFastForward: &github.EmptyRuleParameters{},
+					PullRequest: &github.PullRequestRuleParameters{
+						AllowedMergeMethods:          []github.PullRequestMergeMethod{"squash"},
+						RequiredApprovingReviewCount: 1,
+						RequireCodeOwnerReview:       true,
+					},
+					// GitHub leaves out do_not_enforce_on_create when it is false.
+					RequiredStatusChecks: &github.RequiredStatusChecksRuleParameters{
+						RequiredStatusChecks: []*github.RuleStatusCheck{{Context: "presubmit"}},
+					},
+				},
+			},
+			want: &config.RepositoryRuleset{
+				Name:        "main",
+				Enforcement: "active",
+				Rules: &config.RulesetRules{
+					Deletion:       true,
+					NonFastForward: true,
+					PullRequest: &config.PullRequestRule{
+						AllowedMergeMethods:          []string{"squash"},
+						RequiredApprovingReviewCount: 1,
+						RequireCodeOwnerReview:       true,
+					},
+					RequiredStatusChecks: &config.RequiredStatusChecksRule{
+						Checks: []config.StatusCheck{{Context: "presubmit"}},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,260 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+// dueOnFormat is the format of the due dates of milestones in the configuration.
+const dueOnFormat = "2006-01-02"
+
+// labelUpdate is a configured label and the existing label it updates.
+type labelUpdate struct {
+	config   config.Label
+	existing *github.Label
+}
+
+// labelPlan is how applyLabels reconciles the existing labels of a repository with its configuration.
+type labelPlan struct {
+	create []config.Label
+	update []labelUpdate
+	// delete are the existing labels that are not configured; they are only deleted with --prune-labels.
+	delete []*github.Label
+}
+
+// planLabels matches the configured labels with the existing ones by name, ignoring case as GitHub does.
+// Existing labels that already match their configuration are left alone.
+func planLabels(configured []config.Label, existing []*github.Label) *labelPlan {
+	plan := &labelPlan{}
+	claimed := make(map[*github.Label]bool)
+	for _, label := range configured {
+		var match *github.Label
+		for _, l := range existing {
+			if strings.EqualFold(l.GetName(), label.Name) {
+				match = l
+				break
+			}
+		}
+		switch {
+		case match == nil:
+			plan.create = append(plan.create, label)
+		case !sameLabel(match, label):
+			plan.update = append(plan.update, labelUpdate{config: label, existing: match})
+		}
+		if match != nil {
+			claimed[match] = true
+		}
+	}
+	for _, l := range existing {
+		if !claimed[l] {
+			plan.delete = append(plan.delete, l)
+		}
+	}
+	return plan
+}
+
+// sameLabel reports whether the existing label l has the name, color and description of label.
+// An unset description is not managed.
+func sameLabel(l *github.Label, label config.Label) bool {
+	return l.GetName() == label.Name &&
+		strings.EqualFold(l.GetColor(), labelColor(label.Color)) &&
+		(label.Description == nil || l.GetDescription() == *label.Description)
+}
+
+// labelColor returns color without the leading # that GitHub rejects.
+func labelColor(color string) string {
+	return strings.TrimPrefix(color, "#")
+}
+
+func labelFromConfig(label config.Label) *github.Label {
+	return &github.Label{
+		Name:        github.Ptr(label.Name),
+		Color:       github.Ptr(labelColor(label.Color)),
+		Description: label.Description,
+	}
+}
+
+// applyLabels creates and updates the configured labels of the repository, and deletes the others if prune is set.
+func applyLabels(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool, prune bool) error {
+	existing, err := listLabels(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+	plan := planLabels(cfg.Labels, existing)
+
+	for _, label := range plan.create {
+		if dryRun {
+			fmt.Printf("[DryRun] Would create label %s for %s\n", label.Name, cfg.Name)
+			continue
+		}
+		if _, _, err := client.Issues.CreateLabel(ctx, cfg.Owner, cfg.Name, labelFromConfig(label)); err != nil {
+			return fmt.Errorf("failed to create label %s: %w", label.Name, err)
+		}
+	}
+	for _, u := range plan.update {
+		if dryRun {
+			fmt.Printf("[DryRun] Would update label %s for %s\n", u.config.Name, cfg.Name)
+			continue
+		}
+		// The label is addressed by its current name, which may only differ from the configured one by case.
+		if _, _, err := client.Issues.EditLabel(ctx, cfg.Owner, cfg.Name, u.existing.GetName(), labelFromConfig(u.config)); err != nil {
+			return fmt.Errorf("failed to update label %s: %w", u.config.Name, err)
+		}
+	}
+	for _, l := range plan.delete {
+		if !prune {
+			fmt.Printf("Label %s of %s is not configured; pass --prune-labels to delete it\n", l.GetName(), cfg.Name)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DryRun] Would delete label %s for %s\n", l.GetName(), cfg.Name)
+			continue
+		}
+		if _, err := client.Issues.DeleteLabel(ctx, cfg.Owner, cfg.Name, l.GetName()); err != nil {
+			return fmt.Errorf("failed to delete label %s: %w", l.GetName(), err)
+		}
+	}
+	return nil
+}
+
+func listLabels(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Label, error) {
+	var all []*github.Label
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := client.Issues.ListLabels(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		all = append(all, labels...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+func listMilestones(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Milestone, error) {
+	var all []*github.Milestone
+	opt := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		milestones, resp, err := client.Issues.ListMilestones(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list milestones: %w", err)
+		}
+		all = append(all, milestones...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// applyMilestones creates the configured milestones that do not exist, and updates those that differ.
+func applyMilestones(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool) error {
+	existing, err := listMilestones(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+	byTitle := make(map[string]*github.Milestone)
+	for _, m := range existing {
+		byTitle[m.GetTitle()] = m
+	}
+
+	for _, milestone := range cfg.Milestones {
+		req, err := milestoneFromConfig(milestone)
+		if err != nil {
+			return err
+		}
+		current, ok := byTitle[milestone.Title]
+		switch {
+		case !ok && dryRun:
+			fmt.Printf("[DryRun] Would create milestone %s for %s\n", milestone.Title, cfg.Name)
+		case !ok:
+			if _, _, err := client.Issues.CreateMilestone(ctx, cfg.Owner, cfg.Name, req); err != nil {
+				return fmt.Errorf("failed to create milestone %s: %w", milestone.Title, err)
+			}
+		case sameMilestone(current, milestone):
+		case dryRun:
+			fmt.Printf("[DryRun] Would update milestone %s for %s\n", milestone.Title, cfg.Name)
+		default:
+			if _, _, err := client.Issues.EditMilestone(ctx, cfg.Owner, cfg.Name, current.GetNumber(), req); err != nil {
+				return fmt.Errorf("failed to update milestone %s: %w", milestone.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+func milestoneFromConfig(milestone config.Milestone) (*github.Milestone, error) {
+	res := &github.Milestone{
+		Title:       github.Ptr(milestone.Title),
+		Description: milestone.Description,
+		State:       github.Ptr(milestoneState(milestone)),
+	}
+	if milestone.DueOn != "" {
+		due, err := time.Parse(dueOnFormat, milestone.DueOn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dueOn %q of milestone %s, want YYYY-MM-DD: %w", milestone.DueOn, milestone.Title, err)
+		}
+		res.DueOn = &github.Timestamp{Time: due}
+	}
+	return res, nil
+}
+
+func milestoneState(milestone config.Milestone) string {
+	if milestone.State == "" {
+		return "open"
+	}
+	return milestone.State
+}
+
+// sameMilestone reports whether the existing milestone m has the state, description and due date of milestone.
+// An unset description or due date is not managed.
+func sameMilestone(m *github.Milestone, milestone config.Milestone) bool {
+	return m.GetState() == milestoneState(milestone) &&
+		(milestone.Description == nil || m.GetDescription() == *milestone.Description) &&
+		(milestone.DueOn == "" || milestoneDueOn(m) == milestone.DueOn)
+}
+
+// milestoneDueOn returns the due date of m as YYYY-MM-DD, or "" if it has none.
+func milestoneDueOn(m *github.Milestone) string {
+	if m.DueOn == nil {
+		return ""
+	}
+	return m.DueOn.UTC().Format(dueOnFormat)
+}
+
+func mapLabel(l *github.Label) config.Label {
+	res := config.Label{Name: l.GetName(), Color: l.GetColor()}
+	if l.GetDescription() != "" {
+		res.Description = l.Description
+	}
+	return res
+}
+
+func mapMilestone(m *github.Milestone) config.Milestone {
+	res := config.Milestone{Title: m.GetTitle(), State: m.GetState(), DueOn: milestoneDueOn(m)}
+	if m.GetDescription() != "" {
+		res.Description = m.Description
+	}
+	return res
+}
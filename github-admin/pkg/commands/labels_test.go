@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+func TestPlanLabels(t *testing.T) {
+	existing := []*github.Label{
+		{Name: github.Ptr("bug"), Color: github.Ptr("D73A4A"), Description: github.Ptr("Something isn't working")},
+		{Name: github.Ptr("Enhancement"), Color: github.Ptr("a2eeef")},
+		{Name: github.Ptr("wontfix"), Color: github.Ptr("ffffff")},
+	}
+	configured := []config.Label{
+		// Colors are compared ignoring case and the leading #.
+		{Name: "bug", Color: "#d73a4a"},
+		{Name: "enhancement", Color: "a2eeef"},
+		{Name: "kind/cleanup", Color: "c5def5", Description: github.Ptr("Refactoring")},
+	}
+
+	plan := planLabels(configured, existing)
+	var got []string
+	for _, l := range plan.create {
+		got = append(got, "create "+l.Name)
+	}
+	for _, u := range plan.update {
+		got = append(got, "update "+u.existing.GetName()+" to "+u.config.Name)
+	}
+	for _, l := range plan.delete {
+		got = append(got, "delete "+l.GetName())
+	}
+	want := []string{"create kind/cleanup", "update Enhancement to enhancement", "delete wontfix"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planLabels() = %q, want %q", got, want)
+	}
+}
+
+// fakeLabels serves the labels and milestones of repo example/repo, recording the mutating requests.
+type fakeLabels struct {
+	requests []string
+}
+
+func (f *fakeLabels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	switch key {
+	case "GET /repos/example/repo/labels":
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"name": "bug", "color": "d73a4a"},
+			{"name": "question", "color": "d876e3"},
+		})
+	case "GET /repos/example/repo/milestones":
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 1, "title": "v1.0", "state": "open", "due_on": "2026-03-01T08:00:00Z"},
+		})
+	default:
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.requests = append(f.requests, key)
+		json.NewEncoder(w).Encode(map[string]any{})
+	}
+}
+
+func TestApplyLabels(t *testing.T) {
+	cfg := config.RepositoryConfig{
+		Owner:  "example",
+		Name:   "repo",
+		Labels: []config.Label{{Name: "bug", Color: "d73a4a"}, {Name: "kind/feature", Color: "a2eeef"}},
+	}
+	for _, tc := range []struct {
+		name   string
+		dryRun bool
+		prune  bool
+		want   []string
+	}{
+		{name: "dry run", dryRun: true, prune: true},
+		{name: "keep", want: []string{"POST /repos/example/repo/labels"}},
+		{name: "prune", prune: true, want: []string{"POST /repos/example/repo/labels", "DELETE /repos/example/repo/labels/question"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeLabels{}
+			if err := applyLabels(t.Context(), newFakeClient(t, fake), cfg, tc.dryRun, tc.prune); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fake.requests, tc.want) {
+				t.Errorf("requests = %q, want %q", fake.requests, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyMilestones(t *testing.T) {
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Milestones: []config.Milestone{
+			{Title: "v1.0", DueOn: "2026-03-01"},
+			{Title: "v1.1", DueOn: "2026-06-01"},
+			{Title: "v2.0", State: "closed"},
+		},
+	}
+	fake := &fakeLabels{}
+	if err := applyMilestones(t.Context(), newFakeClient(t, fake), cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"POST /repos/example/repo/milestones", "POST /repos/example/repo/milestones"}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+
+	cfg.Milestones = []config.Milestone{{Title: "v1.0", State: "closed"}}
+	fake = &fakeLabels{}
+	if err := applyMilestones(t.Context(), newFakeClient(t, fake), cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"PATCH /repos/example/repo/milestones/1"}; !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+
+	cfg.Milestones = []config.Milestone{{Title: "v3.0", DueOn: "next week"}}
+	if err := applyMilestones(t.Context(), newFakeClient(t, &fakeLabels{}), cfg, false); err == nil {
+		t.Error("applyMilestones() with an invalid dueOn succeeded, want an error")
+	}
+}
+
+func TestMapMilestone(t *testing.T) {
+	m := &github.Milestone{
+		Title:       github.Ptr("v1.0"),
+		State:       github.Ptr("closed"),
+		Description: github.Ptr(""),
+		DueOn:       &github.Timestamp{Time: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	want := config.Milestone{Title: "v1.0", State: "closed", DueOn: "2026-03-01"}
+	if got := mapMilestone(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("mapMilestone() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+// permissionPlan is how applyPermissions reconciles the existing roles of the teams or the users of a repository
+// with the configured ones.
+type permissionPlan struct {
+	// grant are the configured teams or users whose role is missing or differs, sorted.
+	grant []string
+	// remove are the teams or users with access that are not configured, sorted.
+	remove []string
+}
+
+// planPermissions compares the configured roles with the existing ones, both keyed by team slug or user login.
+// Names are compared ignoring case, as GitHub does, and roles after normalizing them with normalizeRole.
+func planPermissions(configured, existing map[string]string) permissionPlan {
+	current := make(map[string]string)
+	for name, role := range existing {
+		current[strings.ToLower(name)] = normalizeRole(role)
+	}
+	wanted := make(map[string]bool)
+
+	var plan permissionPlan
+	for name, role := range configured {
+		wanted[strings.ToLower(name)] = true
+		if got, ok := current[strings.ToLower(name)]; !ok || got != normalizeRole(role) {
+			plan.grant = append(plan.grant, name)
+		}
+	}
+	for name := range existing {
+		if !wanted[strings.ToLower(name)] {
+			plan.remove = append(plan.remove, name)
+		}
+	}
+	sort.Strings(plan.grant)
+	sort.Strings(plan.remove)
+	return plan
+}
+
+// normalizeRole returns the role as shown in the GitHub UI, which the API calls read and write permissions
+// "pull" and "push".
+func normalizeRole(role string) string {
+	switch role = strings.ToLower(role); role {
+	case "pull":
+		return "read"
+	case "push":
+		return "write"
+	}
+	return role
+}
+
+// apiPermission returns the permission the API expects for role.
+func apiPermission(role string) string {
+	switch role = normalizeRole(role); role {
+	case "read":
+		return "pull"
+	case "write":
+		return "push"
+	}
+	return role
+}
+
+// applyPermissions grants the configured roles to the teams and users of the repository, and removes the access
+// of the others if prune is set. The teams or the users are not managed if their map is not set.
+func applyPermissions(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool, prune bool) error {
+	if cfg.Permissions.Teams != nil {
+		if err := applyTeamRoles(ctx, client, cfg, dryRun, prune); err != nil {
+			return err
+		}
+	}
+	if cfg.Permissions.Users != nil {
+		if err := applyUserRoles(ctx, client, cfg, dryRun, prune); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyTeamRoles(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool, prune bool) error {
+	teams, err := listTeamRoles(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+	plan := planPermissions(cfg.Permissions.Teams, teams)
+	for _, slug := range plan.grant {
+		role := cfg.Permissions.Teams[slug]
+		if dryRun {
+			fmt.Printf("[DryRun] Would grant team %s the %s role on %s\n", slug, normalizeRole(role), cfg.Name)
+			continue
+		}
+		opts := &github.TeamAddTeamRepoOptions{Permission: apiPermission(role)}
+		if _, err := client.Teams.AddTeamRepoBySlug(ctx, cfg.Owner, slug, cfg.Owner, cfg.Name, opts); err != nil {
+			return fmt.Errorf("failed to grant team %s the %s role: %w", slug, role, err)
+		}
+	}
+	for _, slug := range plan.remove {
+		if !prune {
+			fmt.Printf("Team %s has access to %s but is not configured; pass --prune-permissions to remove it\n", slug, cfg.Name)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DryRun] Would remove team %s from %s\n", slug, cfg.Name)
+			continue
+		}
+		if _, err := client.Teams.RemoveTeamRepoBySlug(ctx, cfg.Owner, slug, cfg.Owner, cfg.Name); err != nil {
+			return fmt.Errorf("failed to remove team %s: %w", slug, err)
+		}
+	}
+	return nil
+}
+
+func applyUserRoles(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool, prune bool) error {
+	users, err := listCollaboratorRoles(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+	invitations, err := listInvitations(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+	// A pending invitation with the configured role is not sent again.
+	for login, invitation := range invitations {
+		users[login] = invitation.GetPermissions()
+	}
+	plan := planPermissions(cfg.Permissions.Users, users)
+	for _, login := range plan.grant {
+		role := cfg.Permissions.Users[login]
+		if dryRun {
+			fmt.Printf("[DryRun] Would grant user %s the %s role on %s\n", login, normalizeRole(role), cfg.Name)
+			continue
+		}
+		opts := &github.RepositoryAddCollaboratorOptions{Permission: apiPermission(role)}
+		if _, _, err := client.Repositories.AddCollaborator(ctx, cfg.Owner, cfg.Name, login, opts); err != nil {
+			return fmt.Errorf("failed to grant user %s the %s role: %w", login, role, err)
+		}
+	}
+	for _, login := range plan.remove {
+		if !prune {
+			fmt.Printf("User %s has access to %s but is not configured; pass --prune-permissions to remove it\n", login, cfg.Name)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DryRun] Would remove user %s from %s\n", login, cfg.Name)
+			continue
+		}
+		if invitation, ok := invitations[login]; ok {
+			if _, err := client.Repositories.DeleteInvitation(ctx, cfg.Owner, cfg.Name, invitation.GetID()); err != nil {
+				return fmt.Errorf("failed to cancel the invitation of user %s: %w", login, err)
+			}
+			continue
+		}
+		if _, err := client.Repositories.RemoveCollaborator(ctx, cfg.Owner, cfg.Name, login); err != nil {
+			return fmt.Errorf("failed to remove user %s: %w", login, err)
+		}
+	}
+	return nil
+}
+
+// listTeamRoles returns the roles of the teams with access to the repository, by team slug.
+func listTeamRoles(ctx context.Context, client *github.Client, owner, repo string) (map[string]string, error) {
+	roles := make(map[string]string)
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		teams, resp, err := client.Repositories.ListTeams(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
+		for _, team := range teams {
+			roles[team.GetSlug()] = normalizeRole(team.GetPermission())
+		}
+		if resp.NextPage == 0 {
+			return roles, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// listCollaboratorRoles returns the roles of the direct collaborators of the repository, by login.
+// Users who only have access through a team or as organization owners are not included.
+func listCollaboratorRoles(ctx context.Context, client *github.Client, owner, repo string) (map[string]string, error) {
+	roles := make(map[string]string)
+	opt := &github.ListCollaboratorsOptions{Affiliation: "direct", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		users, resp, err := client.Repositories.ListCollaborators(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list collaborators: %w", err)
+		}
+		for _, user := range users {
+			roles[user.GetLogin()] = normalizeRole(user.GetRoleName())
+		}
+		if resp.NextPage == 0 {
+			return roles, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// listInvitations returns the pending invitations to collaborate on the repository, by the login of the invitee.
+func listInvitations(ctx context.Context, client *github.Client, owner, repo string) (map[string]*github.RepositoryInvitation, error) {
+	invitations := make(map[string]*github.RepositoryInvitation)
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Repositories.ListInvitations(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list invitations: %w", err)
+		}
+		for _, invitation := range page {
+			if invitation.GetExpired() {
+				continue
+			}
+			invitations[invitation.GetInvitee().GetLogin()] = invitation
+		}
+		if resp.NextPage == 0 {
+			return invitations, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// exportPermissions returns the teams and direct collaborators of the repository with their roles,
+// or nil if there are none.
+func exportPermissions(ctx context.Context, client *github.Client, owner, repo string) (*config.PermissionsConfig, error) {
+	teams, err := listTeamRoles(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	users, err := listCollaboratorRoles(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) == 0 && len(users) == 0 {
+		return nil, nil
+	}
+	permissions := &config.PermissionsConfig{}
+	if len(teams) > 0 {
+		permissions.Teams = teams
+	}
+	if len(users) > 0 {
+		permissions.Users = users
+	}
+	return permissions, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+)
+
+func TestPlanPermissions(t *testing.T) {
+	configured := map[string]string{
+		"maintainers": "maintain",
+		"Alice":       "write",
+		"bob":         "admin",
+		"reviewers":   "read",
+	}
+	existing := map[string]string{
+		"maintainers": "maintain",
+		// GitHub reports write as push; logins are compared ignoring case.
+		"alice":  "push",
+		"bob":    "write",
+		"former": "admin",
+	}
+	got := planPermissions(configured, existing)
+	want := permissionPlan{grant: []string{"bob", "reviewers"}, remove: []string{"former"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planPermissions() = %+v, want %+v", got, want)
+	}
+}
+
+// fakePermissions serves the teams, collaborators and invitations of repo example/repo,
+// recording the mutating requests with their permission.
+type fakePermissions struct {
+	requests []string
+}
+
+func (f *fakePermissions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	switch key {
+	case "GET /repos/example/repo/teams":
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"slug": "maintainers", "permission": "maintain"},
+			{"slug": "old-team", "permission": "push"},
+		})
+	case "GET /repos/example/repo/collaborators":
+		if r.URL.Query().Get("affiliation") != "direct" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"login": "alice", "role_name": "read"},
+			{"login": "mallory", "role_name": "admin"},
+		})
+	case "GET /repos/example/repo/invitations":
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 7, "invitee": map[string]any{"login": "carol"}, "permissions": "write"},
+		})
+	default:
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		var body struct {
+			Permission string `json:"permission"`
+		}
+		_ = json.Unmarshal(data, &body)
+		if body.Permission != "" {
+			key += " " + body.Permission
+		}
+		f.requests = append(f.requests, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestApplyPermissions(t *testing.T) {
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Permissions: &config.PermissionsConfig{
+			Teams: map[string]string{"maintainers": "maintain", "reviewers": "read"},
+			Users: map[string]string{"alice": "write", "carol": "write"},
+		},
+	}
+	for _, tc := range []struct {
+		name   string
+		dryRun bool
+		prune  bool
+		want   []string
+	}{
+		{name: "dry run", dryRun: true, prune: true},
+		{
+			name: "keep",
+			want: []string{
+				"PUT /orgs/example/teams/reviewers/repos/example/repo pull",
+				"PUT /repos/example/repo/collaborators/alice push",
+			},
+		},
+		{
+			name:  "prune",
+			prune: true,
+			want: []string{
+				"PUT /orgs/example/teams/reviewers/repos/example/repo pull",
+				"DELETE /orgs/example/teams/old-team/repos/example/repo",
+				"PUT /repos/example/repo/collaborators/alice push",
+				"DELETE /repos/example/repo/collaborators/mallory",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakePermissions{}
+			if err := applyPermissions(t.Context(), newFakeClient(t, fake), cfg, tc.dryRun, tc.prune); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fake.requests, tc.want) {
+				t.Errorf("requests = %q, want %q", fake.requests, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyPermissionsTeamsOnly(t *testing.T) {
+	// Users are not configured, so pruning leaves the collaborators alone.
+	cfg := config.RepositoryConfig{
+		Owner:       "example",
+		Name:        "repo",
+		Permissions: &config.PermissionsConfig{Teams: map[string]string{"maintainers": "maintain"}},
+	}
+	fake := &fakePermissions{}
+	if err := applyPermissions(t.Context(), newFakeClient(t, fake), cfg, false, true); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"DELETE /orgs/example/teams/old-team/repos/example/repo"}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+}
+
+func TestExportPermissions(t *testing.T) {
+	got, err := exportPermissions(t.Context(), newFakeClient(t, &fakePermissions{}), "example", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &config.PermissionsConfig{
+		Teams: map[string]string{"maintainers": "maintain", "old-team": "write"},
+		Users: map[string]string{"alice": "read", "mallory": "admin"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("exportPermissions() = %+v, want %+v", got, want)
+	}
+}
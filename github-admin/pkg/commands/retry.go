@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// retryPolicy controls how transient GitHub errors (rate limits, server errors) are retried.
+type retryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	// MaxDelay is the longest we will wait before a retry; if GitHub asks us to wait longer
+	// (e.g. the hourly rate limit is exhausted) we give up, and the run can be resumed later.
+	MaxDelay time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    5 * time.Minute,
+}
+
+// do calls fn, retrying with exponential backoff while it returns a retryable error.
+func (p retryPolicy) do(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= p.MaxAttempts {
+			return err
+		}
+		delay, ok := p.retryDelay(err, attempt, time.Now())
+		if !ok {
+			return err
+		}
+		// Progress goes to stderr, so it does not mix with output written to stdout (e.g. audit export).
+		fmt.Fprintf(os.Stderr, "Retrying in %v after error: %v\n", delay.Round(time.Second), err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay returns how long to wait before retrying after err, and false if err is not retryable.
+func (p retryPolicy) retryDelay(err error, attempt int, now time.Time) (time.Duration, bool) {
+	backoff := p.BaseDelay << (attempt - 1)
+	if backoff > p.MaxDelay || backoff <= 0 {
+		backoff = p.MaxDelay
+	}
+
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		delay := rateLimitErr.Rate.Reset.Sub(now)
+		if delay > p.MaxDelay {
+			return 0, false
+		}
+		return max(delay, backoff), true
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			if *abuseErr.RetryAfter > p.MaxDelay {
+				return 0, false
+			}
+			return max(*abuseErr.RetryAfter, backoff), true
+		}
+		return backoff, true
+	}
+
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode >= http.StatusInternalServerError {
+		return backoff, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+	now := time.Now()
+	retryAfter := 10 * time.Second
+	longRetryAfter := time.Hour
+
+	tests := []struct {
+		name      string
+		err       error
+		attempt   int
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{
+			name:      "server error backs off exponentially",
+			err:       &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}},
+			attempt:   3,
+			wantDelay: 4 * time.Second,
+			wantRetry: true,
+		},
+		{
+			name:      "backoff is capped",
+			err:       &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusInternalServerError}},
+			attempt:   20,
+			wantDelay: time.Minute,
+			wantRetry: true,
+		},
+		{
+			name:      "client error is not retried",
+			err:       &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}},
+			attempt:   1,
+			wantRetry: false,
+		},
+		{
+			name:      "wrapped rate limit waits for reset",
+			err:       fmt.Errorf("failed: %w", &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: now.Add(30 * time.Second)}}}),
+			attempt:   1,
+			wantDelay: 30 * time.Second,
+			wantRetry: true,
+		},
+		{
+			name:      "rate limit reset too far away",
+			err:       &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: now.Add(time.Hour)}}},
+			attempt:   1,
+			wantRetry: false,
+		},
+		{
+			name:      "abuse rate limit honors retry-after",
+			err:       &github.AbuseRateLimitError{RetryAfter: &retryAfter},
+			attempt:   1,
+			wantDelay: retryAfter,
+			wantRetry: true,
+		},
+		{
+			name:      "abuse rate limit retry-after too long",
+			err:       &github.AbuseRateLimitError{RetryAfter: &longRetryAfter},
+			attempt:   1,
+			wantRetry: false,
+		},
+		{
+			name:      "other errors are not retried",
+			err:       fmt.Errorf("boom"),
+			attempt:   1,
+			wantRetry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := policy.retryDelay(tt.err, tt.attempt, now)
+			if retry != tt.wantRetry {
+				t.Fatalf("retryDelay() retry = %v, want %v", retry, tt.wantRetry)
+			}
+			if retry && delay != tt.wantDelay {
+				t.Errorf("retryDelay() delay = %v, want %v", delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := retryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	}
+	serverErr := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+
+	calls := 0
+	err := policy.do(context.Background(), func() error {
+		calls++
+		if calls < 2 {
+			return serverErr
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("do() returned error after transient failure: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	calls = 0
+	err = policy.do(context.Background(), func() error {
+		calls++
+		return serverErr
+	})
+	if err == nil {
+		t.Errorf("do() should fail after exhausting attempts")
+	}
+	if calls != policy.MaxAttempts {
+		t.Errorf("expected %d calls, got %d", policy.MaxAttempts, calls)
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+// rulesetMatch is a configured ruleset and the existing ruleset it updates, if any.
+type rulesetMatch struct {
+	config   *config.RepositoryRuleset
+	existing *github.RepositoryRuleset
+	// renamed explains why an existing ruleset with another name is the configured one, empty if the names match.
+	renamed string
+}
+
+// rulesetPlan is how applyRulesets reconciles the existing rulesets of a repository with its configuration.
+type rulesetPlan struct {
+	matches []rulesetMatch
+	// superseded are the existing rulesets replaced by a configured one under another name, which are deleted.
+	superseded []*github.RepositoryRuleset
+	// unconfigured are the other existing rulesets; they are only deleted with --prune-rulesets.
+	unconfigured []*github.RepositoryRuleset
+}
+
+// applyRulesets creates and updates the configured rulesets of the repository. If prune is set and the
+// repository configures rulesets, the existing rulesets that are not configured are deleted.
+func applyRulesets(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool, prune bool) error {
+	// List existing rulesets to find IDs
+	existingRulesets, err := listRulesets(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	// The list does not include the conditions and rules, which are only fetched when comparing them.
+	details := func(rs *github.RepositoryRuleset) (*github.RepositoryRuleset, error) {
+		if rs.ID == nil {
+			return nil, fmt.Errorf("existing ruleset %s has no ID", rs.Name)
+		}
+		full, _, err := client.Repositories.GetRuleset(ctx, cfg.Owner, cfg.Name, *rs.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ruleset %s: %w", rs.Name, err)
+		}
+		return full, nil
+	}
+	plan, err := planRulesets(cfg.Rulesets, existingRulesets, details)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range plan.matches {
+		rsReq := rulesetFromConfig(m.config)
+
+		if existing := m.existing; existing != nil {
+			// Update
+			if dryRun {
+				if m.renamed != "" {
+					fmt.Printf("[DryRun] Would rename ruleset %s to %s (%s) and update it for %s\n", existing.Name, m.config.Name, m.renamed, cfg.Name)
+				} else {
+					fmt.Printf("[DryRun] Would update ruleset %s for %s\n", m.config.Name, cfg.Name)
+				}
+			} else {
+				if existing.ID == nil {
+					return fmt.Errorf("existing ruleset %s has no ID", existing.Name)
+				}
+				if m.renamed != "" {
+					fmt.Printf("Renaming ruleset %s to %s (%s) for %s\n", existing.Name, m.config.Name, m.renamed, cfg.Name)
+				}
+				_, _, err := client.Repositories.UpdateRuleset(ctx, cfg.Owner, cfg.Name, *existing.ID, *rsReq)
+				if err != nil {
+					return fmt.Errorf("failed to update ruleset %s: %w", m.config.Name, err)
+				}
+			}
+		} else {
+			// Create
+			if dryRun {
+				fmt.Printf("[DryRun] Would create ruleset %s for %s\n", m.config.Name, cfg.Name)
+			} else {
+				_, _, err := client.Repositories.CreateRuleset(ctx, cfg.Owner, cfg.Name, *rsReq)
+				if err != nil {
+					return fmt.Errorf("failed to create ruleset %s: %w", m.config.Name, err)
+				}
+			}
+		}
+	}
+
+	// Superseded rulesets are deleted last, so that the rules stay enforced throughout.
+	for _, rs := range plan.superseded {
+		if dryRun {
+			fmt.Printf("[DryRun] Would delete superseded ruleset %s for %s\n", rs.Name, cfg.Name)
+			continue
+		}
+		if rs.ID == nil {
+			return fmt.Errorf("existing ruleset %s has no ID", rs.Name)
+		}
+		fmt.Printf("Deleting superseded ruleset %s for %s\n", rs.Name, cfg.Name)
+		if _, err := client.Repositories.DeleteRuleset(ctx, cfg.Owner, cfg.Name, *rs.ID); err != nil {
+			return fmt.Errorf("failed to delete superseded ruleset %s: %w", rs.Name, err)
+		}
+	}
+
+	// Without rulesets in the config, the rulesets of the repository are not managed.
+	if cfg.Rulesets == nil {
+		return nil
+	}
+	for _, rs := range plan.unconfigured {
+		if !prune {
+			fmt.Printf("Ruleset %s of %s is not configured; pass --prune-rulesets to delete it\n", rs.Name, cfg.Name)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("[DryRun] Would delete ruleset %s for %s\n", rs.Name, cfg.Name)
+			continue
+		}
+		if rs.ID == nil {
+			return fmt.Errorf("existing ruleset %s has no ID", rs.Name)
+		}
+		if _, err := client.Repositories.DeleteRuleset(ctx, cfg.Owner, cfg.Name, *rs.ID); err != nil {
+			return fmt.Errorf("failed to delete ruleset %s: %w", rs.Name, err)
+		}
+	}
+	return nil
+}
+
+// planRulesets matches the configured rulesets with the existing ones, which details returns with their
+// conditions and rules. A configured ruleset updates, in order of preference:
+//   - the existing ruleset with its name;
+//   - an existing ruleset with one of its previousNames;
+//   - the only existing ruleset that is not configured and has the same target, enforcement, conditions and rules,
+//     as when it was renamed in the configuration only.
+//
+// Otherwise it is created. Existing rulesets that are not configured but are named in previousNames are superseded:
+// left behind by a rename. Other existing rulesets, including those that are only identical to a configured one,
+// are unconfigured.
+func planRulesets(configured []*config.RepositoryRuleset, existing []*github.RepositoryRuleset, details func(*github.RepositoryRuleset) (*github.RepositoryRuleset, error)) (*rulesetPlan, error) {
+	byName := make(map[string]*github.RepositoryRuleset)
+	for _, rs := range existing {
+		byName[rs.Name] = rs
+	}
+	claimed := make(map[*github.RepositoryRuleset]bool)
+
+	plan := &rulesetPlan{matches: make([]rulesetMatch, len(configured))}
+	for i, rsConfig := range configured {
+		plan.matches[i].config = rsConfig
+		if rs, ok := byName[rsConfig.Name]; ok {
+			plan.matches[i].existing = rs
+			claimed[rs] = true
+		}
+	}
+
+	for i, rsConfig := range configured {
+		if plan.matches[i].existing != nil {
+			continue
+		}
+		for _, previous := range rsConfig.PreviousNames {
+			if rs, ok := byName[previous]; ok && !claimed[rs] {
+				plan.matches[i].existing = rs
+				plan.matches[i].renamed = "listed in previousNames"
+				claimed[rs] = true
+				break
+			}
+		}
+	}
+
+	// The remaining existing rulesets are compared by content with the configured ones.
+	fetched := make(map[*github.RepositoryRuleset]*github.RepositoryRuleset)
+	sameAs := func(rs *github.RepositoryRuleset, rsConfig *config.RepositoryRuleset) (bool, error) {
+		full, ok := fetched[rs]
+		if !ok {
+			var err error
+			if full, err = details(rs); err != nil {
+				return false, err
+			}
+			fetched[rs] = full
+		}
+		return sameRulesetContent(full, rulesetFromConfig(rsConfig)), nil
+	}
+
+	for i, rsConfig := range configured {
+		if plan.matches[i].existing != nil {
+			continue
+		}
+		var candidates []*github.RepositoryRuleset
+		for _, rs := range existing {
+			if claimed[rs] {
+				continue
+			}
+			same, err := sameAs(rs, rsConfig)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				candidates = append(candidates, rs)
+			}
+		}
+		// With several candidates, there is no telling which one was renamed.
+		if len(candidates) == 1 {
+			plan.matches[i].existing = candidates[0]
+			plan.matches[i].renamed = "same rules"
+			claimed[candidates[0]] = true
+		}
+	}
+
+	for _, rs := range existing {
+		if claimed[rs] {
+			continue
+		}
+		superseded := slices.ContainsFunc(configured, func(rsConfig *config.RepositoryRuleset) bool {
+			return slices.Contains(rsConfig.PreviousNames, rs.Name)
+		})
+		if superseded {
+			plan.superseded = append(plan.superseded, rs)
+		} else {
+			plan.unconfigured = append(plan.unconfigured, rs)
+		}
+	}
+	return plan, nil
+}
+
+// listRulesets returns the rulesets of the repository itself, without those it inherits from its organization
+// or enterprise, which cannot be updated or deleted through the repository.
+func listRulesets(ctx context.Context, client *github.Client, owner, repo string) ([]*github.RepositoryRuleset, error) {
+	var all []*github.RepositoryRuleset
+	opt := &github.RepositoryListRulesetsOptions{IncludesParents: github.Ptr(false), ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		rulesets, resp, err := client.Repositories.GetAllRulesets(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list existing rulesets: %w", err)
+		}
+		for _, rs := range rulesets {
+			// Rulesets without a source type are from before it was reported, when they could only be the repository's.
+			if source := rs.GetSourceType(); source == nil || *source == github.RulesetSourceTypeRepository {
+				all = append(all, rs)
+			}
+		}
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// sameRulesetContent reports whether two rulesets enforce the same rules on the same refs, whatever their names.
+func sameRulesetContent(a, b *github.RepositoryRuleset) bool {
+	return reflect.DeepEqual(rulesetContent(a), rulesetContent(b))
+}
+
+// rulesetContent returns the target, enforcement, conditions and rules of rs, with the defaults and empty
+// values that GitHub returns normalized to what rulesetFromConfig produces.
+func rulesetContent(rs *github.RepositoryRuleset) github.RepositoryRuleset {
+	target := github.RulesetTarget("branch")
+	if rs.Target != nil {
+		target = *rs.Target
+	}
+	content := github.RepositoryRuleset{Target: &target, Enforcement: rs.Enforcement}
+
+	if rs.Conditions != nil && rs.Conditions.RefName != nil {
+		include, exclude := rs.Conditions.RefName.Include, rs.Conditions.RefName.Exclude
+		if len(include) > 0 || len(exclude) > 0 {
+			refName := &github.RepositoryRulesetRefConditionParameters{}
+			if len(include) > 0 {
+				refName.Include = include
+			}
+			if len(exclude) > 0 {
+				refName.Exclude = exclude
+			}
+			content.Conditions = &github.RepositoryRulesetConditions{RefName: refName}
+		}
+	}
+	if rs.Rules != nil && !reflect.DeepEqual(*rs.Rules, github.RepositoryRulesetRules{}) {
+		rules := *rs.Rules
+		if rsc := rules.RequiredStatusChecks; rsc != nil && rsc.DoNotEnforceOnCreate == nil {
+			rules.RequiredStatusChecks = &github.RequiredStatusChecksRuleParameters{
+				DoNotEnforceOnCreate:             github.Ptr(false),
+				RequiredStatusChecks:             rsc.RequiredStatusChecks,
+				StrictRequiredStatusChecksPolicy: rsc.StrictRequiredStatusChecksPolicy,
+			}
+		}
+		if p := rules.TagNamePattern; p != nil && p.Negate == nil {
+			rules.TagNamePattern = &github.PatternRuleParameters{Name: p.Name, Negate: github.Ptr(false), Operator: p.Operator, Pattern: p.Pattern}
+		}
+		content.Rules = &rules
+	}
+	return content
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+func TestPlanRulesets(t *testing.T) {
+	mergeQueue := &config.RulesetRules{MergeQueue: &config.MergeQueueRule{MergeMethod: "SQUASH"}}
+	mainOnly := &config.RulesetConditions{RefName: &config.RefNameCondition{Include: []string{"~DEFAULT_BRANCH"}}}
+
+	// existing returns the existing ruleset with the given name and ID, as GitHub returns it:
+	// with the default target and empty exclusions.
+	existing := func(id int64, name string, rs *config.RepositoryRuleset) *github.RepositoryRuleset {
+		full := rulesetFromConfig(rs)
+		full.ID = github.Ptr(id)
+		full.Name = name
+		full.Target = github.Ptr(github.RulesetTarget("branch"))
+		if full.Conditions != nil {
+			full.Conditions.RefName.Exclude = []string{}
+		}
+		return full
+	}
+	queue := &config.RepositoryRuleset{Name: "queue", Enforcement: "active", Conditions: mainOnly, Rules: mergeQueue}
+	evaluate := &config.RepositoryRuleset{Name: "queue", Enforcement: "evaluate", Conditions: mainOnly, Rules: mergeQueue}
+
+	tests := []struct {
+		name             string
+		configured       []*config.RepositoryRuleset
+		existing         []*github.RepositoryRuleset
+		wantMatches      []string
+		wantSuperseded   []string
+		wantUnconfigured []string
+	}{
+		{
+			name:        "same name",
+			configured:  []*config.RepositoryRuleset{queue},
+			existing:    []*github.RepositoryRuleset{existing(1, "queue", evaluate)},
+			wantMatches: []string{"queue=queue"},
+		},
+		{
+			name:        "previous name",
+			configured:  []*config.RepositoryRuleset{{Name: "merge-queue", PreviousNames: []string{"mq", "queue"}, Enforcement: "evaluate"}},
+			existing:    []*github.RepositoryRuleset{existing(1, "queue", queue)},
+			wantMatches: []string{"merge-queue=queue (listed in previousNames)"},
+		},
+		{
+			name:        "renamed with the same rules",
+			configured:  []*config.RepositoryRuleset{{Name: "merge-queue", Enforcement: "active", Conditions: mainOnly, Rules: mergeQueue}},
+			existing:    []*github.RepositoryRuleset{existing(1, "queue", queue)},
+			wantMatches: []string{"merge-queue=queue (same rules)"},
+		},
+		{
+			name:             "different rules",
+			configured:       []*config.RepositoryRuleset{{Name: "merge-queue", Enforcement: "evaluate", Conditions: mainOnly, Rules: mergeQueue}},
+			existing:         []*github.RepositoryRuleset{existing(1, "queue", queue)},
+			wantMatches:      []string{"merge-queue="},
+			wantUnconfigured: []string{"queue"},
+		},
+		{
+			name:        "ambiguous",
+			configured:  []*config.RepositoryRuleset{{Name: "merge-queue", Enforcement: "active", Conditions: mainOnly, Rules: mergeQueue}},
+			existing:    []*github.RepositoryRuleset{existing(1, "queue", queue), existing(2, "queue-copy", queue)},
+			wantMatches: []string{"merge-queue="},
+			// Both are identical to the configured ruleset, which is created; neither is deleted without a flag.
+			wantUnconfigured: []string{"queue", "queue-copy"},
+		},
+		{
+			name:           "left behind by an earlier rename",
+			configured:     []*config.RepositoryRuleset{{Name: "merge-queue", PreviousNames: []string{"queue"}, Enforcement: "evaluate"}},
+			existing:       []*github.RepositoryRuleset{existing(1, "merge-queue", evaluate), existing(2, "queue", queue)},
+			wantMatches:    []string{"merge-queue=merge-queue"},
+			wantSuperseded: []string{"queue"},
+		},
+		{
+			name:             "unrelated ruleset is unconfigured",
+			configured:       []*config.RepositoryRuleset{queue},
+			existing:         []*github.RepositoryRuleset{existing(1, "queue", queue), existing(2, "tags", &config.RepositoryRuleset{Enforcement: "active"})},
+			wantMatches:      []string{"queue=queue"},
+			wantUnconfigured: []string{"tags"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The list of rulesets only has their names and IDs.
+			var listed []*github.RepositoryRuleset
+			byID := map[int64]*github.RepositoryRuleset{}
+			for _, rs := range tc.existing {
+				listed = append(listed, &github.RepositoryRuleset{ID: rs.ID, Name: rs.Name})
+				byID[*rs.ID] = rs
+			}
+			details := func(rs *github.RepositoryRuleset) (*github.RepositoryRuleset, error) {
+				return byID[*rs.ID], nil
+			}
+
+			plan, err := planRulesets(tc.configured, listed, details)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var matches []string
+			for _, m := range plan.matches {
+				match := m.config.Name + "="
+				if m.existing != nil {
+					match += m.existing.Name
+				}
+				if m.renamed != "" {
+					match += " (" + m.renamed + ")"
+				}
+				matches = append(matches, match)
+			}
+			if !reflect.DeepEqual(matches, tc.wantMatches) {
+				t.Errorf("matches = %q, want %q", matches, tc.wantMatches)
+			}
+			var superseded []string
+			for _, rs := range plan.superseded {
+				superseded = append(superseded, rs.Name)
+			}
+			if !reflect.DeepEqual(superseded, tc.wantSuperseded) {
+				t.Errorf("superseded = %q, want %q", superseded, tc.wantSuperseded)
+			}
+			var unconfigured []string
+			for _, rs := range plan.unconfigured {
+				unconfigured = append(unconfigured, rs.Name)
+			}
+			if !reflect.DeepEqual(unconfigured, tc.wantUnconfigured) {
+				t.Errorf("unconfigured = %q, want %q", unconfigured, tc.wantUnconfigured)
+			}
+		})
+	}
+}
+
+// fakeRulesets serves the ruleset API calls of applyRulesets for repo example/repo, which has the rulesets
+// "queue" (ID 1) and "old-queue" (ID 2), and inherits "org-queue" (ID 3) from its organization.
+type fakeRulesets struct {
+	// requests records the mutating requests, as "METHOD path name", with the name of the ruleset sent.
+	requests []string
+}
+
+func (f *fakeRulesets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	if r.Method != http.MethodGet {
+		var body struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.requests = append(f.requests, strings.TrimSpace(key+" "+body.Name))
+	}
+
+	ruleset := func(id int64, name string) map[string]any {
+		return map[string]any{"id": id, "name": name, "target": "branch", "enforcement": "active",
+			"conditions": map[string]any{"ref_name": map[string]any{"include": []string{"~DEFAULT_BRANCH"}, "exclude": []string{}}}}
+	}
+	switch key {
+	case "GET /repos/example/repo/rulesets":
+		// The organization ruleset is listed whatever includes_parents is, to check that it is left alone.
+		json.NewEncoder(w).Encode([]any{
+			map[string]any{"id": 1, "name": "queue", "source_type": "Repository"},
+			map[string]any{"id": 2, "name": "old-queue", "source_type": "Repository"},
+			map[string]any{"id": 3, "name": "org-queue", "source_type": "Organization"},
+		})
+	case "GET /repos/example/repo/rulesets/1":
+		json.NewEncoder(w).Encode(ruleset(1, "queue"))
+	case "GET /repos/example/repo/rulesets/2":
+		json.NewEncoder(w).Encode(ruleset(2, "old-queue"))
+	case "POST /repos/example/repo/rulesets", "PUT /repos/example/repo/rulesets/1", "PUT /repos/example/repo/rulesets/2":
+		json.NewEncoder(w).Encode(map[string]any{})
+	case "DELETE /repos/example/repo/rulesets/1", "DELETE /repos/example/repo/rulesets/2":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	}
+}
+
+func TestApplyRulesetsRename(t *testing.T) {
+	fake := &fakeRulesets{}
+	client := newFakeClient(t, fake)
+
+	// "queue" was renamed to "merge-queue" in the configuration, and "old-queue" is a leftover of an
+	// earlier rename that created "queue" next to it; both are listed in previousNames.
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Rulesets: []*config.RepositoryRuleset{{
+			Name:          "merge-queue",
+			PreviousNames: []string{"queue", "old-queue"},
+			Enforcement:   "active",
+			Conditions:    &config.RulesetConditions{RefName: &config.RefNameCondition{Include: []string{"~DEFAULT_BRANCH"}}},
+		}},
+	}
+	if err := applyRulesets(t.Context(), client, cfg, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"PUT /repos/example/repo/rulesets/1 merge-queue",
+		"DELETE /repos/example/repo/rulesets/2",
+	}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+}
+
+func TestApplyRulesetsIdentical(t *testing.T) {
+	fake := &fakeRulesets{}
+
+	// "queue" and "old-queue" both have the rules of the configured ruleset, so there is no telling which one
+	// it was renamed from: it is created, and both are left alone without --prune-rulesets.
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Rulesets: []*config.RepositoryRuleset{{
+			Name:        "merge-queue",
+			Enforcement: "active",
+			Conditions:  &config.RulesetConditions{RefName: &config.RefNameCondition{Include: []string{"~DEFAULT_BRANCH"}}},
+		}},
+	}
+	if err := applyRulesets(t.Context(), newFakeClient(t, fake), cfg, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"POST /repos/example/repo/rulesets merge-queue"}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+}
+
+func TestApplyRulesetsPrune(t *testing.T) {
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Rulesets: []*config.RepositoryRuleset{{
+			Name:        "tags",
+			Target:      "tag",
+			Enforcement: "active",
+			Conditions:  &config.RulesetConditions{RefName: &config.RefNameCondition{Include: []string{"~ALL"}}},
+			Rules:       &config.RulesetRules{Update: &config.UpdateRule{}, Deletion: true},
+		}},
+	}
+	for _, tc := range []struct {
+		name  string
+		cfg   config.RepositoryConfig
+		prune bool
+		want  []string
+	}{
+		{name: "keep", cfg: cfg, want: []string{"POST /repos/example/repo/rulesets tags"}},
+		{
+			name:  "prune",
+			cfg:   cfg,
+			prune: true,
+			// The inherited organization ruleset cannot be deleted through the repo, so it is not pruned.
+			want: []string{
+				"POST /repos/example/repo/rulesets tags",
+				"DELETE /repos/example/repo/rulesets/1",
+				"DELETE /repos/example/repo/rulesets/2",
+			},
+		},
+		// Rulesets are not managed for repos that do not configure them.
+		{name: "not configured", cfg: config.RepositoryConfig{Owner: "example", Name: "repo"}, prune: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeRulesets{}
+			if err := applyRulesets(t.Context(), newFakeClient(t, fake), tc.cfg, false, tc.prune); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fake.requests, tc.want) {
+				t.Errorf("requests = %q, want %q", fake.requests, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	repoStatusDone    = "done"
+	repoStatusFailed  = "failed"
+	repoStatusSkipped = "skipped"
+)
+
+// ApplyState records the progress of an apply, so that an interrupted run can be resumed.
+type ApplyState struct {
+	Repos map[string]*RepoState `json:"repos"`
+}
+
+// RepoState is the outcome of applying the config to a single repo.
+type RepoState struct {
+	Status  string    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+func newApplyState() *ApplyState {
+	return &ApplyState{Repos: make(map[string]*RepoState)}
+}
+
+// loadApplyState reads the state file, returning an empty state if it does not exist.
+func loadApplyState(path string) (*ApplyState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return newApplyState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	state := newApplyState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if state.Repos == nil {
+		state.Repos = make(map[string]*RepoState)
+	}
+	return state, nil
+}
+
+// save writes the state atomically, so that a crash never leaves a truncated state file.
+func (s *ApplyState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// IsDone returns true if the repo was applied successfully in a previous run.
+func (s *ApplyState) IsDone(repo string) bool {
+	r, ok := s.Repos[repo]
+	return ok && r.Status == repoStatusDone
+}
+
+// Record stores the outcome of applying the config to repo.
+func (s *ApplyState) Record(repo string, err error) {
+	r := &RepoState{Status: repoStatusDone, Updated: time.Now().UTC()}
+	if err != nil {
+		r.Status = repoStatusFailed
+		r.Error = err.Error()
+	}
+	s.Repos[repo] = r
+}
+
+// repoResult is a row in the apply summary.
+type repoResult struct {
+	Repo   string
+	Status string
+	Err    error
+}
+
+// printSummary writes a table of the per-repo results, in the order the repos were applied.
+func printSummary(w io.Writer, results []repoResult) error {
+	counts := make(map[string]int)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tSTATUS\tERROR")
+	for _, r := range results {
+		counts[r.Status]++
+		msg := ""
+		if r.Err != nil {
+			msg = r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Repo, r.Status, msg)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d done, %d failed, %d skipped\n", counts[repoStatusDone], counts[repoStatusFailed], counts[repoStatusSkipped])
+	return err
+}
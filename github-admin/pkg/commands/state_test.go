@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.yaml.state.json")
+
+	state, err := loadApplyState(path)
+	if err != nil {
+		t.Fatalf("loadApplyState of missing file failed: %v", err)
+	}
+	if len(state.Repos) != 0 {
+		t.Fatalf("expected empty state, got %v", state.Repos)
+	}
+
+	state.Record("org/a", nil)
+	state.Record("org/b", fmt.Errorf("rate limited"))
+	if err := state.save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	loaded, err := loadApplyState(path)
+	if err != nil {
+		t.Fatalf("loadApplyState failed: %v", err)
+	}
+	if !loaded.IsDone("org/a") {
+		t.Errorf("expected org/a to be done")
+	}
+	if loaded.IsDone("org/b") {
+		t.Errorf("expected org/b not to be done")
+	}
+	if got := loaded.Repos["org/b"].Error; got != "rate limited" {
+		t.Errorf("expected error to be recorded for org/b, got %q", got)
+	}
+	if loaded.IsDone("org/c") {
+		t.Errorf("expected unknown repo not to be done")
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	var buf bytes.Buffer
+	results := []repoResult{
+		{Repo: "org/a", Status: repoStatusDone},
+		{Repo: "org/b", Status: repoStatusFailed, Err: fmt.Errorf("boom")},
+		{Repo: "org/c", Status: repoStatusSkipped},
+	}
+	if err := printSummary(&buf, results); err != nil {
+		t.Fatalf("printSummary failed: %v", err)
+	}
+
+	got := buf.String()
+	want := `REPO   STATUS   ERROR
+org/a  done     
+org/b  failed   boom
+org/c  skipped  
+
+1 done, 1 failed, 1 skipped
+`
+	if got != want {
+		t.Errorf("printSummary() =\n%s\nwant:\n%s", got, want)
+	}
+}
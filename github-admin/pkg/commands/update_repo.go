@@ -17,11 +17,10 @@ package commands
 import (
 	"context"
 	"fmt"
-	"os"
 
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
 	"github.com/google/go-github/v81/github"
 	"github.com/spf13/cobra"
-	"golang.org/x/oauth2"
 )
 
 type UpdateRepoOptions struct {
@@ -49,6 +48,8 @@ func BuildUpdateRepoCommand() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&opt.Owner, "owner", opt.Owner, "The github owner")
 	cmd.Flags().StringVar(&opt.Repo, "repo", opt.Repo, "The github repo name")
+	cmd.MarkFlagRequired("owner")
+	cmd.MarkFlagRequired("repo")
 	cmd.Flags().StringVar(&opt.GitHubToken, "token", opt.GitHubToken, "The github token (default from GITHUB_TOKEN env var)")
 
 	return cmd
@@ -61,19 +62,11 @@ func RunUpdateRepo(ctx context.Context, opt UpdateRepoOptions) error {
 	if opt.Repo == "" {
 		return fmt.Errorf("--repo is required")
 	}
-	if opt.GitHubToken == "" {
-		opt.GitHubToken = os.Getenv("GITHUB_TOKEN")
-	}
-	if opt.GitHubToken == "" {
-		return fmt.Errorf("--token or GITHUB_TOKEN env var is required")
+	client, err := githubclient.New(ctx, opt.GitHubToken)
+	if err != nil {
+		return err
 	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: opt.GitHubToken},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
-
 	fmt.Printf("Updating repo %s/%s...\n", opt.Owner, opt.Repo)
 
 	// 1. Enable Auto-Merge (prerequisite for Merge Queue)
@@ -85,7 +78,7 @@ func RunUpdateRepo(ctx context.Context, opt UpdateRepoOptions) error {
 		DeleteBranchOnMerge: github.Bool(false),
 	}
 
-	_, _, err := client.Repositories.Edit(ctx, opt.Owner, opt.Repo, repoReq)
+	_, _, err = client.Repositories.Edit(ctx, opt.Owner, opt.Repo, repoReq)
 	if err != nil {
 		return fmt.Errorf("failed to update repo settings: %w", err)
 	}
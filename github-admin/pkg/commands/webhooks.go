@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+// webhookUpdate is a configured webhook and the existing webhook it updates.
+type webhookUpdate struct {
+	config   config.Webhook
+	existing *github.Hook
+}
+
+// webhookPlan is how applyWebhooks reconciles the existing webhooks of a repository with its configuration.
+type webhookPlan struct {
+	create []config.Webhook
+	update []webhookUpdate
+}
+
+// planWebhooks matches the configured webhooks with the existing ones by URL.
+// Existing webhooks that already match their configuration are left alone.
+func planWebhooks(configured []config.Webhook, existing []*github.Hook) *webhookPlan {
+	byURL := make(map[string]*github.Hook)
+	for _, h := range existing {
+		byURL[h.GetConfig().GetURL()] = h
+	}
+	plan := &webhookPlan{}
+	for _, hook := range configured {
+		match, ok := byURL[hook.URL]
+		switch {
+		case !ok:
+			plan.create = append(plan.create, hook)
+		case !sameWebhook(match, hook):
+			plan.update = append(plan.update, webhookUpdate{config: hook, existing: match})
+		}
+	}
+	return plan
+}
+
+// sameWebhook reports whether the existing webhook h has the events, state and delivery settings of hook.
+// Secrets are not compared, as GitHub does not return them.
+func sameWebhook(h *github.Hook, hook config.Webhook) bool {
+	return slices.Equal(sortedStrings(h.Events), sortedStrings(webhookEvents(hook))) &&
+		h.GetActive() == webhookActive(hook) &&
+		h.GetConfig().GetContentType() == webhookContentType(hook) &&
+		h.GetConfig().GetInsecureSSL() == webhookInsecureSSL(hook)
+}
+
+func sortedStrings(s []string) []string {
+	return slices.Sorted(slices.Values(s))
+}
+
+func webhookEvents(hook config.Webhook) []string {
+	if len(hook.Events) == 0 {
+		return []string{"push"}
+	}
+	return hook.Events
+}
+
+func webhookActive(hook config.Webhook) bool {
+	return hook.Active == nil || *hook.Active
+}
+
+func webhookContentType(hook config.Webhook) string {
+	if hook.ContentType == "" {
+		return "json"
+	}
+	return hook.ContentType
+}
+
+// webhookInsecureSSL returns the insecure_ssl setting of hook as GitHub represents it.
+func webhookInsecureSSL(hook config.Webhook) string {
+	if hook.InsecureSSL {
+		return "1"
+	}
+	return "0"
+}
+
+// webhookConfig returns the delivery settings of hook, with the secret read from its SecretEnv if set.
+func webhookConfig(hook config.Webhook) (*github.HookConfig, error) {
+	res := &github.HookConfig{
+		URL:         github.Ptr(hook.URL),
+		ContentType: github.Ptr(webhookContentType(hook)),
+		InsecureSSL: github.Ptr(webhookInsecureSSL(hook)),
+	}
+	if hook.SecretEnv != "" {
+		secret := os.Getenv(hook.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("environment variable %s with the secret of webhook %s is not set", hook.SecretEnv, hook.URL)
+		}
+		res.Secret = github.Ptr(secret)
+	}
+	return res, nil
+}
+
+// applyWebhooks creates the configured webhooks that do not exist, and updates those that differ.
+func applyWebhooks(ctx context.Context, client *github.Client, cfg config.RepositoryConfig, dryRun bool) error {
+	existing, err := listWebhooks(ctx, client, cfg.Owner, cfg.Name)
+	if err != nil {
+		return err
+	}
+	plan := planWebhooks(cfg.Webhooks, existing)
+
+	for _, hook := range plan.create {
+		if dryRun {
+			fmt.Printf("[DryRun] Would create webhook %s for %s\n", hook.URL, cfg.Name)
+			continue
+		}
+		hookConfig, err := webhookConfig(hook)
+		if err != nil {
+			return err
+		}
+		req := &github.Hook{
+			Config: hookConfig,
+			Events: webhookEvents(hook),
+			Active: github.Ptr(webhookActive(hook)),
+		}
+		if _, _, err := client.Repositories.CreateHook(ctx, cfg.Owner, cfg.Name, req); err != nil {
+			return fmt.Errorf("failed to create webhook %s: %w", hook.URL, err)
+		}
+	}
+	for _, u := range plan.update {
+		if dryRun {
+			fmt.Printf("[DryRun] Would update webhook %s for %s\n", u.config.URL, cfg.Name)
+			continue
+		}
+		hookConfig, err := webhookConfig(u.config)
+		if err != nil {
+			return err
+		}
+		req := &github.Hook{
+			Events: webhookEvents(u.config),
+			Active: github.Ptr(webhookActive(u.config)),
+		}
+		if _, _, err := client.Repositories.EditHook(ctx, cfg.Owner, cfg.Name, u.existing.GetID(), req); err != nil {
+			return fmt.Errorf("failed to update webhook %s: %w", u.config.URL, err)
+		}
+		// The configuration is updated on its own, as it only changes the fields that are sent:
+		// an unmanaged secret is kept.
+		if _, _, err := client.Repositories.EditHookConfiguration(ctx, cfg.Owner, cfg.Name, u.existing.GetID(), hookConfig); err != nil {
+			return fmt.Errorf("failed to update the configuration of webhook %s: %w", u.config.URL, err)
+		}
+	}
+	return nil
+}
+
+func listWebhooks(ctx context.Context, client *github.Client, owner, repo string) ([]*github.Hook, error) {
+	var all []*github.Hook
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		hooks, resp, err := client.Repositories.ListHooks(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+		all = append(all, hooks...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// mapWebhook returns the configuration of h. Its secret cannot be exported; SecretEnv is left for the
+// caller to fill in.
+func mapWebhook(h *github.Hook) config.Webhook {
+	res := config.Webhook{
+		URL:         h.GetConfig().GetURL(),
+		Events:      h.Events,
+		ContentType: h.GetConfig().GetContentType(),
+		InsecureSSL: h.GetConfig().GetInsecureSSL() == "1",
+	}
+	if !h.GetActive() {
+		res.Active = github.Ptr(false)
+	}
+	return res
+}
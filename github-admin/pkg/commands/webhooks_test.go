@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/config"
+	"github.com/google/go-github/v81/github"
+)
+
+func TestPlanWebhooks(t *testing.T) {
+	existing := []*github.Hook{
+		{
+			ID:     github.Ptr(int64(1)),
+			Events: []string{"pull_request", "push"},
+			Active: github.Ptr(true),
+			Config: &github.HookConfig{URL: github.Ptr("https://bot.example.com/hook"), ContentType: github.Ptr("json"), InsecureSSL: github.Ptr("0")},
+		},
+		{
+			ID:     github.Ptr(int64(2)),
+			Events: []string{"push"},
+			Active: github.Ptr(true),
+			Config: &github.HookConfig{URL: github.Ptr("https://ci.example.com/hook"), ContentType: github.Ptr("form"), InsecureSSL: github.Ptr("0")},
+		},
+		{
+			ID:     github.Ptr(int64(3)),
+			Events: []string{"push"},
+			Active: github.Ptr(true),
+			Config: &github.HookConfig{URL: github.Ptr("https://unmanaged.example.com/hook")},
+		},
+	}
+	configured := []config.Webhook{
+		// Events are compared ignoring their order.
+		{URL: "https://bot.example.com/hook", Events: []string{"push", "pull_request"}, SecretEnv: "BOT_WEBHOOK_SECRET"},
+		// The content type defaults to json.
+		{URL: "https://ci.example.com/hook"},
+		{URL: "https://new.example.com/hook", Events: []string{"*"}},
+	}
+
+	plan := planWebhooks(configured, existing)
+	var got []string
+	for _, hook := range plan.create {
+		got = append(got, "create "+hook.URL)
+	}
+	for _, u := range plan.update {
+		got = append(got, "update "+u.existing.GetConfig().GetURL())
+	}
+	want := []string{"create https://new.example.com/hook", "update https://ci.example.com/hook"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planWebhooks() = %q, want %q", got, want)
+	}
+}
+
+// fakeWebhooks serves the webhooks of repo example/repo, recording the mutating requests and their bodies.
+type fakeWebhooks struct {
+	requests []string
+	bodies   map[string]map[string]any
+}
+
+func (f *fakeWebhooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	if key == "GET /repos/example/repo/hooks" {
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 1, "events": []string{"push"}, "active": true, "config": map[string]any{"url": "https://ci.example.com/hook", "content_type": "form", "insecure_ssl": "0", "secret": "********"}},
+		})
+		return
+	}
+	data, _ := io.ReadAll(r.Body)
+	var body map[string]any
+	_ = json.Unmarshal(data, &body)
+	f.requests = append(f.requests, key)
+	f.bodies[key] = body
+	json.NewEncoder(w).Encode(map[string]any{"id": 2})
+}
+
+func TestApplyWebhooks(t *testing.T) {
+	t.Setenv("BOT_WEBHOOK_SECRET", "s3cret")
+	cfg := config.RepositoryConfig{
+		Owner: "example",
+		Name:  "repo",
+		Webhooks: []config.Webhook{
+			{URL: "https://ci.example.com/hook"},
+			{URL: "https://bot.example.com/hook", Events: []string{"pull_request"}, SecretEnv: "BOT_WEBHOOK_SECRET"},
+		},
+	}
+
+	dryRun := &fakeWebhooks{bodies: map[string]map[string]any{}}
+	if err := applyWebhooks(t.Context(), newFakeClient(t, dryRun), cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(dryRun.requests) != 0 {
+		t.Errorf("dry run requests = %q, want none", dryRun.requests)
+	}
+
+	fake := &fakeWebhooks{bodies: map[string]map[string]any{}}
+	if err := applyWebhooks(t.Context(), newFakeClient(t, fake), cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"POST /repos/example/repo/hooks",
+		"PATCH /repos/example/repo/hooks/1",
+		"PATCH /repos/example/repo/hooks/1/config",
+	}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %q, want %q", fake.requests, want)
+	}
+
+	created := fake.bodies["POST /repos/example/repo/hooks"]["config"].(map[string]any)
+	if created["secret"] != "s3cret" || created["content_type"] != "json" {
+		t.Errorf("created webhook config = %v, want the secret from BOT_WEBHOOK_SECRET and content type json", created)
+	}
+	// The secret of the existing webhook is not managed, so it is not sent.
+	if _, ok := fake.bodies["PATCH /repos/example/repo/hooks/1/config"]["secret"]; ok {
+		t.Errorf("updated webhook config = %v, want no secret", fake.bodies["PATCH /repos/example/repo/hooks/1/config"])
+	}
+}
+
+func TestApplyWebhooksMissingSecret(t *testing.T) {
+	cfg := config.RepositoryConfig{
+		Owner:    "example",
+		Name:     "repo",
+		Webhooks: []config.Webhook{{URL: "https://bot.example.com/hook", SecretEnv: "UNSET_WEBHOOK_SECRET"}},
+	}
+	fake := &fakeWebhooks{bodies: map[string]map[string]any{}}
+	if err := applyWebhooks(t.Context(), newFakeClient(t, fake), cfg, false); err == nil {
+		t.Error("applyWebhooks() succeeded, want an error for the unset secret")
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("requests = %q, want none", fake.requests)
+	}
+}
+
+func TestMapWebhook(t *testing.T) {
+	h := &github.Hook{
+		Events: []string{"pull_request"},
+		Active: github.Ptr(false),
+		Config: &github.HookConfig{URL: github.Ptr("https://bot.example.com/hook"), ContentType: github.Ptr("json"), InsecureSSL: github.Ptr("1"), Secret: github.Ptr("********")},
+	}
+	got := mapWebhook(h)
+	want := config.Webhook{
+		URL:         "https://bot.example.com/hook",
+		Events:      []string{"pull_request"},
+		ContentType: "json",
+		Active:      github.Ptr(false),
+		InsecureSSL: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mapWebhook() = %+v, want %+v", got, want)
+	}
+}
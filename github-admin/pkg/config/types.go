@@ -51,6 +51,40 @@ type RepositoryConfig struct {
 	// Rulesets defines the repository rulesets.
 	// +optional
 	Rulesets []*RepositoryRuleset `json:"rulesets,omitempty"`
+
+	// Community defines the contribution scaffolding in the .github directory.
+	// Changes are proposed in a pull request, never pushed to the default branch.
+	// +optional
+	Community *CommunityFiles `json:"community,omitempty"`
+
+	// Discussions configures GitHub Discussions.
+	// +optional
+	Discussions *DiscussionsConfig `json:"discussions,omitempty"`
+
+	// Labels are the issue and pull request labels of the repository. Existing labels that are not listed
+	// are left alone, unless apply runs with --prune-labels. Labels are not managed if this is not set.
+	// +optional
+	Labels []Label `json:"labels,omitempty"`
+
+	// Milestones are the milestones of the repository, matched by title. Existing milestones that are not
+	// listed are left alone.
+	// +optional
+	Milestones []Milestone `json:"milestones,omitempty"`
+
+	// Permissions are the teams and users with access to the repository, and their roles.
+	// +optional
+	Permissions *PermissionsConfig `json:"permissions,omitempty"`
+
+	// Webhooks are the webhooks of the repository, matched by URL. Existing webhooks that are not listed
+	// are left alone.
+	// +optional
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+
+	// RequiredApps are the slugs of the GitHub Apps that must be installed on the repository,
+	// e.g. "github-automation". Apply adds the repository to the installation of the app in the owner
+	// organization; the app itself must already be installed there, as that needs an owner's approval.
+	// +optional
+	RequiredApps []string `json:"requiredApps,omitempty"`
 }
 
 type RepositorySettings struct {
@@ -79,7 +113,13 @@ type BranchProtection struct {
 }
 
 type RepositoryRuleset struct {
-	Name        string             `json:"name,omitempty"`
+	Name string `json:"name,omitempty"`
+
+	// PreviousNames are names the ruleset had before it was renamed. An existing ruleset with one of these
+	// names is renamed and updated, rather than left behind next to a new one.
+	// +optional
+	PreviousNames []string `json:"previousNames,omitempty"`
+
 	Target      string             `json:"target,omitempty"`
 	Enforcement string             `json:"enforcement,omitempty"`
 	Conditions  *RulesetConditions `json:"conditions,omitempty"`
@@ -96,9 +136,94 @@ type RefNameCondition struct {
 }
 
 type RulesetRules struct {
+	// Creation restricts creating matching refs, e.g. tags, to those who can bypass the ruleset.
+	// +optional
+	Creation bool `json:"creation,omitempty"`
+
+	// Update restricts updating matching refs to those who can bypass the ruleset.
+	// +optional
+	Update *UpdateRule `json:"update,omitempty"`
+
+	// Deletion restricts deleting matching refs to those who can bypass the ruleset.
+	// +optional
+	Deletion bool `json:"deletion,omitempty"`
+
+	// NonFastForward prevents force pushes to matching refs.
+	// +optional
+	NonFastForward bool `json:"nonFastForward,omitempty"`
+
+	// +optional
+	RequiredLinearHistory bool `json:"requiredLinearHistory,omitempty"`
+
+	// +optional
+	RequiredSignatures bool `json:"requiredSignatures,omitempty"`
+
+	// +optional
+	PullRequest *PullRequestRule `json:"pullRequest,omitempty"`
+
+	// +optional
+	RequiredStatusChecks *RequiredStatusChecksRule `json:"requiredStatusChecks,omitempty"`
+
+	// TagNamePattern restricts the names of the tags that can be pushed.
+	// +optional
+	TagNamePattern *PatternRule `json:"tagNamePattern,omitempty"`
+
+	// +optional
 	MergeQueue *MergeQueueRule `json:"mergeQueue,omitempty"`
 }
 
+type UpdateRule struct {
+	// UpdateAllowsFetchAndMerge lets pull requests be updated with the changes of their base branch.
+	// +optional
+	UpdateAllowsFetchAndMerge bool `json:"updateAllowsFetchAndMerge,omitempty"`
+}
+
+type PullRequestRule struct {
+	// AllowedMergeMethods are merge, squash and rebase; defaults to all of them.
+	// +optional
+	AllowedMergeMethods          []string `json:"allowedMergeMethods,omitempty"`
+	DismissStaleReviewsOnPush    bool     `json:"dismissStaleReviewsOnPush,omitempty"`
+	RequireCodeOwnerReview       bool     `json:"requireCodeOwnerReview,omitempty"`
+	RequireLastPushApproval      bool     `json:"requireLastPushApproval,omitempty"`
+	RequiredApprovingReviewCount int      `json:"requiredApprovingReviewCount,omitempty"`
+	// RequiredReviewThreadResolution requires all review conversations to be resolved before merging.
+	RequiredReviewThreadResolution bool `json:"requiredReviewThreadResolution,omitempty"`
+}
+
+type RequiredStatusChecksRule struct {
+	Checks []StatusCheck `json:"checks,omitempty"`
+
+	// Strict requires branches to be up to date with the base branch before merging.
+	// +optional
+	Strict bool `json:"strict,omitempty"`
+
+	// DoNotEnforceOnCreate allows creating matching refs whose commits have not passed the checks.
+	// +optional
+	DoNotEnforceOnCreate bool `json:"doNotEnforceOnCreate,omitempty"`
+}
+
+type StatusCheck struct {
+	// Context is the name of the check.
+	Context string `json:"context"`
+
+	// IntegrationID is the ID of the GitHub App that must report the check; any app can if it is not set.
+	// +optional
+	IntegrationID *int64 `json:"integrationID,omitempty"`
+}
+
+type PatternRule struct {
+	// Operator is starts_with, ends_with, contains or regex.
+	Operator string `json:"operator"`
+	Pattern  string `json:"pattern"`
+
+	// Negate makes the rule fail if the pattern matches.
+	// +optional
+	Negate bool `json:"negate,omitempty"`
+
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
 type MergeQueueRule struct {
 	CheckResponseTimeoutMinutes  int    `json:"checkResponseTimeoutMinutes,omitempty"`
 	GroupingStrategy             string `json:"groupingStrategy,omitempty"`
@@ -119,3 +244,103 @@ type RequiredPullRequestReviews struct {
 	RequireCodeOwnerReviews      bool `json:"requireCodeOwnerReviews,omitempty"`
 	RequiredApprovingReviewCount int  `json:"requiredApprovingReviewCount,omitempty"`
 }
+
+type CommunityFiles struct {
+	// IssueTemplates maps file names in .github/ISSUE_TEMPLATE (e.g. bug_report.yml or config.yml) to their content.
+	// Templates that exist in the repository but are not listed are left alone.
+	// +optional
+	IssueTemplates map[string]string `json:"issueTemplates,omitempty"`
+
+	// PullRequestTemplate is the content of .github/pull_request_template.md.
+	// +optional
+	PullRequestTemplate *string `json:"pullRequestTemplate,omitempty"`
+
+	// Branch is the branch the changes are pushed to; it is reset to the default branch first.
+	// Defaults to github-admin/community.
+	// +optional
+	Branch string `json:"branch,omitempty"`
+}
+
+type DiscussionsConfig struct {
+	// Enabled turns GitHub Discussions on or off.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Categories are the discussion categories the repository should have.
+	// GitHub has no API for creating categories, so apply reports the ones that are missing.
+	// +optional
+	Categories []DiscussionCategory `json:"categories,omitempty"`
+}
+
+type DiscussionCategory struct {
+	Name string `json:"name"`
+}
+
+type PermissionsConfig struct {
+	// Teams maps the slugs of teams of the owner organization to their role on the repository:
+	// read, triage, write, maintain, admin or the name of a custom role.
+	// Teams that are not listed keep their access, unless apply runs with --prune-permissions.
+	// Teams are not managed if this is not set.
+	// +optional
+	Teams map[string]string `json:"teams,omitempty"`
+
+	// Users maps the logins of direct collaborators to their role on the repository. Users that are not
+	// members of the organization are invited. Users that are not listed keep their access, unless apply runs
+	// with --prune-permissions. Users are not managed if this is not set.
+	// +optional
+	Users map[string]string `json:"users,omitempty"`
+}
+
+type Label struct {
+	// Name is the name of the label; GitHub matches label names case-insensitively.
+	Name string `json:"name"`
+
+	// Color is the hexadecimal color of the label, without the leading #, e.g. "d73a4a".
+	Color string `json:"color"`
+
+	// +optional
+	Description *string `json:"description,omitempty"`
+}
+
+type Milestone struct {
+	Title string `json:"title"`
+
+	// +optional
+	Description *string `json:"description,omitempty"`
+
+	// State is "open" (default) or "closed".
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// DueOn is the due date of the milestone, as YYYY-MM-DD.
+	// +optional
+	DueOn string `json:"dueOn,omitempty"`
+}
+
+type Webhook struct {
+	// URL is the payload URL that GitHub delivers the events to.
+	URL string `json:"url"`
+
+	// Events are the events that trigger the webhook, e.g. "pull_request" or "*" for all of them.
+	// Defaults to ["push"].
+	// +optional
+	Events []string `json:"events,omitempty"`
+
+	// ContentType is "json" (default) or "form".
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+
+	// SecretEnv is the name of the environment variable that holds the secret of the webhook when apply runs,
+	// so that the secret itself is never part of the config. GitHub does not return secrets, so the secret
+	// is only sent when the webhook is created or updated. If not set, the secret is not managed.
+	// +optional
+	SecretEnv string `json:"secretEnv,omitempty"`
+
+	// Active defaults to true.
+	// +optional
+	Active *bool `json:"active,omitempty"`
+
+	// InsecureSSL disables the verification of the certificate of the payload URL.
+	// +optional
+	InsecureSSL bool `json:"insecureSSL,omitempty"`
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package githubclient creates the authenticated GitHub API client shared by our tools.
+package githubclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/google/go-github/v81/github"
+	"golang.org/x/oauth2"
+)
+
+// New returns a GitHub client authenticated with token, or with the GITHUB_TOKEN
+// environment variable if token is empty.
+func New(ctx context.Context, token string) (*github.Client, error) {
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("--token or GITHUB_TOKEN env var is required")
+	}
+
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	tc := oauth2.NewClient(ctx, ts)
+	return github.NewClient(tc), nil
+}
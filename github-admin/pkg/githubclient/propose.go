@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githubclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// Proposal is a set of files to bring to their desired content in a pull request.
+type Proposal struct {
+	Owner string
+	Repo  string
+
+	// Branch is the head branch of the pull request; it is reset to the default branch on every proposal,
+	// so that the pull request only holds the latest desired content.
+	Branch string
+
+	// Files is the desired content of each file, keyed by path in the repository.
+	Files map[string]string
+
+	// Title is the title of the pull request.
+	Title string
+
+	// Body introduces the list of changed files in the description of the pull request.
+	Body string
+
+	// DryRun only compares the files, without pushing them or opening a pull request.
+	DryRun bool
+}
+
+// FileChange is a file whose content on the default branch differs from the desired content.
+type FileChange struct {
+	Path string
+	// Kind is added or modified.
+	Kind string
+}
+
+// ProposalResult describes the outcome of Propose.
+type ProposalResult struct {
+	// Changes are the files that differ from the default branch, sorted by path; empty if the repository is up to date.
+	Changes []FileChange
+
+	// URL is the URL of the pull request, unless there are no changes or it is a dry run.
+	URL string
+
+	// Updated is true if an open pull request was updated, rather than a new one opened.
+	Updated bool
+}
+
+// Propose pushes the files of p that differ from the default branch to p.Branch, and opens a pull request
+// from it, or updates the open one. If no file differs, nothing is done.
+func Propose(ctx context.Context, client *github.Client, p Proposal) (*ProposalResult, error) {
+	repo, _, err := client.Repositories.Get(ctx, p.Owner, p.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo: %w", err)
+	}
+	base := repo.GetDefaultBranch()
+
+	result := &ProposalResult{}
+	paths := make([]string, 0, len(p.Files))
+	for path := range p.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		current, exists, err := FileContent(ctx, client, p.Owner, p.Repo, path, base)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			result.Changes = append(result.Changes, FileChange{Path: path, Kind: "added"})
+		} else if current != p.Files[path] {
+			result.Changes = append(result.Changes, FileChange{Path: path, Kind: "modified"})
+		}
+	}
+	if len(result.Changes) == 0 || p.DryRun {
+		return result, nil
+	}
+
+	baseRef, _, err := client.Git.GetRef(ctx, p.Owner, p.Repo, "heads/"+base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch %s: %w", base, err)
+	}
+	baseSHA := baseRef.GetObject().GetSHA()
+	if _, _, err := client.Git.GetRef(ctx, p.Owner, p.Repo, "heads/"+p.Branch); err == nil {
+		if _, _, err := client.Git.UpdateRef(ctx, p.Owner, p.Repo, "heads/"+p.Branch, github.UpdateRef{SHA: baseSHA, Force: github.Ptr(true)}); err != nil {
+			return nil, fmt.Errorf("failed to reset branch %s: %w", p.Branch, err)
+		}
+	} else if IsNotFound(err) {
+		if _, _, err := client.Git.CreateRef(ctx, p.Owner, p.Repo, github.CreateRef{Ref: "refs/heads/" + p.Branch, SHA: baseSHA}); err != nil {
+			return nil, fmt.Errorf("failed to create branch %s: %w", p.Branch, err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to get branch %s: %w", p.Branch, err)
+	}
+
+	for _, change := range result.Changes {
+		opts := &github.RepositoryContentFileOptions{
+			Message: github.Ptr("Update " + change.Path),
+			Content: []byte(p.Files[change.Path]),
+			Branch:  github.Ptr(p.Branch),
+		}
+		file, _, _, err := client.Repositories.GetContents(ctx, p.Owner, p.Repo, change.Path, &github.RepositoryContentGetOptions{Ref: p.Branch})
+		switch {
+		case err == nil:
+			opts.SHA = file.SHA
+		case !IsNotFound(err):
+			return nil, fmt.Errorf("failed to get %s: %w", change.Path, err)
+		}
+		if _, _, err := client.Repositories.UpdateFile(ctx, p.Owner, p.Repo, change.Path, opts); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", change.Path, err)
+		}
+	}
+
+	open, _, err := client.PullRequests.List(ctx, p.Owner, p.Repo, &github.PullRequestListOptions{
+		State: "open",
+		Head:  p.Owner + ":" + p.Branch,
+		Base:  base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(open) > 0 {
+		result.URL = open[0].GetHTMLURL()
+		result.Updated = true
+		return result, nil
+	}
+
+	var body strings.Builder
+	body.WriteString(p.Body)
+	body.WriteString("\n\n")
+	for _, change := range result.Changes {
+		fmt.Fprintf(&body, "- `%s`\n", change.Path)
+	}
+	pr, _, err := client.PullRequests.Create(ctx, p.Owner, p.Repo, &github.NewPullRequest{
+		Title: github.Ptr(p.Title),
+		Head:  github.Ptr(p.Branch),
+		Base:  github.Ptr(base),
+		Body:  github.Ptr(body.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	result.URL = pr.GetHTMLURL()
+	return result, nil
+}
+
+// Paths returns the paths of the changes.
+func (r *ProposalResult) Paths() []string {
+	var paths []string
+	for _, change := range r.Changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// FileContent returns the content of the file at path on ref, and false if it does not exist.
+func FileContent(ctx context.Context, client *github.Client, owner, repo, path, ref string) (string, bool, error) {
+	file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file == nil {
+		return "", false, fmt.Errorf("%s is a directory", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return content, true, nil
+}
+
+// IsNotFound returns true if err is a 404 response from the GitHub API.
+func IsNotFound(err error) bool {
+	var resp *github.ErrorResponse
+	return errors.As(err, &resp) && resp.Response != nil && resp.Response.StatusCode == http.StatusNotFound
+}
@@ -20,12 +20,17 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
-	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/rules"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/lint"
 	"github.com/spf13/cobra"
 )
 
 func BuildRootCommand() *cobra.Command {
+	output := string(findings.FormatText)
+	configFile := filepath.Join(".ap", "kubelint.yaml")
+	fix := false
+
 	cmd := &cobra.Command{
 		Use:           "kubelint [file...]",
 		Short:         "kubelint is a linter for Kubernetes manifests",
@@ -35,61 +40,48 @@ func BuildRootCommand() *cobra.Command {
 			if len(args) == 0 {
 				return fmt.Errorf("no files specified")
 			}
+			format, err := findings.ParseFormat(output)
+			if err != nil {
+				return err
+			}
 
-			allRules := rules.AllRules()
-			var allDiagnostics []rules.Diagnostic
-
-			for _, arg := range args {
-				err := filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
-					if err != nil {
-						return err
-					}
-					if info.IsDir() {
-						return nil
-					}
-					ext := filepath.Ext(path)
-					if ext != ".yaml" && ext != ".yml" {
-						return nil
-					}
-
-					f, err := os.Open(path)
-					if err != nil {
-						return fmt.Errorf("failed to open %s: %w", path, err)
-					}
-					defer f.Close()
-
-					objs, err := manifests.Parse(f)
-					if err != nil {
-						return fmt.Errorf("failed to parse %s: %w", path, err)
-					}
-
-					for _, obj := range objs {
-						for _, rule := range allRules {
-							diags := rule.Check(obj)
-							for i := range diags {
-								diags[i].Message = fmt.Sprintf("%s:%d: %s [%s]", path, diags[i].Line, diags[i].Message, diags[i].RuleName)
-								allDiagnostics = append(allDiagnostics, diags[i])
-							}
-						}
-					}
-					return nil
-				})
+			cfg, err := config.LoadFile(configFile)
+			if err != nil {
+				return err
+			}
+			if fix {
+				changed, err := lint.Fix(args, cfg)
 				if err != nil {
 					return err
 				}
+				for _, path := range changed {
+					fmt.Fprintf(os.Stderr, "Fixed %s\n", path)
+				}
+			}
+			found, err := lint.Paths(args, cfg)
+			if err != nil {
+				return err
 			}
 
-			if len(allDiagnostics) > 0 {
-				for _, d := range allDiagnostics {
-					fmt.Fprintln(os.Stderr, d.Message)
-				}
+			// Text goes to stderr like any other error output; the other formats are reports for tools to consume.
+			out := os.Stdout
+			if format == findings.FormatText {
+				out = os.Stderr
+			}
+			if err := findings.Write(out, format, "kubelint", found); err != nil {
+				return err
+			}
+			if findings.HasErrors(found) {
 				return fmt.Errorf("lint failures found")
 			}
-
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVar(&output, "output", output, "Output format: text, github, sarif or junit")
+	cmd.Flags().BoolVar(&fix, "fix", fix, "Fix the findings that can be fixed mechanically, editing the manifests in place, before linting")
+	cmd.Flags().StringVar(&configFile, "config", configFile, "The kubelint config file enabling and disabling rules, if it exists")
+
 	return cmd
 }
 
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config loads the configuration of kubelint, .ap/kubelint.yaml.
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/rules"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the contents of .ap/kubelint.yaml.
+type Config struct {
+	// Rules sets the mode of rules by name: "ignore", "warn" or "error". Rules that are not listed are errors,
+	// except the opt-in rules (e.g. container-resources), which are ignored.
+	Rules map[string]string `json:"rules,omitempty"`
+
+	// Labels are the labels that the required-labels rule requires on workloads and Services
+	// (default: app.kubernetes.io/name).
+	Labels []string `json:"labels,omitempty"`
+
+	// CRDs lists the files and directories (searched for .yaml and .yml files) of the CustomResourceDefinitions
+	// that the crd-schema rule validates custom resources against, in addition to the CRDs in the manifests.
+	// They are relative to the directory of .ap, and default to config/crd; paths that do not exist are skipped.
+	CRDs []string `json:"crds,omitempty"`
+
+	// root is the directory of .ap, which CRDs are relative to.
+	root string
+}
+
+// DefaultCRDs are the paths of the CRDs, if not configured: where kubebuilder writes them.
+var DefaultCRDs = []string{"config/crd"}
+
+// LoadConfig loads .ap/kubelint.yaml from root, returning an empty config if it does not exist.
+func LoadConfig(root string) (*Config, error) {
+	return LoadFile(filepath.Join(root, ".ap", "kubelint.yaml"))
+}
+
+// LoadFile loads the kubelint config at path, returning an empty config if it does not exist.
+func LoadFile(configFile string) (*Config, error) {
+	config := Config{root: filepath.Dir(filepath.Dir(configFile))}
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return &config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+
+	names := rules.Names()
+	sort.Strings(names)
+	for name, mode := range config.Rules {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("error in %s: unknown rule %q (rules: %v)", configFile, name, names)
+		}
+		if !slices.Contains([]string{"ignore", "warn", "error"}, mode) {
+			return nil, fmt.Errorf("error in %s: mode of rule %s must be ignore, warn or error, not %q", configFile, name, mode)
+		}
+	}
+	return &config, nil
+}
+
+// Severity returns the severity of the findings of rule, a rules.Rule or rules.SetRule,
+// and false if the rule is ignored. A nil config uses the defaults.
+func (c *Config) Severity(rule interface{ Name() string }) (findings.Severity, bool) {
+	mode := "error"
+	if rules.IsOptIn(rule) {
+		mode = "ignore"
+	}
+	if c != nil && c.Rules[rule.Name()] != "" {
+		mode = c.Rules[rule.Name()]
+	}
+	switch mode {
+	case "ignore":
+		return "", false
+	case "warn":
+		return findings.SeverityWarning, true
+	default:
+		return findings.SeverityError, true
+	}
+}
+
+// CRDFiles returns the .yaml and .yml files under the CRD paths. A nil config uses the defaults, relative to the
+// current directory.
+func (c *Config) CRDFiles() ([]string, error) {
+	paths := DefaultCRDs
+	root := ""
+	if c != nil {
+		root = c.root
+		if c.CRDs != nil {
+			paths = c.CRDs
+		}
+	}
+
+	var files []string
+	for _, p := range paths {
+		err := filepath.WalkDir(filepath.Join(root, p), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if ext := filepath.Ext(path); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
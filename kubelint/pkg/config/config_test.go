@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/rules"
+)
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	cfg, err := LoadConfig(root)
+	if err != nil || cfg.Rules != nil {
+		t.Fatalf("LoadConfig() without a config = %+v, %v, want an empty config", cfg, err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: "rules:\n  container-resources: warn\n  ingress-class: ignore\nlabels: [team]\n"},
+		{name: "unknown rule", content: "rules:\n  container-resource: warn\n", wantErr: `unknown rule "container-resource"`},
+		{name: "invalid mode", content: "rules:\n  container-resources: on\n", wantErr: "must be ignore, warn or error"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.MkdirAll(filepath.Join(root, ".ap"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, ".ap", "kubelint.yaml"), []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(root)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadConfig() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	cfg := &Config{Rules: map[string]string{"container-resources": "warn", "ingress-class": "ignore"}}
+	for _, tc := range []struct {
+		cfg     *Config
+		rule    rules.Rule
+		wantSev findings.Severity
+		wantOK  bool
+	}{
+		{cfg: nil, rule: &rules.IngressClass{}, wantSev: findings.SeverityError, wantOK: true},
+		{cfg: nil, rule: &rules.ContainerResources{}},
+		{cfg: cfg, rule: &rules.ContainerResources{}, wantSev: findings.SeverityWarning, wantOK: true},
+		{cfg: cfg, rule: &rules.IngressClass{}},
+		{cfg: cfg, rule: &rules.StatefulSetUpdateStrategy{}, wantSev: findings.SeverityError, wantOK: true},
+	} {
+		sev, ok := tc.cfg.Severity(tc.rule)
+		if sev != tc.wantSev || ok != tc.wantOK {
+			t.Errorf("Severity(%s) with %+v = %q, %v, want %q, %v", tc.rule.Name(), tc.cfg, sev, ok, tc.wantSev, tc.wantOK)
+		}
+	}
+}
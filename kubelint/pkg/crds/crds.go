@@ -0,0 +1,345 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crds validates custom resources against the openAPIV3Schema of their CustomResourceDefinitions,
+// as the API server would when they are applied.
+package crds
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the subset of an OpenAPI v3 schema, as used in CRDs, that custom resources are checked against.
+type Schema struct {
+	Type                 string             `yaml:"type"`
+	Properties           map[string]*Schema `yaml:"properties"`
+	Required             []string           `yaml:"required"`
+	Items                *Schema            `yaml:"items"`
+	AdditionalProperties *Additional        `yaml:"additionalProperties"`
+	Enum                 []string           `yaml:"enum"`
+	Nullable             bool               `yaml:"nullable"`
+	Minimum              *float64           `yaml:"minimum"`
+	Maximum              *float64           `yaml:"maximum"`
+	MinLength            *int               `yaml:"minLength"`
+	MaxLength            *int               `yaml:"maxLength"`
+	MinItems             *int               `yaml:"minItems"`
+	MaxItems             *int               `yaml:"maxItems"`
+	Pattern              string             `yaml:"pattern"`
+
+	PreserveUnknownFields bool `yaml:"x-kubernetes-preserve-unknown-fields"`
+	IntOrString           bool `yaml:"x-kubernetes-int-or-string"`
+	EmbeddedResource      bool `yaml:"x-kubernetes-embedded-resource"`
+}
+
+// Additional is the additionalProperties of a schema: either a schema for the values of a map, or whether
+// properties that are not listed are allowed.
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalYAML decodes additionalProperties, a boolean or a schema.
+func (a *Additional) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&a.Allowed)
+	}
+	a.Allowed = true
+	return node.Decode(&a.Schema)
+}
+
+// definition is the part of a CustomResourceDefinition that custom resources are checked against.
+type definition struct {
+	Spec struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []struct {
+			Name   string `yaml:"name"`
+			Served bool   `yaml:"served"`
+			Schema struct {
+				OpenAPIV3Schema *Schema `yaml:"openAPIV3Schema"`
+			} `yaml:"schema"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// CRD is a CustomResourceDefinition.
+type CRD struct {
+	Group string
+	Kind  string
+	// Versions are the schemas of the versions of the CRD by name; a version that is not served has a nil schema.
+	Versions map[string]*Schema
+	// Served are the names of the versions that are served, in the order of the CRD.
+	Served []string
+}
+
+// Set is the CRDs custom resources are checked against, by group and kind.
+type Set map[string]*CRD
+
+// IsCRD reports whether obj is a CustomResourceDefinition.
+func IsCRD(obj *manifests.Object) bool {
+	kind, _, _ := obj.Kind()
+	apiVersion, _, _ := obj.ApiVersion()
+	return kind == "CustomResourceDefinition" && strings.HasPrefix(apiVersion, "apiextensions.k8s.io/")
+}
+
+// Add adds the CRDs among objs to the set. Objects that are not CRDs are skipped.
+func (s Set) Add(objs []*manifests.Object) error {
+	for _, obj := range objs {
+		if !IsCRD(obj) {
+			continue
+		}
+		var def definition
+		if err := obj.Node.Decode(&def); err != nil {
+			return fmt.Errorf("invalid CustomResourceDefinition %s: %w", obj.Name(), err)
+		}
+		crd := &CRD{Group: def.Spec.Group, Kind: def.Spec.Names.Kind, Versions: map[string]*Schema{}}
+		for _, v := range def.Spec.Versions {
+			if !v.Served {
+				crd.Versions[v.Name] = nil
+				continue
+			}
+			schema := v.Schema.OpenAPIV3Schema
+			if schema == nil {
+				// Without a schema, anything goes (apiextensions.k8s.io/v1beta1 CRDs).
+				schema = &Schema{PreserveUnknownFields: true}
+			}
+			crd.Versions[v.Name] = schema
+			crd.Served = append(crd.Served, v.Name)
+		}
+		s[crd.Group+"/"+crd.Kind] = crd
+	}
+	return nil
+}
+
+// Problem is a way in which a custom resource does not match the schema of its CRD.
+type Problem struct {
+	Line    int
+	Message string
+}
+
+// Validate checks obj against the schema of its CRD. It returns nil if obj is not a custom resource of a CRD in
+// the set. apiVersion, kind and metadata are left to the API server, which validates them for all objects.
+func (s Set) Validate(obj *manifests.Object) []Problem {
+	kind, _, _ := obj.Kind()
+	apiVersion, _, _ := obj.ApiVersion()
+	group, version, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		return nil
+	}
+	crd := s[group+"/"+kind]
+	if crd == nil {
+		return nil
+	}
+
+	line, _ := obj.GetLine("apiVersion")
+	schema, defined := crd.Versions[version]
+	if !defined {
+		return []Problem{{Line: line, Message: fmt.Sprintf("%s is not a version of %s.%s (served versions: %s)", version, kind, group, strings.Join(crd.Served, ", "))}}
+	}
+	if schema == nil {
+		return []Problem{{Line: line, Message: fmt.Sprintf("version %s of %s.%s is not served (served versions: %s)", version, kind, group, strings.Join(crd.Served, ", "))}}
+	}
+
+	root := obj.Node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	v := &validator{}
+	v.object(schema, root, "", []string{"apiVersion", "kind", "metadata"})
+	return v.problems
+}
+
+// validator collects the problems of a custom resource.
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) report(node *yaml.Node, path string, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if path != "" {
+		message = path + ": " + message
+	}
+	v.problems = append(v.problems, Problem{Line: node.Line, Message: message})
+}
+
+// validate checks node, at path, against schema.
+func (v *validator) validate(schema *Schema, node *yaml.Node, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.ShortTag() == "!!null" {
+		// Null values of fields that are not nullable are dropped by the API server.
+		return
+	}
+	if schema.IntOrString {
+		if tag := node.ShortTag(); tag != "!!int" && tag != "!!str" {
+			v.report(node, path, "must be an integer or a string, not %s", describe(node))
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			v.report(node, path, "must be an object, not %s", describe(node))
+			return
+		}
+		v.object(schema, node, path, nil)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.report(node, path, "must be an array, not %s", describe(node))
+			return
+		}
+		if schema.MinItems != nil && len(node.Content) < *schema.MinItems {
+			v.report(node, path, "must have at least %d items, not %d", *schema.MinItems, len(node.Content))
+		}
+		if schema.MaxItems != nil && len(node.Content) > *schema.MaxItems {
+			v.report(node, path, "must have at most %d items, not %d", *schema.MaxItems, len(node.Content))
+		}
+		if schema.Items != nil {
+			for i, item := range node.Content {
+				v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case "string":
+		if node.ShortTag() != "!!str" {
+			v.report(node, path, "must be a string, not %s; quote it", describe(node))
+			return
+		}
+		v.scalar(schema, node, path)
+		length := utf8.RuneCountInString(node.Value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			v.report(node, path, "must be at least %d characters long", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			v.report(node, path, "must be at most %d characters long", *schema.MaxLength)
+		}
+		// Patterns are ECMA-262 regular expressions; those that Go cannot compile are not checked.
+		if re, err := regexp.Compile(schema.Pattern); schema.Pattern != "" && err == nil && !re.MatchString(node.Value) {
+			v.report(node, path, "%q does not match the pattern %s", node.Value, schema.Pattern)
+		}
+	case "integer", "number":
+		tag := node.ShortTag()
+		if tag != "!!int" && (schema.Type == "integer" || tag != "!!float") {
+			v.report(node, path, "must be %s, not %s", article(schema.Type), describe(node))
+			return
+		}
+		v.scalar(schema, node, path)
+		// Integers in other bases (e.g. 0x1F) are parsed as integers; values that cannot be parsed are NaN,
+		// which is neither below a minimum nor above a maximum.
+		n, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			n = math.NaN()
+			if i, err := strconv.ParseInt(node.Value, 0, 64); err == nil {
+				n = float64(i)
+			}
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			v.report(node, path, "must be at least %v, not %s", *schema.Minimum, node.Value)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			v.report(node, path, "must be at most %v, not %s", *schema.Maximum, node.Value)
+		}
+	case "boolean":
+		if node.ShortTag() != "!!bool" {
+			v.report(node, path, "must be a boolean, not %s", describe(node))
+		}
+	default:
+		if node.Kind == yaml.MappingNode {
+			v.object(schema, node, path, nil)
+		}
+	}
+}
+
+// scalar checks the value of node, a scalar, against the enum of schema.
+func (v *validator) scalar(schema *Schema, node *yaml.Node, path string) {
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, node.Value) {
+		v.report(node, path, "must be one of %s, not %q", strings.Join(schema.Enum, ", "), node.Value)
+	}
+}
+
+// object checks the fields of node, a mapping at path, against schema; the fields in skip are not checked.
+func (v *validator) object(schema *Schema, node *yaml.Node, path string, skip []string) {
+	if schema.EmbeddedResource {
+		// Embedded objects are resources of their own, validated by their own schema.
+		return
+	}
+	present := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		present[key.Value] = true
+		if slices.Contains(skip, key.Value) {
+			continue
+		}
+		fieldPath := key.Value
+		if path != "" {
+			fieldPath = path + "." + key.Value
+		}
+		if prop, ok := schema.Properties[key.Value]; ok {
+			v.validate(prop, value, fieldPath)
+			continue
+		}
+		switch {
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+			v.validate(schema.AdditionalProperties.Schema, value, fieldPath)
+		case schema.PreserveUnknownFields:
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Allowed:
+		case len(schema.Properties) > 0 || schema.AdditionalProperties != nil:
+			v.report(key, fieldPath, "unknown field")
+		}
+	}
+	for _, name := range schema.Required {
+		if !present[name] {
+			v.report(node, path, "missing required field %q", name)
+		}
+	}
+}
+
+// describe returns the type of the value of node, for messages.
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "an array"
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		return "an integer"
+	case "!!float":
+		return "a number"
+	case "!!bool":
+		return "a boolean"
+	}
+	return "a string"
+}
+
+// article returns the type name with its indefinite article.
+func article(typ string) string {
+	if typ == "integer" {
+		return "an integer"
+	}
+	return "a " + typ
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/textstyle"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/config"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// Paths runs the rules enabled by cfg over the manifests in paths, which may be files or directories
+// (searched for .yaml and .yml files). The manifests are checked together, as one manifest set, against the
+// CRDs of cfg. A nil cfg runs the rules that are on by default.
+func Paths(paths []string, cfg *config.Config) ([]findings.Finding, error) {
+	files, err := manifestFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var all []*manifests.Object
+	seen := map[string]bool{}
+	for _, path := range files {
+		objs, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		seen[path] = true
+		all = append(all, objs...)
+	}
+
+	crdFiles, err := cfg.CRDFiles()
+	if err != nil {
+		return nil, err
+	}
+	var crds []*manifests.Object
+	for _, path := range crdFiles {
+		if seen[path] {
+			continue
+		}
+		objs, err := parseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, objs...)
+	}
+	return objects(all, crds, cfg), nil
+}
+
+// File runs the rules enabled by cfg over the manifests in the file at path.
+func File(path string, cfg *config.Config) ([]findings.Finding, error) {
+	objs, err := parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Objects(objs, cfg), nil
+}
+
+// Objects runs the rules enabled by cfg over objs, checking them together as one manifest set.
+func Objects(objs []*manifests.Object, cfg *config.Config) []findings.Finding {
+	return objects(objs, nil, cfg)
+}
+
+// objects runs the rules enabled by cfg over objs, validating custom resources against crds as well as the
+// CRDs among objs.
+func objects(objs []*manifests.Object, crds []*manifests.Object, cfg *config.Config) []findings.Finding {
+	var found []findings.Finding
+	add := func(path string, d rules.Diagnostic, sev findings.Severity) {
+		found = append(found, findings.Finding{
+			Path:     path,
+			Line:     d.Line,
+			Rule:     d.RuleName,
+			Message:  d.Message,
+			Severity: sev,
+		})
+	}
+	enabled := enabledRules(cfg)
+	for _, obj := range objs {
+		for _, e := range enabled {
+			for _, d := range e.rule.Check(obj) {
+				add(obj.Path, d, e.sev)
+			}
+		}
+	}
+	for _, rule := range rules.AllSetRules() {
+		sev, ok := cfg.Severity(rule)
+		if !ok {
+			continue
+		}
+		if r, ok := rule.(*rules.CRDSchema); ok {
+			r.CRDs = crds
+		}
+		for _, d := range rule.CheckSet(objs) {
+			add(d.Path, d, sev)
+		}
+	}
+	return found
+}
+
+// enabledRule is a rule enabled by the config, with the severity of its findings.
+type enabledRule struct {
+	rule rules.Rule
+	sev  findings.Severity
+}
+
+// enabledRules returns the rules (but not the set rules) enabled by cfg, configured as cfg sets.
+func enabledRules(cfg *config.Config) []enabledRule {
+	var enabled []enabledRule
+	for _, rule := range rules.AllRules() {
+		sev, ok := cfg.Severity(rule)
+		if !ok {
+			continue
+		}
+		if r, ok := rule.(*rules.RequiredLabels); ok && cfg != nil {
+			r.Labels = cfg.Labels
+		}
+		enabled = append(enabled, enabledRule{rule: rule, sev: sev})
+	}
+	return enabled
+}
+
+// Fix applies the fixes of the rules enabled by cfg to the manifests in paths, which may be files or directories,
+// and returns the files it changed. Only the rules that are rules.Fixers fix anything; their other findings, and
+// those of the other rules, are left for Paths to report. Comments, blank lines and the indentation of the files are
+// kept, though the other formatting of a changed file is normalized as by ap format.
+func Fix(paths []string, cfg *config.Config) ([]string, error) {
+	var fixers []rules.Fixer
+	for _, e := range enabledRules(cfg) {
+		if fixer, ok := e.rule.(rules.Fixer); ok {
+			fixers = append(fixers, fixer)
+		}
+	}
+	if len(fixers) == 0 {
+		return nil, nil
+	}
+
+	files, err := manifestFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, path := range files {
+		fixed, err := fixFile(path, fixers)
+		if err != nil {
+			return changed, err
+		}
+		if fixed {
+			changed = append(changed, path)
+		}
+	}
+	return changed, nil
+}
+
+// fixFile applies fixers to the manifests in the file at path, rewriting it if any of them changed a manifest.
+func fixFile(path string, fixers []rules.Fixer) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	objs, err := manifests.Parse(bytes.NewReader(content))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	changed := false
+	for _, obj := range objs {
+		for _, fixer := range fixers {
+			if fixer.Fix(obj) {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	docs := make([]*yaml.Node, len(objs))
+	for i, obj := range objs {
+		docs[i] = obj.Node
+	}
+	indent, compact := textstyle.DetectYAMLStyle(content)
+	fixed, err := textstyle.EncodeYAML(docs, content, indent, compact)
+	if err != nil {
+		return false, fmt.Errorf("failed to write the fixes of %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, fixed, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// manifestFiles returns the .yaml and .yml files in paths, which may be files or directories.
+func manifestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, arg := range paths {
+		err := filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// parseFile parses the manifests in the file at path.
+func parseFile(path string) ([]*manifests.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	objs, err := manifests.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, obj := range objs {
+		obj.Path = path
+	}
+	return objs, nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/codestyle/pkg/findings"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/config"
+)
+
+func TestPaths(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"statefulset.yaml": "apiVersion: apps/v1\nkind: Service\nmetadata:\n  name: db\n---\napiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\n",
+		"ok.yml":           "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\nspec:\n  updateStrategy:\n    type: RollingUpdate\n",
+		"notes.txt":        "kind: StatefulSet\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Paths([]string{dir}, nil)
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	want := findings.Finding{
+		Path:     filepath.Join(dir, "statefulset.yaml"),
+		Line:     7,
+		Rule:     "statefulset-updatestrategy",
+		Message:  "StatefulSet updateStrategy should be explicitly set.",
+		Severity: findings.SeverityError,
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Paths() = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestPathsChecksManifestSet(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    metadata:\n      labels:\n        app: web\n    spec:\n      containers:\n      - name: web\n        ports:\n        - containerPort: 8080\n",
+		"service.yaml":    "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  selector:\n    app: web\n  ports:\n  - port: 80\n    targetPort: 8000\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Paths([]string{dir}, nil)
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	want := findings.Finding{
+		Path:     filepath.Join(dir, "service.yaml"),
+		Line:     10,
+		Rule:     "service-targetport",
+		Message:  "Service web targetPort 8000 does not match a containerPort or port name of Deployment web.",
+		Severity: findings.SeverityError,
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Paths() = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestPathsWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	content := "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\n  labels:\n    team: data\n"
+	if err := os.WriteFile(filepath.Join(dir, "db.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Rules:  map[string]string{"statefulset-updatestrategy": "ignore", "required-labels": "warn"},
+		Labels: []string{"team", "app.kubernetes.io/name"},
+	}
+	got, err := Paths([]string{dir}, cfg)
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	want := findings.Finding{
+		Path:     filepath.Join(dir, "db.yaml"),
+		Line:     4,
+		Rule:     "required-labels",
+		Message:  "StatefulSet db does not have the label app.kubernetes.io/name.",
+		Severity: findings.SeverityWarning,
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Paths() = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestPathsWithCRDs(t *testing.T) {
+	root := t.TempDir()
+	crd := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+  - name: v1
+    served: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+`
+	files := map[string]string{
+		"config/crd/bases/widgets.yaml": crd,
+		"k8s/widget.yaml":               "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\nspec:\n  size: large\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := config.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Paths([]string{filepath.Join(root, "k8s")}, cfg)
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	want := findings.Finding{
+		Path:     filepath.Join(root, "k8s", "widget.yaml"),
+		Line:     6,
+		Rule:     "crd-schema",
+		Message:  "Widget w: spec.size: must be an integer, not a string.",
+		Severity: findings.SeverityError,
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Paths() = %+v, want [%+v]", got, want)
+	}
+
+	// The CRDs are only read from the configured paths.
+	cfg.CRDs = []string{"crds"}
+	got, err = Paths([]string{filepath.Join(root, "k8s")}, cfg)
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Paths() with crds: [crds] = %+v, want no findings", got)
+	}
+}
+
+func TestFix(t *testing.T) {
+	dir := t.TempDir()
+	in := `# The web frontend.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2 # scaled by an HPA
+
+  template:
+    spec:
+      securityContext: {}
+      containers:
+      - name: web
+        resources:
+          requests:
+            memory: 256Mi
+      - name: debug
+        securityContext:
+          runAsUser: 0
+`
+	want := `# The web frontend.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  replicas: 2 # scaled by an HPA
+
+  template:
+    spec:
+      securityContext: {}
+      containers:
+      - name: web
+        resources:
+          requests:
+            memory: 256Mi
+            cpu: 100m
+          limits:
+            memory: 256Mi
+        securityContext:
+          allowPrivilegeEscalation: false
+      - name: debug
+        securityContext:
+          runAsUser: 0
+          allowPrivilegeEscalation: false
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+          limits:
+            memory: 128Mi
+`
+	path := filepath.Join(dir, "web.yaml")
+	if err := os.WriteFile(path, []byte(in), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pod := filepath.Join(dir, "pod.yaml")
+	podIn := "kind: Pod\nmetadata:\n  name: p\n  labels:\n    app.kubernetes.io/name: p\nspec:\n  containers:\n  - name: p\n" +
+		"    securityContext:\n      allowPrivilegeEscalation: false\n    resources:\n      requests: {cpu: 1, memory: 1Gi}\n      limits: {memory: 1Gi}\n"
+	if err := os.WriteFile(pod, []byte(podIn), 0644); err != nil {
+		t.Fatal(err)
+	}
+	untouched := filepath.Join(dir, "db.yaml")
+	if err := os.WriteFile(untouched, []byte("kind: ConfigMap\nmetadata:\n    name: db # not a workload\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Rules: map[string]string{
+		"container-resources":       "warn",
+		"container-securitycontext": "warn",
+		"run-as-non-root":           "warn",
+		"required-labels":           "warn",
+	}}
+	changed, err := Fix([]string{dir}, cfg)
+	if err != nil {
+		t.Fatalf("Fix failed: %v", err)
+	}
+	if !slices.Equal(changed, []string{pod, path}) {
+		t.Errorf("Fix() = %v, want [%s %s]", changed, pod, path)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("Fix() wrote:\n%s\nwant:\n%s", got, want)
+	}
+	got, err = os.ReadFile(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := podIn + "  securityContext:\n    runAsNonRoot: true\n"; string(got) != want {
+		t.Errorf("Fix() wrote:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A pod with a container that runs as root explicitly is left to be fixed by hand.
+	found, err := Paths([]string{path}, cfg)
+	if err != nil {
+		t.Fatalf("Paths failed: %v", err)
+	}
+	var lines []int
+	for _, f := range found {
+		if f.Rule == "run-as-non-root" {
+			lines = append(lines, f.Line)
+		}
+	}
+	if len(found) != 2 || !slices.Equal(lines, []int{15, 26}) {
+		t.Errorf("Paths() after Fix() = %+v, want run-as-non-root findings at lines 15 and 26", found)
+	}
+
+	// Fixing again changes nothing.
+	changed, err = Fix([]string{dir}, cfg)
+	if err != nil {
+		t.Fatalf("Fix failed: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("Fix() of fixed manifests = %v, want no changes", changed)
+	}
+
+	// Rules that are not enabled fix nothing.
+	if changed, err := Fix([]string{dir}, nil); err != nil || len(changed) != 0 {
+		t.Errorf("Fix() with the default rules = %v, %v, want no changes", changed, err)
+	}
+}
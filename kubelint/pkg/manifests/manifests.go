@@ -25,6 +25,9 @@ import (
 // Object represents a single YAML document.
 type Object struct {
 	Node *yaml.Node
+
+	// Path is the file the object was parsed from, if known.
+	Path string
 }
 
 // Parse parses a multi-document YAML file.
@@ -61,6 +64,11 @@ func (o *Object) GetString(path string) (string, bool, error) {
 	return node.Value, true, nil
 }
 
+// GetNode returns the node at the given path, or nil if there is none.
+func (o *Object) GetNode(path string) (*yaml.Node, error) {
+	return o.findNode(path)
+}
+
 func (o *Object) findNode(path string) (*yaml.Node, error) {
 	parts := strings.Split(path, ".")
 	curr := o.Node
@@ -110,3 +118,15 @@ func (o *Object) Kind() (string, bool, error) {
 func (o *Object) ApiVersion() (string, bool, error) {
 	return o.GetString("apiVersion")
 }
+
+// Name returns metadata.name of the object, or "" if it is not set.
+func (o *Object) Name() string {
+	name, _, _ := o.GetString("metadata.name")
+	return name
+}
+
+// Namespace returns metadata.namespace of the object, or "" if it is not set.
+func (o *Object) Namespace() string {
+	namespace, _, _ := o.GetString("metadata.namespace")
+	return namespace
+}
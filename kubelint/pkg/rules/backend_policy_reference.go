@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+// backendConfigAnnotations are the annotations of a Service naming its BackendConfigs.
+var backendConfigAnnotations = []string{"cloud.google.com/backend-config", "beta.cloud.google.com/backend-config"}
+
+// backendPolicyKinds are the GKE Gateway policies attached to a Service by targetRef.
+var backendPolicyKinds = map[string]bool{
+	"HealthCheckPolicy": true,
+	"GCPBackendPolicy":  true,
+}
+
+type BackendPolicyReference struct {
+	name    string
+	message string
+}
+
+func (r *BackendPolicyReference) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.BackendPolicyReferenceMD)
+	}
+}
+
+func (r *BackendPolicyReference) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *BackendPolicyReference) CheckSet(objs []*manifests.Object) []Diagnostic {
+	r.init()
+	index := indexObjects(objs)
+
+	var diags []Diagnostic
+	for _, obj := range objs {
+		kind, _, _ := obj.Kind()
+		switch {
+		case kind == "Service":
+			for _, name := range backendConfigAnnotations {
+				annotation := child(root(obj), "metadata", "annotations", name)
+				if annotation == nil {
+					continue
+				}
+				configs, err := backendConfigNames(value(annotation))
+				if err != nil {
+					diags = append(diags, Diagnostic{
+						RuleName: r.Name(),
+						Message:  fmt.Sprintf("Service %s has an invalid %s annotation: %v.", obj.Name(), name, err),
+						Line:     annotation.Line,
+						Path:     obj.Path,
+					})
+					continue
+				}
+				for _, config := range configs {
+					if !index.has("BackendConfig", obj.Namespace(), config) {
+						diags = append(diags, Diagnostic{
+							RuleName: r.Name(),
+							Message:  fmt.Sprintf("Service %s refers to BackendConfig %s, which is not in the manifests.", obj.Name(), config),
+							Line:     annotation.Line,
+							Path:     obj.Path,
+						})
+					}
+				}
+			}
+
+		case backendPolicyKinds[kind]:
+			targetRef := child(root(obj), "spec", "targetRef")
+			if value(child(targetRef, "kind")) != "Service" {
+				continue
+			}
+			service := value(child(targetRef, "name"))
+			namespace := value(child(targetRef, "namespace"))
+			if namespace == "" {
+				namespace = obj.Namespace()
+			}
+			if !index.has("Service", namespace, service) {
+				diags = append(diags, Diagnostic{
+					RuleName: r.Name(),
+					Message:  fmt.Sprintf("%s %s targets Service %s, which is not in the manifests.", kind, obj.Name(), service),
+					Line:     line(obj, child(targetRef, "name")),
+					Path:     obj.Path,
+				})
+			}
+		}
+	}
+	return diags
+}
+
+// backendConfigNames returns the names of the BackendConfigs in the value of a backend-config annotation,
+// e.g. {"default": "web", "ports": {"http": "web-http"}}.
+func backendConfigNames(annotation string) ([]string, error) {
+	var config struct {
+		Default string            `json:"default"`
+		Ports   map[string]string `json:"ports"`
+	}
+	if err := json.Unmarshal([]byte(annotation), &config); err != nil {
+		return nil, err
+	}
+	var names []string
+	if config.Default != "" {
+		names = append(names, config.Default)
+	}
+	var ports []string
+	for port := range config.Ports {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		names = append(names, config.Ports[port])
+	}
+	return names, nil
+}
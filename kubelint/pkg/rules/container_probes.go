@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+type ContainerProbes struct {
+	name    string
+	message string
+}
+
+func (r *ContainerProbes) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.ContainerProbesMD)
+	}
+}
+
+func (r *ContainerProbes) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *ContainerProbes) OptIn() {}
+
+func (r *ContainerProbes) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	kind, _, _ := obj.Kind()
+	if kind != "Deployment" && kind != "StatefulSet" && kind != "DaemonSet" {
+		return nil
+	}
+	var diags []Diagnostic
+	for _, c := range containers(podSpec(obj), false) {
+		var missing []string
+		for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+			if child(c.node, probe) == nil {
+				missing = append(missing, probe)
+			}
+		}
+		if len(missing) > 0 {
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("The %s does not set %s.", c.describe(obj), strings.Join(missing, " or ")),
+				Line:     c.node.Line,
+			})
+		}
+	}
+	return diags
+}
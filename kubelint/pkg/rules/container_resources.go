@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+// fixRequests are the requests Fix sets on containers that do not set them; the memory limit is set to the
+// memory request.
+var fixRequests = map[string]string{"cpu": "100m", "memory": "128Mi"}
+
+type ContainerResources struct {
+	name    string
+	message string
+}
+
+func (r *ContainerResources) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.ContainerResourcesMD)
+	}
+}
+
+func (r *ContainerResources) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *ContainerResources) OptIn() {}
+
+func (r *ContainerResources) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	var diags []Diagnostic
+	for _, c := range containers(podSpec(obj), true) {
+		var missing []string
+		for _, path := range [][]string{{"requests", "cpu"}, {"requests", "memory"}, {"limits", "memory"}} {
+			if child(c.node, append([]string{"resources"}, path...)...) == nil {
+				missing = append(missing, "resources."+strings.Join(path, "."))
+			}
+		}
+		if len(missing) > 0 {
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("The %s does not set %s.", c.describe(obj), strings.Join(missing, ", ")),
+				Line:     c.node.Line,
+			})
+		}
+	}
+	return diags
+}
+
+// Fix sets the missing requests of each container to fixRequests, and the missing memory limit to the memory request.
+func (r *ContainerResources) Fix(obj *manifests.Object) bool {
+	changed := false
+	for _, c := range containers(podSpec(obj), true) {
+		if child(c.node, "resources", "requests", "cpu") != nil && child(c.node, "resources", "requests", "memory") != nil &&
+			child(c.node, "resources", "limits", "memory") != nil {
+			continue
+		}
+		requests := ensureMapping(c.node, "resources", "requests")
+		for _, resource := range []string{"cpu", "memory"} {
+			if setDefault(requests, resource, fixRequests[resource], "!!str") {
+				changed = true
+			}
+		}
+		memory := value(child(requests, "memory"))
+		if memory != "" && setDefault(ensureMapping(c.node, "resources", "limits"), "memory", memory, "!!str") {
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+type ContainerSecurityContext struct {
+	name    string
+	message string
+}
+
+func (r *ContainerSecurityContext) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.ContainerSecurityContextMD)
+	}
+}
+
+func (r *ContainerSecurityContext) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *ContainerSecurityContext) OptIn() {}
+
+func (r *ContainerSecurityContext) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	var diags []Diagnostic
+	for _, c := range containers(podSpec(obj), true) {
+		securityContext := child(c.node, "securityContext")
+		if privileged := child(securityContext, "privileged"); value(privileged) == "true" {
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("The %s runs privileged.", c.describe(obj)),
+				Line:     privileged.Line,
+			})
+		}
+		if value(child(securityContext, "allowPrivilegeEscalation")) != "false" {
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("The %s does not set securityContext.allowPrivilegeEscalation to false.", c.describe(obj)),
+				Line:     c.node.Line,
+			})
+		}
+	}
+	return diags
+}
+
+// Fix sets allowPrivilegeEscalation to false in the securityContext of the containers that do not set it.
+// Containers that allow privilege escalation explicitly, or run privileged, are left to be fixed by hand.
+func (r *ContainerSecurityContext) Fix(obj *manifests.Object) bool {
+	changed := false
+	for _, c := range containers(podSpec(obj), true) {
+		if child(c.node, "securityContext", "allowPrivilegeEscalation") != nil {
+			continue
+		}
+		if setDefault(ensureMapping(c.node, "securityContext"), "allowPrivilegeEscalation", "false", "!!bool") {
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/crds"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+// CRDSchema validates custom resources against the schemas of their CustomResourceDefinitions: those in the
+// manifest set, and CRDs, which are loaded from elsewhere (e.g. config/crd).
+type CRDSchema struct {
+	name    string
+	message string
+
+	// CRDs are CustomResourceDefinitions that are not in the manifest set.
+	CRDs []*manifests.Object
+}
+
+func (r *CRDSchema) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.CRDSchemaMD)
+	}
+}
+
+func (r *CRDSchema) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *CRDSchema) CheckSet(objs []*manifests.Object) []Diagnostic {
+	r.init()
+
+	var diags []Diagnostic
+	set := crds.Set{}
+	// The CRDs of the manifest set are added last, so that they take precedence.
+	for _, obj := range append(slices.Clone(r.CRDs), objs...) {
+		if err := set.Add([]*manifests.Object{obj}); err != nil {
+			line, _ := obj.GetLine("spec")
+			diags = append(diags, Diagnostic{Message: err.Error() + ".", Line: line, RuleName: r.name, Path: obj.Path})
+		}
+	}
+	if len(set) == 0 {
+		return diags
+	}
+
+	for _, obj := range objs {
+		kind, _, _ := obj.Kind()
+		for _, p := range set.Validate(obj) {
+			diags = append(diags, Diagnostic{
+				Message:  fmt.Sprintf("%s %s: %s.", kind, obj.Name(), p.Message),
+				Line:     p.Line,
+				RuleName: r.name,
+				Path:     obj.Path,
+			})
+		}
+	}
+	return diags
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+type ImageLatestTag struct {
+	name    string
+	message string
+}
+
+func (r *ImageLatestTag) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.ImageLatestTagMD)
+	}
+}
+
+func (r *ImageLatestTag) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *ImageLatestTag) OptIn() {}
+
+func (r *ImageLatestTag) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	var diags []Diagnostic
+	for _, c := range containers(podSpec(obj), true) {
+		image := child(c.node, "image")
+		if !floatingImage(value(image)) {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			RuleName: r.Name(),
+			Message:  fmt.Sprintf("The %s uses image %s, which is not pinned to a version tag or digest.", c.describe(obj), value(image)),
+			Line:     image.Line,
+		})
+	}
+	return diags
+}
+
+// floatingImage reports whether image is in a registry and refers to the latest tag, explicitly or by having no tag.
+// Images without a registry host are the placeholders that ap deploy replaces, so they are never floating.
+func floatingImage(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	host, path, ok := strings.Cut(image, "/")
+	if !ok || !(strings.ContainsAny(host, ".:") || host == "localhost") {
+		return false
+	}
+	// A colon after the last slash separates the tag; one in the host is a port.
+	i := strings.LastIndex(path, ":")
+	return i == -1 || strings.Contains(path[i+1:], "/") || path[i+1:] == "latest"
+}
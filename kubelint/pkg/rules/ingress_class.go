@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+type IngressClass struct {
+	name    string
+	message string
+}
+
+func (r *IngressClass) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.IngressClassMD)
+	}
+}
+
+func (r *IngressClass) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *IngressClass) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	kind, _, _ := obj.Kind()
+	if kind != "Ingress" {
+		return nil
+	}
+
+	className := child(root(obj), "spec", "ingressClassName")
+	annotation := child(root(obj), "metadata", "annotations", "kubernetes.io/ingress.class")
+	if value(className) == "" || value(annotation) == "" || value(className) == value(annotation) {
+		return nil
+	}
+	return []Diagnostic{
+		{
+			RuleName: r.Name(),
+			Message:  fmt.Sprintf("Ingress %s sets ingressClassName %q, but the kubernetes.io/ingress.class annotation %q.", obj.Name(), value(className), value(annotation)),
+			Line:     className.Line,
+		},
+	}
+}
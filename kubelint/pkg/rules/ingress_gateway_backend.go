@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+type IngressGatewayBackend struct {
+	name    string
+	message string
+}
+
+func (r *IngressGatewayBackend) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.IngressGatewayBackendMD)
+	}
+}
+
+func (r *IngressGatewayBackend) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *IngressGatewayBackend) CheckSet(objs []*manifests.Object) []Diagnostic {
+	r.init()
+
+	// The Ingresses routing to each Service, by name.
+	ingresses := map[string][]*manifests.Object{}
+	for _, obj := range objs {
+		if kind, _, _ := obj.Kind(); kind == "Ingress" {
+			for _, service := range ingressBackends(obj) {
+				ingresses[service] = append(ingresses[service], obj)
+			}
+		}
+	}
+	if len(ingresses) == 0 {
+		return nil
+	}
+
+	var diags []Diagnostic
+	for _, obj := range objs {
+		kind, _, _ := obj.Kind()
+		if kind != "HTTPRoute" && kind != "GRPCRoute" {
+			continue
+		}
+		for _, rule := range items(child(root(obj), "spec", "rules")) {
+			for _, ref := range items(child(rule, "backendRefs")) {
+				if group := value(child(ref, "group")); group != "" && group != "core" {
+					continue
+				}
+				if refKind := value(child(ref, "kind")); refKind != "" && refKind != "Service" {
+					continue
+				}
+				service := value(child(ref, "name"))
+				namespace := value(child(ref, "namespace"))
+				if namespace == "" {
+					namespace = obj.Namespace()
+				}
+				for _, ingress := range ingresses[service] {
+					if !sameNamespace(ingress.Namespace(), namespace) {
+						continue
+					}
+					diags = append(diags, Diagnostic{
+						RuleName: r.Name(),
+						Message:  fmt.Sprintf("Service %s is a backend of both Ingress %s and %s %s.", service, ingress.Name(), kind, obj.Name()),
+						Line:     line(obj, child(ref, "name")),
+						Path:     obj.Path,
+					})
+					break
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// ingressBackends returns the names of the Services an Ingress routes to.
+func ingressBackends(obj *manifests.Object) []string {
+	spec := child(root(obj), "spec")
+	var services []string
+	if name := value(child(spec, "defaultBackend", "service", "name")); name != "" {
+		services = append(services, name)
+	}
+	for _, rule := range items(child(spec, "rules")) {
+		for _, path := range items(child(rule, "http", "paths")) {
+			if name := value(child(path, "backend", "service", "name")); name != "" {
+				services = append(services, name)
+			}
+		}
+	}
+	return services
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"gopkg.in/yaml.v3"
+)
+
+// objectIndex finds the objects of a manifest set by kind and name.
+type objectIndex map[string][]*manifests.Object
+
+// indexObjects returns an index of objs.
+func indexObjects(objs []*manifests.Object) objectIndex {
+	index := objectIndex{}
+	for _, obj := range objs {
+		kind, _, _ := obj.Kind()
+		index[kind+"/"+obj.Name()] = append(index[kind+"/"+obj.Name()], obj)
+	}
+	return index
+}
+
+// has reports whether the set has an object of the kind and name in namespace.
+// Objects without a namespace are deployed to the namespace of the target, so they match any namespace.
+func (index objectIndex) has(kind, namespace, name string) bool {
+	for _, obj := range index[kind+"/"+name] {
+		if sameNamespace(obj.Namespace(), namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameNamespace reports whether objects in namespaces a and b can be in the same namespace.
+func sameNamespace(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// child returns the node at the path of keys under node, a mapping, or nil if there is none.
+func child(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+// ensureMapping returns the mapping at the path of keys under node, a mapping, adding the missing keys
+// with empty mappings. It returns nil if a key already has a value that is not a mapping or null.
+func ensureMapping(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		next := child(node, key)
+		switch {
+		case next == nil:
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, next)
+		case next.Kind == yaml.ScalarNode && next.Tag == "!!null":
+			// "key:" without a value, or "key: null".
+			*next = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: next.LineComment}
+		}
+		node = next
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	if len(node.Content) == 0 {
+		// Write "{}" as a block mapping once it has entries.
+		node.Style &^= yaml.FlowStyle
+	}
+	return node
+}
+
+// setDefault adds key to mapping with the scalar value and tag (e.g. "!!str" or "!!bool"),
+// unless mapping already has key, and reports whether it did.
+func setDefault(mapping *yaml.Node, key, value, tag string) bool {
+	if mapping == nil || child(mapping, key) != nil {
+		return false
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value},
+	)
+	return true
+}
+
+// items returns the items of node, if it is a sequence.
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+// value returns the value of node, if it is a scalar.
+func value(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// stringMap returns the scalar values of node, a mapping such as labels or a selector.
+func stringMap(node *yaml.Node) map[string]string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	m := make(map[string]string)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		m[node.Content[i].Value] = value(node.Content[i+1])
+	}
+	return m
+}
+
+// root returns the top-level mapping of obj, or nil if the document is empty.
+func root(obj *manifests.Object) *yaml.Node {
+	node := obj.Node
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	return node
+}
+
+// line returns the line of node, or of the kind of obj if node is nil.
+func line(obj *manifests.Object, node *yaml.Node) int {
+	if node != nil {
+		return node.Line
+	}
+	l, _ := obj.GetLine("kind")
+	return l
+}
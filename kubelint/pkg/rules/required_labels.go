@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+)
+
+// nameLabel is the recommended label holding the name of the application.
+const nameLabel = "app.kubernetes.io/name"
+
+// DefaultRequiredLabels are the labels required by RequiredLabels if none are configured.
+var DefaultRequiredLabels = []string{nameLabel}
+
+type RequiredLabels struct {
+	name    string
+	message string
+
+	// Labels are the labels required on workloads and Services (default: DefaultRequiredLabels).
+	Labels []string
+}
+
+func (r *RequiredLabels) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.RequiredLabelsMD)
+	}
+}
+
+func (r *RequiredLabels) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *RequiredLabels) OptIn() {}
+
+func (r *RequiredLabels) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	kind, _, _ := obj.Kind()
+	if _, ok := podSpecPaths[kind]; !ok && kind != "Service" {
+		return nil
+	}
+	required := r.Labels
+	if len(required) == 0 {
+		required = DefaultRequiredLabels
+	}
+
+	labels := stringMap(child(root(obj), "metadata", "labels"))
+	var missing []string
+	for _, label := range required {
+		if _, ok := labels[label]; !ok {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []Diagnostic{
+		{
+			RuleName: r.Name(),
+			Message:  fmt.Sprintf("%s %s does not have the label %s.", kind, obj.Name(), strings.Join(missing, ", ")),
+			Line:     line(obj, child(root(obj), "metadata")),
+		},
+	}
+}
+
+// Fix adds app.kubernetes.io/name, if it is required, with the name of the object as its value.
+// The values of other labels cannot be guessed, so they are left to be added by hand.
+func (r *RequiredLabels) Fix(obj *manifests.Object) bool {
+	kind, _, _ := obj.Kind()
+	if _, ok := podSpecPaths[kind]; !ok && kind != "Service" {
+		return false
+	}
+	required := r.Labels
+	if len(required) == 0 {
+		required = DefaultRequiredLabels
+	}
+	if !slices.Contains(required, nameLabel) || obj.Name() == "" || child(root(obj), "metadata", "labels", nameLabel) != nil {
+		return false
+	}
+	return setDefault(ensureMapping(root(obj), "metadata", "labels"), nameLabel, obj.Name(), "!!str")
+}
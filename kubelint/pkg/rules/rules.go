@@ -44,16 +44,74 @@ type Rule interface {
 	Check(obj *manifests.Object) []Diagnostic
 }
 
+// SetRule defines a linter rule that checks the objects of a manifest set together,
+// e.g. a Service against the workloads it selects.
+type SetRule interface {
+	Name() string
+	CheckSet(objs []*manifests.Object) []Diagnostic
+}
+
+// OptInRule is implemented by rules that are off unless enabled in .ap/kubelint.yaml,
+// because they check for practices that many manifests do not follow.
+type OptInRule interface {
+	OptIn()
+}
+
+// IsOptIn returns true if rule, a Rule or a SetRule, is off unless enabled.
+func IsOptIn(rule any) bool {
+	_, ok := rule.(OptInRule)
+	return ok
+}
+
+// Fixer is implemented by rules whose findings can be fixed mechanically, by editing the manifest.
+type Fixer interface {
+	// Fix edits obj so that the rule no longer reports what can be fixed without knowing more about the workload,
+	// keeping the comments of obj, and reports whether obj was changed.
+	Fix(obj *manifests.Object) bool
+}
+
 // Diagnostic represents a finding by a rule.
 type Diagnostic struct {
 	Message  string
 	Line     int
 	RuleName string
+
+	// Path is the file of the object the finding is about, for the findings of set rules.
+	Path string
 }
 
 // AllRules returns all registered rules.
 func AllRules() []Rule {
 	return []Rule{
 		&StatefulSetUpdateStrategy{},
+		&IngressClass{},
+		&ContainerSecurityContext{},
+		&ContainerResources{},
+		&ContainerProbes{},
+		&ImageLatestTag{},
+		&RunAsNonRoot{},
+		&RequiredLabels{},
+	}
+}
+
+// AllSetRules returns all registered set rules.
+func AllSetRules() []SetRule {
+	return []SetRule{
+		&IngressGatewayBackend{},
+		&BackendPolicyReference{},
+		&ServiceTargetPort{},
+		&CRDSchema{},
+	}
+}
+
+// Names returns the names of all registered rules and set rules.
+func Names() []string {
+	var names []string
+	for _, rule := range AllRules() {
+		names = append(names, rule.Name())
+	}
+	for _, rule := range AllSetRules() {
+		names = append(names, rule.Name())
 	}
+	return names
 }
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gke-labs/gke-labs-infra/ap/pkg/goldentest"
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+)
+
+// TestAllRulesGolden runs the rules (and set rules) that are on by default over testdata/*.yaml, comparing
+// the diagnostics against the matching .golden file.
+func TestAllRulesGolden(t *testing.T) {
+	var rules []Rule
+	for _, rule := range AllRules() {
+		if !IsOptIn(rule) {
+			rules = append(rules, rule)
+		}
+	}
+	testGolden(t, filepath.Join("testdata", "*.yaml"), rules, AllSetRules())
+}
+
+// TestOptInRulesGolden runs the opt-in rules over testdata/workloads/*.yaml, comparing the diagnostics
+// against the matching .golden file.
+func TestOptInRulesGolden(t *testing.T) {
+	var rules []Rule
+	for _, rule := range AllRules() {
+		if IsOptIn(rule) {
+			rules = append(rules, rule)
+		}
+	}
+	testGolden(t, filepath.Join("testdata", "workloads", "*.yaml"), rules, nil)
+}
+
+func testGolden(t *testing.T, pattern string, rules []Rule, setRules []SetRule) {
+	inputs, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no test manifests found in testdata")
+	}
+
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			f, err := os.Open(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			objs, err := manifests.Parse(f)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			var sb strings.Builder
+			for _, obj := range objs {
+				for _, rule := range rules {
+					for _, d := range rule.Check(obj) {
+						fmt.Fprintf(&sb, "%d: %s [%s]\n", d.Line, d.Message, d.RuleName)
+					}
+				}
+			}
+			// Each file is a manifest set of its own.
+			for _, rule := range setRules {
+				for _, d := range rule.CheckSet(objs) {
+					fmt.Fprintf(&sb, "%d: %s [%s]\n", d.Line, d.Message, d.RuleName)
+				}
+			}
+
+			golden := strings.TrimSuffix(input, filepath.Ext(input)) + ".golden"
+			goldentest.CompareFile(t, golden, []byte(sb.String()))
+		})
+	}
+}
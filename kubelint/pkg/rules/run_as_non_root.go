@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+	"gopkg.in/yaml.v3"
+)
+
+type RunAsNonRoot struct {
+	name    string
+	message string
+}
+
+func (r *RunAsNonRoot) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.RunAsNonRootMD)
+	}
+}
+
+func (r *RunAsNonRoot) Name() string {
+	r.init()
+	return r.name
+}
+
+func (r *RunAsNonRoot) OptIn() {}
+
+func (r *RunAsNonRoot) Check(obj *manifests.Object) []Diagnostic {
+	r.init()
+	spec := podSpec(obj)
+	if spec == nil {
+		return nil
+	}
+	kind, _, _ := obj.Kind()
+	podContext := child(spec, "securityContext")
+
+	var diags []Diagnostic
+	if user := child(podContext, "runAsUser"); value(user) == "0" {
+		diags = append(diags, Diagnostic{
+			RuleName: r.Name(),
+			Message:  fmt.Sprintf("The pods of %s %s run as root (runAsUser: 0).", kind, obj.Name()),
+			Line:     user.Line,
+		})
+	}
+	podNonRoot := nonRoot(podContext)
+	for _, c := range containers(spec, true) {
+		containerContext := child(c.node, "securityContext")
+		if user := child(containerContext, "runAsUser"); value(user) == "0" {
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("The %s runs as root (runAsUser: 0).", c.describe(obj)),
+				Line:     user.Line,
+			})
+			continue
+		}
+		if !podNonRoot && !nonRoot(containerContext) {
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("The %s may run as root: set runAsNonRoot: true or a non-zero runAsUser in the securityContext of the pod or container.", c.describe(obj)),
+				Line:     c.node.Line,
+			})
+		}
+	}
+	return diags
+}
+
+// nonRoot reports whether the security context ensures a non-root user.
+// Fix sets runAsNonRoot: true in the securityContext of the pods that may run as root. Pods and containers that
+// set runAsUser: 0 or runAsNonRoot: false explicitly are left to be fixed by hand.
+func (r *RunAsNonRoot) Fix(obj *manifests.Object) bool {
+	spec := podSpec(obj)
+	if spec == nil || nonRoot(child(spec, "securityContext")) || explicitRoot(child(spec, "securityContext")) {
+		return false
+	}
+	mayRunAsRoot := false
+	for _, c := range containers(spec, true) {
+		containerContext := child(c.node, "securityContext")
+		if explicitRoot(containerContext) {
+			return false
+		}
+		if !nonRoot(containerContext) {
+			mayRunAsRoot = true
+		}
+	}
+	return mayRunAsRoot && setDefault(ensureMapping(spec, "securityContext"), "runAsNonRoot", "true", "!!bool")
+}
+
+// explicitRoot reports whether securityContext sets runAsUser: 0 or runAsNonRoot: false.
+func explicitRoot(securityContext *yaml.Node) bool {
+	return value(child(securityContext, "runAsUser")) == "0" || value(child(securityContext, "runAsNonRoot")) == "false"
+}
+
+func nonRoot(securityContext *yaml.Node) bool {
+	if value(child(securityContext, "runAsNonRoot")) == "true" {
+		return true
+	}
+	user := value(child(securityContext, "runAsUser"))
+	return user != "" && user != "0"
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"github.com/gke-labs/gke-labs-infra/kubelint/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// podTemplateKinds are the workloads whose pods are described by spec.template.
+var podTemplateKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"ReplicaSet":  true,
+	"Job":         true,
+}
+
+type ServiceTargetPort struct {
+	name    string
+	message string
+}
+
+func (r *ServiceTargetPort) init() {
+	if r.name == "" {
+		r.name, r.message = ParseRuleMarkdown(ruledata.ServiceTargetPortMD)
+	}
+}
+
+func (r *ServiceTargetPort) Name() string {
+	r.init()
+	return r.name
+}
+
+// workload is a set of pods that Services can select.
+type workload struct {
+	obj *manifests.Object
+	// labels are the labels of the pods.
+	labels map[string]string
+	// numbers and names are the ports declared by the containers of the pods.
+	numbers map[string]bool
+	names   map[string]bool
+}
+
+func (r *ServiceTargetPort) CheckSet(objs []*manifests.Object) []Diagnostic {
+	r.init()
+
+	var workloads []workload
+	for _, obj := range objs {
+		if w, ok := workloadOf(obj); ok {
+			workloads = append(workloads, w)
+		}
+	}
+	if len(workloads) == 0 {
+		return nil
+	}
+
+	var diags []Diagnostic
+	for _, obj := range objs {
+		if kind, _, _ := obj.Kind(); kind != "Service" {
+			continue
+		}
+		spec := child(root(obj), "spec")
+		selector := stringMap(child(spec, "selector"))
+		if len(selector) == 0 || value(child(spec, "type")) == "ExternalName" {
+			continue
+		}
+
+		var selected []workload
+		for _, w := range workloads {
+			if sameNamespace(w.obj.Namespace(), obj.Namespace()) && matches(selector, w.labels) && len(w.numbers)+len(w.names) > 0 {
+				selected = append(selected, w)
+			}
+		}
+		if len(selected) == 0 {
+			// Either the pods are deployed elsewhere, or they do not declare their ports.
+			continue
+		}
+
+		for _, port := range items(child(spec, "ports")) {
+			target := child(port, "targetPort")
+			if target == nil {
+				target = child(port, "port")
+			}
+			if target == nil || targetDeclared(target, selected) {
+				continue
+			}
+			var names []string
+			for _, w := range selected {
+				kind, _, _ := w.obj.Kind()
+				names = append(names, kind+" "+w.obj.Name())
+			}
+			diags = append(diags, Diagnostic{
+				RuleName: r.Name(),
+				Message:  fmt.Sprintf("Service %s targetPort %s does not match a containerPort or port name of %s.", obj.Name(), target.Value, strings.Join(names, ", ")),
+				Line:     target.Line,
+				Path:     obj.Path,
+			})
+		}
+	}
+	return diags
+}
+
+// workloadOf returns the pods of obj, if it is a Pod or a workload with a pod template.
+func workloadOf(obj *manifests.Object) (workload, bool) {
+	kind, _, _ := obj.Kind()
+	var pod *yaml.Node
+	switch {
+	case kind == "Pod":
+		pod = root(obj)
+	case podTemplateKinds[kind]:
+		pod = child(root(obj), "spec", "template")
+	default:
+		return workload{}, false
+	}
+
+	w := workload{
+		obj:     obj,
+		labels:  stringMap(child(pod, "metadata", "labels")),
+		numbers: map[string]bool{},
+		names:   map[string]bool{},
+	}
+	for _, container := range items(child(pod, "spec", "containers")) {
+		for _, port := range items(child(container, "ports")) {
+			if number := value(child(port, "containerPort")); number != "" {
+				w.numbers[number] = true
+			}
+			if name := value(child(port, "name")); name != "" {
+				w.names[name] = true
+			}
+		}
+	}
+	return w, true
+}
+
+// matches reports whether labels match all of selector.
+func matches(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// targetDeclared reports whether target, a port number or name, is declared by one of the workloads.
+func targetDeclared(target *yaml.Node, workloads []workload) bool {
+	for _, w := range workloads {
+		declared := w.names
+		if target.Tag == "!!int" {
+			declared = w.numbers
+		}
+		if declared[target.Value] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+
+	"github.com/gke-labs/gke-labs-infra/kubelint/pkg/manifests"
+	"gopkg.in/yaml.v3"
+)
+
+// podSpecPaths are the paths of the pod spec in each kind of workload.
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// podSpec returns the pod spec of obj, or nil if it is not a workload.
+func podSpec(obj *manifests.Object) *yaml.Node {
+	kind, _, _ := obj.Kind()
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return nil
+	}
+	return child(root(obj), path...)
+}
+
+// container is a container in a pod spec.
+type container struct {
+	node *yaml.Node
+	name string
+
+	// init is set for init containers.
+	init bool
+}
+
+// containers returns the containers of the pod spec, and its init containers if withInit is set.
+func containers(spec *yaml.Node, withInit bool) []container {
+	var found []container
+	for _, node := range items(child(spec, "containers")) {
+		found = append(found, container{node: node, name: value(child(node, "name"))})
+	}
+	if withInit {
+		for _, node := range items(child(spec, "initContainers")) {
+			found = append(found, container{node: node, name: value(child(node, "name")), init: true})
+		}
+	}
+	return found
+}
+
+// describe returns how findings refer to the container c of obj, e.g. "container web of Deployment web".
+func (c container) describe(obj *manifests.Object) string {
+	kind, _, _ := obj.Kind()
+	what := "container"
+	if c.init {
+		what = "init container"
+	}
+	return fmt.Sprintf("%s %s of %s %s", what, c.name, kind, obj.Name())
+}
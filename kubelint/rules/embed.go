@@ -20,3 +20,36 @@ import (
 
 //go:embed statefulset-updatestrategy.md
 var StatefulSetUpdateStrategyMD string
+
+//go:embed ingress-class.md
+var IngressClassMD string
+
+//go:embed ingress-gateway-backend.md
+var IngressGatewayBackendMD string
+
+//go:embed backend-policy-reference.md
+var BackendPolicyReferenceMD string
+
+//go:embed service-targetport.md
+var ServiceTargetPortMD string
+
+//go:embed container-securitycontext.md
+var ContainerSecurityContextMD string
+
+//go:embed container-resources.md
+var ContainerResourcesMD string
+
+//go:embed container-probes.md
+var ContainerProbesMD string
+
+//go:embed image-latest-tag.md
+var ImageLatestTagMD string
+
+//go:embed run-as-non-root.md
+var RunAsNonRootMD string
+
+//go:embed required-labels.md
+var RequiredLabelsMD string
+
+//go:embed crd-schema.md
+var CRDSchemaMD string
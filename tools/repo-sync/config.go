@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// defaultBranch is the branch changes are pushed to, if not configured.
+const defaultBranch = "repo-sync"
+
+// Config is the contents of the repo-sync config file.
+type Config struct {
+	// Branch is the head branch of the pull requests (default repo-sync).
+	Branch string `json:"branch,omitempty"`
+
+	// Files are the files kept in sync.
+	Files []File `json:"files"`
+
+	// Repos are the repositories the files are synced to.
+	Repos []Repo `json:"repos"`
+}
+
+// File is a file synced from this repository.
+type File struct {
+	// Path is the path of the file in the target repositories.
+	Path string `json:"path"`
+
+	// Source is the path of the file to copy, relative to the directory of the config file (defaults to Path).
+	Source string `json:"source,omitempty"`
+
+	// Template renders the source as a Go text/template, with the fields of TemplateData.
+	Template bool `json:"template,omitempty"`
+}
+
+// Repo is a repository the files are synced to.
+type Repo struct {
+	// Name is the repository, as owner/name.
+	Name string `json:"name"`
+
+	// Vars are available to templates as .Vars.
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// Exclude lists the paths of files that are not synced to this repository.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// TemplateData is the data templates are rendered with.
+type TemplateData struct {
+	Owner string
+	Name  string
+	Vars  map[string]string
+}
+
+// loadConfig loads and validates the config file at configFile.
+func loadConfig(configFile string) (*Config, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", configFile, err)
+	}
+	if cfg.Branch == "" {
+		cfg.Branch = defaultBranch
+	}
+
+	paths := make(map[string]bool)
+	for i, f := range cfg.Files {
+		if f.Path == "" {
+			return nil, fmt.Errorf("error in %s: file %d has no path", configFile, i)
+		}
+		if path.IsAbs(f.Path) || strings.HasPrefix(path.Clean(f.Path), "..") {
+			return nil, fmt.Errorf("error in %s: file path %q must be relative to the repository root", configFile, f.Path)
+		}
+		if paths[f.Path] {
+			return nil, fmt.Errorf("error in %s: file %s is listed twice", configFile, f.Path)
+		}
+		paths[f.Path] = true
+	}
+	for _, repo := range cfg.Repos {
+		if _, _, ok := splitRepo(repo.Name); !ok {
+			return nil, fmt.Errorf("error in %s: repo %q must be owner/name", configFile, repo.Name)
+		}
+		for _, p := range repo.Exclude {
+			if !paths[p] {
+				return nil, fmt.Errorf("error in %s: repo %s excludes %s, which is not a synced file", configFile, repo.Name, p)
+			}
+		}
+	}
+	return &cfg, nil
+}
+
+// splitRepo splits owner/name.
+func splitRepo(name string) (string, string, bool) {
+	owner, repo, ok := strings.Cut(name, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+	return owner, repo, true
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/spf13/cobra"
+)
+
+type options struct {
+	config string
+	repos  []string
+	token  string
+	dryRun bool
+}
+
+func main() {
+	opt := &options{}
+	cmd := &cobra.Command{
+		Use:   "repo-sync",
+		Short: "Sync shared files to other repositories with pull requests",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runSync(cmd.Context(), opt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.config, "config", "", "Path to the repo-sync config file")
+	cmd.Flags().StringSliceVar(&opt.repos, "repo", nil, "Only sync these repos (owner/name); can be repeated")
+	cmd.Flags().StringVar(&opt.token, "token", "", "The github token (default from GITHUB_TOKEN env var)")
+	cmd.Flags().BoolVar(&opt.dryRun, "dry-run", false, "Report the files that drifted, without pushing them or opening pull requests")
+	_ = cmd.MarkFlagRequired("config")
+
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		os.Exit(1)
+	}
+}
+
+func runSync(ctx context.Context, opt *options) error {
+	cfg, err := loadConfig(opt.config)
+	if err != nil {
+		return err
+	}
+	client, err := githubclient.New(ctx, opt.token)
+	if err != nil {
+		return err
+	}
+	if opt.dryRun {
+		fmt.Println("Dry run: no changes will be pushed")
+	}
+	return syncRepos(ctx, client, cfg, filepath.Dir(opt.config), opt.repos, opt.dryRun, os.Stdout)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/template"
+
+	"github.com/gke-labs/gke-labs-infra/github-admin/pkg/githubclient"
+	"github.com/google/go-github/v81/github"
+)
+
+// renderFiles returns the desired content of the files synced to repo, keyed by path.
+// Sources are read relative to baseDir.
+func renderFiles(cfg *Config, baseDir string, repo Repo) (map[string]string, error) {
+	owner, name, _ := splitRepo(repo.Name)
+	data := TemplateData{Owner: owner, Name: name, Vars: repo.Vars}
+
+	files := make(map[string]string)
+	for _, f := range cfg.Files {
+		if slices.Contains(repo.Exclude, f.Path) {
+			continue
+		}
+		source := f.Source
+		if source == "" {
+			source = f.Path
+		}
+		content, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(source)))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", source, err)
+		}
+		if !f.Template {
+			files[f.Path] = string(content)
+			continue
+		}
+
+		tmpl, err := template.New(source).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %w", source, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("error rendering %s for %s: %w", source, repo.Name, err)
+		}
+		files[f.Path] = buf.String()
+	}
+	return files, nil
+}
+
+// syncRepos proposes the files of cfg to each of its repos (or only those in only, if set), and reports
+// the outcome to w. Failures for one repository do not stop the others.
+func syncRepos(ctx context.Context, client *github.Client, cfg *Config, baseDir string, only []string, dryRun bool, w io.Writer) error {
+	var errs []error
+	drifted, synced := 0, 0
+	for _, repo := range cfg.Repos {
+		if len(only) > 0 && !slices.Contains(only, repo.Name) {
+			continue
+		}
+		synced++
+
+		files, err := renderFiles(cfg, baseDir, repo)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		owner, name, _ := splitRepo(repo.Name)
+		result, err := githubclient.Propose(ctx, client, githubclient.Proposal{
+			Owner:  owner,
+			Repo:   name,
+			Branch: cfg.Branch,
+			Files:  files,
+			Title:  "Sync shared files from gke-labs-infra",
+			Body:   "repo-sync updates these files to match their shared versions in gke-labs-infra:",
+			DryRun: dryRun,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error syncing %s: %w", repo.Name, err))
+			continue
+		}
+
+		if len(result.Changes) == 0 {
+			fmt.Fprintf(w, "%s: up to date\n", repo.Name)
+			continue
+		}
+		drifted++
+		switch {
+		case dryRun:
+			fmt.Fprintf(w, "%s: would update %d files:\n", repo.Name, len(result.Changes))
+		case result.Updated:
+			fmt.Fprintf(w, "%s: updated %s with %d files:\n", repo.Name, result.URL, len(result.Changes))
+		default:
+			fmt.Fprintf(w, "%s: opened %s with %d files:\n", repo.Name, result.URL, len(result.Changes))
+		}
+		for _, change := range result.Changes {
+			fmt.Fprintf(w, "  %s %s\n", change.Kind, change.Path)
+		}
+	}
+
+	for _, name := range only {
+		if !slices.ContainsFunc(cfg.Repos, func(repo Repo) bool { return repo.Name == name }) {
+			errs = append(errs, fmt.Errorf("repo %s is not in the config", name))
+		}
+	}
+	fmt.Fprintf(w, "%d of %d repos drifted\n", drifted, synced)
+	return errors.Join(errs...)
+}
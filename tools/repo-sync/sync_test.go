@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"valid", "files:\n- path: LICENSE\nrepos:\n- name: gke-labs/a\n  exclude: [LICENSE]\n", ""},
+		{"unknown key", "files:\n- path: LICENSE\n  templat: true\n", "unknown field"},
+		{"bad repo", "repos:\n- name: a\n", `repo "a" must be owner/name`},
+		{"escaping path", "files:\n- path: ../x\n", "must be relative"},
+		{"unknown exclude", "files:\n- path: LICENSE\nrepos:\n- name: gke-labs/a\n  exclude: [README.md]\n", "not a synced file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(dir, tt.name+".yaml")
+			writeFiles(t, dir, map[string]string{tt.name + ".yaml": tt.config})
+			cfg, err := loadConfig(configFile)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadConfig failed: %v", err)
+				}
+				if cfg.Branch != defaultBranch {
+					t.Errorf("Branch = %q, want %q", cfg.Branch, defaultBranch)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRenderFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LICENSE":              "Apache 2.0\n",
+		"SECURITY.md":          "Report issues\n",
+		"templates/codeowners": "* @{{.Owner}}/{{.Vars.team}}\n",
+	})
+	cfg := &Config{Files: []File{
+		{Path: "LICENSE"},
+		{Path: "SECURITY.md"},
+		{Path: ".github/CODEOWNERS", Source: "templates/codeowners", Template: true},
+	}}
+
+	got, err := renderFiles(cfg, dir, Repo{Name: "gke-labs/app", Vars: map[string]string{"team": "app-owners"}, Exclude: []string{"SECURITY.md"}})
+	if err != nil {
+		t.Fatalf("renderFiles failed: %v", err)
+	}
+	want := map[string]string{
+		"LICENSE":            "Apache 2.0\n",
+		".github/CODEOWNERS": "* @gke-labs/app-owners\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("renderFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := renderFiles(cfg, dir, Repo{Name: "gke-labs/other"}); err == nil || !strings.Contains(err.Error(), "team") {
+		t.Errorf("expected an error for the missing template variable, got %v", err)
+	}
+}
+
+// fakeGitHub serves the read-only API calls made by a dry run, for repositories whose default branch is main
+// and which have the files in files (keyed by "owner/repo/path").
+type fakeGitHub struct {
+	files map[string]string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/repos/"), "/", 4)
+	switch {
+	case r.Method != http.MethodGet:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	case len(parts) == 2:
+		json.NewEncoder(w).Encode(map[string]any{"default_branch": "main"})
+	case len(parts) == 4 && parts[2] == "contents":
+		content, ok := f.files[parts[0]+"/"+parts[1]+"/"+parts[3]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"message": "Not Found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSyncReposDryRun(t *testing.T) {
+	server := httptest.NewServer(&fakeGitHub{files: map[string]string{
+		"gke-labs/current/LICENSE":     "Apache 2.0\n",
+		"gke-labs/current/SECURITY.md": "Report issues\n",
+		"gke-labs/stale/LICENSE":       "MIT\n",
+	}})
+	defer server.Close()
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = baseURL
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"LICENSE": "Apache 2.0\n", "SECURITY.md": "Report issues\n"})
+	cfg := &Config{
+		Branch: defaultBranch,
+		Files:  []File{{Path: "LICENSE"}, {Path: "SECURITY.md"}},
+		Repos:  []Repo{{Name: "gke-labs/current"}, {Name: "gke-labs/stale"}},
+	}
+
+	var out bytes.Buffer
+	if err := syncRepos(t.Context(), client, cfg, dir, nil, true, &out); err != nil {
+		t.Fatalf("syncRepos failed: %v", err)
+	}
+	want := `gke-labs/current: up to date
+gke-labs/stale: would update 2 files:
+  modified LICENSE
+  added SECURITY.md
+1 of 2 repos drifted
+`
+	if out.String() != want {
+		t.Errorf("syncRepos() reported:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	err = syncRepos(t.Context(), client, cfg, dir, []string{"gke-labs/missing"}, true, &out)
+	if err == nil || !strings.Contains(err.Error(), "gke-labs/missing is not in the config") {
+		t.Errorf("expected an error for a repo that is not configured, got %v", err)
+	}
+}